@@ -19,37 +19,45 @@ import (
 )
 
 type ServiceContainer struct {
-	Config              *config.Config
-	DB                  *gorm.DB
-	SessionStore        *sessions.Store
-	AuthzService        *authz.Service
-	BackofficeService   *services.BackofficeService
-	AuthService         *services.AuthService
-	UserService         *services.UserService
-	LoggingService      *services.LoggingService
-	AccountService      *services.AccountService
-	TransactionService  *services.TransactionService
-	SettingsService     *services.SettingsService
-	RoleService         *services.RolePermissionService
-	ImportService       *services.ImportService
-	ExportService       *services.ExportService
-	InvestmentService   *services.InvestmentService
-	NotesService        *services.NotesService
-	AnalyticsService    *services.AnalyticsService
-	SavingsService      *services.SavingsService
-	NotificationService *services.NotificationService
-	NotifDispatcher     queue_jobs.NotificationDispatcher
-	SessionsService     *services.SessionsService
-	Hub                 *ws.Hub
+	Config               *config.Config
+	DB                   *gorm.DB
+	SessionStore         *sessions.Store
+	AuthzService         *authz.Service
+	BackofficeService    *services.BackofficeService
+	AuthService          *services.AuthService
+	UserService          *services.UserService
+	LoggingService       *services.LoggingService
+	AccountService       *services.AccountService
+	TransactionService   *services.TransactionService
+	SettingsService      *services.SettingsService
+	RoleService          *services.RolePermissionService
+	ImportService        *services.ImportService
+	ExportService        *services.ExportService
+	InvestmentService    *services.InvestmentService
+	NotesService         *services.NotesService
+	AnalyticsService     *services.AnalyticsService
+	SavingsService       *services.SavingsService
+	InterestService      *services.InterestService
+	AllocationService    *services.AllocationService
+	PriceAlertService    *services.PriceAlertService
+	BudgetService        *services.BudgetService
+	EnvelopeService      *services.EnvelopeService
+	SpendingLimitService *services.SpendingLimitService
+	HouseholdService     *services.HouseholdService
+	DelegationService    *services.DelegationService
+	NotificationService  *services.NotificationService
+	NotifDispatcher      queue_jobs.NotificationDispatcher
+	SessionsService      *services.SessionsService
+	Hub                  *ws.Hub
 }
 
 // NewServiceContainer initialises the application service layer.
 // Pass a non-nil priceFetcher to override the default client (e.g. a mock in tests).
-// Pass nil to have the real Yahoo Finance client created from cfg.FinanceAPIBaseURL.
+// Pass nil to have the price provider registry built from cfg.
 func NewServiceContainer(cfg *config.Config, db *gorm.DB, rdb *redis.Client, logger *zap.Logger, jobDispatcher queue.JobDispatcher, priceFetcher finance.PriceFetcher) (*ServiceContainer, error) {
 	if priceFetcher == nil {
 		var err error
-		priceFetcher, err = finance.NewPriceFetchClient(cfg.FinanceAPIBaseURL)
+		priceFetcher, err = finance.NewPriceFetcher(cfg)
 		if err != nil {
 			logger.Warn("Failed to create price fetch client", zap.Error(err))
 		}
@@ -79,14 +87,22 @@ func NewServiceContainer(cfg *config.Config, db *gorm.DB, rdb *redis.Client, log
 	analyticsRepo := repositories.NewAnalyticsRepository(db)
 	savingsRepo := repositories.NewSavingsRepository(db)
 	notificationRepo := repositories.NewNotificationRepository(db)
+	interestRepo := repositories.NewInterestRepository(db)
+	allocationRepo := repositories.NewAllocationRepository(db)
+	priceAlertRepo := repositories.NewPriceAlertRepository(db)
+	householdRepo := repositories.NewHouseholdRepository(db)
+	delegationRepo := repositories.NewDelegationRepository(db)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	envelopeRepo := repositories.NewEnvelopeRepository(db)
+	spendingLimitRepo := repositories.NewSpendingLimitRepository(db)
 
 	// Initialize services
 	loggingService := services.NewLoggingService(loggingRepo)
-	authService := services.NewAuthService(userRepo, roleRepo, settingsRepo, loggingRepo, jobDispatcher, mail, sessionStore)
+	authService := services.NewAuthService(userRepo, roleRepo, householdRepo, delegationRepo, settingsRepo, loggingRepo, jobDispatcher, mail, sessionStore)
 	roleService := services.NewRolePermissionService(roleRepo, loggingRepo, jobDispatcher)
 	userService := services.NewUserService(userRepo, roleRepo, loggingRepo, jobDispatcher, mail)
 	accountService := services.NewAccountService(logger.Named("account_srv"), accountRepo, transactionRepo, settingsRepo, loggingRepo, savingsRepo, investmentRepo, jobDispatcher, priceFetcher)
-	transactionService := services.NewTransactionService(transactionRepo, accountRepo, settingsRepo, loggingRepo, savingsRepo, jobDispatcher)
+	transactionService := services.NewTransactionService(transactionRepo, accountRepo, settingsRepo, loggingRepo, savingsRepo, householdRepo, jobDispatcher)
 	settingsService := services.NewSettingsService(cfg, logger.Named("settings_srv"), settingsRepo, userRepo, loggingRepo, transactionRepo, jobDispatcher, sessionStore)
 	importService := services.NewImportService(importRepo, transactionRepo, accountRepo, investmentRepo, settingsRepo, loggingRepo, jobDispatcher)
 	exportService := services.NewExportService(exportRepo, transactionRepo, accountRepo, settingsRepo, loggingRepo, jobDispatcher)
@@ -94,34 +110,50 @@ func NewServiceContainer(cfg *config.Config, db *gorm.DB, rdb *redis.Client, log
 	notesService := services.NewNotesService(notesRepo, loggingRepo, jobDispatcher)
 	analyticsService := services.NewAnalyticsService(logger.Named("analytics_svc"), analyticsRepo, accountRepo, transactionRepo, settingsRepo, jobDispatcher)
 	backOfficeService := services.NewBackofficeService(logger.Named("backoffice_srv"), jobDispatcher, backOfficeRepo, investmentService, accountService, userService)
-	savingsService := services.NewSavingsService(savingsRepo, accountRepo, loggingRepo, jobDispatcher)
-	notificationService := services.NewNotificationService(notificationRepo)
 	notifDispatcher := queue_jobs.NewNotificationDispatcher(notificationRepo, jobDispatcher)
+	savingsService := services.NewSavingsService(savingsRepo, accountRepo, loggingRepo, transactionService, jobDispatcher, notifDispatcher)
+	interestService := services.NewInterestService(interestRepo, accountRepo, transactionRepo, loggingRepo, jobDispatcher)
+	allocationService := services.NewAllocationService(logger.Named("allocation_svc"), allocationRepo, investmentRepo, accountRepo, settingsRepo, analyticsRepo, loggingRepo, jobDispatcher)
+	priceAlertService := services.NewPriceAlertService(logger.Named("price_alert_svc"), priceAlertRepo, investmentRepo, accountRepo, analyticsRepo, settingsRepo, loggingRepo, jobDispatcher)
+	budgetService := services.NewBudgetService(budgetRepo, transactionRepo, analyticsRepo, loggingRepo, jobDispatcher)
+	envelopeService := services.NewEnvelopeService(envelopeRepo, transactionRepo, analyticsRepo, settingsRepo, loggingRepo, jobDispatcher)
+	spendingLimitService := services.NewSpendingLimitService(spendingLimitRepo, transactionRepo, analyticsRepo, loggingRepo, jobDispatcher, notifDispatcher)
+	householdService := services.NewHouseholdService(householdRepo, userRepo, roleRepo, accountRepo, loggingRepo, jobDispatcher, mail)
+	delegationService := services.NewDelegationService(delegationRepo, userRepo, roleRepo, accountRepo, loggingRepo, jobDispatcher, mail)
+	notificationService := services.NewNotificationService(notificationRepo)
 	hub := ws.NewHub(logger.Named("ws"))
 	sessionsService := services.NewSessionsService(sessionStore, hub)
 
 	return &ServiceContainer{
-		Config:              cfg,
-		DB:                  db,
-		SessionStore:        sessionStore,
-		BackofficeService:   backOfficeService,
-		AuthzService:        authzSvc,
-		AuthService:         authService,
-		UserService:         userService,
-		LoggingService:      loggingService,
-		AccountService:      accountService,
-		TransactionService:  transactionService,
-		SettingsService:     settingsService,
-		RoleService:         roleService,
-		ImportService:       importService,
-		ExportService:       exportService,
-		InvestmentService:   investmentService,
-		NotesService:        notesService,
-		AnalyticsService:    analyticsService,
-		SavingsService:      savingsService,
-		NotificationService: notificationService,
-		NotifDispatcher:     notifDispatcher,
-		SessionsService:     sessionsService,
-		Hub:                 hub,
+		Config:               cfg,
+		DB:                   db,
+		SessionStore:         sessionStore,
+		BackofficeService:    backOfficeService,
+		AuthzService:         authzSvc,
+		AuthService:          authService,
+		UserService:          userService,
+		LoggingService:       loggingService,
+		AccountService:       accountService,
+		TransactionService:   transactionService,
+		SettingsService:      settingsService,
+		RoleService:          roleService,
+		ImportService:        importService,
+		ExportService:        exportService,
+		InvestmentService:    investmentService,
+		NotesService:         notesService,
+		AnalyticsService:     analyticsService,
+		SavingsService:       savingsService,
+		InterestService:      interestService,
+		AllocationService:    allocationService,
+		PriceAlertService:    priceAlertService,
+		BudgetService:        budgetService,
+		EnvelopeService:      envelopeService,
+		SpendingLimitService: spendingLimitService,
+		HouseholdService:     householdService,
+		DelegationService:    delegationService,
+		NotificationService:  notificationService,
+		NotifDispatcher:      notifDispatcher,
+		SessionsService:      sessionsService,
+		Hub:                  hub,
 	}, nil
 }
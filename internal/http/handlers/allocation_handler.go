@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type AllocationHandler struct {
+	service services.AllocationServiceInterface
+	v       validators.Validator
+}
+
+func NewAllocationHandler(
+	service services.AllocationServiceInterface,
+	v validators.Validator,
+) *AllocationHandler {
+	return &AllocationHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+// Routes scope every endpoint to one investment account with ?account=, or to
+// the whole portfolio without it.
+func (h *AllocationHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("/targets", authz.RequireAllMW("view_data"), h.GetTargets)
+	apiGroup.PUT("/targets", authz.RequireAllMW("manage_data"), h.SaveTargets)
+	apiGroup.DELETE("/targets", authz.RequireAllMW("manage_data"), h.DeleteTargets)
+	apiGroup.GET("/drift", authz.RequireAllMW("view_data"), h.GetDrift)
+}
+
+func parseAccountQuery(c *gin.Context) (*int64, error) {
+	accStr := strings.TrimSpace(c.Query("account"))
+	if accStr == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(accStr, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (h *AllocationHandler) GetTargets(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accID, err := parseAccountQuery(c)
+	if err != nil {
+		utils.ErrorMessage(c, "param error", "account must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.service.FetchTargets(ctx, userID, accID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *AllocationHandler) SaveTargets(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.AllocationTargetsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.SaveTargets(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Save error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Target allocation saved", "Success", http.StatusOK)
+}
+
+func (h *AllocationHandler) DeleteTargets(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accID, err := parseAccountQuery(c)
+	if err != nil {
+		utils.ErrorMessage(c, "param error", "account must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteTargets(ctx, userID, accID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Target allocation removed", "Success", http.StatusOK)
+}
+
+// GetDrift proposes a full rebalance by default; ?contribution= switches to
+// spreading that much new cash over the underweight targets instead.
+func (h *AllocationHandler) GetDrift(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accID, err := parseAccountQuery(c)
+	if err != nil {
+		utils.ErrorMessage(c, "param error", "account must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	contribution := decimal.Zero
+	if raw := strings.TrimSpace(c.Query("contribution")); raw != "" {
+		contribution, err = decimal.NewFromString(raw)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "contribution must be a valid amount", http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	report, err := h.service.FetchDrift(ctx, userID, accID, contribution)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
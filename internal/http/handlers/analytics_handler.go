@@ -34,6 +34,9 @@ func NewAnalyticsHandler(
 func (h *AnalyticsHandler) Routes(ap *gin.RouterGroup) {
 	ap.GET("/networth", authz.RequireAllMW("view_basic_statistics"), h.NetWorthChart)
 	ap.GET("/asset/:id/chart", authz.RequireAllMW("view_basic_statistics"), h.AssetChart)
+	ap.GET("/asset/:id/performance", authz.RequireAllMW("view_basic_statistics"), h.AssetPerformance)
+	ap.GET("/performance", authz.RequireAllMW("view_basic_statistics"), h.PortfolioPerformance)
+	ap.GET("/currency-exposure", authz.RequireAllMW("view_basic_statistics"), h.CurrencyExposure)
 	ap.GET("/monthly-category-breakdown", authz.RequireAllMW("view_basic_statistics"), h.GetMonthlyCategoryBreakdown)
 	ap.GET("/yearly-cash-flow-breakdown", authz.RequireAllMW("view_basic_statistics"), h.GetYearlyCashFlowBreakdown)
 	ap.GET("/sankey", authz.RequireAllMW("view_basic_statistics"), h.GetYearlySankeyData)
@@ -45,6 +48,7 @@ func (h *AnalyticsHandler) Routes(ap *gin.RouterGroup) {
 	ap.GET("/categories/:id/average", authz.RequireAllMW("view_basic_statistics"), h.GetYearlyAverageForCategory)
 	ap.GET("/reports", authz.RequireAllMW("view_basic_statistics"), h.ListReports)
 	ap.POST("/reports/category", authz.RequireAllMW("view_basic_statistics"), h.GenerateCategoryReport)
+	ap.POST("/reports/capital-gains", authz.RequireAllMW("view_basic_statistics"), h.GenerateCapitalGainsReport)
 	ap.GET("/reports/:id/download", authz.RequireAllMW("view_basic_statistics"), h.DownloadReport)
 	ap.DELETE("/reports/:id", authz.RequireAllMW("manage_data"), h.DeleteReport)
 }
@@ -536,6 +540,37 @@ func (h *AnalyticsHandler) GenerateCategoryReport(c *gin.Context) {
 	c.JSON(http.StatusOK, report)
 }
 
+func (h *AnalyticsHandler) GenerateCapitalGainsReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req struct {
+		Year   int    `json:"year"`
+		Format string `json:"format"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+	if req.Year == 0 {
+		utils.ErrorMessage(c, "param error", "year is required", http.StatusBadRequest, nil)
+		return
+	}
+
+	params := models.CapitalGainsReportParams{
+		Year:   req.Year,
+		Format: strings.ToLower(strings.TrimSpace(req.Format)),
+	}
+
+	report, err := h.Service.GenerateCapitalGainsReport(ctx, userID, params)
+	if err != nil {
+		utils.ErrorMessage(c, "Failed to generate report", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 func (h *AnalyticsHandler) DownloadReport(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetInt64("user_id")
@@ -552,8 +587,11 @@ func (h *AnalyticsHandler) DownloadReport(c *gin.Context) {
 		return
 	}
 
-	filename := fmt.Sprintf("%s.xlsx", name)
+	filename := name
 	mime := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	if strings.HasSuffix(name, ".csv") {
+		mime = "text/csv"
+	}
 	c.Header("Content-Type", mime)
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 	c.Data(http.StatusOK, mime, data)
@@ -609,3 +647,75 @@ func (h *AnalyticsHandler) AssetChart(c *gin.Context) {
 
 	c.JSON(http.StatusOK, res)
 }
+
+func (h *AnalyticsHandler) AssetPerformance(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	assetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "param error", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	rangeKey := strings.ToLower(strings.TrimSpace(c.Query("range")))
+	if rangeKey == "" {
+		rangeKey = "ytd"
+	}
+
+	res, err := h.Service.FetchPerformance(ctx, userID, &assetID, nil, rangeKey)
+	if err != nil {
+		utils.ErrorMessage(c, "Failed to load performance", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// PortfolioPerformance covers a single investment account when ?account= is
+// given, and every investment holding otherwise.
+func (h *AnalyticsHandler) PortfolioPerformance(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var accID *int64
+	if accStr := strings.TrimSpace(c.Query("account")); accStr != "" {
+		v, err := strconv.ParseInt(accStr, 10, 64)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "account must be a valid integer", http.StatusBadRequest, err)
+			return
+		}
+		accID = &v
+	}
+
+	rangeKey := strings.ToLower(strings.TrimSpace(c.Query("range")))
+	if rangeKey == "" {
+		rangeKey = "ytd"
+	}
+
+	res, err := h.Service.FetchPerformance(ctx, userID, nil, accID, rangeKey)
+	if err != nil {
+		utils.ErrorMessage(c, "Failed to load performance", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AnalyticsHandler) CurrencyExposure(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	rangeKey := strings.ToLower(strings.TrimSpace(c.Query("range")))
+	if rangeKey == "" {
+		rangeKey = "ytd"
+	}
+
+	res, err := h.Service.FetchCurrencyExposure(ctx, userID, rangeKey)
+	if err != nil {
+		utils.ErrorMessage(c, "Failed to load currency exposure", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
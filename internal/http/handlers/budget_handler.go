@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetHandler struct {
+	service services.BudgetServiceInterface
+	v       validators.Validator
+}
+
+func NewBudgetHandler(
+	service services.BudgetServiceInterface,
+	v validators.Validator,
+) *BudgetHandler {
+	return &BudgetHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *BudgetHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetBudgets)
+	apiGroup.GET("/report", authz.RequireAllMW("view_data"), h.GetReport)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertBudget)
+	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateBudget)
+	apiGroup.DELETE("/:id", authz.RequireAllMW("manage_data"), h.DeleteBudget)
+
+	apiGroup.PUT("/:id/months", authz.RequireAllMW("manage_data"), h.SaveBudgetMonth)
+	apiGroup.DELETE("/:id/months/:month", authz.RequireAllMW("manage_data"), h.DeleteBudgetMonth)
+}
+
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchBudgets(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *BudgetHandler) GetReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	month := time.Now().UTC()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.Parse("2006-01-02", m)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "month must be YYYY-MM-DD", http.StatusBadRequest, err)
+			return
+		}
+		month = parsed
+	}
+
+	report, err := h.service.FetchBudgetReport(ctx, userID, month)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *BudgetHandler) InsertBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.CategoryBudgetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err := h.service.InsertBudget(ctx, userID, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Budget created", "Success", http.StatusOK)
+}
+
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.CategoryBudgetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err = h.service.UpdateBudget(ctx, userID, id, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Budget updated", "Success", http.StatusOK)
+}
+
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteBudget(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Budget deleted", "Success", http.StatusOK)
+}
+
+func (h *BudgetHandler) SaveBudgetMonth(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.CategoryBudgetMonthReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.SaveBudgetMonth(ctx, userID, id, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Budget month saved", "Success", http.StatusOK)
+}
+
+func (h *BudgetHandler) DeleteBudgetMonth(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	month, err := time.Parse("2006-01-02", c.Param("month"))
+	if err != nil {
+		utils.ErrorMessage(c, "param error", "month must be YYYY-MM-DD", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteBudgetMonth(ctx, userID, id, month); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Budget month removed", "Success", http.StatusOK)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DelegationHandler struct {
+	service services.DelegationServiceInterface
+	v       validators.Validator
+}
+
+func NewDelegationHandler(
+	service services.DelegationServiceInterface,
+	v validators.Validator,
+) *DelegationHandler {
+	return &DelegationHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *DelegationHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetDelegations)
+	apiGroup.GET("/received", authz.RequireAllMW("view_data"), h.GetReceivedDelegations)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertDelegation)
+	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateDelegation)
+	apiGroup.POST("/:id/revoke", authz.RequireAllMW("manage_data"), h.RevokeDelegation)
+}
+
+func (h *DelegationHandler) GetDelegations(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchDelegations(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *DelegationHandler) GetReceivedDelegations(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchReceivedDelegations(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *DelegationHandler) InsertDelegation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.AccessDelegationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.InsertDelegation(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Access delegated", "Success", http.StatusOK)
+}
+
+func (h *DelegationHandler) UpdateDelegation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.AccessDelegationUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.UpdateDelegation(ctx, userID, id, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Delegation updated", "Success", http.StatusOK)
+}
+
+func (h *DelegationHandler) RevokeDelegation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.RevokeDelegation(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Delegation revoked", "Success", http.StatusOK)
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnvelopeHandler struct {
+	service services.EnvelopeServiceInterface
+	v       validators.Validator
+}
+
+func NewEnvelopeHandler(
+	service services.EnvelopeServiceInterface,
+	v validators.Validator,
+) *EnvelopeHandler {
+	return &EnvelopeHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *EnvelopeHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("/mode", authz.RequireAllMW("view_data"), h.GetMode)
+	apiGroup.PUT("/mode", authz.RequireAllMW("manage_data"), h.EnableMode)
+	apiGroup.DELETE("/mode", authz.RequireAllMW("manage_data"), h.DisableMode)
+
+	apiGroup.GET("/month", authz.RequireAllMW("view_data"), h.GetMonth)
+	apiGroup.POST("/assign", authz.RequireAllMW("manage_data"), h.Assign)
+	apiGroup.POST("/move", authz.RequireAllMW("manage_data"), h.Move)
+	apiGroup.POST("/cover", authz.RequireAllMW("manage_data"), h.Cover)
+
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetEnvelopes)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertEnvelope)
+	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateEnvelope)
+	apiGroup.DELETE("/:id", authz.RequireAllMW("manage_data"), h.DeleteEnvelope)
+}
+
+func (h *EnvelopeHandler) GetMode(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	record, err := h.service.FetchEnvelopeBudget(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *EnvelopeHandler) EnableMode(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.EnvelopeModeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.EnableEnvelopeMode(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Envelope budgeting enabled", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) DisableMode(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	if err := h.service.DisableEnvelopeMode(ctx, userID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Envelope budgeting disabled", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) GetMonth(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	month := time.Now().UTC()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.Parse("2006-01-02", m)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "month must be YYYY-MM-DD", http.StatusBadRequest, err)
+			return
+		}
+		month = parsed
+	}
+
+	record, err := h.service.FetchMonth(ctx, userID, month)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *EnvelopeHandler) Assign(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.EnvelopeAssignReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.Assign(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Money assigned", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) Move(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.EnvelopeMoveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.Move(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Money moved", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) Cover(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.EnvelopeCoverReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.Cover(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Overspending covered", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) GetEnvelopes(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchEnvelopes(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *EnvelopeHandler) InsertEnvelope(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.EnvelopeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err := h.service.InsertEnvelope(ctx, userID, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Envelope created", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) UpdateEnvelope(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.EnvelopeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err = h.service.UpdateEnvelope(ctx, userID, id, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Envelope updated", "Success", http.StatusOK)
+}
+
+func (h *EnvelopeHandler) DeleteEnvelope(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteEnvelope(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Envelope deleted", "Success", http.StatusOK)
+}
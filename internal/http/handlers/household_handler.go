@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"net/http"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HouseholdHandler struct {
+	service services.HouseholdServiceInterface
+	v       validators.Validator
+}
+
+func NewHouseholdHandler(
+	service services.HouseholdServiceInterface,
+	v validators.Validator,
+) *HouseholdHandler {
+	return &HouseholdHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *HouseholdHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetHousehold)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.CreateHousehold)
+	apiGroup.PUT("/name", authz.RequireAllMW("manage_data"), h.RenameHousehold)
+	apiGroup.POST("/invitations", authz.RequireAllMW("manage_data"), h.InviteMember)
+	apiGroup.GET("/invitations/pending", authz.RequireAllMW("view_data"), h.GetPendingInvitations)
+	apiGroup.POST("/invitations/:id/accept", authz.RequireAllMW("manage_data"), h.AcceptInvitation)
+	apiGroup.DELETE("/members/:id", authz.RequireAllMW("manage_data"), h.RemoveMember)
+
+	apiGroup.GET("/shared-accounts", authz.RequireAllMW("view_data"), h.GetSharedAccounts)
+	apiGroup.GET("/accounts/:id/shares", authz.RequireAllMW("view_data"), h.GetAccountShares)
+	apiGroup.PUT("/accounts/:id/shares", authz.RequireAllMW("manage_data"), h.ShareAccount)
+	apiGroup.DELETE("/accounts/:id/shares/:user_id", authz.RequireAllMW("manage_data"), h.UnshareAccount)
+	apiGroup.PUT("/accounts/:id/ownership", authz.RequireAllMW("manage_data"), h.SetAccountOwnership)
+}
+
+func (h *HouseholdHandler) GetHousehold(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	record, err := h.service.FetchHousehold(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.HouseholdReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.CreateHousehold(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Household created", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) RenameHousehold(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.HouseholdReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.RenameHousehold(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Household renamed", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) InviteMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.HouseholdInviteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.InviteMember(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Invitation sent", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) GetPendingInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchPendingInvitations(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *HouseholdHandler) AcceptInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.AcceptInvitation(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Joined household", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	memberID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.RemoveMember(ctx, userID, memberID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Member removed", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) GetSharedAccounts(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchSharedAccounts(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *HouseholdHandler) GetAccountShares(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.service.FetchAccountShares(ctx, userID, accountID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *HouseholdHandler) ShareAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.AccountShareReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.ShareAccount(ctx, userID, accountID, &req); err != nil {
+		utils.ErrorMessage(c, "Save error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Account shared", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) UnshareAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	memberID, err := parseID(c, "user_id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.UnshareAccount(ctx, userID, accountID, memberID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Account share removed", "Success", http.StatusOK)
+}
+
+func (h *HouseholdHandler) SetAccountOwnership(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.AccountOwnershipReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.SetAccountOwnership(ctx, userID, accountID, &req); err != nil {
+		utils.ErrorMessage(c, "Save error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Ownership updated", "Success", http.StatusOK)
+}
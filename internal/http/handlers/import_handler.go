@@ -46,6 +46,7 @@ func (h *ImportHandler) Routes(apiGroup *gin.RouterGroup) {
 	apiGroup.POST("custom/savings", authz.RequireAllMW("manage_data"), h.TransferSavingsFromImport)
 	apiGroup.POST("custom/repayments", authz.RequireAllMW("manage_data"), h.TransferRepaymentsFromImport)
 	apiGroup.POST("custom/trades", authz.RequireAllMW("manage_data"), h.TransferInvestmentTrades)
+	apiGroup.POST("custom/broker", authz.RequireAllMW("manage_data"), h.ImportBrokerStatement)
 	apiGroup.DELETE("/:id", authz.RequireAllMW("manage_data"), h.DeleteImport)
 }
 
@@ -546,3 +547,43 @@ func (h *ImportHandler) TransferInvestmentTrades(c *gin.Context) {
 
 	utils.SuccessMessage(c, "Investments transferred successfully", "Success", http.StatusOK)
 }
+
+func (h *ImportHandler) ImportBrokerStatement(c *gin.Context) {
+
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 10<<20)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.ErrorMessage(c, "Invalid Request", "Missing file", http.StatusBadRequest, err)
+		return
+	}
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			utils.ErrorMessage(c, "Error occurred", "Failed to close file stream", http.StatusInternalServerError, err)
+			return
+		}
+	}(file)
+
+	var req models.BrokerImportReq
+	if err := json.Unmarshal([]byte(c.Request.FormValue("payload")), &req); err != nil {
+		utils.ErrorMessage(c, "Invalid Request", "Invalid payload JSON", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	result, err := h.Service.ImportBrokerStatement(ctx, userID, header.Filename, file, req)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
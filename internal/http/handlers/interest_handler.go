@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InterestHandler struct {
+	service services.InterestServiceInterface
+	v       validators.Validator
+}
+
+func NewInterestHandler(
+	service services.InterestServiceInterface,
+	v validators.Validator,
+) *InterestHandler {
+	return &InterestHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *InterestHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetConfigs)
+	apiGroup.GET("/accounts/:id", authz.RequireAllMW("view_data"), h.GetConfig)
+	apiGroup.GET("/accounts/:id/preview", authz.RequireAllMW("view_data"), h.PreviewInterest)
+	apiGroup.PUT("/accounts/:id", authz.RequireAllMW("manage_data"), h.UpsertConfig)
+	apiGroup.DELETE("/accounts/:id", authz.RequireAllMW("manage_data"), h.DeleteConfig)
+}
+
+func (h *InterestHandler) GetConfigs(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchConfigs(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *InterestHandler) GetConfig(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	record, err := h.service.FetchConfigByAccountID(ctx, userID, accountID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *InterestHandler) PreviewInterest(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := h.service.PreviewYearlyInterest(ctx, userID, accountID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *InterestHandler) UpsertConfig(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.AccountInterestConfigReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.UpsertConfig(ctx, userID, accountID, &req); err != nil {
+		utils.ErrorMessage(c, "Save error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Interest settings saved", "Success", http.StatusOK)
+}
+
+func (h *InterestHandler) DeleteConfig(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	accountID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteConfig(ctx, userID, accountID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Interest settings removed", "Success", http.StatusOK)
+}
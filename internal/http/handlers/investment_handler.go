@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 	"wealth-warden/internal/models"
 	"wealth-warden/internal/services"
 	"wealth-warden/pkg/authz"
@@ -35,11 +38,18 @@ func (h *InvestmentHandler) Routes(ap *gin.RouterGroup) {
 	ap.GET("trades", authz.RequireAllMW("view_data"), h.GetInvestmentTradesPaginated)
 	ap.GET("trades/:id", authz.RequireAllMW("view_data"), h.GetInvestmentTradeByID)
 	ap.GET("assets/:id/income", authz.RequireAllMW("view_data"), h.GetInvestmentIncomeByAsset)
+	ap.GET("assets/:id/corporate-actions", authz.RequireAllMW("view_data"), h.GetCorporateActions)
+	ap.GET("assets/:id/prices", authz.RequireAllMW("view_data"), h.GetAssetPrices)
 	ap.PUT("", authz.RequireAllMW("manage_data"), h.InsertInvestmentAsset)
 	ap.PUT("trades", authz.RequireAllMW("manage_data"), h.InsertInvestmentTrade)
 	ap.PUT(":id", authz.RequireAllMW("manage_data"), h.UpdateInvestmentAsset)
 	ap.PUT("trades/:id", authz.RequireAllMW("manage_data"), h.UpdateInvestmentTrade)
 	ap.PUT("income", authz.RequireAllMW("manage_data"), h.CreateInvestmentIncome)
+	ap.PUT("assets/:id/corporate-actions", authz.RequireAllMW("manage_data"), h.ApplyCorporateAction)
+	ap.PUT("assets/:id/prices", authz.RequireAllMW("manage_data"), h.InsertManualPrice)
+	ap.DELETE("assets/:id/prices/:date", authz.RequireAllMW("manage_data"), h.DeleteManualPrice)
+	ap.DELETE("assets/:id/corporate-actions/:actionId", authz.RequireAllMW("manage_data"), h.RevertCorporateAction)
+	ap.POST("prices/upload", authz.RequireAllMW("manage_data"), h.UploadPrices)
 	ap.DELETE(":id", authz.RequireAllMW("manage_data"), h.DeleteInvestmentAsset)
 	ap.DELETE("trades/:id", authz.RequireAllMW("manage_data"), h.DeleteInvestmentTrade)
 	ap.DELETE("income/:id", authz.RequireAllMW("manage_data"), h.DeleteInvestmentIncome)
@@ -50,6 +60,13 @@ func (h *InvestmentHandler) Routes(ap *gin.RouterGroup) {
 	ap.POST("tax-brackets/copy", authz.RequireAllMW("manage_data"), h.CopyTaxBrackets)
 	ap.GET("tax-settings", authz.RequireAllMW("view_data"), h.GetTaxSettings)
 	ap.PUT("tax-settings", authz.RequireAllMW("manage_data"), h.SaveTaxSettings)
+	ap.GET("tax-hints", authz.RequireAllMW("view_data"), h.GetTaxHints)
+	ap.GET("benchmarks", authz.RequireAllMW("view_data"), h.GetBenchmarks)
+	ap.PUT("benchmarks", authz.RequireAllMW("manage_data"), h.InsertBenchmark)
+	ap.DELETE("benchmarks/:id", authz.RequireAllMW("manage_data"), h.DeleteBenchmark)
+	ap.GET("transfers", authz.RequireAllMW("view_data"), h.GetTransfers)
+	ap.PUT("transfers", authz.RequireAllMW("manage_data"), h.TransferHoldings)
+	ap.DELETE("transfers/:id", authz.RequireAllMW("manage_data"), h.DeleteTransfer)
 }
 
 func (h *InvestmentHandler) GetInvestmentAssetsPaginated(c *gin.Context) {
@@ -267,7 +284,7 @@ func (h *InvestmentHandler) UpdateInvestmentAsset(c *gin.Context) {
 		return
 	}
 
-	var record *models.InvestmentAssetReq
+	var record *models.InvestmentAssetUpdateReq
 
 	if err := c.ShouldBindJSON(&record); err != nil {
 		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
@@ -572,6 +589,29 @@ func (h *InvestmentHandler) GetTaxSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, record)
 }
 
+func (h *InvestmentHandler) GetTaxHints(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	window := 30
+	if raw := c.Query("window"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > 365 {
+			utils.ErrorMessage(c, "param error", "window must be between 1 and 365 days", http.StatusBadRequest, err)
+			return
+		}
+		window = v
+	}
+
+	record, err := h.Service.FetchTaxHints(ctx, userID, window)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
 func (h *InvestmentHandler) SaveTaxSettings(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetInt64("user_id")
@@ -589,3 +629,288 @@ func (h *InvestmentHandler) SaveTaxSettings(c *gin.Context) {
 
 	utils.SuccessMessage(c, "Settings saved", "Success", http.StatusOK)
 }
+
+func (h *InvestmentHandler) GetCorporateActions(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.Service.FetchCorporateActions(ctx, userID, id)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *InvestmentHandler) ApplyCorporateAction(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.CorporateActionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.Service.ApplyCorporateAction(ctx, userID, id, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Corporate action applied", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) RevertCorporateAction(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	actionID, err := strconv.ParseInt(c.Param("actionId"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "action id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.RevertCorporateAction(ctx, userID, id, actionID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Corporate action undone", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) GetBenchmarks(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.Service.FetchBenchmarks(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *InvestmentHandler) InsertBenchmark(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.BenchmarkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.Service.InsertBenchmark(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Benchmark added", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) DeleteBenchmark(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.DeleteBenchmark(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Benchmark removed", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) GetAssetPrices(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.Service.FetchAssetPrices(ctx, userID, id)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *InvestmentHandler) InsertManualPrice(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.ManualPriceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.Service.InsertManualPrice(ctx, userID, id, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Price saved", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) DeleteManualPrice(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	asOf, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "date must be in YYYY-MM-DD format", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.DeleteManualPrice(ctx, userID, id, asOf); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Price deleted", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) UploadPrices(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 10<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorMessage(c, "Invalid upload", "file is required", http.StatusBadRequest, err)
+		return
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorMessage(c, "Invalid upload", "cannot open uploaded file", http.StatusBadRequest, err)
+		return
+	}
+	defer func(f multipart.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}(f)
+
+	result, err := h.Service.UploadPrices(ctx, userID, f)
+	if err != nil {
+		utils.ErrorMessage(c, "Upload error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *InvestmentHandler) GetTransfers(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.Service.FetchTransfers(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *InvestmentHandler) TransferHoldings(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.InvestmentTransferReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.Service.TransferHoldings(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Holdings transferred", "Success", http.StatusOK)
+}
+
+func (h *InvestmentHandler) DeleteTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorMessage(c, "Error occurred", "id must be a valid integer", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.DeleteTransfer(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Transfer deleted", "Success", http.StatusOK)
+}
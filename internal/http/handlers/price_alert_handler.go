@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceAlertHandler struct {
+	service services.PriceAlertServiceInterface
+	v       validators.Validator
+}
+
+func NewPriceAlertHandler(
+	service services.PriceAlertServiceInterface,
+	v validators.Validator,
+) *PriceAlertHandler {
+	return &PriceAlertHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *PriceAlertHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetRules)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertRule)
+	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateRule)
+	apiGroup.DELETE("/:id", authz.RequireAllMW("manage_data"), h.DeleteRule)
+}
+
+func (h *PriceAlertHandler) GetRules(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchRules(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *PriceAlertHandler) InsertRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.PriceAlertRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err := h.service.InsertRule(ctx, userID, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Alert created", "Success", http.StatusOK)
+}
+
+func (h *PriceAlertHandler) UpdateRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.PriceAlertRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err = h.service.UpdateRule(ctx, userID, id, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Alert updated", "Success", http.StatusOK)
+}
+
+func (h *PriceAlertHandler) DeleteRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteRule(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Alert deleted", "Success", http.StatusOK)
+}
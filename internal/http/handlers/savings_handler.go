@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 	"wealth-warden/internal/models"
 	"wealth-warden/internal/services"
 	"wealth-warden/pkg/authz"
@@ -30,6 +31,11 @@ func NewSavingsHandler(
 
 func (h *SavingsHandler) Routes(apiGroup *gin.RouterGroup) {
 	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetGoals)
+	apiGroup.GET("/reconciliation", authz.RequireAllMW("view_data"), h.GetReconciliation)
+	apiGroup.GET("/budget", authz.RequireAllMW("view_data"), h.GetBudget)
+	apiGroup.GET("/budget/plan", authz.RequireAllMW("view_data"), h.GetBudgetPlan)
+	apiGroup.PUT("/budget", authz.RequireAllMW("manage_data"), h.SaveBudget)
+	apiGroup.DELETE("/budget", authz.RequireAllMW("manage_data"), h.DeleteBudget)
 	apiGroup.GET("/:id", authz.RequireAllMW("view_data"), h.GetGoalByID)
 	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertGoal)
 	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateGoal)
@@ -38,6 +44,13 @@ func (h *SavingsHandler) Routes(apiGroup *gin.RouterGroup) {
 	apiGroup.GET("/:id/contributions", authz.RequireAllMW("view_data"), h.GetContributions)
 	apiGroup.PUT("/:id/contributions", authz.RequireAllMW("manage_data"), h.InsertContribution)
 	apiGroup.DELETE("/:id/contributions/:contrib_id", authz.RequireAllMW("manage_data"), h.DeleteContribution)
+
+	apiGroup.GET("/:id/cycles", authz.RequireAllMW("view_data"), h.GetCycles)
+	apiGroup.POST("/:id/settle", authz.RequireAllMW("manage_data"), h.SettleGoal)
+	apiGroup.POST("/:id/pay", authz.RequireAllMW("manage_data"), h.PayGoal)
+
+	apiGroup.GET("/:id/milestones", authz.RequireAllMW("view_data"), h.GetMilestones)
+	apiGroup.PUT("/:id/milestones", authz.RequireAllMW("manage_data"), h.SaveMilestones)
 }
 
 func (h *SavingsHandler) GetGoals(c *gin.Context) {
@@ -53,6 +66,90 @@ func (h *SavingsHandler) GetGoals(c *gin.Context) {
 	c.JSON(http.StatusOK, records)
 }
 
+func (h *SavingsHandler) GetReconciliation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchReconciliation(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *SavingsHandler) GetBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	record, err := h.service.FetchBudget(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *SavingsHandler) GetBudgetPlan(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	month := time.Now().UTC()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.Parse("2006-01-02", m)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "month must be YYYY-MM-DD", http.StatusBadRequest, err)
+			return
+		}
+		month = parsed
+	}
+
+	plan, err := h.service.PlanBudget(ctx, userID, month)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *SavingsHandler) SaveBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.SavingsBudgetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.SaveBudget(ctx, userID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Savings budget saved", "Success", http.StatusOK)
+}
+
+func (h *SavingsHandler) DeleteBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	if err := h.service.DeleteBudget(ctx, userID); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Savings budget deleted", "Success", http.StatusOK)
+}
+
 func (h *SavingsHandler) GetGoalByID(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetInt64("user_id")
@@ -156,7 +253,17 @@ func (h *SavingsHandler) GetContributions(c *gin.Context) {
 
 	p := utils.GetPaginationParams(c.Request.URL.Query())
 
-	records, paginator, err := h.service.FetchContributionsPaginated(ctx, userID, goalID, p)
+	var cycle *int
+	if raw := c.Query("cycle"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.ErrorMessage(c, "param error", "cycle must be a number", http.StatusBadRequest, err)
+			return
+		}
+		cycle = &n
+	}
+
+	records, paginator, err := h.service.FetchContributionsPaginated(ctx, userID, goalID, cycle, p)
 	if err != nil {
 		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
 		return
@@ -233,3 +340,128 @@ func parseID(c *gin.Context, param string) (int64, error) {
 	}
 	return strconv.ParseInt(s, 10, 64)
 }
+
+func (h *SavingsHandler) GetCycles(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	goalID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.service.FetchCycles(ctx, userID, goalID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *SavingsHandler) SettleGoal(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	goalID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.SavingGoalSettleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.SettleRecurringGoal(ctx, userID, goalID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Goal cycle settled", "Success", http.StatusOK)
+}
+
+func (h *SavingsHandler) PayGoal(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	goalID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.SavingGoalPayReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if _, err := h.service.PayRecurringGoal(ctx, userID, goalID, &req); err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Goal bill paid", "Success", http.StatusOK)
+}
+
+func (h *SavingsHandler) GetMilestones(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	goalID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := h.service.FetchMilestones(ctx, userID, goalID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *SavingsHandler) SaveMilestones(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	goalID, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.SavingGoalMilestonesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	if err := h.service.SaveMilestones(ctx, userID, goalID, &req); err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Goal milestones saved", "Success", http.StatusOK)
+}
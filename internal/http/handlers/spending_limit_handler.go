@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/services"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/utils"
+	"wealth-warden/pkg/validators"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SpendingLimitHandler struct {
+	service services.SpendingLimitServiceInterface
+	v       validators.Validator
+}
+
+func NewSpendingLimitHandler(
+	service services.SpendingLimitServiceInterface,
+	v validators.Validator,
+) *SpendingLimitHandler {
+	return &SpendingLimitHandler{
+		service: service,
+		v:       v,
+	}
+}
+
+func (h *SpendingLimitHandler) Routes(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("", authz.RequireAllMW("view_data"), h.GetLimits)
+	apiGroup.PUT("", authz.RequireAllMW("manage_data"), h.InsertLimit)
+	apiGroup.PUT("/:id", authz.RequireAllMW("manage_data"), h.UpdateLimit)
+	apiGroup.DELETE("/:id", authz.RequireAllMW("manage_data"), h.DeleteLimit)
+}
+
+func (h *SpendingLimitHandler) GetLimits(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	records, err := h.service.FetchLimits(ctx, userID)
+	if err != nil {
+		utils.ErrorMessage(c, "Fetch error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+func (h *SpendingLimitHandler) InsertLimit(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	var req models.SpendingLimitReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err := h.service.InsertLimit(ctx, userID, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Create error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Spending limit created", "Success", http.StatusOK)
+}
+
+func (h *SpendingLimitHandler) UpdateLimit(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.SpendingLimitReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorMessage(c, "Invalid JSON", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.v.ValidateStruct(req); err != nil {
+		utils.ValidationFailed(c, err.Error(), err)
+		return
+	}
+
+	_, err = h.service.UpdateLimit(ctx, userID, id, &req)
+	if err != nil {
+		utils.ErrorMessage(c, "Update error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Spending limit updated", "Success", http.StatusOK)
+}
+
+func (h *SpendingLimitHandler) DeleteLimit(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		utils.ErrorMessage(c, "param error", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteLimit(ctx, userID, id); err != nil {
+		utils.ErrorMessage(c, "Delete error", err.Error(), http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.SuccessMessage(c, "Spending limit deleted", "Success", http.StatusOK)
+}
@@ -51,6 +51,14 @@ func (r *RouteInitializerHTTP) initV1Routes(_v1 *gin.RouterGroup, wm *middleware
 	notesHandler := httpHandlers.NewNotesHandler(r.Container.NotesService, validator)
 	analyticsHandler := httpHandlers.NewAnalyticsHandler(r.Container.AnalyticsService, validator)
 	savingsHandler := httpHandlers.NewSavingsHandler(r.Container.SavingsService, validator)
+	interestHandler := httpHandlers.NewInterestHandler(r.Container.InterestService, validator)
+	allocationHandler := httpHandlers.NewAllocationHandler(r.Container.AllocationService, validator)
+	priceAlertHandler := httpHandlers.NewPriceAlertHandler(r.Container.PriceAlertService, validator)
+	budgetHandler := httpHandlers.NewBudgetHandler(r.Container.BudgetService, validator)
+	envelopeHandler := httpHandlers.NewEnvelopeHandler(r.Container.EnvelopeService, validator)
+	spendingLimitHandler := httpHandlers.NewSpendingLimitHandler(r.Container.SpendingLimitService, validator)
+	householdHandler := httpHandlers.NewHouseholdHandler(r.Container.HouseholdService, validator)
+	delegationHandler := httpHandlers.NewDelegationHandler(r.Container.DelegationService, validator)
 	notificationHandler := httpHandlers.NewNotificationHandler(r.Container.NotificationService)
 	websocketHandler := httpHandlers.NewWebsocketHandler(r.Container.Hub, r.Container.Config)
 	sessionsHandler := httpHandlers.NewSessionsHandler(r.Container.SessionsService)
@@ -65,6 +73,7 @@ func (r *RouteInitializerHTTP) initV1Routes(_v1 *gin.RouterGroup, wm *middleware
 	// Auth + Permission gated routes
 	protected := authenticated.Group("",
 		middleware.InjectPerms(r.Container.AuthzService),
+		middleware.InjectDelegation(r.Container.AuthzService, r.Container.DelegationService),
 	)
 
 	// Public routes
@@ -80,7 +89,9 @@ func (r *RouteInitializerHTTP) initV1Routes(_v1 *gin.RouterGroup, wm *middleware
 	backOfficeHandler.Routes(protected.Group("/backoffice"))
 	accountHandler.Routes(protected.Group("/accounts"))
 	analyticsHandler.Routes(protected.Group("/analytics"))
+	delegationHandler.Routes(protected.Group("/delegations"))
 	exportHandler.Routes(protected.Group("/exports"))
+	householdHandler.Routes(protected.Group("/households"))
 	importHandler.Routes(protected.Group("/imports"))
 	investmentHandler.Routes(protected.Group("/investments"))
 	loggingHandler.Routes(protected.Group("/logs"))
@@ -88,6 +99,12 @@ func (r *RouteInitializerHTTP) initV1Routes(_v1 *gin.RouterGroup, wm *middleware
 	roleHandler.Routes(protected.Group("/users/roles"))
 	settingsHandler.Routes(protected.Group("/settings"))
 	savingsHandler.Routes(protected.Group("/savings"))
+	interestHandler.Routes(protected.Group("/interest"))
+	allocationHandler.Routes(protected.Group("/allocation"))
+	priceAlertHandler.Routes(protected.Group("/price-alerts"))
+	budgetHandler.Routes(protected.Group("/budgets"))
+	envelopeHandler.Routes(protected.Group("/envelopes"))
+	spendingLimitHandler.Routes(protected.Group("/spending-limits"))
 	notificationHandler.Routes(protected.Group("/notifications"))
 	transactionHandler.Routes(protected.Group("/transactions"))
 	userHandler.Routes(protected.Group("/users"))
@@ -24,6 +24,12 @@ const (
 	jobNameTemplates            = "templates-job"
 	jobNameSavingsGoalFund      = "savings-goal-fund-job"
 	jobNameAssetPriceSync       = "asset-price-sync-job"
+	jobNameInterestAccrual      = "interest-accrual-job"
+	jobNameAllocationDrift      = "allocation-drift-job"
+	jobNameBondCoupon           = "bond-coupon-job"
+	jobNameTaxFreeLot           = "tax-free-lot-job"
+	jobNameSavingGoalTrack      = "saving-goal-track-job"
+	jobNameSpendingLimitDigest  = "spending-limit-digest-job"
 )
 
 type Scheduler struct {
@@ -43,6 +49,12 @@ type SchedulerFlags struct {
 	StartAssetPriceSyncImmediately       bool
 	StartAssetHistoryBackfillImmediately bool
 	StartSavingsGoalFundImmediately      bool
+	StartInterestAccrualImmediately      bool
+	StartAllocationDriftImmediately      bool
+	StartBondCouponImmediately           bool
+	StartTaxFreeLotImmediately           bool
+	StartSavingGoalTrackImmediately      bool
+	StartSpendingLimitDigestImmediately  bool
 }
 
 func FlagsFromConfig(cfg config.SchedulerConfig) SchedulerFlags {
@@ -59,6 +71,18 @@ func FlagsFromConfig(cfg config.SchedulerConfig) SchedulerFlags {
 			flags.StartAssetHistoryBackfillImmediately = true
 		case "savings_goal_fund":
 			flags.StartSavingsGoalFundImmediately = true
+		case "interest_accrual":
+			flags.StartInterestAccrualImmediately = true
+		case "allocation_drift":
+			flags.StartAllocationDriftImmediately = true
+		case "bond_coupon":
+			flags.StartBondCouponImmediately = true
+		case "tax_free_lot":
+			flags.StartTaxFreeLotImmediately = true
+		case "saving_goal_track":
+			flags.StartSavingGoalTrackImmediately = true
+		case "spending_limit_digest":
+			flags.StartSpendingLimitDigestImmediately = true
 		}
 	}
 	return flags
@@ -182,6 +206,36 @@ func (s *Scheduler) registerJobs() error {
 		return err
 	}
 
+	err = s.registerInterestAccrualJob()
+	if err != nil {
+		return err
+	}
+
+	err = s.registerAllocationDriftJob()
+	if err != nil {
+		return err
+	}
+
+	err = s.registerBondCouponJob()
+	if err != nil {
+		return err
+	}
+
+	err = s.registerTaxFreeLotJob()
+	if err != nil {
+		return err
+	}
+
+	err = s.registerSavingGoalTrackJob()
+	if err != nil {
+		return err
+	}
+
+	err = s.registerSpendingLimitDigestJob()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -282,12 +336,12 @@ func (s *Scheduler) registerTemplateAndFundSavingsJobs() error {
 func (s *Scheduler) registerAssetPriceSyncJob() error {
 
 	logger := s.logger.Named(jobNameAssetPriceSync)
-	client, err := finance.NewPriceFetchClient(s.container.Config.FinanceAPIBaseURL)
+	client, err := finance.NewPriceFetcher(s.container.Config)
 	if err != nil {
 		logger.Warn("Failed to create price fetch client", zap.Error(err))
 	}
 
-	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.container.InvestmentService, s.container.AccountService, s.container.DB, client, s.container.NotifDispatcher, s.concurrentWorkers)
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.container.InvestmentService, s.container.AccountService, s.container.PriceAlertService, s.container.DB, client, s.container.NotifDispatcher, s.concurrentWorkers)
 
 	var opts []gocron.JobOption
 	if s.flags.StartAssetPriceSyncImmediately {
@@ -313,3 +367,174 @@ func (s *Scheduler) registerAssetPriceSyncJob() error {
 	)
 	return err
 }
+
+func (s *Scheduler) registerInterestAccrualJob() error {
+
+	logger := s.logger.Named(jobNameInterestAccrual)
+	job := scheduler_jobs.NewInterestAccrualJob(logger, s.container, s.container.NotifDispatcher, s.concurrentWorkers)
+
+	var opts []gocron.JobOption
+	if s.flags.StartInterestAccrualImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	// Runs after the balance backfill and templates so yesterday's balances are final
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(0, 20, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting interest accrual ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameInterestAccrual, job.Run); err != nil {
+				logger.Error("Interest accrual failed", zap.Error(err))
+			} else {
+				logger.Info("Interest accrual completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
+
+func (s *Scheduler) registerAllocationDriftJob() error {
+
+	logger := s.logger.Named(jobNameAllocationDrift)
+	job := scheduler_jobs.NewAllocationDriftJob(logger, s.container, s.container.NotifDispatcher)
+
+	var opts []gocron.JobOption
+	if s.flags.StartAllocationDriftImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	// Runs once a day; prices come from the latest asset price sync
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(7, 0, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting allocation drift check ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameAllocationDrift, job.Run); err != nil {
+				logger.Error("Allocation drift check failed", zap.Error(err))
+			} else {
+				logger.Info("Allocation drift check completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
+
+func (s *Scheduler) registerBondCouponJob() error {
+
+	logger := s.logger.Named(jobNameBondCoupon)
+	job := scheduler_jobs.NewBondCouponJob(logger, s.container, s.container.NotifDispatcher)
+
+	var opts []gocron.JobOption
+	if s.flags.StartBondCouponImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(0, 30, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting bond coupon check ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameBondCoupon, job.Run); err != nil {
+				logger.Error("Bond coupon check failed", zap.Error(err))
+			} else {
+				logger.Info("Bond coupon check completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
+
+func (s *Scheduler) registerTaxFreeLotJob() error {
+
+	logger := s.logger.Named(jobNameTaxFreeLot)
+	job := scheduler_jobs.NewTaxFreeLotJob(logger, s.container, s.container.NotifDispatcher)
+
+	var opts []gocron.JobOption
+	if s.flags.StartTaxFreeLotImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(7, 10, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting tax-free lot check ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameTaxFreeLot, job.Run); err != nil {
+				logger.Error("Tax-free lot check failed", zap.Error(err))
+			} else {
+				logger.Info("Tax-free lot check completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
+
+func (s *Scheduler) registerSavingGoalTrackJob() error {
+
+	logger := s.logger.Named(jobNameSavingGoalTrack)
+	job := scheduler_jobs.NewSavingGoalTrackJob(logger, s.container, s.container.NotifDispatcher)
+
+	var opts []gocron.JobOption
+	if s.flags.StartSavingGoalTrackImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	// After the 00:10 auto-fund run, so this month's contributions count
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(7, 20, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting savings goal schedule check ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameSavingGoalTrack, job.Run); err != nil {
+				logger.Error("Savings goal schedule check failed", zap.Error(err))
+			} else {
+				logger.Info("Savings goal schedule check completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
+
+func (s *Scheduler) registerSpendingLimitDigestJob() error {
+
+	logger := s.logger.Named(jobNameSpendingLimitDigest)
+	job := scheduler_jobs.NewSpendingLimitDigestJob(logger, s.container, s.container.NotifDispatcher)
+
+	var opts []gocron.JobOption
+	if s.flags.StartSpendingLimitDigestImmediately {
+		opts = append(opts, gocron.WithStartAt(gocron.WithStartImmediately()))
+	}
+
+	_, err := s.scheduler.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(7, 30, 0))),
+		gocron.NewTask(func() {
+			logger.Info("Starting spending limit digest ...")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			if err := s.runJob(ctx, jobNameSpendingLimitDigest, job.Run); err != nil {
+				logger.Error("Spending limit digest failed", zap.Error(err))
+			} else {
+				logger.Info("Spending limit digest completed")
+			}
+		}),
+		opts...,
+	)
+	return err
+}
@@ -0,0 +1,85 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type AllocationDriftJob struct {
+	logger          *zap.Logger
+	container       *bootstrap.ServiceContainer
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewAllocationDriftJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher) *AllocationDriftJob {
+	return &AllocationDriftJob{
+		logger:          logger,
+		container:       container,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+func (j *AllocationDriftJob) Run(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	scopes, err := j.container.AllocationService.FetchTargetScopes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get allocation targets: %w", err)
+	}
+
+	if len(scopes) == 0 {
+		j.logger.Info("No target allocations to check")
+		return nil
+	}
+
+	alerted := 0
+	for _, scope := range scopes {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		report, err := j.container.AllocationService.FetchDrift(ctx, scope.UserID, scope.AccountID, decimal.Zero)
+		if err != nil {
+			j.logger.Error("Failed to compute allocation drift", zap.Int64("userID", scope.UserID), zap.Error(err))
+			continue
+		}
+
+		breached, err := j.container.AllocationService.MarkDriftAlerts(ctx, scope.UserID, report, today)
+		if err != nil {
+			j.logger.Error("Failed to record allocation drift", zap.Int64("userID", scope.UserID), zap.Error(err))
+			continue
+		}
+		if len(breached) == 0 || j.notifDispatcher == nil {
+			continue
+		}
+
+		lines := make([]string, len(breached))
+		for i, b := range breached {
+			lines[i] = fmt.Sprintf("%s: %s%% (target %s%% ± %s%%)",
+				b.Label, b.CurrentPercent.StringFixed(2), b.TargetPercent.StringFixed(2), b.TolerancePercent.StringFixed(2))
+		}
+
+		title := "Portfolio drifted from its target allocation"
+		if scope.AccountID != nil {
+			title = "Account drifted from its target allocation"
+		}
+		_ = j.notifDispatcher.Dispatch(ctx, scope.UserID, title, strings.Join(lines, ",\n"), models.NotificationTypeWarning)
+		alerted++
+	}
+
+	j.logger.Info("Allocation drift check completed",
+		zap.Int("checked", len(scopes)),
+		zap.Int("alerted", alerted))
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type AllocationDriftJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+}
+
+func TestAllocationDriftJobSuite(t *testing.T) {
+	suite.Run(t, new(AllocationDriftJobTestSuite))
+}
+
+func (s *AllocationDriftJobTestSuite) createAsset(accID int64, ticker string, t models.InvestmentType, value int64) models.InvestmentAsset {
+	price := decimal.NewFromInt(100)
+	asset := models.InvestmentAsset{
+		AccountID:      accID,
+		UserID:         1,
+		InvestmentType: t,
+		Name:           ticker,
+		Ticker:         ticker,
+		Quantity:       decimal.NewFromInt(value / 100),
+		CurrentValue:   decimal.NewFromInt(value),
+		CurrentPrice:   &price,
+		Currency:       "EUR",
+	}
+	s.Require().NoError(s.TC.DB.Omit("Account").Create(&asset).Error)
+	return asset
+}
+
+func (s *AllocationDriftJobTestSuite) setupAccount() int64 {
+	initial := decimal.NewFromInt(10000)
+	accID, err := s.TC.App.AccountService.InsertAccount(s.Ctx, 1, &models.AccountReq{
+		Name:          s.T().Name(),
+		AccountTypeID: 5,
+		Balance:       &initial,
+		OpenedAt:      time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	s.Require().NoError(err)
+	return accID
+}
+
+func (s *AllocationDriftJobTestSuite) saveTargets(accID int64) {
+	etf, crypto := models.InvestmentETF, models.InvestmentCrypto
+	err := s.TC.App.AllocationService.SaveTargets(s.Ctx, 1, &models.AllocationTargetsReq{
+		AccountID: &accID,
+		Kind:      models.AllocationByInvestmentType,
+		Targets: []models.AllocationTargetReq{
+			{InvestmentType: &etf, TargetPercent: decimal.NewFromInt(70)},
+			{InvestmentType: &crypto, TargetPercent: decimal.NewFromInt(30)},
+		},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *AllocationDriftJobTestSuite) runJob() {
+	job := scheduler_jobs.NewAllocationDriftJob(zaptest.NewLogger(s.T()), s.TC.App, nil)
+	s.Require().NoError(job.Run(s.Ctx))
+}
+
+func (s *AllocationDriftJobTestSuite) targets(accID int64) []models.AllocationTarget {
+	records, err := s.TC.App.AllocationService.FetchTargets(s.Ctx, 1, &accID)
+	s.Require().NoError(err)
+	return records
+}
+
+// A bucket outside its band is flagged once and stays flagged with the same date.
+func (s *AllocationDriftJobTestSuite) TestDrift_FlagsBreachOnce() {
+	accID := s.setupAccount()
+	s.createAsset(accID, "VWCE", models.InvestmentETF, 5000)
+	s.createAsset(accID, "BTC", models.InvestmentCrypto, 5000)
+	s.saveTargets(accID)
+
+	s.runJob()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, t := range s.targets(accID) {
+		s.Require().NotNil(t.OutOfBandSince, string(*t.InvestmentType))
+		s.True(t.OutOfBandSince.UTC().Equal(today))
+	}
+
+	breached, err := s.TC.App.AllocationService.MarkDriftAlerts(s.Ctx, 1, &models.AllocationReport{AccountID: &accID}, today)
+	s.Require().NoError(err)
+	s.Empty(breached)
+}
+
+// Holdings back inside the band clear the flag.
+func (s *AllocationDriftJobTestSuite) TestDrift_ClearsWhenBackInBand() {
+	accID := s.setupAccount()
+	etf := s.createAsset(accID, "VWCE", models.InvestmentETF, 5000)
+	s.createAsset(accID, "BTC", models.InvestmentCrypto, 3000)
+	s.saveTargets(accID)
+
+	s.runJob()
+	s.Require().NotNil(s.targets(accID)[0].OutOfBandSince)
+
+	s.Require().NoError(s.TC.DB.Model(&models.InvestmentAsset{}).
+		Where("id = ?", etf.ID).
+		Update("current_value", decimal.NewFromInt(7000)).Error)
+
+	s.runJob()
+	for _, t := range s.targets(accID) {
+		s.Nil(t.OutOfBandSince)
+	}
+}
+
+func (s *AllocationDriftJobTestSuite) TestDrift_ReportSuggestsTrades() {
+	accID := s.setupAccount()
+	s.createAsset(accID, "VWCE", models.InvestmentETF, 6000)
+	s.createAsset(accID, "BTC", models.InvestmentCrypto, 4000)
+	s.saveTargets(accID)
+
+	report, err := s.TC.App.AllocationService.FetchDrift(s.Ctx, 1, &accID, decimal.Zero)
+	s.Require().NoError(err)
+	s.Require().Len(report.Buckets, 2)
+	s.Equal("etf", report.Buckets[0].Key)
+	s.True(report.Buckets[0].Suggested.Equal(decimal.NewFromInt(1000)), report.Buckets[0].Suggested.String())
+	s.Require().Len(report.Buckets[0].Trades, 1)
+	s.True(report.Buckets[0].Trades[0].Quantity.Equal(decimal.NewFromInt(10)))
+}
@@ -50,19 +50,24 @@ func (j *AssetPriceHistoryBackfillJob) Run(ctx context.Context) error {
 		JOIN investment_trades it ON it.asset_id = ia.id
 		JOIN accounts a ON a.id = ia.account_id
 		WHERE a.is_active = TRUE AND a.closed_at IS NULL
+		  AND ia.manual_pricing = FALSE
 		GROUP BY ia.id, ia.ticker, ia.investment_type, ia.currency
 	`).Scan(&assets).Error
 	if err != nil {
 		return err
 	}
 
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	if err := j.backfillBenchmarks(ctx, today); err != nil {
+		j.logger.Error("Failed to backfill benchmarks", zap.Error(err))
+	}
+
 	if len(assets) == 0 {
 		j.logger.Info("No assets to backfill price history for")
 		return nil
 	}
 
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-
 	jobs := make(chan assetRow, len(assets))
 	errs := make(chan error, len(assets))
 
@@ -115,3 +120,57 @@ func (j *AssetPriceHistoryBackfillJob) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// backfillBenchmarks covers every attached benchmark from the earliest trade of
+// the portfolios or accounts it is attached to.
+func (j *AssetPriceHistoryBackfillJob) backfillBenchmarks(ctx context.Context, today time.Time) error {
+	type benchmarkRow struct {
+		ID             int64
+		Ticker         string
+		InvestmentType models.InvestmentType
+		Earliest       time.Time
+	}
+
+	var benchmarks []benchmarkRow
+	err := j.db.WithContext(ctx).Raw(`
+		SELECT
+			b.id,
+			b.ticker,
+			b.investment_type,
+			COALESCE(MIN(it.txn_date), MIN(ub.created_at)::date) AS earliest
+		FROM benchmarks b
+		JOIN user_benchmarks ub ON ub.benchmark_id = b.id
+		LEFT JOIN investment_assets ia
+			ON ia.user_id = ub.user_id
+			AND (ub.account_id IS NULL OR ia.account_id = ub.account_id)
+		LEFT JOIN investment_trades it ON it.asset_id = ia.id
+		GROUP BY b.id, b.ticker, b.investment_type
+	`).Scan(&benchmarks).Error
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, b := range benchmarks {
+		benchCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		err := j.investmentSvc.BackfillBenchmarkPriceHistory(benchCtx, b.ID, b.Ticker, b.InvestmentType, b.Earliest, today)
+		cancel()
+		if err != nil {
+			j.logger.Error("Failed to backfill benchmark",
+				zap.Int64("benchmark_id", b.ID),
+				zap.String("ticker", b.Ticker),
+				zap.Error(err),
+			)
+			failed++
+		}
+	}
+
+	if len(benchmarks) > 0 {
+		j.logger.Info("Benchmark backfill completed",
+			zap.Int("total", len(benchmarks)),
+			zap.Int("failed", failed),
+		)
+	}
+
+	return nil
+}
@@ -9,22 +9,30 @@ import (
 	"wealth-warden/internal/queue/queue_jobs"
 	"wealth-warden/internal/services"
 	"wealth-warden/pkg/finance"
+	"wealth-warden/pkg/utils"
 
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// maxSplitHolds is how many syncs in a row a split-like price move is held back,
+// about three days at the usual 8-hour interval.
+const maxSplitHolds = 9
+
 type accService interface {
 	UpdateSnapshotMarketValues(ctx context.Context, userID int64) error
 }
 
-const priceSurgeThreshold = 0.10
+type priceAlertService interface {
+	EvaluateRules(ctx context.Context, now time.Time) ([]models.PriceAlertTrigger, error)
+}
 
 type AssetPriceSyncJob struct {
 	logger            *zap.Logger
 	investmentSvc     services.InvestmentServiceInterface
 	accService        accService
+	alertSvc          priceAlertService
 	db                *gorm.DB
 	priceFetchClient  finance.PriceFetcher
 	notifDispatcher   queue_jobs.NotificationDispatcher
@@ -35,6 +43,7 @@ func NewAssetPriceSyncJob(
 	logger *zap.Logger,
 	investmentSvc services.InvestmentServiceInterface,
 	accService accService,
+	alertSvc priceAlertService,
 	db *gorm.DB,
 	priceFetchClient finance.PriceFetcher,
 	notifDispatcher queue_jobs.NotificationDispatcher,
@@ -44,6 +53,7 @@ func NewAssetPriceSyncJob(
 		logger:            logger,
 		investmentSvc:     investmentSvc,
 		accService:        accService,
+		alertSvc:          alertSvc,
 		db:                db,
 		priceFetchClient:  priceFetchClient,
 		notifDispatcher:   notifDispatcher,
@@ -63,6 +73,10 @@ func (j *AssetPriceSyncJob) Run(ctx context.Context) error {
 		return nil
 	}
 
+	if err := j.settleExpiredOptions(ctx); err != nil {
+		j.logger.Warn("Failed to settle expired options", zap.Error(err))
+	}
+
 	priceData, err := j.fetchPrices(ctx, assets)
 	if err != nil {
 		return err
@@ -85,9 +99,32 @@ func (j *AssetPriceSyncJob) Run(ctx context.Context) error {
 		j.logger.Warn("Failed to refresh snapshot market values after price sync", zap.Error(err))
 	}
 
+	j.deliverPriceAlerts(ctx)
+
 	return nil
 }
 
+// deliverPriceAlerts runs the users' alert rules once prices and snapshot values are
+// fresh. Rules that fired are stamped even without a dispatcher, so their cooldown holds.
+func (j *AssetPriceSyncJob) deliverPriceAlerts(ctx context.Context) {
+	if j.alertSvc == nil {
+		return
+	}
+
+	triggers, err := j.alertSvc.EvaluateRules(ctx, time.Now().UTC())
+	if err != nil {
+		j.logger.Warn("Failed to evaluate price alerts", zap.Error(err))
+		return
+	}
+
+	if j.notifDispatcher == nil {
+		return
+	}
+	for _, t := range triggers {
+		_ = j.notifDispatcher.Dispatch(ctx, t.UserID, t.Title, t.Message, models.NotificationTypeWarning)
+	}
+}
+
 func (j *AssetPriceSyncJob) refreshSnapshotMarketValues(ctx context.Context) error {
 	today := time.Now().UTC().Truncate(24 * time.Hour)
 
@@ -161,17 +198,20 @@ func (j *AssetPriceSyncJob) refreshSnapshotMarketValues(ctx context.Context) err
 func (j *AssetPriceSyncJob) getAssetsToUpdate(ctx context.Context) ([]struct {
 	Ticker         string
 	InvestmentType models.InvestmentType
+	PriceProvider  *string
 }, error) {
 	var assets []struct {
 		Ticker         string
 		InvestmentType models.InvestmentType
+		PriceProvider  *string
 	}
 
 	err := j.db.WithContext(ctx).
 		Model(&models.InvestmentAsset{}).
 		Joins("JOIN accounts ON accounts.id = investment_assets.account_id").
-		Select("DISTINCT investment_assets.ticker, investment_assets.investment_type").
+		Select("DISTINCT investment_assets.ticker, investment_assets.investment_type, investment_assets.price_provider").
 		Where("investment_assets.quantity > 0").
+		Where("investment_assets.manual_pricing = ?", false).
 		Where("accounts.is_active = ?", true).
 		Where("accounts.closed_at IS NULL").
 		Find(&assets).Error
@@ -188,11 +228,26 @@ func (j *AssetPriceSyncJob) getAssetsToUpdate(ctx context.Context) ([]struct {
 func (j *AssetPriceSyncJob) fetchPrices(ctx context.Context, assets []struct {
 	Ticker         string
 	InvestmentType models.InvestmentType
+	PriceProvider  *string
 }) (map[string]*finance.PriceData, error) {
 
 	priceData := make(map[string]*finance.PriceData)
 
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
 	for i, asset := range assets {
+		// Expired contracts no longer quote; settleExpiredOptions values them instead.
+		if asset.InvestmentType == models.InvestmentOption {
+			if _, expiry, _, _, ok := utils.ParseOptionSymbol(asset.Ticker); ok && today.After(expiry) {
+				continue
+			}
+		}
+
+		// The same ticker pinned to different providers only needs one quote
+		if _, ok := priceData[asset.Ticker]; ok {
+			continue
+		}
+
 		// Add delay between requests to avoid rate limiting
 		if i > 0 {
 			select {
@@ -202,7 +257,7 @@ func (j *AssetPriceSyncJob) fetchPrices(ctx context.Context, assets []struct {
 			}
 		}
 
-		price, err := j.priceFetchClient.GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
+		price, err := finance.ForProvider(j.priceFetchClient, asset.PriceProvider).GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
 		if err != nil {
 			j.logger.Warn("Failed to fetch price",
 				zap.String("ticker", asset.Ticker),
@@ -270,6 +325,7 @@ func (j *AssetPriceSyncJob) updateAssetsByTicker(ctx context.Context, tx *gorm.D
 		Preload("Account").
 		Joins("JOIN accounts ON accounts.id = investment_assets.account_id").
 		Where("investment_assets.ticker = ? AND investment_assets.quantity > 0", ticker).
+		Where("investment_assets.manual_pricing = ?", false).
 		Where("accounts.is_active = ?", true).
 		Where("accounts.closed_at IS NULL").
 		Find(&assets).Error
@@ -278,63 +334,120 @@ func (j *AssetPriceSyncJob) updateAssetsByTicker(ctx context.Context, tx *gorm.D
 		return 0, err
 	}
 
-	priceDecimal := decimal.NewFromFloat(price.Price)
+	quote := decimal.NewFromFloat(price.Price)
+	updated := 0
 
 	for _, asset := range assets {
-		if err := j.updateAsset(ctx, tx, asset, priceDecimal, now); err != nil {
+		// Bonds, commodities and options quote per face, ounce or share; holdings are valued per unit held.
+		priceDecimal := utils.UnitPrice(asset, quote, today)
+
+		applied, err := j.updateAsset(ctx, tx, asset, priceDecimal, now)
+		if err != nil {
 			return 0, err
 		}
+		if !applied {
+			continue
+		}
 
 		if err := j.updateTrades(tx, asset.ID, priceDecimal, now, asset.InvestmentType); err != nil {
 			return 0, err
 		}
 
+		currency := price.Currency
+		if currency == "" {
+			currency = asset.Currency
+		}
+
 		// Persist to price history
-		if err := j.investmentSvc.UpsertAssetPrice(ctx, tx, []models.AssetPriceHistory{{AssetID: asset.ID, AsOf: today, Price: priceDecimal, Currency: price.Currency}}); err != nil {
+		if err := j.investmentSvc.UpsertAssetPrice(ctx, tx, []models.AssetPriceHistory{{AssetID: asset.ID, AsOf: today, Price: priceDecimal, Currency: currency}}); err != nil {
 			j.logger.Warn("Failed to upsert asset price history",
 				zap.Int64("asset_id", asset.ID),
 				zap.Error(err))
 			// non-fatal, continue
 		}
+		updated++
 	}
 
-	return len(assets), nil
+	return updated, nil
 }
 
-func (j *AssetPriceSyncJob) updateAsset(ctx context.Context, tx *gorm.DB, asset models.InvestmentAsset, price decimal.Decimal, now time.Time) error {
+// updateAsset applies the fetched price to a single holding. It returns false when the
+// price was held back, in which case trades and price history must not be touched either.
+func (j *AssetPriceSyncJob) updateAsset(ctx context.Context, tx *gorm.DB, asset models.InvestmentAsset, price decimal.Decimal, now time.Time) (bool, error) {
 
 	if price.IsZero() || price.IsNegative() {
 		j.logger.Error("Refusing to update asset with invalid price",
 			zap.Int64("asset_id", asset.ID),
 			zap.String("ticker", asset.Ticker),
 			zap.String("price", price.String()))
-		return fmt.Errorf("invalid price for asset %d: %s", asset.ID, price.String())
+		return false, fmt.Errorf("invalid price for asset %d: %s", asset.ID, price.String())
 	}
 
 	if asset.CurrentPrice != nil && !asset.CurrentPrice.IsZero() {
+		// A move by a whole ratio is usually an unrecorded split. Applying it would value
+		// the old unit count at the new unit price, so hold the price back to give the
+		// user time to record the corporate action, which restates the asset in new
+		// units. A crash or rally can land on a ratio too, so after maxSplitHolds syncs
+		// the price is taken as a real move.
+		if asset.InvestmentType != models.InvestmentCrypto && utils.QuoteIsUnitPrice(asset.InvestmentType) {
+			if from, to, ok := utils.LikelySplitRatio(*asset.CurrentPrice, price); ok {
+				if asset.SplitHolds >= maxSplitHolds {
+					j.logger.Warn("Price move still matches a split ratio after holding it back — accepting it",
+						zap.Int64("asset_id", asset.ID),
+						zap.String("ticker", asset.Ticker),
+						zap.String("old_price", asset.CurrentPrice.String()),
+						zap.String("new_price", price.String()))
+					if err := j.writeAssetPrice(tx, asset, price, now); err != nil {
+						return false, err
+					}
+					return true, nil
+				}
+
+				j.logger.Warn("Price move matches a split ratio — holding the update back",
+					zap.Int64("asset_id", asset.ID),
+					zap.String("ticker", asset.Ticker),
+					zap.String("old_price", asset.CurrentPrice.String()),
+					zap.String("new_price", price.String()),
+					zap.String("ratio", fmt.Sprintf("%d:%d", to, from)),
+					zap.Int("holds", asset.SplitHolds+1))
+				if err := tx.Model(&models.InvestmentAsset{}).
+					Where("id = ?", asset.ID).
+					Update("split_holds", gorm.Expr("split_holds + 1")).Error; err != nil {
+					return false, err
+				}
+				if asset.SplitHolds == 0 && j.notifDispatcher != nil {
+					title := fmt.Sprintf("Possible %d:%d split for %s", to, from, asset.Ticker)
+					msg := fmt.Sprintf("%s moved from %s to %s, which looks like a %d:%d split. Record it as a corporate action on the asset so quantities and history are adjusted. Price updates are paused for a few days; if nothing is recorded by then, the new price is taken as a real move.",
+						asset.Ticker, asset.CurrentPrice.StringFixed(2), price.StringFixed(2), to, from)
+					_ = j.notifDispatcher.Dispatch(ctx, asset.UserID, title, msg, models.NotificationTypeWarning)
+				}
+				return false, nil
+			}
+		}
+
 		changePercent := price.Sub(*asset.CurrentPrice).Div(*asset.CurrentPrice).Abs()
-		if changePercent.GreaterThan(decimal.NewFromFloat(0.90)) && price.LessThan(*asset.CurrentPrice) {
+		// Options routinely lose most of their premium, so a deep drop is not suspicious there.
+		if asset.InvestmentType != models.InvestmentOption &&
+			changePercent.GreaterThan(decimal.NewFromFloat(0.90)) && price.LessThan(*asset.CurrentPrice) {
 			j.logger.Warn("Extreme price drop detected — skipping update to prevent data corruption",
 				zap.Int64("asset_id", asset.ID),
 				zap.String("ticker", asset.Ticker),
 				zap.String("old_price", asset.CurrentPrice.String()),
 				zap.String("new_price", price.String()),
 				zap.String("change_percent", changePercent.Mul(decimal.NewFromInt(100)).StringFixed(2)+"%"))
-			return nil
+			return false, nil
 		}
+	}
 
-		if j.notifDispatcher != nil && changePercent.GreaterThanOrEqual(decimal.NewFromFloat(priceSurgeThreshold)) {
-			direction := "surged"
-			if price.LessThan(*asset.CurrentPrice) {
-				direction = "dropped"
-			}
-			pct := changePercent.Mul(decimal.NewFromInt(100)).StringFixed(1)
-			title := fmt.Sprintf("%s %s %s%%", asset.Ticker, direction, pct)
-			msg := fmt.Sprintf("%s has %s by %s%% (from %s to %s).", asset.Ticker, direction, pct, asset.CurrentPrice.StringFixed(2), price.StringFixed(2))
-			_ = j.notifDispatcher.Dispatch(ctx, asset.UserID, title, msg, models.NotificationTypeWarning)
-		}
+	if err := j.writeAssetPrice(tx, asset, price, now); err != nil {
+		return false, err
 	}
 
+	return true, nil
+}
+
+// writeAssetPrice revalues the holding at price without any plausibility checks.
+func (j *AssetPriceSyncJob) writeAssetPrice(tx *gorm.DB, asset models.InvestmentAsset, price decimal.Decimal, now time.Time) error {
 	newCurrentValue := asset.Quantity.Mul(price)
 	costBasis := asset.ValueAtBuy
 	if asset.InvestmentType != models.InvestmentCrypto {
@@ -354,6 +467,7 @@ func (j *AssetPriceSyncJob) updateAsset(ctx context.Context, tx *gorm.DB, asset
 			"profit_loss":         newProfitLoss,
 			"profit_loss_percent": newProfitLossPercent,
 			"last_price_update":   now,
+			"split_holds":         0,
 			"updated_at":          now,
 		}).Error
 
@@ -367,6 +481,64 @@ func (j *AssetPriceSyncJob) updateAsset(ctx context.Context, tx *gorm.DB, asset
 	return nil
 }
 
+// settleExpiredOptions values each option held past its expiry at what exercising it
+// would return, using the underlying's close on the expiry date. Contracts are settled
+// once; the next run finds them already priced after expiry.
+func (j *AssetPriceSyncJob) settleExpiredOptions(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var options []models.InvestmentAsset
+	err := j.db.WithContext(ctx).
+		Joins("JOIN accounts ON accounts.id = investment_assets.account_id").
+		Where("investment_assets.investment_type = ? AND investment_assets.quantity > 0", models.InvestmentOption).
+		Where("investment_assets.manual_pricing = ?", false).
+		Where("investment_assets.expiry_date < ?", today).
+		Where("investment_assets.last_price_update IS NULL OR investment_assets.last_price_update < investment_assets.expiry_date + INTERVAL '1 day'").
+		Where("accounts.is_active = ?", true).
+		Where("accounts.closed_at IS NULL").
+		Find(&options).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, option := range options {
+		value := decimal.Zero
+		if underlying, _, _, _, ok := utils.ParseOptionSymbol(option.Ticker); ok {
+			quote, err := j.priceFetchClient.GetAssetPriceOnDate(ctx, underlying, models.InvestmentStock, *option.ExpiryDate)
+			if err != nil {
+				j.logger.Warn("Failed to fetch underlying price for expired option",
+					zap.String("ticker", option.Ticker),
+					zap.Error(err))
+				continue
+			}
+			value = utils.OptionIntrinsicValue(option, decimal.NewFromFloat(quote.Price))
+		}
+
+		err := j.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := j.writeAssetPrice(tx, option, value, now); err != nil {
+				return err
+			}
+			if err := j.updateTrades(tx, option.ID, value, now, option.InvestmentType); err != nil {
+				return err
+			}
+			return j.investmentSvc.UpsertAssetPrice(ctx, tx, []models.AssetPriceHistory{{AssetID: option.ID, AsOf: today, Price: value, Currency: option.Currency}})
+		})
+		if err != nil {
+			return err
+		}
+
+		if j.notifDispatcher != nil {
+			title := fmt.Sprintf("%s expired", option.Ticker)
+			msg := fmt.Sprintf("%s expired on %s and is now valued at %s %s per contract.",
+				option.Ticker, option.ExpiryDate.Format("2006-01-02"), value.StringFixed(2), option.Currency)
+			_ = j.notifDispatcher.Dispatch(ctx, option.UserID, title, msg, models.NotificationTypeInfo)
+		}
+	}
+
+	return nil
+}
+
 func (j *AssetPriceSyncJob) updateTrades(tx *gorm.DB, assetID int64, price decimal.Decimal, now time.Time, investmentType models.InvestmentType) error {
 	includeFees := investmentType != models.InvestmentCrypto
 	err := tx.Exec(`
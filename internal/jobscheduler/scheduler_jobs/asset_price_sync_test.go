@@ -25,7 +25,7 @@ func TestAssetPriceSyncJobSuite(t *testing.T) {
 // Test that job runs with no assets
 func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_Success() {
 	logger := zaptest.NewLogger(s.T())
-	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
 
 	err := job.Run(s.Ctx)
 	s.NoError(err)
@@ -65,7 +65,7 @@ func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_SkipsExtremePriceDrop
 	s.Require().NoError(err)
 
 	logger := zaptest.NewLogger(s.T())
-	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
 
 	err = job.Run(s.Ctx)
 	s.Require().NoError(err)
@@ -79,6 +79,55 @@ func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_SkipsExtremePriceDrop
 		inflatedPrice.String(), asset.CurrentPrice.String())
 }
 
+// Tests that a split-like move is held back for a bounded number of syncs and then accepted
+func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_HoldsSplitLikeMoveThenAccepts() {
+	accSvc := s.TC.App.AccountService
+	invSvc := s.TC.App.InvestmentService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := invSvc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentETF,
+		Name:           "iShares Core MSCI World",
+		Ticker:         "IWDA.AS",
+		Quantity:       decimal.NewFromInt(1),
+	})
+	s.Require().NoError(err)
+
+	// Mock returns IWDA.AS at 100, exactly half of this price, like a 2:1 split
+	oldPrice := decimal.NewFromInt(200)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentAsset{}).
+		Where("id = ?", assetID).
+		Update("current_price", oldPrice).Error)
+
+	logger := zaptest.NewLogger(s.T())
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+
+	var asset models.InvestmentAsset
+	s.Require().NoError(job.Run(s.Ctx))
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Assert().True(oldPrice.Equal(*asset.CurrentPrice), "split-like move should be held back, got %s", asset.CurrentPrice.String())
+	s.Assert().Equal(1, asset.SplitHolds)
+
+	for i := 0; i < 20 && oldPrice.Equal(*asset.CurrentPrice); i++ {
+		s.Require().NoError(job.Run(s.Ctx))
+		s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	}
+	s.Assert().True(decimal.NewFromInt(100).Equal(*asset.CurrentPrice), "held move should eventually be accepted, got %s", asset.CurrentPrice.String())
+	s.Assert().Equal(0, asset.SplitHolds)
+}
+
 // Tests that the job updates asset prices, values, P&L, and account balance non-cash flows
 func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_UpdatesPricesAndBalances() {
 	accSvc := s.TC.App.AccountService
@@ -144,7 +193,7 @@ func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_UpdatesPricesAndBalan
 
 	// Run price sync job
 	logger := zaptest.NewLogger(s.T())
-	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
 
 	ctx3, cancel3 := context.WithTimeout(s.Ctx, 30*time.Second)
 	defer cancel3()
@@ -183,3 +232,202 @@ func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_UpdatesPricesAndBalan
 	s.Require().NoError(err)
 	s.Assert().True(balance.CashOutflows.GreaterThan(decimal.Zero), "buy should have written cash outflows")
 }
+
+// Tests that a matching alert rule fires once, holds off for its cooldown, and only
+// fires again after the price crosses back over the level
+func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_FiresPriceAlertOncePerCooldown() {
+	accSvc := s.TC.App.AccountService
+	invSvc := s.TC.App.InvestmentService
+	alertSvc := s.TC.App.PriceAlertService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := invSvc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentCrypto,
+		Name:           "Bitcoin",
+		Ticker:         "BTC-USD",
+		Quantity:       decimal.NewFromInt(1),
+	})
+	s.Require().NoError(err)
+
+	// Mock returns BTC-USD at 50,000
+	ruleID, err := alertSvc.InsertRule(s.Ctx, userID, &models.PriceAlertRuleReq{
+		AssetID:   &assetID,
+		Kind:      models.PriceAlertPrice,
+		Direction: models.PriceAlertAbove,
+		Threshold: decimal.NewFromInt(40000),
+	})
+	s.Require().NoError(err)
+
+	logger := zaptest.NewLogger(s.T())
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+
+	s.Require().NoError(job.Run(s.Ctx))
+
+	var rule models.PriceAlertRule
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", ruleID).First(&rule).Error)
+	s.Require().NotNil(rule.LastTriggeredAt, "rule should have fired")
+	firstFired := *rule.LastTriggeredAt
+
+	triggers, err := alertSvc.EvaluateRules(s.Ctx, time.Now().UTC())
+	s.Require().NoError(err)
+	s.Assert().Empty(triggers, "rule is cooling down")
+
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, firstFired.Add(25*time.Hour))
+	s.Require().NoError(err)
+	s.Assert().Empty(triggers, "price never left the alert side, so there is no new crossing")
+
+	// Price dips below the level, then climbs back over it
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentAsset{}).
+		Where("id = ?", assetID).Update("current_price", decimal.NewFromInt(35000)).Error)
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, firstFired.Add(26*time.Hour))
+	s.Require().NoError(err)
+	s.Assert().Empty(triggers)
+
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentAsset{}).
+		Where("id = ?", assetID).Update("current_price", decimal.NewFromInt(50000)).Error)
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, firstFired.Add(27*time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(triggers, 1)
+	s.Assert().Equal(userID, triggers[0].UserID)
+	s.Assert().Contains(triggers[0].Message, "BTC-USD")
+}
+
+// Tests that a crossing during the cooldown is not lost: once the cooldown ends the
+// rule fires for it
+func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_PriceAlertCrossingDuringCooldown() {
+	accSvc := s.TC.App.AccountService
+	invSvc := s.TC.App.InvestmentService
+	alertSvc := s.TC.App.PriceAlertService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := invSvc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentCrypto,
+		Name:           "Bitcoin",
+		Ticker:         "BTC-USD",
+		Quantity:       decimal.NewFromInt(1),
+	})
+	s.Require().NoError(err)
+
+	ruleID, err := alertSvc.InsertRule(s.Ctx, userID, &models.PriceAlertRuleReq{
+		AssetID:   &assetID,
+		Kind:      models.PriceAlertPrice,
+		Direction: models.PriceAlertAbove,
+		Threshold: decimal.NewFromInt(40000),
+	})
+	s.Require().NoError(err)
+
+	setPrice := func(price int64) {
+		s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentAsset{}).
+			Where("id = ?", assetID).Update("current_price", decimal.NewFromInt(price)).Error)
+	}
+
+	setPrice(50000)
+	start := time.Now().UTC()
+	triggers, err := alertSvc.EvaluateRules(s.Ctx, start)
+	s.Require().NoError(err)
+	s.Require().Len(triggers, 1)
+
+	setPrice(35000)
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, start.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Assert().Empty(triggers)
+
+	setPrice(50000)
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, start.Add(2*time.Hour))
+	s.Require().NoError(err)
+	s.Assert().Empty(triggers, "crossed back over while cooling down")
+
+	var rule models.PriceAlertRule
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", ruleID).First(&rule).Error)
+	s.Assert().False(rule.LastHit, "a suppressed crossing doesn't count as fired")
+
+	triggers, err = alertSvc.EvaluateRules(s.Ctx, start.Add(25*time.Hour))
+	s.Require().NoError(err)
+	s.Assert().Len(triggers, 1, "fires once the cooldown is over")
+}
+
+// Tests that a rule without an asset or account watches the whole portfolio value
+func (s *AssetPriceSyncJobTestSuite) TestAssetPriceSyncJob_PortfolioValueAlert() {
+	accSvc := s.TC.App.AccountService
+	invSvc := s.TC.App.InvestmentService
+	alertSvc := s.TC.App.PriceAlertService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := invSvc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentCrypto,
+		Name:           "Bitcoin",
+		Ticker:         "BTC-USD",
+		Quantity:       decimal.NewFromInt(1),
+	})
+	s.Require().NoError(err)
+
+	_, err = alertSvc.InsertRule(s.Ctx, userID, &models.PriceAlertRuleReq{
+		Kind:      models.PriceAlertPrice,
+		Direction: models.PriceAlertAbove,
+		Threshold: decimal.NewFromInt(1),
+	})
+	s.Assert().Error(err, "price rules need an asset")
+
+	_, err = alertSvc.InsertRule(s.Ctx, userID, &models.PriceAlertRuleReq{
+		AssetID:   &assetID,
+		AccountID: &accID,
+		Kind:      models.PriceAlertValue,
+		Direction: models.PriceAlertAbove,
+		Threshold: decimal.NewFromInt(1),
+	})
+	s.Assert().Error(err, "a rule has one scope")
+
+	ruleID, err := alertSvc.InsertRule(s.Ctx, userID, &models.PriceAlertRuleReq{
+		Kind:      models.PriceAlertValue,
+		Direction: models.PriceAlertAbove,
+		Threshold: decimal.NewFromInt(50000),
+	})
+	s.Require().NoError(err)
+
+	logger := zaptest.NewLogger(s.T())
+	job := scheduler_jobs.NewAssetPriceSyncJob(logger, s.TC.App.InvestmentService, s.TC.App.AccountService, s.TC.App.PriceAlertService, s.TC.DB, &tests.MockPriceFetcher{}, nil, 0)
+
+	s.Require().NoError(job.Run(s.Ctx))
+
+	var rule models.PriceAlertRule
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", ruleID).First(&rule).Error)
+	s.Assert().NotNil(rule.LastTriggeredAt, "portfolio value is above the level")
+	s.Assert().Nil(rule.AssetID)
+	s.Assert().Nil(rule.AccountID)
+}
@@ -39,17 +39,27 @@ func (j *AutoFundGoalsJob) Run(ctx context.Context) error {
 		return err
 	}
 
-	if len(goals) == 0 {
+	budgets, err := j.container.SavingsService.FetchDueBudgets(ctx, now.Day())
+	if err != nil {
+		return err
+	}
+
+	if len(goals) == 0 && len(budgets) == 0 {
 		j.logger.Info("No active goals with allocation to process")
 		return nil
 	}
 
 	j.logger.Info("Processing savings goals auto-fund", zap.Int("count", len(goals)), zap.String("month", month.Format("2006-01")))
 
-	// Group goals by account so balance reads are consistent within an account
+	// Group goals by the account the money is drawn from (the funding account for
+	// transfer-backed goals) so balance reads are consistent within an account
 	accountGroups := make(map[int64][]models.SavingGoal)
 	for _, g := range goals {
-		accountGroups[g.AccountID] = append(accountGroups[g.AccountID], g)
+		sourceID := g.AccountID
+		if g.FundingAccountID != nil {
+			sourceID = *g.FundingAccountID
+		}
+		accountGroups[sourceID] = append(accountGroups[sourceID], g)
 	}
 
 	// Sort goals within each account by priority: higher value first, 0-priority last
@@ -130,6 +140,31 @@ func (j *AutoFundGoalsJob) Run(ctx context.Context) error {
 	wg.Wait()
 	close(results)
 
+	collected := make([]result, 0, len(goals))
+	for r := range results {
+		collected = append(collected, r)
+	}
+
+	// Users with a savings budget get it split across their goals by strategy
+	for _, b := range budgets {
+		funding, err := j.container.SavingsService.FundFromBudget(ctx, b, month)
+		if err != nil {
+			j.logger.Error("Failed to fund savings budget", zap.Int64("userID", b.UserID), zap.Error(err))
+			continue
+		}
+		for _, f := range funding {
+			collected = append(collected, result{
+				goalID:     f.Goal.ID,
+				goalName:   f.Goal.Name,
+				userID:     f.Goal.UserID,
+				accountID:  f.Goal.AccountID,
+				funded:     f.Funded,
+				skipReason: f.SkipReason,
+				err:        f.Err,
+			})
+		}
+	}
+
 	type userSummary struct {
 		funded              []string
 		insufficientBalance []string
@@ -139,7 +174,7 @@ func (j *AutoFundGoalsJob) Run(ctx context.Context) error {
 	funded, skipped, failed := 0, 0, 0
 	userResults := make(map[int64]*userSummary)
 
-	for r := range results {
+	for _, r := range collected {
 		s, ok := userResults[r.userID]
 		if !ok {
 			s = &userSummary{}
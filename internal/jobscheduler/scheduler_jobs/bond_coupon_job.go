@@ -0,0 +1,70 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+
+	"go.uber.org/zap"
+)
+
+type BondCouponJob struct {
+	logger          *zap.Logger
+	container       *bootstrap.ServiceContainer
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewBondCouponJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher) *BondCouponJob {
+	return &BondCouponJob{
+		logger:          logger,
+		container:       container,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+func (j *BondCouponJob) Run(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	bonds, err := j.container.InvestmentService.FetchCouponBonds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get coupon bonds: %w", err)
+	}
+
+	if len(bonds) == 0 {
+		j.logger.Info("No coupon bonds to check")
+		return nil
+	}
+
+	recorded := 0
+	for _, bond := range bonds {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, err := j.container.InvestmentService.RecordDueCoupons(ctx, bond, today)
+		if err != nil {
+			j.logger.Error("Failed to record bond coupons", zap.Int64("assetID", bond.ID), zap.Error(err))
+		}
+		if n == 0 {
+			continue
+		}
+		recorded += n
+
+		if j.notifDispatcher != nil {
+			title := fmt.Sprintf("Coupon paid on %s", bond.Ticker)
+			msg := fmt.Sprintf("%d coupon payment(s) on %s were recorded as income.", n, bond.Name)
+			_ = j.notifDispatcher.Dispatch(ctx, bond.UserID, title, msg, models.NotificationTypeInfo)
+		}
+	}
+
+	j.logger.Info("Bond coupon check completed",
+		zap.Int("bonds", len(bonds)),
+		zap.Int("recorded", recorded))
+
+	return nil
+}
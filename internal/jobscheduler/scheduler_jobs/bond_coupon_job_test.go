@@ -0,0 +1,108 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type BondCouponJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+}
+
+func TestBondCouponJobSuite(t *testing.T) {
+	suite.Run(t, new(BondCouponJobTestSuite))
+}
+
+func (s *BondCouponJobTestSuite) setupBond(heldSince time.Time) models.InvestmentAsset {
+	initial := decimal.NewFromInt(10000)
+	accID, err := s.TC.App.AccountService.InsertAccount(s.Ctx, 1, &models.AccountReq{
+		Name:          s.T().Name(),
+		AccountTypeID: 5,
+		Balance:       &initial,
+		OpenedAt:      heldSince,
+	})
+	s.Require().NoError(err)
+
+	face, rate, freq := decimal.NewFromInt(1000), decimal.NewFromInt(4), 4
+	maturity := time.Now().UTC().Truncate(24*time.Hour).AddDate(5, 0, 0)
+	price := decimal.NewFromInt(1000)
+	asset := models.InvestmentAsset{
+		AccountID:      accID,
+		UserID:         1,
+		InvestmentType: models.InvestmentBond,
+		Name:           "Test Bond",
+		Ticker:         "US912828XG55",
+		Quantity:       decimal.NewFromInt(5),
+		CurrentValue:   decimal.NewFromInt(5000),
+		CurrentPrice:   &price,
+		Currency:       "EUR",
+		CreatedAt:      heldSince,
+		InstrumentTerms: models.InstrumentTerms{
+			FaceValue:       &face,
+			CouponRate:      &rate,
+			CouponFrequency: &freq,
+			MaturityDate:    &maturity,
+		},
+	}
+	s.Require().NoError(s.TC.DB.Omit("Account").Create(&asset).Error)
+	return asset
+}
+
+func (s *BondCouponJobTestSuite) runJob() {
+	job := scheduler_jobs.NewBondCouponJob(zaptest.NewLogger(s.T()), s.TC.App, nil)
+	s.Require().NoError(job.Run(s.Ctx))
+}
+
+func (s *BondCouponJobTestSuite) coupons(assetID int64) []models.InvestmentIncome {
+	var records []models.InvestmentIncome
+	s.Require().NoError(s.TC.DB.
+		Where("asset_id = ? AND income_type = ?", assetID, models.IncomeTypeCoupon).
+		Order("txn_date ASC").
+		Find(&records).Error)
+	return records
+}
+
+// A bond held for seven months has paid two quarterly coupons; a second run adds nothing.
+func (s *BondCouponJobTestSuite) TestCoupons_RecordsDueOnce() {
+	bond := s.setupBond(time.Now().UTC().Truncate(24*time.Hour).AddDate(0, -7, 0))
+
+	s.runJob()
+
+	records := s.coupons(bond.ID)
+	s.Require().Len(records, 2)
+	for _, r := range records {
+		// 5 bonds × 1000 × 4% / 4
+		s.True(r.Amount.Equal(decimal.NewFromInt(50)), r.Amount.String())
+	}
+
+	s.runJob()
+	s.Len(s.coupons(bond.ID), 2)
+}
+
+func (s *BondCouponJobTestSuite) TestCoupons_NoneBeforeFirstDate() {
+	bond := s.setupBond(time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -10))
+
+	s.runJob()
+	s.Len(s.coupons(bond.ID), 0)
+}
+
+// A coupon the user deleted stays deleted on the next run.
+func (s *BondCouponJobTestSuite) TestCoupons_DeletedCouponNotRebooked() {
+	bond := s.setupBond(time.Now().UTC().Truncate(24*time.Hour).AddDate(0, -7, 0))
+
+	s.runJob()
+	records := s.coupons(bond.ID)
+	s.Require().Len(records, 2)
+
+	s.Require().NoError(s.TC.App.InvestmentService.DeleteInvestmentIncome(s.Ctx, 1, records[1].ID))
+
+	s.runJob()
+	s.Len(s.coupons(bond.ID), 1)
+}
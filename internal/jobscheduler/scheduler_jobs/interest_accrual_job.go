@@ -0,0 +1,135 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type InterestAccrualJob struct {
+	logger            *zap.Logger
+	container         *bootstrap.ServiceContainer
+	notifDispatcher   queue_jobs.NotificationDispatcher
+	concurrentWorkers int
+}
+
+func NewInterestAccrualJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher, concurrentWorkers int) *InterestAccrualJob {
+	return &InterestAccrualJob{
+		logger:            logger,
+		container:         container,
+		notifDispatcher:   notifDispatcher,
+		concurrentWorkers: concurrentWorkers,
+	}
+}
+
+func (j *InterestAccrualJob) Run(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	configs, err := j.container.InterestService.FetchActiveConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get interest configs: %w", err)
+	}
+
+	if len(configs) == 0 {
+		j.logger.Info("No interest-bearing accounts to process")
+		return nil
+	}
+
+	j.logger.Info("Accruing interest", zap.Int("count", len(configs)))
+
+	type result struct {
+		userID      int64
+		accountName string
+		currency    string
+		paid        decimal.Decimal
+		err         error
+	}
+
+	jobs := make(chan models.AccountInterestConfig, len(configs))
+	results := make(chan result, len(configs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < j.concurrentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cfg := range jobs {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				paid, err := j.container.InterestService.AccrueInterest(ctx, cfg, today)
+				r := result{userID: cfg.UserID, paid: paid, err: err}
+				if cfg.Account != nil {
+					r.accountName = cfg.Account.Name
+					r.currency = cfg.Account.Currency
+				}
+				results <- r
+			}
+		}()
+	}
+
+	for _, cfg := range configs {
+		jobs <- cfg
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(results)
+
+	type userSummary struct {
+		paid   []string
+		failed []string
+	}
+
+	paidCount, failedCount := 0, 0
+	userResults := make(map[int64]*userSummary)
+
+	for r := range results {
+		s, ok := userResults[r.userID]
+		if !ok {
+			s = &userSummary{}
+			userResults[r.userID] = s
+		}
+		switch {
+		case r.err != nil:
+			j.logger.Error("Failed to accrue interest",
+				zap.Int64("userID", r.userID),
+				zap.String("account", r.accountName),
+				zap.Error(r.err))
+			s.failed = append(s.failed, r.accountName)
+			failedCount++
+		case r.paid.IsPositive():
+			s.paid = append(s.paid, fmt.Sprintf("%s: %s %s", r.accountName, r.paid.StringFixed(2), r.currency))
+			paidCount++
+		}
+	}
+
+	j.logger.Info("Interest accrual completed",
+		zap.Int("paid_out", paidCount),
+		zap.Int("failed", failedCount))
+
+	if j.notifDispatcher != nil {
+		for userID, s := range userResults {
+			if len(s.failed) > 0 {
+				title := fmt.Sprintf("Interest accrual failed for %d account(s)", len(s.failed))
+				_ = j.notifDispatcher.Dispatch(ctx, userID, title, strings.Join(s.failed, ",\n"), models.NotificationTypeError)
+			}
+			if len(s.paid) > 0 {
+				title := fmt.Sprintf("Interest paid on %d account(s)", len(s.paid))
+				_ = j.notifDispatcher.Dispatch(ctx, userID, title, strings.Join(s.paid, ",\n"), models.NotificationTypeSuccess)
+			}
+		}
+	}
+
+	return nil
+}
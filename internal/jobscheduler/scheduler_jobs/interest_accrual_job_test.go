@@ -0,0 +1,147 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type InterestAccrualJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+	memberUserID  int64
+	savingsTypeID int64
+}
+
+func TestInterestAccrualJobSuite(t *testing.T) {
+	suite.Run(t, new(InterestAccrualJobTestSuite))
+}
+
+func (s *InterestAccrualJobTestSuite) SetupSuite() {
+	s.ServiceIntegrationSuite.SetupSuite()
+
+	var user models.User
+	err := s.TC.DB.Where("role_id = ?", 1).First(&user).Error
+	s.Require().NoError(err)
+	s.memberUserID = user.ID
+
+	var at models.AccountType
+	err = s.TC.DB.Where("sub_type = ?", "savings").First(&at).Error
+	s.Require().NoError(err)
+	s.savingsTypeID = at.ID
+}
+
+func (s *InterestAccrualJobTestSuite) createSavingsAccount(name string, balance decimal.Decimal, asOf time.Time) models.Account {
+	acc := models.Account{
+		UserID:            s.memberUserID,
+		Name:              name,
+		AccountTypeID:     s.savingsTypeID,
+		Currency:          "EUR",
+		BalanceProjection: "fixed",
+		ExpectedBalance:   decimal.Zero,
+		OpenedAt:          asOf,
+		IsActive:          true,
+	}
+	s.Require().NoError(s.TC.DB.Create(&acc).Error)
+
+	bal := models.Balance{
+		AccountID:    acc.ID,
+		AsOf:         asOf,
+		StartBalance: balance,
+		Currency:     "EUR",
+	}
+	s.Require().NoError(s.TC.DB.Create(&bal).Error)
+
+	return acc
+}
+
+func (s *InterestAccrualJobTestSuite) createConfig(accountID int64, rate decimal.Decimal, payoutDay int, accruedThrough time.Time) models.AccountInterestConfig {
+	cfg := models.AccountInterestConfig{
+		UserID:           s.memberUserID,
+		AccountID:        accountID,
+		AnnualRate:       rate,
+		Compounding:      models.InterestCompoundingMonthly,
+		PayoutDayOfMonth: payoutDay,
+		IsActive:         true,
+		AccruedThrough:   &accruedThrough,
+	}
+	s.Require().NoError(s.TC.DB.Omit("Tiers", "Account").Create(&cfg).Error)
+	return cfg
+}
+
+func (s *InterestAccrualJobTestSuite) interestTxns(accountID int64) []models.Transaction {
+	var txns []models.Transaction
+	s.Require().NoError(
+		s.TC.DB.Joins("JOIN categories ON categories.id = transactions.category_id").
+			Where("transactions.account_id = ? AND categories.name = ?", accountID, "interest").
+			Find(&txns).Error,
+	)
+	return txns
+}
+
+func (s *InterestAccrualJobTestSuite) runJob() {
+	logger := zaptest.NewLogger(s.T())
+	job := scheduler_jobs.NewInterestAccrualJob(logger, s.TC.App, nil, 2)
+	s.Require().NoError(job.Run(s.Ctx))
+}
+
+// Interest accrued since the last run is paid out on the payout day and the accrual resets.
+func (s *InterestAccrualJobTestSuite) TestAccrual_PaysOutOnPayoutDay() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -10)
+	acc := s.createSavingsAccount(s.T().Name(), decimal.NewFromInt(36500), start)
+	cfg := s.createConfig(acc.ID, decimal.NewFromInt(10), today.Day(), start)
+
+	s.runJob()
+
+	txns := s.interestTxns(acc.ID)
+	s.Require().NotEmpty(txns)
+	s.Equal("income", txns[len(txns)-1].TransactionType)
+
+	var updated models.AccountInterestConfig
+	s.Require().NoError(s.TC.DB.First(&updated, cfg.ID).Error)
+	s.Require().NotNil(updated.LastPayoutAt)
+	s.True(updated.LastPayoutAt.UTC().Equal(today))
+	s.Require().NotNil(updated.AccruedThrough)
+	s.True(updated.AccruedThrough.UTC().Equal(today.AddDate(0, 0, -1)))
+	s.True(updated.AccruedAmount.LessThan(decimal.NewFromFloat(0.01)))
+}
+
+// Running twice on the same day does not pay interest again.
+func (s *InterestAccrualJobTestSuite) TestAccrual_Idempotent() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -5)
+	acc := s.createSavingsAccount(s.T().Name(), decimal.NewFromInt(36500), start)
+	s.createConfig(acc.ID, decimal.NewFromInt(10), today.Day(), start)
+
+	s.runJob()
+	first := len(s.interestTxns(acc.ID))
+
+	s.runJob()
+	s.Equal(first, len(s.interestTxns(acc.ID)))
+}
+
+// Outside the payout day interest only accrues.
+func (s *InterestAccrualJobTestSuite) TestAccrual_AccruesWithoutPayout() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -3)
+	acc := s.createSavingsAccount(s.T().Name(), decimal.NewFromInt(36500), start)
+
+	// pick a payout day that is not within the accrual window
+	payoutDay := today.AddDate(0, 0, 7).Day()
+	cfg := s.createConfig(acc.ID, decimal.NewFromInt(10), payoutDay, start)
+
+	s.runJob()
+
+	s.Empty(s.interestTxns(acc.ID))
+
+	var updated models.AccountInterestConfig
+	s.Require().NoError(s.TC.DB.First(&updated, cfg.ID).Error)
+	// 2 days (start+1 .. yesterday) at 10/day
+	s.True(updated.AccruedAmount.Equal(decimal.NewFromInt(20)), updated.AccruedAmount.String())
+}
@@ -0,0 +1,55 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/ws"
+
+	"go.uber.org/zap"
+)
+
+type SavingGoalTrackJob struct {
+	logger          *zap.Logger
+	container       *bootstrap.ServiceContainer
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewSavingGoalTrackJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher) *SavingGoalTrackJob {
+	return &SavingGoalTrackJob{
+		logger:          logger,
+		container:       container,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+func (j *SavingGoalTrackJob) Run(ctx context.Context) error {
+	behind, err := j.container.SavingsService.MarkBehindGoals(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check savings goal schedules: %w", err)
+	}
+
+	if len(behind) == 0 {
+		j.logger.Info("No savings goals fell behind")
+		return nil
+	}
+
+	if j.notifDispatcher != nil {
+		for _, g := range behind {
+			title := fmt.Sprintf("%s is falling behind", g.Name)
+			msg := fmt.Sprintf("%s has %s of %s saved, less than needed to reach it by %s.",
+				g.Name, g.CurrentAmount.StringFixed(2), g.TargetAmount.StringFixed(2), g.TargetDate.Format("2006-01-02"))
+			if g.MonthlyNeeded != nil {
+				msg += fmt.Sprintf(" Saving %s a month would get it back on track.", g.MonthlyNeeded.StringFixed(2))
+			}
+			_ = j.notifDispatcher.DispatchWithEvent(ctx, g.UserID, title, msg, models.NotificationTypeWarning,
+				ws.Event{Type: ws.TypeSavingGoalBehind, Payload: ws.SavingGoalPayload{GoalID: g.ID}})
+		}
+	}
+
+	j.logger.Info("Savings goal schedule check completed", zap.Int("behind", len(behind)))
+
+	return nil
+}
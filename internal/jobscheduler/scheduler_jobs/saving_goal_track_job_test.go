@@ -0,0 +1,98 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type SavingGoalTrackJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+	accountID int64
+}
+
+func TestSavingGoalTrackJobSuite(t *testing.T) {
+	suite.Run(t, new(SavingGoalTrackJobTestSuite))
+}
+
+func (s *SavingGoalTrackJobTestSuite) SetupTest() {
+	s.ServiceIntegrationSuite.SetupTest()
+
+	var at models.AccountType
+	s.Require().NoError(s.TC.DB.Where("sub_type = ?", "savings").First(&at).Error)
+
+	acc := models.Account{
+		UserID:            1,
+		Name:              "Savings",
+		AccountTypeID:     at.ID,
+		Currency:          "EUR",
+		BalanceProjection: "fixed",
+		ExpectedBalance:   decimal.Zero,
+		OpenedAt:          time.Now().UTC(),
+		IsActive:          true,
+	}
+	s.Require().NoError(s.TC.DB.Create(&acc).Error)
+	s.accountID = acc.ID
+}
+
+// createGoal makes a goal started 100 days ago and due in 100 days, so it should
+// be half saved by now.
+func (s *SavingGoalTrackJobTestSuite) createGoal(name string, saved int64) models.SavingGoal {
+	due := time.Now().UTC().AddDate(0, 0, 100)
+	goal := models.SavingGoal{
+		UserID:        1,
+		AccountID:     s.accountID,
+		Name:          name,
+		TargetAmount:  decimal.NewFromInt(1000),
+		CurrentAmount: decimal.NewFromInt(saved),
+		TargetDate:    &due,
+		Status:        models.SavingGoalStatusActive,
+		CycleNumber:   1,
+		CreatedAt:     time.Now().UTC().AddDate(0, 0, -100),
+	}
+	s.Require().NoError(s.TC.DB.Create(&goal).Error)
+	return goal
+}
+
+func (s *SavingGoalTrackJobTestSuite) alertedAt(goalID int64) *time.Time {
+	var goal models.SavingGoal
+	s.Require().NoError(s.TC.DB.First(&goal, goalID).Error)
+	return goal.LateAlertedAt
+}
+
+func (s *SavingGoalTrackJobTestSuite) runJob() {
+	job := scheduler_jobs.NewSavingGoalTrackJob(zaptest.NewLogger(s.T()), s.TC.App, nil)
+	s.Require().NoError(job.Run(s.Ctx))
+}
+
+// A goal that slips behind is flagged once, cleared when it catches up, and flagged again on the next slip.
+func (s *SavingGoalTrackJobTestSuite) TestSavingGoalTrack_AlertsOncePerSlip() {
+	late := s.createGoal("Car", 100)
+	onTrack := s.createGoal("Holiday", 600)
+
+	s.runJob()
+
+	first := s.alertedAt(late.ID)
+	s.Require().NotNil(first)
+	s.Nil(s.alertedAt(onTrack.ID))
+
+	s.runJob()
+	s.Require().NotNil(s.alertedAt(late.ID))
+	s.True(first.Equal(*s.alertedAt(late.ID)), "still behind, not reported again")
+
+	s.Require().NoError(s.TC.DB.Model(&models.SavingGoal{}).Where("id = ?", late.ID).
+		Update("current_amount", decimal.NewFromInt(700)).Error)
+	s.runJob()
+	s.Nil(s.alertedAt(late.ID), "back on track clears the alert")
+
+	s.Require().NoError(s.TC.DB.Model(&models.SavingGoal{}).Where("id = ?", late.ID).
+		Update("current_amount", decimal.NewFromInt(50)).Error)
+	s.runJob()
+	s.NotNil(s.alertedAt(late.ID))
+}
@@ -0,0 +1,63 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/ws"
+
+	"go.uber.org/zap"
+)
+
+type SpendingLimitDigestJob struct {
+	logger          *zap.Logger
+	container       *bootstrap.ServiceContainer
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewSpendingLimitDigestJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher) *SpendingLimitDigestJob {
+	return &SpendingLimitDigestJob{
+		logger:          logger,
+		container:       container,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+func (j *SpendingLimitDigestJob) Run(ctx context.Context) error {
+	digests, err := j.container.SpendingLimitService.CollectDigests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect spending limit digests: %w", err)
+	}
+
+	if len(digests) == 0 {
+		j.logger.Info("No spending limit crossings to report")
+		return nil
+	}
+
+	if j.notifDispatcher != nil {
+		for _, d := range digests {
+			title := "1 spending limit crossed"
+			if len(d.Crossings) > 1 {
+				title = fmt.Sprintf("%d spending limits crossed", len(d.Crossings))
+			}
+
+			lines := make([]string, 0, len(d.Crossings))
+			limitIDs := make([]int64, 0, len(d.Crossings))
+			for _, c := range d.Crossings {
+				lines = append(lines, fmt.Sprintf("%s: %s of %s spent (%d%%).",
+					c.Name, c.Spent.StringFixed(2), c.Amount.StringFixed(2), c.Percent))
+				limitIDs = append(limitIDs, c.LimitID)
+			}
+
+			_ = j.notifDispatcher.DispatchWithEvent(ctx, d.UserID, title, strings.Join(lines, "\n"), models.NotificationTypeWarning,
+				ws.Event{Type: ws.TypeSpendingLimitDigest, Payload: ws.SpendingLimitPayload{LimitIDs: limitIDs}})
+		}
+	}
+
+	j.logger.Info("Spending limit digest completed", zap.Int("users", len(digests)))
+
+	return nil
+}
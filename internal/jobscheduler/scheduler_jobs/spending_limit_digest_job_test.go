@@ -0,0 +1,73 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type SpendingLimitDigestJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+	limitID int64
+}
+
+func TestSpendingLimitDigestJobSuite(t *testing.T) {
+	suite.Run(t, new(SpendingLimitDigestJobTestSuite))
+}
+
+func (s *SpendingLimitDigestJobTestSuite) SetupTest() {
+	s.ServiceIntegrationSuite.SetupTest()
+
+	var cat models.Category
+	s.Require().NoError(s.TC.DB.
+		Where("classification = ? AND parent_id IS NOT NULL AND user_id IS NULL AND deleted_at IS NULL", "expense").
+		Order("id ASC").
+		First(&cat).Error)
+
+	limit := models.SpendingLimit{
+		UserID:      1,
+		CategoryID:  &cat.ID,
+		Amount:      decimal.NewFromInt(100),
+		NotifyAt80:  true,
+		NotifyAt100: true,
+		Delivery:    models.SpendingLimitDeliveryDigest,
+		IsActive:    true,
+	}
+	s.Require().NoError(s.TC.DB.Create(&limit).Error)
+	s.limitID = limit.ID
+}
+
+func (s *SpendingLimitDigestJobTestSuite) pending() int64 {
+	var n int64
+	s.Require().NoError(s.TC.DB.Model(&models.SpendingLimitAlert{}).
+		Where("limit_id = ? AND notified_at IS NULL", s.limitID).Count(&n).Error)
+	return n
+}
+
+// The digest sends pending crossings once and leaves nothing behind.
+func (s *SpendingLimitDigestJobTestSuite) TestSpendingLimitDigest_SendsPendingOnce() {
+	month := utils.MonthStart(time.Now().UTC())
+	for _, p := range []int{80, 100} {
+		s.Require().NoError(s.TC.DB.Create(&models.SpendingLimitAlert{
+			UserID:  1,
+			LimitID: s.limitID,
+			Month:   month,
+			Percent: p,
+			Spent:   decimal.NewFromInt(105),
+		}).Error)
+	}
+	s.Equal(int64(2), s.pending())
+
+	job := scheduler_jobs.NewSpendingLimitDigestJob(zaptest.NewLogger(s.T()), s.TC.App, nil)
+	s.Require().NoError(job.Run(s.Ctx))
+	s.Equal(int64(0), s.pending())
+
+	s.Require().NoError(job.Run(s.Ctx))
+}
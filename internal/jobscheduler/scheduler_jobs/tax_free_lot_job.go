@@ -0,0 +1,75 @@
+package scheduler_jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"wealth-warden/internal/bootstrap"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+
+	"go.uber.org/zap"
+)
+
+type TaxFreeLotJob struct {
+	logger          *zap.Logger
+	container       *bootstrap.ServiceContainer
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewTaxFreeLotJob(logger *zap.Logger, container *bootstrap.ServiceContainer, notifDispatcher queue_jobs.NotificationDispatcher) *TaxFreeLotJob {
+	return &TaxFreeLotJob{
+		logger:          logger,
+		container:       container,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+func (j *TaxFreeLotJob) Run(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	userIDs, err := j.container.InvestmentService.FetchTaxFreeAlertUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users with tax-free brackets: %w", err)
+	}
+
+	if len(userIDs) == 0 {
+		j.logger.Info("No tax-free brackets to check")
+		return nil
+	}
+
+	alerted := 0
+	for _, userID := range userIDs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		lots, err := j.container.InvestmentService.MarkTaxFreeAlerts(ctx, userID, today)
+		if err != nil {
+			j.logger.Error("Failed to check lots turning tax-free", zap.Int64("userID", userID), zap.Error(err))
+			continue
+		}
+		if len(lots) == 0 || j.notifDispatcher == nil {
+			continue
+		}
+
+		lines := make([]string, len(lots))
+		for i, l := range lots {
+			lines[i] = fmt.Sprintf("%s bought %s: %s %s, tax-free in %d day(s)",
+				l.Ticker, l.BuyDate.Format("2006-01-02"), l.MarketValue.StringFixed(2), l.Currency, *l.DaysUntilTaxFree)
+		}
+
+		title := "Lots become tax-free soon"
+		_ = j.notifDispatcher.Dispatch(ctx, userID, title, "Selling before then is taxed:\n"+strings.Join(lines, ",\n"), models.NotificationTypeWarning)
+		alerted++
+	}
+
+	j.logger.Info("Tax-free lot check completed",
+		zap.Int("users", len(userIDs)),
+		zap.Int("alerted", alerted))
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package scheduler_jobs_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/jobscheduler/scheduler_jobs"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+)
+
+type TaxFreeLotJobTestSuite struct {
+	tests.ServiceIntegrationSuite
+}
+
+func TestTaxFreeLotJobSuite(t *testing.T) {
+	suite.Run(t, new(TaxFreeLotJobTestSuite))
+}
+
+func (s *TaxFreeLotJobTestSuite) setupAsset() models.InvestmentAsset {
+	initial := decimal.NewFromInt(10000)
+	accID, err := s.TC.App.AccountService.InsertAccount(s.Ctx, 1, &models.AccountReq{
+		Name:          s.T().Name(),
+		AccountTypeID: 5,
+		Balance:       &initial,
+		OpenedAt:      time.Now().UTC().Truncate(24*time.Hour).AddDate(-2, 0, 0),
+	})
+	s.Require().NoError(err)
+
+	price := decimal.NewFromInt(20)
+	asset := models.InvestmentAsset{
+		AccountID:      accID,
+		UserID:         1,
+		InvestmentType: models.InvestmentETF,
+		Name:           "Test ETF",
+		Ticker:         "VWCE.DE",
+		Quantity:       decimal.NewFromInt(201),
+		CurrentValue:   decimal.NewFromInt(4020),
+		CurrentPrice:   &price,
+		Currency:       "EUR",
+	}
+	s.Require().NoError(s.TC.DB.Omit("Account").Create(&asset).Error)
+
+	for _, b := range []struct {
+		days int
+		pct  int64
+	}{{0, 25}, {365, 0}} {
+		_, err := s.TC.App.InvestmentService.InsertTaxBracket(s.Ctx, 1, &models.InvestmentTaxBracketReq{
+			InvestmentType: models.InvestmentETF,
+			MinDaysHeld:    b.days,
+			TaxablePercent: decimal.NewFromInt(b.pct),
+		})
+		s.Require().NoError(err)
+	}
+	return asset
+}
+
+func (s *TaxFreeLotJobTestSuite) buy(asset models.InvestmentAsset, daysAgo int, qty int64) models.InvestmentTrade {
+	trade := models.InvestmentTrade{
+		UserID:       1,
+		AssetID:      asset.ID,
+		TxnDate:      time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -daysAgo),
+		TradeType:    models.InvestmentBuy,
+		Quantity:     decimal.NewFromInt(qty),
+		PricePerUnit: decimal.NewFromInt(10),
+		ValueAtBuy:   decimal.NewFromInt(qty * 10),
+		Currency:     "EUR",
+	}
+	s.Require().NoError(s.TC.DB.Omit("Asset", "Lots").Create(&trade).Error)
+	return trade
+}
+
+func (s *TaxFreeLotJobTestSuite) alertedAt(tradeID int64) *time.Time {
+	var trade models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.First(&trade, tradeID).Error)
+	return trade.TaxFreeAlertedAt
+}
+
+func (s *TaxFreeLotJobTestSuite) runJob() {
+	job := scheduler_jobs.NewTaxFreeLotJob(zaptest.NewLogger(s.T()), s.TC.App, nil)
+	s.Require().NoError(job.Run(s.Ctx))
+}
+
+// A large lot five days from tax-free is flagged once; a small one and a far one are not.
+func (s *TaxFreeLotJobTestSuite) TestTaxFreeLot_WarnsLargeLotsOnce() {
+	asset := s.setupAsset()
+	large := s.buy(asset, 360, 100)
+	small := s.buy(asset, 361, 1)
+	far := s.buy(asset, 200, 100)
+
+	s.runJob()
+
+	first := s.alertedAt(large.ID)
+	s.Require().NotNil(first)
+	s.Nil(s.alertedAt(small.ID))
+	s.Nil(s.alertedAt(far.ID))
+
+	s.runJob()
+	s.True(first.Equal(*s.alertedAt(large.ID)))
+}
@@ -27,6 +27,7 @@ func newRegistry(c *bootstrap.ServiceContainer, logger *zap.Logger) *registry {
 	notificationRepo := repositories.NewNotificationRepository(c.DB)
 	analyticsRepo := repositories.NewAnalyticsRepository(c.DB)
 	transactionRepo := repositories.NewTransactionRepository(c.DB)
+	investmentRepo := repositories.NewInvestmentRepository(c.DB)
 
 	factories := map[string]jobFactory{
 		// Struct-literal jobs: exported deps re-attached after unmarshal.
@@ -47,6 +48,14 @@ func newRegistry(c *bootstrap.ServiceContainer, logger *zap.Logger) *registry {
 			j.Broadcaster = c.Hub
 			return &j, nil
 		},
+		queue_jobs.TypeCheckSpendingLimits: func(data []byte) (queue.Job, error) {
+			var j queue_jobs.CheckSpendingLimitsJob
+			if err := json.Unmarshal(data, &j); err != nil {
+				return nil, err
+			}
+			j.Checker = c.SpendingLimitService
+			return &j, nil
+		},
 
 		// Constructor jobs: unmarshal data fields, then rebuild via the public
 		// constructor (deps are unexported, so they can only be set there).
@@ -64,6 +73,13 @@ func newRegistry(c *bootstrap.ServiceContainer, logger *zap.Logger) *registry {
 			}
 			return queue_jobs.NewSyncAssetAfterTradeJob(logger.Named("asset_sync"), c.InvestmentService, j.UserID, j.AssetID, j.Ticker, j.InvestmentType, j.TradeDate), nil
 		},
+		queue_jobs.TypeBackfillBenchmark: func(data []byte) (queue.Job, error) {
+			var j queue_jobs.BackfillBenchmarkJob
+			if err := json.Unmarshal(data, &j); err != nil {
+				return nil, err
+			}
+			return queue_jobs.NewBackfillBenchmarkJob(logger.Named("benchmark_backfill"), c.InvestmentService, j.BenchmarkID, j.Ticker, j.InvestmentType, j.From), nil
+		},
 		queue_jobs.TypeRecalculateTemplateTZ: func(data []byte) (queue.Job, error) {
 			var j queue_jobs.RecalculateTemplateTimezoneJob
 			if err := json.Unmarshal(data, &j); err != nil {
@@ -78,6 +94,13 @@ func newRegistry(c *bootstrap.ServiceContainer, logger *zap.Logger) *registry {
 			}
 			return queue_jobs.NewGenerateCategoryReportJob(logger.Named("category_report"), analyticsRepo, c.Hub, j.ReportID, j.UserID, j.Params), nil
 		},
+		queue_jobs.TypeGenerateCapitalGains: func(data []byte) (queue.Job, error) {
+			var j queue_jobs.GenerateCapitalGainsReportJob
+			if err := json.Unmarshal(data, &j); err != nil {
+				return nil, err
+			}
+			return queue_jobs.NewGenerateCapitalGainsReportJob(logger.Named("capital_gains_report"), analyticsRepo, investmentRepo, c.Hub, j.ReportID, j.UserID, j.Params), nil
+		},
 
 		// Payload-less maintenance jobs: deps only.
 		queue_jobs.TypeBackfillAssetCashFlows: func([]byte) (queue.Job, error) {
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"wealth-warden/pkg/authz"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DelegateAccessLogger records every request a delegate makes on an owner's data.
+type DelegateAccessLogger interface {
+	LogDelegateAccess(ctx context.Context, scope *authz.DelegationScope, method, path string, status int) error
+}
+
+// DelegationResolver looks up a delegate's active grant and the permissions it carries.
+type DelegationResolver interface {
+	ActiveDelegation(ctx context.Context, ownerID, delegateID int64) (*authz.DelegationScope, error)
+	DelegatePerms(ctx context.Context, ownerID int64) (map[string]struct{}, error)
+}
+
+// InjectDelegation lets a delegate read an owner's data by sending the owner's id in
+// the X-Acting-For header. The request then runs as the owner, with read-only
+// permissions and the delegation's module and account restrictions applied.
+// Producing a capital gains report is the one write a delegate may make.
+// It must run after InjectPerms.
+func InjectDelegation(s DelegationResolver, accessLogger DelegateAccessLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(authz.ActingForHeader)
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		delegateID := c.GetInt64("user_id")
+		ownerID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || ownerID <= 0 || delegateID <= 0 || ownerID == delegateID {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Invalid delegation header"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		scope, err := s.ActiveDelegation(ctx, ownerID, delegateID)
+		if err != nil {
+			if errors.Is(err, authz.ErrNoDelegation) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Failed to resolve delegation"})
+			return
+		}
+
+		fullPath := c.FullPath()
+		accountID := authz.AccountForRoute(fullPath, c.Param, c.Query)
+		if !authz.DelegateMayCall(c.Request.Method, fullPath) || !scope.AllowsRoute(fullPath) || !scope.AllowsAccount(accountID) {
+			if accessLogger != nil {
+				_ = accessLogger.LogDelegateAccess(ctx, scope, c.Request.Method, c.Request.URL.Path, http.StatusForbidden)
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
+			return
+		}
+
+		perms, err := s.DelegatePerms(ctx, ownerID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Failed to resolve delegation"})
+			return
+		}
+
+		c.Set("user_id", ownerID)
+		c.Set(authz.CtxPermsKey, perms)
+		c.Set(authz.CtxDelegationKey, scope)
+
+		c.Next()
+
+		if accessLogger != nil {
+			_ = accessLogger.LogDelegateAccess(ctx, scope, c.Request.Method, c.Request.URL.Path, c.Writer.Status())
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wealth-warden/internal/middleware"
+	"wealth-warden/pkg/authz"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	delegateID = int64(20)
+	ownerID    = int64(10)
+)
+
+type fakeResolver struct {
+	scope *authz.DelegationScope
+}
+
+func (f *fakeResolver) ActiveDelegation(_ context.Context, owner, delegate int64) (*authz.DelegationScope, error) {
+	if f.scope == nil || owner != f.scope.OwnerID || delegate != f.scope.DelegateID {
+		return nil, authz.ErrNoDelegation
+	}
+	return f.scope, nil
+}
+
+func (f *fakeResolver) DelegatePerms(_ context.Context, _ int64) (map[string]struct{}, error) {
+	return map[string]struct{}{"view_data": {}, "view_basic_statistics": {}}, nil
+}
+
+type accessEntry struct {
+	delegationID int64
+	method       string
+	path         string
+	status       int
+}
+
+type fakeAccessLogger struct {
+	entries []accessEntry
+}
+
+func (f *fakeAccessLogger) LogDelegateAccess(_ context.Context, scope *authz.DelegationScope, method, path string, status int) error {
+	f.entries = append(f.entries, accessEntry{delegationID: scope.ID, method: method, path: path, status: status})
+	return nil
+}
+
+func newDelegationRouter(scope *authz.DelegationScope, logger *fakeAccessLogger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", delegateID)
+		c.Next()
+	})
+	router.Use(middleware.InjectDelegation(&fakeResolver{scope: scope}, logger))
+
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt64("user_id")})
+	}
+	api := router.Group("/api")
+	api.GET("/investments/tax-settings", handler)
+	api.GET("/transactions", handler)
+	api.POST("/transactions", handler)
+	api.GET("/accounts/:id", handler)
+	api.GET("/analytics/reports", handler)
+	api.POST("/analytics/reports/category", handler)
+	api.POST("/analytics/reports/capital-gains", handler)
+	api.GET("/analytics/reports/:id/download", handler)
+	api.GET("/settings", handler)
+
+	return router
+}
+
+func delegateRequest(router *gin.Engine, method, path, actingFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if actingFor != "" {
+		req.Header.Set(authz.ActingForHeader, actingFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func taxScope() *authz.DelegationScope {
+	return &authz.DelegationScope{
+		ID:         7,
+		OwnerID:    ownerID,
+		DelegateID: delegateID,
+		Modules:    map[string]struct{}{"tax": {}},
+	}
+}
+
+func TestInjectDelegation_NoHeaderRunsAsSelf(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	router := newDelegationRouter(taxScope(), logger)
+
+	w := delegateRequest(router, http.MethodPost, "/api/transactions", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":20}`, w.Body.String())
+	assert.Empty(t, logger.entries)
+}
+
+func TestInjectDelegation_InvalidHeader(t *testing.T) {
+	router := newDelegationRouter(taxScope(), &fakeAccessLogger{})
+
+	for _, raw := range []string{"abc", "-1", "20"} {
+		w := delegateRequest(router, http.MethodGet, "/api/investments/tax-settings", raw)
+		assert.Equal(t, http.StatusBadRequest, w.Code, raw)
+	}
+}
+
+func TestInjectDelegation_WithoutGrant(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	router := newDelegationRouter(nil, logger)
+
+	w := delegateRequest(router, http.MethodGet, "/api/investments/tax-settings", "10")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, logger.entries)
+}
+
+// The request runs as the owner and its outcome lands in the access log
+func TestInjectDelegation_RunsAsOwnerAndLogs(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	router := newDelegationRouter(taxScope(), logger)
+
+	w := delegateRequest(router, http.MethodGet, "/api/investments/tax-settings", "10")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":10}`, w.Body.String())
+	require.Len(t, logger.entries, 1)
+	assert.Equal(t, accessEntry{delegationID: 7, method: http.MethodGet, path: "/api/investments/tax-settings", status: http.StatusOK}, logger.entries[0])
+}
+
+func TestInjectDelegation_RefusesModuleOutsideScope(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	router := newDelegationRouter(taxScope(), logger)
+
+	for _, path := range []string{"/api/transactions", "/api/settings"} {
+		w := delegateRequest(router, http.MethodGet, path, "10")
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+	}
+
+	require.Len(t, logger.entries, 2)
+	for _, e := range logger.entries {
+		assert.Equal(t, http.StatusForbidden, e.status)
+	}
+}
+
+func TestInjectDelegation_RefusesAccountOutsideScope(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	scope := &authz.DelegationScope{
+		ID:         8,
+		OwnerID:    ownerID,
+		DelegateID: delegateID,
+		AccountIDs: map[int64]struct{}{5: {}},
+	}
+	router := newDelegationRouter(scope, logger)
+
+	assert.Equal(t, http.StatusOK, delegateRequest(router, http.MethodGet, "/api/accounts/5", "10").Code)
+	assert.Equal(t, http.StatusForbidden, delegateRequest(router, http.MethodGet, "/api/accounts/6", "10").Code)
+	assert.Equal(t, http.StatusOK, delegateRequest(router, http.MethodGet, "/api/transactions?account_id=5", "10").Code)
+	assert.Equal(t, http.StatusForbidden, delegateRequest(router, http.MethodGet, "/api/transactions", "10").Code,
+		"unscoped listings are refused")
+
+	require.Len(t, logger.entries, 4)
+	assert.Equal(t, http.StatusForbidden, logger.entries[1].status)
+}
+
+func TestInjectDelegation_RefusesWrites(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	scope := &authz.DelegationScope{ID: 9, OwnerID: ownerID, DelegateID: delegateID}
+	router := newDelegationRouter(scope, logger)
+
+	assert.Equal(t, http.StatusForbidden, delegateRequest(router, http.MethodPost, "/api/transactions", "10").Code)
+	assert.Equal(t, http.StatusForbidden, delegateRequest(router, http.MethodPost, "/api/analytics/reports/category", "10").Code)
+
+	require.Len(t, logger.entries, 2)
+	assert.Equal(t, http.MethodPost, logger.entries[0].method)
+	assert.Equal(t, http.StatusForbidden, logger.entries[0].status)
+}
+
+// A tax-only advisor can produce, list and download the capital gains report
+func TestInjectDelegation_TaxDelegateCapitalGainsReport(t *testing.T) {
+	logger := &fakeAccessLogger{}
+	router := newDelegationRouter(taxScope(), logger)
+
+	w := delegateRequest(router, http.MethodPost, "/api/analytics/reports/capital-gains", "10")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":10}`, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, delegateRequest(router, http.MethodGet, "/api/analytics/reports", "10").Code)
+	assert.Equal(t, http.StatusOK, delegateRequest(router, http.MethodGet, "/api/analytics/reports/3/download", "10").Code)
+
+	require.Len(t, logger.entries, 3)
+	assert.Equal(t, "/api/analytics/reports/capital-gains", logger.entries[0].path)
+	assert.Equal(t, "/api/analytics/reports/3/download", logger.entries[2].path)
+}
@@ -18,6 +18,7 @@ type Account struct {
 	IsDefault         bool            `gorm:"type:boolean;not null;default:false" json:"is_default"`
 	IncludeInNetWorth bool             `gorm:"type:boolean;not null;default:true" json:"include_in_net_worth"`
 	CreditLimit       *decimal.Decimal `gorm:"type:decimal(19,4)" json:"credit_limit,omitempty"`
+	OwnershipPercent  *decimal.Decimal `gorm:"type:decimal(5,2)" json:"ownership_percent,omitempty"`
 	ImportID          *int64           `json:"import_id,omitempty"`
 	ExpectedBalance   decimal.Decimal `gorm:"type:decimal(19,4);not null;default:0" json:"expected_balance"`
 	BalanceProjection string          `gorm:"not null;enum(fixed,multiplier,percentage)" json:"balance_projection"`
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type AllocationTargetKind string
+
+const (
+	AllocationByAsset          AllocationTargetKind = "asset"
+	AllocationByInvestmentType AllocationTargetKind = "investment_type"
+	AllocationByAssetClass     AllocationTargetKind = "asset_class"
+)
+
+type AllocationTarget struct {
+	ID               int64                `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64                `gorm:"not null" json:"user_id"`
+	AccountID        *int64               `json:"account_id"`
+	Kind             AllocationTargetKind `gorm:"type:allocation_target_kind;not null" json:"kind"`
+	AssetID          *int64               `json:"asset_id,omitempty"`
+	InvestmentType   *InvestmentType      `gorm:"type:investment_type" json:"investment_type,omitempty"`
+	AssetClass       *string              `gorm:"type:varchar(50)" json:"asset_class,omitempty"`
+	TargetPercent    decimal.Decimal      `gorm:"type:decimal(7,4);not null" json:"target_percent"`
+	TolerancePercent decimal.Decimal      `gorm:"type:decimal(7,4);not null;default:5" json:"tolerance_percent"`
+	OutOfBandSince   *time.Time           `gorm:"type:date" json:"out_of_band_since,omitempty"`
+	CreatedAt        time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AllocationScope is a user's target set: one account, or the whole portfolio when AccountID is nil.
+type AllocationScope struct {
+	UserID    int64
+	AccountID *int64
+}
+
+type AllocationTargetReq struct {
+	AssetID          *int64           `json:"asset_id,omitempty"`
+	InvestmentType   *InvestmentType  `json:"investment_type,omitempty"`
+	AssetClass       *string          `json:"asset_class,omitempty"`
+	TargetPercent    decimal.Decimal  `json:"target_percent" validate:"required"`
+	TolerancePercent *decimal.Decimal `json:"tolerance_percent,omitempty"`
+}
+
+type AllocationTargetsReq struct {
+	AccountID *int64                `json:"account_id,omitempty"`
+	Kind      AllocationTargetKind  `json:"kind" validate:"required,oneof=asset investment_type asset_class"`
+	Targets   []AllocationTargetReq `json:"targets" validate:"required,min=1,dive"`
+}
+
+type AllocationTradeSuggestion struct {
+	AssetID   int64            `json:"asset_id"`
+	Ticker    string           `json:"ticker"`
+	TradeType TradeType        `json:"trade_type"`
+	Amount    decimal.Decimal  `json:"amount"`
+	Price     *decimal.Decimal `json:"price"`
+	// Quantity is nil when the asset has no known price.
+	Quantity *decimal.Decimal `json:"quantity"`
+}
+
+type AllocationBucket struct {
+	TargetID         int64                       `json:"target_id"`
+	Key              string                      `json:"key"`
+	Label            string                      `json:"label"`
+	TargetPercent    decimal.Decimal             `json:"target_percent"`
+	TolerancePercent decimal.Decimal             `json:"tolerance_percent"`
+	CurrentValue     decimal.Decimal             `json:"current_value"`
+	CurrentPercent   decimal.Decimal             `json:"current_percent"`
+	Drift            decimal.Decimal             `json:"drift"`
+	OutsideBand      bool                        `json:"outside_band"`
+	TargetValue      decimal.Decimal             `json:"target_value"`
+	Suggested        decimal.Decimal             `json:"suggested"`
+	Trades           []AllocationTradeSuggestion `json:"trades"`
+}
+
+type AllocationUntargeted struct {
+	AssetID int64           `json:"asset_id"`
+	Ticker  string          `json:"ticker"`
+	Value   decimal.Decimal `json:"value"`
+}
+
+type AllocationReport struct {
+	AccountID    *int64                 `json:"account_id"`
+	Kind         AllocationTargetKind   `json:"kind"`
+	Currency     string                 `json:"currency"`
+	Mode         string                 `json:"mode"`
+	TotalValue   decimal.Decimal        `json:"total_value"`
+	Contribution decimal.Decimal        `json:"contribution"`
+	MaxDrift     decimal.Decimal        `json:"max_drift"`
+	OutsideBand  bool                   `json:"outside_band"`
+	Buckets      []AllocationBucket     `json:"buckets"`
+	Untargeted   []AllocationUntargeted `json:"untargeted"`
+}
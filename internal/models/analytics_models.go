@@ -27,12 +27,62 @@ type NetWorthResponse struct {
 	Current   ChartPoint   `json:"current"`
 	Change    *Change      `json:"change,omitempty"`
 	AssetType *string      `json:"asset_type"`
+	// Benchmarks overlay the tickers attached to the portfolio or account.
+	Benchmarks []BenchmarkSeries `json:"benchmarks,omitempty"`
 }
 
 type AssetChartResponse struct {
 	Currency          string       `json:"currency"`
 	MarketValuePoints []ChartPoint `json:"market_value_points"`
 	CostBasisPoints   []ChartPoint `json:"cost_basis_points"`
+	// Benchmarks are scaled to the first market value point.
+	Benchmarks []BenchmarkSeries `json:"benchmarks,omitempty"`
+}
+
+// PerformanceMetrics holds the returns of an asset, an investment account or the
+// whole portfolio over a range. Returns are fractions (0.05 = 5%).
+type PerformanceMetrics struct {
+	Scope               string           `json:"scope"`
+	Currency            string           `json:"currency"`
+	From                time.Time        `json:"from"`
+	To                  time.Time        `json:"to"`
+	StartValue          decimal.Decimal  `json:"start_value"`
+	EndValue            decimal.Decimal  `json:"end_value"`
+	NetContributions    decimal.Decimal  `json:"net_contributions"`
+	TimeWeightedReturn  decimal.Decimal  `json:"time_weighted_return"`
+	AnnualizedTWR       decimal.Decimal  `json:"annualized_twr"`
+	MoneyWeightedReturn *decimal.Decimal `json:"money_weighted_return"`
+}
+
+// CurrencyExposure breaks net worth down by the currency it is held in, converted
+// to the user's default currency. Holdings count in their asset's currency and cash
+// in its account's. FXGain is what exchange rate moves added to the converted value
+// over the range; PriceGain is what holdings earned in their own currency.
+type CurrencyExposure struct {
+	Currency   string                  `json:"currency"`
+	From       time.Time               `json:"from"`
+	To         time.Time               `json:"to"`
+	Total      decimal.Decimal         `json:"total"`
+	FXGain     decimal.Decimal         `json:"fx_gain"`
+	PriceGain  decimal.Decimal         `json:"price_gain"`
+	Points     []ChartPoint            `json:"points"`
+	Currencies []CurrencyExposureSlice `json:"currencies"`
+}
+
+// CurrencyExposureSlice is one currency's part of CurrencyExposure. Cash and
+// Holdings are in the currency itself, everything else in the default currency.
+// RateMissing means no exchange rate was stored and 1:1 was assumed.
+type CurrencyExposureSlice struct {
+	Currency    string          `json:"currency"`
+	Cash        decimal.Decimal `json:"cash"`
+	Holdings    decimal.Decimal `json:"holdings"`
+	Rate        decimal.Decimal `json:"rate"`
+	Value       decimal.Decimal `json:"value"`
+	Share       decimal.Decimal `json:"share"`
+	FXGain      decimal.Decimal `json:"fx_gain"`
+	PriceGain   decimal.Decimal `json:"price_gain"`
+	RateMissing bool            `json:"rate_missing,omitempty"`
+	Points      []ChartPoint    `json:"points"`
 }
 
 type MonthlyCategoryUsage struct {
@@ -243,6 +293,47 @@ type CategoryReportParams struct {
 	AccountTypeOnly bool
 }
 
+type CapitalGainsReportParams struct {
+	Year     int
+	Format   string // "xlsx" or "csv"
+	Currency string // the user's default currency when the report was requested
+}
+
+// RealizedGainRow is one sell in a capital gains report, together with the buy
+// lots it was matched against under the asset's cost-basis method.
+type RealizedGainRow struct {
+	TradeID          int64
+	Ticker           string
+	AssetName        string
+	InvestmentType   InvestmentType
+	Currency         string
+	SellDate         time.Time
+	AcquisitionDates []time.Time
+	Quantity         decimal.Decimal
+	Proceeds         decimal.Decimal
+	CostBasis        decimal.Decimal
+	Fees             decimal.Decimal
+	Gain             decimal.Decimal
+	DaysHeld         int
+	Bracket          *InvestmentTaxBracket
+	TaxableGain      decimal.Decimal
+	TaxDue           decimal.Decimal
+	Rate             decimal.Decimal // Currency into the summary's currency on SellDate
+}
+
+// CapitalGainsSummary totals are in the report currency; see RealizedGainRow.Rate.
+type CapitalGainsSummary struct {
+	Year           int
+	LossOffsetting bool
+	Rows           []RealizedGainRow
+	TotalProceeds  decimal.Decimal
+	TotalGains     decimal.Decimal
+	TotalLosses    decimal.Decimal
+	NetGain        decimal.Decimal
+	TaxableGain    decimal.Decimal
+	TaxDue         decimal.Decimal
+}
+
 type ReportAccountScope struct {
 	Name    string `gorm:"column:name"`
 	Type    string `gorm:"column:type"`
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Benchmark is a ticker tracked only for comparison. It is shared between users,
+// so its price history is fetched once.
+type Benchmark struct {
+	ID             int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Ticker         string         `gorm:"type:varchar(20);not null" json:"ticker"`
+	InvestmentType InvestmentType `gorm:"type:investment_type;not null" json:"investment_type"`
+	Currency       string         `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type UserBenchmark struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      int64     `gorm:"not null" json:"user_id"`
+	AccountID   *int64    `json:"account_id"`
+	BenchmarkID int64     `gorm:"not null" json:"benchmark_id"`
+	Benchmark   Benchmark `gorm:"foreignKey:BenchmarkID" json:"benchmark"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BenchmarkPriceHistory is a benchmark's row in asset_price_history.
+type BenchmarkPriceHistory struct {
+	BenchmarkID int64           `json:"benchmark_id"`
+	AsOf        time.Time       `gorm:"type:date" json:"as_of"`
+	Price       decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"price"`
+	Currency    string          `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (BenchmarkPriceHistory) TableName() string {
+	return "asset_price_history"
+}
+
+type BenchmarkReq struct {
+	AccountID      *int64         `json:"account_id,omitempty"`
+	Ticker         string         `json:"ticker" validate:"required"`
+	InvestmentType InvestmentType `json:"investment_type" validate:"required"`
+}
+
+// BenchmarkSeries is a benchmark rescaled onto a chart: each point is what the
+// chart's starting value would be worth had it tracked the benchmark instead.
+type BenchmarkSeries struct {
+	BenchmarkID int64        `json:"benchmark_id"`
+	Ticker      string       `json:"ticker"`
+	Points      []ChartPoint `json:"points"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CategoryBudgetRollover string
+
+const (
+	CategoryBudgetRolloverNone CategoryBudgetRollover = "none"
+	// CategoryBudgetRolloverUnspent adds what was left over to the next month.
+	CategoryBudgetRolloverUnspent CategoryBudgetRollover = "unspent"
+	// CategoryBudgetRolloverOverspent takes what was overspent out of the next month.
+	CategoryBudgetRolloverOverspent CategoryBudgetRollover = "overspent"
+	CategoryBudgetRolloverBoth      CategoryBudgetRollover = "both"
+)
+
+// CategoryBudget caps monthly spending on one category or on a category group.
+// Amount applies to every month without its own entry in Months.
+type CategoryBudget struct {
+	ID         int64                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64                  `gorm:"not null" json:"user_id"`
+	CategoryID *int64                 `json:"category_id,omitempty"`
+	GroupID    *int64                 `json:"group_id,omitempty"`
+	Amount     decimal.Decimal        `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Rollover   CategoryBudgetRollover `gorm:"type:category_budget_rollover;not null;default:none" json:"rollover"`
+	StartMonth time.Time              `gorm:"type:date;not null" json:"start_month"`
+	IsActive   bool                   `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt  time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Months []CategoryBudgetMonth `gorm:"foreignKey:BudgetID" json:"months"`
+}
+
+// CategoryBudgetMonth overrides a budget's amount for one month.
+type CategoryBudgetMonth struct {
+	ID        int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	BudgetID  int64           `gorm:"not null" json:"budget_id"`
+	Month     time.Time       `gorm:"type:date;not null" json:"month"`
+	Amount    decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"amount"`
+	CreatedAt time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type CategoryBudgetReq struct {
+	CategoryID *int64                 `json:"category_id,omitempty"`
+	GroupID    *int64                 `json:"group_id,omitempty"`
+	Amount     decimal.Decimal        `json:"amount" validate:"required"`
+	Rollover   CategoryBudgetRollover `json:"rollover" validate:"omitempty,oneof=none unspent overspent both"`
+	StartMonth *string                `json:"start_month,omitempty"`
+	IsActive   *bool                  `json:"is_active,omitempty"`
+}
+
+type CategoryBudgetMonthReq struct {
+	Month  string          `json:"month" validate:"required"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// BudgetReport compares each active budget with what was spent in a month.
+type BudgetReport struct {
+	Month      time.Time          `json:"month"`
+	Lines      []BudgetReportLine `json:"lines"`
+	Unbudgeted decimal.Decimal    `json:"unbudgeted"` // spending in categories no budget covers
+}
+
+type BudgetReportLine struct {
+	BudgetID    int64                  `json:"budget_id"`
+	CategoryID  *int64                 `json:"category_id,omitempty"`
+	GroupID     *int64                 `json:"group_id,omitempty"`
+	Name        string                 `json:"name"`
+	Rollover    CategoryBudgetRollover `json:"rollover"`
+	Planned     decimal.Decimal        `json:"planned"`
+	Carryover   decimal.Decimal        `json:"carryover"`
+	Available   decimal.Decimal        `json:"available"`
+	Spent       decimal.Decimal        `json:"spent"`
+	Remaining   decimal.Decimal        `json:"remaining"`
+	PercentUsed decimal.Decimal        `json:"percent_used"`
+}
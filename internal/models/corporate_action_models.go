@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CorporateActionType string
+
+const (
+	CorporateActionSplit        CorporateActionType = "split"
+	CorporateActionReverseSplit CorporateActionType = "reverse_split"
+	CorporateActionTickerChange CorporateActionType = "ticker_change"
+	CorporateActionMerger       CorporateActionType = "merger"
+	CorporateActionSpinOff      CorporateActionType = "spin_off"
+)
+
+// CorporateAction records an event that retroactively changed a holding.
+// RatioFrom old units became RatioTo new units on EffectiveDate; a 10:1 split is
+// RatioFrom 1, RatioTo 10, a 1:10 reverse split the opposite. For spin-offs the
+// ratio describes how many new units each parent unit received.
+type CorporateAction struct {
+	ID               int64               `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64               `gorm:"not null" json:"user_id"`
+	AssetID          int64               `gorm:"not null;index:idx_ca_asset" json:"asset_id"`
+	ActionType       CorporateActionType `gorm:"type:corporate_action_type;not null" json:"action_type"`
+	EffectiveDate    time.Time           `gorm:"type:date;not null" json:"effective_date"`
+	RatioFrom        decimal.Decimal     `gorm:"type:decimal(19,8);not null;default:1" json:"ratio_from"`
+	RatioTo          decimal.Decimal     `gorm:"type:decimal(19,8);not null;default:1" json:"ratio_to"`
+	OldTicker        *string             `gorm:"type:varchar(20)" json:"old_ticker,omitempty"`
+	NewTicker        *string             `gorm:"type:varchar(20)" json:"new_ticker,omitempty"`
+	OldName          *string             `gorm:"type:varchar(255)" json:"old_name,omitempty"`
+	NewName          *string             `gorm:"type:varchar(255)" json:"new_name,omitempty"`
+	SpunOffAssetID   *int64              `json:"spun_off_asset_id,omitempty"`
+	CostBasisPercent *decimal.Decimal    `gorm:"type:decimal(7,4)" json:"cost_basis_percent,omitempty"`
+	Notes            *string             `gorm:"type:varchar(255)" json:"notes,omitempty"`
+	CreatedAt        time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Factor is the multiplier applied to quantities held before the action.
+func (c CorporateAction) Factor() decimal.Decimal {
+	if c.RatioFrom.IsZero() {
+		return decimal.NewFromInt(1)
+	}
+	return c.RatioTo.Div(c.RatioFrom)
+}
+
+type CorporateActionReq struct {
+	ActionType       CorporateActionType `json:"action_type" validate:"required,oneof=split reverse_split ticker_change merger spin_off"`
+	EffectiveDate    time.Time           `json:"effective_date" validate:"required"`
+	RatioFrom        *decimal.Decimal    `json:"ratio_from,omitempty"`
+	RatioTo          *decimal.Decimal    `json:"ratio_to,omitempty"`
+	NewTicker        *string             `json:"new_ticker,omitempty" validate:"omitempty,max=20"`
+	NewName          *string             `json:"new_name,omitempty" validate:"omitempty,max=255"`
+	CostBasisPercent *decimal.Decimal    `json:"cost_basis_percent,omitempty"`
+	Notes            *string             `json:"notes,omitempty" validate:"omitempty,max=255"`
+}
+
+// InvestmentBasisAdjustment is cost basis a corporate action moved off a buy's open
+// units on EffectiveDate. The buy's ValueAtBuy and Fee already exclude it; units sold
+// before the date are matched against the basis as it was, so realized results
+// don't change.
+type InvestmentBasisAdjustment struct {
+	ID                int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	TradeID           int64           `gorm:"not null" json:"trade_id"`
+	CorporateActionID int64           `gorm:"not null" json:"corporate_action_id"`
+	EffectiveDate     time.Time       `gorm:"type:date;not null" json:"effective_date"`
+	ValueMoved        decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"value_moved"`
+	FeeMoved          decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"fee_moved"`
+	CreatedAt         time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
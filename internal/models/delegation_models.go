@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+type DelegationModule string
+
+const (
+	DelegationModuleAccounts     DelegationModule = "accounts"
+	DelegationModuleTransactions DelegationModule = "transactions"
+	DelegationModuleInvestments  DelegationModule = "investments"
+	DelegationModuleTax          DelegationModule = "tax"
+	DelegationModuleAnalytics    DelegationModule = "analytics"
+	DelegationModuleSavings      DelegationModule = "savings"
+)
+
+type AccessDelegation struct {
+	ID             int64                     `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID        int64                     `gorm:"not null" json:"owner_id"`
+	Owner          *User                     `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
+	DelegateID     *int64                    `json:"delegate_id,omitempty"`
+	Email          string                    `gorm:"type:varchar(255);not null" json:"email"`
+	Label          *string                   `gorm:"type:varchar(150)" json:"label,omitempty"`
+	ExpiresAt      time.Time                 `gorm:"not null" json:"expires_at"`
+	RevokedAt      *time.Time                `json:"revoked_at,omitempty"`
+	LastAccessedAt *time.Time                `json:"last_accessed_at,omitempty"`
+	Modules        []AccessDelegationModule  `gorm:"foreignKey:DelegationID" json:"modules"`
+	Accounts       []AccessDelegationAccount `gorm:"foreignKey:DelegationID" json:"accounts"`
+	CreatedAt      time.Time                 `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time                 `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsActive reports whether the delegation can still be used at t.
+func (d *AccessDelegation) IsActive(t time.Time) bool {
+	return d.RevokedAt == nil && d.ExpiresAt.After(t)
+}
+
+type AccessDelegationModule struct {
+	ID           int64            `gorm:"primaryKey;autoIncrement" json:"-"`
+	DelegationID int64            `gorm:"not null" json:"-"`
+	Module       DelegationModule `gorm:"type:delegation_module;not null" json:"module"`
+}
+
+type AccessDelegationAccount struct {
+	ID           int64 `gorm:"primaryKey;autoIncrement" json:"-"`
+	DelegationID int64 `gorm:"not null" json:"-"`
+	AccountID    int64 `gorm:"not null" json:"account_id"`
+}
+
+type AccessDelegationReq struct {
+	Email      string             `json:"email" validate:"required,email"`
+	Label      *string            `json:"label"`
+	ExpiresAt  time.Time          `json:"expires_at" validate:"required"`
+	Modules    []DelegationModule `json:"modules"`
+	AccountIDs []int64            `json:"account_ids"`
+}
+
+type AccessDelegationUpdateReq struct {
+	Label      *string            `json:"label"`
+	ExpiresAt  time.Time          `json:"expires_at" validate:"required"`
+	Modules    []DelegationModule `json:"modules"`
+	AccountIDs []int64            `json:"account_ids"`
+}
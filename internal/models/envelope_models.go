@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// EnvelopeBudget marks a user as budgeting with envelopes from StartMonth on.
+type EnvelopeBudget struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64     `gorm:"not null;uniqueIndex" json:"user_id"`
+	StartMonth time.Time `gorm:"type:date;not null" json:"start_month"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type Envelope struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"not null" json:"user_id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Categories []EnvelopeCategory `gorm:"foreignKey:EnvelopeID" json:"categories"`
+}
+
+// EnvelopeCategory ties a category's spending to an envelope. A category
+// belongs to at most one of the user's envelopes.
+type EnvelopeCategory struct {
+	EnvelopeID int64 `gorm:"primaryKey" json:"envelope_id"`
+	CategoryID int64 `gorm:"primaryKey" json:"category_id"`
+	UserID     int64 `gorm:"not null" json:"user_id"`
+}
+
+type EnvelopeMovementKind string
+
+const (
+	// EnvelopeMovementAssign moves money between the to-be-assigned pool and an envelope.
+	EnvelopeMovementAssign EnvelopeMovementKind = "assign"
+	EnvelopeMovementMove   EnvelopeMovementKind = "move"
+	// EnvelopeMovementCover moves money into an overspent envelope.
+	EnvelopeMovementCover EnvelopeMovementKind = "cover"
+)
+
+// EnvelopeMovement moves money within a month. A nil side is the
+// to-be-assigned pool.
+type EnvelopeMovement struct {
+	ID             int64                `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64                `gorm:"not null" json:"user_id"`
+	Month          time.Time            `gorm:"type:date;not null" json:"month"`
+	FromEnvelopeID *int64               `json:"from_envelope_id,omitempty"`
+	ToEnvelopeID   *int64               `json:"to_envelope_id,omitempty"`
+	Amount         decimal.Decimal      `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Kind           EnvelopeMovementKind `gorm:"type:envelope_movement_kind;not null" json:"kind"`
+	Note           *string              `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CreatedAt      time.Time            `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type EnvelopeModeReq struct {
+	StartMonth string `json:"start_month" validate:"required"`
+}
+
+type EnvelopeReq struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	CategoryIDs []int64 `json:"category_ids"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// EnvelopeAssignReq assigns money from the pool to an envelope, or returns it
+// to the pool when Amount is negative.
+type EnvelopeAssignReq struct {
+	Month      string          `json:"month" validate:"required"`
+	EnvelopeID int64           `json:"envelope_id" validate:"required"`
+	Amount     decimal.Decimal `json:"amount" validate:"required"`
+	Note       *string         `json:"note,omitempty" validate:"omitempty,max=255"`
+}
+
+type EnvelopeMoveReq struct {
+	Month          string          `json:"month" validate:"required"`
+	FromEnvelopeID int64           `json:"from_envelope_id" validate:"required"`
+	ToEnvelopeID   int64           `json:"to_envelope_id" validate:"required"`
+	Amount         decimal.Decimal `json:"amount" validate:"required"`
+	Note           *string         `json:"note,omitempty" validate:"omitempty,max=255"`
+}
+
+// EnvelopeCoverReq covers an overspent envelope from another one. Without an
+// amount the whole overspending is covered.
+type EnvelopeCoverReq struct {
+	Month          string           `json:"month" validate:"required"`
+	EnvelopeID     int64            `json:"envelope_id" validate:"required"`
+	FromEnvelopeID int64            `json:"from_envelope_id" validate:"required"`
+	Amount         *decimal.Decimal `json:"amount,omitempty"`
+	Note           *string          `json:"note,omitempty" validate:"omitempty,max=255"`
+}
+
+// ExpectedIncome is an income template run that hasn't happened yet.
+type ExpectedIncome struct {
+	TemplateID int64           `json:"template_id"`
+	Name       string          `json:"name"`
+	Date       time.Time       `json:"date"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+// EnvelopeMonth is the state of envelope budgeting for one month.
+type EnvelopeMonth struct {
+	Month          time.Time        `json:"month"`
+	Income         decimal.Decimal  `json:"income"`
+	ExpectedIncome decimal.Decimal  `json:"expected_income"`
+	Expected       []ExpectedIncome `json:"expected"`
+	Assigned       decimal.Decimal  `json:"assigned"`
+	// Unenveloped is spending in categories that belong to no envelope.
+	Unenveloped decimal.Decimal `json:"unenveloped"`
+	// OverspentCarried is last month's overspending nobody covered.
+	OverspentCarried decimal.Decimal     `json:"overspent_carried"`
+	ToBeAssigned     decimal.Decimal     `json:"to_be_assigned"`
+	Envelopes        []EnvelopeMonthLine `json:"envelopes"`
+}
+
+type EnvelopeMonthLine struct {
+	EnvelopeID int64           `json:"envelope_id"`
+	Name       string          `json:"name"`
+	IsActive   bool            `json:"is_active"`
+	Carryover  decimal.Decimal `json:"carryover"`
+	Assigned   decimal.Decimal `json:"assigned"`
+	Spent      decimal.Decimal `json:"spent"`
+	Available  decimal.Decimal `json:"available"`
+	Overspent  decimal.Decimal `json:"overspent"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type HouseholdRole string
+
+const (
+	HouseholdRoleOwner  HouseholdRole = "owner"
+	HouseholdRoleMember HouseholdRole = "member"
+)
+
+type AccountSharePermission string
+
+const (
+	AccountSharePermissionView   AccountSharePermission = "view"
+	AccountSharePermissionManage AccountSharePermission = "manage"
+)
+
+type Household struct {
+	ID        int64             `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string            `gorm:"type:varchar(150);not null" json:"name"`
+	OwnerID   int64             `gorm:"not null" json:"owner_id"`
+	Members   []HouseholdMember `gorm:"foreignKey:HouseholdID" json:"members,omitempty"`
+	CreatedAt time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type HouseholdMember struct {
+	ID          int64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	HouseholdID int64         `gorm:"not null" json:"household_id"`
+	UserID      int64         `gorm:"not null;uniqueIndex" json:"user_id"`
+	User        *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Role        HouseholdRole `gorm:"type:household_role;not null;default:member" json:"role"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type AccountShare struct {
+	ID               int64                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccountID        int64                  `gorm:"not null" json:"account_id"`
+	Account          *Account               `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	HouseholdID      int64                  `gorm:"not null" json:"household_id"`
+	UserID           int64                  `gorm:"not null" json:"user_id"`
+	Permission       AccountSharePermission `gorm:"type:account_share_permission;not null;default:view" json:"permission"`
+	OwnershipPercent *decimal.Decimal       `gorm:"type:decimal(5,2)" json:"ownership_percent,omitempty"`
+	CreatedAt        time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type HouseholdReq struct {
+	Name string `json:"name" validate:"required,min=1,max=150"`
+}
+
+type HouseholdInviteReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type AccountShareReq struct {
+	UserID           int64                  `json:"user_id" validate:"required"`
+	Permission       AccountSharePermission `json:"permission" validate:"required"`
+	OwnershipPercent *decimal.Decimal       `json:"ownership_percent,omitempty"`
+}
+
+type AccountOwnershipReq struct {
+	OwnershipPercent *decimal.Decimal `json:"ownership_percent"`
+}
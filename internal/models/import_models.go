@@ -2,6 +2,16 @@ package models
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Broker statement imports, stored as the import's sub-type.
+const (
+	BrokerIBKR       = "ibkr"
+	BrokerDegiro     = "degiro"
+	BrokerTrading212 = "trading212"
+	BrokerCSV        = "broker_csv"
 )
 
 type Import struct {
@@ -86,3 +96,66 @@ type TransferMapping struct {
 	Name      string `json:"name"`
 	AccountID int64  `json:"account_id"`
 }
+
+type BrokerActivityKind string
+
+const (
+	BrokerActivityBuy      BrokerActivityKind = "buy"
+	BrokerActivitySell     BrokerActivityKind = "sell"
+	BrokerActivityDividend BrokerActivityKind = "dividend"
+)
+
+// BrokerActivity is one trade or dividend read from a broker statement. Amount
+// is the gross dividend; Fee and TaxWithheld are in Currency.
+type BrokerActivity struct {
+	Line           int                `json:"line"`
+	Kind           BrokerActivityKind `json:"kind"`
+	Date           time.Time          `json:"date"`
+	Ticker         string             `json:"ticker"`
+	ISIN           string             `json:"isin"`
+	Name           string             `json:"name"`
+	InvestmentType InvestmentType     `json:"investment_type"`
+	Quantity       decimal.Decimal    `json:"quantity"`
+	Price          decimal.Decimal    `json:"price"`
+	Fee            decimal.Decimal    `json:"fee"`
+	Amount         decimal.Decimal    `json:"amount"`
+	TaxWithheld    decimal.Decimal    `json:"tax_withheld"`
+	Currency       string             `json:"currency"`
+}
+
+// BrokerColumnMapping names the header of each column in a generic broker CSV.
+type BrokerColumnMapping struct {
+	Date           string `json:"date" validate:"required"`
+	Type           string `json:"type" validate:"required"`
+	Ticker         string `json:"ticker" validate:"required"`
+	Quantity       string `json:"quantity" validate:"required"`
+	Price          string `json:"price" validate:"required"`
+	Currency       string `json:"currency" validate:"required"`
+	Name           string `json:"name,omitempty"`
+	Fee            string `json:"fee,omitempty"`
+	Amount         string `json:"amount,omitempty"`
+	TaxWithheld    string `json:"tax_withheld,omitempty"`
+	InvestmentType string `json:"investment_type,omitempty"`
+	// DateFormat is a Go time layout; common formats are tried when empty.
+	DateFormat string `json:"date_format,omitempty"`
+	Delimiter  string `json:"delimiter,omitempty" validate:"omitempty,len=1"`
+	// DecimalSeparator is "." or ","; it is read from the file's numbers when empty.
+	DecimalSeparator string `json:"decimal_separator,omitempty" validate:"omitempty,len=1"`
+}
+
+type BrokerImportReq struct {
+	Broker    string `json:"broker" validate:"required,oneof=ibkr degiro trading212 broker_csv"`
+	AccountID int64  `json:"account_id" validate:"required"`
+	// Tickers maps an ISIN, product name or broker symbol to the ticker to price
+	// it by. Products left unresolved are imported with manual pricing.
+	Tickers map[string]string    `json:"tickers,omitempty"`
+	Mapping *BrokerColumnMapping `json:"mapping,omitempty"`
+}
+
+type BrokerImportResult struct {
+	ImportID  int64    `json:"import_id"`
+	Assets    int      `json:"assets"`
+	Trades    int      `json:"trades"`
+	Dividends int      `json:"dividends"`
+	Skipped   []string `json:"skipped"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type InterestCompounding string
+
+const (
+	InterestCompoundingDaily     InterestCompounding = "daily"
+	InterestCompoundingMonthly   InterestCompounding = "monthly"
+	InterestCompoundingQuarterly InterestCompounding = "quarterly"
+	InterestCompoundingYearly    InterestCompounding = "yearly"
+)
+
+type AccountInterestConfig struct {
+	ID               int64                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64                 `gorm:"not null" json:"user_id"`
+	AccountID        int64                 `gorm:"not null;uniqueIndex" json:"account_id"`
+	Account          *Account              `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	AnnualRate       decimal.Decimal       `gorm:"type:decimal(7,4);not null" json:"annual_rate"`
+	Compounding      InterestCompounding   `gorm:"type:interest_compounding;not null;default:monthly" json:"compounding"`
+	PayoutDayOfMonth int                   `gorm:"type:smallint;not null;default:1" json:"payout_day_of_month"`
+	IsActive         bool                  `gorm:"type:boolean;not null;default:true" json:"is_active"`
+	AccruedAmount    decimal.Decimal       `gorm:"type:decimal(19,4);not null;default:0" json:"accrued_amount"`
+	AccruedThrough   *time.Time            `gorm:"type:date" json:"accrued_through,omitempty"`
+	LastPayoutAt     *time.Time            `gorm:"type:date" json:"last_payout_at,omitempty"`
+	Tiers            []AccountInterestTier `gorm:"foreignKey:ConfigID" json:"tiers"`
+	CreatedAt        time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type AccountInterestTier struct {
+	ID         int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID   int64           `gorm:"not null" json:"config_id"`
+	MinBalance decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"min_balance"`
+	AnnualRate decimal.Decimal `gorm:"type:decimal(7,4);not null" json:"annual_rate"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type AccountInterestTierReq struct {
+	MinBalance decimal.Decimal `json:"min_balance"`
+	AnnualRate decimal.Decimal `json:"annual_rate" validate:"required"`
+}
+
+type AccountInterestConfigReq struct {
+	AnnualRate       decimal.Decimal          `json:"annual_rate" validate:"required"`
+	Compounding      InterestCompounding      `json:"compounding" validate:"required"`
+	PayoutDayOfMonth int                      `json:"payout_day_of_month" validate:"required,min=1,max=31"`
+	IsActive         bool                     `json:"is_active"`
+	Tiers            []AccountInterestTierReq `json:"tiers,omitempty"`
+}
+
+type InterestPreview struct {
+	AccountID      int64               `json:"account_id"`
+	Balance        decimal.Decimal     `json:"balance"`
+	EffectiveRate  decimal.Decimal     `json:"effective_rate"`
+	Compounding    InterestCompounding `json:"compounding"`
+	ExpectedYearly decimal.Decimal     `json:"expected_yearly"`
+	AccruedAmount  decimal.Decimal     `json:"accrued_amount"`
+	NextPayoutDate time.Time           `json:"next_payout_date"`
+	Currency       string              `json:"currency"`
+}
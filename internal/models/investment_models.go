@@ -8,12 +8,59 @@ import (
 
 type InvestmentType string
 
+// Prices are quoted per unit, except for bonds (percent of face value),
+// commodities (per troy ounce) and options (premium per underlying share).
 const (
-	InvestmentStock  InvestmentType = "stock"
-	InvestmentETF    InvestmentType = "etf"
-	InvestmentCrypto InvestmentType = "crypto"
+	InvestmentStock      InvestmentType = "stock"
+	InvestmentETF        InvestmentType = "etf"
+	InvestmentCrypto     InvestmentType = "crypto"
+	InvestmentBond       InvestmentType = "bond"
+	InvestmentMutualFund InvestmentType = "mutual_fund"
+	InvestmentCommodity  InvestmentType = "commodity"
+	InvestmentOption     InvestmentType = "option"
 )
 
+func (t InvestmentType) Valid() bool {
+	switch t {
+	case InvestmentStock, InvestmentETF, InvestmentCrypto,
+		InvestmentBond, InvestmentMutualFund, InvestmentCommodity, InvestmentOption:
+		return true
+	}
+	return false
+}
+
+type CommodityUnit string
+
+const (
+	CommodityGram      CommodityUnit = "g"
+	CommodityKilogram  CommodityUnit = "kg"
+	CommodityTroyOunce CommodityUnit = "oz"
+)
+
+type OptionType string
+
+const (
+	OptionCall OptionType = "call"
+	OptionPut  OptionType = "put"
+)
+
+// InstrumentTerms are the contract details of bonds, commodities and options.
+// They stay empty for the other types and are fixed once the asset exists.
+type InstrumentTerms struct {
+	FaceValue *decimal.Decimal `gorm:"type:decimal(19,4)" json:"face_value,omitempty"`
+	// CouponRate is the annual coupon in percent of face value.
+	CouponRate *decimal.Decimal `gorm:"type:decimal(7,4)" json:"coupon_rate,omitempty"`
+	// CouponFrequency is the number of coupons per year; 0 for zero-coupon bonds.
+	CouponFrequency *int             `json:"coupon_frequency,omitempty"`
+	MaturityDate    *time.Time       `gorm:"type:date" json:"maturity_date,omitempty"`
+	Unit            *CommodityUnit   `gorm:"type:varchar(10)" json:"unit,omitempty"`
+	OptionType      *OptionType      `gorm:"type:varchar(4)" json:"option_type,omitempty"`
+	StrikePrice     *decimal.Decimal `gorm:"type:decimal(19,4)" json:"strike_price,omitempty"`
+	ExpiryDate      *time.Time       `gorm:"type:date" json:"expiry_date,omitempty"`
+	// Multiplier is the number of underlying shares per contract.
+	Multiplier *decimal.Decimal `gorm:"type:decimal(19,4)" json:"multiplier,omitempty"`
+}
+
 type InvestmentAsset struct {
 	ID                int64            `gorm:"primaryKey;autoIncrement" json:"id"`
 	AccountID         int64            `gorm:"not null;index:idx_assets_account" json:"account_id"`
@@ -31,13 +78,29 @@ type InvestmentAsset struct {
 	ProfitLossPercent decimal.Decimal  `gorm:"type:decimal(10,2);not null;default:0" json:"profit_loss_percent"`
 	LastPriceUpdate   *time.Time       `json:"last_price_update"`
 	Currency          string           `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	CostBasisMethod   *CostBasisMethod `gorm:"type:cost_basis_method" json:"cost_basis_method"`
+	AssetClass        *string          `gorm:"type:varchar(50)" json:"asset_class"`
+	PriceProvider     *string          `gorm:"type:varchar(20)" json:"price_provider"`
+	ManualPricing     bool             `gorm:"not null;default:false" json:"manual_pricing"`
+	SplitHolds        int              `gorm:"not null;default:0" json:"split_holds"`
+	LastCouponDate    *time.Time       `gorm:"type:date" json:"last_coupon_date,omitempty"`
 	Account           Account          `json:"account"`
 	ImportID          *int64           `json:"import_id,omitempty"`
 	TaxSummary        *AssetTaxSummary `gorm:"-" json:"tax_summary,omitempty"`
 	CreatedAt         time.Time        `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt         time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	InstrumentTerms
 }
 
+type CostBasisMethod string
+
+const (
+	CostBasisFIFO        CostBasisMethod = "fifo"
+	CostBasisLIFO        CostBasisMethod = "lifo"
+	CostBasisAverage     CostBasisMethod = "average"
+	CostBasisSpecificLot CostBasisMethod = "specific_lot"
+)
+
 type TradeType string
 
 const (
@@ -46,27 +109,70 @@ const (
 )
 
 type InvestmentTrade struct {
-	ID                int64           `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID            int64           `gorm:"not null" json:"user_id"`
-	AssetID           int64           `gorm:"not null;index:idx_inv_trans_asset" json:"asset_id"`
-	TxnDate           time.Time       `gorm:"type:date;not null;index:idx_inv_trans_date" json:"txn_date"`
-	TradeType         TradeType       `gorm:"type:varchar(4);not null" json:"trade_type"`
-	Quantity          decimal.Decimal `gorm:"type:decimal(19,8);not null" json:"quantity"`
-	Fee               decimal.Decimal `gorm:"type:decimal(19,4);not null;default:0" json:"fee"`
-	PricePerUnit      decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"price_per_unit"`
-	ValueAtBuy        decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"value_at_buy"`
-	CurrentValue      decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"current_value"`
-	RealizedValue     decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"realized_value"`
-	ProfitLoss        decimal.Decimal `gorm:"type:decimal(19,4);not null;default:0" json:"profit_loss"`
-	ProfitLossPercent decimal.Decimal `gorm:"type:decimal(10,2);not null;default:0" json:"profit_loss_percent"`
-	Currency          string          `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
-	ExchangeRateToUSD decimal.Decimal `gorm:"type:decimal(19,6);not null;default:1.0" json:"exchange_rate_to_usd"`
-	Description       *string         `gorm:"type:varchar(255)" json:"description"`
-	Asset             InvestmentAsset `json:"asset"`
-	ImportID          *int64          `json:"import_id,omitempty"`
-	TaxInfo           *TradeTaxInfo   `gorm:"-" json:"tax_info,omitempty"`
-	CreatedAt         time.Time       `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt         time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                int64                       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID            int64                       `gorm:"not null" json:"user_id"`
+	AssetID           int64                       `gorm:"not null;index:idx_inv_trans_asset" json:"asset_id"`
+	TxnDate           time.Time                   `gorm:"type:date;not null;index:idx_inv_trans_date" json:"txn_date"`
+	TradeType         TradeType                   `gorm:"type:varchar(4);not null" json:"trade_type"`
+	Quantity          decimal.Decimal             `gorm:"type:decimal(19,8);not null" json:"quantity"`
+	Fee               decimal.Decimal             `gorm:"type:decimal(19,4);not null;default:0" json:"fee"`
+	PricePerUnit      decimal.Decimal             `gorm:"type:decimal(19,4);not null" json:"price_per_unit"`
+	ValueAtBuy        decimal.Decimal             `gorm:"type:decimal(19,4);not null" json:"value_at_buy"`
+	CurrentValue      decimal.Decimal             `gorm:"type:decimal(19,4);not null" json:"current_value"`
+	RealizedValue     decimal.Decimal             `gorm:"type:decimal(19,4);not null" json:"realized_value"`
+	ProfitLoss        decimal.Decimal             `gorm:"type:decimal(19,4);not null;default:0" json:"profit_loss"`
+	ProfitLossPercent decimal.Decimal             `gorm:"type:decimal(10,2);not null;default:0" json:"profit_loss_percent"`
+	Currency          string                      `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	ExchangeRateToUSD decimal.Decimal             `gorm:"type:decimal(19,6);not null;default:1.0" json:"exchange_rate_to_usd"`
+	Description       *string                     `gorm:"type:varchar(255)" json:"description"`
+	Asset             InvestmentAsset             `json:"asset"`
+	ImportID          *int64                      `json:"import_id,omitempty"`
+	TaxFreeAlertedAt  *time.Time                  `gorm:"type:date" json:"tax_free_alerted_at,omitempty"`
+	TransferID        *int64                      `json:"transfer_id,omitempty"`
+	AcquiredAt        *time.Time                  `gorm:"type:date" json:"acquired_at,omitempty"`
+	TaxInfo           *TradeTaxInfo               `gorm:"-" json:"tax_info,omitempty"`
+	Lots              []InvestmentTradeLot        `gorm:"foreignKey:SellTradeID" json:"lots,omitempty"`
+	BasisAdjustments  []InvestmentBasisAdjustment `gorm:"foreignKey:TradeID" json:"-"`
+	CreatedAt         time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time                   `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AcquiredOn is when the lot was originally bought. Buys carried over by an in-kind
+// transfer keep their original date here, while TxnDate is when they arrived.
+func (t InvestmentTrade) AcquiredOn() time.Time {
+	if t.AcquiredAt != nil {
+		return *t.AcquiredAt
+	}
+	return t.TxnDate
+}
+
+// InvestmentTransfer moves units of an asset to another investment account in kind.
+// The source gets a sell and the destination a buy per lot moved; neither touches cash
+// or realizes a gain.
+type InvestmentTransfer struct {
+	ID          int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      int64           `gorm:"not null" json:"user_id"`
+	FromAssetID int64           `gorm:"not null" json:"from_asset_id"`
+	ToAssetID   int64           `gorm:"not null" json:"to_asset_id"`
+	TxnDate     time.Time       `gorm:"type:date;not null" json:"txn_date"`
+	Quantity    decimal.Decimal `gorm:"type:decimal(19,8);not null" json:"quantity"`
+	CostBasis   decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"cost_basis"`
+	Description *string         `gorm:"type:varchar(255)" json:"description"`
+	FromAsset   InvestmentAsset `gorm:"foreignKey:FromAssetID" json:"from_asset"`
+	ToAsset     InvestmentAsset `gorm:"foreignKey:ToAssetID" json:"to_asset"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// InvestmentTradeLot ties part of a sell to the buy lot it was matched against,
+// for specific-lot identification.
+type InvestmentTradeLot struct {
+	ID          int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	SellTradeID int64           `gorm:"not null" json:"sell_trade_id"`
+	BuyTradeID  int64           `gorm:"not null;index:idx_itl_buy" json:"buy_trade_id"`
+	Quantity    decimal.Decimal `gorm:"type:decimal(19,8);not null" json:"quantity"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type AssetPriceHistory struct {
@@ -81,6 +187,31 @@ func (AssetPriceHistory) TableName() string {
 	return "asset_price_history"
 }
 
+// ManualPriceReq records a dated price for a manually priced asset.
+type ManualPriceReq struct {
+	AsOf     time.Time       `json:"as_of" validate:"required"`
+	Price    decimal.Decimal `json:"price" validate:"required"`
+	Currency string          `json:"currency,omitempty" validate:"omitempty,len=3"`
+}
+
+// PriceUploadRow is one parsed line of a price CSV.
+type PriceUploadRow struct {
+	Line     int
+	Ticker   string
+	AsOf     time.Time
+	Price    decimal.Decimal
+	Currency string
+}
+
+type PriceUploadResult struct {
+	Rows     int `json:"rows"`
+	Imported int `json:"imported"`
+	Assets   int `json:"assets"`
+	// Duplicates lists rows replaced by a later row for the same ticker and date.
+	Duplicates []string `json:"duplicates"`
+	Errors     []string `json:"errors"`
+}
+
 type ExchangeRateHistory struct {
 	FromCurrency string          `gorm:"primaryKey;type:char(3)" json:"from_currency"`
 	ToCurrency   string          `gorm:"primaryKey;type:char(3)" json:"to_currency"`
@@ -102,12 +233,14 @@ type IncomeType string
 const (
 	IncomeTypeStaking  IncomeType = "staking_reward"
 	IncomeTypeDividend IncomeType = "dividend"
+	IncomeTypeCoupon   IncomeType = "coupon"
 )
 
 type InvestmentIncome struct {
 	ID                  int64            `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID              int64            `gorm:"not null;index:idx_inv_income_user" json:"user_id"`
 	AssetID             int64            `gorm:"not null;index:idx_inv_income_asset" json:"asset_id"`
+	ImportID            *int64           `json:"import_id,omitempty"`
 	TxnDate             time.Time        `gorm:"type:date;not null;index:idx_inv_income_date" json:"txn_date"`
 	IncomeType          IncomeType       `gorm:"type:income_type;not null" json:"income_type"`
 	Quantity            *decimal.Decimal `gorm:"type:decimal(19,8)" json:"quantity"`
@@ -116,6 +249,7 @@ type InvestmentIncome struct {
 	Currency            string           `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
 	Notes               *string          `gorm:"type:varchar(255)" json:"notes"`
 	LinkedTransactionID *int64           `gorm:"index" json:"linked_transaction_id,omitempty"`
+	ReinvestTradeID     *int64           `gorm:"index" json:"reinvest_trade_id,omitempty"`
 	Asset               InvestmentAsset  `json:"asset"`
 	CreatedAt           time.Time        `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
@@ -134,6 +268,52 @@ type AssetTaxSummary struct {
 	AfterTaxPnL     decimal.Decimal `json:"after_tax_pnl"`
 }
 
+// TaxHarvestCandidate is an asset whose open lots sit at a loss that could be
+// sold to offset this year's realized gains. UnrealizedLoss is positive.
+type TaxHarvestCandidate struct {
+	AssetID           int64           `json:"asset_id"`
+	Ticker            string          `json:"ticker"`
+	Name              string          `json:"name"`
+	InvestmentType    InvestmentType  `json:"investment_type"`
+	Currency          string          `json:"currency"`
+	Quantity          decimal.Decimal `json:"quantity"`
+	CostBasis         decimal.Decimal `json:"cost_basis"`
+	MarketValue       decimal.Decimal `json:"market_value"`
+	UnrealizedLoss    decimal.Decimal `json:"unrealized_loss"`
+	GainOffset        decimal.Decimal `json:"gain_offset"`
+	EstimatedTaxSaved decimal.Decimal `json:"estimated_tax_saved"`
+}
+
+// TaxHoldCandidate is an open lot in profit that moves into a lower taxable
+// bracket soon, and is cheaper to sell after that.
+type TaxHoldCandidate struct {
+	TradeID               int64           `json:"trade_id"`
+	AssetID               int64           `json:"asset_id"`
+	Ticker                string          `json:"ticker"`
+	Name                  string          `json:"name"`
+	InvestmentType        InvestmentType  `json:"investment_type"`
+	Currency              string          `json:"currency"`
+	BuyDate               time.Time       `json:"buy_date"`
+	Quantity              decimal.Decimal `json:"quantity"`
+	MarketValue           decimal.Decimal `json:"market_value"`
+	UnrealizedGain        decimal.Decimal `json:"unrealized_gain"`
+	DaysHeld              int             `json:"days_held"`
+	DaysUntilNextBracket  int             `json:"days_until_next_bracket"`
+	DaysUntilTaxFree      *int            `json:"days_until_tax_free"`
+	CurrentTaxablePercent decimal.Decimal `json:"current_taxable_percent"`
+	NextTaxablePercent    decimal.Decimal `json:"next_taxable_percent"`
+	TaxSavedByWaiting     decimal.Decimal `json:"tax_saved_by_waiting"`
+}
+
+type TaxOptimizationHints struct {
+	Year            int                   `json:"year"`
+	LossOffsetting  bool                  `json:"loss_offsetting"`
+	RealizedNetGain decimal.Decimal       `json:"realized_net_gain"`
+	RealizedTaxDue  decimal.Decimal       `json:"realized_tax_due"`
+	Harvest         []TaxHarvestCandidate `json:"harvest"`
+	Hold            []TaxHoldCandidate    `json:"hold"`
+}
+
 type InvestmentTaxBracket struct {
 	ID             int64          `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID         int64          `gorm:"not null;index:idx_tax_brackets_user" json:"user_id"`
@@ -147,11 +327,13 @@ type InvestmentTaxBracket struct {
 }
 
 type InvestmentTaxSettings struct {
-	ID                    int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID                int64     `gorm:"not null;uniqueIndex" json:"user_id"`
-	LossOffsettingEnabled bool      `gorm:"not null;default:false" json:"loss_offsetting_enabled"`
-	CreatedAt             time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt             time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                    int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID                int64           `gorm:"not null;uniqueIndex" json:"user_id"`
+	LossOffsettingEnabled bool            `gorm:"not null;default:false" json:"loss_offsetting_enabled"`
+	CostBasisMethod       CostBasisMethod `gorm:"type:cost_basis_method;not null;default:fifo" json:"cost_basis_method"`
+	TaxFreeAlertMinValue  decimal.Decimal `gorm:"type:decimal(19,4);not null;default:1000" json:"tax_free_alert_min_value"`
+	CreatedAt             time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type InvestmentTaxBracketReq struct {
@@ -163,9 +345,16 @@ type InvestmentTaxBracketReq struct {
 }
 
 type InvestmentTaxSettingsReq struct {
-	LossOffsettingEnabled bool `json:"loss_offsetting_enabled"`
+	LossOffsettingEnabled bool             `json:"loss_offsetting_enabled"`
+	CostBasisMethod       CostBasisMethod  `json:"cost_basis_method" validate:"omitempty,oneof=fifo lifo average specific_lot"`
+	TaxFreeAlertMinValue  *decimal.Decimal `json:"tax_free_alert_min_value,omitempty"`
 }
 
+// DefaultTaxFreeAlertMinValue is the market value, in the asset's currency, from
+// which a lot is warned about a week before it becomes tax-free, until the user
+// sets their own. Zero turns the warning off.
+var DefaultTaxFreeAlertMinValue = decimal.NewFromInt(1000)
+
 type InvestmentTaxBracketsCopyReq struct {
 	FromType InvestmentType `json:"from_type" validate:"required"`
 	ToType   InvestmentType `json:"to_type" validate:"required"`
@@ -180,6 +369,9 @@ type InvestmentIncomeReq struct {
 	TaxWithheld *decimal.Decimal `json:"tax_withheld,omitempty"`
 	Currency    string           `json:"currency" validate:"required"`
 	Notes       *string          `json:"notes,omitempty"`
+	// Reinvest turns a dividend into a buy of the same asset. Quantity is the
+	// number of units bought; without it the net amount is spent at that day's price.
+	Reinvest bool `json:"reinvest"`
 }
 
 type InvestmentAssetReq struct {
@@ -189,6 +381,25 @@ type InvestmentAssetReq struct {
 	Ticker         string          `json:"ticker" validate:"required"`
 	Quantity       decimal.Decimal `json:"quantity" validate:"required"`
 	Currency       string          `json:"currency" validate:"required"`
+	// CostBasisMethod overrides the user's tax setting for this asset; nil inherits it.
+	CostBasisMethod *CostBasisMethod `json:"cost_basis_method,omitempty" validate:"omitempty,oneof=fifo lifo average specific_lot"`
+	// AssetClass is a free-form label used to group assets for target allocations.
+	AssetClass *string `json:"asset_class,omitempty" validate:"omitempty,max=50"`
+	// PriceProvider pins the asset to one price source, tried before the fallback order.
+	PriceProvider *string `json:"price_provider,omitempty" validate:"omitempty,oneof=yahoo coingecko stooq ecb"`
+	// ManualPricing values the asset from entered prices only; the price sync skips it.
+	ManualPricing bool `json:"manual_pricing"`
+	InstrumentTerms
+}
+
+// InvestmentAssetUpdateReq changes only the fields that are sent.
+// An empty string resets an optional field to its default.
+type InvestmentAssetUpdateReq struct {
+	Name            *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	CostBasisMethod *CostBasisMethod `json:"cost_basis_method,omitempty" validate:"omitempty,oneof='' fifo lifo average specific_lot"`
+	AssetClass      *string          `json:"asset_class,omitempty" validate:"omitempty,max=50"`
+	PriceProvider   *string          `json:"price_provider,omitempty" validate:"omitempty,oneof='' yahoo coingecko stooq ecb"`
+	ManualPricing   *bool            `json:"manual_pricing,omitempty"`
 }
 
 type InvestmentTradeReq struct {
@@ -200,4 +411,20 @@ type InvestmentTradeReq struct {
 	Currency     string           `json:"currency" validate:"required"`
 	Fee          *decimal.Decimal `json:"fee"`
 	Description  *string          `json:"description,omitempty"`
+	// Lots picks the buy lots a sell is matched against (specific-lot identification).
+	Lots []InvestmentTradeLotReq `json:"lots,omitempty" validate:"omitempty,dive"`
+}
+
+// InvestmentTransferReq moves Quantity units, or the whole position when it is nil.
+type InvestmentTransferReq struct {
+	AssetID     int64            `json:"asset_id" validate:"required"`
+	ToAccountID int64            `json:"to_account_id" validate:"required"`
+	TxnDate     time.Time        `json:"txn_date" validate:"required"`
+	Quantity    *decimal.Decimal `json:"quantity,omitempty"`
+	Description *string          `json:"description,omitempty"`
+}
+
+type InvestmentTradeLotReq struct {
+	BuyTradeID int64           `json:"buy_trade_id" validate:"required"`
+	Quantity   decimal.Decimal `json:"quantity" validate:"required"`
 }
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PriceAlertKind string
+
+const (
+	// PriceAlertPercentChange compares the price, or the account or portfolio value, with its level PeriodDays ago.
+	PriceAlertPercentChange PriceAlertKind = "percent_change"
+	PriceAlertPrice         PriceAlertKind = "price"
+	// PriceAlertValue watches the position value of an asset or the total value of an account or the portfolio.
+	PriceAlertValue      PriceAlertKind = "value"
+	PriceAlertProfitLoss PriceAlertKind = "profit_loss"
+)
+
+type PriceAlertDirection string
+
+const (
+	PriceAlertAbove PriceAlertDirection = "above"
+	PriceAlertBelow PriceAlertDirection = "below"
+	// PriceAlertEither fires on a move either way and is valid only for percent change rules.
+	PriceAlertEither PriceAlertDirection = "either"
+)
+
+type PriceAlertRule struct {
+	ID              int64               `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID          int64               `gorm:"not null" json:"user_id"`
+	AssetID         *int64              `json:"asset_id,omitempty"`
+	AccountID       *int64              `json:"account_id,omitempty"`
+	Kind            PriceAlertKind      `gorm:"type:price_alert_kind;not null" json:"kind"`
+	Direction       PriceAlertDirection `gorm:"type:price_alert_direction;not null" json:"direction"`
+	Threshold       decimal.Decimal     `gorm:"type:decimal(19,4);not null" json:"threshold"`
+	PeriodDays      *int                `json:"period_days,omitempty"`
+	CooldownHours   int                 `gorm:"not null;default:24" json:"cooldown_hours"`
+	IsActive        bool                `gorm:"not null;default:true" json:"is_active"`
+	LastTriggeredAt *time.Time          `json:"last_triggered_at"`
+	LastHit         bool                `gorm:"not null;default:false" json:"-"`
+	CreatedAt       time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type PriceAlertRuleReq struct {
+	AssetID       *int64              `json:"asset_id,omitempty"`
+	AccountID     *int64              `json:"account_id,omitempty"`
+	Kind          PriceAlertKind      `json:"kind" validate:"required,oneof=percent_change price value profit_loss"`
+	Direction     PriceAlertDirection `json:"direction" validate:"required,oneof=above below either"`
+	Threshold     decimal.Decimal     `json:"threshold" validate:"required"`
+	PeriodDays    *int                `json:"period_days,omitempty" validate:"omitempty,oneof=1 7 30"`
+	CooldownHours *int                `json:"cooldown_hours,omitempty" validate:"omitempty,min=1,max=8760"`
+	IsActive      *bool               `json:"is_active,omitempty"`
+}
+
+// PriceAlertTrigger is a rule that fired during a price sync, ready to be sent to its owner.
+type PriceAlertTrigger struct {
+	RuleID  int64
+	UserID  int64
+	Title   string
+	Message string
+}
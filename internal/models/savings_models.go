@@ -20,34 +20,68 @@ type SavingContributionSource string
 const (
 	SavingContributionSourceManual SavingContributionSource = "manual"
 	SavingContributionSourceAuto   SavingContributionSource = "auto"
+	// SavingContributionSourceSettlement draws a recurring goal down when its bill is paid.
+	SavingContributionSourceSettlement SavingContributionSource = "settlement"
+)
+
+type SavingGoalRecurrence string
+
+const (
+	SavingGoalRecurrenceYearly    SavingGoalRecurrence = "yearly"
+	SavingGoalRecurrenceQuarterly SavingGoalRecurrence = "quarterly"
+)
+
+type SavingGoalCompletionAction string
+
+const (
+	SavingGoalCompletionNotify  SavingGoalCompletionAction = "notify"
+	SavingGoalCompletionArchive SavingGoalCompletionAction = "archive"
+	// SavingGoalCompletionRedirect hands the goal's MonthlyAllocation to the next goal in priority order.
+	SavingGoalCompletionRedirect SavingGoalCompletionAction = "redirect"
+)
+
+type SavingGoalMilestoneKind string
+
+const (
+	SavingGoalMilestonePercent SavingGoalMilestoneKind = "percent"
+	SavingGoalMilestoneAmount  SavingGoalMilestoneKind = "amount"
 )
 
 type SavingGoal struct {
-	ID                int64            `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID            int64            `gorm:"not null" json:"user_id"`
-	AccountID         int64            `gorm:"not null" json:"account_id"`
-	Name              string           `gorm:"type:varchar(150);not null" json:"name"`
-	TargetAmount      decimal.Decimal  `gorm:"type:decimal(19,4);not null" json:"target_amount"`
-	CurrentAmount     decimal.Decimal  `gorm:"type:decimal(19,4);not null;default:0" json:"current_amount"`
-	TargetDate        *time.Time       `gorm:"type:date" json:"target_date,omitempty"`
-	Status            SavingGoalStatus `gorm:"type:saving_goal_status;not null;default:active" json:"status"`
-	Priority          int              `gorm:"not null;default:0" json:"priority"`
-	MonthlyAllocation *decimal.Decimal `gorm:"type:decimal(19,4)" json:"monthly_allocation,omitempty"`
-	FundDayOfMonth    *int             `gorm:"type:smallint"     json:"fund_day_of_month,omitempty"`
-	CreatedAt         time.Time        `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt         time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                int64                      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID            int64                      `gorm:"not null" json:"user_id"`
+	AccountID         int64                      `gorm:"not null" json:"account_id"`
+	Name              string                     `gorm:"type:varchar(150);not null" json:"name"`
+	TargetAmount      decimal.Decimal            `gorm:"type:decimal(19,4);not null" json:"target_amount"`
+	CurrentAmount     decimal.Decimal            `gorm:"type:decimal(19,4);not null;default:0" json:"current_amount"`
+	TargetDate        *time.Time                 `gorm:"type:date" json:"target_date,omitempty"`
+	Status            SavingGoalStatus           `gorm:"type:saving_goal_status;not null;default:active" json:"status"`
+	Priority          int                        `gorm:"not null;default:0" json:"priority"`
+	MonthlyAllocation *decimal.Decimal           `gorm:"type:decimal(19,4)" json:"monthly_allocation,omitempty"`
+	FundDayOfMonth    *int                       `gorm:"type:smallint"     json:"fund_day_of_month,omitempty"`
+	FundingAccountID  *int64                     `json:"funding_account_id,omitempty"`
+	Recurrence        *SavingGoalRecurrence      `gorm:"type:saving_goal_recurrence" json:"recurrence,omitempty"`
+	CycleNumber       int                        `gorm:"not null;default:1" json:"cycle_number"`
+	CycleStartedAt    *time.Time                 `gorm:"type:date" json:"cycle_started_at,omitempty"`
+	CompletionAction  SavingGoalCompletionAction `gorm:"type:saving_goal_completion_action;not null;default:notify" json:"completion_action"`
+	LateAlertedAt     *time.Time                 `json:"late_alerted_at,omitempty"`
+	CreatedAt         time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time                  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type SavingContribution struct {
-	ID        int64                    `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    int64                    `gorm:"not null" json:"user_id"`
-	GoalID    int64                    `gorm:"not null" json:"goal_id"`
-	Amount    decimal.Decimal          `gorm:"type:decimal(19,4);not null" json:"amount"`
-	Month     time.Time                `gorm:"type:date;not null" json:"month"`
-	Note      *string                  `gorm:"type:varchar(255)" json:"note,omitempty"`
-	Source    SavingContributionSource `gorm:"type:saving_contribution_source;not null;default:manual" json:"source"`
-	CreatedAt time.Time                `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time                `gorm:"autoUpdateTime" json:"updated_at"`
+	ID            int64                    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64                    `gorm:"not null" json:"user_id"`
+	GoalID        int64                    `gorm:"not null" json:"goal_id"`
+	Amount        decimal.Decimal          `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Month         time.Time                `gorm:"type:date;not null" json:"month"`
+	Note          *string                  `gorm:"type:varchar(255)" json:"note,omitempty"`
+	Source        SavingContributionSource `gorm:"type:saving_contribution_source;not null;default:manual" json:"source"`
+	TransferID    *int64                   `json:"transfer_id,omitempty"`
+	CycleNumber   int                      `gorm:"not null;default:1" json:"cycle_number"`
+	TransactionID *int64                   `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time                `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time                `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type SavingGoalWithProgress struct {
@@ -59,24 +93,30 @@ type SavingGoalWithProgress struct {
 }
 
 type SavingGoalReq struct {
-	AccountID         int64            `json:"account_id" validate:"required"`
-	Name              string           `json:"name" validate:"required,min=1,max=150"`
-	TargetAmount      decimal.Decimal  `json:"target_amount" validate:"required"`
-	InitialAmount     *decimal.Decimal `json:"initial_amount,omitempty"`
-	TargetDate        *string          `json:"target_date,omitempty"`
-	Priority          int              `json:"priority"`
-	MonthlyAllocation *decimal.Decimal `json:"monthly_allocation,omitempty"`
-	FundDayOfMonth    *int             `json:"fund_day_of_month,omitempty"`
+	AccountID         int64                      `json:"account_id" validate:"required"`
+	Name              string                     `json:"name" validate:"required,min=1,max=150"`
+	TargetAmount      decimal.Decimal            `json:"target_amount" validate:"required"`
+	InitialAmount     *decimal.Decimal           `json:"initial_amount,omitempty"`
+	TargetDate        *string                    `json:"target_date,omitempty"`
+	Priority          int                        `json:"priority"`
+	MonthlyAllocation *decimal.Decimal           `json:"monthly_allocation,omitempty"`
+	FundDayOfMonth    *int                       `json:"fund_day_of_month,omitempty"`
+	FundingAccountID  *int64                     `json:"funding_account_id,omitempty"`
+	Recurrence        *SavingGoalRecurrence      `json:"recurrence,omitempty" validate:"omitempty,oneof=yearly quarterly"`
+	CompletionAction  SavingGoalCompletionAction `json:"completion_action,omitempty" validate:"omitempty,oneof=notify archive redirect"`
 }
 
 type SavingGoalUpdateReq struct {
-	Name              string           `json:"name" validate:"required,min=1,max=150"`
-	TargetAmount      decimal.Decimal  `json:"target_amount" validate:"required"`
-	TargetDate        *string          `json:"target_date,omitempty"`
-	Status            SavingGoalStatus `json:"status" validate:"required"`
-	Priority          int              `json:"priority"`
-	MonthlyAllocation *decimal.Decimal `json:"monthly_allocation,omitempty"`
-	FundDayOfMonth    *int             `json:"fund_day_of_month,omitempty"`
+	Name              string                     `json:"name" validate:"required,min=1,max=150"`
+	TargetAmount      decimal.Decimal            `json:"target_amount" validate:"required"`
+	TargetDate        *string                    `json:"target_date,omitempty"`
+	Status            SavingGoalStatus           `json:"status" validate:"required"`
+	Priority          int                        `json:"priority"`
+	MonthlyAllocation *decimal.Decimal           `json:"monthly_allocation,omitempty"`
+	FundDayOfMonth    *int                       `json:"fund_day_of_month,omitempty"`
+	FundingAccountID  *int64                     `json:"funding_account_id,omitempty"`
+	Recurrence        *SavingGoalRecurrence      `json:"recurrence,omitempty" validate:"omitempty,oneof=yearly quarterly"`
+	CompletionAction  SavingGoalCompletionAction `json:"completion_action,omitempty" validate:"omitempty,oneof=notify archive redirect"`
 }
 
 type SavingContributionReq struct {
@@ -84,3 +124,141 @@ type SavingContributionReq struct {
 	Month  string          `json:"month" validate:"required"`
 	Note   *string         `json:"note,omitempty"`
 }
+
+// SavingsReconciliation compares what goals claim to hold in an account
+// against the account's actual balance.
+type SavingsReconciliation struct {
+	AccountID     int64                      `json:"account_id"`
+	AccountName   string                     `json:"account_name"`
+	Currency      string                     `json:"currency"`
+	Balance       decimal.Decimal            `json:"balance"`
+	Allocated     decimal.Decimal            `json:"allocated"`
+	Uncategorized decimal.Decimal            `json:"uncategorized"`
+	Shortfall     decimal.Decimal            `json:"shortfall"`
+	Goals         []SavingGoalReconciliation `json:"goals"`
+}
+
+type SavingGoalReconciliation struct {
+	GoalID           int64           `json:"goal_id"`
+	Name             string          `json:"name"`
+	CurrentAmount    decimal.Decimal `json:"current_amount"`
+	TransferBacked   decimal.Decimal `json:"transfer_backed"`
+	Unbacked         decimal.Decimal `json:"unbacked"`
+	FundingAccountID *int64          `json:"funding_account_id,omitempty"`
+}
+
+type SavingsBudgetMode string
+
+const (
+	SavingsBudgetModeFixed         SavingsBudgetMode = "fixed"
+	SavingsBudgetModeIncomePercent SavingsBudgetMode = "income_percent"
+)
+
+type SavingsBudgetStrategy string
+
+const (
+	SavingsBudgetStrategyPriority     SavingsBudgetStrategy = "priority"
+	SavingsBudgetStrategyProportional SavingsBudgetStrategy = "proportional"
+	SavingsBudgetStrategyDeadline     SavingsBudgetStrategy = "deadline"
+)
+
+// SavingsBudget is a monthly amount spread across a user's active goals.
+// While active it replaces each goal's own MonthlyAllocation.
+type SavingsBudget struct {
+	ID             int64                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64                 `gorm:"not null" json:"user_id"`
+	Mode           SavingsBudgetMode     `gorm:"type:savings_budget_mode;not null" json:"mode"`
+	Amount         *decimal.Decimal      `gorm:"type:decimal(19,4)" json:"amount,omitempty"`
+	IncomePercent  *decimal.Decimal      `gorm:"type:decimal(7,4)" json:"income_percent,omitempty"`
+	Strategy       SavingsBudgetStrategy `gorm:"type:savings_budget_strategy;not null;default:priority" json:"strategy"`
+	FundDayOfMonth *int                  `gorm:"type:smallint" json:"fund_day_of_month,omitempty"`
+	IsActive       bool                  `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type SavingsBudgetReq struct {
+	Mode           SavingsBudgetMode     `json:"mode" validate:"required,oneof=fixed income_percent"`
+	Amount         *decimal.Decimal      `json:"amount,omitempty"`
+	IncomePercent  *decimal.Decimal      `json:"income_percent,omitempty"`
+	Strategy       SavingsBudgetStrategy `json:"strategy" validate:"required,oneof=priority proportional deadline"`
+	FundDayOfMonth *int                  `json:"fund_day_of_month,omitempty" validate:"omitempty,min=1,max=31"`
+	IsActive       bool                  `json:"is_active"`
+}
+
+// SavingsBudgetPlan is how a month's budget splits across goals.
+type SavingsBudgetPlan struct {
+	Month       time.Time                 `json:"month"`
+	Budget      decimal.Decimal           `json:"budget"`
+	Strategy    SavingsBudgetStrategy     `json:"strategy"`
+	Unallocated decimal.Decimal           `json:"unallocated"`
+	Allocations []SavingsBudgetAllocation `json:"allocations"`
+}
+
+type SavingsBudgetAllocation struct {
+	GoalID int64           `json:"goal_id"`
+	Name   string          `json:"name"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// SavingGoalFundResult is the outcome of funding one goal from a savings budget.
+type SavingGoalFundResult struct {
+	Goal       SavingGoal
+	Amount     decimal.Decimal
+	Funded     bool
+	SkipReason string
+	Err        error
+}
+
+// SavingGoalCycle records a finished cycle of a recurring goal.
+type SavingGoalCycle struct {
+	ID            int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64           `gorm:"not null" json:"user_id"`
+	GoalID        int64           `gorm:"not null" json:"goal_id"`
+	CycleNumber   int             `gorm:"not null" json:"cycle_number"`
+	StartedAt     *time.Time      `gorm:"type:date" json:"started_at,omitempty"`
+	DueDate       time.Time       `gorm:"type:date;not null" json:"due_date"`
+	Saved         decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"saved"`
+	Spent         decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"spent"`
+	CarriedOver   decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"carried_over"`
+	TransactionID *int64          `json:"transaction_id,omitempty"`
+	SettledAt     time.Time       `gorm:"not null" json:"settled_at"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type SavingGoalSettleReq struct {
+	TransactionID int64 `json:"transaction_id" validate:"required"`
+}
+
+// SavingGoalPayReq books a recurring goal's bill as an expense on its account.
+type SavingGoalPayReq struct {
+	Amount      decimal.Decimal `json:"amount" validate:"required"`
+	TxnDate     time.Time       `json:"txn_date"`
+	CategoryID  *int64          `json:"category_id,omitempty"`
+	Description *string         `json:"description,omitempty"`
+}
+
+// SavingGoalMilestone is a point on the way to a goal's target, either a share
+// of the target or a fixed amount. It fires once per goal cycle.
+type SavingGoalMilestone struct {
+	ID           int64                   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64                   `gorm:"not null" json:"user_id"`
+	GoalID       int64                   `gorm:"not null" json:"goal_id"`
+	Kind         SavingGoalMilestoneKind `gorm:"type:saving_goal_milestone_kind;not null" json:"kind"`
+	Value        decimal.Decimal         `gorm:"type:decimal(19,4);not null" json:"value"`
+	ReachedCycle *int                    `json:"reached_cycle,omitempty"`
+	ReachedAt    *time.Time              `json:"reached_at,omitempty"`
+	CreatedAt    time.Time               `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time               `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type SavingGoalMilestoneReq struct {
+	Kind  SavingGoalMilestoneKind `json:"kind" validate:"required,oneof=percent amount"`
+	Value decimal.Decimal         `json:"value" validate:"required"`
+}
+
+// SavingGoalMilestonesReq replaces a goal's milestones as a whole.
+type SavingGoalMilestonesReq struct {
+	Milestones []SavingGoalMilestoneReq `json:"milestones" validate:"max=20,dive"`
+}
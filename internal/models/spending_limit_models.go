@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type SpendingLimitDelivery string
+
+const (
+	// SpendingLimitDeliveryInstant notifies as soon as a transaction crosses a threshold.
+	SpendingLimitDeliveryInstant SpendingLimitDelivery = "instant"
+	// SpendingLimitDeliveryDigest collects crossings into one notification a day.
+	SpendingLimitDeliveryDigest SpendingLimitDelivery = "digest"
+)
+
+// SpendingLimit alerts when monthly spending on a category or category group
+// reaches 80%, 100% or a custom percentage of Amount.
+type SpendingLimit struct {
+	ID            int64                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64                 `gorm:"not null" json:"user_id"`
+	CategoryID    *int64                `json:"category_id,omitempty"`
+	GroupID       *int64                `json:"group_id,omitempty"`
+	Amount        decimal.Decimal       `gorm:"type:decimal(19,4);not null" json:"amount"`
+	NotifyAt80    bool                  `gorm:"column:notify_at_80;not null;default:true" json:"notify_at_80"`
+	NotifyAt100   bool                  `gorm:"column:notify_at_100;not null;default:true" json:"notify_at_100"`
+	CustomPercent *int                  `json:"custom_percent,omitempty"`
+	Delivery      SpendingLimitDelivery `gorm:"type:spending_limit_delivery;not null;default:instant" json:"delivery"`
+	IsActive      bool                  `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt     time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SpendingLimitAlert records a threshold crossed in a month. Digest alerts
+// keep NotifiedAt unset until the daily digest goes out.
+type SpendingLimitAlert struct {
+	ID         int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64           `gorm:"not null" json:"user_id"`
+	LimitID    int64           `gorm:"not null" json:"limit_id"`
+	Month      time.Time       `gorm:"type:date;not null" json:"month"`
+	Percent    int             `gorm:"not null" json:"percent"`
+	Spent      decimal.Decimal `gorm:"type:decimal(19,4);not null" json:"spent"`
+	NotifiedAt *time.Time      `json:"notified_at,omitempty"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type SpendingLimitReq struct {
+	CategoryID    *int64                `json:"category_id,omitempty"`
+	GroupID       *int64                `json:"group_id,omitempty"`
+	Amount        decimal.Decimal       `json:"amount" validate:"required"`
+	NotifyAt80    *bool                 `json:"notify_at_80,omitempty"`
+	NotifyAt100   *bool                 `json:"notify_at_100,omitempty"`
+	CustomPercent *int                  `json:"custom_percent,omitempty" validate:"omitempty,min=1,max=500"`
+	Delivery      SpendingLimitDelivery `json:"delivery" validate:"omitempty,oneof=instant digest"`
+	IsActive      *bool                 `json:"is_active,omitempty"`
+}
+
+// SpendingLimitStatus is a limit with this month's spending against it.
+type SpendingLimitStatus struct {
+	SpendingLimit
+	Name        string          `json:"name"`
+	Spent       decimal.Decimal `json:"spent"`
+	PercentUsed decimal.Decimal `json:"percent_used"`
+}
+
+// SpendingLimitCrossing is one threshold crossed, as reported to the user.
+type SpendingLimitCrossing struct {
+	LimitID int64           `json:"limit_id"`
+	Name    string          `json:"name"`
+	Percent int             `json:"percent"`
+	Spent   decimal.Decimal `json:"spent"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+// SpendingLimitDigest gathers a user's crossings since the last digest.
+type SpendingLimitDigest struct {
+	UserID    int64
+	Crossings []SpendingLimitCrossing
+}
@@ -21,6 +21,7 @@ type Transaction struct {
 	IsSystem        bool            `gorm:"not null;type:boolean" json:"is_system"`
 	IsTransfer      bool            `gorm:"not null;type:boolean" json:"is_transfer"`
 	IdempotencyKey  *string         `gorm:"type:varchar(64)" json:"idempotency_key,omitempty"`
+	CreatedBy       *int64          `json:"created_by,omitempty"`
 	Account         Account         `json:"account"`
 	Category        Category        `json:"category,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
@@ -21,13 +21,15 @@ type User struct {
 }
 
 type Invitation struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Email     string    `gorm:"type:varchar(255);index:idx_email_role,unique" json:"email"`
-	Hash      string    `gorm:"type:varchar(255);not null" json:"hash"`
-	RoleID    int64     `gorm:"not null;index:idx_email_role,unique" json:"role_id"`
-	Role      Role      `gorm:"foreignKey:RoleID" json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email        string    `gorm:"type:varchar(255);index:idx_email_role,unique" json:"email"`
+	Hash         string    `gorm:"type:varchar(255);not null" json:"hash"`
+	RoleID       int64     `gorm:"not null;index:idx_email_role,unique" json:"role_id"`
+	Role         Role      `gorm:"foreignKey:RoleID" json:"role"`
+	HouseholdID  *int64    `json:"household_id,omitempty"`
+	DelegationID *int64    `json:"delegation_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type Token struct {
@@ -0,0 +1,58 @@
+package queue_jobs
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"go.uber.org/zap"
+)
+
+type benchmarkBackfillSvc interface {
+	BackfillBenchmarkPriceHistory(ctx context.Context, benchmarkID int64, ticker string, investmentType models.InvestmentType, from, to time.Time) error
+}
+
+// BackfillBenchmarkJob loads a benchmark's history as soon as it is attached, so
+// charts don't have to wait for the nightly backfill.
+type BackfillBenchmarkJob struct {
+	logger            *zap.Logger
+	InvestmentService benchmarkBackfillSvc `json:"-"`
+	BenchmarkID       int64
+	Ticker            string
+	InvestmentType    models.InvestmentType
+	From              time.Time
+}
+
+func (j *BackfillBenchmarkJob) Type() string { return TypeBackfillBenchmark }
+
+func NewBackfillBenchmarkJob(
+	logger *zap.Logger,
+	investmentService benchmarkBackfillSvc,
+	benchmarkID int64,
+	ticker string,
+	investmentType models.InvestmentType,
+	from time.Time,
+) *BackfillBenchmarkJob {
+	return &BackfillBenchmarkJob{
+		logger:            logger,
+		InvestmentService: investmentService,
+		BenchmarkID:       benchmarkID,
+		Ticker:            ticker,
+		InvestmentType:    investmentType,
+		From:              from,
+	}
+}
+
+func (j *BackfillBenchmarkJob) Process(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	if err := j.InvestmentService.BackfillBenchmarkPriceHistory(ctx, j.BenchmarkID, j.Ticker, j.InvestmentType, j.From, today); err != nil {
+		j.logger.Warn("Failed to backfill benchmark price history",
+			zap.Int64("benchmarkID", j.BenchmarkID),
+			zap.String("ticker", j.Ticker),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
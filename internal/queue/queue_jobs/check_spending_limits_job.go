@@ -0,0 +1,25 @@
+package queue_jobs
+
+import (
+	"context"
+	"time"
+)
+
+type spendingLimitChecker interface {
+	CheckLimits(ctx context.Context, userID int64, months []time.Time) error
+}
+
+// CheckSpendingLimitsJob looks for spending limits crossed by transactions
+// written in the given months. Writers only know the data; the consumer's
+// registry attaches the checker.
+type CheckSpendingLimitsJob struct {
+	Checker spendingLimitChecker `json:"-"`
+	UserID  int64
+	Months  []time.Time
+}
+
+func (j *CheckSpendingLimitsJob) Type() string { return TypeCheckSpendingLimits }
+
+func (j *CheckSpendingLimitsJob) Process(ctx context.Context) error {
+	return j.Checker.CheckLimits(ctx, j.UserID, j.Months)
+}
@@ -0,0 +1,435 @@
+package queue_jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/internal/ws"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrNoCapitalGainsData = errors.New("no sells or investment income found for the selected tax year")
+
+type capitalGainsSource interface {
+	FindAllTradesByUserID(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTrade, error)
+	FindTaxBracketsByUser(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTaxBracket, error)
+	FindTaxSettings(ctx context.Context, tx *gorm.DB, userID int64) (models.InvestmentTaxSettings, error)
+	FindInvestmentIncomeInRange(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) ([]models.InvestmentIncome, error)
+}
+
+// reportTable is one section of the report, rendered as a sheet in XLSX and as
+// a titled block in CSV.
+type reportTable struct {
+	title     string
+	sheet     string
+	headers   []string
+	rows      [][]string
+	labelCols int
+}
+
+type GenerateCapitalGainsReportJob struct {
+	logger         *zap.Logger
+	analyticsRepo  repositories.AnalyticsRepositoryInterface
+	investmentRepo capitalGainsSource
+	broadcaster    ws.Broadcaster
+	ReportID       int64
+	UserID         int64
+	Params         models.CapitalGainsReportParams
+}
+
+func (j *GenerateCapitalGainsReportJob) Type() string { return TypeGenerateCapitalGains }
+
+func NewGenerateCapitalGainsReportJob(
+	logger *zap.Logger,
+	analyticsRepo repositories.AnalyticsRepositoryInterface,
+	investmentRepo capitalGainsSource,
+	broadcaster ws.Broadcaster,
+	reportID, userID int64,
+	params models.CapitalGainsReportParams,
+) *GenerateCapitalGainsReportJob {
+	return &GenerateCapitalGainsReportJob{
+		logger:         logger,
+		analyticsRepo:  analyticsRepo,
+		investmentRepo: investmentRepo,
+		broadcaster:    broadcaster,
+		ReportID:       reportID,
+		UserID:         userID,
+		Params:         params,
+	}
+}
+
+func (j *GenerateCapitalGainsReportJob) Process(ctx context.Context) error {
+	if err := j.analyticsRepo.UpdateReport(ctx, nil, j.ReportID, map[string]interface{}{
+		"status": "processing",
+	}); err != nil {
+		return err
+	}
+
+	trades, err := j.investmentRepo.FindAllTradesByUserID(ctx, nil, j.UserID)
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+	brackets, err := j.investmentRepo.FindTaxBracketsByUser(ctx, nil, j.UserID)
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+	settings, err := j.investmentRepo.FindTaxSettings(ctx, nil, j.UserID)
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+
+	from := time.Date(j.Params.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	income, err := j.investmentRepo.FindInvestmentIncomeInRange(ctx, nil, j.UserID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+
+	rate, err := j.reportRates(ctx, trades, income)
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+
+	summary := utils.RealizedGainsForYear(trades, brackets, settings, j.Params.Year, rate)
+	if len(summary.Rows) == 0 && len(income) == 0 {
+		return j.fail(ctx, ErrNoCapitalGainsData)
+	}
+
+	tables := []reportTable{
+		summaryTable(summary, income, rate, j.Params.Currency),
+		gainsTable(summary),
+		incomeTable(income),
+	}
+
+	var data []byte
+	if j.Params.Format == "csv" {
+		data, err = buildCSV(tables)
+	} else {
+		data, err = buildTablesXLSX(tables)
+	}
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+
+	filePath, err := j.saveFile(data)
+	if err != nil {
+		return j.fail(ctx, err)
+	}
+
+	now := time.Now().UTC()
+	fileSize := int64(len(data))
+	if err := j.analyticsRepo.UpdateReport(ctx, nil, j.ReportID, map[string]interface{}{
+		"status":       "completed",
+		"file_path":    filePath,
+		"file_size":    fileSize,
+		"completed_at": now,
+	}); err != nil {
+		return err
+	}
+
+	j.broadcaster.Send(j.UserID, ws.Event{Type: ws.TypeReportCompleted, Payload: ws.ReportPayload{ReportID: j.ReportID}})
+	return nil
+}
+
+type rateKey struct {
+	currency string
+	on       time.Time
+}
+
+// reportRates looks up the stored rate into the report currency for the date of
+// every sell and income entry of the year. Totals can't mix currencies, so a
+// missing rate fails the report instead of falling back to 1:1.
+func (j *GenerateCapitalGainsReportJob) reportRates(ctx context.Context, trades []models.InvestmentTrade, income []models.InvestmentIncome) (utils.RateFunc, error) {
+	base := j.Params.Currency
+	if base == "" {
+		return nil, nil
+	}
+
+	rates := make(map[rateKey]decimal.Decimal)
+	lookup := func(currency string, on time.Time) error {
+		key := rateKey{currency: currency, on: on.UTC().Truncate(24 * time.Hour)}
+		if currency == base {
+			return nil
+		}
+		if _, ok := rates[key]; ok {
+			return nil
+		}
+
+		rate, found, err := j.analyticsRepo.FetchLatestExchangeRate(ctx, nil, currency, base, key.on)
+		if err != nil {
+			return err
+		}
+		if !found {
+			inverse, ok, err := j.analyticsRepo.FetchLatestExchangeRate(ctx, nil, base, currency, key.on)
+			if err != nil {
+				return err
+			}
+			if !ok || !inverse.IsPositive() {
+				return fmt.Errorf("no exchange rate from %s to %s on %s", currency, base, key.on.Format("2006-01-02"))
+			}
+			rate = decimal.NewFromInt(1).Div(inverse)
+		}
+		rates[key] = rate
+		return nil
+	}
+
+	for _, t := range trades {
+		if t.TradeType == models.InvestmentSell && t.TxnDate.Year() == j.Params.Year {
+			if err := lookup(t.Currency, t.TxnDate); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, inc := range income {
+		if err := lookup(inc.Currency, inc.TxnDate); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(currency string, on time.Time) decimal.Decimal {
+		if currency == base {
+			return decimal.NewFromInt(1)
+		}
+		return rates[rateKey{currency: currency, on: on.UTC().Truncate(24 * time.Hour)}]
+	}, nil
+}
+
+func (j *GenerateCapitalGainsReportJob) fail(ctx context.Context, err error) error {
+	j.logger.Error("capital gains report generation failed", zap.Int64("reportID", j.ReportID), zap.Error(err))
+	msg := err.Error()
+	_ = j.analyticsRepo.UpdateReport(ctx, nil, j.ReportID, map[string]interface{}{
+		"status": "failed",
+		"error":  msg,
+	})
+	j.broadcaster.Send(j.UserID, ws.Event{Type: ws.TypeReportFailed, Payload: ws.ReportPayload{ReportID: j.ReportID}})
+	return err
+}
+
+func (j *GenerateCapitalGainsReportJob) saveFile(data []byte) (string, error) {
+	ext := "xlsx"
+	if j.Params.Format == "csv" {
+		ext = "csv"
+	}
+	dir := filepath.Join("storage", "reports", fmt.Sprintf("%d", j.UserID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(dir, fmt.Sprintf("%d.%s", j.ReportID, ext))
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+func summaryTable(summary models.CapitalGainsSummary, income []models.InvestmentIncome, rate utils.RateFunc, currency string) reportTable {
+	offsetting := "Disabled"
+	if summary.LossOffsetting {
+		offsetting = "Enabled"
+	}
+	if currency == "" {
+		currency = "-"
+	}
+
+	var received, withheld decimal.Decimal
+	for _, inc := range income {
+		r := decimal.NewFromInt(1)
+		if rate != nil {
+			r = rate(inc.Currency, inc.TxnDate)
+		}
+		received = received.Add(inc.Amount.Mul(r))
+		if inc.TaxWithheld != nil {
+			withheld = withheld.Add(inc.TaxWithheld.Mul(r))
+		}
+	}
+
+	return reportTable{
+		title:   fmt.Sprintf("Capital Gains Summary - %d", summary.Year),
+		sheet:   "Summary",
+		headers: []string{"Metric", "Value"},
+		rows: [][]string{
+			{"Tax Year", fmt.Sprintf("%d", summary.Year)},
+			{"Loss Offsetting", offsetting},
+			{"Currency", currency},
+			{"Sells", fmt.Sprintf("%d", len(summary.Rows))},
+			{"Total Proceeds", summary.TotalProceeds.StringFixed(2)},
+			{"Total Gains", summary.TotalGains.StringFixed(2)},
+			{"Total Losses", summary.TotalLosses.StringFixed(2)},
+			{"Net Gain", summary.NetGain.StringFixed(2)},
+			{"Taxable Gain", summary.TaxableGain.StringFixed(2)},
+			{"Estimated Tax", summary.TaxDue.StringFixed(2)},
+			{"Investment Income", received.StringFixed(2)},
+			{"Tax Withheld on Income", withheld.StringFixed(2)},
+		},
+		labelCols: 1,
+	}
+}
+
+func gainsTable(summary models.CapitalGainsSummary) reportTable {
+	t := reportTable{
+		title: "Realized Gains",
+		sheet: "Realized Gains",
+		headers: []string{
+			"Sell Date", "Ticker", "Name", "Type", "Acquired", "Quantity", "Proceeds", "Cost Basis", "Fees",
+			"Gain", "Days Held", "Bracket", "Tax %", "Taxable Gain", "Tax Due", "Currency",
+		},
+		labelCols: 5,
+	}
+
+	for _, r := range summary.Rows {
+		acquired := make([]string, len(r.AcquisitionDates))
+		for i, d := range r.AcquisitionDates {
+			acquired[i] = d.Format("2006-01-02")
+		}
+
+		bracketLabel, taxPct := "-", "-"
+		if r.Bracket != nil {
+			bracketLabel = bracketName(*r.Bracket)
+			taxPct = r.Bracket.TaxablePercent.StringFixed(2)
+		}
+
+		t.rows = append(t.rows, []string{
+			r.SellDate.Format("2006-01-02"),
+			r.Ticker,
+			r.AssetName,
+			string(r.InvestmentType),
+			strings.Join(acquired, "; "),
+			r.Quantity.String(),
+			r.Proceeds.StringFixed(2),
+			r.CostBasis.StringFixed(2),
+			r.Fees.StringFixed(2),
+			r.Gain.StringFixed(2),
+			fmt.Sprintf("%d", r.DaysHeld),
+			bracketLabel,
+			taxPct,
+			r.TaxableGain.StringFixed(2),
+			r.TaxDue.StringFixed(2),
+			r.Currency,
+		})
+	}
+	return t
+}
+
+func incomeTable(income []models.InvestmentIncome) reportTable {
+	t := reportTable{
+		title:     "Dividend & Staking Income",
+		sheet:     "Income",
+		headers:   []string{"Date", "Ticker", "Name", "Type", "Quantity", "Amount", "Tax Withheld", "Net", "Currency"},
+		labelCols: 4,
+	}
+
+	for _, inc := range income {
+		qty := "-"
+		if inc.Quantity != nil {
+			qty = inc.Quantity.String()
+		}
+		withheld := decimal.Zero
+		if inc.TaxWithheld != nil {
+			withheld = *inc.TaxWithheld
+		}
+		t.rows = append(t.rows, []string{
+			inc.TxnDate.Format("2006-01-02"),
+			inc.Asset.Ticker,
+			inc.Asset.Name,
+			humanizeSubtype(string(inc.IncomeType)),
+			qty,
+			inc.Amount.StringFixed(2),
+			withheld.StringFixed(2),
+			inc.Amount.Sub(withheld).StringFixed(2),
+			inc.Currency,
+		})
+	}
+	return t
+}
+
+func bracketName(b models.InvestmentTaxBracket) string {
+	if b.Label != nil && *b.Label != "" {
+		return *b.Label
+	}
+	if b.ToDays != nil {
+		return fmt.Sprintf("%d-%d days", b.MinDaysHeld, *b.ToDays)
+	}
+	return fmt.Sprintf("%d+ days", b.MinDaysHeld)
+}
+
+func buildTablesXLSX(tables []reportTable) ([]byte, error) {
+	f := excelize.NewFile()
+	defer func(f *excelize.File) {
+		err := f.Close()
+		if err != nil {
+			fmt.Printf("Error closing file: %s\n", err)
+		}
+	}(f)
+
+	styles := makeXLSXStyles(f)
+
+	for i, t := range tables {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", t.sheet); err != nil {
+				return nil, err
+			}
+		} else if _, err := f.NewSheet(t.sheet); err != nil {
+			return nil, err
+		}
+
+		cur := 1
+		cur = xlsxTitle(f, t.sheet, cur, t.title, styles.SectionTitle)
+		cur++
+		cur = xlsxHeaderRow(f, t.sheet, cur, t.headers, styles)
+		for _, row := range t.rows {
+			cur = xlsxDataRow(f, t.sheet, cur, row, t.labelCols, styles)
+		}
+
+		lastCol, _ := excelize.ColumnNumberToName(len(t.headers))
+		if err := f.SetColWidth(t.sheet, "A", lastCol, 16); err != nil {
+			return nil, err
+		}
+	}
+
+	f.SetActiveSheet(0)
+
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func buildCSV(tables []reportTable) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	for i, t := range tables {
+		if i > 0 {
+			if err := w.Write([]string{}); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Write([]string{t.title}); err != nil {
+			return nil, err
+		}
+		if err := w.Write(t.headers); err != nil {
+			return nil, err
+		}
+		if err := w.WriteAll(t.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,186 @@
+package queue_jobs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/ws"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+type mockCapitalGainsSource struct {
+	trades   []models.InvestmentTrade
+	brackets []models.InvestmentTaxBracket
+	settings models.InvestmentTaxSettings
+	income   []models.InvestmentIncome
+	err      error
+}
+
+func (m *mockCapitalGainsSource) FindAllTradesByUserID(_ context.Context, _ *gorm.DB, _ int64) ([]models.InvestmentTrade, error) {
+	return m.trades, m.err
+}
+func (m *mockCapitalGainsSource) FindTaxBracketsByUser(_ context.Context, _ *gorm.DB, _ int64) ([]models.InvestmentTaxBracket, error) {
+	return m.brackets, nil
+}
+func (m *mockCapitalGainsSource) FindTaxSettings(_ context.Context, _ *gorm.DB, _ int64) (models.InvestmentTaxSettings, error) {
+	return m.settings, nil
+}
+func (m *mockCapitalGainsSource) FindInvestmentIncomeInRange(_ context.Context, _ *gorm.DB, _ int64, _, _ time.Time) ([]models.InvestmentIncome, error) {
+	return m.income, nil
+}
+
+func sampleGainsSource() *mockCapitalGainsSource {
+	asset := models.InvestmentAsset{ID: 3, Ticker: "VWCE", Name: "FTSE All-World", InvestmentType: models.InvestmentETF}
+	withheld := decimal.NewFromInt(3)
+	return &mockCapitalGainsSource{
+		trades: []models.InvestmentTrade{
+			{ID: 1, AssetID: 3, Asset: asset, TradeType: models.InvestmentBuy, TxnDate: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+				Quantity: decimal.NewFromInt(10), ValueAtBuy: decimal.NewFromInt(1000), Currency: "EUR"},
+			{ID: 2, AssetID: 3, Asset: asset, TradeType: models.InvestmentSell, TxnDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				Quantity: decimal.NewFromInt(4), RealizedValue: decimal.NewFromInt(520), Currency: "EUR"},
+		},
+		brackets: []models.InvestmentTaxBracket{{InvestmentType: models.InvestmentETF, MinDaysHeld: 0, TaxablePercent: decimal.NewFromInt(25)}},
+		income: []models.InvestmentIncome{
+			{ID: 1, AssetID: 3, Asset: asset, TxnDate: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), IncomeType: models.IncomeTypeDividend,
+				Amount: decimal.NewFromInt(20), TaxWithheld: &withheld, Currency: "EUR"},
+		},
+	}
+}
+
+func TestGenerateCapitalGainsReportJob_CSV(t *testing.T) {
+	repo := &mockAnalyticsRepo{}
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, sampleGainsSource(), ws.NoopBroadcaster{}, 11, 1, models.CapitalGainsReportParams{
+		Year:   2024,
+		Format: "csv",
+	})
+
+	if err := job.Process(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := repo.updates[len(repo.updates)-1]
+	if s, _ := last["status"].(string); s != "completed" {
+		t.Fatalf("last update status = %q, want \"completed\"", s)
+	}
+	path, _ := last["file_path"].(string)
+	if !strings.HasSuffix(path, "11.csv") {
+		t.Fatalf("file_path = %q, want a .csv file", path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	out := string(raw)
+	for _, want := range []string{
+		"Realized Gains",
+		"2024-05-01,VWCE,FTSE All-World,etf,2023-03-01,4,520.00,400.00,0.00,120.00,427,0+ days,25.00,120.00,30.00,EUR",
+		"Dividend & Staking Income",
+		"2024-06-15,VWCE,FTSE All-World,Dividend,-,20.00,3.00,17.00,EUR",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// Totals are taken in the user's currency; income in another currency is converted
+// at the stored rate, here only available the other way round.
+func TestGenerateCapitalGainsReportJob_ConvertsTotals(t *testing.T) {
+	repo := &mockAnalyticsRepo{rates: map[string]decimal.Decimal{"EUR/USD": decimal.NewFromFloat(1.25)}}
+	src := sampleGainsSource()
+	src.income[0].Currency = "USD"
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, src, ws.NoopBroadcaster{}, 15, 1, models.CapitalGainsReportParams{
+		Year:     2024,
+		Format:   "csv",
+		Currency: "EUR",
+	})
+
+	if err := job.Process(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path, _ := repo.updates[len(repo.updates)-1]["file_path"].(string)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	out := string(raw)
+	for _, want := range []string{
+		"Currency,EUR",
+		"Net Gain,120.00",
+		"Investment Income,16.00",
+		"Tax Withheld on Income,2.40",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateCapitalGainsReportJob_MissingRate_Fails(t *testing.T) {
+	repo := &mockAnalyticsRepo{}
+	src := sampleGainsSource()
+	src.income[0].Currency = "USD"
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, src, ws.NoopBroadcaster{}, 16, 1, models.CapitalGainsReportParams{
+		Year:     2024,
+		Currency: "EUR",
+	})
+
+	if err := job.Process(context.Background()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if s, _ := repo.updates[len(repo.updates)-1]["status"].(string); s != "failed" {
+		t.Errorf("last update status = %q, want \"failed\"", s)
+	}
+}
+
+func TestGenerateCapitalGainsReportJob_DefaultsToXLSX(t *testing.T) {
+	repo := &mockAnalyticsRepo{}
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, sampleGainsSource(), ws.NoopBroadcaster{}, 12, 1, models.CapitalGainsReportParams{Year: 2024})
+
+	if err := job.Process(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := repo.updates[len(repo.updates)-1]
+	if path, _ := last["file_path"].(string); !strings.HasSuffix(path, "12.xlsx") {
+		t.Errorf("file_path = %q, want a .xlsx file", path)
+	}
+}
+
+func TestGenerateCapitalGainsReportJob_NoActivity_Fails(t *testing.T) {
+	repo := &mockAnalyticsRepo{}
+	broadcaster := &recordingBroadcaster{}
+	src := sampleGainsSource()
+	src.income = nil
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, src, broadcaster, 13, 1, models.CapitalGainsReportParams{Year: 2019})
+
+	if err := job.Process(context.Background()); !errors.Is(err, queue_jobs.ErrNoCapitalGainsData) {
+		t.Fatalf("error = %v, want ErrNoCapitalGainsData", err)
+	}
+	if s, _ := repo.updates[len(repo.updates)-1]["status"].(string); s != "failed" {
+		t.Errorf("last update status = %q, want \"failed\"", s)
+	}
+	if got := broadcaster.events[1]; len(got) != 1 || got[0].Type != ws.TypeReportFailed {
+		t.Errorf("events = %+v, want one report_failed", got)
+	}
+}
+
+func TestGenerateCapitalGainsReportJob_SourceError_SetsFailedStatus(t *testing.T) {
+	repo := &mockAnalyticsRepo{}
+	src := &mockCapitalGainsSource{err: errors.New("db unavailable")}
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(zaptest.NewLogger(t), repo, src, ws.NoopBroadcaster{}, 14, 1, models.CapitalGainsReportParams{Year: 2024})
+
+	if err := job.Process(context.Background()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if s, _ := repo.updates[len(repo.updates)-1]["status"].(string); s != "failed" {
+		t.Errorf("last update status = %q, want \"failed\"", s)
+	}
+}
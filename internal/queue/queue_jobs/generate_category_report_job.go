@@ -162,7 +162,7 @@ func (j *GenerateCategoryReportJob) buildXLSX(rows []models.CategoryReportDataRo
 		}
 	}(f)
 
-	styles := j.makeStyles(f)
+	styles := makeXLSXStyles(f)
 
 	err := f.SetSheetName("Sheet1", "Summary")
 	if err != nil {
@@ -195,7 +195,7 @@ func (j *GenerateCategoryReportJob) buildXLSX(rows []models.CategoryReportDataRo
 	return buf.Bytes(), nil
 }
 
-func (j *GenerateCategoryReportJob) makeStyles(f *excelize.File) xlsxStyles {
+func makeXLSXStyles(f *excelize.File) xlsxStyles {
 	thin := []excelize.Border{
 		{Type: "left", Color: "BFBFBF", Style: 1},
 		{Type: "right", Color: "BFBFBF", Style: 1},
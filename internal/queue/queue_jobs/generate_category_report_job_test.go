@@ -35,6 +35,7 @@ type mockAnalyticsRepo struct {
 	updateErrOn int // which call (1-indexed) should fail; 0 = always fail
 	updateCalls int
 	updates     []map[string]interface{}
+	rates       map[string]decimal.Decimal // keyed "FROM/TO"
 }
 
 func (m *mockAnalyticsRepo) UpdateReport(_ context.Context, _ *gorm.DB, _ int64, fields map[string]interface{}) error {
@@ -112,6 +113,28 @@ func (m *mockAnalyticsRepo) FetchMonthlyCategoryTotalsCheckingOnly(_ context.Con
 func (m *mockAnalyticsRepo) GetAvailableStatsYears(_ context.Context, _ *gorm.DB, _ *int64, _ int64, _ bool) ([]models.AvailableStatsYear, error) {
 	return nil, nil
 }
+func (m *mockAnalyticsRepo) FetchPerformanceAssets(_ context.Context, _ *gorm.DB, _ int64, _, _ *int64) ([]models.InvestmentAsset, error) {
+	return nil, nil
+}
+func (m *mockAnalyticsRepo) FetchPerformanceHistory(_ context.Context, _ *gorm.DB, _ int64, _ []int64, _, _ time.Time) ([]models.InvestmentTrade, []models.InvestmentIncome, []models.AssetPriceHistory, error) {
+	return nil, nil, nil, nil
+}
+func (m *mockAnalyticsRepo) FetchLatestExchangeRate(_ context.Context, _ *gorm.DB, from, to string, _ time.Time) (decimal.Decimal, bool, error) {
+	rate, ok := m.rates[from+"/"+to]
+	return rate, ok, nil
+}
+func (m *mockAnalyticsRepo) FetchChartBenchmarks(_ context.Context, _ *gorm.DB, _ int64, _ *int64) ([]models.Benchmark, error) {
+	return nil, nil
+}
+func (m *mockAnalyticsRepo) FetchBenchmarkPrices(_ context.Context, _ *gorm.DB, _ int64, _, _ time.Time) ([]models.ChartPoint, error) {
+	return nil, nil
+}
+func (m *mockAnalyticsRepo) FetchCashByCurrency(_ context.Context, _ *gorm.DB, _ int64, _, _ time.Time) (map[string][]models.ChartPoint, error) {
+	return nil, nil
+}
+func (m *mockAnalyticsRepo) FetchExchangeRateSeries(_ context.Context, _ *gorm.DB, _, _ string, _, _ time.Time) ([]models.ChartPoint, error) {
+	return nil, nil
+}
 
 var sampleRows = []models.CategoryReportDataRow{
 	{Year: 2024, Month: 1, CategoryName: "Salary", Classification: "inflow", Total: decimal.NewFromInt(5000)},
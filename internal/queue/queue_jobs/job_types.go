@@ -9,4 +9,7 @@ const (
 	TypeNotification           = "notification"
 	TypeCorrectFeeAccounting   = "correct_fee_accounting"
 	TypeGenerateCategoryReport = "generate_category_report"
+	TypeGenerateCapitalGains   = "generate_capital_gains_report"
+	TypeBackfillBenchmark      = "backfill_benchmark"
+	TypeCheckSpendingLimits    = "check_spending_limits"
 )
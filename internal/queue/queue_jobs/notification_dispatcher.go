@@ -5,10 +5,13 @@ import (
 	"wealth-warden/internal/models"
 	"wealth-warden/internal/queue"
 	"wealth-warden/internal/repositories"
+	"wealth-warden/internal/ws"
 )
 
 type NotificationDispatcher interface {
 	Dispatch(ctx context.Context, userID int64, title, message string, notifType models.NotificationType) error
+	// DispatchWithEvent also sends event over the user's websocket once the notification is stored.
+	DispatchWithEvent(ctx context.Context, userID int64, title, message string, notifType models.NotificationType, event ws.Event) error
 }
 
 type notificationDispatcher struct {
@@ -31,3 +34,16 @@ func (d *notificationDispatcher) Dispatch(ctx context.Context, userID int64, tit
 		},
 	})
 }
+
+func (d *notificationDispatcher) DispatchWithEvent(ctx context.Context, userID int64, title, message string, notifType models.NotificationType, event ws.Event) error {
+	return d.jobDispatcher.Dispatch(ctx, &NotificationJob{
+		Repo: d.repo,
+		Payload: models.Notification{
+			UserID:  userID,
+			Title:   title,
+			Message: message,
+			Type:    notifType,
+		},
+		Event: &event,
+	})
+}
@@ -11,6 +11,8 @@ type NotificationJob struct {
 	Repo        repositories.NotificationRepositoryInterface `json:"-"`
 	Broadcaster ws.Broadcaster                               `json:"-"`
 	Payload     models.Notification
+	// Event, when set, is sent alongside notification.created so clients can react to what happened.
+	Event *ws.Event `json:",omitempty"`
 }
 
 func (j *NotificationJob) Type() string { return TypeNotification }
@@ -20,5 +22,8 @@ func (j *NotificationJob) Process(ctx context.Context) error {
 		return err
 	}
 	j.Broadcaster.Send(j.Payload.UserID, ws.Event{Type: ws.TypeNotificationCreated})
+	if j.Event != nil {
+		j.Broadcaster.Send(j.Payload.UserID, *j.Event)
+	}
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"wealth-warden/internal/models"
 	"wealth-warden/internal/queue"
 	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/ws"
 	"wealth-warden/pkg/utils"
 )
 
@@ -85,12 +86,35 @@ func TestPayloadContract(t *testing.T) {
 		Payload: models.Notification{UserID: 1, Title: "hi"},
 	}, "Payload")
 
+	assertKeys(t, &queue_jobs.NotificationJob{
+		Payload: models.Notification{UserID: 1, Title: "hi"},
+		Event:   &ws.Event{Type: ws.TypeSavingGoalMilestone, Payload: ws.SavingGoalPayload{GoalID: 2}},
+	}, "Payload", "Event")
+
 	assertKeys(t, &queue_jobs.GenerateCategoryReportJob{
 		ReportID: 9,
 		UserID:   1,
 		Params:   models.CategoryReportParams{Years: []int{2026}, Description: "d"},
 	}, "ReportID", "UserID", "Params")
 
+	assertKeys(t, &queue_jobs.GenerateCapitalGainsReportJob{
+		ReportID: 10,
+		UserID:   1,
+		Params:   models.CapitalGainsReportParams{Year: 2025, Format: "csv"},
+	}, "ReportID", "UserID", "Params")
+
+	assertKeys(t, &queue_jobs.BackfillBenchmarkJob{
+		BenchmarkID:    3,
+		Ticker:         "SPY",
+		InvestmentType: models.InvestmentETF,
+		From:           time.Now(),
+	}, "BenchmarkID", "Ticker", "InvestmentType", "From")
+
+	assertKeys(t, &queue_jobs.CheckSpendingLimitsJob{
+		UserID: 1,
+		Months: []time.Time{time.Now()},
+	}, "UserID", "Months")
+
 	// Payload-less maintenance jobs serialize to an empty object — deps dropped.
 	assertKeys(t, &queue_jobs.BackfillAssetCashFlowsJob{})
 	assertKeys(t, &queue_jobs.CorrectFeeAccountingJob{})
@@ -146,6 +170,9 @@ func TestJobTypeTags(t *testing.T) {
 		&queue_jobs.NotificationJob{}:                queue_jobs.TypeNotification,
 		&queue_jobs.CorrectFeeAccountingJob{}:        queue_jobs.TypeCorrectFeeAccounting,
 		&queue_jobs.GenerateCategoryReportJob{}:      queue_jobs.TypeGenerateCategoryReport,
+		&queue_jobs.GenerateCapitalGainsReportJob{}:  queue_jobs.TypeGenerateCapitalGains,
+		&queue_jobs.BackfillBenchmarkJob{}:           queue_jobs.TypeBackfillBenchmark,
+		&queue_jobs.CheckSpendingLimitsJob{}:         queue_jobs.TypeCheckSpendingLimits,
 	}
 	for job, want := range cases {
 		if got := job.Type(); got != want {
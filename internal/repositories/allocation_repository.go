@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AllocationRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) ([]models.AllocationTarget, error)
+	FindTargetScopes(ctx context.Context, tx *gorm.DB) ([]models.AllocationScope, error)
+	ReplaceTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64, targets []models.AllocationTarget) error
+	DeleteTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) error
+	SetOutOfBandSince(ctx context.Context, tx *gorm.DB, ids []int64, since *time.Time) error
+}
+
+type AllocationRepository struct {
+	db *gorm.DB
+}
+
+func NewAllocationRepository(db *gorm.DB) *AllocationRepository {
+	return &AllocationRepository{db: db}
+}
+
+var _ AllocationRepositoryInterface = (*AllocationRepository)(nil)
+
+func (r *AllocationRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func whereAllocationScope(db *gorm.DB, accountID *int64) *gorm.DB {
+	if accountID == nil {
+		return db.Where("account_id IS NULL")
+	}
+	return db.Where("account_id = ?", *accountID)
+}
+
+func (r *AllocationRepository) FindTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) ([]models.AllocationTarget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AllocationTarget
+	q := db.Model(&models.AllocationTarget{}).Where("user_id = ?", userID)
+	err := whereAllocationScope(q, accountID).
+		Order("target_percent DESC, id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// FindTargetScopes lists every user/account pair that has a target set.
+func (r *AllocationRepository) FindTargetScopes(ctx context.Context, tx *gorm.DB) ([]models.AllocationScope, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var scopes []models.AllocationScope
+	err := db.Model(&models.AllocationTarget{}).
+		Distinct("user_id", "account_id").
+		Order("user_id ASC").
+		Scan(&scopes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return scopes, nil
+}
+
+func (r *AllocationRepository) ReplaceTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64, targets []models.AllocationTarget) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := r.DeleteTargets(ctx, db, userID, accountID); err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	for i := range targets {
+		targets[i].UserID = userID
+		targets[i].AccountID = accountID
+	}
+	return db.Create(&targets).Error
+}
+
+func (r *AllocationRepository) DeleteTargets(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return whereAllocationScope(db.Where("user_id = ?", userID), accountID).
+		Delete(&models.AllocationTarget{}).Error
+}
+
+func (r *AllocationRepository) SetOutOfBandSince(ctx context.Context, tx *gorm.DB, ids []int64, since *time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.AllocationTarget{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"out_of_band_since": since,
+			"updated_at":        time.Now().UTC(),
+		}).Error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"wealth-warden/internal/models"
@@ -32,6 +33,13 @@ type AnalyticsRepositoryInterface interface {
 	DeleteReport(ctx context.Context, tx *gorm.DB, id, userID int64) error
 	FetchCategoryReportData(ctx context.Context, tx *gorm.DB, userID int64, params models.CategoryReportParams) ([]models.CategoryReportDataRow, error)
 	FindReportAccountScope(ctx context.Context, tx *gorm.DB, userID, accountID int64) (*models.ReportAccountScope, error)
+	FetchPerformanceAssets(ctx context.Context, tx *gorm.DB, userID int64, assetID, accountID *int64) ([]models.InvestmentAsset, error)
+	FetchPerformanceHistory(ctx context.Context, tx *gorm.DB, userID int64, assetIDs []int64, from, to time.Time) ([]models.InvestmentTrade, []models.InvestmentIncome, []models.AssetPriceHistory, error)
+	FetchLatestExchangeRate(ctx context.Context, tx *gorm.DB, from, to string, asOf time.Time) (decimal.Decimal, bool, error)
+	FetchChartBenchmarks(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) ([]models.Benchmark, error)
+	FetchBenchmarkPrices(ctx context.Context, tx *gorm.DB, benchmarkID int64, from, to time.Time) ([]models.ChartPoint, error)
+	FetchCashByCurrency(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) (map[string][]models.ChartPoint, error)
+	FetchExchangeRateSeries(ctx context.Context, tx *gorm.DB, fromCurrency, toCurrency string, from, to time.Time) ([]models.ChartPoint, error)
 }
 type AnalyticsRepository struct {
 	db *gorm.DB
@@ -1078,3 +1086,222 @@ func (r *AnalyticsRepository) FetchAssetChartSeries(ctx context.Context, tx *gor
 
 	return currency, toPoints(mvRows), toPoints(cbRows), nil
 }
+
+// FetchPerformanceAssets returns the holdings a performance figure covers: one
+// asset, every asset of an account, or (both nil) the whole portfolio.
+func (r *AnalyticsRepository) FetchPerformanceAssets(ctx context.Context, tx *gorm.DB, userID int64, assetID, accountID *int64) ([]models.InvestmentAsset, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	q := db.Preload("Account").Where("user_id = ?", userID)
+	if assetID != nil {
+		q = q.Where("id = ?", *assetID)
+	}
+	if accountID != nil {
+		q = q.Where("account_id = ?", *accountID)
+	}
+
+	var assets []models.InvestmentAsset
+	err := q.Order("id ASC").Find(&assets).Error
+	return assets, err
+}
+
+// FetchPerformanceHistory loads everything needed to replay the given assets up
+// to `to`: all trades and income, and the price history from a month before
+// `from` so the opening price can be carried forward. A zero `from` loads the
+// whole price history.
+func (r *AnalyticsRepository) FetchPerformanceHistory(ctx context.Context, tx *gorm.DB, userID int64, assetIDs []int64, from, to time.Time) ([]models.InvestmentTrade, []models.InvestmentIncome, []models.AssetPriceHistory, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var trades []models.InvestmentTrade
+	if err := db.Where("user_id = ? AND asset_id IN ? AND txn_date <= ?", userID, assetIDs, to).
+		Order("txn_date ASC, id ASC").
+		Find(&trades).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	var income []models.InvestmentIncome
+	if err := db.Where("user_id = ? AND asset_id IN ? AND txn_date <= ?", userID, assetIDs, to).
+		Order("txn_date ASC, id ASC").
+		Find(&income).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	priceQ := db.Where("asset_id IN ? AND as_of <= ?", assetIDs, to)
+	if !from.IsZero() {
+		priceQ = priceQ.Where("as_of >= ?", from.AddDate(0, -1, 0))
+	}
+	var prices []models.AssetPriceHistory
+	if err := priceQ.
+		Order("as_of ASC").
+		Find(&prices).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	return trades, income, prices, nil
+}
+
+// FetchLatestExchangeRate returns the most recent stored rate on or before asOf.
+func (r *AnalyticsRepository) FetchLatestExchangeRate(ctx context.Context, tx *gorm.DB, from, to string, asOf time.Time) (decimal.Decimal, bool, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var entry models.ExchangeRateHistory
+	err := db.WithContext(ctx).
+		Where("from_currency = ? AND to_currency = ? AND as_of <= ?", from, to, asOf).
+		Order("as_of DESC").
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return decimal.Zero, false, nil
+	}
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	return entry.Rate, true, nil
+}
+
+// FetchChartBenchmarks returns the benchmarks attached to the portfolio, plus
+// those attached to accountID when one is given.
+func (r *AnalyticsRepository) FetchChartBenchmarks(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) ([]models.Benchmark, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	q := db.WithContext(ctx).Model(&models.Benchmark{}).
+		Joins("JOIN user_benchmarks ub ON ub.benchmark_id = benchmarks.id").
+		Where("ub.user_id = ?", userID)
+	if accountID != nil {
+		q = q.Where("ub.account_id IS NULL OR ub.account_id = ?", *accountID)
+	} else {
+		q = q.Where("ub.account_id IS NULL")
+	}
+
+	var records []models.Benchmark
+	err := q.Distinct("benchmarks.*").
+		Order("benchmarks.ticker ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// FetchBenchmarkPrices returns a benchmark's prices in [from, to], starting a
+// little earlier so the first chart point has a price to carry forward.
+func (r *AnalyticsRepository) FetchBenchmarkPrices(ctx context.Context, tx *gorm.DB, benchmarkID int64, from, to time.Time) ([]models.ChartPoint, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var prices []models.BenchmarkPriceHistory
+	err := db.WithContext(ctx).
+		Where("benchmark_id = ? AND as_of >= ? AND as_of <= ?", benchmarkID, from.AddDate(0, 0, -10), to).
+		Order("as_of ASC").
+		Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]models.ChartPoint, len(prices))
+	for i, p := range prices {
+		points[i] = models.ChartPoint{Date: p.AsOf, Value: p.Price}
+	}
+	return points, nil
+}
+
+// FetchCashByCurrency sums the cash balances of the user's net worth accounts per
+// account currency and day in [from, to], scaled by the user's ownership share.
+// Accounts shared with the user count at their share, as in
+// v_user_account_daily_snapshots. Holdings are left out; liabilities count negative.
+func (r *AnalyticsRepository) FetchCashByCurrency(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) (map[string][]models.ChartPoint, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	type row struct {
+		AsOf     time.Time
+		Currency string
+		Amount   decimal.Decimal
+	}
+	var rows []row
+	err := db.WithContext(ctx).Raw(`
+		WITH cash AS (
+			SELECT s.as_of, s.currency,
+			       s.end_balance * COALESCE(a.ownership_percent, 100) / 100 AS amount
+			FROM account_daily_snapshots s
+			JOIN accounts a ON a.id = s.account_id
+			WHERE a.user_id = ?
+			  AND a.include_in_net_worth = TRUE
+			  AND (a.opened_at IS NULL OR s.as_of::date >= a.opened_at::date)
+			  AND (a.closed_at IS NULL OR s.as_of::date <  a.closed_at::date)
+			  AND s.as_of BETWEEN ? AND ?
+			UNION ALL
+			SELECT s.as_of, s.currency,
+			       s.end_balance * COALESCE(sh.ownership_percent, 100) / 100 AS amount
+			FROM account_daily_snapshots s
+			JOIN accounts a        ON a.id = s.account_id
+			JOIN account_shares sh ON sh.account_id = s.account_id
+			WHERE sh.user_id = ?
+			  AND a.include_in_net_worth = TRUE
+			  AND (a.opened_at IS NULL OR s.as_of::date >= a.opened_at::date)
+			  AND (a.closed_at IS NULL OR s.as_of::date <  a.closed_at::date)
+			  AND s.as_of BETWEEN ? AND ?
+		)
+		SELECT as_of, currency, SUM(amount)::NUMERIC(19,4) AS amount
+		FROM cash
+		GROUP BY as_of, currency
+		ORDER BY as_of
+	`, userID, from, to, userID, from, to).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]models.ChartPoint)
+	for _, r := range rows {
+		out[r.Currency] = append(out[r.Currency], models.ChartPoint{Date: r.AsOf, Value: r.Amount})
+	}
+	return out, nil
+}
+
+// FetchExchangeRateSeries returns the stored rates in [from, to], starting with
+// the latest one before from so the opening rate can be carried forward.
+func (r *AnalyticsRepository) FetchExchangeRateSeries(ctx context.Context, tx *gorm.DB, fromCurrency, toCurrency string, from, to time.Time) ([]models.ChartPoint, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var entries []models.ExchangeRateHistory
+	err := db.Where("from_currency = ? AND to_currency = ? AND as_of < ?", fromCurrency, toCurrency, from).
+		Order("as_of DESC").
+		Limit(1).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var inRange []models.ExchangeRateHistory
+	err = db.Where("from_currency = ? AND to_currency = ? AND as_of >= ? AND as_of <= ?", fromCurrency, toCurrency, from, to).
+		Order("as_of ASC").
+		Find(&inRange).Error
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, inRange...)
+
+	points := make([]models.ChartPoint, len(entries))
+	for i, e := range entries {
+		points[i] = models.ChartPoint{Date: e.AsOf, Value: e.Rate}
+	}
+	return points, nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindBudgets(ctx context.Context, tx *gorm.DB, userID int64) ([]models.CategoryBudget, error)
+	FindBudgetByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.CategoryBudget, error)
+	InsertBudget(ctx context.Context, tx *gorm.DB, record *models.CategoryBudget) (int64, error)
+	UpdateBudget(ctx context.Context, tx *gorm.DB, record models.CategoryBudget) (int64, error)
+	DeleteBudget(ctx context.Context, tx *gorm.DB, id int64) error
+	UpsertBudgetMonth(ctx context.Context, tx *gorm.DB, record *models.CategoryBudgetMonth) error
+	DeleteBudgetMonth(ctx context.Context, tx *gorm.DB, budgetID int64, month time.Time) error
+}
+
+type BudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+var _ BudgetRepositoryInterface = (*BudgetRepository)(nil)
+
+func (r *BudgetRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *BudgetRepository) FindBudgets(ctx context.Context, tx *gorm.DB, userID int64) ([]models.CategoryBudget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.CategoryBudget
+	err := db.Model(&models.CategoryBudget{}).
+		Preload("Months", func(db *gorm.DB) *gorm.DB {
+			return db.Order("month ASC")
+		}).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *BudgetRepository) FindBudgetByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.CategoryBudget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.CategoryBudget
+	err := db.Preload("Months", func(db *gorm.DB) *gorm.DB {
+		return db.Order("month ASC")
+	}).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *BudgetRepository) InsertBudget(ctx context.Context, tx *gorm.DB, record *models.CategoryBudget) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("Months").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *BudgetRepository) UpdateBudget(ctx context.Context, tx *gorm.DB, record models.CategoryBudget) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Model(&models.CategoryBudget{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"amount":      record.Amount,
+			"rollover":    record.Rollover,
+			"start_month": record.StartMonth,
+			"is_active":   record.IsActive,
+			"updated_at":  time.Now().UTC(),
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	return record.ID, nil
+}
+
+func (r *BudgetRepository) DeleteBudget(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.CategoryBudget{}).Error
+}
+
+func (r *BudgetRepository) UpsertBudgetMonth(ctx context.Context, tx *gorm.DB, record *models.CategoryBudgetMonth) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "budget_id"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"amount": record.Amount, "updated_at": time.Now().UTC()}),
+	}).Create(record).Error
+}
+
+func (r *BudgetRepository) DeleteBudgetMonth(ctx context.Context, tx *gorm.DB, budgetID int64, month time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("budget_id = ? AND month = ?", budgetID, month).Delete(&models.CategoryBudgetMonth{}).Error
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DelegationRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindDelegationsByOwner(ctx context.Context, tx *gorm.DB, ownerID int64) ([]models.AccessDelegation, error)
+	FindActiveDelegationsForDelegate(ctx context.Context, tx *gorm.DB, delegateID int64) ([]models.AccessDelegation, error)
+	FindDelegationByID(ctx context.Context, tx *gorm.DB, id, ownerID int64) (models.AccessDelegation, error)
+	FindActiveDelegationByEmail(ctx context.Context, tx *gorm.DB, ownerID int64, email string) (models.AccessDelegation, error)
+	InsertDelegation(ctx context.Context, tx *gorm.DB, record *models.AccessDelegation) (int64, error)
+	UpdateDelegation(ctx context.Context, tx *gorm.DB, record *models.AccessDelegation) error
+	ReplaceScope(ctx context.Context, tx *gorm.DB, delegationID int64, modules []models.DelegationModule, accountIDs []int64) error
+	RevokeDelegation(ctx context.Context, tx *gorm.DB, id int64, at time.Time) error
+	LinkDelegate(ctx context.Context, tx *gorm.DB, id, delegateID int64) error
+	TouchLastAccessed(ctx context.Context, tx *gorm.DB, id int64, at time.Time) error
+}
+
+type DelegationRepository struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) *DelegationRepository {
+	return &DelegationRepository{db: db}
+}
+
+var _ DelegationRepositoryInterface = (*DelegationRepository)(nil)
+
+func (r *DelegationRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *DelegationRepository) FindDelegationsByOwner(ctx context.Context, tx *gorm.DB, ownerID int64) ([]models.AccessDelegation, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccessDelegation
+	err := db.Preload("Modules").
+		Preload("Accounts").
+		Where("owner_id = ?", ownerID).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *DelegationRepository) FindActiveDelegationsForDelegate(ctx context.Context, tx *gorm.DB, delegateID int64) ([]models.AccessDelegation, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccessDelegation
+	err := db.Preload("Owner").
+		Preload("Modules").
+		Preload("Accounts").
+		Where("delegate_id = ? AND revoked_at IS NULL AND expires_at > ?", delegateID, time.Now().UTC()).
+		Order("expires_at ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *DelegationRepository) FindDelegationByID(ctx context.Context, tx *gorm.DB, id, ownerID int64) (models.AccessDelegation, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.AccessDelegation
+	err := db.Preload("Modules").
+		Preload("Accounts").
+		Where("id = ? AND owner_id = ?", id, ownerID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *DelegationRepository) FindActiveDelegationByEmail(ctx context.Context, tx *gorm.DB, ownerID int64, email string) (models.AccessDelegation, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.AccessDelegation
+	err := db.Where("owner_id = ? AND LOWER(email) = LOWER(?) AND revoked_at IS NULL AND expires_at > ?", ownerID, email, time.Now().UTC()).
+		First(&record).Error
+	return record, err
+}
+
+func (r *DelegationRepository) InsertDelegation(ctx context.Context, tx *gorm.DB, record *models.AccessDelegation) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("Owner", "Modules", "Accounts").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *DelegationRepository) UpdateDelegation(ctx context.Context, tx *gorm.DB, record *models.AccessDelegation) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.AccessDelegation{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"label":      record.Label,
+			"expires_at": record.ExpiresAt,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *DelegationRepository) ReplaceScope(ctx context.Context, tx *gorm.DB, delegationID int64, modules []models.DelegationModule, accountIDs []int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Where("delegation_id = ?", delegationID).Delete(&models.AccessDelegationModule{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("delegation_id = ?", delegationID).Delete(&models.AccessDelegationAccount{}).Error; err != nil {
+		return err
+	}
+
+	if len(modules) > 0 {
+		rows := make([]models.AccessDelegationModule, len(modules))
+		for i, m := range modules {
+			rows[i] = models.AccessDelegationModule{DelegationID: delegationID, Module: m}
+		}
+		if err := db.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(accountIDs) > 0 {
+		rows := make([]models.AccessDelegationAccount, len(accountIDs))
+		for i, id := range accountIDs {
+			rows[i] = models.AccessDelegationAccount{DelegationID: delegationID, AccountID: id}
+		}
+		if err := db.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DelegationRepository) RevokeDelegation(ctx context.Context, tx *gorm.DB, id int64, at time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.AccessDelegation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": at,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *DelegationRepository) LinkDelegate(ctx context.Context, tx *gorm.DB, id, delegateID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.AccessDelegation{}).
+		Where("id = ?", id).
+		Update("delegate_id", delegateID).Error
+}
+
+func (r *DelegationRepository) TouchLastAccessed(ctx context.Context, tx *gorm.DB, id int64, at time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.AccessDelegation{}).
+		Where("id = ?", id).
+		UpdateColumn("last_accessed_at", at).Error
+}
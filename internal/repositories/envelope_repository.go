@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EnvelopeRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindEnvelopeBudget(ctx context.Context, tx *gorm.DB, userID int64) (models.EnvelopeBudget, error)
+	UpsertEnvelopeBudget(ctx context.Context, tx *gorm.DB, record *models.EnvelopeBudget) error
+	DeleteEnvelopeBudget(ctx context.Context, tx *gorm.DB, userID int64) error
+	FindEnvelopes(ctx context.Context, tx *gorm.DB, userID int64) ([]models.Envelope, error)
+	FindEnvelopeByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.Envelope, error)
+	InsertEnvelope(ctx context.Context, tx *gorm.DB, record *models.Envelope) (int64, error)
+	UpdateEnvelope(ctx context.Context, tx *gorm.DB, record models.Envelope) error
+	DeleteEnvelope(ctx context.Context, tx *gorm.DB, id int64) error
+	ReplaceEnvelopeCategories(ctx context.Context, tx *gorm.DB, userID, envelopeID int64, categoryIDs []int64) error
+	FindEnvelopeOfCategories(ctx context.Context, tx *gorm.DB, userID int64, categoryIDs []int64) ([]models.EnvelopeCategory, error)
+	FindMovements(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) ([]models.EnvelopeMovement, error)
+	InsertMovement(ctx context.Context, tx *gorm.DB, record *models.EnvelopeMovement) (int64, error)
+}
+
+type EnvelopeRepository struct {
+	db *gorm.DB
+}
+
+func NewEnvelopeRepository(db *gorm.DB) *EnvelopeRepository {
+	return &EnvelopeRepository{db: db}
+}
+
+var _ EnvelopeRepositoryInterface = (*EnvelopeRepository)(nil)
+
+func (r *EnvelopeRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *EnvelopeRepository) FindEnvelopeBudget(ctx context.Context, tx *gorm.DB, userID int64) (models.EnvelopeBudget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.EnvelopeBudget
+	err := db.Where("user_id = ?", userID).First(&record).Error
+	return record, err
+}
+
+func (r *EnvelopeRepository) UpsertEnvelopeBudget(ctx context.Context, tx *gorm.DB, record *models.EnvelopeBudget) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"start_month": record.StartMonth, "updated_at": time.Now().UTC()}),
+	}).Create(record).Error
+}
+
+func (r *EnvelopeRepository) DeleteEnvelopeBudget(ctx context.Context, tx *gorm.DB, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("user_id = ?", userID).Delete(&models.EnvelopeBudget{}).Error
+}
+
+func (r *EnvelopeRepository) FindEnvelopes(ctx context.Context, tx *gorm.DB, userID int64) ([]models.Envelope, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.Envelope
+	err := db.Model(&models.Envelope{}).
+		Preload("Categories").
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *EnvelopeRepository) FindEnvelopeByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.Envelope, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.Envelope
+	err := db.Preload("Categories").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *EnvelopeRepository) InsertEnvelope(ctx context.Context, tx *gorm.DB, record *models.Envelope) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("Categories").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *EnvelopeRepository) UpdateEnvelope(ctx context.Context, tx *gorm.DB, record models.Envelope) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.Envelope{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"name":       record.Name,
+			"is_active":  record.IsActive,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *EnvelopeRepository) DeleteEnvelope(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.Envelope{}).Error
+}
+
+func (r *EnvelopeRepository) ReplaceEnvelopeCategories(ctx context.Context, tx *gorm.DB, userID, envelopeID int64, categoryIDs []int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Where("envelope_id = ?", envelopeID).Delete(&models.EnvelopeCategory{}).Error; err != nil {
+		return err
+	}
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.EnvelopeCategory, 0, len(categoryIDs))
+	for _, id := range categoryIDs {
+		links = append(links, models.EnvelopeCategory{EnvelopeID: envelopeID, CategoryID: id, UserID: userID})
+	}
+	return db.Create(&links).Error
+}
+
+func (r *EnvelopeRepository) FindEnvelopeOfCategories(ctx context.Context, tx *gorm.DB, userID int64, categoryIDs []int64) ([]models.EnvelopeCategory, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.EnvelopeCategory
+	if len(categoryIDs) == 0 {
+		return records, nil
+	}
+	err := db.Where("user_id = ? AND category_id IN ?", userID, categoryIDs).Find(&records).Error
+	return records, err
+}
+
+func (r *EnvelopeRepository) FindMovements(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) ([]models.EnvelopeMovement, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.EnvelopeMovement
+	err := db.Where("user_id = ? AND month >= ? AND month <= ?", userID, from, to).
+		Order("month ASC, id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *EnvelopeRepository) InsertMovement(ctx context.Context, tx *gorm.DB, record *models.EnvelopeMovement) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
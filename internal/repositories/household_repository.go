@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HouseholdRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindHouseholdByID(ctx context.Context, tx *gorm.DB, id int64) (models.Household, error)
+	FindMembership(ctx context.Context, tx *gorm.DB, userID int64) (models.HouseholdMember, error)
+	InsertHousehold(ctx context.Context, tx *gorm.DB, record *models.Household) (int64, error)
+	UpdateHouseholdName(ctx context.Context, tx *gorm.DB, id int64, name string) error
+	DeleteHousehold(ctx context.Context, tx *gorm.DB, id int64) error
+	InsertMember(ctx context.Context, tx *gorm.DB, record *models.HouseholdMember) (int64, error)
+	DeleteMember(ctx context.Context, tx *gorm.DB, householdID, userID int64) error
+
+	FindShare(ctx context.Context, tx *gorm.DB, accountID, userID int64) (models.AccountShare, error)
+	FindSharesByAccountID(ctx context.Context, tx *gorm.DB, accountID int64) ([]models.AccountShare, error)
+	FindSharesForUser(ctx context.Context, tx *gorm.DB, userID int64) ([]models.AccountShare, error)
+	UpsertShare(ctx context.Context, tx *gorm.DB, record *models.AccountShare) error
+	DeleteShare(ctx context.Context, tx *gorm.DB, accountID, userID int64) error
+	DeleteSharesInvolvingUser(ctx context.Context, tx *gorm.DB, householdID, userID int64) error
+	UpdateAccountOwnership(ctx context.Context, tx *gorm.DB, accountID int64, pct *decimal.Decimal) error
+	FindSharedAccountOwner(ctx context.Context, tx *gorm.DB, accountID, userID int64, requireManage bool) (int64, error)
+	FindHouseholdInvitationsForEmail(ctx context.Context, tx *gorm.DB, email string) ([]models.Invitation, error)
+}
+
+type HouseholdRepository struct {
+	db *gorm.DB
+}
+
+func NewHouseholdRepository(db *gorm.DB) *HouseholdRepository {
+	return &HouseholdRepository{db: db}
+}
+
+var _ HouseholdRepositoryInterface = (*HouseholdRepository)(nil)
+
+func (r *HouseholdRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *HouseholdRepository) FindHouseholdByID(ctx context.Context, tx *gorm.DB, id int64) (models.Household, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.Household
+	err := db.
+		Preload("Members", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Members.User").
+		Where("id = ?", id).
+		First(&record).Error
+	return record, err
+}
+
+func (r *HouseholdRepository) FindMembership(ctx context.Context, tx *gorm.DB, userID int64) (models.HouseholdMember, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.HouseholdMember
+	err := db.Where("user_id = ?", userID).First(&record).Error
+	return record, err
+}
+
+func (r *HouseholdRepository) InsertHousehold(ctx context.Context, tx *gorm.DB, record *models.Household) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("Members").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *HouseholdRepository) UpdateHouseholdName(ctx context.Context, tx *gorm.DB, id int64, name string) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.Household{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"name":       name,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *HouseholdRepository) DeleteHousehold(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.Household{}).Error
+}
+
+func (r *HouseholdRepository) InsertMember(ctx context.Context, tx *gorm.DB, record *models.HouseholdMember) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("User").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *HouseholdRepository) DeleteMember(ctx context.Context, tx *gorm.DB, householdID, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("household_id = ? AND user_id = ?", householdID, userID).
+		Delete(&models.HouseholdMember{}).Error
+}
+
+func (r *HouseholdRepository) FindShare(ctx context.Context, tx *gorm.DB, accountID, userID int64) (models.AccountShare, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.AccountShare
+	err := db.Preload("Account").
+		Where("account_id = ? AND user_id = ?", accountID, userID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *HouseholdRepository) FindSharesByAccountID(ctx context.Context, tx *gorm.DB, accountID int64) ([]models.AccountShare, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccountShare
+	err := db.Where("account_id = ?", accountID).
+		Order("created_at ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *HouseholdRepository) FindSharesForUser(ctx context.Context, tx *gorm.DB, userID int64) ([]models.AccountShare, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccountShare
+	err := db.
+		Preload("Account").
+		Preload("Account.AccountType").
+		Preload("Account.Balance", func(db *gorm.DB) *gorm.DB {
+			return db.Where("as_of = (SELECT MAX(b2.as_of) FROM balances b2 WHERE b2.account_id = balances.account_id)")
+		}).
+		Joins("JOIN accounts ON accounts.id = account_shares.account_id").
+		Where("account_shares.user_id = ?", userID).
+		Where("accounts.closed_at IS NULL").
+		Order("accounts.name ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *HouseholdRepository) UpsertShare(ctx context.Context, tx *gorm.DB, record *models.AccountShare) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Omit("Account").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "ownership_percent", "updated_at"}),
+	}).Create(record).Error
+}
+
+func (r *HouseholdRepository) DeleteShare(ctx context.Context, tx *gorm.DB, accountID, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("account_id = ? AND user_id = ?", accountID, userID).
+		Delete(&models.AccountShare{}).Error
+}
+
+// DeleteSharesInvolvingUser drops every share a leaving member received, and every
+// share other members received on that member's accounts.
+func (r *HouseholdRepository) DeleteSharesInvolvingUser(ctx context.Context, tx *gorm.DB, householdID, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("household_id = ? AND (user_id = ? OR account_id IN (SELECT id FROM accounts WHERE user_id = ?))", householdID, userID, userID).
+		Delete(&models.AccountShare{}).Error
+}
+
+func (r *HouseholdRepository) UpdateAccountOwnership(ctx context.Context, tx *gorm.DB, accountID int64, pct *decimal.Decimal) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.Account{}).
+		Where("id = ?", accountID).
+		Update("ownership_percent", pct).Error
+}
+
+// FindSharedAccountOwner returns the owner of accountID when the account is shared with
+// userID, optionally requiring manage rights.
+func (r *HouseholdRepository) FindSharedAccountOwner(ctx context.Context, tx *gorm.DB, accountID, userID int64, requireManage bool) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	q := db.Table("account_shares").
+		Select("accounts.user_id").
+		Joins("JOIN accounts ON accounts.id = account_shares.account_id").
+		Where("account_shares.account_id = ? AND account_shares.user_id = ?", accountID, userID)
+	if requireManage {
+		q = q.Where("account_shares.permission = ?", models.AccountSharePermissionManage)
+	}
+
+	var ownerIDs []int64
+	if err := q.Limit(1).Pluck("accounts.user_id", &ownerIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(ownerIDs) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ownerIDs[0], nil
+}
+
+func (r *HouseholdRepository) FindHouseholdInvitationsForEmail(ctx context.Context, tx *gorm.DB, email string) ([]models.Invitation, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.Invitation
+	err := db.Where("email = ? AND household_id IS NOT NULL", email).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type InterestRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindConfigs(ctx context.Context, tx *gorm.DB, userID int64) ([]models.AccountInterestConfig, error)
+	FindActiveConfigs(ctx context.Context, tx *gorm.DB) ([]models.AccountInterestConfig, error)
+	FindConfigByAccountID(ctx context.Context, tx *gorm.DB, accountID, userID int64) (models.AccountInterestConfig, error)
+	InsertConfig(ctx context.Context, tx *gorm.DB, record *models.AccountInterestConfig) (int64, error)
+	UpdateConfig(ctx context.Context, tx *gorm.DB, record models.AccountInterestConfig) (int64, error)
+	UpdateAccrual(ctx context.Context, tx *gorm.DB, id int64, accrued decimal.Decimal, accruedThrough time.Time, lastPayoutAt *time.Time) error
+	DeleteConfig(ctx context.Context, tx *gorm.DB, id int64) error
+	ReplaceTiers(ctx context.Context, tx *gorm.DB, configID int64, tiers []models.AccountInterestTier) error
+	FindEndBalanceAsOf(ctx context.Context, tx *gorm.DB, accountID int64, asOf time.Time) (decimal.Decimal, error)
+}
+
+type InterestRepository struct {
+	db *gorm.DB
+}
+
+func NewInterestRepository(db *gorm.DB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+var _ InterestRepositoryInterface = (*InterestRepository)(nil)
+
+func (r *InterestRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *InterestRepository) FindConfigs(ctx context.Context, tx *gorm.DB, userID int64) ([]models.AccountInterestConfig, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccountInterestConfig
+	err := db.Model(&models.AccountInterestConfig{}).
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB { return db.Order("min_balance ASC") }).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *InterestRepository) FindActiveConfigs(ctx context.Context, tx *gorm.DB) ([]models.AccountInterestConfig, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.AccountInterestConfig
+	err := db.Model(&models.AccountInterestConfig{}).
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB { return db.Order("min_balance ASC") }).
+		Preload("Account").
+		Joins("JOIN accounts ON accounts.id = account_interest_configs.account_id").
+		Where("account_interest_configs.is_active = ?", true).
+		Where("accounts.is_active = ? AND accounts.closed_at IS NULL", true).
+		Order("account_interest_configs.id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *InterestRepository) FindConfigByAccountID(ctx context.Context, tx *gorm.DB, accountID, userID int64) (models.AccountInterestConfig, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.AccountInterestConfig
+	err := db.
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB { return db.Order("min_balance ASC") }).
+		Where("account_id = ? AND user_id = ?", accountID, userID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *InterestRepository) InsertConfig(ctx context.Context, tx *gorm.DB, record *models.AccountInterestConfig) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Omit("Tiers", "Account").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *InterestRepository) UpdateConfig(ctx context.Context, tx *gorm.DB, record models.AccountInterestConfig) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	err := db.Model(&models.AccountInterestConfig{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"annual_rate":         record.AnnualRate,
+			"compounding":         record.Compounding,
+			"payout_day_of_month": record.PayoutDayOfMonth,
+			"is_active":           record.IsActive,
+			"updated_at":          time.Now().UTC(),
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *InterestRepository) UpdateAccrual(ctx context.Context, tx *gorm.DB, id int64, accrued decimal.Decimal, accruedThrough time.Time, lastPayoutAt *time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	updates := map[string]interface{}{
+		"accrued_amount":  accrued,
+		"accrued_through": accruedThrough,
+		"updated_at":      time.Now().UTC(),
+	}
+	if lastPayoutAt != nil {
+		updates["last_payout_at"] = *lastPayoutAt
+	}
+
+	return db.Model(&models.AccountInterestConfig{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *InterestRepository) DeleteConfig(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.AccountInterestConfig{}).Error
+}
+
+func (r *InterestRepository) ReplaceTiers(ctx context.Context, tx *gorm.DB, configID int64, tiers []models.AccountInterestTier) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Where("config_id = ?", configID).Delete(&models.AccountInterestTier{}).Error; err != nil {
+		return err
+	}
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	for i := range tiers {
+		tiers[i].ConfigID = configID
+	}
+	return db.Create(&tiers).Error
+}
+
+// FindEndBalanceAsOf returns the end balance of the latest balance row on or before asOf,
+// or zero when the account has no history yet.
+func (r *InterestRepository) FindEndBalanceAsOf(ctx context.Context, tx *gorm.DB, accountID int64, asOf time.Time) (decimal.Decimal, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var balances []models.Balance
+	err := db.Model(&models.Balance{}).
+		Where("account_id = ? AND as_of <= ?", accountID, asOf).
+		Order("as_of DESC").
+		Limit(1).
+		Find(&balances).Error
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(balances) == 0 {
+		return decimal.Zero, nil
+	}
+	return balances[0].EndBalance, nil
+}
@@ -18,6 +18,7 @@ type InvestmentRepositoryInterface interface {
 	FindInvestmentAssets(ctx context.Context, tx *gorm.DB, userID int64, offset, limit int, sortField, sortOrder string, filters []utils.Filter, accountID *int64) ([]models.InvestmentAsset, error)
 	FindAllInvestmentAssets(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentAsset, error)
 	FindInvestmentAssetByID(ctx context.Context, tx *gorm.DB, ID, userID int64) (models.InvestmentAsset, error)
+	FindInvestmentAssetForPricing(ctx context.Context, tx *gorm.DB, ID int64) (models.InvestmentAsset, error)
 	FindAssetsByAccountID(ctx context.Context, tx *gorm.DB, accID, userID int64) ([]models.InvestmentAsset, error)
 	FindInvestmentTrades(ctx context.Context, tx *gorm.DB, userID int64, offset, limit int, sortField, sortOrder string, filters []utils.Filter, accountID *int64) ([]models.InvestmentTrade, error)
 	FindInvestmentTradeByID(ctx context.Context, tx *gorm.DB, ID, userID int64) (models.InvestmentTrade, error)
@@ -28,12 +29,13 @@ type InvestmentRepositoryInterface interface {
 	InsertInvestmentTrade(ctx context.Context, tx *gorm.DB, newRecord *models.InvestmentTrade) (int64, error)
 	UpdateAssetAfterTrade(ctx context.Context, tx *gorm.DB, assetID int64, quantity decimal.Decimal, pricePerUnit decimal.Decimal, currentPrice *decimal.Decimal, lastPriceUpdate *time.Time, TradeType models.TradeType, TradeValueAtBuy decimal.Decimal, tradeFee decimal.Decimal) error
 	FindTotalInvestmentValue(ctx context.Context, tx *gorm.DB, accountID, userID int64) (decimal.Decimal, error)
-	UpdateInvestmentAsset(ctx context.Context, tx *gorm.DB, record models.InvestmentAsset) (int64, error)
+	UpdateInvestmentAsset(ctx context.Context, tx *gorm.DB, id int64, fields map[string]interface{}) error
 	UpdateInvestmentTrade(ctx context.Context, tx *gorm.DB, record models.InvestmentTrade) (int64, error)
 	CorrectTradeValueAtBuy(ctx context.Context, tx *gorm.DB, tradeID int64, valueAtBuy decimal.Decimal) error
 	RecalculateAssetFromTrades(ctx context.Context, tx *gorm.DB, assetID, userID int64) error
 	DeleteInvestmentTrade(ctx context.Context, tx *gorm.DB, id int64) error
 	GetEarliestTradeDate(ctx context.Context, tx *gorm.DB, assetID, userID int64) (time.Time, error)
+	GetEarliestUserTradeDate(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) (time.Time, error)
 	FindAllTradesByAssetID(ctx context.Context, tx *gorm.DB, assetID, userID int64) ([]models.InvestmentTrade, error)
 	DeleteAllTradesForAsset(ctx context.Context, tx *gorm.DB, assetID, userID int64) error
 	DeleteInvestmentAsset(ctx context.Context, tx *gorm.DB, id int64) error
@@ -43,6 +45,9 @@ type InvestmentRepositoryInterface interface {
 	GetInvestmentTradesDateRange(ctx context.Context, tx *gorm.DB, accountID int64) (time.Time, time.Time, error)
 	UpsertAssetPrice(ctx context.Context, tx *gorm.DB, entries []models.AssetPriceHistory) error
 	GetPriceHistoryForAsset(ctx context.Context, tx *gorm.DB, assetID int64) ([]models.AssetPriceHistory, error)
+	FindAssetPriceOnOrBefore(ctx context.Context, tx *gorm.DB, assetID int64, asOf *time.Time) (models.AssetPriceHistory, error)
+	DeleteAssetPrice(ctx context.Context, tx *gorm.DB, assetID int64, asOf time.Time) error
+	FindAssetsByTickers(ctx context.Context, tx *gorm.DB, userID int64, tickers []string) ([]models.InvestmentAsset, error)
 	GetAssetIDsForAccount(ctx context.Context, tx *gorm.DB, accountID, userID int64) ([]int64, error)
 	UpsertExchangeRate(ctx context.Context, tx *gorm.DB, entry models.ExchangeRateHistory) error
 	GetCachedExchangeRate(ctx context.Context, tx *gorm.DB, from, to string, asOf time.Time) (decimal.Decimal, bool, error)
@@ -51,9 +56,14 @@ type InvestmentRepositoryInterface interface {
 	UpdateTradesPnLForAsset(ctx context.Context, tx *gorm.DB, assetID int64, price decimal.Decimal, investmentType models.InvestmentType, now time.Time) error
 	CreateInvestmentIncome(ctx context.Context, tx *gorm.DB, record *models.InvestmentIncome) (int64, error)
 	FindInvestmentIncomeByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.InvestmentIncome, error)
+	FindCouponBonds(ctx context.Context, tx *gorm.DB) ([]models.InvestmentAsset, error)
+	AdvanceLastCouponDate(ctx context.Context, tx *gorm.DB, assetID int64, date time.Time) error
+	FindInvestmentIncomeByReinvestTrade(ctx context.Context, tx *gorm.DB, tradeID, userID int64) (models.InvestmentIncome, error)
 	CountInvestmentIncome(ctx context.Context, tx *gorm.DB, assetID, userID int64) (int64, error)
 	GetInvestmentIncomeByAsset(ctx context.Context, tx *gorm.DB, assetID, userID int64, offset, limit int, sortField, sortOrder string) ([]models.InvestmentIncome, error)
+	FindInvestmentIncomeInRange(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) ([]models.InvestmentIncome, error)
 	DeleteInvestmentIncome(ctx context.Context, tx *gorm.DB, id, userID int64) error
+	PurgeImportedInvestmentIncome(ctx context.Context, tx *gorm.DB, importID, userID int64) (int64, error)
 	FindTaxBracketsByUser(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTaxBracket, error)
 	FindTaxBracketsByUserAndType(ctx context.Context, tx *gorm.DB, userID int64, investmentType models.InvestmentType) ([]models.InvestmentTaxBracket, error)
 	CountTaxBracketsByUserAndType(ctx context.Context, tx *gorm.DB, userID int64, investmentType models.InvestmentType) (int64, error)
@@ -62,6 +72,30 @@ type InvestmentRepositoryInterface interface {
 	DeleteTaxBracket(ctx context.Context, tx *gorm.DB, id, userID int64) error
 	FindTaxSettings(ctx context.Context, tx *gorm.DB, userID int64) (models.InvestmentTaxSettings, error)
 	UpsertTaxSettings(ctx context.Context, tx *gorm.DB, record models.InvestmentTaxSettings) error
+	FindTaxFreeBracketUserIDs(ctx context.Context, tx *gorm.DB) ([]int64, error)
+	SetTaxFreeAlertedAt(ctx context.Context, tx *gorm.DB, tradeIDs []int64, at *time.Time) error
+	FindCorporateActionsByAsset(ctx context.Context, tx *gorm.DB, assetID, userID int64) ([]models.CorporateAction, error)
+	InsertCorporateAction(ctx context.Context, tx *gorm.DB, record *models.CorporateAction) (int64, error)
+	DeleteCorporateAction(ctx context.Context, tx *gorm.DB, id int64) error
+	ApplySplitFactor(ctx context.Context, tx *gorm.DB, assetID, userID int64, before time.Time, factor decimal.Decimal) error
+	RevertSplitFactor(ctx context.Context, tx *gorm.DB, assetID, userID int64, before time.Time, factor decimal.Decimal) error
+	MoveBuyCostBasis(ctx context.Context, tx *gorm.DB, adjustments []models.InvestmentBasisAdjustment) error
+	RestoreBuyCostBasis(ctx context.Context, tx *gorm.DB, actionID int64) error
+	UpdateAssetIdentity(ctx context.Context, tx *gorm.DB, assetID int64, ticker, name string) error
+	InsertTradeLots(ctx context.Context, tx *gorm.DB, lots []models.InvestmentTradeLot) error
+	FindUserBenchmarks(ctx context.Context, tx *gorm.DB, userID int64) ([]models.UserBenchmark, error)
+	FindUserBenchmarkByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.UserBenchmark, error)
+	FindOrCreateBenchmark(ctx context.Context, tx *gorm.DB, ticker string, investmentType models.InvestmentType, currency string) (models.Benchmark, error)
+	InsertUserBenchmark(ctx context.Context, tx *gorm.DB, record *models.UserBenchmark) (int64, error)
+	DeleteUserBenchmark(ctx context.Context, tx *gorm.DB, id, userID int64) error
+	GetPriceHistoryForBenchmark(ctx context.Context, tx *gorm.DB, benchmarkID int64) ([]models.BenchmarkPriceHistory, error)
+	UpsertBenchmarkPrice(ctx context.Context, tx *gorm.DB, entries []models.BenchmarkPriceHistory) error
+	FindTransfers(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTransfer, error)
+	FindTransferByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.InvestmentTransfer, error)
+	InsertTransfer(ctx context.Context, tx *gorm.DB, record *models.InvestmentTransfer) (int64, error)
+	DeleteTransfer(ctx context.Context, tx *gorm.DB, id int64) error
+	CountTransfersForAsset(ctx context.Context, tx *gorm.DB, assetID int64) (int64, error)
+	CountSellTrades(ctx context.Context, tx *gorm.DB, assetIDs []int64) (int64, error)
 }
 
 type InvestmentRepository struct {
@@ -211,6 +245,18 @@ func (r *InvestmentRepository) FindInvestmentAssetByID(ctx context.Context, tx *
 	return record, q.Error
 }
 
+// FindInvestmentAssetForPricing loads an asset without its account, for the
+// background jobs that price it on no particular user's behalf.
+func (r *InvestmentRepository) FindInvestmentAssetForPricing(ctx context.Context, tx *gorm.DB, ID int64) (models.InvestmentAsset, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var record models.InvestmentAsset
+	err := db.WithContext(ctx).Where("id = ?", ID).First(&record).Error
+	return record, err
+}
+
 func (r *InvestmentRepository) FindAssetByTicker(ctx context.Context, tx *gorm.DB, ticker string, accID, userID int64) (models.InvestmentAsset, error) {
 	db := tx
 	if db == nil {
@@ -294,6 +340,8 @@ func (r *InvestmentRepository) FindInvestmentTradeByID(ctx context.Context, tx *
 	var record models.InvestmentTrade
 	q := db.
 		Preload("Asset").
+		Preload("Lots").
+		Preload("BasisAdjustments").
 		Where("id = ? AND user_id = ?", ID, userID)
 
 	q = q.First(&record)
@@ -494,7 +542,7 @@ func (r *InvestmentRepository) FindTotalInvestmentValue(ctx context.Context, tx
 	return total, nil
 }
 
-func (r *InvestmentRepository) UpdateInvestmentAsset(ctx context.Context, tx *gorm.DB, record models.InvestmentAsset) (int64, error) {
+func (r *InvestmentRepository) UpdateInvestmentAsset(ctx context.Context, tx *gorm.DB, id int64, fields map[string]interface{}) error {
 
 	db := tx
 	if db == nil {
@@ -502,16 +550,10 @@ func (r *InvestmentRepository) UpdateInvestmentAsset(ctx context.Context, tx *go
 	}
 	db = db.WithContext(ctx)
 
-	if err := db.Model(models.InvestmentAsset{}).
-		Where("id = ?", record.ID).
-		Updates(map[string]interface{}{
-			"name":       record.Name,
-			"updated_at": time.Now().UTC(),
-		}).Error; err != nil {
-		return 0, err
-	}
-
-	return record.ID, nil
+	fields["updated_at"] = time.Now().UTC()
+	return db.Model(models.InvestmentAsset{}).
+		Where("id = ?", id).
+		Updates(fields).Error
 }
 
 func (r *InvestmentRepository) UpdateInvestmentTrade(ctx context.Context, tx *gorm.DB, record models.InvestmentTrade) (int64, error) {
@@ -568,33 +610,36 @@ func (r *InvestmentRepository) RecalculateAssetFromTrades(ctx context.Context, t
 
 	// Get all trades for this asset
 	var trades []models.InvestmentTrade
-	if err := db.Where("asset_id = ? AND user_id = ?", assetID, userID).
+	if err := db.Preload("Lots").
+		Preload("BasisAdjustments").
+		Where("asset_id = ? AND user_id = ?", assetID, userID).
 		Order("txn_date ASC, id ASC").
 		Find(&trades).Error; err != nil {
 		return err
 	}
 
-	// Start fresh
-	totalQuantity := decimal.Zero
-	totalValueAtBuy := decimal.Zero
-	totalFees := decimal.Zero
+	var settings models.InvestmentTaxSettings
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&settings).Error; err != nil {
+		return err
+	}
 
+	// Net traded quantity, independent of which lots the sells were matched against
+	totalQuantity := decimal.Zero
 	for _, txn := range trades {
 		if txn.TradeType == models.InvestmentBuy {
 			totalQuantity = totalQuantity.Add(txn.Quantity)
-			totalValueAtBuy = totalValueAtBuy.Add(txn.ValueAtBuy)
-			totalFees = totalFees.Add(txn.Fee)
 		} else {
-			// Sell: reduce proportionally
 			totalQuantity = totalQuantity.Sub(txn.Quantity)
-			if totalQuantity.GreaterThan(decimal.Zero) {
-				soldProportion := txn.Quantity.Div(totalQuantity.Add(txn.Quantity))
-				totalValueAtBuy = totalValueAtBuy.Mul(decimal.NewFromInt(1).Sub(soldProportion))
-				totalFees = totalFees.Mul(decimal.NewFromInt(1).Sub(soldProportion))
-			} else {
-				totalValueAtBuy = decimal.Zero
-				totalFees = decimal.Zero
-			}
+		}
+	}
+
+	// Remaining cost basis follows the asset's cost-basis method
+	totalValueAtBuy := decimal.Zero
+	totalFees := decimal.Zero
+	if totalQuantity.GreaterThan(decimal.Zero) {
+		for _, lot := range utils.BuildLots(trades, 0, utils.ResolveCostBasisMethod(asset, settings)) {
+			totalValueAtBuy = totalValueAtBuy.Add(lot.ValueAtBuy)
+			totalFees = totalFees.Add(lot.Fee)
 		}
 	}
 
@@ -711,6 +756,29 @@ func (r *InvestmentRepository) GetEarliestTradeDate(ctx context.Context, tx *gor
 	return txn.TxnDate, nil
 }
 
+// GetEarliestUserTradeDate is the first trade of a user, optionally within one account.
+func (r *InvestmentRepository) GetEarliestUserTradeDate(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64) (time.Time, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	q := db.Model(&models.InvestmentTrade{}).
+		Joins("JOIN investment_assets ia ON ia.id = investment_trades.asset_id").
+		Where("investment_trades.user_id = ?", userID)
+	if accountID != nil {
+		q = q.Where("ia.account_id = ?", *accountID)
+	}
+
+	var txn models.InvestmentTrade
+	if err := q.Order("investment_trades.txn_date ASC").First(&txn).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	return txn.TxnDate, nil
+}
+
 func (r *InvestmentRepository) FindAllTradesByAssetID(ctx context.Context, tx *gorm.DB, assetID, userID int64) ([]models.InvestmentTrade, error) {
 	db := tx
 	if db == nil {
@@ -719,7 +787,9 @@ func (r *InvestmentRepository) FindAllTradesByAssetID(ctx context.Context, tx *g
 	db = db.WithContext(ctx)
 
 	var trades []models.InvestmentTrade
-	err := db.Where("asset_id = ? AND user_id = ?", assetID, userID).
+	err := db.Preload("Lots").
+		Preload("BasisAdjustments").
+		Where("asset_id = ? AND user_id = ?", assetID, userID).
 		Order("txn_date ASC, id ASC").
 		Find(&trades).Error
 
@@ -735,8 +805,10 @@ func (r *InvestmentRepository) FindAllTradesByUserID(ctx context.Context, tx *go
 
 	var trades []models.InvestmentTrade
 	err := db.Preload("Asset.Account").
+		Preload("Lots").
+		Preload("BasisAdjustments").
 		Where("user_id = ?", userID).
-		Order("txn_date ASC").
+		Order("txn_date ASC, id ASC").
 		Find(&trades).Error
 
 	return trades, err
@@ -836,6 +908,53 @@ func (r *InvestmentRepository) GetPriceHistoryForAsset(ctx context.Context, tx *
 	return prices, err
 }
 
+// FindAssetPriceOnOrBefore returns the asset's latest price dated on or before
+// asOf, or its latest price overall when asOf is nil.
+func (r *InvestmentRepository) FindAssetPriceOnOrBefore(ctx context.Context, tx *gorm.DB, assetID int64, asOf *time.Time) (models.AssetPriceHistory, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	q := db.WithContext(ctx).Where("asset_id = ?", assetID)
+	if asOf != nil {
+		q = q.Where("as_of <= ?", asOf.UTC().Truncate(24*time.Hour))
+	}
+	var record models.AssetPriceHistory
+	err := q.Order("as_of DESC").First(&record).Error
+	return record, err
+}
+
+func (r *InvestmentRepository) DeleteAssetPrice(ctx context.Context, tx *gorm.DB, assetID int64, asOf time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	res := db.WithContext(ctx).
+		Where("asset_id = ? AND as_of = ?", assetID, asOf.UTC().Truncate(24*time.Hour)).
+		Delete(&models.AssetPriceHistory{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindAssetsByTickers returns the user's assets whose ticker is one of tickers.
+func (r *InvestmentRepository) FindAssetsByTickers(ctx context.Context, tx *gorm.DB, userID int64, tickers []string) ([]models.InvestmentAsset, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var records []models.InvestmentAsset
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND ticker IN ?", userID, tickers).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
+
 func (r *InvestmentRepository) UpsertAssetPrice(ctx context.Context, tx *gorm.DB, entries []models.AssetPriceHistory) error {
 	if len(entries) == 0 {
 		return nil
@@ -903,6 +1022,48 @@ func (r *InvestmentRepository) FindInvestmentIncomeByID(ctx context.Context, tx
 	return record, err
 }
 
+// FindCouponBonds returns the held coupon-paying bonds in active accounts.
+func (r *InvestmentRepository) FindCouponBonds(ctx context.Context, tx *gorm.DB) ([]models.InvestmentAsset, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var records []models.InvestmentAsset
+	err := db.WithContext(ctx).
+		Joins("JOIN accounts ON accounts.id = investment_assets.account_id").
+		Where("investment_assets.investment_type = ?", models.InvestmentBond).
+		Where("investment_assets.coupon_frequency > 0 AND investment_assets.quantity > 0").
+		Where("accounts.is_active = ? AND accounts.closed_at IS NULL", true).
+		Order("investment_assets.id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// AdvanceLastCouponDate moves the asset's last coupon date forward to date; it
+// never moves back, so deleting a coupon doesn't get it booked again.
+func (r *InvestmentRepository) AdvanceLastCouponDate(ctx context.Context, tx *gorm.DB, assetID int64, date time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Model(&models.InvestmentAsset{}).
+		Where("id = ?", assetID).
+		Update("last_coupon_date", gorm.Expr("GREATEST(last_coupon_date, ?::date)", date)).Error
+}
+
+func (r *InvestmentRepository) FindInvestmentIncomeByReinvestTrade(ctx context.Context, tx *gorm.DB, tradeID, userID int64) (models.InvestmentIncome, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var record models.InvestmentIncome
+	err := db.WithContext(ctx).
+		Where("reinvest_trade_id = ? AND user_id = ?", tradeID, userID).
+		First(&record).Error
+	return record, err
+}
+
 func (r *InvestmentRepository) CreateInvestmentIncome(ctx context.Context, tx *gorm.DB, record *models.InvestmentIncome) (int64, error) {
 	db := tx
 	if db == nil {
@@ -942,6 +1103,20 @@ func (r *InvestmentRepository) GetInvestmentIncomeByAsset(ctx context.Context, t
 	return records, err
 }
 
+func (r *InvestmentRepository) FindInvestmentIncomeInRange(ctx context.Context, tx *gorm.DB, userID int64, from, to time.Time) ([]models.InvestmentIncome, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var records []models.InvestmentIncome
+	err := db.WithContext(ctx).
+		Preload("Asset").
+		Where("user_id = ? AND txn_date >= ? AND txn_date < ?", userID, from, to).
+		Order("txn_date ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
 func (r *InvestmentRepository) DeleteInvestmentIncome(ctx context.Context, tx *gorm.DB, id, userID int64) error {
 	db := tx
 	if db == nil {
@@ -952,6 +1127,17 @@ func (r *InvestmentRepository) DeleteInvestmentIncome(ctx context.Context, tx *g
 		Delete(&models.InvestmentIncome{}).Error
 }
 
+func (r *InvestmentRepository) PurgeImportedInvestmentIncome(ctx context.Context, tx *gorm.DB, importID, userID int64) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	res := db.WithContext(ctx).
+		Where("import_id = ? AND user_id = ?", importID, userID).
+		Delete(&models.InvestmentIncome{})
+	return res.RowsAffected, res.Error
+}
+
 func (r *InvestmentRepository) FindTaxBracketsByUser(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTaxBracket, error) {
 	db := tx
 	if db == nil {
@@ -1037,7 +1223,7 @@ func (r *InvestmentRepository) FindTaxSettings(ctx context.Context, tx *gorm.DB,
 		Where("user_id = ?", userID).
 		First(&record).Error
 	if err == gorm.ErrRecordNotFound {
-		return models.InvestmentTaxSettings{UserID: userID}, nil
+		return models.InvestmentTaxSettings{UserID: userID, TaxFreeAlertMinValue: models.DefaultTaxFreeAlertMinValue}, nil
 	}
 	return record, err
 }
@@ -1050,7 +1236,419 @@ func (r *InvestmentRepository) UpsertTaxSettings(ctx context.Context, tx *gorm.D
 	return db.WithContext(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "user_id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"loss_offsetting_enabled", "updated_at"}),
+			DoUpdates: clause.AssignmentColumns([]string{"loss_offsetting_enabled", "cost_basis_method", "tax_free_alert_min_value", "updated_at"}),
 		}).
 		Create(&record).Error
 }
+
+// FindTaxFreeBracketUserIDs returns the users with at least one tax-free bracket,
+// the only ones whose lots can become tax-free.
+func (r *InvestmentRepository) FindTaxFreeBracketUserIDs(ctx context.Context, tx *gorm.DB) ([]int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var ids []int64
+	err := db.WithContext(ctx).
+		Model(&models.InvestmentTaxBracket{}).
+		Where("taxable_percent = 0").
+		Distinct().
+		Order("user_id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *InvestmentRepository) SetTaxFreeAlertedAt(ctx context.Context, tx *gorm.DB, tradeIDs []int64, at *time.Time) error {
+	if len(tradeIDs) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).
+		Model(&models.InvestmentTrade{}).
+		Where("id IN ?", tradeIDs).
+		Update("tax_free_alerted_at", at).Error
+}
+
+func (r *InvestmentRepository) FindCorporateActionsByAsset(ctx context.Context, tx *gorm.DB, assetID, userID int64) ([]models.CorporateAction, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.CorporateAction
+	err := db.Where("asset_id = ? AND user_id = ?", assetID, userID).
+		Order("effective_date DESC, id DESC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *InvestmentRepository) InsertCorporateAction(ctx context.Context, tx *gorm.DB, record *models.CorporateAction) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *InvestmentRepository) DeleteCorporateAction(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Delete(&models.CorporateAction{}, id).Error
+}
+
+// ApplySplitFactor restates everything dated before the effective date in post-action
+// units: trade and staking quantities are multiplied by factor, per-unit prices are
+// divided by it. Trade values are left as-is, so cost basis and realized P&L don't move.
+func (r *InvestmentRepository) ApplySplitFactor(ctx context.Context, tx *gorm.DB, assetID, userID int64, before time.Time, factor decimal.Decimal) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+	now := time.Now().UTC()
+
+	if err := db.Exec(`
+		UPDATE investment_trades
+		SET quantity = quantity * ?, price_per_unit = price_per_unit / ?, updated_at = ?
+		WHERE asset_id = ? AND user_id = ? AND txn_date < ?
+	`, factor, factor, now, assetID, userID, before).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		UPDATE investment_income
+		SET quantity = quantity * ?, updated_at = ?
+		WHERE asset_id = ? AND user_id = ? AND txn_date < ? AND quantity IS NOT NULL
+	`, factor, now, assetID, userID, before).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		UPDATE asset_price_history
+		SET price = price / ?
+		WHERE asset_id = ? AND as_of < ?
+	`, factor, assetID, before).Error; err != nil {
+		return err
+	}
+
+	// A price synced before the action is still quoted in old units
+	return db.Exec(`
+		UPDATE investment_assets
+		SET current_price = current_price / ?, updated_at = ?
+		WHERE id = ? AND user_id = ? AND current_price IS NOT NULL
+		  AND (last_price_update IS NULL OR last_price_update < ?)
+	`, factor, now, assetID, userID, before).Error
+}
+
+// RevertSplitFactor undoes ApplySplitFactor for the same effective date and factor,
+// dividing and multiplying by the original factor so whole-number splits come back exactly.
+func (r *InvestmentRepository) RevertSplitFactor(ctx context.Context, tx *gorm.DB, assetID, userID int64, before time.Time, factor decimal.Decimal) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+	now := time.Now().UTC()
+
+	if err := db.Exec(`
+		UPDATE investment_trades
+		SET quantity = quantity / ?, price_per_unit = price_per_unit * ?, updated_at = ?
+		WHERE asset_id = ? AND user_id = ? AND txn_date < ?
+	`, factor, factor, now, assetID, userID, before).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		UPDATE investment_income
+		SET quantity = quantity / ?, updated_at = ?
+		WHERE asset_id = ? AND user_id = ? AND txn_date < ? AND quantity IS NOT NULL
+	`, factor, now, assetID, userID, before).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		UPDATE asset_price_history
+		SET price = price * ?
+		WHERE asset_id = ? AND as_of < ?
+	`, factor, assetID, before).Error; err != nil {
+		return err
+	}
+
+	// Only a price that ApplySplitFactor rescaled goes back; a sync since quotes the real units
+	return db.Exec(`
+		UPDATE investment_assets
+		SET current_price = current_price * ?, updated_at = ?
+		WHERE id = ? AND user_id = ? AND current_price IS NOT NULL
+		  AND (last_price_update IS NULL OR last_price_update < ?)
+	`, factor, now, assetID, userID, before).Error
+}
+
+// MoveBuyCostBasis takes each adjustment's basis and fees off its buy and records
+// the adjustment, so units sold before its effective date keep their basis.
+func (r *InvestmentRepository) MoveBuyCostBasis(ctx context.Context, tx *gorm.DB, adjustments []models.InvestmentBasisAdjustment) error {
+	if len(adjustments) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	now := time.Now().UTC()
+	for _, a := range adjustments {
+		if err := db.Exec(`
+			UPDATE investment_trades
+			SET value_at_buy = value_at_buy - ?,
+			    fee = fee - ?,
+			    price_per_unit = CASE WHEN quantity > 0 THEN (value_at_buy - ?) / quantity ELSE price_per_unit END,
+			    updated_at = ?
+			WHERE id = ? AND trade_type = 'buy'
+		`, a.ValueMoved, a.FeeMoved, a.ValueMoved, now, a.TradeID).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.Create(&adjustments).Error
+}
+
+// RestoreBuyCostBasis puts the basis and fees a corporate action moved back onto
+// its buys. The adjustment rows go with the action itself.
+func (r *InvestmentRepository) RestoreBuyCostBasis(ctx context.Context, tx *gorm.DB, actionID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Exec(`
+		UPDATE investment_trades t
+		SET value_at_buy = t.value_at_buy + a.value_moved,
+		    fee = t.fee + a.fee_moved,
+		    price_per_unit = CASE WHEN t.quantity > 0 THEN (t.value_at_buy + a.value_moved) / t.quantity ELSE t.price_per_unit END,
+		    updated_at = ?
+		FROM investment_basis_adjustments a
+		WHERE a.trade_id = t.id AND a.corporate_action_id = ?
+	`, time.Now().UTC(), actionID).Error
+}
+
+func (r *InvestmentRepository) UpdateAssetIdentity(ctx context.Context, tx *gorm.DB, assetID int64, ticker, name string) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.InvestmentAsset{}).
+		Where("id = ?", assetID).
+		Updates(map[string]interface{}{
+			"ticker":     ticker,
+			"name":       name,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+func (r *InvestmentRepository) InsertTradeLots(ctx context.Context, tx *gorm.DB, lots []models.InvestmentTradeLot) error {
+	if len(lots) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Create(&lots).Error
+}
+
+func (r *InvestmentRepository) FindUserBenchmarks(ctx context.Context, tx *gorm.DB, userID int64) ([]models.UserBenchmark, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var records []models.UserBenchmark
+	err := db.WithContext(ctx).
+		Preload("Benchmark").
+		Where("user_id = ?", userID).
+		Order("account_id ASC NULLS FIRST, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *InvestmentRepository) FindUserBenchmarkByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.UserBenchmark, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var record models.UserBenchmark
+	err := db.WithContext(ctx).
+		Preload("Benchmark").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&record).Error
+	return record, err
+}
+
+// FindOrCreateBenchmark returns the shared benchmark for a ticker, creating it on first use.
+func (r *InvestmentRepository) FindOrCreateBenchmark(ctx context.Context, tx *gorm.DB, ticker string, investmentType models.InvestmentType, currency string) (models.Benchmark, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	record := models.Benchmark{Ticker: ticker, InvestmentType: investmentType, Currency: currency}
+	err := db.WithContext(ctx).
+		Where("ticker = ? AND investment_type = ?", ticker, investmentType).
+		FirstOrCreate(&record).Error
+	return record, err
+}
+
+func (r *InvestmentRepository) InsertUserBenchmark(ctx context.Context, tx *gorm.DB, record *models.UserBenchmark) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	if err := db.WithContext(ctx).Omit("Benchmark").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *InvestmentRepository) DeleteUserBenchmark(ctx context.Context, tx *gorm.DB, id, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&models.UserBenchmark{}).Error
+}
+
+func (r *InvestmentRepository) GetPriceHistoryForBenchmark(ctx context.Context, tx *gorm.DB, benchmarkID int64) ([]models.BenchmarkPriceHistory, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var records []models.BenchmarkPriceHistory
+	err := db.WithContext(ctx).
+		Where("benchmark_id = ?", benchmarkID).
+		Order("as_of ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *InvestmentRepository) UpsertBenchmarkPrice(ctx context.Context, tx *gorm.DB, entries []models.BenchmarkPriceHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "benchmark_id"}, {Name: "as_of"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "currency"}),
+	}).Create(&entries).Error
+}
+
+func (r *InvestmentRepository) FindTransfers(ctx context.Context, tx *gorm.DB, userID int64) ([]models.InvestmentTransfer, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var records []models.InvestmentTransfer
+	err := db.WithContext(ctx).
+		Preload("FromAsset.Account").
+		Preload("ToAsset.Account").
+		Where("user_id = ?", userID).
+		Order("txn_date DESC, id DESC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *InvestmentRepository) FindTransferByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.InvestmentTransfer, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var record models.InvestmentTransfer
+	err := db.WithContext(ctx).
+		Preload("FromAsset.Account").
+		Preload("ToAsset.Account").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&record).Error
+	return record, err
+}
+
+func (r *InvestmentRepository) InsertTransfer(ctx context.Context, tx *gorm.DB, record *models.InvestmentTransfer) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	if err := db.WithContext(ctx).Omit("FromAsset", "ToAsset").Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+// DeleteTransfer removes the transfer together with both of its legs.
+func (r *InvestmentRepository) DeleteTransfer(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	return db.WithContext(ctx).Where("id = ?", id).Delete(&models.InvestmentTransfer{}).Error
+}
+
+func (r *InvestmentRepository) CountTransfersForAsset(ctx context.Context, tx *gorm.DB, assetID int64) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var count int64
+	err := db.WithContext(ctx).
+		Model(&models.InvestmentTransfer{}).
+		Where("from_asset_id = ? OR to_asset_id = ?", assetID, assetID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *InvestmentRepository) CountSellTrades(ctx context.Context, tx *gorm.DB, assetIDs []int64) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+
+	var count int64
+	if len(assetIDs) == 0 {
+		return count, nil
+	}
+	err := db.WithContext(ctx).
+		Model(&models.InvestmentTrade{}).
+		Where("asset_id IN ? AND trade_type = ?", assetIDs, models.InvestmentSell).
+		Count(&count).Error
+	return count, err
+}
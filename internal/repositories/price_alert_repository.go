@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PriceAlertRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindRules(ctx context.Context, tx *gorm.DB, userID int64) ([]models.PriceAlertRule, error)
+	FindRuleByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.PriceAlertRule, error)
+	FindActiveRules(ctx context.Context, tx *gorm.DB) ([]models.PriceAlertRule, error)
+	InsertRule(ctx context.Context, tx *gorm.DB, record *models.PriceAlertRule) (int64, error)
+	UpdateRule(ctx context.Context, tx *gorm.DB, record models.PriceAlertRule) (int64, error)
+	DeleteRule(ctx context.Context, tx *gorm.DB, id int64) error
+	SetLastTriggeredAt(ctx context.Context, tx *gorm.DB, ids []int64, at time.Time) error
+	SetLastHit(ctx context.Context, tx *gorm.DB, ids []int64, hit bool) error
+	FindAccountValueOnOrBefore(ctx context.Context, tx *gorm.DB, accountID int64, asOf *time.Time) (decimal.Decimal, bool, error)
+}
+
+type PriceAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceAlertRepository(db *gorm.DB) *PriceAlertRepository {
+	return &PriceAlertRepository{db: db}
+}
+
+var _ PriceAlertRepositoryInterface = (*PriceAlertRepository)(nil)
+
+func (r *PriceAlertRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *PriceAlertRepository) FindRules(ctx context.Context, tx *gorm.DB, userID int64) ([]models.PriceAlertRule, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.PriceAlertRule
+	err := db.Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *PriceAlertRepository) FindRuleByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.PriceAlertRule, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.PriceAlertRule
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&record).Error
+	return record, err
+}
+
+// FindActiveRules lists every user's active rules, grouped by user.
+func (r *PriceAlertRepository) FindActiveRules(ctx context.Context, tx *gorm.DB) ([]models.PriceAlertRule, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.PriceAlertRule
+	err := db.Where("is_active = ?", true).
+		Order("user_id ASC, id ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *PriceAlertRepository) InsertRule(ctx context.Context, tx *gorm.DB, record *models.PriceAlertRule) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *PriceAlertRepository) UpdateRule(ctx context.Context, tx *gorm.DB, record models.PriceAlertRule) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Model(&models.PriceAlertRule{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"asset_id":          record.AssetID,
+			"account_id":        record.AccountID,
+			"kind":              record.Kind,
+			"direction":         record.Direction,
+			"threshold":         record.Threshold,
+			"period_days":       record.PeriodDays,
+			"cooldown_hours":    record.CooldownHours,
+			"is_active":         record.IsActive,
+			"last_triggered_at": record.LastTriggeredAt,
+			"last_hit":          record.LastHit,
+			"updated_at":        time.Now().UTC(),
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	return record.ID, nil
+}
+
+func (r *PriceAlertRepository) DeleteRule(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.PriceAlertRule{}).Error
+}
+
+func (r *PriceAlertRepository) SetLastTriggeredAt(ctx context.Context, tx *gorm.DB, ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.PriceAlertRule{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"last_triggered_at": at,
+			"updated_at":        time.Now().UTC(),
+		}).Error
+}
+
+// SetLastHit marks rules as fired and still on the alert side, or clears them once
+// their figures are seen back on the other side.
+func (r *PriceAlertRepository) SetLastHit(ctx context.Context, tx *gorm.DB, ids []int64, hit bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.PriceAlertRule{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"last_hit":   hit,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+// FindAccountValueOnOrBefore returns the account's cash plus holdings from its
+// latest snapshot dated on or before asOf, or its latest snapshot when asOf is nil.
+func (r *PriceAlertRepository) FindAccountValueOnOrBefore(ctx context.Context, tx *gorm.DB, accountID int64, asOf *time.Time) (decimal.Decimal, bool, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	q := db.Model(&models.AccountDailySnapshot{}).Where("account_id = ?", accountID)
+	if asOf != nil {
+		q = q.Where("as_of <= ?", asOf.UTC().Truncate(24*time.Hour))
+	}
+
+	var rows []decimal.Decimal
+	err := q.Order("as_of DESC").
+		Limit(1).
+		Pluck("end_balance + market_value", &rows).Error
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	if len(rows) == 0 {
+		return decimal.Zero, false, nil
+	}
+
+	return rows[0], true, nil
+}
@@ -22,12 +22,33 @@ type SavingsRepositoryInterface interface {
 
 	GetUncategorizedBalance(ctx context.Context, tx *gorm.DB, accountID, userID int64) (decimal.Decimal, error)
 	HasContributionForMonth(ctx context.Context, tx *gorm.DB, goalID int64, month time.Time) (bool, error)
-	CountContributions(ctx context.Context, tx *gorm.DB, goalID int64) (int64, error)
+	CountContributions(ctx context.Context, tx *gorm.DB, goalID int64, cycle *int) (int64, error)
 	FindContributions(ctx context.Context, tx *gorm.DB, goalID int64) ([]models.SavingContribution, error)
-	FindContributionsPaginated(ctx context.Context, tx *gorm.DB, goalID int64, offset, limit int, sortField, sortOrder string) ([]models.SavingContribution, error)
+	FindContributionsPaginated(ctx context.Context, tx *gorm.DB, goalID int64, cycle *int, offset, limit int, sortField, sortOrder string) ([]models.SavingContribution, error)
 	FindContributionByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.SavingContribution, error)
+	FindContributionByTransferID(ctx context.Context, tx *gorm.DB, transferID, userID int64) (models.SavingContribution, error)
+	FindContributionTransfer(ctx context.Context, tx *gorm.DB, transferID, userID int64) (models.Transfer, error)
+	SumTransferBackedContributions(ctx context.Context, tx *gorm.DB, userID int64) (map[int64]decimal.Decimal, error)
 	InsertContribution(ctx context.Context, tx *gorm.DB, record *models.SavingContribution) (int64, error)
 	DeleteContribution(ctx context.Context, tx *gorm.DB, id int64) error
+	FindAutoContributionsForMonth(ctx context.Context, tx *gorm.DB, userID int64, month time.Time) ([]models.SavingContribution, error)
+
+	FindBudget(ctx context.Context, tx *gorm.DB, userID int64) (models.SavingsBudget, error)
+	FindDueBudgets(ctx context.Context, tx *gorm.DB, dayOfMonth int) ([]models.SavingsBudget, error)
+	UpsertBudget(ctx context.Context, tx *gorm.DB, record *models.SavingsBudget) (int64, error)
+	DeleteBudget(ctx context.Context, tx *gorm.DB, userID int64) error
+	SumIncomeForMonth(ctx context.Context, tx *gorm.DB, userID int64, month time.Time) (decimal.Decimal, error)
+
+	FindCycles(ctx context.Context, tx *gorm.DB, goalID int64) ([]models.SavingGoalCycle, error)
+	FindCycleByTransactionID(ctx context.Context, tx *gorm.DB, transactionID, userID int64) (models.SavingGoalCycle, error)
+	InsertCycle(ctx context.Context, tx *gorm.DB, record *models.SavingGoalCycle) (int64, error)
+	AdvanceCycle(ctx context.Context, tx *gorm.DB, record models.SavingGoal) error
+
+	FindMilestones(ctx context.Context, tx *gorm.DB, goalID int64) ([]models.SavingGoalMilestone, error)
+	ReplaceMilestones(ctx context.Context, tx *gorm.DB, goalID int64, records []models.SavingGoalMilestone) error
+	MarkMilestonesReached(ctx context.Context, tx *gorm.DB, ids []int64, cycle int, at time.Time) error
+	FindActiveGoalsWithTargetDate(ctx context.Context, tx *gorm.DB) ([]models.SavingGoal, error)
+	SetLateAlertedAt(ctx context.Context, tx *gorm.DB, ids []int64, at *time.Time) error
 }
 
 type SavingsRepository struct {
@@ -77,6 +98,8 @@ func (r *SavingsRepository) FindActiveGoalsWithAllocation(ctx context.Context, t
 			"status = ? AND monthly_allocation IS NOT NULL AND monthly_allocation > 0 AND (fund_day_of_month IS NULL OR fund_day_of_month <= ?)",
 			models.SavingGoalStatusActive, dayOfMonth,
 		).
+		// users with an active savings budget are funded from the budget instead
+		Where("user_id NOT IN (SELECT user_id FROM savings_budgets WHERE is_active = true)").
 		Find(&records).Error
 	if err != nil {
 		return nil, err
@@ -141,6 +164,9 @@ func (r *SavingsRepository) UpdateGoal(ctx context.Context, tx *gorm.DB, record
 			"priority":           record.Priority,
 			"monthly_allocation": record.MonthlyAllocation,
 			"fund_day_of_month":  record.FundDayOfMonth,
+			"funding_account_id": record.FundingAccountID,
+			"recurrence":         record.Recurrence,
+			"completion_action":  record.CompletionAction,
 			"updated_at":         time.Now().UTC(),
 		}).Error; err != nil {
 		return 0, err
@@ -206,19 +232,24 @@ func (r *SavingsRepository) GetUncategorizedBalance(ctx context.Context, tx *gor
 	return uncategorized, nil
 }
 
-func (r *SavingsRepository) CountContributions(ctx context.Context, tx *gorm.DB, goalID int64) (int64, error) {
+func (r *SavingsRepository) CountContributions(ctx context.Context, tx *gorm.DB, goalID int64, cycle *int) (int64, error) {
 	db := tx
 	if db == nil {
 		db = r.db
 	}
 	db = db.WithContext(ctx)
 
+	query := db.Model(&models.SavingContribution{}).Where("goal_id = ?", goalID)
+	if cycle != nil {
+		query = query.Where("cycle_number = ?", *cycle)
+	}
+
 	var count int64
-	err := db.Model(&models.SavingContribution{}).Where("goal_id = ?", goalID).Count(&count).Error
+	err := query.Count(&count).Error
 	return count, err
 }
 
-func (r *SavingsRepository) FindContributionsPaginated(ctx context.Context, tx *gorm.DB, goalID int64, offset, limit int, sortField, sortOrder string) ([]models.SavingContribution, error) {
+func (r *SavingsRepository) FindContributionsPaginated(ctx context.Context, tx *gorm.DB, goalID int64, cycle *int, offset, limit int, sortField, sortOrder string) ([]models.SavingContribution, error) {
 	db := tx
 	if db == nil {
 		db = r.db
@@ -227,9 +258,13 @@ func (r *SavingsRepository) FindContributionsPaginated(ctx context.Context, tx *
 
 	orderBy := utils.ConstructOrderByClause(nil, "saving_contributions", sortField, sortOrder)
 
+	query := db.Model(&models.SavingContribution{}).Where("goal_id = ?", goalID)
+	if cycle != nil {
+		query = query.Where("cycle_number = ?", *cycle)
+	}
+
 	var records []models.SavingContribution
-	err := db.Model(&models.SavingContribution{}).
-		Where("goal_id = ?", goalID).
+	err := query.
 		Order(orderBy + ", created_at DESC").
 		Offset(offset).Limit(limit).
 		Find(&records).Error
@@ -267,6 +302,63 @@ func (r *SavingsRepository) FindContributionByID(ctx context.Context, tx *gorm.D
 	return record, err
 }
 
+func (r *SavingsRepository) FindContributionByTransferID(ctx context.Context, tx *gorm.DB, transferID, userID int64) (models.SavingContribution, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.SavingContribution
+	err := db.Where("transfer_id = ? AND user_id = ?", transferID, userID).First(&record).Error
+	return record, err
+}
+
+// FindContributionTransfer loads the transfer behind a contribution along with
+// both of its legs.
+func (r *SavingsRepository) FindContributionTransfer(ctx context.Context, tx *gorm.DB, transferID, userID int64) (models.Transfer, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.Transfer
+	err := db.Preload("TransactionInflow").Preload("TransactionOutflow").
+		Where("id = ? AND user_id = ?", transferID, userID).
+		First(&record).Error
+	return record, err
+}
+
+// SumTransferBackedContributions returns, per goal, the net amount of
+// contributions that moved real money through a transfer.
+func (r *SavingsRepository) SumTransferBackedContributions(ctx context.Context, tx *gorm.DB, userID int64) (map[int64]decimal.Decimal, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var rows []struct {
+		GoalID int64
+		Total  decimal.Decimal
+	}
+	err := db.Model(&models.SavingContribution{}).
+		Select("goal_id, COALESCE(SUM(amount), 0) AS total").
+		Where("user_id = ? AND transfer_id IS NOT NULL", userID).
+		Group("goal_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[int64]decimal.Decimal, len(rows))
+	for _, row := range rows {
+		totals[row.GoalID] = row.Total
+	}
+	return totals, nil
+}
+
 func (r *SavingsRepository) InsertContribution(ctx context.Context, tx *gorm.DB, record *models.SavingContribution) (int64, error) {
 	db := tx
 	if db == nil {
@@ -289,3 +381,258 @@ func (r *SavingsRepository) DeleteContribution(ctx context.Context, tx *gorm.DB,
 
 	return db.Where("id = ?", id).Delete(&models.SavingContribution{}).Error
 }
+
+func (r *SavingsRepository) FindAutoContributionsForMonth(ctx context.Context, tx *gorm.DB, userID int64, month time.Time) ([]models.SavingContribution, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var records []models.SavingContribution
+	err := db.Model(&models.SavingContribution{}).
+		Where("user_id = ? AND month = ? AND source = ?", userID, monthStart, models.SavingContributionSourceAuto).
+		Find(&records).Error
+	return records, err
+}
+
+func (r *SavingsRepository) FindBudget(ctx context.Context, tx *gorm.DB, userID int64) (models.SavingsBudget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.SavingsBudget
+	err := db.Where("user_id = ?", userID).First(&record).Error
+	return record, err
+}
+
+func (r *SavingsRepository) FindDueBudgets(ctx context.Context, tx *gorm.DB, dayOfMonth int) ([]models.SavingsBudget, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.SavingsBudget
+	err := db.Model(&models.SavingsBudget{}).
+		Where("is_active = true AND (fund_day_of_month IS NULL OR fund_day_of_month <= ?)", dayOfMonth).
+		Find(&records).Error
+	return records, err
+}
+
+func (r *SavingsRepository) UpsertBudget(ctx context.Context, tx *gorm.DB, record *models.SavingsBudget) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if record.ID == 0 {
+		if err := db.Create(record).Error; err != nil {
+			return 0, err
+		}
+		return record.ID, nil
+	}
+
+	if err := db.Model(&models.SavingsBudget{}).
+		Where("id = ? AND user_id = ?", record.ID, record.UserID).
+		Updates(map[string]interface{}{
+			"mode":              record.Mode,
+			"amount":            record.Amount,
+			"income_percent":    record.IncomePercent,
+			"strategy":          record.Strategy,
+			"fund_day_of_month": record.FundDayOfMonth,
+			"is_active":         record.IsActive,
+			"updated_at":        time.Now().UTC(),
+		}).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *SavingsRepository) DeleteBudget(ctx context.Context, tx *gorm.DB, userID int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("user_id = ?", userID).Delete(&models.SavingsBudget{}).Error
+}
+
+// SumIncomeForMonth is the user's take-home for a month: real income,
+// excluding transfers, adjustments and system entries.
+func (r *SavingsRepository) SumIncomeForMonth(ctx context.Context, tx *gorm.DB, userID int64, month time.Time) (decimal.Decimal, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var total decimal.Decimal
+	err := db.Raw(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE user_id = ?
+		  AND transaction_type = 'income'
+		  AND is_adjustment = false
+		  AND is_system = false
+		  AND is_transfer = false
+		  AND deleted_at IS NULL
+		  AND txn_date >= ? AND txn_date < ?`,
+		userID, monthStart, monthStart.AddDate(0, 1, 0),
+	).Scan(&total).Error
+	return total, err
+}
+
+func (r *SavingsRepository) FindCycles(ctx context.Context, tx *gorm.DB, goalID int64) ([]models.SavingGoalCycle, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.SavingGoalCycle
+	err := db.Model(&models.SavingGoalCycle{}).
+		Where("goal_id = ?", goalID).
+		Order("cycle_number DESC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *SavingsRepository) FindCycleByTransactionID(ctx context.Context, tx *gorm.DB, transactionID, userID int64) (models.SavingGoalCycle, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.SavingGoalCycle
+	err := db.Where("transaction_id = ? AND user_id = ?", transactionID, userID).First(&record).Error
+	return record, err
+}
+
+func (r *SavingsRepository) InsertCycle(ctx context.Context, tx *gorm.DB, record *models.SavingGoalCycle) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+// AdvanceCycle moves a recurring goal into its next cycle.
+func (r *SavingsRepository) AdvanceCycle(ctx context.Context, tx *gorm.DB, record models.SavingGoal) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.SavingGoal{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"current_amount":   record.CurrentAmount,
+			"cycle_number":     record.CycleNumber,
+			"cycle_started_at": record.CycleStartedAt,
+			"target_date":      record.TargetDate,
+			"status":           record.Status,
+			"updated_at":       time.Now().UTC(),
+		}).Error
+}
+
+func (r *SavingsRepository) FindMilestones(ctx context.Context, tx *gorm.DB, goalID int64) ([]models.SavingGoalMilestone, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.SavingGoalMilestone
+	err := db.Model(&models.SavingGoalMilestone{}).
+		Where("goal_id = ?", goalID).
+		Order("kind, value").
+		Find(&records).Error
+	return records, err
+}
+
+// ReplaceMilestones swaps a goal's milestones for records.
+func (r *SavingsRepository) ReplaceMilestones(ctx context.Context, tx *gorm.DB, goalID int64, records []models.SavingGoalMilestone) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Where("goal_id = ?", goalID).Delete(&models.SavingGoalMilestone{}).Error; err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return db.Create(&records).Error
+}
+
+func (r *SavingsRepository) MarkMilestonesReached(ctx context.Context, tx *gorm.DB, ids []int64, cycle int, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.SavingGoalMilestone{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"reached_cycle": cycle,
+			"reached_at":    at,
+			"updated_at":    time.Now().UTC(),
+		}).Error
+}
+
+// FindActiveGoalsWithTargetDate returns every active goal that has a schedule
+// to fall behind on.
+func (r *SavingsRepository) FindActiveGoalsWithTargetDate(ctx context.Context, tx *gorm.DB) ([]models.SavingGoal, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.SavingGoal
+	err := db.Model(&models.SavingGoal{}).
+		Where("status = ? AND target_date IS NOT NULL", models.SavingGoalStatusActive).
+		Order("user_id, id").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *SavingsRepository) SetLateAlertedAt(ctx context.Context, tx *gorm.DB, ids []int64, at *time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Model(&models.SavingGoal{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"late_alerted_at": at,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wealth-warden/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SpendingLimitRepositoryInterface interface {
+	BeginTx(ctx context.Context) (*gorm.DB, error)
+	FindLimits(ctx context.Context, tx *gorm.DB, userID int64, onlyActive bool) ([]models.SpendingLimit, error)
+	FindLimitByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.SpendingLimit, error)
+	InsertLimit(ctx context.Context, tx *gorm.DB, record *models.SpendingLimit) (int64, error)
+	UpdateLimit(ctx context.Context, tx *gorm.DB, record models.SpendingLimit) (int64, error)
+	DeleteLimit(ctx context.Context, tx *gorm.DB, id int64) error
+	InsertAlert(ctx context.Context, tx *gorm.DB, record *models.SpendingLimitAlert) (bool, error)
+	FindPendingAlerts(ctx context.Context, tx *gorm.DB) ([]models.SpendingLimitAlert, error)
+	MarkAlertsNotified(ctx context.Context, tx *gorm.DB, ids []int64, at time.Time) error
+}
+
+type SpendingLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewSpendingLimitRepository(db *gorm.DB) *SpendingLimitRepository {
+	return &SpendingLimitRepository{db: db}
+}
+
+var _ SpendingLimitRepositoryInterface = (*SpendingLimitRepository)(nil)
+
+func (r *SpendingLimitRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	return tx, tx.Error
+}
+
+func (r *SpendingLimitRepository) FindLimits(ctx context.Context, tx *gorm.DB, userID int64, onlyActive bool) ([]models.SpendingLimit, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	q := db.Model(&models.SpendingLimit{}).Where("user_id = ?", userID)
+	if onlyActive {
+		q = q.Where("is_active = ?", true)
+	}
+
+	var records []models.SpendingLimit
+	if err := q.Order("id ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *SpendingLimitRepository) FindLimitByID(ctx context.Context, tx *gorm.DB, id, userID int64) (models.SpendingLimit, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var record models.SpendingLimit
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&record).Error
+	return record, err
+}
+
+func (r *SpendingLimitRepository) InsertLimit(ctx context.Context, tx *gorm.DB, record *models.SpendingLimit) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Create(record).Error; err != nil {
+		return 0, err
+	}
+	return record.ID, nil
+}
+
+func (r *SpendingLimitRepository) UpdateLimit(ctx context.Context, tx *gorm.DB, record models.SpendingLimit) (int64, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if err := db.Model(&models.SpendingLimit{}).
+		Where("id = ?", record.ID).
+		Updates(map[string]interface{}{
+			"amount":         record.Amount,
+			"notify_at_80":   record.NotifyAt80,
+			"notify_at_100":  record.NotifyAt100,
+			"custom_percent": record.CustomPercent,
+			"delivery":       record.Delivery,
+			"is_active":      record.IsActive,
+			"updated_at":     time.Now().UTC(),
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	return record.ID, nil
+}
+
+func (r *SpendingLimitRepository) DeleteLimit(ctx context.Context, tx *gorm.DB, id int64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	return db.Where("id = ?", id).Delete(&models.SpendingLimit{}).Error
+}
+
+// InsertAlert stores a crossing and reports whether it is new. A crossing
+// already on record for the limit, month and percent is left untouched.
+func (r *SpendingLimitRepository) InsertAlert(ctx context.Context, tx *gorm.DB, record *models.SpendingLimitAlert) (bool, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	res := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "limit_id"}, {Name: "month"}, {Name: "percent"}},
+		DoNothing: true,
+	}).Create(record)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *SpendingLimitRepository) FindPendingAlerts(ctx context.Context, tx *gorm.DB) ([]models.SpendingLimitAlert, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	var records []models.SpendingLimitAlert
+	err := db.Where("notified_at IS NULL").
+		Order("user_id ASC, limit_id ASC, percent ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r *SpendingLimitRepository) MarkAlertsNotified(ctx context.Context, tx *gorm.DB, ids []int64, at time.Time) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	db = db.WithContext(ctx)
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.SpendingLimitAlert{}).
+		Where("id IN ?", ids).
+		Update("notified_at", at).Error
+}
@@ -565,6 +565,12 @@ func (r *TransactionRepository) InsertTransaction(ctx context.Context, tx *gorm.
 	}
 	db = db.WithContext(ctx)
 
+	// entries without an explicit author were entered by the account owner
+	if newRecord.CreatedBy == nil {
+		createdBy := newRecord.UserID
+		newRecord.CreatedBy = &createdBy
+	}
+
 	if err := db.Create(&newRecord).Error; err != nil {
 		return 0, err
 	}
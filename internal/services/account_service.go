@@ -1280,15 +1280,27 @@ func (s *AccountService) SyncForUser(ctx context.Context, userID int64) error {
 }
 
 func (s *AccountService) RecalculateAssetPnL(ctx context.Context, userID, assetID int64) error {
-	if s.priceFetchClient != nil {
-		asset, err := s.investmentRepo.FindInvestmentAssetByID(ctx, nil, assetID, userID)
-		if err != nil {
-			return err
+	asset, err := s.investmentRepo.FindInvestmentAssetByID(ctx, nil, assetID, userID)
+	if err != nil {
+		return err
+	}
+
+	if asset.ManualPricing {
+		// Carried at the latest price the user entered
+		latest, err := s.investmentRepo.FindAssetPriceOnOrBefore(ctx, nil, assetID, nil)
+		if err == nil && latest.Price.IsPositive() {
+			if err := s.investmentRepo.UpdateAssetCurrentPrice(ctx, nil, assetID, latest.Price, latest.AsOf); err != nil {
+				return err
+			}
+			if err := s.investmentRepo.UpdateTradesPnLForAsset(ctx, nil, assetID, latest.Price, asset.InvestmentType, latest.AsOf); err != nil {
+				return err
+			}
 		}
-		priceData, err := s.priceFetchClient.GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
+	} else if s.priceFetchClient != nil {
+		priceData, err := finance.ForProvider(s.priceFetchClient, asset.PriceProvider).GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
 		if err == nil && priceData != nil && priceData.Price > 0 {
 			now := time.Now().UTC()
-			price := decimal.NewFromFloat(priceData.Price)
+			price := utils.UnitPrice(asset, decimal.NewFromFloat(priceData.Price), now)
 			if err := s.investmentRepo.UpdateAssetCurrentPrice(ctx, nil, assetID, price, now); err != nil {
 				return err
 			}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type AllocationServiceInterface interface {
+	FetchTargets(ctx context.Context, userID int64, accountID *int64) ([]models.AllocationTarget, error)
+	SaveTargets(ctx context.Context, userID int64, req *models.AllocationTargetsReq) error
+	DeleteTargets(ctx context.Context, userID int64, accountID *int64) error
+	FetchDrift(ctx context.Context, userID int64, accountID *int64, contribution decimal.Decimal) (*models.AllocationReport, error)
+
+	FetchTargetScopes(ctx context.Context) ([]models.AllocationScope, error)
+	MarkDriftAlerts(ctx context.Context, userID int64, report *models.AllocationReport, today time.Time) ([]models.AllocationBucket, error)
+}
+
+type AllocationService struct {
+	logger         *zap.Logger
+	repo           repositories.AllocationRepositoryInterface
+	investmentRepo repositories.InvestmentRepositoryInterface
+	accountRepo    repositories.AccountRepositoryInterface
+	settingsRepo   repositories.SettingsRepositoryInterface
+	analyticsRepo  repositories.AnalyticsRepositoryInterface
+	loggingRepo    repositories.LoggingRepositoryInterface
+	jobDispatcher  queue.JobDispatcher
+}
+
+func NewAllocationService(
+	logger *zap.Logger,
+	repo *repositories.AllocationRepository,
+	investmentRepo *repositories.InvestmentRepository,
+	accountRepo *repositories.AccountRepository,
+	settingsRepo *repositories.SettingsRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+) *AllocationService {
+	return &AllocationService{
+		logger:         logger,
+		repo:           repo,
+		investmentRepo: investmentRepo,
+		accountRepo:    accountRepo,
+		settingsRepo:   settingsRepo,
+		analyticsRepo:  analyticsRepo,
+		loggingRepo:    loggingRepo,
+		jobDispatcher:  jobDispatcher,
+	}
+}
+
+var _ AllocationServiceInterface = (*AllocationService)(nil)
+
+func (s *AllocationService) FetchTargets(ctx context.Context, userID int64, accountID *int64) ([]models.AllocationTarget, error) {
+	return s.repo.FindTargets(ctx, nil, userID, accountID)
+}
+
+func (s *AllocationService) FetchTargetScopes(ctx context.Context) ([]models.AllocationScope, error) {
+	return s.repo.FindTargetScopes(ctx, nil)
+}
+
+var defaultAllocationTolerance = decimal.NewFromInt(5)
+
+func validateAllocationReq(req *models.AllocationTargetsReq) error {
+	hundred := decimal.NewFromInt(100)
+	total := decimal.Zero
+	seen := make(map[string]bool, len(req.Targets))
+
+	for _, t := range req.Targets {
+		target := models.AllocationTarget{
+			Kind:           req.Kind,
+			AssetID:        t.AssetID,
+			InvestmentType: t.InvestmentType,
+			AssetClass:     t.AssetClass,
+		}
+		key := utils.AllocationTargetKey(target)
+		if key == "" {
+			return fmt.Errorf("every %s target needs a %s", req.Kind, req.Kind)
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate target: %s", key)
+		}
+		seen[key] = true
+
+		if req.Kind == models.AllocationByInvestmentType {
+			if !t.InvestmentType.Valid() {
+				return fmt.Errorf("invalid investment type: %s", *t.InvestmentType)
+			}
+		}
+
+		if !t.TargetPercent.IsPositive() || t.TargetPercent.GreaterThan(hundred) {
+			return fmt.Errorf("target weights must be between 0 and 100")
+		}
+		if t.TolerancePercent != nil && t.TolerancePercent.IsNegative() {
+			return fmt.Errorf("tolerance cannot be negative")
+		}
+		total = total.Add(t.TargetPercent)
+	}
+
+	if !total.Equal(hundred) {
+		return fmt.Errorf("target weights must add up to 100%%, got %s%%", total.String())
+	}
+
+	return nil
+}
+
+func (s *AllocationService) SaveTargets(ctx context.Context, userID int64, req *models.AllocationTargetsReq) error {
+	if err := validateAllocationReq(req); err != nil {
+		return err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if req.AccountID != nil {
+		accType, err := s.accountRepo.FindAccountTypeByAccID(ctx, tx, *req.AccountID, userID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if accType.Type != "investment" && accType.Type != "crypto" {
+			tx.Rollback()
+			return fmt.Errorf("target allocations can be set only for investment accounts")
+		}
+	}
+
+	existing, err := s.repo.FindTargets(ctx, tx, userID, req.AccountID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	targets := make([]models.AllocationTarget, len(req.Targets))
+	for i, t := range req.Targets {
+		if t.AssetID != nil {
+			asset, err := s.investmentRepo.FindInvestmentAssetByID(ctx, tx, *t.AssetID, userID)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("asset not found: %w", err)
+			}
+			if req.AccountID != nil && asset.AccountID != *req.AccountID {
+				tx.Rollback()
+				return fmt.Errorf("asset %s is not held in this account", asset.Ticker)
+			}
+		}
+
+		tolerance := defaultAllocationTolerance
+		if t.TolerancePercent != nil {
+			tolerance = *t.TolerancePercent
+		}
+		targets[i] = models.AllocationTarget{
+			Kind:             req.Kind,
+			AssetID:          t.AssetID,
+			InvestmentType:   t.InvestmentType,
+			AssetClass:       utils.NormalizeAssetClass(t.AssetClass),
+			TargetPercent:    t.TargetPercent,
+			TolerancePercent: tolerance,
+		}
+	}
+
+	if err := s.repo.ReplaceTargets(ctx, tx, userID, req.AccountID, targets); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	if req.AccountID != nil {
+		changes.Stamp("account_id", strconv.FormatInt(*req.AccountID, 10))
+	}
+	oldKind := ""
+	if len(existing) > 0 {
+		oldKind = string(existing[0].Kind)
+	}
+	utils.CompareChanges(oldKind, string(req.Kind), changes, "kind")
+	utils.CompareChanges(strconv.Itoa(len(existing)), strconv.Itoa(len(targets)), changes, "targets")
+
+	event := "update"
+	if len(existing) == 0 {
+		event = "create"
+	}
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       event,
+		Category:    "allocation_target",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *AllocationService) DeleteTargets(ctx context.Context, userID int64, accountID *int64) error {
+	existing, err := s.repo.FindTargets(ctx, nil, userID, accountID)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("no target allocation found")
+	}
+
+	if err := s.repo.DeleteTargets(ctx, nil, userID, accountID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	if accountID != nil {
+		changes.Stamp("account_id", strconv.FormatInt(*accountID, 10))
+	}
+	utils.CompareChanges(string(existing[0].Kind), "", changes, "kind")
+	utils.CompareChanges(strconv.Itoa(len(existing)), "", changes, "targets")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "allocation_target",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// FetchDrift measures the holdings in scope against the target set. Values are
+// converted into the account currency, or the default currency for the whole
+// portfolio, at the latest stored rate.
+func (s *AllocationService) FetchDrift(ctx context.Context, userID int64, accountID *int64, contribution decimal.Decimal) (*models.AllocationReport, error) {
+	if contribution.IsNegative() {
+		return nil, fmt.Errorf("contribution cannot be negative")
+	}
+
+	targets, err := s.repo.FindTargets(ctx, nil, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no target allocation found")
+	}
+
+	var currency string
+	var assets []models.InvestmentAsset
+	if accountID != nil {
+		acc, err := s.accountRepo.FindAccountByID(ctx, nil, *accountID, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		currency = acc.Currency
+		assets, err = s.investmentRepo.FindAssetsByAccountID(ctx, nil, *accountID, userID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, userID)
+		if err != nil {
+			return nil, err
+		}
+		currency = settings.DefaultCurrency
+		assets, err = s.investmentRepo.FindAllInvestmentAssets(ctx, nil, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	rates := map[string]decimal.Decimal{currency: decimal.NewFromInt(1)}
+	holdings := make([]utils.AllocationHolding, 0, len(assets))
+	for _, a := range assets {
+		rate, ok := rates[a.Currency]
+		if !ok {
+			found := false
+			rate, found, err = s.analyticsRepo.FetchLatestExchangeRate(ctx, nil, a.Currency, currency, now)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				s.logger.Warn("no exchange rate for allocation, using 1:1",
+					zap.String("from", a.Currency), zap.String("to", currency))
+				rate = decimal.NewFromInt(1)
+			}
+			rates[a.Currency] = rate
+		}
+
+		h := utils.AllocationHolding{Asset: a, Value: a.CurrentValue.Mul(rate)}
+		if a.CurrentPrice != nil {
+			price := a.CurrentPrice.Mul(rate)
+			h.Price = &price
+		}
+		holdings = append(holdings, h)
+	}
+
+	report := utils.ComputeAllocation(targets, holdings, contribution)
+	report.AccountID = accountID
+	report.Currency = currency
+	return &report, nil
+}
+
+// MarkDriftAlerts records which buckets left their band today and clears the
+// ones that came back, returning only the newly breached buckets so an alert
+// goes out once per breach rather than every day.
+func (s *AllocationService) MarkDriftAlerts(ctx context.Context, userID int64, report *models.AllocationReport, today time.Time) ([]models.AllocationBucket, error) {
+	targets, err := s.repo.FindTargets(ctx, nil, userID, report.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	flagged := make(map[int64]bool, len(targets))
+	for _, t := range targets {
+		flagged[t.ID] = t.OutOfBandSince != nil
+	}
+
+	var breached []models.AllocationBucket
+	var newIDs, clearedIDs []int64
+	for _, b := range report.Buckets {
+		switch {
+		case b.OutsideBand && !flagged[b.TargetID]:
+			breached = append(breached, b)
+			newIDs = append(newIDs, b.TargetID)
+		case !b.OutsideBand && flagged[b.TargetID]:
+			clearedIDs = append(clearedIDs, b.TargetID)
+		}
+	}
+
+	if err := s.repo.SetOutOfBandSince(ctx, nil, newIDs, &today); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetOutOfBandSince(ctx, nil, clearedIDs, nil); err != nil {
+		return nil, err
+	}
+
+	return breached, nil
+}
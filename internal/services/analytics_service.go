@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -19,11 +20,14 @@ import (
 
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type AnalyticsServiceInterface interface {
 	GetNetWorthSeries(ctx context.Context, userID int64, currency, rangeKey, from, to string, accountID *int64) (*models.NetWorthResponse, error)
 	FetchAssetChart(ctx context.Context, userID, assetID int64, rangeKey string) (*models.AssetChartResponse, error)
+	FetchPerformance(ctx context.Context, userID int64, assetID, accountID *int64, rangeKey string) (*models.PerformanceMetrics, error)
+	FetchCurrencyExposure(ctx context.Context, userID int64, rangeKey string) (*models.CurrencyExposure, error)
 	GetCategoryUsageForYear(ctx context.Context, userID int64, year int, class string, accID, catID *int64, asPercent bool) (*models.CategoryUsageResponse, error)
 	GetCategoryUsageForYears(ctx context.Context, userID int64, years []int, class string, accID, catID *int64, asPercent bool) (*models.MultiYearCategoryUsageResponse, error)
 	GetYearlyCashFlowBreakdown(ctx context.Context, userID int64, year int, accountID *int64) (*models.YearlyCashflowBreakdown, error)
@@ -33,6 +37,7 @@ type AnalyticsServiceInterface interface {
 	GetMonthlyStats(ctx context.Context, userID int64, accountID *int64, year, month int) (*models.MonthlyStats, error)
 	GetYearlyAverageForCategory(ctx context.Context, userID int64, accountID int64, categoryID int64, isGroup bool) (float64, error)
 	GenerateCategoryReport(ctx context.Context, userID int64, params models.CategoryReportParams) (*models.Report, error)
+	GenerateCapitalGainsReport(ctx context.Context, userID int64, params models.CapitalGainsReportParams) (*models.Report, error)
 	FindReportByID(ctx context.Context, id, userID int64) (*models.Report, error)
 	DownloadReport(ctx context.Context, id, userID int64) ([]byte, string, error)
 	ListReportsPaginated(ctx context.Context, userID int64, p utils.PaginationParams) ([]models.Report, *utils.Paginator, error)
@@ -222,6 +227,12 @@ func (s *AnalyticsService) GetNetWorthSeries(ctx context.Context, userID int64,
 		}
 	}
 
+	benchmarks, err := s.benchmarkSeries(ctx, tx, userID, accountID, points)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -238,6 +249,7 @@ func (s *AnalyticsService) GetNetWorthSeries(ctx context.Context, userID int64,
 			Abs:                abs,
 			Pct:                pct,
 		},
+		Benchmarks: benchmarks,
 	}
 
 	if at != nil {
@@ -1376,6 +1388,52 @@ func (s *AnalyticsService) GenerateCategoryReport(
 	return record, nil
 }
 
+func (s *AnalyticsService) GenerateCapitalGainsReport(
+	ctx context.Context,
+	userID int64,
+	params models.CapitalGainsReportParams,
+) (*models.Report, error) {
+	if params.Year < 1900 || params.Year > time.Now().Year() {
+		return nil, fmt.Errorf("invalid tax year %d", params.Year)
+	}
+	if params.Format == "" {
+		params.Format = "xlsx"
+	}
+	if params.Format != "xlsx" && params.Format != "csv" {
+		return nil, fmt.Errorf("unsupported report format %q", params.Format)
+	}
+
+	settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	params.Currency = settings.DefaultCurrency
+
+	metadata, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.Report{
+		UserID:   userID,
+		Name:     fmt.Sprintf("Capital Gains Report - %d", params.Year),
+		Type:     "capital_gains",
+		Status:   "pending",
+		Metadata: metadata,
+	}
+	if err := s.repo.InsertReport(ctx, nil, record); err != nil {
+		return nil, err
+	}
+
+	// Only the serialized fields survive Dispatch; the job registry re-attaches live deps before Process.
+	job := queue_jobs.NewGenerateCapitalGainsReportJob(s.logger, s.repo, nil, ws.NoopBroadcaster{}, record.ID, userID, params)
+	if err := s.jobDispatcher.Dispatch(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
 func (s *AnalyticsService) DownloadReport(ctx context.Context, id, userID int64) ([]byte, string, error) {
 
 	tx, err := s.repo.BeginTx(ctx)
@@ -1407,7 +1465,7 @@ func (s *AnalyticsService) DownloadReport(ctx context.Context, id, userID int64)
 		return nil, "", err
 	}
 
-	return data, report.Name, nil
+	return data, report.Name + filepath.Ext(*report.FilePath), nil
 }
 
 func (s *AnalyticsService) DeleteReport(ctx context.Context, userID, id int64) error {
@@ -1446,25 +1504,7 @@ func (s *AnalyticsService) DeleteReport(ctx context.Context, userID, id int64) e
 
 func (s *AnalyticsService) FetchAssetChart(ctx context.Context, userID, assetID int64, rangeKey string) (*models.AssetChartResponse, error) {
 	dto := time.Now().UTC().Truncate(24 * time.Hour)
-	var dfrom time.Time
-	switch rangeKey {
-	case "1w":
-		dfrom = dto.AddDate(0, 0, -7)
-	case "1m":
-		dfrom = dto.AddDate(0, -1, 0)
-	case "3m":
-		dfrom = dto.AddDate(0, -3, 0)
-	case "6m":
-		dfrom = dto.AddDate(0, -6, 0)
-	case "ytd":
-		dfrom = time.Date(dto.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	case "1y":
-		dfrom = dto.AddDate(-1, 0, 0)
-	case "5y":
-		dfrom = dto.AddDate(-5, 0, 0)
-	default:
-		dfrom = dto.AddDate(0, -1, 0)
-	}
+	dfrom := assetRangeStart(rangeKey, dto)
 
 	days := int(dto.Sub(dfrom).Hours()/24) + 1
 	gran := "day"
@@ -1480,9 +1520,382 @@ func (s *AnalyticsService) FetchAssetChart(ctx context.Context, userID, assetID
 		return nil, err
 	}
 
+	assets, err := s.repo.FetchPerformanceAssets(ctx, nil, userID, &assetID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var accountID *int64
+	if len(assets) > 0 {
+		accountID = &assets[0].AccountID
+	}
+
+	benchmarks, err := s.benchmarkSeries(ctx, nil, userID, accountID, mv)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.AssetChartResponse{
 		Currency:          currency,
 		MarketValuePoints: mv,
 		CostBasisPoints:   cb,
+		Benchmarks:        benchmarks,
 	}, nil
 }
+
+// benchmarkSeries rescales the benchmarks attached to the portfolio (and to
+// accountID, if any) onto the chart points. Benchmarks without prices in the
+// window are left out.
+func (s *AnalyticsService) benchmarkSeries(ctx context.Context, tx *gorm.DB, userID int64, accountID *int64, points []models.ChartPoint) ([]models.BenchmarkSeries, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	benchmarks, err := s.repo.FetchChartBenchmarks(ctx, tx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := points[0].Date, points[len(points)-1].Date
+	var series []models.BenchmarkSeries
+	for _, b := range benchmarks {
+		prices, err := s.repo.FetchBenchmarkPrices(ctx, tx, b.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		normalized := utils.NormalizeBenchmark(points, prices)
+		if len(normalized) == 0 {
+			continue
+		}
+		series = append(series, models.BenchmarkSeries{
+			BenchmarkID: b.ID,
+			Ticker:      b.Ticker,
+			Points:      normalized,
+		})
+	}
+
+	return series, nil
+}
+
+func assetRangeStart(rangeKey string, dto time.Time) time.Time {
+	switch rangeKey {
+	case "1w":
+		return dto.AddDate(0, 0, -7)
+	case "1m":
+		return dto.AddDate(0, -1, 0)
+	case "3m":
+		return dto.AddDate(0, -3, 0)
+	case "6m":
+		return dto.AddDate(0, -6, 0)
+	case "ytd":
+		return time.Date(dto.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "1y":
+		return dto.AddDate(-1, 0, 0)
+	case "5y":
+		return dto.AddDate(-5, 0, 0)
+	default:
+		return dto.AddDate(0, -1, 0)
+	}
+}
+
+// FetchPerformance computes time- and money-weighted returns for one asset, one
+// investment account (accountID) or the whole portfolio (both nil). Besides the
+// chart ranges, rangeKey accepts "all", which starts at the first trade.
+//
+// Account figures are in the account currency and portfolio figures in the
+// user's default currency. Holdings in other currencies are converted at the
+// latest rate on the range end, so currency moves don't show up as performance.
+func (s *AnalyticsService) FetchPerformance(ctx context.Context, userID int64, assetID, accountID *int64, rangeKey string) (*models.PerformanceMetrics, error) {
+	assets, err := s.repo.FetchPerformanceAssets(ctx, nil, userID, assetID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "portfolio"
+	var currency string
+	switch {
+	case assetID != nil:
+		if len(assets) == 0 {
+			return nil, fmt.Errorf("asset not found")
+		}
+		scope = "asset"
+		currency = assets[0].Currency
+	case accountID != nil:
+		acc, err := s.accRepo.FindAccountByID(ctx, nil, *accountID, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		scope = "account"
+		currency = acc.Currency
+	default:
+		settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, userID)
+		if err != nil {
+			return nil, err
+		}
+		currency = settings.DefaultCurrency
+	}
+
+	dto := time.Now().UTC().Truncate(24 * time.Hour)
+	dfrom := assetRangeStart(rangeKey, dto)
+
+	res := &models.PerformanceMetrics{Scope: scope, Currency: currency, To: dto}
+	if len(assets) == 0 {
+		res.From = dfrom
+		return res, nil
+	}
+
+	assetIDs := make([]int64, len(assets))
+	for i, a := range assets {
+		assetIDs[i] = a.ID
+	}
+
+	// "all" needs the full history anyway, so load it first and start the range
+	// at the first trade.
+	historyFrom := dfrom
+	if rangeKey == "all" {
+		historyFrom = time.Time{}
+	}
+	trades, income, prices, err := s.repo.FetchPerformanceHistory(ctx, nil, userID, assetIDs, historyFrom, dto)
+	if err != nil {
+		return nil, err
+	}
+	if rangeKey == "all" {
+		dfrom = dto
+		if len(trades) > 0 && trades[0].TxnDate.Before(dto) {
+			dfrom = trades[0].TxnDate.UTC().Truncate(24 * time.Hour)
+		}
+	}
+	res.From = dfrom
+
+	tradesByAsset := make(map[int64][]models.InvestmentTrade)
+	for _, t := range trades {
+		tradesByAsset[t.AssetID] = append(tradesByAsset[t.AssetID], t)
+	}
+	incomeByAsset := make(map[int64][]models.InvestmentIncome)
+	for _, inc := range income {
+		incomeByAsset[inc.AssetID] = append(incomeByAsset[inc.AssetID], inc)
+	}
+	pricesByAsset := make(map[int64][]models.AssetPriceHistory)
+	for _, p := range prices {
+		pricesByAsset[p.AssetID] = append(pricesByAsset[p.AssetID], p)
+	}
+
+	series := make([]utils.PositionSeries, 0, len(assets))
+	for _, a := range assets {
+		ps := utils.AssetPositionSeries(a, tradesByAsset[a.ID], incomeByAsset[a.ID], pricesByAsset[a.ID], dfrom, dto)
+		if a.Currency != currency {
+			rate, ok, err := s.repo.FetchLatestExchangeRate(ctx, nil, a.Currency, currency, dto)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				s.logger.Warn("no exchange rate for performance, using 1:1",
+					zap.String("from", a.Currency), zap.String("to", currency))
+				rate = decimal.NewFromInt(1)
+			}
+			ps = ps.Scale(rate)
+		}
+		series = append(series, ps)
+	}
+	total := utils.MergePositionSeries(series)
+
+	res.StartValue = total.Values[0].Value
+	res.EndValue = total.Values[len(total.Values)-1].Value
+	for _, f := range total.Flows {
+		res.NetContributions = res.NetContributions.Add(f.Amount)
+	}
+	res.TimeWeightedReturn = utils.TimeWeightedReturn(total).Round(6)
+	res.AnnualizedTWR = utils.AnnualizeReturn(res.TimeWeightedReturn, int(dto.Sub(dfrom).Hours()/24)).Round(6)
+	if mwr, ok := utils.MoneyWeightedReturn(total); ok {
+		mwr = mwr.Round(6)
+		res.MoneyWeightedReturn = &mwr
+	}
+
+	return res, nil
+}
+
+// FetchCurrencyExposure breaks net worth down by currency over the range and
+// converts it to the user's default currency at each day's stored rate, so the
+// effect of rate moves can be told apart from the holdings' own performance.
+func (s *AnalyticsService) FetchCurrencyExposure(ctx context.Context, userID int64, rangeKey string) (*models.CurrencyExposure, error) {
+	settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	base := settings.DefaultCurrency
+
+	dto := time.Now().UTC().Truncate(24 * time.Hour)
+	dfrom := assetRangeStart(rangeKey, dto)
+	days := int(dto.Sub(dfrom).Hours()/24) + 1
+
+	cash, err := s.repo.FetchCashByCurrency(ctx, nil, userID, dfrom, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]*utils.CurrencyPosition)
+	position := func(currency string) *utils.CurrencyPosition {
+		p, ok := positions[currency]
+		if !ok {
+			p = &utils.CurrencyPosition{
+				Currency: currency,
+				Cash:     make([]decimal.Decimal, days),
+				Holdings: make([]decimal.Decimal, days),
+				Flows:    make([]decimal.Decimal, days),
+			}
+			positions[currency] = p
+		}
+		return p
+	}
+
+	for currency, points := range cash {
+		copy(position(currency).Cash, utils.FillDaily(points, dfrom, days))
+	}
+
+	assets, err := s.repo.FetchPerformanceAssets(ctx, nil, userID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var held []models.InvestmentAsset
+	for _, a := range assets {
+		if a.Account.IncludeInNetWorth {
+			held = append(held, a)
+		}
+	}
+	if len(held) > 0 {
+		assetIDs := make([]int64, len(held))
+		for i, a := range held {
+			assetIDs[i] = a.ID
+		}
+		trades, income, prices, err := s.repo.FetchPerformanceHistory(ctx, nil, userID, assetIDs, dfrom, dto)
+		if err != nil {
+			return nil, err
+		}
+
+		tradesByAsset := make(map[int64][]models.InvestmentTrade)
+		for _, t := range trades {
+			tradesByAsset[t.AssetID] = append(tradesByAsset[t.AssetID], t)
+		}
+		incomeByAsset := make(map[int64][]models.InvestmentIncome)
+		for _, inc := range income {
+			incomeByAsset[inc.AssetID] = append(incomeByAsset[inc.AssetID], inc)
+		}
+		pricesByAsset := make(map[int64][]models.AssetPriceHistory)
+		for _, p := range prices {
+			pricesByAsset[p.AssetID] = append(pricesByAsset[p.AssetID], p)
+		}
+
+		for _, a := range held {
+			ps := utils.AssetPositionSeries(a, tradesByAsset[a.ID], incomeByAsset[a.ID], pricesByAsset[a.ID], dfrom, dto)
+			if a.Account.OwnershipPercent != nil {
+				ps = ps.Scale(a.Account.OwnershipPercent.Div(decimal.NewFromInt(100)))
+			}
+			p := position(a.Currency)
+			for i, v := range ps.Values {
+				p.Holdings[i] = p.Holdings[i].Add(v.Value)
+			}
+			for _, f := range ps.Flows {
+				if i := int(f.Date.UTC().Truncate(24*time.Hour).Sub(dfrom).Hours() / 24); i >= 0 && i < days {
+					p.Flows[i] = p.Flows[i].Add(f.Amount)
+				}
+			}
+		}
+	}
+
+	currencies := make([]string, 0, len(positions))
+	for currency := range positions {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	missing := make(map[string]bool)
+	for _, currency := range currencies {
+		p := positions[currency]
+		if currency == base {
+			p.Rates = constantRates(decimal.NewFromInt(1), days)
+			continue
+		}
+		p.Rates, err = s.dailyRates(ctx, currency, base, dfrom, days)
+		if err != nil {
+			return nil, err
+		}
+		if p.Rates == nil {
+			s.logger.Warn("no exchange rate for currency exposure, using 1:1",
+				zap.String("from", currency), zap.String("to", base))
+			p.Rates = constantRates(decimal.NewFromInt(1), days)
+			missing[currency] = true
+		}
+	}
+
+	res := &models.CurrencyExposure{Currency: base, From: dfrom, To: dto}
+	sample := utils.ChartSampleDays(dfrom, days)
+	totals := make([]decimal.Decimal, len(sample))
+	last := days - 1
+
+	for _, currency := range currencies {
+		p := positions[currency]
+		fxGain, priceGain := utils.AttributeFX(*p)
+		slice := models.CurrencyExposureSlice{
+			Currency:    currency,
+			Cash:        p.Cash[last].Round(2),
+			Holdings:    p.Holdings[last].Round(2),
+			Rate:        p.Rates[last],
+			Value:       p.Converted(last).Round(2),
+			FXGain:      fxGain.Round(2),
+			PriceGain:   priceGain.Round(2),
+			RateMissing: missing[currency],
+		}
+		for j, i := range sample {
+			v := p.Converted(i)
+			totals[j] = totals[j].Add(v)
+			slice.Points = append(slice.Points, models.ChartPoint{Date: dfrom.AddDate(0, 0, i), Value: v.Round(2)})
+		}
+		res.Total = res.Total.Add(slice.Value)
+		res.FXGain = res.FXGain.Add(slice.FXGain)
+		res.PriceGain = res.PriceGain.Add(slice.PriceGain)
+		res.Currencies = append(res.Currencies, slice)
+	}
+
+	for j, i := range sample {
+		res.Points = append(res.Points, models.ChartPoint{Date: dfrom.AddDate(0, 0, i), Value: totals[j].Round(2)})
+	}
+	if !res.Total.IsZero() {
+		for i := range res.Currencies {
+			res.Currencies[i].Share = res.Currencies[i].Value.Div(res.Total).Round(4)
+		}
+	}
+
+	return res, nil
+}
+
+// dailyRates loads the stored rates from one currency into another, falling back
+// to the inverse of the opposite pair. It returns nil when neither is stored.
+func (s *AnalyticsService) dailyRates(ctx context.Context, from, to string, start time.Time, days int) ([]decimal.Decimal, error) {
+	points, err := s.repo.FetchExchangeRateSeries(ctx, nil, from, to, start, start.AddDate(0, 0, days-1))
+	if err != nil {
+		return nil, err
+	}
+	if len(points) > 0 {
+		return utils.DailyRates(points, start, days), nil
+	}
+
+	points, err = s.repo.FetchExchangeRateSeries(ctx, nil, to, from, start, start.AddDate(0, 0, days-1))
+	if err != nil {
+		return nil, err
+	}
+	var inverse []models.ChartPoint
+	for _, p := range points {
+		if p.Value.IsPositive() {
+			inverse = append(inverse, models.ChartPoint{Date: p.Date, Value: decimal.NewFromInt(1).Div(p.Value)})
+		}
+	}
+	return utils.DailyRates(inverse, start, days), nil
+}
+
+func constantRates(rate decimal.Decimal, days int) []decimal.Decimal {
+	out := make([]decimal.Decimal, days)
+	for i := range out {
+		out[i] = rate
+	}
+	return out
+}
@@ -124,3 +124,19 @@ func (s *AnalyticsServiceTestSuite) TestDeleteReport_NotFound_ReturnsError() {
 	err := svc.DeleteReport(s.Ctx, 1, 99999)
 	s.Require().Error(err)
 }
+
+func (s *AnalyticsServiceTestSuite) TestGenerateCapitalGainsReport_RejectsUnknownFormat() {
+	svc := s.TC.App.AnalyticsService
+	_, err := svc.GenerateCapitalGainsReport(s.Ctx, 1, models.CapitalGainsReportParams{Year: 2024, Format: "pdf"})
+	s.Require().Error(err)
+	s.Contains(err.Error(), "format")
+}
+
+func (s *AnalyticsServiceTestSuite) TestGenerateCapitalGainsReport_CreatesPendingReport() {
+	svc := s.TC.App.AnalyticsService
+	report, err := svc.GenerateCapitalGainsReport(s.Ctx, 1, models.CapitalGainsReportParams{Year: 2024})
+	s.Require().NoError(err)
+	s.Equal("pending", report.Status)
+	s.Equal("capital_gains", report.Type)
+	s.Contains(report.Name, "2024")
+}
@@ -33,6 +33,8 @@ type AuthServiceInterface interface {
 type AuthService struct {
 	userRepo      repositories.UserRepositoryInterface
 	roleRepo      repositories.RolePermissionRepositoryInterface
+	householdRepo repositories.HouseholdRepositoryInterface
+	delegRepo     repositories.DelegationRepositoryInterface
 	settingsRepo  repositories.SettingsRepositoryInterface
 	loggingRepo   repositories.LoggingRepositoryInterface
 	jobDispatcher queue.JobDispatcher
@@ -43,6 +45,8 @@ type AuthService struct {
 func NewAuthService(
 	userRepo *repositories.UserRepository,
 	roleRepo *repositories.RolePermissionRepository,
+	householdRepo *repositories.HouseholdRepository,
+	delegRepo *repositories.DelegationRepository,
 	settingsRepo *repositories.SettingsRepository,
 	loggingRepo *repositories.LoggingRepository,
 	jobDispatcher queue.JobDispatcher,
@@ -52,6 +56,8 @@ func NewAuthService(
 	return &AuthService{
 		userRepo:      userRepo,
 		roleRepo:      roleRepo,
+		householdRepo: householdRepo,
+		delegRepo:     delegRepo,
 		settingsRepo:  settingsRepo,
 		loggingRepo:   loggingRepo,
 		jobDispatcher: jobDispatcher,
@@ -356,6 +362,25 @@ func (s *AuthService) SignUp(ctx context.Context, form models.RegisterForm, user
 			return 0, err
 		}
 
+		if invitation != nil && invitation.HouseholdID != nil {
+			_, err = s.householdRepo.InsertMember(ctx, tx, &models.HouseholdMember{
+				HouseholdID: *invitation.HouseholdID,
+				UserID:      userID,
+				Role:        models.HouseholdRoleMember,
+			})
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+
+		if invitation != nil && invitation.DelegationID != nil {
+			if err := s.delegRepo.LinkDelegate(ctx, tx, *invitation.DelegationID, userID); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+
 		if invitation != nil {
 			err = s.userRepo.DeleteInvitation(ctx, tx, invitation.ID)
 			if err != nil {
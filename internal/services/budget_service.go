@@ -0,0 +1,488 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type BudgetServiceInterface interface {
+	FetchBudgets(ctx context.Context, userID int64) ([]models.CategoryBudget, error)
+	InsertBudget(ctx context.Context, userID int64, req *models.CategoryBudgetReq) (int64, error)
+	UpdateBudget(ctx context.Context, userID, id int64, req *models.CategoryBudgetReq) (int64, error)
+	DeleteBudget(ctx context.Context, userID, id int64) error
+	SaveBudgetMonth(ctx context.Context, userID, id int64, req *models.CategoryBudgetMonthReq) error
+	DeleteBudgetMonth(ctx context.Context, userID, id int64, month time.Time) error
+
+	FetchBudgetReport(ctx context.Context, userID int64, month time.Time) (*models.BudgetReport, error)
+}
+
+type BudgetService struct {
+	repo          repositories.BudgetRepositoryInterface
+	txnRepo       repositories.TransactionRepositoryInterface
+	analyticsRepo repositories.AnalyticsRepositoryInterface
+	loggingRepo   repositories.LoggingRepositoryInterface
+	jobDispatcher queue.JobDispatcher
+}
+
+func NewBudgetService(
+	repo *repositories.BudgetRepository,
+	txnRepo *repositories.TransactionRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+) *BudgetService {
+	return &BudgetService{
+		repo:          repo,
+		txnRepo:       txnRepo,
+		analyticsRepo: analyticsRepo,
+		loggingRepo:   loggingRepo,
+		jobDispatcher: jobDispatcher,
+	}
+}
+
+var _ BudgetServiceInterface = (*BudgetService)(nil)
+
+func (s *BudgetService) FetchBudgets(ctx context.Context, userID int64) ([]models.CategoryBudget, error) {
+	return s.repo.FindBudgets(ctx, nil, userID)
+}
+
+// checkExpenseScope makes sure exactly one expense category or expense group
+// owned by, or shared with, the user was picked. Budgets and spending limits
+// both apply to one of the two; noun and verb word the errors for each, e.g.
+// "budget" and "budgeted".
+func checkExpenseScope(ctx context.Context, txnRepo repositories.TransactionRepositoryInterface, tx *gorm.DB, userID int64, categoryID, groupID *int64, noun, verb string) error {
+	if (categoryID == nil) == (groupID == nil) {
+		return fmt.Errorf("a %s applies to either one category or one category group", noun)
+	}
+
+	if categoryID != nil {
+		cat, err := txnRepo.FindCategoryByID(ctx, tx, *categoryID, &userID, false)
+		if err != nil {
+			return fmt.Errorf("category not found: %w", err)
+		}
+		if cat.ParentID == nil {
+			return fmt.Errorf("%ss go on a category, not on a whole classification", noun)
+		}
+		if cat.Classification != "expense" {
+			return fmt.Errorf("only expense categories can be %s", verb)
+		}
+		return nil
+	}
+
+	group, err := txnRepo.FindCategoryGroupByID(ctx, tx, *groupID, userID)
+	if err != nil || group.ID == 0 {
+		return fmt.Errorf("category group not found")
+	}
+	if group.Classification != "expense" {
+		return fmt.Errorf("only expense groups can be %s", verb)
+	}
+	return nil
+}
+
+// parseBudgetMonth reads a YYYY-MM-DD date and returns the first of its month.
+func parseBudgetMonth(value string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month: %w", err)
+	}
+	return utils.MonthStart(parsed), nil
+}
+
+func budgetFromReq(req *models.CategoryBudgetReq) (models.CategoryBudget, error) {
+	if req.Amount.IsNegative() {
+		return models.CategoryBudget{}, fmt.Errorf("budget amount can't be negative")
+	}
+
+	record := models.CategoryBudget{
+		CategoryID: req.CategoryID,
+		GroupID:    req.GroupID,
+		Amount:     req.Amount,
+		Rollover:   req.Rollover,
+		StartMonth: utils.MonthStart(time.Now().UTC()),
+		IsActive:   true,
+	}
+	if record.Rollover == "" {
+		record.Rollover = models.CategoryBudgetRolloverNone
+	}
+	if req.StartMonth != nil && *req.StartMonth != "" {
+		start, err := parseBudgetMonth(*req.StartMonth)
+		if err != nil {
+			return models.CategoryBudget{}, err
+		}
+		record.StartMonth = start
+	}
+	if req.IsActive != nil {
+		record.IsActive = *req.IsActive
+	}
+	return record, nil
+}
+
+var budgetLogFields = []string{"scope", "scope_id", "amount", "rollover", "start_month", "is_active"}
+
+// budgetFields flattens a budget for the activity log; a nil budget has no fields.
+func budgetFields(b *models.CategoryBudget) map[string]string {
+	fields := map[string]string{}
+	if b == nil {
+		return fields
+	}
+	if b.CategoryID != nil {
+		fields["scope"] = "category"
+		fields["scope_id"] = strconv.FormatInt(*b.CategoryID, 10)
+	} else if b.GroupID != nil {
+		fields["scope"] = "group"
+		fields["scope_id"] = strconv.FormatInt(*b.GroupID, 10)
+	}
+	fields["amount"] = b.Amount.StringFixed(2)
+	fields["rollover"] = string(b.Rollover)
+	fields["start_month"] = b.StartMonth.Format("2006-01-02")
+	fields["is_active"] = strconv.FormatBool(b.IsActive)
+	return fields
+}
+
+func budgetChanges(old, new *models.CategoryBudget, changes *utils.Changes) {
+	oldFields, newFields := budgetFields(old), budgetFields(new)
+	for _, key := range budgetLogFields {
+		utils.CompareChanges(oldFields[key], newFields[key], changes, key)
+	}
+}
+
+func (s *BudgetService) InsertBudget(ctx context.Context, userID int64, req *models.CategoryBudgetReq) (int64, error) {
+	record, err := budgetFromReq(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkExpenseScope(ctx, s.txnRepo, nil, userID, req.CategoryID, req.GroupID, "budget", "budgeted"); err != nil {
+		return 0, err
+	}
+
+	record.UserID = userID
+	id, err := s.repo.InsertBudget(ctx, nil, &record)
+	if err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	budgetChanges(nil, &record, changes)
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "category_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateBudget changes the amount, rollover and start of a budget. What it
+// covers is fixed; budgeting something else means a new budget.
+func (s *BudgetService) UpdateBudget(ctx context.Context, userID, id int64, req *models.CategoryBudgetReq) (int64, error) {
+	record, err := budgetFromReq(req)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindBudgetByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("budget not found: %w", err)
+	}
+
+	if optionalID(req.CategoryID) != optionalID(existing.CategoryID) || optionalID(req.GroupID) != optionalID(existing.GroupID) {
+		tx.Rollback()
+		return 0, fmt.Errorf("a budget's category or group can't be changed")
+	}
+
+	record.ID = existing.ID
+	record.UserID = existing.UserID
+	if req.StartMonth == nil || *req.StartMonth == "" {
+		record.StartMonth = existing.StartMonth
+	}
+
+	changes := utils.InitChanges()
+	budgetChanges(&existing, &record, changes)
+
+	if _, err := s.repo.UpdateBudget(ctx, tx, record); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "update",
+			Category:    "category_budget",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return record.ID, nil
+}
+
+func (s *BudgetService) DeleteBudget(ctx context.Context, userID, id int64) error {
+	existing, err := s.repo.FindBudgetByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("budget not found: %w", err)
+	}
+
+	if err := s.repo.DeleteBudget(ctx, nil, existing.ID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(existing.ID, 10), "", changes, "id")
+	budgetChanges(&existing, nil, changes)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "category_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// SaveBudgetMonth sets the budget's amount for one month, leaving the default
+// amount in place for every other month.
+func (s *BudgetService) SaveBudgetMonth(ctx context.Context, userID, id int64, req *models.CategoryBudgetMonthReq) error {
+	if req.Amount.IsNegative() {
+		return fmt.Errorf("budget amount can't be negative")
+	}
+
+	month, err := parseBudgetMonth(req.Month)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.repo.FindBudgetByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("budget not found: %w", err)
+	}
+
+	before := budgetAmountFor(existing, month)
+	if err := s.repo.UpsertBudgetMonth(ctx, nil, &models.CategoryBudgetMonth{
+		BudgetID: existing.ID,
+		Month:    month,
+		Amount:   req.Amount,
+	}); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareDecimalChange(&before, &req.Amount, changes, "amount", 2)
+	if !changes.HasChanges() {
+		return nil
+	}
+	changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+	changes.Stamp("month", month.Format("2006-01-02"))
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "category_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// DeleteBudgetMonth drops a month's own amount so the default applies again.
+func (s *BudgetService) DeleteBudgetMonth(ctx context.Context, userID, id int64, month time.Time) error {
+	month = utils.MonthStart(month)
+
+	existing, err := s.repo.FindBudgetByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("budget not found: %w", err)
+	}
+
+	before := budgetAmountFor(existing, month)
+	if err := s.repo.DeleteBudgetMonth(ctx, nil, existing.ID, month); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareDecimalChange(&before, &existing.Amount, changes, "amount", 2)
+	if !changes.HasChanges() {
+		return nil
+	}
+	changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+	changes.Stamp("month", month.Format("2006-01-02"))
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "category_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// budgetAmountFor is what the budget plans for month: its own amount for that
+// month if one was set, the default otherwise.
+func budgetAmountFor(b models.CategoryBudget, month time.Time) decimal.Decimal {
+	for _, m := range b.Months {
+		if m.Month.Year() == month.Year() && m.Month.Month() == month.Month() {
+			return m.Amount
+		}
+	}
+	return b.Amount
+}
+
+// monthsBetween counts whole months from one month start to another.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// FetchBudgetReport compares every active budget with the month's spending,
+// taken from the same category totals as the monthly analytics. Budgets that
+// roll over replay each month since they started to work out the carryover.
+func (s *BudgetService) FetchBudgetReport(ctx context.Context, userID int64, month time.Time) (*models.BudgetReport, error) {
+	month = utils.MonthStart(month)
+
+	budgets, err := s.repo.FindBudgets(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := month
+	active := make([]models.CategoryBudget, 0, len(budgets))
+	for _, b := range budgets {
+		if !b.IsActive || b.StartMonth.After(month) {
+			continue
+		}
+		active = append(active, b)
+		if b.Rollover != models.CategoryBudgetRolloverNone && b.StartMonth.Before(from) {
+			from = b.StartMonth
+		}
+	}
+
+	// spending[i] is per-category spending i months after from
+	spending := make([]map[int64]decimal.Decimal, monthsBetween(from, month)+1)
+	for i := range spending {
+		m := from.AddDate(0, i, 0)
+		rows, err := s.analyticsRepo.FetchMonthlyCategoryTotals(ctx, nil, userID, nil, m.Year(), int(m.Month()))
+		if err != nil {
+			return nil, err
+		}
+		spending[i] = utils.CategoryOutflows(rows)
+	}
+
+	categories, err := s.txnRepo.FindAllCategories(ctx, nil, &userID, true)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.DisplayName
+	}
+
+	groups, err := s.txnRepo.FindAllCategoryGroups(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	groupsByID := make(map[int64]models.CategoryGroup, len(groups))
+	for _, g := range groups {
+		groupsByID[g.ID] = g
+	}
+
+	report := &models.BudgetReport{Month: month, Lines: make([]models.BudgetReportLine, 0, len(active))}
+	covered := make(map[int64]bool)
+	current := spending[len(spending)-1]
+
+	for _, b := range active {
+		line := models.BudgetReportLine{
+			BudgetID:   b.ID,
+			CategoryID: b.CategoryID,
+			GroupID:    b.GroupID,
+			Rollover:   b.Rollover,
+		}
+
+		var categoryIDs []int64
+		if b.CategoryID != nil {
+			categoryIDs = []int64{*b.CategoryID}
+			line.Name = categoryNames[*b.CategoryID]
+		} else if g, ok := groupsByID[*b.GroupID]; ok {
+			for _, c := range g.Categories {
+				categoryIDs = append(categoryIDs, c.ID)
+			}
+			line.Name = g.Name
+		}
+		for _, id := range categoryIDs {
+			covered[id] = true
+		}
+
+		spentIn := func(byCategory map[int64]decimal.Decimal) decimal.Decimal {
+			total := decimal.Zero
+			for _, id := range categoryIDs {
+				total = total.Add(byCategory[id])
+			}
+			return total
+		}
+
+		if b.Rollover != models.CategoryBudgetRolloverNone {
+			history := make([]utils.BudgetMonth, 0, monthsBetween(b.StartMonth, month))
+			for m := b.StartMonth; m.Before(month); m = m.AddDate(0, 1, 0) {
+				history = append(history, utils.BudgetMonth{
+					Planned: budgetAmountFor(b, m),
+					Spent:   spentIn(spending[monthsBetween(from, m)]),
+				})
+			}
+			line.Carryover = utils.BudgetCarryover(b.Rollover, history)
+		}
+
+		line.Planned = budgetAmountFor(b, month)
+		line.Available = line.Planned.Add(line.Carryover)
+		line.Spent = spentIn(current)
+		line.Remaining = line.Available.Sub(line.Spent)
+		if line.Available.IsPositive() {
+			line.PercentUsed = line.Spent.Div(line.Available).Mul(decimal.NewFromInt(100)).Round(2)
+		}
+
+		report.Lines = append(report.Lines, line)
+	}
+
+	for id, spent := range current {
+		if !covered[id] {
+			report.Unbudgeted = report.Unbudgeted.Add(spent)
+		}
+	}
+
+	return report, nil
+}
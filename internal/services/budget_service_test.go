@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type BudgetServiceTestSuite struct {
+	tests.ServiceIntegrationSuite
+}
+
+func TestBudgetServiceSuite(t *testing.T) {
+	suite.Run(t, new(BudgetServiceTestSuite))
+}
+
+func (s *BudgetServiceTestSuite) expenseCategory() models.Category {
+	var cat models.Category
+	err := s.TC.DB.WithContext(s.Ctx).
+		Where("classification = ? AND parent_id IS NOT NULL AND user_id IS NULL AND deleted_at IS NULL", "expense").
+		Order("id ASC").
+		First(&cat).Error
+	s.Require().NoError(err, "expected a default expense category")
+	return cat
+}
+
+func (s *BudgetServiceTestSuite) TestInsertBudget_RejectsScope() {
+	svc := s.TC.App.BudgetService
+	userID := int64(1)
+	cat := s.expenseCategory()
+	groupID := int64(1)
+
+	_, err := svc.InsertBudget(s.Ctx, userID, &models.CategoryBudgetReq{
+		Amount: decimal.NewFromInt(100),
+	})
+	s.Require().Error(err)
+
+	_, err = svc.InsertBudget(s.Ctx, userID, &models.CategoryBudgetReq{
+		CategoryID: &cat.ID,
+		GroupID:    &groupID,
+		Amount:     decimal.NewFromInt(100),
+	})
+	s.Require().Error(err)
+
+	_, err = svc.InsertBudget(s.Ctx, userID, &models.CategoryBudgetReq{
+		CategoryID: cat.ParentID,
+		Amount:     decimal.NewFromInt(100),
+	})
+	s.Require().Error(err, "a classification root can't be budgeted")
+}
+
+func (s *BudgetServiceTestSuite) TestFetchBudgetReport_RollsOverUnspent() {
+	svc := s.TC.App.BudgetService
+	accSvc := s.TC.App.AccountService
+	txnSvc := s.TC.App.TransactionService
+	userID := int64(1)
+	cat := s.expenseCategory()
+
+	thisMonth := utils.MonthStart(time.Now().UTC())
+	twoAgo := thisMonth.AddDate(0, -2, 0)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+
+	balance := decimal.NewFromInt(10000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Budget Account",
+		AccountTypeID:  1,
+		Type:           "asset",
+		Subtype:        "cash",
+		Classification: "current",
+		Balance:        &balance,
+		OpenedAt:       twoAgo.AddDate(0, 0, -5),
+	})
+	s.Require().NoError(err)
+
+	spend := func(date time.Time, amount int64) {
+		_, err := txnSvc.InsertTransaction(s.Ctx, userID, &models.TransactionReq{
+			AccountID:       accID,
+			CategoryID:      &cat.ID,
+			TransactionType: "expense",
+			Amount:          decimal.NewFromInt(amount),
+			TxnDate:         date,
+		})
+		s.Require().NoError(err)
+	}
+	spend(twoAgo.AddDate(0, 0, 9), 60)
+	spend(lastMonth.AddDate(0, 0, 9), 130)
+	spend(thisMonth, 50)
+
+	start := twoAgo.Format("2006-01-02")
+	budgetID, err := svc.InsertBudget(s.Ctx, userID, &models.CategoryBudgetReq{
+		CategoryID: &cat.ID,
+		Amount:     decimal.NewFromInt(100),
+		Rollover:   models.CategoryBudgetRolloverUnspent,
+		StartMonth: &start,
+	})
+	s.Require().NoError(err)
+
+	report, err := svc.FetchBudgetReport(s.Ctx, userID, thisMonth)
+	s.Require().NoError(err)
+
+	var line *models.BudgetReportLine
+	for i := range report.Lines {
+		if report.Lines[i].BudgetID == budgetID {
+			line = &report.Lines[i]
+		}
+	}
+	s.Require().NotNil(line)
+
+	// 40 left two months ago, 140 - 130 leaves 10 to carry into this month
+	s.True(decimal.NewFromInt(10).Equal(line.Carryover), "carryover %s", line.Carryover)
+	s.True(decimal.NewFromInt(110).Equal(line.Available))
+	s.True(decimal.NewFromInt(50).Equal(line.Spent))
+	s.True(decimal.NewFromInt(60).Equal(line.Remaining))
+
+	// A per-month amount replaces the default for that month only
+	s.Require().NoError(svc.SaveBudgetMonth(s.Ctx, userID, budgetID, &models.CategoryBudgetMonthReq{
+		Month:  lastMonth.Format("2006-01-02"),
+		Amount: decimal.NewFromInt(200),
+	}))
+
+	report, err = svc.FetchBudgetReport(s.Ctx, userID, thisMonth)
+	s.Require().NoError(err)
+	for _, l := range report.Lines {
+		if l.BudgetID == budgetID {
+			// 40 + 200 - 130
+			s.True(decimal.NewFromInt(110).Equal(l.Carryover), "carryover %s", l.Carryover)
+			s.True(decimal.NewFromInt(100).Equal(l.Planned))
+		}
+	}
+}
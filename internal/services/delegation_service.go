@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/authz"
+	"wealth-warden/pkg/mailer"
+	"wealth-warden/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// maxDelegationDuration caps how far ahead a delegation may expire.
+const maxDelegationDuration = 366 * 24 * time.Hour
+
+type DelegationServiceInterface interface {
+	FetchDelegations(ctx context.Context, userID int64) ([]models.AccessDelegation, error)
+	FetchReceivedDelegations(ctx context.Context, userID int64) ([]models.AccessDelegation, error)
+	InsertDelegation(ctx context.Context, userID int64, req *models.AccessDelegationReq) (int64, error)
+	UpdateDelegation(ctx context.Context, userID, id int64, req *models.AccessDelegationUpdateReq) error
+	RevokeDelegation(ctx context.Context, userID, id int64) error
+	LogDelegateAccess(ctx context.Context, scope *authz.DelegationScope, method, path string, status int) error
+}
+
+type DelegationService struct {
+	repo          repositories.DelegationRepositoryInterface
+	userRepo      repositories.UserRepositoryInterface
+	roleRepo      repositories.RolePermissionRepositoryInterface
+	accountRepo   repositories.AccountRepositoryInterface
+	loggingRepo   repositories.LoggingRepositoryInterface
+	jobDispatcher queue.JobDispatcher
+	mailer        *mailer.Mailer
+}
+
+func NewDelegationService(
+	repo *repositories.DelegationRepository,
+	userRepo *repositories.UserRepository,
+	roleRepo *repositories.RolePermissionRepository,
+	accountRepo *repositories.AccountRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+	mailer *mailer.Mailer,
+) *DelegationService {
+	return &DelegationService{
+		repo:          repo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		accountRepo:   accountRepo,
+		loggingRepo:   loggingRepo,
+		jobDispatcher: jobDispatcher,
+		mailer:        mailer,
+	}
+}
+
+var _ DelegationServiceInterface = (*DelegationService)(nil)
+
+func validateDelegationScope(expiresAt time.Time, modules []models.DelegationModule) error {
+	now := time.Now().UTC()
+	if !expiresAt.After(now) {
+		return fmt.Errorf("expiry must be in the future")
+	}
+	if expiresAt.After(now.Add(maxDelegationDuration)) {
+		return fmt.Errorf("delegations can last at most one year")
+	}
+
+	for _, m := range modules {
+		switch m {
+		case models.DelegationModuleAccounts, models.DelegationModuleTransactions,
+			models.DelegationModuleInvestments, models.DelegationModuleTax,
+			models.DelegationModuleAnalytics, models.DelegationModuleSavings:
+		default:
+			return fmt.Errorf("invalid module: %s", m)
+		}
+	}
+	return nil
+}
+
+func dedupeModules(modules []models.DelegationModule) []models.DelegationModule {
+	seen := make(map[models.DelegationModule]bool, len(modules))
+	out := make([]models.DelegationModule, 0, len(modules))
+	for _, m := range modules {
+		if !seen[m] {
+			seen[m] = true
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *DelegationService) ensureOwnAccounts(ctx context.Context, tx *gorm.DB, userID int64, accountIDs []int64) ([]int64, error) {
+	seen := make(map[int64]bool, len(accountIDs))
+	out := make([]int64, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if seen[id] {
+			continue
+		}
+		if _, err := s.accountRepo.FindAccountByID(ctx, tx, id, userID, false, true); err != nil {
+			return nil, fmt.Errorf("account not found: %w", err)
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+func moduleNames(modules []models.DelegationModule) string {
+	if len(modules) == 0 {
+		return "all"
+	}
+	names := make([]string, len(modules))
+	for i, m := range modules {
+		names[i] = string(m)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func accountIDList(ids []int64) string {
+	if len(ids) == 0 {
+		return "all"
+	}
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, len(sorted))
+	for i, id := range sorted {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s *DelegationService) FetchDelegations(ctx context.Context, userID int64) ([]models.AccessDelegation, error) {
+	return s.repo.FindDelegationsByOwner(ctx, nil, userID)
+}
+
+func (s *DelegationService) FetchReceivedDelegations(ctx context.Context, userID int64) ([]models.AccessDelegation, error) {
+	return s.repo.FindActiveDelegationsForDelegate(ctx, nil, userID)
+}
+
+func (s *DelegationService) InsertDelegation(ctx context.Context, userID int64, req *models.AccessDelegationReq) (int64, error) {
+	if err := validateDelegationScope(req.ExpiresAt, req.Modules); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	owner, err := s.userRepo.FindUserByID(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if strings.EqualFold(owner.Email, email) {
+		tx.Rollback()
+		return 0, fmt.Errorf("access cannot be delegated to yourself")
+	}
+
+	if _, err := s.repo.FindActiveDelegationByEmail(ctx, tx, userID, email); err == nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("an active delegation for this email already exists")
+	}
+
+	accountIDs, err := s.ensureOwnAccounts(ctx, tx, userID, req.AccountIDs)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	modules := dedupeModules(req.Modules)
+
+	record := &models.AccessDelegation{
+		OwnerID:   userID,
+		Email:     email,
+		Label:     req.Label,
+		ExpiresAt: req.ExpiresAt.UTC(),
+	}
+
+	delegate, _ := s.userRepo.FindUserByEmail(ctx, tx, email)
+	if delegate != nil {
+		record.DelegateID = &delegate.ID
+	}
+
+	id, err := s.repo.InsertDelegation(ctx, tx, record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := s.repo.ReplaceScope(ctx, tx, id, modules, accountIDs); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// external delegates get an invitation; the delegation links to them on sign up
+	var hash string
+	if delegate == nil {
+		role, err := s.roleRepo.FindRoleByName(ctx, tx, "member")
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("can't find member role: %w", err)
+		}
+
+		hash, err = utils.GenerateSecureToken(64)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if _, err := s.userRepo.InsertInvitation(ctx, tx, &models.Invitation{
+			Email:        email,
+			Hash:         hash,
+			RoleID:       role.ID,
+			DelegationID: &id,
+		}); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	expiresAt := record.ExpiresAt
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	utils.CompareChanges("", email, changes, "email")
+	utils.CompareChanges("", utils.SafeString(req.Label), changes, "label")
+	utils.CompareDateChange(nil, &expiresAt, changes, "expires_at")
+	utils.CompareChanges("", moduleNames(modules), changes, "modules")
+	utils.CompareChanges("", accountIDList(accountIDs), changes, "accounts")
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "delegation",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	if hash != "" && s.mailer != nil {
+		if err := s.mailer.SendRegistrationEmail(email, utils.EmailToName(email), hash); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+func (s *DelegationService) UpdateDelegation(ctx context.Context, userID, id int64, req *models.AccessDelegationUpdateReq) error {
+	if err := validateDelegationScope(req.ExpiresAt, req.Modules); err != nil {
+		return err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindDelegationByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delegation not found: %w", err)
+	}
+	if existing.RevokedAt != nil {
+		tx.Rollback()
+		return fmt.Errorf("revoked delegations cannot be changed")
+	}
+
+	accountIDs, err := s.ensureOwnAccounts(ctx, tx, userID, req.AccountIDs)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	modules := dedupeModules(req.Modules)
+
+	updated := existing
+	updated.Label = req.Label
+	updated.ExpiresAt = req.ExpiresAt.UTC()
+
+	if err := s.repo.UpdateDelegation(ctx, tx, &updated); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := s.repo.ReplaceScope(ctx, tx, id, modules, accountIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	oldModules := make([]models.DelegationModule, len(existing.Modules))
+	for i, m := range existing.Modules {
+		oldModules[i] = m.Module
+	}
+	oldAccounts := make([]int64, len(existing.Accounts))
+	for i, a := range existing.Accounts {
+		oldAccounts[i] = a.AccountID
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(utils.SafeString(existing.Label), utils.SafeString(req.Label), changes, "label")
+	utils.CompareDateChange(&existing.ExpiresAt, &updated.ExpiresAt, changes, "expires_at")
+	utils.CompareChanges(moduleNames(oldModules), moduleNames(modules), changes, "modules")
+	utils.CompareChanges(accountIDList(oldAccounts), accountIDList(accountIDs), changes, "accounts")
+	if changes.IsEmpty() {
+		return nil
+	}
+	changes.Stamp("id", strconv.FormatInt(id, 10))
+	changes.Stamp("email", existing.Email)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "delegation",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *DelegationService) RevokeDelegation(ctx context.Context, userID, id int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindDelegationByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delegation not found: %w", err)
+	}
+	if existing.RevokedAt != nil {
+		tx.Rollback()
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.RevokeDelegation(ctx, tx, id, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareDateChange(nil, &now, changes, "revoked_at")
+	changes.Stamp("id", strconv.FormatInt(id, 10))
+	changes.Stamp("email", existing.Email)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "revoke",
+		Category:    "delegation",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// LogDelegateAccess writes one activity log row per request a delegate makes on the
+// owner's data, with the delegate as causer.
+func (s *DelegationService) LogDelegateAccess(ctx context.Context, scope *authz.DelegationScope, method, path string, status int) error {
+	now := time.Now().UTC()
+	if err := s.repo.TouchLastAccessed(ctx, nil, scope.ID, now); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	changes.Stamp("delegation_id", strconv.FormatInt(scope.ID, 10))
+	changes.Stamp("owner_id", strconv.FormatInt(scope.OwnerID, 10))
+	changes.Stamp("method", method)
+	changes.Stamp("path", path)
+	changes.Stamp("status", strconv.Itoa(status))
+
+	event := "access"
+	if status == http.StatusForbidden {
+		event = "access_denied"
+	}
+
+	delegateID := scope.DelegateID
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       event,
+		Category:    "delegation",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &delegateID,
+	})
+}
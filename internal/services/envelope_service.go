@@ -0,0 +1,717 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type EnvelopeServiceInterface interface {
+	FetchEnvelopeBudget(ctx context.Context, userID int64) (*models.EnvelopeBudget, error)
+	EnableEnvelopeMode(ctx context.Context, userID int64, req *models.EnvelopeModeReq) error
+	DisableEnvelopeMode(ctx context.Context, userID int64) error
+
+	FetchEnvelopes(ctx context.Context, userID int64) ([]models.Envelope, error)
+	InsertEnvelope(ctx context.Context, userID int64, req *models.EnvelopeReq) (int64, error)
+	UpdateEnvelope(ctx context.Context, userID, id int64, req *models.EnvelopeReq) (int64, error)
+	DeleteEnvelope(ctx context.Context, userID, id int64) error
+
+	FetchMonth(ctx context.Context, userID int64, month time.Time) (*models.EnvelopeMonth, error)
+	Assign(ctx context.Context, userID int64, req *models.EnvelopeAssignReq) error
+	Move(ctx context.Context, userID int64, req *models.EnvelopeMoveReq) error
+	Cover(ctx context.Context, userID int64, req *models.EnvelopeCoverReq) error
+}
+
+type EnvelopeService struct {
+	repo          repositories.EnvelopeRepositoryInterface
+	txnRepo       repositories.TransactionRepositoryInterface
+	analyticsRepo repositories.AnalyticsRepositoryInterface
+	settingsRepo  repositories.SettingsRepositoryInterface
+	loggingRepo   repositories.LoggingRepositoryInterface
+	jobDispatcher queue.JobDispatcher
+}
+
+func NewEnvelopeService(
+	repo *repositories.EnvelopeRepository,
+	txnRepo *repositories.TransactionRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	settingsRepo *repositories.SettingsRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+) *EnvelopeService {
+	return &EnvelopeService{
+		repo:          repo,
+		txnRepo:       txnRepo,
+		analyticsRepo: analyticsRepo,
+		settingsRepo:  settingsRepo,
+		loggingRepo:   loggingRepo,
+		jobDispatcher: jobDispatcher,
+	}
+}
+
+var _ EnvelopeServiceInterface = (*EnvelopeService)(nil)
+
+// FetchEnvelopeBudget returns nil when the user doesn't budget with envelopes.
+func (s *EnvelopeService) FetchEnvelopeBudget(ctx context.Context, userID int64) (*models.EnvelopeBudget, error) {
+	record, err := s.repo.FindEnvelopeBudget(ctx, nil, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// EnableEnvelopeMode turns envelope budgeting on from the given month, or
+// moves its start when it's already on. Balances are worked out from the
+// start month, so moving it replays history from there.
+func (s *EnvelopeService) EnableEnvelopeMode(ctx context.Context, userID int64, req *models.EnvelopeModeReq) error {
+	start, err := parseBudgetMonth(req.StartMonth)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.FetchEnvelopeBudget(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpsertEnvelopeBudget(ctx, nil, &models.EnvelopeBudget{UserID: userID, StartMonth: start}); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	if existing != nil {
+		utils.CompareDateChange(&existing.StartMonth, &start, changes, "start_month")
+	} else {
+		utils.CompareDateChange(nil, &start, changes, "start_month")
+	}
+	if !changes.HasChanges() {
+		return nil
+	}
+
+	event := "create"
+	if existing != nil {
+		event = "update"
+	}
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       event,
+		Category:    "envelope_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// DisableEnvelopeMode turns envelope budgeting off. Envelopes and their
+// movements are kept, so turning it back on picks up where it left off.
+func (s *EnvelopeService) DisableEnvelopeMode(ctx context.Context, userID int64) error {
+	existing, err := s.FetchEnvelopeBudget(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := s.repo.DeleteEnvelopeBudget(ctx, nil, userID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareDateChange(&existing.StartMonth, nil, changes, "start_month")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "envelope_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *EnvelopeService) FetchEnvelopes(ctx context.Context, userID int64) ([]models.Envelope, error) {
+	return s.repo.FindEnvelopes(ctx, nil, userID)
+}
+
+// checkEnvelopeCategories makes sure every category can be spent from an
+// envelope and isn't already part of another one.
+func (s *EnvelopeService) checkEnvelopeCategories(ctx context.Context, tx *gorm.DB, userID, envelopeID int64, categoryIDs []int64) error {
+	for _, id := range categoryIDs {
+		cat, err := s.txnRepo.FindCategoryByID(ctx, tx, id, &userID, false)
+		if err != nil {
+			return fmt.Errorf("category %d not found: %w", id, err)
+		}
+		if cat.ParentID == nil || cat.Classification != "expense" {
+			return fmt.Errorf("only expense categories can go in an envelope, %s can't", cat.DisplayName)
+		}
+	}
+
+	links, err := s.repo.FindEnvelopeOfCategories(ctx, tx, userID, categoryIDs)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.EnvelopeID != envelopeID {
+			return fmt.Errorf("category %d already belongs to another envelope", l.CategoryID)
+		}
+	}
+	return nil
+}
+
+// uniqueIDs drops duplicates while keeping the original order.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func envelopeCategoryList(links []models.EnvelopeCategory) string {
+	ids := make([]int64, 0, len(links))
+	for _, l := range links {
+		ids = append(ids, l.CategoryID)
+	}
+	return categoryIDList(ids)
+}
+
+func categoryIDList(ids []int64) string {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	parts := make([]string, 0, len(sorted))
+	for _, id := range sorted {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *EnvelopeService) InsertEnvelope(ctx context.Context, userID int64, req *models.EnvelopeReq) (int64, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return 0, fmt.Errorf("envelope name is required")
+	}
+	categoryIDs := uniqueIDs(req.CategoryIDs)
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := s.checkEnvelopeCategories(ctx, tx, userID, 0, categoryIDs); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	record := models.Envelope{
+		UserID:   userID,
+		Name:     name,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		record.IsActive = *req.IsActive
+	}
+
+	id, err := s.repo.InsertEnvelope(ctx, tx, &record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := s.repo.ReplaceEnvelopeCategories(ctx, tx, userID, id, categoryIDs); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	utils.CompareChanges("", record.Name, changes, "name")
+	utils.CompareChanges("", strconv.FormatBool(record.IsActive), changes, "is_active")
+	utils.CompareChanges("", categoryIDList(categoryIDs), changes, "categories")
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "envelope",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateEnvelope renames an envelope, switches it on or off and, when
+// category_ids is sent, replaces its categories.
+func (s *EnvelopeService) UpdateEnvelope(ctx context.Context, userID, id int64, req *models.EnvelopeReq) (int64, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return 0, fmt.Errorf("envelope name is required")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindEnvelopeByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("envelope not found: %w", err)
+	}
+
+	record := existing
+	record.Name = name
+	if req.IsActive != nil {
+		record.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateEnvelope(ctx, tx, record); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	oldCategories := envelopeCategoryList(existing.Categories)
+	newCategories := oldCategories
+	if req.CategoryIDs != nil {
+		categoryIDs := uniqueIDs(req.CategoryIDs)
+		if err := s.checkEnvelopeCategories(ctx, tx, userID, existing.ID, categoryIDs); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := s.repo.ReplaceEnvelopeCategories(ctx, tx, userID, existing.ID, categoryIDs); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		newCategories = categoryIDList(categoryIDs)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(existing.Name, record.Name, changes, "name")
+	utils.CompareChanges(strconv.FormatBool(existing.IsActive), strconv.FormatBool(record.IsActive), changes, "is_active")
+	utils.CompareChanges(oldCategories, newCategories, changes, "categories")
+
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "update",
+			Category:    "envelope",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return existing.ID, nil
+}
+
+// DeleteEnvelope removes an envelope with its movements. Whatever was
+// assigned to it goes back to be assigned, and spending in its categories
+// stops being covered by an envelope.
+func (s *EnvelopeService) DeleteEnvelope(ctx context.Context, userID, id int64) error {
+	existing, err := s.repo.FindEnvelopeByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+
+	if err := s.repo.DeleteEnvelope(ctx, nil, existing.ID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(existing.ID, 10), "", changes, "id")
+	utils.CompareChanges(existing.Name, "", changes, "name")
+	utils.CompareChanges(envelopeCategoryList(existing.Categories), "", changes, "categories")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "envelope",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *EnvelopeService) FetchMonth(ctx context.Context, userID int64, month time.Time) (*models.EnvelopeMonth, error) {
+	return s.monthState(ctx, nil, userID, utils.MonthStart(month))
+}
+
+// monthState replays every month from the start of envelope budgeting up to
+// month. Actuals come from the same category totals as the monthly analytics,
+// and income templates that haven't run yet count as expected income.
+func (s *EnvelopeService) monthState(ctx context.Context, tx *gorm.DB, userID int64, month time.Time) (*models.EnvelopeMonth, error) {
+	budget, err := s.repo.FindEnvelopeBudget(ctx, tx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("envelope budgeting isn't turned on")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if month.Before(budget.StartMonth) {
+		return nil, fmt.Errorf("envelope budgeting starts in %s", budget.StartMonth.Format("2006-01"))
+	}
+
+	envelopes, err := s.repo.FindEnvelopes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	envelopeOf := make(map[int64]int64)
+	for _, e := range envelopes {
+		for _, c := range e.Categories {
+			envelopeOf[c.CategoryID] = e.ID
+		}
+	}
+
+	movements, err := s.repo.FindMovements(ctx, tx, userID, budget.StartMonth, month)
+	if err != nil {
+		return nil, err
+	}
+	months := monthsBetween(budget.StartMonth, month) + 1
+	byMonth := make([][]models.EnvelopeMovement, months)
+	for _, m := range movements {
+		i := monthsBetween(budget.StartMonth, utils.MonthStart(m.Month))
+		byMonth[i] = append(byMonth[i], m)
+	}
+
+	templates, err := s.txnRepo.GetActiveTemplates(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.settingsRepo.FetchUserSettings(ctx, tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch user settings %w", err)
+	}
+	loc, _ := time.LoadLocation(settings.Timezone)
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	open := utils.EnvelopeOpening{Balances: map[int64]decimal.Decimal{}}
+	for i := 0; i < months; i++ {
+		m := budget.StartMonth.AddDate(0, i, 0)
+
+		rows, err := s.analyticsRepo.FetchMonthlyCategoryTotals(ctx, tx, userID, nil, m.Year(), int(m.Month()))
+		if err != nil {
+			return nil, err
+		}
+
+		act := utils.EnvelopeActivityFromTotals(rows, envelopeOf)
+		act.Movements = byMonth[i]
+		expected := utils.ExpectedTemplateIncome(templates, m, m.AddDate(0, 1, 0), loc)
+		for _, e := range expected {
+			act.Expected = act.Expected.Add(e.Amount)
+		}
+
+		lines, assigned, toBeAssigned := utils.StepEnvelopeMonth(open, act)
+
+		if i == months-1 {
+			state := &models.EnvelopeMonth{
+				Month:            m,
+				Income:           act.Income,
+				ExpectedIncome:   act.Expected,
+				Expected:         expected,
+				Assigned:         assigned,
+				Unenveloped:      act.Unenveloped,
+				OverspentCarried: open.Overspent,
+				ToBeAssigned:     toBeAssigned,
+				Envelopes:        make([]models.EnvelopeMonthLine, 0, len(envelopes)),
+			}
+			for _, e := range envelopes {
+				l := lines[e.ID]
+				line := models.EnvelopeMonthLine{
+					EnvelopeID: e.ID,
+					Name:       e.Name,
+					IsActive:   e.IsActive,
+					Carryover:  l.Carryover,
+					Assigned:   l.Assigned,
+					Spent:      l.Spent,
+					Available:  l.Available,
+				}
+				if l.Available.IsNegative() {
+					line.Overspent = l.Available.Neg()
+				}
+				state.Envelopes = append(state.Envelopes, line)
+			}
+			return state, nil
+		}
+
+		open = utils.NextEnvelopeOpening(lines, toBeAssigned)
+	}
+
+	return nil, fmt.Errorf("no months to budget")
+}
+
+func findEnvelopeLine(state *models.EnvelopeMonth, envelopeID int64) models.EnvelopeMonthLine {
+	for _, l := range state.Envelopes {
+		if l.EnvelopeID == envelopeID {
+			return l
+		}
+	}
+	return models.EnvelopeMonthLine{EnvelopeID: envelopeID}
+}
+
+// parseMovementMonth reads the month money is moved in. Past months are
+// closed; their leftovers have already rolled forward.
+func parseMovementMonth(value string) (time.Time, error) {
+	month, err := parseBudgetMonth(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if month.Before(utils.MonthStart(time.Now().UTC())) {
+		return time.Time{}, fmt.Errorf("money can only be moved in the current or a future month")
+	}
+	return month, nil
+}
+
+// recordMovement stores a movement once check has approved it against the
+// month's balances, worked out inside the same transaction. check may settle
+// the amount.
+func (s *EnvelopeService) recordMovement(ctx context.Context, userID int64, movement models.EnvelopeMovement, check func(state *models.EnvelopeMonth, movement *models.EnvelopeMovement) error) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	state, err := s.monthState(ctx, tx, userID, movement.Month)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := check(state, &movement); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	movement.UserID = userID
+	id, err := s.repo.InsertMovement(ctx, tx, &movement)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	utils.CompareChanges("", string(movement.Kind), changes, "kind")
+	utils.CompareChanges("", movement.Month.Format("2006-01-02"), changes, "month")
+	utils.CompareChanges("", optionalID(movement.FromEnvelopeID), changes, "from_envelope_id")
+	utils.CompareChanges("", optionalID(movement.ToEnvelopeID), changes, "to_envelope_id")
+	utils.CompareDecimalChange(nil, &movement.Amount, changes, "amount", 2)
+	if movement.Note != nil {
+		utils.CompareChanges("", *movement.Note, changes, "note")
+	}
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "envelope_movement",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// checkEnvelopeHas makes sure an envelope can give up amount.
+func checkEnvelopeHas(state *models.EnvelopeMonth, envelopeID int64, amount decimal.Decimal) error {
+	line := findEnvelopeLine(state, envelopeID)
+	if line.Available.LessThan(amount) {
+		return fmt.Errorf("%s only has %s available", line.Name, decimal.Max(line.Available, decimal.Zero).StringFixed(2))
+	}
+	return nil
+}
+
+// Assign moves money from to-be-assigned into an envelope, or back out of it
+// when the amount is negative. Expected income counts as assignable, so
+// upcoming pay can be assigned before it arrives.
+func (s *EnvelopeService) Assign(ctx context.Context, userID int64, req *models.EnvelopeAssignReq) error {
+	if req.Amount.IsZero() {
+		return fmt.Errorf("amount can't be zero")
+	}
+	month, err := parseMovementMonth(req.Month)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := s.repo.FindEnvelopeByID(ctx, nil, req.EnvelopeID, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+
+	movement := models.EnvelopeMovement{
+		Month:  month,
+		Amount: req.Amount.Abs(),
+		Kind:   models.EnvelopeMovementAssign,
+		Note:   req.Note,
+	}
+
+	if req.Amount.IsNegative() {
+		movement.FromEnvelopeID = &envelope.ID
+		return s.recordMovement(ctx, userID, movement, func(state *models.EnvelopeMonth, movement *models.EnvelopeMovement) error {
+			return checkEnvelopeHas(state, envelope.ID, movement.Amount)
+		})
+	}
+
+	if !envelope.IsActive {
+		return fmt.Errorf("%s is inactive and can't receive money", envelope.Name)
+	}
+	movement.ToEnvelopeID = &envelope.ID
+	return s.recordMovement(ctx, userID, movement, func(state *models.EnvelopeMonth, movement *models.EnvelopeMovement) error {
+		if findEnvelopeLine(state, envelope.ID).Available.IsNegative() {
+			return fmt.Errorf("%s is overspent, cover it from another envelope first", envelope.Name)
+		}
+		if state.ToBeAssigned.LessThan(movement.Amount) {
+			return fmt.Errorf("only %s is left to be assigned", decimal.Max(state.ToBeAssigned, decimal.Zero).StringFixed(2))
+		}
+		return nil
+	})
+}
+
+// Move shifts money between two envelopes.
+func (s *EnvelopeService) Move(ctx context.Context, userID int64, req *models.EnvelopeMoveReq) error {
+	if !req.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	if req.FromEnvelopeID == req.ToEnvelopeID {
+		return fmt.Errorf("money has to move between two different envelopes")
+	}
+	month, err := parseMovementMonth(req.Month)
+	if err != nil {
+		return err
+	}
+
+	from, err := s.repo.FindEnvelopeByID(ctx, nil, req.FromEnvelopeID, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+	to, err := s.repo.FindEnvelopeByID(ctx, nil, req.ToEnvelopeID, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+	if !to.IsActive {
+		return fmt.Errorf("%s is inactive and can't receive money", to.Name)
+	}
+
+	movement := models.EnvelopeMovement{
+		Month:          month,
+		FromEnvelopeID: &from.ID,
+		ToEnvelopeID:   &to.ID,
+		Amount:         req.Amount,
+		Kind:           models.EnvelopeMovementMove,
+		Note:           req.Note,
+	}
+	return s.recordMovement(ctx, userID, movement, func(state *models.EnvelopeMonth, movement *models.EnvelopeMovement) error {
+		return checkEnvelopeHas(state, from.ID, movement.Amount)
+	})
+}
+
+// Cover moves money from one envelope into an overspent one, by default just
+// enough to bring it back to zero. Overspending nobody covers comes out of
+// next month's to-be-assigned instead.
+func (s *EnvelopeService) Cover(ctx context.Context, userID int64, req *models.EnvelopeCoverReq) error {
+	if req.FromEnvelopeID == req.EnvelopeID {
+		return fmt.Errorf("an envelope can't cover itself")
+	}
+	if req.Amount != nil && !req.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	month, err := parseMovementMonth(req.Month)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.repo.FindEnvelopeByID(ctx, nil, req.EnvelopeID, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+	from, err := s.repo.FindEnvelopeByID(ctx, nil, req.FromEnvelopeID, userID)
+	if err != nil {
+		return fmt.Errorf("envelope not found: %w", err)
+	}
+
+	movement := models.EnvelopeMovement{
+		Month:          month,
+		FromEnvelopeID: &from.ID,
+		ToEnvelopeID:   &target.ID,
+		Kind:           models.EnvelopeMovementCover,
+		Note:           req.Note,
+	}
+	return s.recordMovement(ctx, userID, movement, func(state *models.EnvelopeMonth, movement *models.EnvelopeMovement) error {
+		overspent := findEnvelopeLine(state, target.ID).Overspent
+		if !overspent.IsPositive() {
+			return fmt.Errorf("%s isn't overspent", target.Name)
+		}
+
+		amount := overspent
+		if req.Amount != nil {
+			if req.Amount.GreaterThan(overspent) {
+				return fmt.Errorf("%s is only overspent by %s", target.Name, overspent.StringFixed(2))
+			}
+			amount = *req.Amount
+		}
+		if err := checkEnvelopeHas(state, from.ID, amount); err != nil {
+			return err
+		}
+
+		movement.Amount = amount
+		return nil
+	})
+}
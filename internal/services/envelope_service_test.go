@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvelopeServiceTestSuite struct {
+	tests.ServiceIntegrationSuite
+}
+
+func TestEnvelopeServiceSuite(t *testing.T) {
+	suite.Run(t, new(EnvelopeServiceTestSuite))
+}
+
+func (s *EnvelopeServiceTestSuite) leafCategories(classification string, n int) []models.Category {
+	var cats []models.Category
+	err := s.TC.DB.WithContext(s.Ctx).
+		Where("classification = ? AND parent_id IS NOT NULL AND user_id IS NULL AND deleted_at IS NULL", classification).
+		Order("id ASC").
+		Limit(n).
+		Find(&cats).Error
+	s.Require().NoError(err)
+	s.Require().Len(cats, n, "expected default %s categories", classification)
+	return cats
+}
+
+func (s *EnvelopeServiceTestSuite) TestAssignAndCoverOverspending() {
+	svc := s.TC.App.EnvelopeService
+	userID := int64(1)
+	thisMonth := utils.MonthStart(time.Now().UTC())
+	month := thisMonth.Format("2006-01-02")
+
+	expenses := s.leafCategories("expense", 2)
+	income := s.leafCategories("income", 1)[0]
+
+	s.Require().NoError(svc.EnableEnvelopeMode(s.Ctx, userID, &models.EnvelopeModeReq{StartMonth: month}))
+
+	foodID, err := svc.InsertEnvelope(s.Ctx, userID, &models.EnvelopeReq{Name: "Food", CategoryIDs: []int64{expenses[0].ID}})
+	s.Require().NoError(err)
+	funID, err := svc.InsertEnvelope(s.Ctx, userID, &models.EnvelopeReq{Name: "Fun", CategoryIDs: []int64{expenses[1].ID}})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertEnvelope(s.Ctx, userID, &models.EnvelopeReq{Name: "Dup", CategoryIDs: []int64{expenses[0].ID}})
+	s.Require().Error(err, "a category can only be in one envelope")
+
+	balance := decimal.NewFromInt(0)
+	accID, err := s.TC.App.AccountService.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Envelope Account",
+		AccountTypeID:  1,
+		Type:           "asset",
+		Subtype:        "cash",
+		Classification: "current",
+		Balance:        &balance,
+		OpenedAt:       thisMonth.AddDate(0, 0, -1),
+	})
+	s.Require().NoError(err)
+
+	txn := func(cat models.Category, kind string, amount int64) {
+		_, err := s.TC.App.TransactionService.InsertTransaction(s.Ctx, userID, &models.TransactionReq{
+			AccountID:       accID,
+			CategoryID:      &cat.ID,
+			TransactionType: kind,
+			Amount:          decimal.NewFromInt(amount),
+			TxnDate:         thisMonth,
+		})
+		s.Require().NoError(err)
+	}
+	txn(income, "income", 1000)
+
+	s.Require().NoError(svc.Assign(s.Ctx, userID, &models.EnvelopeAssignReq{Month: month, EnvelopeID: foodID, Amount: decimal.NewFromInt(600)}))
+	s.Require().NoError(svc.Assign(s.Ctx, userID, &models.EnvelopeAssignReq{Month: month, EnvelopeID: funID, Amount: decimal.NewFromInt(200)}))
+	s.Require().Error(svc.Assign(s.Ctx, userID, &models.EnvelopeAssignReq{Month: month, EnvelopeID: funID, Amount: decimal.NewFromInt(300)}),
+		"only 200 is left to be assigned")
+
+	txn(expenses[0], "expense", 700)
+
+	state, err := svc.FetchMonth(s.Ctx, userID, thisMonth)
+	s.Require().NoError(err)
+	s.True(decimal.NewFromInt(200).Equal(state.ToBeAssigned), "to be assigned %s", state.ToBeAssigned)
+
+	var food models.EnvelopeMonthLine
+	for _, l := range state.Envelopes {
+		if l.EnvelopeID == foodID {
+			food = l
+		}
+	}
+	s.True(decimal.NewFromInt(100).Equal(food.Overspent), "overspent %s", food.Overspent)
+
+	// Overspending has to be covered from another envelope, not from the pool
+	s.Require().Error(svc.Assign(s.Ctx, userID, &models.EnvelopeAssignReq{Month: month, EnvelopeID: foodID, Amount: decimal.NewFromInt(100)}))
+	s.Require().NoError(svc.Cover(s.Ctx, userID, &models.EnvelopeCoverReq{Month: month, EnvelopeID: foodID, FromEnvelopeID: funID}))
+
+	state, err = svc.FetchMonth(s.Ctx, userID, thisMonth)
+	s.Require().NoError(err)
+	for _, l := range state.Envelopes {
+		switch l.EnvelopeID {
+		case foodID:
+			s.True(l.Available.IsZero(), "food available %s", l.Available)
+		case funID:
+			s.True(decimal.NewFromInt(100).Equal(l.Available), "fun available %s", l.Available)
+		}
+	}
+	s.True(decimal.NewFromInt(200).Equal(state.ToBeAssigned))
+}
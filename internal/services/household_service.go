@@ -0,0 +1,641 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/mailer"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type HouseholdServiceInterface interface {
+	FetchHousehold(ctx context.Context, userID int64) (*models.Household, error)
+	CreateHousehold(ctx context.Context, userID int64, req *models.HouseholdReq) (int64, error)
+	RenameHousehold(ctx context.Context, userID int64, req *models.HouseholdReq) error
+	InviteMember(ctx context.Context, userID int64, req *models.HouseholdInviteReq) (int64, error)
+	FetchPendingInvitations(ctx context.Context, userID int64) ([]models.Invitation, error)
+	AcceptInvitation(ctx context.Context, userID, invitationID int64) error
+	RemoveMember(ctx context.Context, userID, memberUserID int64) error
+
+	FetchSharedAccounts(ctx context.Context, userID int64) ([]models.AccountShare, error)
+	FetchAccountShares(ctx context.Context, userID, accountID int64) ([]models.AccountShare, error)
+	ShareAccount(ctx context.Context, userID, accountID int64, req *models.AccountShareReq) error
+	UnshareAccount(ctx context.Context, userID, accountID, memberUserID int64) error
+	SetAccountOwnership(ctx context.Context, userID, accountID int64, req *models.AccountOwnershipReq) error
+}
+
+type HouseholdService struct {
+	repo          repositories.HouseholdRepositoryInterface
+	userRepo      repositories.UserRepositoryInterface
+	roleRepo      repositories.RolePermissionRepositoryInterface
+	accountRepo   repositories.AccountRepositoryInterface
+	loggingRepo   repositories.LoggingRepositoryInterface
+	jobDispatcher queue.JobDispatcher
+	mailer        *mailer.Mailer
+}
+
+func NewHouseholdService(
+	repo *repositories.HouseholdRepository,
+	userRepo *repositories.UserRepository,
+	roleRepo *repositories.RolePermissionRepository,
+	accountRepo *repositories.AccountRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+	mailer *mailer.Mailer,
+) *HouseholdService {
+	return &HouseholdService{
+		repo:          repo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		accountRepo:   accountRepo,
+		loggingRepo:   loggingRepo,
+		jobDispatcher: jobDispatcher,
+		mailer:        mailer,
+	}
+}
+
+var _ HouseholdServiceInterface = (*HouseholdService)(nil)
+
+func validateOwnershipPercent(pct *decimal.Decimal) error {
+	if pct == nil {
+		return nil
+	}
+	if pct.IsNegative() || pct.GreaterThan(decimal.NewFromInt(100)) {
+		return fmt.Errorf("ownership percent must be between 0 and 100")
+	}
+	return nil
+}
+
+func (s *HouseholdService) FetchHousehold(ctx context.Context, userID int64) (*models.Household, error) {
+	membership, err := s.repo.FindMembership(ctx, nil, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	record, err := s.repo.FindHouseholdByID(ctx, nil, membership.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *HouseholdService) CreateHousehold(ctx context.Context, userID int64, req *models.HouseholdReq) (int64, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if _, err := s.repo.FindMembership(ctx, tx, userID); err == nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("user already belongs to a household")
+	}
+
+	household := &models.Household{
+		Name:    strings.TrimSpace(req.Name),
+		OwnerID: userID,
+	}
+
+	id, err := s.repo.InsertHousehold(ctx, tx, household)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = s.repo.InsertMember(ctx, tx, &models.HouseholdMember{
+		HouseholdID: id,
+		UserID:      userID,
+		Role:        models.HouseholdRoleOwner,
+	})
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	utils.CompareChanges("", household.Name, changes, "name")
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "household",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ownedHousehold returns the household the user owns, or an error if they are not its owner.
+func (s *HouseholdService) ownedHousehold(ctx context.Context, tx *gorm.DB, userID int64) (*models.Household, error) {
+	membership, err := s.repo.FindMembership(ctx, tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("household not found: %w", err)
+	}
+	if membership.Role != models.HouseholdRoleOwner {
+		return nil, fmt.Errorf("only the household owner can perform this action")
+	}
+
+	household, err := s.repo.FindHouseholdByID(ctx, tx, membership.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("household not found: %w", err)
+	}
+	return &household, nil
+}
+
+func (s *HouseholdService) RenameHousehold(ctx context.Context, userID int64, req *models.HouseholdReq) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	household, err := s.ownedHousehold(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.repo.UpdateHouseholdName(ctx, tx, household.ID, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(household.Name, name, changes, "name")
+	if changes.IsEmpty() {
+		return nil
+	}
+	changes.Stamp("id", strconv.FormatInt(household.ID, 10))
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "household",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *HouseholdService) InviteMember(ctx context.Context, userID int64, req *models.HouseholdInviteReq) (int64, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	household, err := s.ownedHousehold(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	existingUser, _ := s.userRepo.FindUserByEmail(ctx, tx, email)
+	if existingUser != nil {
+		if _, err := s.repo.FindMembership(ctx, tx, existingUser.ID); err == nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("user already belongs to a household")
+		}
+	}
+
+	role, err := s.roleRepo.FindRoleByName(ctx, tx, "member")
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("can't find member role: %w", err)
+	}
+
+	hash, err := utils.GenerateSecureToken(64)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	invitation := &models.Invitation{
+		Email:       email,
+		Hash:        hash,
+		RoleID:      role.ID,
+		HouseholdID: &household.ID,
+	}
+
+	invID, err := s.userRepo.InsertInvitation(ctx, tx, invitation)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(invID, 10), changes, "id")
+	utils.CompareChanges("", household.Name, changes, "household")
+	utils.CompareChanges("", invitation.Email, changes, "email")
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "invitation",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	// existing users accept from within the app, new users register through the link
+	if existingUser == nil && s.mailer != nil {
+		if err := s.mailer.SendRegistrationEmail(invitation.Email, utils.EmailToName(invitation.Email), hash); err != nil {
+			return 0, err
+		}
+	}
+
+	return invID, nil
+}
+
+func (s *HouseholdService) FetchPendingInvitations(ctx context.Context, userID int64) ([]models.Invitation, error) {
+	user, err := s.userRepo.FindUserByID(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindHouseholdInvitationsForEmail(ctx, nil, user.Email)
+}
+
+func (s *HouseholdService) AcceptInvitation(ctx context.Context, userID, invitationID int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	user, err := s.userRepo.FindUserByID(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	invitation, err := s.userRepo.FindInvitationByID(ctx, tx, invitationID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("invitation not found: %w", err)
+	}
+
+	if invitation.HouseholdID == nil || !strings.EqualFold(invitation.Email, user.Email) {
+		tx.Rollback()
+		return fmt.Errorf("invitation not found")
+	}
+
+	if _, err := s.repo.FindMembership(ctx, tx, userID); err == nil {
+		tx.Rollback()
+		return fmt.Errorf("user already belongs to a household")
+	}
+
+	_, err = s.repo.InsertMember(ctx, tx, &models.HouseholdMember{
+		HouseholdID: *invitation.HouseholdID,
+		UserID:      userID,
+		Role:        models.HouseholdRoleMember,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := s.userRepo.DeleteInvitation(ctx, tx, invitation.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(*invitation.HouseholdID, 10), changes, "household_id")
+	utils.CompareChanges("", user.Email, changes, "member")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "join",
+		Category:    "household",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *HouseholdService) RemoveMember(ctx context.Context, userID, memberUserID int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	membership, err := s.repo.FindMembership(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("household not found: %w", err)
+	}
+
+	target, err := s.repo.FindMembership(ctx, tx, memberUserID)
+	if err != nil || target.HouseholdID != membership.HouseholdID {
+		tx.Rollback()
+		return fmt.Errorf("member not found")
+	}
+
+	// members may leave on their own, everyone else needs the owner
+	if userID != memberUserID && membership.Role != models.HouseholdRoleOwner {
+		tx.Rollback()
+		return fmt.Errorf("only the household owner can remove members")
+	}
+
+	event := "remove_member"
+	if target.Role == models.HouseholdRoleOwner {
+		// the owner leaving dissolves the household; members and shares cascade
+		if err := s.repo.DeleteHousehold(ctx, tx, membership.HouseholdID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		event = "delete"
+	} else {
+		if err := s.repo.DeleteSharesInvolvingUser(ctx, tx, membership.HouseholdID, memberUserID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := s.repo.DeleteMember(ctx, tx, membership.HouseholdID, memberUserID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(memberUserID, 10), "", changes, "member_id")
+	changes.Stamp("household_id", strconv.FormatInt(membership.HouseholdID, 10))
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       event,
+		Category:    "household",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *HouseholdService) FetchSharedAccounts(ctx context.Context, userID int64) ([]models.AccountShare, error) {
+	return s.repo.FindSharesForUser(ctx, nil, userID)
+}
+
+func (s *HouseholdService) FetchAccountShares(ctx context.Context, userID, accountID int64) ([]models.AccountShare, error) {
+	if _, err := s.accountRepo.FindAccountByID(ctx, nil, accountID, userID, false, true); err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+	return s.repo.FindSharesByAccountID(ctx, nil, accountID)
+}
+
+func (s *HouseholdService) ShareAccount(ctx context.Context, userID, accountID int64, req *models.AccountShareReq) error {
+	switch req.Permission {
+	case models.AccountSharePermissionView, models.AccountSharePermissionManage:
+	default:
+		return fmt.Errorf("invalid share permission: %s", req.Permission)
+	}
+	if err := validateOwnershipPercent(req.OwnershipPercent); err != nil {
+		return err
+	}
+	if req.UserID == userID {
+		return fmt.Errorf("an account cannot be shared with its owner")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	acc, err := s.accountRepo.FindAccountByID(ctx, tx, accountID, userID, false)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("account not found: %w", err)
+	}
+
+	membership, err := s.repo.FindMembership(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("household not found: %w", err)
+	}
+
+	target, err := s.repo.FindMembership(ctx, tx, req.UserID)
+	if err != nil || target.HouseholdID != membership.HouseholdID {
+		tx.Rollback()
+		return fmt.Errorf("accounts can only be shared with household members")
+	}
+
+	existing, findErr := s.repo.FindShare(ctx, tx, accountID, req.UserID)
+
+	record := &models.AccountShare{
+		AccountID:        accountID,
+		HouseholdID:      membership.HouseholdID,
+		UserID:           req.UserID,
+		Permission:       req.Permission,
+		OwnershipPercent: req.OwnershipPercent,
+	}
+
+	if err := s.repo.UpsertShare(ctx, tx, record); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	event := "create"
+	if findErr == nil {
+		event = "update"
+		utils.CompareChanges(string(existing.Permission), string(req.Permission), changes, "permission")
+		utils.CompareDecimalChange(existing.OwnershipPercent, req.OwnershipPercent, changes, "ownership_percent", 2)
+	} else {
+		utils.CompareChanges("", string(req.Permission), changes, "permission")
+		utils.CompareDecimalChange(nil, req.OwnershipPercent, changes, "ownership_percent", 2)
+	}
+	if changes.IsEmpty() {
+		return nil
+	}
+	changes.Stamp("account", acc.Name)
+	changes.Stamp("member_id", strconv.FormatInt(req.UserID, 10))
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       event,
+		Category:    "account_share",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *HouseholdService) UnshareAccount(ctx context.Context, userID, accountID, memberUserID int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	acc, err := s.accountRepo.FindAccountByID(ctx, tx, accountID, userID, false, true)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("account not found: %w", err)
+	}
+
+	existing, err := s.repo.FindShare(ctx, tx, accountID, memberUserID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("share not found: %w", err)
+	}
+
+	if err := s.repo.DeleteShare(ctx, tx, accountID, memberUserID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(string(existing.Permission), "", changes, "permission")
+	changes.Stamp("account", acc.Name)
+	changes.Stamp("member_id", strconv.FormatInt(memberUserID, 10))
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "account_share",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *HouseholdService) SetAccountOwnership(ctx context.Context, userID, accountID int64, req *models.AccountOwnershipReq) error {
+	if err := validateOwnershipPercent(req.OwnershipPercent); err != nil {
+		return err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	acc, err := s.accountRepo.FindAccountByID(ctx, tx, accountID, userID, false, true)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("account not found: %w", err)
+	}
+
+	if err := s.repo.UpdateAccountOwnership(ctx, tx, accountID, req.OwnershipPercent); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareDecimalChange(acc.OwnershipPercent, req.OwnershipPercent, changes, "ownership_percent", 2)
+	if changes.IsEmpty() {
+		return nil
+	}
+	changes.Stamp("account", acc.Name)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "account_share",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
@@ -0,0 +1,286 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type HouseholdServiceTestSuite struct {
+	tests.ServiceIntegrationSuite
+	ownerID   int64
+	memberID  int64
+	accountID int64
+}
+
+func TestHouseholdServiceSuite(t *testing.T) {
+	suite.Run(t, new(HouseholdServiceTestSuite))
+}
+
+const householdMemberEmail = "household-member@example.com"
+
+// The basic seeder only creates the root user, so the member is made once and
+// reused; users are not truncated between tests
+func (s *HouseholdServiceTestSuite) SetupTest() {
+	s.ServiceIntegrationSuite.SetupTest()
+
+	s.ownerID = 1
+	member := models.User{
+		Email:       householdMemberEmail,
+		Password:    "x",
+		DisplayName: "Member",
+		RoleID:      1,
+	}
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).
+		Where("email = ?", member.Email).
+		FirstOrCreate(&member).Error)
+	s.memberID = member.ID
+
+	balance := decimal.NewFromInt(1000)
+	var err error
+	s.accountID, err = s.TC.App.AccountService.InsertAccount(s.Ctx, s.ownerID, &models.AccountReq{
+		Name:           "Joint Checking",
+		AccountTypeID:  1,
+		Type:           "asset",
+		Subtype:        "cash",
+		Classification: "current",
+		Balance:        &balance,
+		OpenedAt:       time.Now().UTC().AddDate(0, 0, -7),
+	})
+	s.Require().NoError(err)
+}
+
+// joinHousehold creates the owner's household and has the member accept an invite into it.
+func (s *HouseholdServiceTestSuite) joinHousehold() int64 {
+	svc := s.TC.App.HouseholdService
+
+	householdID, err := svc.CreateHousehold(s.Ctx, s.ownerID, &models.HouseholdReq{Name: "Home"})
+	s.Require().NoError(err)
+
+	invID, err := svc.InviteMember(s.Ctx, s.ownerID, &models.HouseholdInviteReq{Email: householdMemberEmail})
+	s.Require().NoError(err)
+	s.Require().NoError(svc.AcceptInvitation(s.Ctx, s.memberID, invID))
+
+	return householdID
+}
+
+func (s *HouseholdServiceTestSuite) share(permission models.AccountSharePermission, pct *decimal.Decimal) {
+	s.Require().NoError(s.TC.App.HouseholdService.ShareAccount(s.Ctx, s.ownerID, s.accountID, &models.AccountShareReq{
+		UserID:           s.memberID,
+		Permission:       permission,
+		OwnershipPercent: pct,
+	}))
+}
+
+// An invite shows up for the invitee, accepting it joins the household and uses it up
+func (s *HouseholdServiceTestSuite) TestInviteAndAccept() {
+	svc := s.TC.App.HouseholdService
+
+	householdID, err := svc.CreateHousehold(s.Ctx, s.ownerID, &models.HouseholdReq{Name: "Home"})
+	s.Require().NoError(err)
+
+	_, err = svc.CreateHousehold(s.Ctx, s.ownerID, &models.HouseholdReq{Name: "Second"})
+	s.Error(err, "a user belongs to one household at a time")
+
+	invID, err := svc.InviteMember(s.Ctx, s.ownerID, &models.HouseholdInviteReq{Email: householdMemberEmail})
+	s.Require().NoError(err)
+
+	pending, err := svc.FetchPendingInvitations(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Require().Len(pending, 1)
+	s.Equal(invID, pending[0].ID)
+
+	// only the invitee can accept
+	s.Error(svc.AcceptInvitation(s.Ctx, s.ownerID, invID))
+
+	s.Require().NoError(svc.AcceptInvitation(s.Ctx, s.memberID, invID))
+
+	household, err := svc.FetchHousehold(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Require().NotNil(household)
+	s.Equal(householdID, household.ID)
+
+	pending, err = svc.FetchPendingInvitations(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Empty(pending)
+
+	s.Error(svc.AcceptInvitation(s.Ctx, s.memberID, invID), "an accepted invite is gone")
+}
+
+// A member leaving takes their shares along; the owner leaving dissolves the household
+func (s *HouseholdServiceTestSuite) TestLeaveAndDissolve() {
+	svc := s.TC.App.HouseholdService
+	s.joinHousehold()
+	s.share(models.AccountSharePermissionView, nil)
+
+	s.Error(svc.RemoveMember(s.Ctx, s.memberID, s.ownerID), "members can't remove the owner")
+
+	s.Require().NoError(svc.RemoveMember(s.Ctx, s.memberID, s.memberID))
+
+	household, err := svc.FetchHousehold(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Nil(household)
+
+	shares, err := svc.FetchSharedAccounts(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Empty(shares)
+
+	// rejoin, then the owner leaves
+	invID, err := svc.InviteMember(s.Ctx, s.ownerID, &models.HouseholdInviteReq{Email: householdMemberEmail})
+	s.Require().NoError(err)
+	s.Require().NoError(svc.AcceptInvitation(s.Ctx, s.memberID, invID))
+	s.share(models.AccountSharePermissionView, nil)
+
+	s.Require().NoError(svc.RemoveMember(s.Ctx, s.ownerID, s.ownerID))
+
+	for _, userID := range []int64{s.ownerID, s.memberID} {
+		household, err := svc.FetchHousehold(s.Ctx, userID)
+		s.Require().NoError(err)
+		s.Nil(household)
+	}
+
+	shares, err = svc.FetchSharedAccounts(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Empty(shares)
+}
+
+// Accounts are only shared inside the household, and only by their owner
+func (s *HouseholdServiceTestSuite) TestShareAccount_Scope() {
+	svc := s.TC.App.HouseholdService
+	req := &models.AccountShareReq{UserID: s.memberID, Permission: models.AccountSharePermissionView}
+
+	_, err := svc.CreateHousehold(s.Ctx, s.ownerID, &models.HouseholdReq{Name: "Home"})
+	s.Require().NoError(err)
+	s.Error(svc.ShareAccount(s.Ctx, s.ownerID, s.accountID, req), "not a member yet")
+
+	invID, err := svc.InviteMember(s.Ctx, s.ownerID, &models.HouseholdInviteReq{Email: householdMemberEmail})
+	s.Require().NoError(err)
+	s.Require().NoError(svc.AcceptInvitation(s.Ctx, s.memberID, invID))
+
+	s.Error(svc.ShareAccount(s.Ctx, s.memberID, s.accountID, &models.AccountShareReq{
+		UserID:     s.ownerID,
+		Permission: models.AccountSharePermissionView,
+	}), "only the account's owner can share it")
+
+	s.Require().NoError(svc.ShareAccount(s.Ctx, s.ownerID, s.accountID, req))
+
+	shares, err := svc.FetchSharedAccounts(s.Ctx, s.memberID)
+	s.Require().NoError(err)
+	s.Require().Len(shares, 1)
+	s.Equal(s.accountID, shares[0].AccountID)
+	s.Equal(models.AccountSharePermissionView, shares[0].Permission)
+}
+
+// The per-user snapshot view counts the owner at the account's ownership share and
+// every member at theirs
+func (s *HouseholdServiceTestSuite) TestUserSnapshotView_ScalesByOwnership() {
+	s.joinHousehold()
+
+	ownerPct := decimal.NewFromInt(60)
+	s.Require().NoError(s.TC.App.HouseholdService.SetAccountOwnership(s.Ctx, s.ownerID, s.accountID, &models.AccountOwnershipReq{
+		OwnershipPercent: &ownerPct,
+	}))
+	memberPct := decimal.NewFromInt(40)
+	s.share(models.AccountSharePermissionView, &memberPct)
+
+	type row struct {
+		UserID     int64
+		EndBalance decimal.Decimal
+	}
+	var rows []row
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Raw(`
+		SELECT user_id, end_balance
+		FROM v_user_account_daily_snapshots
+		WHERE account_id = ?
+		  AND as_of = (SELECT MAX(as_of) FROM account_daily_snapshots WHERE account_id = ?)
+	`, s.accountID, s.accountID).Scan(&rows).Error)
+
+	byUser := make(map[int64]decimal.Decimal)
+	for _, r := range rows {
+		byUser[r.UserID] = r.EndBalance
+	}
+	s.Require().Len(byUser, 2)
+	s.True(byUser[s.ownerID].Equal(decimal.NewFromInt(600)), byUser[s.ownerID].String())
+	s.True(byUser[s.memberID].Equal(decimal.NewFromInt(400)), byUser[s.memberID].String())
+}
+
+// Members with manage rights book on the owner's ledger under their own name;
+// view rights are read only
+func (s *HouseholdServiceTestSuite) TestInsertTransaction_SharedAccount() {
+	txnSvc := s.TC.App.TransactionService
+	s.joinHousehold()
+
+	req := &models.TransactionReq{
+		AccountID:       s.accountID,
+		TransactionType: "expense",
+		Amount:          decimal.NewFromInt(25),
+		TxnDate:         time.Now().UTC(),
+	}
+
+	s.share(models.AccountSharePermissionView, nil)
+	_, err := txnSvc.InsertTransaction(s.Ctx, s.memberID, req)
+	s.Error(err, "view rights can't book")
+
+	s.share(models.AccountSharePermissionManage, nil)
+	res, err := txnSvc.InsertTransaction(s.Ctx, s.memberID, req)
+	s.Require().NoError(err)
+
+	var txn models.Transaction
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", res.ID).First(&txn).Error)
+	s.Equal(s.ownerID, txn.UserID)
+	s.Require().NotNil(txn.CreatedBy)
+	s.Equal(s.memberID, *txn.CreatedBy)
+
+	accountID := s.accountID
+	p := utils.PaginationParams{PageNumber: 1, RowsPerPage: 10, SortField: "txn_date", SortOrder: "desc"}
+	txns, _, _, err := txnSvc.FetchTransactionsPaginated(s.Ctx, s.memberID, p, false, &accountID)
+	s.Require().NoError(err)
+	var ids []int64
+	for _, t := range txns {
+		ids = append(ids, t.ID)
+	}
+	s.Contains(ids, res.ID, "members read the shared account's ledger")
+}
+
+// A member's custom category maps onto the owner's category of the same name and
+// is refused when the owner has none
+func (s *HouseholdServiceTestSuite) TestInsertTransaction_SharedAccountMapsMemberCategory() {
+	txnSvc := s.TC.App.TransactionService
+	s.joinHousehold()
+	s.share(models.AccountSharePermissionManage, nil)
+
+	ownerCatID, err := txnSvc.InsertCategory(s.Ctx, s.ownerID, &models.CategoryReq{DisplayName: "Household Pets", Classification: "expense"})
+	s.Require().NoError(err)
+	memberCatID, err := txnSvc.InsertCategory(s.Ctx, s.memberID, &models.CategoryReq{DisplayName: "Household Pets", Classification: "expense"})
+	s.Require().NoError(err)
+	memberOnlyID, err := txnSvc.InsertCategory(s.Ctx, s.memberID, &models.CategoryReq{DisplayName: "Member Hobby", Classification: "expense"})
+	s.Require().NoError(err)
+
+	book := func(categoryID int64) (models.InsertResult, error) {
+		return txnSvc.InsertTransaction(s.Ctx, s.memberID, &models.TransactionReq{
+			AccountID:       s.accountID,
+			CategoryID:      &categoryID,
+			TransactionType: "expense",
+			Amount:          decimal.NewFromInt(10),
+			TxnDate:         time.Now().UTC(),
+		})
+	}
+
+	res, err := book(memberCatID)
+	s.Require().NoError(err)
+	var txn models.Transaction
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", res.ID).First(&txn).Error)
+	s.Require().NotNil(txn.CategoryID)
+	s.Equal(ownerCatID, *txn.CategoryID)
+
+	_, err = book(memberOnlyID)
+	s.Error(err, "the owner has no matching category")
+
+	_, err = book(ownerCatID)
+	s.Error(err, "the owner's own categories aren't the member's to pick")
+}
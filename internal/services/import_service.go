@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,6 +36,7 @@ type ImportServiceInterface interface {
 	TransferSavingsFromImport(ctx context.Context, userID int64, payload models.SavingTransferPayload) error
 	TransferRepaymentsFromImport(ctx context.Context, userID int64, payload models.RepaymentTransferPayload) error
 	TransferInvestmentsTrades(ctx context.Context, userID int64, txnBytes []byte, payload models.InvestmentTradesPayload) error
+	ImportBrokerStatement(ctx context.Context, userID int64, fileName string, r io.Reader, req models.BrokerImportReq) (*models.BrokerImportResult, error)
 	DeleteImport(ctx context.Context, userID, id int64) error
 	deleteTxnImport(ctx context.Context, userID int64, imp *models.Import) error
 	deleteAccImport(ctx context.Context, userID int64, imp *models.Import) error
@@ -122,6 +125,29 @@ func (s *ImportService) frontfillBalances(ctx context.Context, tx *gorm.DB, user
 	return nil
 }
 
+// checkSpendingLimits queues a spending limit check for the months the given
+// ledger dates fall in. The check reads committed totals, so it runs once the
+// import has committed.
+func (s *ImportService) checkSpendingLimits(ctx context.Context, userID int64, dates []time.Time) error {
+	var months []time.Time
+	seen := make(map[time.Time]bool)
+	for _, d := range dates {
+		month := utils.MonthStart(d)
+		if !seen[month] {
+			seen[month] = true
+			months = append(months, month)
+		}
+	}
+	if len(months) == 0 {
+		return nil
+	}
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.CheckSpendingLimitsJob{
+		UserID: userID,
+		Months: months,
+	})
+}
+
 func (s *ImportService) markImportFailed(ctx context.Context, importID int64, cause error) {
 
 	msg := ""
@@ -477,6 +503,16 @@ func (s *ImportService) ImportTransactions(ctx context.Context, userID, checkID
 		return err
 	}
 
+	var expenseDates []time.Time
+	for _, txn := range payload.Txns {
+		if txn.TransactionType == "expense" {
+			expenseDates = append(expenseDates, txn.TxnDate)
+		}
+	}
+	if err := s.checkSpendingLimits(ctx, userID, expenseDates); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -986,6 +1022,7 @@ func (s *ImportService) TransferInvestmentsFromImport(ctx context.Context, userI
 		}
 	}
 
+	var expenseDates []time.Time
 	for _, txn := range txnPayload.InvestmentTransfers {
 		if txn.TransactionType != "investments" {
 			continue
@@ -1033,6 +1070,7 @@ func (s *ImportService) TransferInvestmentsFromImport(ctx context.Context, userI
 			s.markImportFailed(ctx, payload.ImportID, err)
 			return err
 		}
+		expenseDates = append(expenseDates, txDay)
 
 		income := models.Transaction{
 			UserID:          userID,
@@ -1149,6 +1187,10 @@ func (s *ImportService) TransferInvestmentsFromImport(ctx context.Context, userI
 		return err
 	}
 
+	if err := s.checkSpendingLimits(ctx, userID, expenseDates); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1244,6 +1286,7 @@ func (s *ImportService) TransferSavingsFromImport(ctx context.Context, userID in
 		}
 	}
 
+	var expenseDates []time.Time
 	for _, txn := range txnPayload.SavingsTransfers {
 		if txn.TransactionType != "savings" {
 			continue
@@ -1304,6 +1347,7 @@ func (s *ImportService) TransferSavingsFromImport(ctx context.Context, userID in
 			s.markImportFailed(ctx, payload.ImportID, err)
 			return err
 		}
+		expenseDates = append(expenseDates, txDay)
 
 		income := models.Transaction{
 			UserID:          userID,
@@ -1420,6 +1464,10 @@ func (s *ImportService) TransferSavingsFromImport(ctx context.Context, userID in
 		return err
 	}
 
+	if err := s.checkSpendingLimits(ctx, userID, expenseDates); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1515,6 +1563,7 @@ func (s *ImportService) TransferRepaymentsFromImport(ctx context.Context, userID
 		}
 	}
 
+	var expenseDates []time.Time
 	for _, txn := range txnPayload.RepaymentTransfers {
 		if txn.TransactionType != "repayments" {
 			continue
@@ -1575,6 +1624,7 @@ func (s *ImportService) TransferRepaymentsFromImport(ctx context.Context, userID
 			s.markImportFailed(ctx, payload.ImportID, err)
 			return err
 		}
+		expenseDates = append(expenseDates, txDay)
 
 		income := models.Transaction{
 			UserID:          userID,
@@ -1691,6 +1741,10 @@ func (s *ImportService) TransferRepaymentsFromImport(ctx context.Context, userID
 		return err
 	}
 
+	if err := s.checkSpendingLimits(ctx, userID, expenseDates); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1808,7 +1862,7 @@ func (s *ImportService) TransferInvestmentsTrades(ctx context.Context, userID in
 		_ = tx.Rollback()
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-	client, err := finance.NewPriceFetchClient(cfg.FinanceAPIBaseURL)
+	client, err := finance.NewPriceFetcher(cfg)
 	if err != nil {
 		s.markImportFailed(ctx, importID, err)
 		_ = tx.Rollback()
@@ -2140,6 +2194,543 @@ func (s *ImportService) TransferInvestmentsTrades(ctx context.Context, userID in
 	return nil
 }
 
+// brokerTicker resolves the ticker an activity is priced by: an explicit mapping
+// by ISIN, symbol or product name first, then the broker's own symbol. Products
+// without one fall back to their ISIN and are priced manually.
+func brokerTicker(act models.BrokerActivity, tickers map[string]string) (string, bool) {
+	for _, key := range []string{act.ISIN, act.Ticker, act.Name} {
+		if key == "" {
+			continue
+		}
+		if t, ok := tickers[key]; ok && strings.TrimSpace(t) != "" {
+			return t, true
+		}
+	}
+	if act.Ticker != "" {
+		return act.Ticker, true
+	}
+	return act.ISIN, false
+}
+
+var brokerFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+// ImportBrokerStatement imports trades and dividends from a broker export into a
+// single investment account. Assets are created as needed; products that can't
+// be tied to a ticker are imported with manual pricing.
+func (s *ImportService) ImportBrokerStatement(ctx context.Context, userID int64, fileName string, r io.Reader, req models.BrokerImportReq) (*models.BrokerImportResult, error) {
+
+	activities, issues, err := utils.ParseBrokerStatement(req.Broker, r, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return nil, errors.New("no trades or dividends found in the statement")
+	}
+
+	account, err := s.accRepo.FindAccountByID(ctx, nil, req.AccountID, userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("can't find account with given id %w", err)
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	stem = strings.Trim(brokerFileNameRegex.ReplaceAllString(stem, "_"), "_")
+	if len(stem) > 100 {
+		stem = stem[:100]
+	}
+	todayStr := time.Now().UTC().Format("2006-01-02")
+	importName := fmt.Sprintf("%s_%s_generated_%s", req.Broker, stem, todayStr)
+
+	dir := filepath.Join("storage", "imports", fmt.Sprintf("%d", userID))
+	finalPath := filepath.Join(dir, importName+".json")
+	tmpPath := filepath.Join(dir, importName+".json.tmp")
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(finalPath); err == nil {
+		return nil, errors.New("import file already exists")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Reserve the name with an exclusive temp file (prevents races)
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, errors.New("import file already exists")
+		}
+		return nil, err
+	}
+	reserved := true
+	defer func() {
+		if reserved {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now().UTC()
+
+	importID, err := s.repo.InsertImport(ctx, nil, models.Import{
+		Name:      importName,
+		UserID:    userID,
+		Type:      "custom",
+		SubType:   req.Broker,
+		Status:    "pending",
+		Step:      "investments",
+		Currency:  settings.DefaultCurrency,
+		StartedAt: &started,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadConfig(nil)
+	if err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := finance.NewPriceFetcher(cfg)
+	if err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, fmt.Errorf("couldn't fetch price client: %w", err)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	fail := func(err error) (*models.BrokerImportResult, error) {
+		_ = tx.Rollback()
+		s.markImportFailed(ctx, importID, err)
+		return nil, err
+	}
+
+	taxSettings, err := s.investmentRepo.FindTaxSettings(ctx, tx, userID)
+	if err != nil {
+		return fail(err)
+	}
+
+	category, err := s.txnRepo.FindCategoryByClassification(ctx, tx, "uncategorized", &userID)
+	if err != nil {
+		return fail(fmt.Errorf("failed to find uncategorized category: %w", err))
+	}
+
+	// Rates are looked up once per currency and day
+	rates := make(map[string]decimal.Decimal)
+	rateOn := func(from, to string, day time.Time) (decimal.Decimal, error) {
+		if from == to {
+			return decimal.NewFromInt(1), nil
+		}
+		key := from + to + day.Format("2006-01-02")
+		if r, ok := rates[key]; ok {
+			return r, nil
+		}
+		r, err := client.GetExchangeRateOnDate(ctx, from, to, day)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		rates[key] = decimal.NewFromFloat(r)
+		return rates[key], nil
+	}
+
+	result := &models.BrokerImportResult{ImportID: importID, Skipped: issues}
+	skip := func(act models.BrokerActivity, reason string) {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("line %d: %s", act.Line, reason))
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assets := make(map[string]*models.InvestmentAsset)
+	held := make(map[int64]decimal.Decimal)
+	touchedAssets := make(map[int64]struct{})
+	priceSeen := make(map[string]struct{})
+	var prices []models.AssetPriceHistory
+	var earliest time.Time
+	var dividendDates []time.Time
+
+	for _, act := range activities {
+		day := act.Date.UTC().Truncate(24 * time.Hour)
+
+		rawTicker, resolved := brokerTicker(act, req.Tickers)
+		if rawTicker == "" {
+			skip(act, "no ticker or ISIN to identify the product")
+			continue
+		}
+
+		investmentType := act.InvestmentType
+		if investmentType == "" {
+			investmentType = models.InvestmentStock
+			if account.AccountType.Type == "crypto" {
+				investmentType = models.InvestmentCrypto
+			}
+		}
+
+		manual := !resolved
+		ticker := strings.ToUpper(strings.TrimSpace(rawTicker))
+		if resolved {
+			formatted, err := formatTicker(rawTicker, investmentType)
+			if err != nil {
+				manual = true
+			} else {
+				ticker = formatted
+			}
+		}
+		if manual && !manualTickerRegex.MatchString(ticker) {
+			skip(act, fmt.Sprintf("%s is not a usable ticker", rawTicker))
+			continue
+		}
+
+		asset, ok := assets[ticker]
+		if !ok {
+			existing, err := s.investmentRepo.FindAssetByTicker(ctx, tx, ticker, account.ID, userID)
+			switch {
+			case err == nil:
+				asset = &existing
+				held[asset.ID] = existing.Quantity
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				terms, err := instrumentTerms(investmentType, ticker, models.InstrumentTerms{})
+				if err != nil {
+					skip(act, fmt.Sprintf("%s: %s", ticker, err.Error()))
+					continue
+				}
+
+				name := act.Name
+				if name == "" {
+					name = ticker
+				}
+				currency := act.Currency
+				if currency == "" {
+					currency = account.Currency
+				}
+
+				newAsset := models.InvestmentAsset{
+					UserID:          userID,
+					AccountID:       account.ID,
+					ImportID:        &importID,
+					InvestmentType:  investmentType,
+					Name:            name,
+					Ticker:          ticker,
+					Quantity:        decimal.Zero,
+					Currency:        currency,
+					ManualPricing:   manual,
+					InstrumentTerms: terms,
+					AverageBuyPrice: decimal.Zero,
+				}
+
+				var seedCurrency string
+				if !manual {
+					priceData, err := client.GetAssetPrice(ctx, ticker, investmentType)
+					if err != nil {
+						// Keep the position; it can be priced by hand or remapped later
+						newAsset.ManualPricing = true
+						result.Skipped = append(result.Skipped, fmt.Sprintf("%s: no price found, imported with manual pricing", ticker))
+					} else {
+						price := utils.UnitPrice(newAsset, decimal.NewFromFloat(priceData.Price), today)
+						updated := time.Unix(priceData.LastUpdate, 0)
+						newAsset.CurrentPrice = &price
+						newAsset.LastPriceUpdate = &updated
+						seedCurrency = priceData.Currency
+					}
+				}
+
+				assetID, err := s.investmentRepo.InsertAsset(ctx, tx, &newAsset)
+				if err != nil {
+					return fail(fmt.Errorf("failed to create asset: %w", err))
+				}
+				newAsset.ID = assetID
+				if newAsset.CurrentPrice != nil {
+					prices = append(prices, models.AssetPriceHistory{AssetID: assetID, AsOf: today, Price: *newAsset.CurrentPrice, Currency: seedCurrency})
+					priceSeen[fmt.Sprintf("%d_%s", assetID, today.Format("2006-01-02"))] = struct{}{}
+				}
+				asset = &newAsset
+				held[asset.ID] = decimal.Zero
+				result.Assets++
+			default:
+				return fail(err)
+			}
+			assets[ticker] = asset
+		}
+
+		if act.Currency == "" {
+			act.Currency = asset.Currency
+		}
+
+		accRate, err := rateOn(act.Currency, account.Currency, day)
+		if err != nil {
+			return fail(fmt.Errorf("no %s/%s rate for %s: %w", act.Currency, account.Currency, day.Format("2006-01-02"), err))
+		}
+
+		if act.Kind == models.BrokerActivityDividend {
+			net := act.Amount.Sub(act.TaxWithheld)
+			if !net.IsPositive() {
+				skip(act, "dividend nets to nothing after withholding tax")
+				continue
+			}
+
+			cash := net.Mul(accRate).Round(4)
+			desc := "Dividend: " + asset.Ticker
+			txnID, err := s.txnRepo.InsertTransaction(ctx, tx, &models.Transaction{
+				UserID:          userID,
+				AccountID:       account.ID,
+				CategoryID:      &category.ID,
+				TransactionType: "income",
+				Amount:          cash,
+				Currency:        account.Currency,
+				TxnDate:         day,
+				Description:     &desc,
+				IsSystem:        true,
+				ImportID:        &importID,
+			})
+			if err != nil {
+				return fail(fmt.Errorf("failed to create dividend transaction: %w", err))
+			}
+
+			income := models.InvestmentIncome{
+				UserID:              userID,
+				AssetID:             asset.ID,
+				ImportID:            &importID,
+				TxnDate:             day,
+				IncomeType:          models.IncomeTypeDividend,
+				Amount:              act.Amount,
+				Currency:            act.Currency,
+				LinkedTransactionID: &txnID,
+			}
+			if act.TaxWithheld.IsPositive() {
+				taxWithheld := act.TaxWithheld
+				income.TaxWithheld = &taxWithheld
+			}
+			if _, err := s.investmentRepo.CreateInvestmentIncome(ctx, tx, &income); err != nil {
+				return fail(fmt.Errorf("failed to record dividend: %w", err))
+			}
+
+			if err := s.updateDailyCash(ctx, tx, account, day, "income", cash, false); err != nil {
+				return fail(err)
+			}
+
+			result.Dividends++
+			dividendDates = append(dividendDates, day)
+			if earliest.IsZero() || day.Before(earliest) {
+				earliest = day
+			}
+			continue
+		}
+
+		if !act.Quantity.IsPositive() {
+			skip(act, "trade has no quantity")
+			continue
+		}
+
+		price := act.Price
+		if !utils.QuoteIsUnitPrice(asset.InvestmentType) {
+			price = utils.UnitPrice(*asset, act.Price, day)
+		}
+		fee := act.Fee
+		// Crypto fees are kept in coin units elsewhere; a cash fee goes into the price
+		if asset.InvestmentType == models.InvestmentCrypto && act.Kind == models.BrokerActivityBuy {
+			price = price.Add(fee.Div(act.Quantity))
+			fee = decimal.Zero
+		}
+
+		usdRate := decimal.NewFromInt(1)
+		if r, err := rateOn(act.Currency, "USD", day); err == nil {
+			usdRate = r
+		}
+
+		trade := models.InvestmentTrade{
+			UserID:            userID,
+			AssetID:           asset.ID,
+			ImportID:          &importID,
+			TxnDate:           day,
+			Quantity:          act.Quantity,
+			PricePerUnit:      price,
+			Fee:               fee,
+			Currency:          act.Currency,
+			ExchangeRateToUSD: usdRate,
+		}
+
+		var cashColumn string
+		var cash decimal.Decimal
+
+		if act.Kind == models.BrokerActivityBuy {
+			trade.TradeType = models.InvestmentBuy
+			trade.ValueAtBuy = act.Quantity.Mul(price)
+			cashColumn = "cash_outflows"
+			cash = trade.ValueAtBuy.Add(fee).Mul(accRate)
+			held[asset.ID] = held[asset.ID].Add(act.Quantity)
+		} else {
+			if act.Quantity.GreaterThan(held[asset.ID]) {
+				skip(act, fmt.Sprintf("cannot sell %s %s, only %s held", act.Quantity.String(), asset.Ticker, held[asset.ID].String()))
+				continue
+			}
+
+			trades, err := s.investmentRepo.FindAllTradesByAssetID(ctx, tx, asset.ID, userID)
+			if err != nil {
+				return fail(err)
+			}
+			var prior []models.InvestmentTrade
+			for _, t := range trades {
+				if !t.TxnDate.After(day) {
+					prior = append(prior, t)
+				}
+			}
+			method := utils.ResolveCostBasisMethod(*asset, taxSettings)
+			costBasis := decimal.Zero
+			for _, c := range utils.ConsumeLots(utils.BuildLots(prior, 0, method), day, act.Quantity, method, nil) {
+				costBasis = costBasis.Add(c.ValueAtBuy)
+			}
+
+			if asset.InvestmentType == models.InvestmentCrypto {
+				trade.Fee = decimal.Zero
+			}
+			trade.TradeType = models.InvestmentSell
+			trade.ValueAtBuy = costBasis
+			trade.RealizedValue = act.Quantity.Mul(price).Sub(fee)
+			trade.ProfitLoss = trade.RealizedValue.Sub(costBasis)
+			if !costBasis.IsZero() {
+				trade.ProfitLossPercent = trade.ProfitLoss.Div(costBasis)
+			}
+			cashColumn = "cash_inflows"
+			cash = trade.RealizedValue.Mul(accRate)
+			held[asset.ID] = held[asset.ID].Sub(act.Quantity)
+		}
+
+		if asset.CurrentPrice != nil {
+			trade.CurrentValue = act.Quantity.Mul(*asset.CurrentPrice)
+			if trade.TradeType == models.InvestmentBuy {
+				trade.ProfitLoss = trade.CurrentValue.Sub(trade.ValueAtBuy.Add(fee))
+				if basis := trade.ValueAtBuy.Add(fee); !basis.IsZero() {
+					trade.ProfitLossPercent = trade.ProfitLoss.Div(basis)
+				}
+			}
+		}
+
+		if _, err := s.investmentRepo.InsertInvestmentTrade(ctx, tx, &trade); err != nil {
+			return fail(fmt.Errorf("failed to insert trade: %w", err))
+		}
+
+		if err := s.accRepo.EnsureDailyBalanceRow(ctx, tx, account.ID, day, account.Currency); err != nil {
+			return fail(err)
+		}
+		if err := s.accRepo.AddToDailyBalance(ctx, tx, account.ID, day, cashColumn, cash.Round(4)); err != nil {
+			return fail(err)
+		}
+
+		key := fmt.Sprintf("%d_%s", asset.ID, day.Format("2006-01-02"))
+		if _, ok := priceSeen[key]; !ok {
+			priceSeen[key] = struct{}{}
+			prices = append(prices, models.AssetPriceHistory{AssetID: asset.ID, AsOf: day, Price: price, Currency: act.Currency})
+		}
+
+		touchedAssets[asset.ID] = struct{}{}
+		result.Trades++
+		if earliest.IsZero() || day.Before(earliest) {
+			earliest = day
+		}
+	}
+
+	if result.Trades == 0 && result.Dividends == 0 {
+		return fail(errors.New("nothing in the statement could be imported"))
+	}
+
+	if len(prices) > 0 {
+		if err := s.investmentRepo.UpsertAssetPrice(ctx, tx, prices); err != nil {
+			return fail(fmt.Errorf("failed to upsert asset price history: %w", err))
+		}
+	}
+
+	for assetID := range touchedAssets {
+		if err := s.investmentRepo.RecalculateAssetFromTrades(ctx, tx, assetID, userID); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := s.frontfillBalances(ctx, tx, userID, account.ID, account.Currency, earliest); err != nil {
+		return fail(err)
+	}
+
+	// Write the parsed activities to the temp file
+	data, err := json.MarshalIndent(activities, "", "  ")
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fail(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, err
+	}
+
+	// Populate market_value on the new snapshots from the committed price history
+	if err := s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil); err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, err
+	}
+
+	// Promote the temp file to final
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		s.markImportFailed(ctx, importID, err)
+		return nil, err
+	}
+	reserved = false
+
+	if err := s.repo.UpdateImport(ctx, nil, importID, map[string]interface{}{
+		"status":       "success",
+		"step":         "completed",
+		"completed_at": time.Now().UTC(),
+		"error":        "",
+	}); err != nil {
+		return nil, fmt.Errorf("marking import %d successful failed: %w", importID, err)
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", "custom", changes, "type")
+	utils.CompareChanges("", req.Broker, changes, "sub_type")
+	utils.CompareChanges("", importName, changes, "name")
+	utils.CompareChanges("", account.Name, changes, "account")
+	utils.CompareChanges("", strconv.Itoa(result.Trades), changes, "trades_imported_count")
+	utils.CompareChanges("", strconv.Itoa(result.Dividends), changes, "dividends_imported_count")
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "import",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Dividends are booked on the ledger like any other import
+	if err := s.checkSpendingLimits(ctx, userID, dividendDates); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (s *ImportService) DeleteImport(ctx context.Context, userID, id int64) error {
 
 	imp, err := s.FetchImportByID(ctx, id, userID, "custom")
@@ -2163,7 +2754,7 @@ func (s *ImportService) DeleteImport(ctx context.Context, userID, id int64) erro
 		if err != nil {
 			return err
 		}
-	case "trades":
+	case "trades", models.BrokerIBKR, models.BrokerDegiro, models.BrokerTrading212, models.BrokerCSV:
 		err = s.deleteTradesImport(ctx, userID, imp)
 		if err != nil {
 			return err
@@ -2474,6 +3065,25 @@ func (s *ImportService) deleteTradesImport(ctx context.Context, userID int64, im
 
 	affectedAccountIDs := map[int64]bool{}
 
+	// Broker imports also bring in dividends, each with a linked income transaction.
+	txns, err := s.txnRepo.FindTransactionsByImportID(ctx, tx, imp.ID, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to find transactions: %w", err)
+	}
+	for _, txn := range txns {
+		affectedAccountIDs[txn.AccountID] = true
+	}
+
+	if _, err := s.investmentRepo.PurgeImportedInvestmentIncome(ctx, tx, imp.ID, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := s.txnRepo.PurgeImportedTransactions(ctx, tx, imp.ID, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	assets, err := s.investmentRepo.FindInvestmentAssetsByImportID(ctx, tx, imp.ID, userID)
 	if err != nil {
 		tx.Rollback()
@@ -2552,7 +3162,7 @@ func (s *ImportService) backfillInvestmentCashFlows(ctx context.Context, userID
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-	client, err := finance.NewPriceFetchClient(cfg.FinanceAPIBaseURL)
+	client, err := finance.NewPriceFetcher(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create price client: %w", err)
 	}
@@ -2612,7 +3222,8 @@ func (s *ImportService) backfillInvestmentCashFlows(ctx context.Context, userID
 	}
 
 	for _, trade := range trades {
-		if !affectedSet[trade.Asset.AccountID] {
+		// In-kind transfer legs never moved cash
+		if !affectedSet[trade.Asset.AccountID] || trade.TransferID != nil {
 			continue
 		}
 
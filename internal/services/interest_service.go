@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type InterestServiceInterface interface {
+	FetchConfigs(ctx context.Context, userID int64) ([]models.AccountInterestConfig, error)
+	FetchConfigByAccountID(ctx context.Context, userID, accountID int64) (*models.AccountInterestConfig, error)
+	UpsertConfig(ctx context.Context, userID, accountID int64, req *models.AccountInterestConfigReq) (int64, error)
+	DeleteConfig(ctx context.Context, userID, accountID int64) error
+	PreviewYearlyInterest(ctx context.Context, userID, accountID int64) (*models.InterestPreview, error)
+
+	FetchActiveConfigs(ctx context.Context) ([]models.AccountInterestConfig, error)
+	AccrueInterest(ctx context.Context, cfg models.AccountInterestConfig, today time.Time) (decimal.Decimal, error)
+}
+
+type InterestService struct {
+	repo          repositories.InterestRepositoryInterface
+	accountRepo   repositories.AccountRepositoryInterface
+	txnRepo       repositories.TransactionRepositoryInterface
+	loggingRepo   repositories.LoggingRepositoryInterface
+	jobDispatcher queue.JobDispatcher
+}
+
+func NewInterestService(
+	repo *repositories.InterestRepository,
+	accountRepo *repositories.AccountRepository,
+	txnRepo *repositories.TransactionRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+) *InterestService {
+	return &InterestService{
+		repo:          repo,
+		accountRepo:   accountRepo,
+		txnRepo:       txnRepo,
+		loggingRepo:   loggingRepo,
+		jobDispatcher: jobDispatcher,
+	}
+}
+
+var _ InterestServiceInterface = (*InterestService)(nil)
+
+func (s *InterestService) FetchConfigs(ctx context.Context, userID int64) ([]models.AccountInterestConfig, error) {
+	return s.repo.FindConfigs(ctx, nil, userID)
+}
+
+func (s *InterestService) FetchConfigByAccountID(ctx context.Context, userID, accountID int64) (*models.AccountInterestConfig, error) {
+	record, err := s.repo.FindConfigByAccountID(ctx, nil, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *InterestService) FetchActiveConfigs(ctx context.Context) ([]models.AccountInterestConfig, error) {
+	return s.repo.FindActiveConfigs(ctx, nil)
+}
+
+func validateInterestReq(req *models.AccountInterestConfigReq) error {
+	switch req.Compounding {
+	case models.InterestCompoundingDaily, models.InterestCompoundingMonthly,
+		models.InterestCompoundingQuarterly, models.InterestCompoundingYearly:
+	default:
+		return fmt.Errorf("invalid compounding frequency: %s", req.Compounding)
+	}
+
+	if req.AnnualRate.IsNegative() {
+		return fmt.Errorf("annual rate cannot be negative")
+	}
+
+	seen := make(map[string]bool, len(req.Tiers))
+	for _, t := range req.Tiers {
+		if t.MinBalance.IsNegative() || t.AnnualRate.IsNegative() {
+			return fmt.Errorf("tier balance and rate cannot be negative")
+		}
+		key := t.MinBalance.String()
+		if seen[key] {
+			return fmt.Errorf("duplicate tier for balance %s", t.MinBalance.StringFixed(2))
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func (s *InterestService) UpsertConfig(ctx context.Context, userID, accountID int64, req *models.AccountInterestConfigReq) (int64, error) {
+	if err := validateInterestReq(req); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	accType, err := s.accountRepo.FindAccountTypeByAccID(ctx, tx, accountID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("account not found: %w", err)
+	}
+	if accType.Type != "cash" {
+		tx.Rollback()
+		return 0, fmt.Errorf("interest can be configured only for cash accounts")
+	}
+
+	tiers := make([]models.AccountInterestTier, len(req.Tiers))
+	for i, t := range req.Tiers {
+		tiers[i] = models.AccountInterestTier{MinBalance: t.MinBalance, AnnualRate: t.AnnualRate}
+	}
+
+	existing, findErr := s.repo.FindConfigByAccountID(ctx, tx, accountID, userID)
+	isNew := findErr != nil
+
+	changes := utils.InitChanges()
+	var id int64
+
+	if isNew {
+		// accrual starts on the day the config is created
+		yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		record := models.AccountInterestConfig{
+			UserID:           userID,
+			AccountID:        accountID,
+			AnnualRate:       req.AnnualRate,
+			Compounding:      req.Compounding,
+			PayoutDayOfMonth: req.PayoutDayOfMonth,
+			IsActive:         req.IsActive,
+			AccruedThrough:   &yesterday,
+		}
+		id, err = s.repo.InsertConfig(ctx, tx, &record)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		utils.CompareChanges("", strconv.FormatInt(accountID, 10), changes, "account_id")
+		utils.CompareDecimalChange(nil, &record.AnnualRate, changes, "annual_rate", 4)
+		utils.CompareChanges("", string(record.Compounding), changes, "compounding")
+		utils.CompareChanges("", strconv.Itoa(record.PayoutDayOfMonth), changes, "payout_day_of_month")
+	} else {
+		utils.CompareDecimalChange(&existing.AnnualRate, &req.AnnualRate, changes, "annual_rate", 4)
+		utils.CompareChanges(string(existing.Compounding), string(req.Compounding), changes, "compounding")
+		utils.CompareChanges(strconv.Itoa(existing.PayoutDayOfMonth), strconv.Itoa(req.PayoutDayOfMonth), changes, "payout_day_of_month")
+		utils.CompareChanges(strconv.FormatBool(existing.IsActive), strconv.FormatBool(req.IsActive), changes, "is_active")
+
+		existing.AnnualRate = req.AnnualRate
+		existing.Compounding = req.Compounding
+		existing.PayoutDayOfMonth = req.PayoutDayOfMonth
+		existing.IsActive = req.IsActive
+
+		id, err = s.repo.UpdateConfig(ctx, tx, existing)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := s.repo.ReplaceTiers(ctx, tx, id, tiers); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	utils.CompareChanges(strconv.Itoa(len(existing.Tiers)), strconv.Itoa(len(tiers)), changes, "tiers")
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	event := "update"
+	if isNew {
+		event = "create"
+	}
+
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(id, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       event,
+			Category:    "interest_config",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+func (s *InterestService) DeleteConfig(ctx context.Context, userID, accountID int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	cfg, err := s.repo.FindConfigByAccountID(ctx, tx, accountID, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("interest config not found: %w", err)
+	}
+
+	if err := s.repo.DeleteConfig(ctx, tx, cfg.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(cfg.ID, 10), changes, "id")
+	utils.CompareChanges(strconv.FormatInt(cfg.AccountID, 10), "", changes, "account_id")
+	utils.CompareDecimalChange(&cfg.AnnualRate, nil, changes, "annual_rate", 4)
+	if !changes.IsEmpty() {
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "delete",
+			Category:    "interest_config",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *InterestService) PreviewYearlyInterest(ctx context.Context, userID, accountID int64) (*models.InterestPreview, error) {
+	cfg, err := s.repo.FindConfigByAccountID(ctx, nil, accountID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("interest config not found: %w", err)
+	}
+
+	account, err := s.accountRepo.FindAccountByID(ctx, nil, accountID, userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	balance := decimal.Zero
+	latest, err := s.accountRepo.FindLatestBalance(ctx, nil, accountID, userID)
+	if err == nil && latest != nil {
+		balance = latest.EndBalance
+	}
+
+	rate := utils.InterestRateForBalance(cfg.AnnualRate, cfg.Tiers, balance)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return &models.InterestPreview{
+		AccountID:      accountID,
+		Balance:        balance,
+		EffectiveRate:  rate,
+		Compounding:    cfg.Compounding,
+		ExpectedYearly: utils.ProjectYearlyInterest(balance, rate, cfg.Compounding),
+		AccruedAmount:  cfg.AccruedAmount.Round(2),
+		NextPayoutDate: utils.NextInterestPayoutDate(today.AddDate(0, 0, -1), cfg.Compounding, cfg.PayoutDayOfMonth),
+		Currency:       account.Currency,
+	}, nil
+}
+
+// AccrueInterest brings the accrual of cfg up to the day before today and posts
+// every payout that fell due on or before today. It returns the total amount paid.
+func (s *InterestService) AccrueInterest(ctx context.Context, cfg models.AccountInterestConfig, today time.Time) (decimal.Decimal, error) {
+	today = today.UTC().Truncate(24 * time.Hour)
+
+	start := cfg.CreatedAt.UTC().Truncate(24 * time.Hour)
+	if cfg.AccruedThrough != nil {
+		start = cfg.AccruedThrough.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+	anchor := start.AddDate(0, 0, -1)
+	if cfg.LastPayoutAt != nil {
+		anchor = cfg.LastPayoutAt.UTC().Truncate(24 * time.Hour)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	account, err := s.accountRepo.FindAccountByID(ctx, tx, cfg.AccountID, cfg.UserID, false)
+	if err != nil {
+		tx.Rollback()
+		return decimal.Zero, fmt.Errorf("account not found: %w", err)
+	}
+
+	paid := decimal.Zero
+	var lastPayout *time.Time
+
+	for {
+		payoutDate := utils.NextInterestPayoutDate(anchor, cfg.Compounding, cfg.PayoutDayOfMonth)
+		due := !payoutDate.After(today)
+
+		// interest earned up to the payout day is paid on it; otherwise accrue through yesterday
+		accrueTo := today.AddDate(0, 0, -1)
+		if due {
+			accrueTo = payoutDate.AddDate(0, 0, -1)
+		}
+
+		if !start.After(accrueTo) {
+			opening, err := s.repo.FindEndBalanceAsOf(ctx, tx, cfg.AccountID, start.AddDate(0, 0, -1))
+			if err != nil {
+				tx.Rollback()
+				return decimal.Zero, err
+			}
+			balances, err := s.accountRepo.GetBalancesInRange(ctx, tx, cfg.AccountID, start, accrueTo)
+			if err != nil {
+				tx.Rollback()
+				return decimal.Zero, err
+			}
+			cfg.AccruedAmount = utils.AccrueInterest(cfg, balances, opening, start, accrueTo)
+			start = accrueTo.AddDate(0, 0, 1)
+		}
+
+		if !due {
+			break
+		}
+
+		amount := cfg.AccruedAmount.Round(2)
+		if amount.IsPositive() {
+			if err := s.postInterest(ctx, tx, account, payoutDate, today, amount); err != nil {
+				tx.Rollback()
+				return decimal.Zero, err
+			}
+			// sub-cent remainders stay accrued for the next payout
+			cfg.AccruedAmount = cfg.AccruedAmount.Sub(amount)
+			paid = paid.Add(amount)
+		}
+
+		anchor = payoutDate
+		p := payoutDate
+		lastPayout = &p
+	}
+
+	if err := s.repo.UpdateAccrual(ctx, tx, cfg.ID, cfg.AccruedAmount, start.AddDate(0, 0, -1), lastPayout); err != nil {
+		tx.Rollback()
+		return decimal.Zero, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return decimal.Zero, err
+	}
+
+	return paid, nil
+}
+
+func (s *InterestService) postInterest(ctx context.Context, tx *gorm.DB, account *models.Account, payoutDate, today time.Time, amount decimal.Decimal) error {
+	userID := account.UserID
+
+	category, err := s.txnRepo.FindCategoryByName(ctx, tx, "interest", &userID)
+	if err != nil {
+		category, err = s.txnRepo.FindCategoryByClassification(ctx, tx, "uncategorized", &userID)
+		if err != nil {
+			return fmt.Errorf("failed to find interest category: %w", err)
+		}
+	}
+
+	desc := "Interest: " + account.Name
+	txn := &models.Transaction{
+		UserID:          userID,
+		AccountID:       account.ID,
+		CategoryID:      &category.ID,
+		TransactionType: "income",
+		Amount:          amount,
+		Currency:        account.Currency,
+		TxnDate:         payoutDate,
+		Description:     &desc,
+	}
+	if _, err := s.txnRepo.InsertTransaction(ctx, tx, txn); err != nil {
+		return fmt.Errorf("failed to create interest transaction: %w", err)
+	}
+
+	if err := s.accountRepo.EnsureDailyBalanceRow(ctx, tx, account.ID, payoutDate, account.Currency); err != nil {
+		return err
+	}
+	if err := s.accountRepo.AddToDailyBalance(ctx, tx, account.ID, payoutDate, "cash_inflows", amount); err != nil {
+		return err
+	}
+	if err := s.accountRepo.FrontfillBalances(ctx, tx, account.ID, account.Currency, payoutDate); err != nil {
+		return err
+	}
+	return s.accountRepo.UpsertSnapshotsFromBalances(ctx, tx, userID, account.ID, account.Currency, payoutDate, today)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -30,7 +31,7 @@ type InvestmentServiceInterface interface {
 	FetchInvestmentTradeByID(ctx context.Context, userID int64, id int64) (*models.InvestmentTrade, error)
 	InsertAsset(ctx context.Context, userID int64, req *models.InvestmentAssetReq) (int64, error)
 	InsertInvestmentTrade(ctx context.Context, userID int64, req *models.InvestmentTradeReq) (int64, error)
-	UpdateInvestmentAsset(ctx context.Context, userID int64, id int64, req *models.InvestmentAssetReq) (int64, error)
+	UpdateInvestmentAsset(ctx context.Context, userID int64, id int64, req *models.InvestmentAssetUpdateReq) (int64, error)
 	UpdateInvestmentTrade(ctx context.Context, userID int64, id int64, req *models.InvestmentTradeReq) (int64, error)
 	DeleteInvestmentAsset(ctx context.Context, userID int64, id int64) error
 	DeleteInvestmentTrade(ctx context.Context, userID int64, id int64) error
@@ -51,6 +52,25 @@ type InvestmentServiceInterface interface {
 	FetchTaxSettings(ctx context.Context, userID int64) (models.InvestmentTaxSettings, error)
 	SaveTaxSettings(ctx context.Context, userID int64, req *models.InvestmentTaxSettingsReq) error
 	CopyTaxBrackets(ctx context.Context, userID int64, fromType, toType models.InvestmentType) error
+	FetchTaxHints(ctx context.Context, userID int64, window int) (*models.TaxOptimizationHints, error)
+	FetchTaxFreeAlertUsers(ctx context.Context) ([]int64, error)
+	MarkTaxFreeAlerts(ctx context.Context, userID int64, today time.Time) ([]models.TaxHoldCandidate, error)
+	FetchCorporateActions(ctx context.Context, userID, assetID int64) ([]models.CorporateAction, error)
+	ApplyCorporateAction(ctx context.Context, userID, assetID int64, req *models.CorporateActionReq) (int64, error)
+	RevertCorporateAction(ctx context.Context, userID, assetID, actionID int64) error
+	FetchCouponBonds(ctx context.Context) ([]models.InvestmentAsset, error)
+	RecordDueCoupons(ctx context.Context, asset models.InvestmentAsset, today time.Time) (int, error)
+	FetchBenchmarks(ctx context.Context, userID int64) ([]models.UserBenchmark, error)
+	InsertBenchmark(ctx context.Context, userID int64, req *models.BenchmarkReq) (int64, error)
+	DeleteBenchmark(ctx context.Context, userID, id int64) error
+	BackfillBenchmarkPriceHistory(ctx context.Context, benchmarkID int64, ticker string, investmentType models.InvestmentType, from, to time.Time) error
+	FetchAssetPrices(ctx context.Context, userID, assetID int64) ([]models.AssetPriceHistory, error)
+	InsertManualPrice(ctx context.Context, userID, assetID int64, req *models.ManualPriceReq) error
+	DeleteManualPrice(ctx context.Context, userID, assetID int64, asOf time.Time) error
+	UploadPrices(ctx context.Context, userID int64, r io.Reader) (*models.PriceUploadResult, error)
+	FetchTransfers(ctx context.Context, userID int64) ([]models.InvestmentTransfer, error)
+	TransferHoldings(ctx context.Context, userID int64, req *models.InvestmentTransferReq) (int64, error)
+	DeleteTransfer(ctx context.Context, userID, id int64) error
 }
 
 type InvestmentService struct {
@@ -102,6 +122,220 @@ var stockTickerRegex = func() *regexp.Regexp {
 	return regexp.MustCompile(`^[A-Z]{1,7}(\.(` + strings.Join(codes, "|") + `))?$`)
 }()
 
+// Bonds trade under exchange tickers or ISINs; commodities under futures or spot
+// symbols such as GC=F or XAUUSD=X.
+var (
+	bondTickerRegex      = regexp.MustCompile(`^[A-Z0-9][A-Z0-9.\-]{0,19}$`)
+	commodityTickerRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9=.\-]{0,19}$`)
+)
+
+// formatTicker upper-cases a ticker and brings it into the form the price
+// provider expects.
+func formatTicker(ticker string, investmentType models.InvestmentType) (string, error) {
+	formatted := strings.ToUpper(strings.TrimSpace(ticker))
+	switch investmentType {
+	case models.InvestmentCrypto:
+		// Ensure format: BTC-USD
+		if !strings.Contains(formatted, "-") {
+			formatted = formatted + "-USD"
+		}
+
+	case models.InvestmentStock, models.InvestmentETF, models.InvestmentMutualFund:
+		// Allow either:
+		//   - pure ticker: AAPL
+		//   - ticker + exchange: IWDA.AS
+		if !stockTickerRegex.MatchString(formatted) {
+			return "", fmt.Errorf("invalid stock/ETF ticker: must look like AAPL or IWDA.AS")
+		}
+
+	case models.InvestmentBond:
+		if !bondTickerRegex.MatchString(formatted) {
+			return "", fmt.Errorf("invalid bond ticker: use the exchange ticker or ISIN")
+		}
+
+	case models.InvestmentCommodity:
+		if !commodityTickerRegex.MatchString(formatted) {
+			return "", fmt.Errorf("invalid commodity ticker: must look like GC=F or XAUUSD=X")
+		}
+
+	case models.InvestmentOption:
+		if _, _, _, _, ok := utils.ParseOptionSymbol(formatted); !ok {
+			return "", fmt.Errorf("invalid option ticker: must be an OCC symbol like AAPL250117C00150000")
+		}
+
+	default:
+		return "", fmt.Errorf("invalid investment type: %s", investmentType)
+	}
+	return formatted, nil
+}
+
+// instrumentTerms validates the contract terms a bond, commodity or option needs
+// and drops the ones that don't apply to the type. Option terms left empty are
+// read from the OCC symbol.
+func instrumentTerms(investmentType models.InvestmentType, ticker string, req models.InstrumentTerms) (models.InstrumentTerms, error) {
+	var terms models.InstrumentTerms
+
+	switch investmentType {
+	case models.InvestmentBond:
+		if req.FaceValue == nil || !req.FaceValue.IsPositive() {
+			return terms, errors.New("bonds need a positive face value")
+		}
+		if req.MaturityDate == nil {
+			return terms, errors.New("bonds need a maturity date")
+		}
+		freq := 0
+		if req.CouponFrequency != nil {
+			freq = *req.CouponFrequency
+		}
+		switch freq {
+		case 0, 1, 2, 4, 12:
+		default:
+			return terms, errors.New("coupon frequency must be 0, 1, 2, 4 or 12 payments a year")
+		}
+		rate := decimal.Zero
+		if req.CouponRate != nil {
+			rate = *req.CouponRate
+		}
+		if rate.IsNegative() || (freq > 0 && !rate.IsPositive()) {
+			return terms, errors.New("coupon bonds need a positive coupon rate")
+		}
+		maturity := req.MaturityDate.UTC().Truncate(24 * time.Hour)
+		terms.FaceValue = req.FaceValue
+		terms.CouponRate = &rate
+		terms.CouponFrequency = &freq
+		terms.MaturityDate = &maturity
+
+	case models.InvestmentCommodity:
+		unit := models.CommodityGram
+		if req.Unit != nil {
+			unit = *req.Unit
+		}
+		switch unit {
+		case models.CommodityGram, models.CommodityKilogram, models.CommodityTroyOunce:
+		default:
+			return terms, fmt.Errorf("invalid commodity unit: %s", unit)
+		}
+		terms.Unit = &unit
+
+	case models.InvestmentOption:
+		_, expiry, optType, strike, _ := utils.ParseOptionSymbol(ticker)
+		if req.OptionType != nil {
+			optType = *req.OptionType
+		}
+		if optType != models.OptionCall && optType != models.OptionPut {
+			return terms, fmt.Errorf("invalid option type: %s", optType)
+		}
+		if req.StrikePrice != nil {
+			strike = *req.StrikePrice
+		}
+		if !strike.IsPositive() {
+			return terms, errors.New("options need a positive strike price")
+		}
+		if req.ExpiryDate != nil {
+			expiry = req.ExpiryDate.UTC().Truncate(24 * time.Hour)
+		}
+		multiplier := decimal.NewFromInt(100)
+		if req.Multiplier != nil {
+			multiplier = *req.Multiplier
+		}
+		if !multiplier.IsPositive() {
+			return terms, errors.New("option multiplier must be positive")
+		}
+		terms.OptionType = &optType
+		terms.StrikePrice = &strike
+		terms.ExpiryDate = &expiry
+		terms.Multiplier = &multiplier
+	}
+
+	return terms, nil
+}
+
+// manualTickerRegex is the looser check for manually priced assets, which never
+// reach a price provider.
+var manualTickerRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9.\-_]{0,19}$`)
+
+// fetcherFor routes price requests for the asset to its pinned provider, if any.
+// Manually priced assets read their own price history instead.
+func (s *InvestmentService) fetcherFor(asset models.InvestmentAsset) finance.PriceFetcher {
+	if asset.ManualPricing {
+		return &manualPriceFetcher{repo: s.repo, assetID: asset.ID, currency: asset.Currency, fx: s.priceFetchClient}
+	}
+	return finance.ForProvider(s.priceFetchClient, asset.PriceProvider)
+}
+
+// manualPriceFetcher serves a manually priced asset from the prices the user
+// entered. Exchange rates still go to the regular fetcher.
+type manualPriceFetcher struct {
+	repo     repositories.InvestmentRepositoryInterface
+	assetID  int64
+	currency string
+	fx       finance.PriceFetcher
+}
+
+func (f *manualPriceFetcher) price(ctx context.Context, ticker string, asOf *time.Time) (*finance.PriceData, error) {
+	entry, err := f.repo.FindAssetPriceOnOrBefore(ctx, nil, f.assetID, asOf)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("no manual price entered for '%s'", ticker)
+		}
+		return nil, err
+	}
+
+	currency := entry.Currency
+	if currency == "" {
+		currency = f.currency
+	}
+	return &finance.PriceData{
+		Symbol:     ticker,
+		Price:      entry.Price.InexactFloat64(),
+		Currency:   currency,
+		LastUpdate: entry.AsOf.Unix(),
+	}, nil
+}
+
+func (f *manualPriceFetcher) GetAssetPrice(ctx context.Context, ticker string, _ models.InvestmentType) (*finance.PriceData, error) {
+	return f.price(ctx, ticker, nil)
+}
+
+func (f *manualPriceFetcher) GetAssetPriceOnDate(ctx context.Context, ticker string, _ models.InvestmentType, date time.Time) (*finance.PriceData, error) {
+	return f.price(ctx, ticker, &date)
+}
+
+func (f *manualPriceFetcher) GetPricesForMultipleAssets(_ context.Context, _ []finance.AssetRequest) (map[string]*finance.PriceData, error) {
+	return nil, errors.New("manually priced assets are not synced")
+}
+
+func (f *manualPriceFetcher) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	if f.fx == nil {
+		return 0, errors.New("price fetch client not available")
+	}
+	return f.fx.GetExchangeRate(ctx, fromCurrency, toCurrency)
+}
+
+func (f *manualPriceFetcher) GetExchangeRateOnDate(ctx context.Context, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	if f.fx == nil {
+		return 0, errors.New("price fetch client not available")
+	}
+	return f.fx.GetExchangeRateOnDate(ctx, fromCurrency, toCurrency, date)
+}
+
+// latestUnitPrice fetches the asset's latest quote and converts it to the price of
+// one held unit. Bonds without a quote are carried at par; that price keeps the
+// fetch error in PriceData.Error and must not be stored as price history.
+func (s *InvestmentService) latestUnitPrice(ctx context.Context, asset models.InvestmentAsset) (*finance.PriceData, decimal.Decimal, error) {
+	priceData, err := s.fetcherFor(asset).GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
+	if err != nil {
+		if asset.InvestmentType != models.InvestmentBond {
+			return nil, decimal.Zero, err
+		}
+		priceData = &finance.PriceData{Symbol: asset.Ticker, Price: 100, Currency: asset.Currency, LastUpdate: time.Now().Unix(), Error: err}
+	}
+
+	quote := decimal.NewFromFloat(priceData.Price)
+	asOf := time.Unix(priceData.LastUpdate, 0).UTC()
+	return priceData, utils.UnitPrice(asset, quote, asOf), nil
+}
+
 func (s *InvestmentService) FetchInvestmentAssetsPaginated(ctx context.Context, userID int64, p utils.PaginationParams, accountID *int64) ([]models.InvestmentAsset, *utils.Paginator, error) {
 
 	totalRecords, err := s.repo.CountInvestmentAssets(ctx, nil, userID, p.Filters, accountID)
@@ -215,15 +449,19 @@ func (s *InvestmentService) FetchInvestmentTradeByID(ctx context.Context, userID
 		return nil, err
 	}
 	if len(brackets) > 0 {
+		method, err := s.costBasisMethodFor(ctx, nil, userID, record.Asset)
+		if err != nil {
+			return nil, err
+		}
+		allTrades, err := s.repo.FindAllTradesByAssetID(ctx, nil, record.AssetID, userID)
+		if err != nil {
+			return nil, err
+		}
 		if record.TradeType == models.InvestmentBuy {
-			info := utils.ComputeBuyTradeTaxInfo(record, brackets, time.Now().UTC())
+			info := utils.ComputeBuyTradeTaxInfo(record, allTrades, method, brackets, time.Now().UTC())
 			record.TaxInfo = &info
 		} else {
-			allTrades, err := s.repo.FindAllTradesByAssetID(ctx, nil, record.AssetID, userID)
-			if err != nil {
-				return nil, err
-			}
-			info := utils.ComputeSellTradeTaxInfo(record, allTrades, brackets)
+			info := utils.ComputeSellTradeTaxInfo(record, allTrades, method, brackets)
 			record.TaxInfo = &info
 		}
 	}
@@ -250,48 +488,25 @@ func (s *InvestmentService) InsertAsset(ctx context.Context, userID int64, req *
 		return 0, fmt.Errorf("can't find account with given id %w", err)
 	}
 
-	// Validate ticker and fetch price
-	var currentPrice *decimal.Decimal
-	var lastPriceUpdate *time.Time
 	var formattedTicker string
-	var fetchedPriceCurrency string
-
-	if s.priceFetchClient != nil {
-
-		formattedTicker = strings.ToUpper(req.Ticker)
-		switch req.InvestmentType {
-		case models.InvestmentCrypto:
-			// Ensure format: BTC-USD
-			if !strings.Contains(formattedTicker, "-") {
-				formattedTicker = formattedTicker + "-USD"
-			}
-
-		case models.InvestmentStock, models.InvestmentETF:
-			// Allow either:
-			//   - pure ticker: AAPL
-			//   - ticker + exchange: IWDA.AS
-			if !stockTickerRegex.MatchString(formattedTicker) {
-				tx.Rollback()
-				return 0, fmt.Errorf("invalid stock/ETF ticker: must look like AAPL or IWDA.AS")
-			}
+	if req.ManualPricing {
+		formattedTicker = strings.ToUpper(strings.TrimSpace(req.Ticker))
+		if !manualTickerRegex.MatchString(formattedTicker) {
+			tx.Rollback()
+			return 0, fmt.Errorf("invalid ticker: use up to 20 letters, digits, dots, dashes or underscores")
 		}
-
-		priceData, err := s.priceFetchClient.GetAssetPrice(ctx, formattedTicker, req.InvestmentType)
+	} else {
+		formattedTicker, err = formatTicker(req.Ticker, req.InvestmentType)
 		if err != nil {
 			tx.Rollback()
-			return 0, fmt.Errorf("failed to fetch price for ticker '%s': %w", formattedTicker, err)
+			return 0, err
 		}
+	}
 
-		price := decimal.NewFromFloat(priceData.Price)
-		now := time.Unix(priceData.LastUpdate, 0)
-		currentPrice = &price
-		lastPriceUpdate = &now
-		fetchedPriceCurrency = priceData.Currency
-
-	} else {
-		// Client not available - allow creation but without price
-		currentPrice = nil
-		lastPriceUpdate = nil
+	terms, err := instrumentTerms(req.InvestmentType, formattedTicker, req.InstrumentTerms)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
 	}
 
 	hold := models.InvestmentAsset{
@@ -302,9 +517,30 @@ func (s *InvestmentService) InsertAsset(ctx context.Context, userID int64, req *
 		Ticker:          formattedTicker,
 		Quantity:        req.Quantity,
 		Currency:        req.Currency,
+		CostBasisMethod: req.CostBasisMethod,
+		AssetClass:      utils.NormalizeAssetClass(req.AssetClass),
+		PriceProvider:   req.PriceProvider,
+		ManualPricing:   req.ManualPricing,
+		InstrumentTerms: terms,
 		AverageBuyPrice: decimal.Zero,
-		CurrentPrice:    currentPrice,
-		LastPriceUpdate: lastPriceUpdate,
+	}
+
+	// Validate ticker and fetch price. Manually priced assets start without one.
+	var fetchedPriceCurrency string
+
+	if s.priceFetchClient != nil && !hold.ManualPricing {
+		priceData, price, err := s.latestUnitPrice(ctx, hold)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to fetch price for ticker '%s': %w", formattedTicker, err)
+		}
+
+		now := time.Unix(priceData.LastUpdate, 0)
+		hold.CurrentPrice = &price
+		hold.LastPriceUpdate = &now
+		if priceData.Error == nil {
+			fetchedPriceCurrency = priceData.Currency
+		}
 	}
 
 	holdID, err := s.repo.InsertAsset(ctx, tx, &hold)
@@ -313,9 +549,9 @@ func (s *InvestmentService) InsertAsset(ctx context.Context, userID int64, req *
 		return 0, err
 	}
 
-	if currentPrice != nil && fetchedPriceCurrency != "" {
+	if hold.CurrentPrice != nil && fetchedPriceCurrency != "" {
 		today := time.Now().UTC().Truncate(24 * time.Hour)
-		if err := s.repo.UpsertAssetPrice(ctx, tx, []models.AssetPriceHistory{{AssetID: holdID, AsOf: today, Price: *currentPrice, Currency: fetchedPriceCurrency}}); err != nil {
+		if err := s.repo.UpsertAssetPrice(ctx, tx, []models.AssetPriceHistory{{AssetID: holdID, AsOf: today, Price: *hold.CurrentPrice, Currency: fetchedPriceCurrency}}); err != nil {
 			tx.Rollback()
 			return 0, fmt.Errorf("failed to seed price history for new asset: %w", err)
 		}
@@ -351,17 +587,20 @@ func (s *InvestmentService) InsertAsset(ctx context.Context, userID int64, req *
 }
 
 func (s *InvestmentService) fetchCurrentPrice(ctx context.Context, tx *gorm.DB, asset models.InvestmentAsset) (*decimal.Decimal, *time.Time) {
-	if s.priceFetchClient == nil {
+	if s.priceFetchClient == nil && !asset.ManualPricing {
 		return nil, nil
 	}
 
-	priceData, err := s.priceFetchClient.GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
+	priceData, price, err := s.latestUnitPrice(ctx, asset)
 	if err != nil {
 		return nil, nil
 	}
 
-	price := decimal.NewFromFloat(priceData.Price)
 	now := time.Unix(priceData.LastUpdate, 0)
+	if asset.ManualPricing || priceData.Error != nil {
+		// Already read from the price history, or a par fallback that isn't a real quote
+		return &price, &now
+	}
 
 	if err := s.repo.UpsertAssetPrice(ctx, nil, []models.AssetPriceHistory{{AssetID: asset.ID, AsOf: now, Price: price, Currency: priceData.Currency}}); err != nil {
 		fmt.Printf("warn: failed to upsert asset price history for asset %d: %v\n", asset.ID, err)
@@ -388,6 +627,13 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 		return 0, fmt.Errorf("can't find asset with given id %w", err)
 	}
 
+	// Trades are entered at the quoted price; holdings are kept per unit.
+	if !utils.QuoteIsUnitPrice(asset.InvestmentType) {
+		quoted := *req
+		quoted.PricePerUnit = utils.UnitPrice(asset, req.PricePerUnit, req.TxnDate)
+		req = &quoted
+	}
+
 	exchangeRate, err := s.GetExchangeRate(ctx, req.Currency, asset.Account.Currency, &req.TxnDate)
 	if err != nil {
 		return 0, err
@@ -402,6 +648,19 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 			req.Quantity.String())
 	}
 
+	var sellCostBasis decimal.Decimal
+	var lotPicks []models.InvestmentTradeLot
+	if req.TradeType == models.InvestmentSell {
+		sellCostBasis, lotPicks, err = s.matchSellLots(ctx, tx, userID, asset, req)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	} else if len(req.Lots) > 0 {
+		tx.Rollback()
+		return 0, errors.New("lots can only be picked for sell trades")
+	}
+
 	// Validate buy affordability — balance already reflects cash only
 	if req.TradeType == models.InvestmentBuy {
 		availableBalance, err := s.accRepo.FindLatestBalance(ctx, tx, asset.AccountID, userID)
@@ -415,7 +674,7 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 
 		purchaseCost := req.Quantity.Mul(req.PricePerUnit)
 		if req.Fee != nil {
-			if asset.InvestmentType == models.InvestmentStock || asset.InvestmentType == models.InvestmentETF {
+			if asset.InvestmentType != models.InvestmentCrypto {
 				purchaseCost = purchaseCost.Add(*req.Fee)
 			}
 		}
@@ -465,7 +724,7 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 		} else {
 			txnRealizedValue = req.Quantity.Mul(req.PricePerUnit).Sub(fee)
 		}
-		costBasis := asset.AverageBuyPrice.Mul(req.Quantity)
+		costBasis := sellCostBasis
 		txnProfitLoss = txnRealizedValue.Sub(costBasis)
 		txnCurrentValue, _, _ = s.calculateTradePnL(req.Quantity, currentPrice, costBasis)
 		if !costBasis.IsZero() {
@@ -481,7 +740,7 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 
 	txnValueAtBuy := valueAtBuy
 	if req.TradeType == models.InvestmentSell {
-		txnValueAtBuy = asset.AverageBuyPrice.Mul(req.Quantity)
+		txnValueAtBuy = sellCostBasis
 	}
 
 	txn := models.InvestmentTrade{
@@ -508,6 +767,14 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 		return 0, err
 	}
 
+	for i := range lotPicks {
+		lotPicks[i].SellTradeID = txnID
+	}
+	if err := s.repo.InsertTradeLots(ctx, tx, lotPicks); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	// Write cash flow to balances + update snapshots
 	txnDate := req.TxnDate.UTC().Truncate(24 * time.Hour)
 	today := time.Now().UTC().Truncate(24 * time.Hour)
@@ -553,6 +820,15 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 		return 0, err
 	}
 
+	// The proportional reduction above is a weighted average; restate the remaining
+	// cost basis under the asset's actual method.
+	if req.TradeType == models.InvestmentSell {
+		if err := s.repo.RecalculateAssetFromTrades(ctx, tx, asset.ID, userID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return 0, err
 	}
@@ -598,6 +874,74 @@ func (s *InvestmentService) InsertInvestmentTrade(ctx context.Context, userID in
 	return txnID, nil
 }
 
+// costBasisMethodFor resolves the cost-basis method for an asset: its own override,
+// otherwise the user's tax setting.
+func (s *InvestmentService) costBasisMethodFor(ctx context.Context, tx *gorm.DB, userID int64, asset models.InvestmentAsset) (models.CostBasisMethod, error) {
+	settings, err := s.repo.FindTaxSettings(ctx, tx, userID)
+	if err != nil {
+		return "", err
+	}
+	return utils.ResolveCostBasisMethod(asset, settings), nil
+}
+
+// matchSellLots matches a sell against the open buy lots as of its date, honouring
+// the asset's cost-basis method and any explicitly picked lots. It returns the cost
+// basis of the sold units and the picks to store with the trade.
+func (s *InvestmentService) matchSellLots(ctx context.Context, tx *gorm.DB, userID int64, asset models.InvestmentAsset, req *models.InvestmentTradeReq) (decimal.Decimal, []models.InvestmentTradeLot, error) {
+	method, err := s.costBasisMethodFor(ctx, tx, userID, asset)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+	if len(req.Lots) > 0 && method == models.CostBasisAverage {
+		return decimal.Zero, nil, errors.New("lots can't be picked when the asset uses weighted average cost")
+	}
+
+	trades, err := s.repo.FindAllTradesByAssetID(ctx, tx, asset.ID, userID)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+	var prior []models.InvestmentTrade
+	for _, t := range trades {
+		if !t.TxnDate.After(req.TxnDate) {
+			prior = append(prior, t)
+		}
+	}
+	lots := utils.BuildLots(prior, 0, method)
+
+	open := make(map[int64]decimal.Decimal, len(lots))
+	for _, lot := range lots {
+		open[lot.TradeID] = lot.Quantity
+	}
+
+	picks := make([]models.InvestmentTradeLot, 0, len(req.Lots))
+	picked := decimal.Zero
+	for _, l := range req.Lots {
+		if !l.Quantity.IsPositive() {
+			return decimal.Zero, nil, errors.New("picked lot quantity must be positive")
+		}
+		available, ok := open[l.BuyTradeID]
+		if !ok {
+			return decimal.Zero, nil, fmt.Errorf("buy trade %d is not an open lot of %s", l.BuyTradeID, asset.Ticker)
+		}
+		if l.Quantity.GreaterThan(available) {
+			return decimal.Zero, nil, fmt.Errorf("buy trade %d only has %s units left", l.BuyTradeID, available.String())
+		}
+		open[l.BuyTradeID] = available.Sub(l.Quantity)
+		picked = picked.Add(l.Quantity)
+		picks = append(picks, models.InvestmentTradeLot{BuyTradeID: l.BuyTradeID, Quantity: l.Quantity})
+	}
+	if picked.GreaterThan(req.Quantity) {
+		return decimal.Zero, nil, errors.New("picked lots exceed the quantity sold")
+	}
+
+	costBasis := decimal.Zero
+	for _, c := range utils.ConsumeLots(lots, req.TxnDate, req.Quantity, method, picks) {
+		costBasis = costBasis.Add(c.ValueAtBuy)
+	}
+
+	return costBasis, picks, nil
+}
+
 func (s *InvestmentService) handleSellTrade(ctx context.Context, tx *gorm.DB, asset models.InvestmentAsset, quantitySold, salePrice, fee decimal.Decimal, investmentType models.InvestmentType, txnDate time.Time, tradeCurrency string) error {
 
 	var proceeds decimal.Decimal
@@ -651,6 +995,10 @@ func (s *InvestmentService) BackfillInvestmentCashFlows(ctx context.Context, use
 	accountCurrency := make(map[int64]string)
 
 	for _, trade := range trades {
+		// In-kind transfer legs never moved cash
+		if trade.TransferID != nil {
+			continue
+		}
 		txnDate := trade.TxnDate.UTC().Truncate(24 * time.Hour)
 
 		if err := s.accRepo.EnsureDailyBalanceRow(ctx, tx, trade.Asset.AccountID, txnDate, trade.Asset.Account.Currency); err != nil {
@@ -737,7 +1085,7 @@ func (s *InvestmentService) CorrectTradeFeeAccounting(ctx context.Context, userI
 	affectedAssetIDs := map[int64]bool{}
 
 	for _, trade := range trades {
-		if trade.TradeType != models.InvestmentBuy {
+		if trade.TradeType != models.InvestmentBuy || trade.TransferID != nil {
 			continue
 		}
 		if trade.Asset.InvestmentType != models.InvestmentStock && trade.Asset.InvestmentType != models.InvestmentETF {
@@ -843,7 +1191,7 @@ func (s *InvestmentService) calculateTradePnL(quantity decimal.Decimal, currentP
 	return decimal.Zero, decimal.Zero, decimal.Zero
 }
 
-func (s *InvestmentService) UpdateInvestmentAsset(ctx context.Context, userID int64, id int64, req *models.InvestmentAssetReq) (int64, error) {
+func (s *InvestmentService) UpdateInvestmentAsset(ctx context.Context, userID int64, id int64, req *models.InvestmentAssetUpdateReq) (int64, error) {
 
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
@@ -864,17 +1212,79 @@ func (s *InvestmentService) UpdateInvestmentAsset(ctx context.Context, userID in
 		return 0, fmt.Errorf("can't find asset: %w", err)
 	}
 
-	hold := models.InvestmentAsset{
-		ID:     exHold.ID,
-		UserID: userID,
-		Name:   req.Name,
+	// Only the columns that were sent are written
+	hold := exHold
+	fields := map[string]interface{}{}
+	if req.Name != nil {
+		hold.Name = *req.Name
+		fields["name"] = hold.Name
+	}
+	if req.CostBasisMethod != nil {
+		hold.CostBasisMethod = nil
+		if *req.CostBasisMethod != "" {
+			hold.CostBasisMethod = req.CostBasisMethod
+		}
+		fields["cost_basis_method"] = hold.CostBasisMethod
+	}
+	if req.AssetClass != nil {
+		hold.AssetClass = utils.NormalizeAssetClass(req.AssetClass)
+		fields["asset_class"] = hold.AssetClass
 	}
+	if req.PriceProvider != nil {
+		hold.PriceProvider = nil
+		if *req.PriceProvider != "" {
+			hold.PriceProvider = req.PriceProvider
+		}
+		fields["price_provider"] = hold.PriceProvider
+	}
+	if req.ManualPricing != nil {
+		hold.ManualPricing = *req.ManualPricing
+		fields["manual_pricing"] = hold.ManualPricing
+	}
+
+	holdID := exHold.ID
 
-	holdID, err := s.repo.UpdateInvestmentAsset(ctx, tx, hold)
+	// Stored sells keep the cost basis they were matched with, so the method is fixed once there are any
+	oldEffective, err := s.costBasisMethodFor(ctx, tx, userID, exHold)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
+	newEffective, err := s.costBasisMethodFor(ctx, tx, userID, hold)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if oldEffective != newEffective {
+		sells, err := s.repo.CountSellTrades(ctx, tx, []int64{holdID})
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if sells > 0 {
+			tx.Rollback()
+			return 0, fmt.Errorf("can't change the cost-basis method of an asset that already has sells")
+		}
+	}
+
+	if err := s.repo.UpdateInvestmentAsset(ctx, tx, holdID, fields); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	oldMethod, newMethod := "", ""
+	if exHold.CostBasisMethod != nil {
+		oldMethod = string(*exHold.CostBasisMethod)
+	}
+	if hold.CostBasisMethod != nil {
+		newMethod = string(*hold.CostBasisMethod)
+	}
+	if oldMethod != newMethod {
+		if err := s.repo.RecalculateAssetFromTrades(ctx, tx, holdID, userID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
 
 	if err := tx.Commit().Error; err != nil {
 		return 0, err
@@ -882,6 +1292,10 @@ func (s *InvestmentService) UpdateInvestmentAsset(ctx context.Context, userID in
 
 	changes := utils.InitChanges()
 	utils.CompareChanges(exHold.Name, hold.Name, changes, "name")
+	utils.CompareChanges(oldMethod, newMethod, changes, "cost_basis_method")
+	utils.CompareChanges(utils.SafeString(exHold.AssetClass), utils.SafeString(hold.AssetClass), changes, "asset_class")
+	utils.CompareChanges(utils.SafeString(exHold.PriceProvider), utils.SafeString(hold.PriceProvider), changes, "price_provider")
+	utils.CompareChanges(strconv.FormatBool(exHold.ManualPricing), strconv.FormatBool(hold.ManualPricing), changes, "manual_pricing")
 
 	if changes.HasChanges() {
 		changes.Stamp("id", strconv.FormatInt(holdID, 10))
@@ -921,6 +1335,10 @@ func (s *InvestmentService) UpdateInvestmentTrade(ctx context.Context, userID in
 	if err != nil {
 		return 0, fmt.Errorf("can't find investment trade with given id %w", err)
 	}
+	if exTxn.TransferID != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("cannot edit a transferred trade, delete the transfer instead")
+	}
 
 	// Load existing relations
 	asset, err := s.repo.FindInvestmentAssetByID(ctx, tx, exTxn.AssetID, userID)
@@ -996,6 +1414,16 @@ func (s *InvestmentService) DeleteInvestmentAsset(ctx context.Context, userID in
 		return fmt.Errorf("can't find asset: %w", err)
 	}
 
+	transfers, err := s.repo.CountTransfersForAsset(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if transfers > 0 {
+		tx.Rollback()
+		return fmt.Errorf("asset has in-kind transfers, delete them first")
+	}
+
 	earliestTxnDate, err := s.repo.GetEarliestTradeDate(ctx, tx, id, userID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		tx.Rollback()
@@ -1122,6 +1550,19 @@ func (s *InvestmentService) DeleteInvestmentTrade(ctx context.Context, userID in
 		return fmt.Errorf("can't find asset: %w", err)
 	}
 
+	if exTxn.TransferID != nil {
+		tx.Rollback()
+		return fmt.Errorf("cannot delete a transferred trade, delete the transfer instead")
+	}
+
+	if _, err := s.repo.FindInvestmentIncomeByReinvestTrade(ctx, tx, exTxn.ID, userID); err == nil {
+		tx.Rollback()
+		return fmt.Errorf("cannot delete a reinvested dividend's trade, delete the dividend instead")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return err
+	}
+
 	// Validate deletion: check if removing this buy would cause negative quantity
 	if exTxn.TradeType == models.InvestmentBuy {
 		newQuantity := asset.Quantity.Sub(exTxn.Quantity)
@@ -1233,15 +1674,14 @@ func (s *InvestmentService) UpsertAssetPrice(ctx context.Context, tx *gorm.DB, e
 }
 
 func (s *InvestmentService) RecalculateAssetPnL(ctx context.Context, userID, assetID int64) error {
-	if s.priceFetchClient != nil {
-		asset, err := s.repo.FindInvestmentAssetByID(ctx, nil, assetID, userID)
-		if err != nil {
-			return err
-		}
-		priceData, err := s.priceFetchClient.GetAssetPrice(ctx, asset.Ticker, asset.InvestmentType)
+	asset, err := s.repo.FindInvestmentAssetByID(ctx, nil, assetID, userID)
+	if err != nil {
+		return err
+	}
+	if s.priceFetchClient != nil || asset.ManualPricing {
+		priceData, price, err := s.latestUnitPrice(ctx, asset)
 		if err == nil && priceData != nil && priceData.Price > 0 {
 			now := time.Now().UTC()
-			price := decimal.NewFromFloat(priceData.Price)
 			if err := s.repo.UpdateAssetCurrentPrice(ctx, nil, assetID, price, now); err != nil {
 				return err
 			}
@@ -1319,14 +1759,24 @@ func (s *InvestmentService) CreateInvestmentIncome(ctx context.Context, userID i
 	} else {
 		if req.Amount == nil || !req.Amount.IsPositive() {
 			tx.Rollback()
-			return 0, fmt.Errorf("amount is required and must be positive for dividend income")
+			return 0, fmt.Errorf("amount is required and must be positive for dividends and coupons")
 		}
 	}
 
+	if req.IncomeType == models.IncomeTypeCoupon && asset.InvestmentType != models.InvestmentBond {
+		tx.Rollback()
+		return 0, fmt.Errorf("coupons can only be recorded on bonds")
+	}
+
+	if req.Reinvest && req.IncomeType != models.IncomeTypeDividend {
+		tx.Rollback()
+		return 0, fmt.Errorf("only dividends can be reinvested, staking rewards already add to the position")
+	}
+
 	// For staking, calculate fair market value from price at income date
 	incomeAmount := decimal.Zero
 	if req.IncomeType == models.IncomeTypeStaking {
-		priceData, err := s.priceFetchClient.GetAssetPriceOnDate(ctx, asset.Ticker, asset.InvestmentType, req.TxnDate)
+		priceData, err := s.fetcherFor(asset).GetAssetPriceOnDate(ctx, asset.Ticker, asset.InvestmentType, req.TxnDate)
 		if err == nil && priceData != nil && priceData.Price > 0 {
 			incomeAmount = req.Quantity.Mul(decimal.NewFromFloat(priceData.Price))
 		}
@@ -1359,7 +1809,15 @@ func (s *InvestmentService) CreateInvestmentIncome(ctx context.Context, userID i
 		}
 	}
 
-	if req.IncomeType == models.IncomeTypeDividend {
+	if req.IncomeType == models.IncomeTypeCoupon {
+		if err := s.repo.AdvanceLastCouponDate(ctx, tx, asset.ID, record.TxnDate); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	// Dividends and coupons are paid out in cash
+	if req.IncomeType != models.IncomeTypeStaking {
 		category, err := s.txnRepo.FindCategoryByClassification(ctx, tx, "uncategorized", &userID)
 		if err != nil {
 			tx.Rollback()
@@ -1379,6 +1837,9 @@ func (s *InvestmentService) CreateInvestmentIncome(ctx context.Context, userID i
 			dividendAmount = grossAmount.Mul(rate)
 		}
 		desc := "Dividend: " + asset.Ticker
+		if req.IncomeType == models.IncomeTypeCoupon {
+			desc = "Coupon: " + asset.Ticker
+		}
 		txn := &models.Transaction{
 			UserID:          userID,
 			AccountID:       asset.AccountID,
@@ -1399,13 +1860,39 @@ func (s *InvestmentService) CreateInvestmentIncome(ctx context.Context, userID i
 			tx.Rollback()
 			return 0, fmt.Errorf("failed to link dividend transaction: %w", err)
 		}
+
+		if err := s.accRepo.EnsureDailyBalanceRow(ctx, tx, asset.AccountID, record.TxnDate, asset.Account.Currency); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := s.accRepo.AddToDailyBalance(ctx, tx, asset.AccountID, record.TxnDate, "cash_inflows", dividendAmount); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if req.Reinvest {
+			if err := s.reinvestDividend(ctx, tx, userID, asset, &record, req.Quantity, dividendAmount); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if err := s.accRepo.FrontfillBalances(ctx, tx, asset.AccountID, asset.Account.Currency, record.TxnDate); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := s.accRepo.UpsertSnapshotsFromBalances(ctx, tx, userID, asset.AccountID, asset.Account.Currency, record.TxnDate, today); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		return 0, err
 	}
 
-	if req.IncomeType == models.IncomeTypeStaking {
+	if req.IncomeType == models.IncomeTypeStaking || req.Reinvest {
 		txnDate := req.TxnDate.UTC().Truncate(24 * time.Hour)
 		if err := s.jobDispatcher.Dispatch(ctx, queue_jobs.NewSyncAssetAfterTradeJob(
 			s.logger.Named("sync_after_income"),
@@ -1423,17 +1910,165 @@ func (s *InvestmentService) CreateInvestmentIncome(ctx context.Context, userID i
 	return id, nil
 }
 
-func (s *InvestmentService) DeleteInvestmentIncome(ctx context.Context, userID int64, id int64) error {
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		return err
+// reinvestDividend spends a dividend's net amount on a buy of the same asset. The
+// buy draws cash, the dividend's account-currency amount, like any other purchase,
+// so together with the dividend's inflow the account's cash is left where it was.
+func (s *InvestmentService) reinvestDividend(ctx context.Context, tx *gorm.DB, userID int64, asset models.InvestmentAsset, income *models.InvestmentIncome, quantity *decimal.Decimal, cash decimal.Decimal) error {
+	net := income.Amount
+	if income.TaxWithheld != nil {
+		net = net.Sub(*income.TaxWithheld)
 	}
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		}
-	}()
+	if !net.IsPositive() {
+		return fmt.Errorf("nothing left to reinvest after tax withheld")
+	}
+
+	var qty decimal.Decimal
+	if quantity != nil && quantity.IsPositive() {
+		qty = *quantity
+	} else {
+		if s.priceFetchClient == nil {
+			return fmt.Errorf("quantity is required to reinvest a dividend")
+		}
+		priceData, err := s.fetcherFor(asset).GetAssetPriceOnDate(ctx, asset.Ticker, asset.InvestmentType, income.TxnDate)
+		if err != nil || priceData == nil || priceData.Price <= 0 {
+			return fmt.Errorf("can't price %s on %s, quantity is required to reinvest", asset.Ticker, income.TxnDate.Format("2006-01-02"))
+		}
+		price := utils.UnitPrice(asset, decimal.NewFromFloat(priceData.Price), income.TxnDate)
+		if priceData.Currency != "" && priceData.Currency != income.Currency {
+			rate, err := s.GetExchangeRate(ctx, priceData.Currency, income.Currency, &income.TxnDate)
+			if err != nil {
+				return err
+			}
+			price = price.Mul(rate)
+		}
+		qty = net.Div(price).Truncate(8)
+		if !qty.IsPositive() {
+			return fmt.Errorf("dividend is too small to buy any %s", asset.Ticker)
+		}
+	}
+
+	exchangeRateToUSD, err := s.GetExchangeRate(ctx, income.Currency, "USD", &income.TxnDate)
+	if err != nil {
+		return err
+	}
+
+	currentPrice, _ := s.fetchCurrentPrice(ctx, tx, asset)
+	currentValue, profitLoss, profitLossPercent := s.calculateTradePnL(qty, currentPrice, net)
+
+	desc := "Reinvested dividend"
+	trade := models.InvestmentTrade{
+		UserID:            userID,
+		AssetID:           asset.ID,
+		TxnDate:           income.TxnDate,
+		TradeType:         models.InvestmentBuy,
+		Quantity:          qty,
+		PricePerUnit:      net.Div(qty).Round(4),
+		ValueAtBuy:        net,
+		CurrentValue:      currentValue,
+		ProfitLoss:        profitLoss,
+		ProfitLossPercent: profitLossPercent,
+		Currency:          income.Currency,
+		ExchangeRateToUSD: exchangeRateToUSD,
+		Description:       &desc,
+	}
+
+	tradeID, err := s.repo.InsertInvestmentTrade(ctx, tx, &trade)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.InvestmentIncome{}).Where("id = ?", income.ID).
+		Updates(map[string]interface{}{"reinvest_trade_id": tradeID, "quantity": qty}).Error; err != nil {
+		return fmt.Errorf("failed to link reinvested trade: %w", err)
+	}
+	income.ReinvestTradeID = &tradeID
+	income.Quantity = &qty
+
+	if err := s.accRepo.AddToDailyBalance(ctx, tx, asset.AccountID, income.TxnDate, "cash_outflows", cash); err != nil {
+		return err
+	}
+
+	return s.repo.RecalculateAssetFromTrades(ctx, tx, asset.ID, userID)
+}
+
+func (s *InvestmentService) FetchCouponBonds(ctx context.Context) ([]models.InvestmentAsset, error) {
+	return s.repo.FindCouponBonds(ctx, nil)
+}
+
+// RecordDueCoupons books the bond's coupons that fell due since the last recorded
+// one, each on the units held the day before it was paid.
+func (s *InvestmentService) RecordDueCoupons(ctx context.Context, asset models.InvestmentAsset, today time.Time) (int, error) {
+	trades, err := s.repo.FindAllTradesByAssetID(ctx, nil, asset.ID, asset.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Booked coupons move the asset's last coupon date, which a deletion leaves in place
+	since := asset.LastCouponDate
+	if since == nil {
+		start := asset.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if len(trades) > 0 && trades[0].TxnDate.Before(start) {
+			start = trades[0].TxnDate.UTC().Truncate(24 * time.Hour)
+		}
+		since = &start
+	}
+
+	// Units that didn't come from trades (the opening quantity) are held throughout
+	baseline := asset.Quantity
+	for _, t := range trades {
+		if t.TradeType == models.InvestmentBuy {
+			baseline = baseline.Sub(t.Quantity)
+		} else {
+			baseline = baseline.Add(t.Quantity)
+		}
+	}
+
+	recorded := 0
+	for _, due := range utils.CouponDates(asset, *since, today) {
+		held := baseline
+		for _, t := range trades {
+			if !t.TxnDate.Before(due) {
+				continue
+			}
+			if t.TradeType == models.InvestmentBuy {
+				held = held.Add(t.Quantity)
+			} else {
+				held = held.Sub(t.Quantity)
+			}
+		}
+		if !held.IsPositive() {
+			continue
+		}
+
+		amount := held.Mul(utils.CouponAmount(asset)).Round(4)
+		notes := "Scheduled coupon"
+		if _, err := s.CreateInvestmentIncome(ctx, asset.UserID, &models.InvestmentIncomeReq{
+			AssetID:    asset.ID,
+			TxnDate:    due,
+			IncomeType: models.IncomeTypeCoupon,
+			Amount:     &amount,
+			Currency:   asset.Currency,
+			Notes:      &notes,
+		}); err != nil {
+			return recorded, fmt.Errorf("failed to record coupon on %s: %w", due.Format("2006-01-02"), err)
+		}
+		recorded++
+	}
+
+	return recorded, nil
+}
+
+func (s *InvestmentService) DeleteInvestmentIncome(ctx context.Context, userID int64, id int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
 
 	income, err := s.repo.FindInvestmentIncomeByID(ctx, tx, id, userID)
 	if err != nil {
@@ -1447,10 +2082,33 @@ func (s *InvestmentService) DeleteInvestmentIncome(ctx context.Context, userID i
 		return fmt.Errorf("can't find asset: %w", err)
 	}
 
+	// Cash the payout brought in, in the account's currency
+	var linked models.Transaction
 	if income.LinkedTransactionID != nil {
-		if err := s.txnRepo.DeleteTransaction(ctx, tx, *income.LinkedTransactionID, userID); err != nil {
+		linked, err = s.txnRepo.FindTransactionByID(ctx, tx, *income.LinkedTransactionID, userID, true)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("can't find linked dividend transaction: %w", err)
+		}
+		// A linked transaction deleted on its own already gave its cash back
+		if linked.DeletedAt == nil {
+			if err := s.txnRepo.DeleteTransaction(ctx, tx, linked.ID, userID); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to delete linked dividend transaction: %w", err)
+			}
+		}
+	}
+
+	var reinvested models.InvestmentTrade
+	if income.ReinvestTradeID != nil {
+		reinvested, err = s.repo.FindInvestmentTradeByID(ctx, tx, *income.ReinvestTradeID, userID)
+		if err != nil {
 			tx.Rollback()
-			return fmt.Errorf("failed to delete linked dividend transaction: %w", err)
+			return fmt.Errorf("can't find reinvested trade: %w", err)
+		}
+		if asset.Quantity.Sub(reinvested.Quantity).IsNegative() {
+			tx.Rollback()
+			return fmt.Errorf("cannot delete income: the reinvested %s units were already sold", reinvested.Quantity.String())
 		}
 	}
 
@@ -1459,7 +2117,14 @@ func (s *InvestmentService) DeleteInvestmentIncome(ctx context.Context, userID i
 		return err
 	}
 
-	if income.IncomeType == models.IncomeTypeStaking {
+	if income.ReinvestTradeID != nil {
+		if err := s.repo.DeleteInvestmentTrade(ctx, tx, reinvested.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if income.IncomeType == models.IncomeTypeStaking || income.LinkedTransactionID != nil {
 		if err := s.repo.RecalculateAssetFromTrades(ctx, tx, asset.ID, userID); err != nil {
 			tx.Rollback()
 			return err
@@ -1473,6 +2138,19 @@ func (s *InvestmentService) DeleteInvestmentIncome(ctx context.Context, userID i
 			return err
 		}
 
+		if income.LinkedTransactionID != nil && linked.DeletedAt == nil {
+			if err := s.accRepo.AddToDailyBalance(ctx, tx, asset.AccountID, incomeDate, "cash_inflows", linked.Amount.Neg()); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if income.ReinvestTradeID != nil && income.LinkedTransactionID != nil {
+			if err := s.accRepo.AddToDailyBalance(ctx, tx, asset.AccountID, incomeDate, "cash_outflows", linked.Amount.Neg()); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
 		if err := s.accRepo.FrontfillBalances(ctx, tx, asset.AccountID, asset.Account.Currency, incomeDate); err != nil {
 			tx.Rollback()
 			return err
@@ -1492,8 +2170,13 @@ func (s *InvestmentService) BackfillAssetPriceHistory(ctx context.Context, asset
 		return nil
 	}
 
-	from = from.UTC().Truncate(24 * time.Hour)
-	to = to.UTC().Truncate(24 * time.Hour)
+	asset, err := s.repo.FindInvestmentAssetForPricing(ctx, nil, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.ManualPricing {
+		return nil
+	}
 
 	existing, err := s.repo.GetPriceHistoryForAsset(ctx, nil, assetID)
 	if err != nil {
@@ -1505,8 +2188,74 @@ func (s *InvestmentService) BackfillAssetPriceHistory(ctx context.Context, asset
 		existingSet[p.AsOf.UTC().Truncate(24*time.Hour).Format("2006-01-02")] = true
 	}
 
+	prices, err := s.fetchMissingPrices(ctx, s.fetcherFor(asset), ticker, investmentType, from, to, existingSet)
+	if err != nil {
+		return err
+	}
+
+	if !utils.QuoteIsUnitPrice(investmentType) {
+		for i := range prices {
+			prices[i].price = utils.UnitPrice(asset, prices[i].price, prices[i].asOf)
+		}
+	}
+
+	batch := make([]models.AssetPriceHistory, len(prices))
+	for i, p := range prices {
+		batch[i] = models.AssetPriceHistory{AssetID: assetID, AsOf: p.asOf, Price: p.price, Currency: p.currency}
+	}
+
+	if len(batch) > 0 {
+		return s.repo.UpsertAssetPrice(ctx, nil, batch)
+	}
+	return nil
+}
+
+// BackfillBenchmarkPriceHistory fills a benchmark's price history the same way as an asset's.
+func (s *InvestmentService) BackfillBenchmarkPriceHistory(ctx context.Context, benchmarkID int64, ticker string, investmentType models.InvestmentType, from, to time.Time) error {
+	if s.priceFetchClient == nil {
+		return nil
+	}
+
+	existing, err := s.repo.GetPriceHistoryForBenchmark(ctx, nil, benchmarkID)
+	if err != nil {
+		return err
+	}
+
+	existingSet := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		existingSet[p.AsOf.UTC().Truncate(24*time.Hour).Format("2006-01-02")] = true
+	}
+
+	prices, err := s.fetchMissingPrices(ctx, s.priceFetchClient, ticker, investmentType, from, to, existingSet)
+	if err != nil {
+		return err
+	}
+
+	batch := make([]models.BenchmarkPriceHistory, len(prices))
+	for i, p := range prices {
+		batch[i] = models.BenchmarkPriceHistory{BenchmarkID: benchmarkID, AsOf: p.asOf, Price: p.price, Currency: p.currency}
+	}
+
+	if len(batch) > 0 {
+		return s.repo.UpsertBenchmarkPrice(ctx, nil, batch)
+	}
+	return nil
+}
+
+type datedPrice struct {
+	asOf     time.Time
+	price    decimal.Decimal
+	currency string
+}
+
+// fetchMissingPrices fetches a ticker's closing price for every weekday in
+// [from, to] that isn't already in existing (keyed by YYYY-MM-DD).
+func (s *InvestmentService) fetchMissingPrices(ctx context.Context, fetcher finance.PriceFetcher, ticker string, investmentType models.InvestmentType, from, to time.Time, existing map[string]bool) ([]datedPrice, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+
 	current := from
-	var batch []models.AssetPriceHistory
+	var batch []datedPrice
 	requestCount := 0
 
 	for !current.After(to) {
@@ -1517,7 +2266,7 @@ func (s *InvestmentService) BackfillAssetPriceHistory(ctx context.Context, asset
 			continue
 		}
 
-		if existingSet[dateKey] {
+		if existing[dateKey] {
 			current = current.AddDate(0, 0, 1)
 			continue
 		}
@@ -1525,12 +2274,12 @@ func (s *InvestmentService) BackfillAssetPriceHistory(ctx context.Context, asset
 		if requestCount > 0 && requestCount%10 == 0 {
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil, ctx.Err()
 			case <-time.After(500 * time.Millisecond):
 			}
 		}
 
-		priceData, err := s.priceFetchClient.GetAssetPriceOnDate(ctx, ticker, investmentType, current)
+		priceData, err := fetcher.GetAssetPriceOnDate(ctx, ticker, investmentType, current)
 		if err != nil {
 			s.logger.Debug("Failed to fetch historical price", zap.String("ticker", ticker), zap.String("date", dateKey), zap.Error(err))
 			current = current.AddDate(0, 0, 1)
@@ -1545,21 +2294,13 @@ func (s *InvestmentService) BackfillAssetPriceHistory(ctx context.Context, asset
 			continue
 		}
 
-		batch = append(batch, models.AssetPriceHistory{
-			AssetID:  assetID,
-			AsOf:     current,
-			Price:    price,
-			Currency: priceData.Currency,
-		})
+		batch = append(batch, datedPrice{asOf: current, price: price, currency: priceData.Currency})
 
 		requestCount++
 		current = current.AddDate(0, 0, 1)
 	}
 
-	if len(batch) > 0 {
-		return s.repo.UpsertAssetPrice(ctx, nil, batch)
-	}
-	return nil
+	return batch, nil
 }
 
 func (s *InvestmentService) FetchTaxBrackets(ctx context.Context, userID int64) ([]models.InvestmentTaxBracket, error) {
@@ -1612,10 +2353,89 @@ func (s *InvestmentService) FetchTaxSettings(ctx context.Context, userID int64)
 }
 
 func (s *InvestmentService) SaveTaxSettings(ctx context.Context, userID int64, req *models.InvestmentTaxSettingsReq) error {
-	return s.repo.UpsertTaxSettings(ctx, nil, models.InvestmentTaxSettings{
+	if req.TaxFreeAlertMinValue != nil && req.TaxFreeAlertMinValue.IsNegative() {
+		return errors.New("tax-free alert value can't be negative")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindTaxSettings(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	method := req.CostBasisMethod
+	if method == "" {
+		method = existing.CostBasisMethod
+	}
+	if method == "" {
+		method = models.CostBasisFIFO
+	}
+
+	alertMinValue := existing.TaxFreeAlertMinValue
+	if req.TaxFreeAlertMinValue != nil {
+		alertMinValue = *req.TaxFreeAlertMinValue
+	}
+
+	// Remaining cost basis of every asset without its own method depends on this setting
+	previous := existing.CostBasisMethod
+	if previous == "" {
+		previous = models.CostBasisFIFO
+	}
+	var inheriting []int64
+	if previous != method {
+		assets, err := s.repo.FindAllInvestmentAssets(ctx, tx, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		for _, a := range assets {
+			if a.CostBasisMethod == nil || *a.CostBasisMethod == "" {
+				inheriting = append(inheriting, a.ID)
+			}
+		}
+
+		// Stored sells keep the cost basis they were matched with
+		sells, err := s.repo.CountSellTrades(ctx, tx, inheriting)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if sells > 0 {
+			tx.Rollback()
+			return errors.New("can't change the default cost-basis method while assets using it have sells, set a method on new assets instead")
+		}
+	}
+
+	if err := s.repo.UpsertTaxSettings(ctx, tx, models.InvestmentTaxSettings{
 		UserID:                userID,
 		LossOffsettingEnabled: req.LossOffsettingEnabled,
-	})
+		CostBasisMethod:       method,
+		TaxFreeAlertMinValue:  alertMinValue,
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, id := range inheriting {
+		if err := s.repo.RecalculateAssetFromTrades(ctx, tx, id, userID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
 }
 
 func (s *InvestmentService) CopyTaxBrackets(ctx context.Context, userID int64, fromType, toType models.InvestmentType) error {
@@ -1668,6 +2488,79 @@ func (s *InvestmentService) CopyTaxBrackets(ctx context.Context, userID int64, f
 	return nil
 }
 
+// FetchTaxHints ranks open positions for tax-loss harvesting and lots worth
+// holding until they reach a lower bracket within window days.
+func (s *InvestmentService) FetchTaxHints(ctx context.Context, userID int64, window int) (*models.TaxOptimizationHints, error) {
+	trades, err := s.repo.FindAllTradesByUserID(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	brackets, err := s.repo.FindTaxBracketsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.repo.FindTaxSettings(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := utils.TaxHints(trades, brackets, settings, time.Now().UTC(), window)
+	return &hints, nil
+}
+
+func (s *InvestmentService) FetchTaxFreeAlertUsers(ctx context.Context) ([]int64, error) {
+	return s.repo.FindTaxFreeBracketUserIDs(ctx, nil)
+}
+
+// taxFreeAlertLead is how many days ahead a lot turning tax-free is warned about.
+const taxFreeAlertLead = 7
+
+// MarkTaxFreeAlerts returns the lots worth at least the user's alert value that
+// become tax-free within a week and weren't warned about yet, and marks them so
+// each lot is only announced once.
+func (s *InvestmentService) MarkTaxFreeAlerts(ctx context.Context, userID int64, today time.Time) ([]models.TaxHoldCandidate, error) {
+	settings, err := s.repo.FindTaxSettings(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.TaxFreeAlertMinValue.IsPositive() {
+		return nil, nil
+	}
+
+	trades, err := s.repo.FindAllTradesByUserID(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	brackets, err := s.repo.FindTaxBracketsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	alerted := make(map[int64]bool)
+	for _, t := range trades {
+		alerted[t.ID] = t.TaxFreeAlertedAt != nil
+	}
+
+	var due []models.TaxHoldCandidate
+	var ids []int64
+	for _, lot := range utils.TaxHints(trades, brackets, settings, today, taxFreeAlertLead).Hold {
+		if lot.DaysUntilTaxFree == nil || *lot.DaysUntilTaxFree <= 0 || *lot.DaysUntilTaxFree > taxFreeAlertLead {
+			continue
+		}
+		if alerted[lot.TradeID] || lot.MarketValue.LessThan(settings.TaxFreeAlertMinValue) {
+			continue
+		}
+		due = append(due, lot)
+		ids = append(ids, lot.TradeID)
+	}
+
+	if err := s.repo.SetTaxFreeAlertedAt(ctx, nil, ids, &today); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
 func (s *InvestmentService) MigrateZeroCostTradesForAsset(ctx context.Context, userID, assetID int64, trades []models.InvestmentTrade) error {
 	if len(trades) == 0 {
 		return nil
@@ -1714,7 +2607,7 @@ func (s *InvestmentService) MigrateZeroCostTradesForAsset(ctx context.Context, u
 		}
 
 		var amount decimal.Decimal
-		priceData, err := s.priceFetchClient.GetAssetPriceOnDate(ctx, asset.Ticker, asset.InvestmentType, txnDate)
+		priceData, err := s.fetcherFor(asset).GetAssetPriceOnDate(ctx, asset.Ticker, asset.InvestmentType, txnDate)
 		if err == nil && priceData != nil && priceData.Price > 0 {
 			amount = trade.Quantity.Mul(decimal.NewFromFloat(priceData.Price))
 		}
@@ -1800,3 +2693,1288 @@ func (s *InvestmentService) MigrateZeroCostTradesForAsset(ctx context.Context, u
 
 	return tx.Commit().Error
 }
+
+func (s *InvestmentService) FetchCorporateActions(ctx context.Context, userID, assetID int64) ([]models.CorporateAction, error) {
+	if _, err := s.repo.FindInvestmentAssetByID(ctx, nil, assetID, userID); err != nil {
+		return nil, fmt.Errorf("asset not found: %w", err)
+	}
+	return s.repo.FindCorporateActionsByAsset(ctx, nil, assetID, userID)
+}
+
+// ApplyCorporateAction records a corporate action on an asset and restates its history.
+// Splits, reverse splits and mergers rescale every trade, staking reward and price
+// dated before the effective date; a spin-off opens a new holding whose lots mirror
+// the parent's open lots and take over part of their cost basis.
+func (s *InvestmentService) ApplyCorporateAction(ctx context.Context, userID, assetID int64, req *models.CorporateActionReq) (int64, error) {
+	effective := req.EffectiveDate.UTC().Truncate(24 * time.Hour)
+	if effective.After(time.Now().UTC()) {
+		return 0, errors.New("effective date can't be in the future")
+	}
+
+	ratioFrom := decimal.NewFromInt(1)
+	if req.RatioFrom != nil {
+		ratioFrom = *req.RatioFrom
+	}
+	ratioTo := decimal.NewFromInt(1)
+	if req.RatioTo != nil {
+		ratioTo = *req.RatioTo
+	}
+	if !ratioFrom.IsPositive() || !ratioTo.IsPositive() {
+		return 0, errors.New("ratio must be positive")
+	}
+
+	switch req.ActionType {
+	case models.CorporateActionSplit:
+		if !ratioTo.GreaterThan(ratioFrom) {
+			return 0, errors.New("a split must increase the number of units")
+		}
+	case models.CorporateActionReverseSplit:
+		if !ratioTo.LessThan(ratioFrom) {
+			return 0, errors.New("a reverse split must decrease the number of units")
+		}
+	case models.CorporateActionTickerChange, models.CorporateActionMerger:
+		if req.NewTicker == nil || strings.TrimSpace(*req.NewTicker) == "" {
+			return 0, errors.New("new ticker is required")
+		}
+	case models.CorporateActionSpinOff:
+		if req.NewTicker == nil || strings.TrimSpace(*req.NewTicker) == "" || req.NewName == nil || strings.TrimSpace(*req.NewName) == "" {
+			return 0, errors.New("spin-off requires the new ticker and name")
+		}
+		if req.CostBasisPercent == nil || !req.CostBasisPercent.IsPositive() || !req.CostBasisPercent.LessThan(decimal.NewFromInt(1)) {
+			return 0, errors.New("spin-off cost basis percent must be between 0 and 1")
+		}
+	default:
+		return 0, fmt.Errorf("unsupported corporate action type: %s", req.ActionType)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	asset, err := s.repo.FindInvestmentAssetByID(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("asset not found: %w", err)
+	}
+
+	var newTicker string
+	if req.NewTicker != nil {
+		newTicker = strings.ToUpper(strings.TrimSpace(*req.NewTicker))
+		if newTicker == asset.Ticker {
+			tx.Rollback()
+			return 0, errors.New("new ticker must differ from the current one")
+		}
+		if _, err := s.repo.FindAssetByTicker(ctx, tx, newTicker, asset.AccountID, userID); err == nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("account already holds an asset with ticker %s", newTicker)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	trades, err := s.repo.FindAllTradesByAssetID(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	var before []models.InvestmentTrade
+	for _, t := range trades {
+		if t.TxnDate.Before(effective) {
+			before = append(before, t)
+		}
+	}
+	if req.ActionType != models.CorporateActionTickerChange && len(before) == 0 {
+		tx.Rollback()
+		return 0, errors.New("asset has no trades before the effective date")
+	}
+
+	oldTicker := asset.Ticker
+	oldName := asset.Name
+	record := models.CorporateAction{
+		UserID:           userID,
+		AssetID:          assetID,
+		ActionType:       req.ActionType,
+		EffectiveDate:    effective,
+		RatioFrom:        ratioFrom,
+		RatioTo:          ratioTo,
+		OldTicker:        &oldTicker,
+		OldName:          &oldName,
+		NewName:          req.NewName,
+		CostBasisPercent: req.CostBasisPercent,
+		Notes:            req.Notes,
+	}
+	if newTicker != "" {
+		record.NewTicker = &newTicker
+	}
+
+	factor := record.Factor()
+
+	switch req.ActionType {
+	case models.CorporateActionSplit, models.CorporateActionReverseSplit, models.CorporateActionMerger:
+		if !factor.Equal(decimal.NewFromInt(1)) {
+			if err := s.repo.ApplySplitFactor(ctx, tx, assetID, userID, effective, factor); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+	}
+
+	switch req.ActionType {
+	case models.CorporateActionTickerChange, models.CorporateActionMerger:
+		name := asset.Name
+		if req.NewName != nil && strings.TrimSpace(*req.NewName) != "" {
+			name = strings.TrimSpace(*req.NewName)
+		}
+		if err := s.repo.UpdateAssetIdentity(ctx, tx, assetID, newTicker, name); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+	case models.CorporateActionSpinOff:
+		childID, err := s.spinOffAsset(ctx, tx, userID, asset, before, newTicker, strings.TrimSpace(*req.NewName), factor, *req.CostBasisPercent)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		record.SpunOffAssetID = &childID
+	}
+
+	actionID, err := s.repo.InsertCorporateAction(ctx, tx, &record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if req.ActionType == models.CorporateActionSpinOff {
+		if err := s.moveSpunOffBasis(ctx, tx, userID, asset, before, actionID, effective, *req.CostBasisPercent); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	// Trades keep their stored current value until the next price sync, so restate
+	// them at the (possibly rescaled) current price now.
+	adjusted, err := s.repo.FindInvestmentAssetByID(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if adjusted.CurrentPrice != nil {
+		if err := s.repo.UpdateTradesPnLForAsset(ctx, tx, assetID, *adjusted.CurrentPrice, adjusted.InvestmentType, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := s.repo.RecalculateAssetFromTrades(ctx, tx, assetID, userID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if err := s.RecalculateAssetPnL(ctx, userID, assetID); err != nil {
+		return 0, err
+	}
+	if record.SpunOffAssetID != nil {
+		if err := s.RecalculateAssetPnL(ctx, userID, *record.SpunOffAssetID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil); err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", asset.Name, changes, "asset")
+	utils.CompareChanges("", string(record.ActionType), changes, "type")
+	utils.CompareDateChange(nil, &record.EffectiveDate, changes, "effective_date")
+	if !factor.Equal(decimal.NewFromInt(1)) {
+		utils.CompareChanges("", ratioFrom.String()+":"+ratioTo.String(), changes, "ratio")
+	}
+	if newTicker != "" {
+		utils.CompareChanges(oldTicker, newTicker, changes, "ticker")
+	}
+	if record.CostBasisPercent != nil {
+		utils.CompareDecimalChange(nil, record.CostBasisPercent, changes, "cost_basis_percent", 4)
+	}
+
+	err = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "corporate_action",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return actionID, nil
+}
+
+// RevertCorporateAction undoes an asset's latest corporate action: split factors are
+// taken back out, the old ticker and name return, and a spun-off holding is removed
+// with its basis handed back to the parent's buys.
+func (s *InvestmentService) RevertCorporateAction(ctx context.Context, userID, assetID, actionID int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	asset, err := s.repo.FindInvestmentAssetByID(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("asset not found: %w", err)
+	}
+
+	actions, err := s.repo.FindCorporateActionsByAsset(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var record *models.CorporateAction
+	for i := range actions {
+		if actions[i].ID == actionID {
+			record = &actions[i]
+		} else if actions[i].ID > actionID {
+			tx.Rollback()
+			return errors.New("only the latest corporate action on an asset can be undone")
+		}
+	}
+	if record == nil {
+		tx.Rollback()
+		return errors.New("corporate action not found")
+	}
+
+	factor := record.Factor()
+
+	switch record.ActionType {
+	case models.CorporateActionSplit, models.CorporateActionReverseSplit, models.CorporateActionMerger:
+		if !factor.Equal(decimal.NewFromInt(1)) {
+			trades, err := s.repo.FindAllTradesByAssetID(ctx, tx, assetID, userID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			// Those were entered in post-action units and would be rescaled wrongly
+			for _, t := range trades {
+				if t.TxnDate.Before(record.EffectiveDate) && t.CreatedAt.After(record.CreatedAt) {
+					tx.Rollback()
+					return errors.New("trades before the effective date were added after the action, delete them first")
+				}
+			}
+			if err := s.repo.RevertSplitFactor(ctx, tx, assetID, userID, record.EffectiveDate, factor); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	switch record.ActionType {
+	case models.CorporateActionTickerChange, models.CorporateActionMerger:
+		if record.OldTicker != nil {
+			if other, err := s.repo.FindAssetByTicker(ctx, tx, *record.OldTicker, asset.AccountID, userID); err == nil && other.ID != assetID {
+				tx.Rollback()
+				return fmt.Errorf("account already holds an asset with ticker %s", *record.OldTicker)
+			} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				tx.Rollback()
+				return err
+			}
+
+			name := asset.Name
+			if record.OldName != nil {
+				name = *record.OldName
+			}
+			if err := s.repo.UpdateAssetIdentity(ctx, tx, assetID, *record.OldTicker, name); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
+	case models.CorporateActionSpinOff:
+		if record.SpunOffAssetID != nil {
+			if err := s.removeSpunOffAsset(ctx, tx, userID, *record.SpunOffAssetID, record.CreatedAt); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := s.repo.RestoreBuyCostBasis(ctx, tx, record.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteCorporateAction(ctx, tx, record.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	adjusted, err := s.repo.FindInvestmentAssetByID(ctx, tx, assetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if adjusted.CurrentPrice != nil {
+		if err := s.repo.UpdateTradesPnLForAsset(ctx, tx, assetID, *adjusted.CurrentPrice, adjusted.InvestmentType, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := s.repo.RecalculateAssetFromTrades(ctx, tx, assetID, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := s.RecalculateAssetPnL(ctx, userID, assetID); err != nil {
+		return err
+	}
+
+	if err := s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(asset.Name, "", changes, "asset")
+	utils.CompareChanges(string(record.ActionType), "", changes, "type")
+	utils.CompareDateChange(&record.EffectiveDate, nil, changes, "effective_date")
+	if record.OldTicker != nil && *record.OldTicker != asset.Ticker {
+		utils.CompareChanges(asset.Ticker, *record.OldTicker, changes, "ticker")
+	}
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "corporate_action",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// removeSpunOffAsset deletes the holding a spin-off created. It is refused once the
+// holding has a life of its own: trades, income, transfers or actions entered since.
+func (s *InvestmentService) removeSpunOffAsset(ctx context.Context, tx *gorm.DB, userID, childID int64, createdAt time.Time) error {
+	trades, err := s.repo.FindAllTradesByAssetID(ctx, tx, childID, userID)
+	if err != nil {
+		return err
+	}
+	for _, t := range trades {
+		if t.TradeType != models.InvestmentBuy || t.CreatedAt.After(createdAt) {
+			return errors.New("spun-off asset has trades of its own, delete them first")
+		}
+	}
+
+	income, err := s.repo.CountInvestmentIncome(ctx, tx, childID, userID)
+	if err != nil {
+		return err
+	}
+	transfers, err := s.repo.CountTransfersForAsset(ctx, tx, childID)
+	if err != nil {
+		return err
+	}
+	actions, err := s.repo.FindCorporateActionsByAsset(ctx, tx, childID, userID)
+	if err != nil {
+		return err
+	}
+	if income > 0 || transfers > 0 || len(actions) > 0 {
+		return errors.New("spun-off asset has income, transfers or corporate actions of its own")
+	}
+
+	// The mirrored buys never touched cash, so there is nothing to reverse
+	if err := s.repo.DeleteAllTradesForAsset(ctx, tx, childID, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteInvestmentAsset(ctx, tx, childID)
+}
+
+// moveSpunOffBasis takes the share of cost basis the spun-off holding took over off
+// the parent's lots that were open on the effective date. Units sold earlier keep
+// their basis, so realized results don't change.
+func (s *InvestmentService) moveSpunOffBasis(ctx context.Context, tx *gorm.DB, userID int64, parent models.InvestmentAsset, trades []models.InvestmentTrade, actionID int64, effective time.Time, costPercent decimal.Decimal) error {
+	method, err := s.costBasisMethodFor(ctx, tx, userID, parent)
+	if err != nil {
+		return err
+	}
+
+	var adjustments []models.InvestmentBasisAdjustment
+	for _, lot := range utils.BuildLots(trades, 0, method) {
+		if !lot.Quantity.IsPositive() {
+			continue
+		}
+		adjustments = append(adjustments, models.InvestmentBasisAdjustment{
+			TradeID:           lot.TradeID,
+			CorporateActionID: actionID,
+			EffectiveDate:     effective,
+			ValueMoved:        lot.ValueAtBuy.Mul(costPercent).Round(4),
+			FeeMoved:          lot.Fee.Mul(costPercent).Round(4),
+		})
+	}
+
+	return s.repo.MoveBuyCostBasis(ctx, tx, adjustments)
+}
+
+// spinOffAsset opens the spun-off holding in the parent's account. Its buy trades
+// mirror the parent's open lots, so holding periods for tax purposes carry over.
+// No cash moves, so balances are left untouched.
+func (s *InvestmentService) spinOffAsset(ctx context.Context, tx *gorm.DB, userID int64, parent models.InvestmentAsset, trades []models.InvestmentTrade, ticker, name string, factor, costPercent decimal.Decimal) (int64, error) {
+	method, err := s.costBasisMethodFor(ctx, tx, userID, parent)
+	if err != nil {
+		return 0, err
+	}
+
+	lots := utils.SpinOffLots(utils.BuildLots(trades, 0, method), factor, costPercent)
+	if len(lots) == 0 {
+		return 0, errors.New("asset had no open position on the effective date")
+	}
+
+	rates := make(map[time.Time]decimal.Decimal)
+	for _, t := range trades {
+		if t.TradeType == models.InvestmentBuy {
+			rates[t.AcquiredOn()] = t.ExchangeRateToUSD
+		}
+	}
+
+	child := models.InvestmentAsset{
+		UserID:          userID,
+		AccountID:       parent.AccountID,
+		InvestmentType:  parent.InvestmentType,
+		Name:            name,
+		Ticker:          ticker,
+		Currency:        parent.Currency,
+		AverageBuyPrice: decimal.Zero,
+	}
+	childID, err := s.repo.InsertAsset(ctx, tx, &child)
+	if err != nil {
+		return 0, err
+	}
+
+	description := fmt.Sprintf("Spin-off from %s", parent.Ticker)
+	for _, lot := range lots {
+		rate, ok := rates[lot.TxnDate]
+		if !ok || rate.IsZero() {
+			rate = decimal.NewFromInt(1)
+		}
+		trade := models.InvestmentTrade{
+			UserID:            userID,
+			AssetID:           childID,
+			TxnDate:           lot.TxnDate,
+			TradeType:         models.InvestmentBuy,
+			Quantity:          lot.Quantity,
+			PricePerUnit:      lot.ValueAtBuy.Div(lot.Quantity),
+			Fee:               lot.Fee,
+			ValueAtBuy:        lot.ValueAtBuy,
+			Currency:          parent.Currency,
+			ExchangeRateToUSD: rate,
+			Description:       &description,
+		}
+		if _, err := s.repo.InsertInvestmentTrade(ctx, tx, &trade); err != nil {
+			return 0, err
+		}
+	}
+
+	return childID, nil
+}
+
+func (s *InvestmentService) FetchTransfers(ctx context.Context, userID int64) ([]models.InvestmentTransfer, error) {
+	return s.repo.FindTransfers(ctx, nil, userID)
+}
+
+// TransferHoldings moves units of an asset to another investment account in kind.
+// The lots leave the source as a sell at cost, so no gain is realized, and arrive
+// as one buy per lot that keeps its original purchase date and fees. Cash is not
+// touched on either side; only market values change.
+func (s *InvestmentService) TransferHoldings(ctx context.Context, userID int64, req *models.InvestmentTransferReq) (int64, error) {
+	txnDate := req.TxnDate.UTC().Truncate(24 * time.Hour)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if txnDate.After(today) {
+		return 0, errors.New("transfer date can't be in the future")
+	}
+	if req.Quantity != nil && !req.Quantity.IsPositive() {
+		return 0, errors.New("quantity must be positive")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	src, err := s.repo.FindInvestmentAssetByID(ctx, tx, req.AssetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("asset not found: %w", err)
+	}
+	if src.AccountID == req.ToAccountID {
+		tx.Rollback()
+		return 0, errors.New("asset is already held in that account")
+	}
+
+	accType, err := s.accRepo.FindAccountTypeByAccID(ctx, tx, req.ToAccountID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("account not found: %w", err)
+	}
+	if accType.Type != "investment" && accType.Type != "crypto" {
+		tx.Rollback()
+		return 0, errors.New("holdings can be transferred only to investment accounts")
+	}
+	toAccount, err := s.accRepo.FindAccountByID(ctx, tx, req.ToAccountID, userID, false)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("account not found: %w", err)
+	}
+
+	method, err := s.costBasisMethodFor(ctx, tx, userID, src)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	trades, err := s.repo.FindAllTradesByAssetID(ctx, tx, src.ID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	var prior []models.InvestmentTrade
+	for _, t := range trades {
+		if !t.TxnDate.After(txnDate) {
+			prior = append(prior, t)
+		}
+	}
+
+	// Units sold after the transfer date must still be there when those sells happen
+	available := decimal.Zero
+	for _, lot := range utils.BuildLots(prior, 0, method) {
+		available = available.Add(lot.Quantity)
+	}
+	held := decimal.Zero
+	for _, lot := range utils.BuildLots(trades, 0, method) {
+		held = held.Add(lot.Quantity)
+	}
+	if held.LessThan(available) {
+		available = held
+	}
+	if !available.IsPositive() {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s has no units to transfer on that date", src.Ticker)
+	}
+
+	quantity := available
+	if req.Quantity != nil {
+		if req.Quantity.GreaterThan(available) {
+			tx.Rollback()
+			return 0, fmt.Errorf("only %s units of %s can be transferred", available.String(), src.Ticker)
+		}
+		quantity = *req.Quantity
+	}
+
+	consumed := utils.ConsumeLots(utils.BuildLots(prior, 0, method), txnDate, quantity, method, nil)
+	valueAtBuy, fees := decimal.Zero, decimal.Zero
+	for _, c := range consumed {
+		valueAtBuy = valueAtBuy.Add(c.ValueAtBuy)
+		fees = fees.Add(c.Fee)
+	}
+	costBasis := valueAtBuy
+	if src.InvestmentType != models.InvestmentCrypto {
+		costBasis = valueAtBuy.Add(fees)
+	}
+
+	dst, err := s.repo.FindAssetByTicker(ctx, tx, src.Ticker, req.ToAccountID, userID)
+	switch {
+	case err == nil:
+		if dst.InvestmentType != src.InvestmentType || dst.Currency != src.Currency {
+			tx.Rollback()
+			return 0, fmt.Errorf("%s in the target account has a different type or currency", src.Ticker)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		dst, err = s.openTransferAsset(ctx, tx, userID, src, toAccount.ID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	default:
+		tx.Rollback()
+		return 0, err
+	}
+
+	record := models.InvestmentTransfer{
+		UserID:      userID,
+		FromAssetID: src.ID,
+		ToAssetID:   dst.ID,
+		TxnDate:     txnDate,
+		Quantity:    quantity,
+		CostBasis:   costBasis,
+		Description: req.Description,
+	}
+	transferID, err := s.repo.InsertTransfer(ctx, tx, &record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	rateToUSD, err := s.GetExchangeRate(ctx, src.Currency, "USD", &txnDate)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	description := req.Description
+	if description == nil {
+		text := fmt.Sprintf("Transfer to %s", toAccount.Name)
+		description = &text
+	}
+	outValue, _, _ := s.calculateTradePnL(quantity, src.CurrentPrice, costBasis)
+	out := models.InvestmentTrade{
+		UserID:            userID,
+		AssetID:           src.ID,
+		TxnDate:           txnDate,
+		TradeType:         models.InvestmentSell,
+		Quantity:          quantity,
+		PricePerUnit:      costBasis.Div(quantity),
+		ValueAtBuy:        valueAtBuy,
+		CurrentValue:      outValue,
+		RealizedValue:     costBasis,
+		Currency:          src.Currency,
+		ExchangeRateToUSD: rateToUSD,
+		Description:       description,
+		TransferID:        &transferID,
+	}
+	outID, err := s.repo.InsertInvestmentTrade(ctx, tx, &out)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	byID := make(map[int64]models.InvestmentTrade, len(prior))
+	for _, t := range prior {
+		byID[t.ID] = t
+	}
+
+	inDescription := fmt.Sprintf("Transfer from %s", src.Account.Name)
+	if req.Description != nil {
+		inDescription = *req.Description
+	}
+	var picks []models.InvestmentTradeLot
+	for _, c := range consumed {
+		if method != models.CostBasisAverage {
+			picks = append(picks, models.InvestmentTradeLot{SellTradeID: outID, BuyTradeID: c.TradeID, Quantity: c.Quantity})
+		}
+
+		origin := byID[c.TradeID]
+		acquired := c.TxnDate
+		basis := c.ValueAtBuy
+		if src.InvestmentType != models.InvestmentCrypto {
+			basis = basis.Add(c.Fee)
+		}
+		currentValue, profitLoss, profitLossPercent := s.calculateTradePnL(c.Quantity, dst.CurrentPrice, basis)
+
+		in := models.InvestmentTrade{
+			UserID:            userID,
+			AssetID:           dst.ID,
+			TxnDate:           txnDate,
+			TradeType:         models.InvestmentBuy,
+			Quantity:          c.Quantity,
+			PricePerUnit:      c.ValueAtBuy.Div(c.Quantity),
+			Fee:               c.Fee,
+			ValueAtBuy:        c.ValueAtBuy,
+			CurrentValue:      currentValue,
+			ProfitLoss:        profitLoss,
+			ProfitLossPercent: profitLossPercent,
+			Currency:          origin.Currency,
+			ExchangeRateToUSD: origin.ExchangeRateToUSD,
+			Description:       &inDescription,
+			TransferID:        &transferID,
+			AcquiredAt:        &acquired,
+		}
+		if in.Currency == "" {
+			in.Currency = src.Currency
+			in.ExchangeRateToUSD = rateToUSD
+		}
+		if _, err := s.repo.InsertInvestmentTrade(ctx, tx, &in); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := s.repo.InsertTradeLots(ctx, tx, picks); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := s.settleTransfer(ctx, tx, userID, []models.InvestmentAsset{src, dst}, txnDate); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if err := s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil); err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", src.Ticker, changes, "asset")
+	utils.CompareChanges("", src.Account.Name, changes, "from_account")
+	utils.CompareChanges("", toAccount.Name, changes, "to_account")
+	utils.CompareDecimalChange(nil, &quantity, changes, "quantity", 8)
+	utils.CompareDecimalChange(nil, &costBasis, changes, "cost_basis", 2)
+	utils.CompareDateChange(nil, &txnDate, changes, "date")
+
+	err = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "investment_transfer",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return transferID, nil
+}
+
+// openTransferAsset creates the receiving holding in the target account as a copy
+// of the source, price history included, so valuations line up from the first day.
+func (s *InvestmentService) openTransferAsset(ctx context.Context, tx *gorm.DB, userID int64, src models.InvestmentAsset, accountID int64) (models.InvestmentAsset, error) {
+	asset := models.InvestmentAsset{
+		UserID:          userID,
+		AccountID:       accountID,
+		InvestmentType:  src.InvestmentType,
+		Name:            src.Name,
+		Ticker:          src.Ticker,
+		Currency:        src.Currency,
+		CostBasisMethod: src.CostBasisMethod,
+		AssetClass:      src.AssetClass,
+		PriceProvider:   src.PriceProvider,
+		ManualPricing:   src.ManualPricing,
+		InstrumentTerms: src.InstrumentTerms,
+		AverageBuyPrice: decimal.Zero,
+		CurrentPrice:    src.CurrentPrice,
+		LastPriceUpdate: src.LastPriceUpdate,
+	}
+	id, err := s.repo.InsertAsset(ctx, tx, &asset)
+	if err != nil {
+		return models.InvestmentAsset{}, err
+	}
+
+	prices, err := s.repo.GetPriceHistoryForAsset(ctx, tx, src.ID)
+	if err != nil {
+		return models.InvestmentAsset{}, err
+	}
+	for i := range prices {
+		prices[i].AssetID = id
+	}
+	if len(prices) > 0 {
+		if err := s.repo.UpsertAssetPrice(ctx, tx, prices); err != nil {
+			return models.InvestmentAsset{}, err
+		}
+	}
+
+	return s.repo.FindInvestmentAssetByID(ctx, tx, id, userID)
+}
+
+// settleTransfer restates both holdings from their trades and rebuilds snapshots of
+// both accounts from the transfer date.
+func (s *InvestmentService) settleTransfer(ctx context.Context, tx *gorm.DB, userID int64, assets []models.InvestmentAsset, from time.Time) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, asset := range assets {
+		if err := s.repo.RecalculateAssetFromTrades(ctx, tx, asset.ID, userID); err != nil {
+			return err
+		}
+		if err := s.accRepo.EnsureDailyBalanceRow(ctx, tx, asset.AccountID, from, asset.Account.Currency); err != nil {
+			return err
+		}
+		if err := s.accRepo.FrontfillBalances(ctx, tx, asset.AccountID, asset.Account.Currency, from); err != nil {
+			return err
+		}
+		if err := s.accRepo.UpsertSnapshotsFromBalances(ctx, tx, userID, asset.AccountID, asset.Account.Currency, from, today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTransfer undoes an in-kind transfer. It is refused once the receiving holding
+// has sold since, because those sells may have drawn on the carried-over lots.
+func (s *InvestmentService) DeleteTransfer(ctx context.Context, userID, id int64) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	record, err := s.repo.FindTransferByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("transfer not found: %w", err)
+	}
+
+	dstTrades, err := s.repo.FindAllTradesByAssetID(ctx, tx, record.ToAssetID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, t := range dstTrades {
+		if t.TradeType == models.InvestmentSell && !t.TxnDate.Before(record.TxnDate) {
+			tx.Rollback()
+			return fmt.Errorf("%s was sold or moved after this transfer, delete those trades first", record.ToAsset.Ticker)
+		}
+	}
+
+	if err := s.repo.DeleteTransfer(ctx, tx, record.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := s.settleTransfer(ctx, tx, userID, []models.InvestmentAsset{record.FromAsset, record.ToAsset}, record.TxnDate); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(record.FromAsset.Ticker, "", changes, "asset")
+	utils.CompareChanges(record.FromAsset.Account.Name, "", changes, "from_account")
+	utils.CompareChanges(record.ToAsset.Account.Name, "", changes, "to_account")
+	utils.CompareDecimalChange(&record.Quantity, nil, changes, "quantity", 8)
+	utils.CompareDateChange(&record.TxnDate, nil, changes, "date")
+
+	err = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "investment_transfer",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *InvestmentService) FetchBenchmarks(ctx context.Context, userID int64) ([]models.UserBenchmark, error) {
+	return s.repo.FindUserBenchmarks(ctx, nil, userID)
+}
+
+// InsertBenchmark attaches a benchmark ticker to the portfolio, or to one
+// investment account, and queues a backfill of its price history from the
+// first trade in that scope.
+func (s *InvestmentService) InsertBenchmark(ctx context.Context, userID int64, req *models.BenchmarkReq) (int64, error) {
+	switch req.InvestmentType {
+	case models.InvestmentStock, models.InvestmentETF, models.InvestmentCrypto,
+		models.InvestmentMutualFund, models.InvestmentCommodity:
+	default:
+		return 0, fmt.Errorf("invalid investment type: %s", req.InvestmentType)
+	}
+
+	ticker, err := formatTicker(req.Ticker, req.InvestmentType)
+	if err != nil {
+		return 0, err
+	}
+
+	currency := "USD"
+	if s.priceFetchClient != nil {
+		priceData, err := s.priceFetchClient.GetAssetPrice(ctx, ticker, req.InvestmentType)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch price for ticker '%s': %w", ticker, err)
+		}
+		if priceData.Currency != "" {
+			currency = priceData.Currency
+		}
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if req.AccountID != nil {
+		accType, err := s.accRepo.FindAccountTypeByAccID(ctx, tx, *req.AccountID, userID)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("account not found: %w", err)
+		}
+		if accType.Type != "investment" && accType.Type != "crypto" {
+			tx.Rollback()
+			return 0, fmt.Errorf("benchmarks can be attached only to investment accounts")
+		}
+	}
+
+	existing, err := s.repo.FindUserBenchmarks(ctx, tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	for _, ub := range existing {
+		sameScope := (ub.AccountID == nil && req.AccountID == nil) ||
+			(ub.AccountID != nil && req.AccountID != nil && *ub.AccountID == *req.AccountID)
+		if sameScope && ub.Benchmark.Ticker == ticker && ub.Benchmark.InvestmentType == req.InvestmentType {
+			tx.Rollback()
+			return 0, fmt.Errorf("benchmark %s is already attached", ticker)
+		}
+	}
+
+	benchmark, err := s.repo.FindOrCreateBenchmark(ctx, tx, ticker, req.InvestmentType, currency)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	record := models.UserBenchmark{
+		UserID:      userID,
+		AccountID:   req.AccountID,
+		BenchmarkID: benchmark.ID,
+	}
+	id, err := s.repo.InsertUserBenchmark(ctx, tx, &record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	from, err := s.repo.GetEarliestUserTradeDate(ctx, tx, userID, req.AccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		from = time.Now().UTC().AddDate(-1, 0, 0)
+	} else if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if err := s.jobDispatcher.Dispatch(ctx, queue_jobs.NewBackfillBenchmarkJob(
+		s.logger.Named("benchmark_backfill"), s, benchmark.ID, benchmark.Ticker, benchmark.InvestmentType, from,
+	)); err != nil {
+		s.logger.Warn("Failed to dispatch benchmark backfill", zap.Int64("benchmarkID", benchmark.ID), zap.Error(err))
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", benchmark.Ticker, changes, "ticker")
+	if req.AccountID != nil {
+		utils.CompareChanges("", strconv.FormatInt(*req.AccountID, 10), changes, "account_id")
+	}
+
+	err = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "benchmark",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *InvestmentService) DeleteBenchmark(ctx context.Context, userID, id int64) error {
+	record, err := s.repo.FindUserBenchmarkByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("benchmark not found: %w", err)
+	}
+
+	if err := s.repo.DeleteUserBenchmark(ctx, nil, id, userID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(record.Benchmark.Ticker, "", changes, "ticker")
+	if record.AccountID != nil {
+		utils.CompareChanges(strconv.FormatInt(*record.AccountID, 10), "", changes, "account_id")
+	}
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "benchmark",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *InvestmentService) FetchAssetPrices(ctx context.Context, userID, assetID int64) ([]models.AssetPriceHistory, error) {
+	if _, err := s.repo.FindInvestmentAssetByID(ctx, nil, assetID, userID); err != nil {
+		return nil, fmt.Errorf("can't find asset: %w", err)
+	}
+	return s.repo.GetPriceHistoryForAsset(ctx, nil, assetID)
+}
+
+// revalueManualAssets carries fresh manual prices into the assets' P&L and the
+// snapshot market values.
+func (s *InvestmentService) revalueManualAssets(ctx context.Context, userID int64, assetIDs []int64) error {
+	for _, id := range assetIDs {
+		if err := s.RecalculateAssetPnL(ctx, userID, id); err != nil {
+			return err
+		}
+	}
+	return s.accRepo.UpdateSnapshotMarketValues(ctx, nil, userID, nil)
+}
+
+// manualPriceAsset loads an asset and makes sure it is manually priced.
+func (s *InvestmentService) manualPriceAsset(ctx context.Context, userID, assetID int64) (models.InvestmentAsset, error) {
+	asset, err := s.repo.FindInvestmentAssetByID(ctx, nil, assetID, userID)
+	if err != nil {
+		return asset, fmt.Errorf("can't find asset: %w", err)
+	}
+	if !asset.ManualPricing {
+		return asset, fmt.Errorf("asset '%s' is priced by the sync job, enable manual pricing first", asset.Ticker)
+	}
+	return asset, nil
+}
+
+func (s *InvestmentService) InsertManualPrice(ctx context.Context, userID, assetID int64, req *models.ManualPriceReq) error {
+	asset, err := s.manualPriceAsset(ctx, userID, assetID)
+	if err != nil {
+		return err
+	}
+
+	if !req.Price.IsPositive() {
+		return errors.New("price must be positive")
+	}
+	asOf := req.AsOf.UTC().Truncate(24 * time.Hour)
+	if asOf.After(time.Now().UTC()) {
+		return errors.New("price date can't be in the future")
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = asset.Currency
+	}
+
+	entry := models.AssetPriceHistory{AssetID: asset.ID, AsOf: asOf, Price: req.Price, Currency: currency}
+	if err := s.repo.UpsertAssetPrice(ctx, nil, []models.AssetPriceHistory{entry}); err != nil {
+		return err
+	}
+
+	if err := s.revalueManualAssets(ctx, userID, []int64{asset.ID}); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	changes.Stamp("asset", asset.Ticker)
+	utils.CompareChanges("", asOf.Format("2006-01-02"), changes, "date")
+	utils.CompareChanges("", req.Price.String(), changes, "price")
+	utils.CompareChanges("", currency, changes, "currency")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "asset_price",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *InvestmentService) DeleteManualPrice(ctx context.Context, userID, assetID int64, asOf time.Time) error {
+	asset, err := s.manualPriceAsset(ctx, userID, assetID)
+	if err != nil {
+		return err
+	}
+
+	asOf = asOf.UTC().Truncate(24 * time.Hour)
+	if err := s.repo.DeleteAssetPrice(ctx, nil, asset.ID, asOf); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("no price recorded on %s", asOf.Format("2006-01-02"))
+		}
+		return err
+	}
+
+	if err := s.revalueManualAssets(ctx, userID, []int64{asset.ID}); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	changes.Stamp("asset", asset.Ticker)
+	utils.CompareChanges(asOf.Format("2006-01-02"), "", changes, "date")
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "asset_price",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// UploadPrices reads a price CSV into the history of the user's manually priced
+// assets. Rows that can't be matched are reported back instead of failing the
+// whole upload.
+func (s *InvestmentService) UploadPrices(ctx context.Context, userID int64, r io.Reader) (*models.PriceUploadResult, error) {
+	rows, issues, err := utils.ParsePriceCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PriceUploadResult{Rows: len(rows) + len(issues), Errors: issues}
+	rows, result.Duplicates = utils.DedupePriceRows(rows)
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	tickerSet := make(map[string]bool)
+	for _, row := range rows {
+		tickerSet[row.Ticker] = true
+	}
+	tickers := make([]string, 0, len(tickerSet))
+	for t := range tickerSet {
+		tickers = append(tickers, t)
+	}
+
+	assets, err := s.repo.FindAssetsByTickers(ctx, nil, userID, tickers)
+	if err != nil {
+		return nil, err
+	}
+	byTicker := make(map[string][]models.InvestmentAsset)
+	for _, a := range assets {
+		byTicker[a.Ticker] = append(byTicker[a.Ticker], a)
+	}
+
+	today := time.Now().UTC()
+	var batch []models.AssetPriceHistory
+	touched := make(map[int64]bool)
+	var touchedIDs []int64
+
+	for _, row := range rows {
+		matches := byTicker[row.Ticker]
+		if len(matches) == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: no asset with ticker %s", row.Line, row.Ticker))
+			continue
+		}
+		if row.AsOf.After(today) {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: date is in the future", row.Line))
+			continue
+		}
+
+		imported := false
+		for _, asset := range matches {
+			if !asset.ManualPricing {
+				continue
+			}
+			currency := row.Currency
+			if currency == "" {
+				currency = asset.Currency
+			}
+			batch = append(batch, models.AssetPriceHistory{AssetID: asset.ID, AsOf: row.AsOf, Price: row.Price, Currency: currency})
+			if !touched[asset.ID] {
+				touched[asset.ID] = true
+				touchedIDs = append(touchedIDs, asset.ID)
+			}
+			imported = true
+		}
+		if !imported {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %s is not manually priced", row.Line, row.Ticker))
+			continue
+		}
+		result.Imported++
+	}
+
+	if len(batch) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := s.repo.UpsertAssetPrice(ctx, tx, batch); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	result.Assets = len(touchedIDs)
+	if err := s.revalueManualAssets(ctx, userID, touchedIDs); err != nil {
+		return nil, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.Itoa(result.Imported), changes, "prices")
+	utils.CompareChanges("", strconv.Itoa(result.Assets), changes, "assets")
+
+	err = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "asset_price",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
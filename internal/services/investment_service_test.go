@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 	"wealth-warden/internal/models"
@@ -102,6 +103,244 @@ func (s *InvestmentServiceTestSuite) TestInsertAsset_SeedsPriceHistory() {
 	s.Assert().NotEmpty(ph.Currency, "seeded price should have a currency")
 }
 
+// Verifies that a manually priced asset skips the price lookup and is valued from
+// entered and uploaded prices
+func (s *InvestmentServiceTestSuite) TestManualPricing_EnteredAndUploadedPrices() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Private Holdings",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      time.Now(),
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentStock,
+		Name:           "Family Farm Shares",
+		Ticker:         "farm_co",
+		Quantity:       decimal.Zero,
+		Currency:       "EUR",
+		ManualPricing:  true,
+	})
+	s.Require().NoError(err)
+
+	asset, err := svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Equal("FARM_CO", asset.Ticker)
+	s.Assert().True(asset.ManualPricing)
+	s.Assert().Nil(asset.CurrentPrice, "manual assets start without a price")
+
+	lastWeek := time.Now().UTC().AddDate(0, 0, -7).Truncate(24 * time.Hour)
+	err = svc.InsertManualPrice(s.Ctx, userID, assetID, &models.ManualPriceReq{AsOf: lastWeek, Price: decimal.NewFromInt(120)})
+	s.Require().NoError(err)
+
+	asset, err = svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Require().NotNil(asset.CurrentPrice)
+	s.Assert().True(asset.CurrentPrice.Equal(decimal.NewFromInt(120)), asset.CurrentPrice.String())
+
+	// The repeated FARM_CO row replaces the first instead of failing the batch
+	csv := "ticker,date,price\n" +
+		"FARM_CO," + time.Now().UTC().Format("2006-01-02") + ",125\n" +
+		"FARM_CO," + time.Now().UTC().Format("2006-01-02") + ",130\n" +
+		"NOPE," + time.Now().UTC().Format("2006-01-02") + ",1\n"
+	result, err := svc.UploadPrices(s.Ctx, userID, strings.NewReader(csv))
+	s.Require().NoError(err)
+	s.Assert().Equal(3, result.Rows)
+	s.Assert().Equal(1, result.Imported)
+	s.Assert().Equal(1, result.Assets)
+	s.Assert().Len(result.Duplicates, 1)
+	s.Assert().Len(result.Errors, 1)
+
+	asset, err = svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().True(asset.CurrentPrice.Equal(decimal.NewFromInt(130)), asset.CurrentPrice.String())
+
+	prices, err := svc.FetchAssetPrices(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Len(prices, 2)
+	for _, p := range prices {
+		s.Assert().Equal("EUR", p.Currency)
+	}
+
+	s.Require().NoError(svc.DeleteManualPrice(s.Ctx, userID, assetID, time.Now().UTC()))
+	prices, err = svc.FetchAssetPrices(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Len(prices, 1)
+}
+
+// Verifies that an asset update leaves the fields it does not send untouched
+func (s *InvestmentServiceTestSuite) TestUpdateInvestmentAsset_OnlyWritesSentFields() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Private Holdings",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      time.Now(),
+	})
+	s.Require().NoError(err)
+
+	lifo := models.CostBasisLIFO
+	class := "equity"
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:       accID,
+		InvestmentType:  models.InvestmentStock,
+		Name:            "Family Farm Shares",
+		Ticker:          "farm_co",
+		Quantity:        decimal.Zero,
+		Currency:        "EUR",
+		CostBasisMethod: &lifo,
+		AssetClass:      &class,
+		ManualPricing:   true,
+	})
+	s.Require().NoError(err)
+
+	name := "Farm Co-op Shares"
+	_, err = svc.UpdateInvestmentAsset(s.Ctx, userID, assetID, &models.InvestmentAssetUpdateReq{Name: &name})
+	s.Require().NoError(err)
+
+	asset, err := svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Equal(name, asset.Name)
+	s.Assert().True(asset.ManualPricing)
+	s.Require().NotNil(asset.CostBasisMethod)
+	s.Assert().Equal(lifo, *asset.CostBasisMethod)
+	s.Require().NotNil(asset.AssetClass)
+	s.Assert().Equal(class, *asset.AssetClass)
+
+	cleared := ""
+	_, err = svc.UpdateInvestmentAsset(s.Ctx, userID, assetID, &models.InvestmentAssetUpdateReq{AssetClass: &cleared})
+	s.Require().NoError(err)
+
+	asset, err = svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Nil(asset.AssetClass)
+	s.Assert().Equal(name, asset.Name)
+}
+
+// Verifies that the cost-basis method can't change under sells already stored with the old one
+func (s *InvestmentServiceTestSuite) TestCostBasisMethod_LockedOnceAssetHasSells() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(200000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentCrypto,
+		Name:           "Bitcoin",
+		Ticker:         "BTC-USD",
+		Currency:       "EUR",
+		Quantity:       decimal.NewFromInt(0),
+	})
+	s.Require().NoError(err)
+
+	lifo := models.CostBasisLIFO
+	_, err = svc.UpdateInvestmentAsset(s.Ctx, userID, assetID, &models.InvestmentAssetUpdateReq{CostBasisMethod: &lifo})
+	s.Require().NoError(err, "method can change while there are no sells")
+
+	for _, tradeType := range []models.TradeType{models.InvestmentBuy, models.InvestmentSell} {
+		_, err = svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+			AssetID:      assetID,
+			TxnDate:      today,
+			TradeType:    tradeType,
+			Quantity:     decimal.NewFromInt(1),
+			PricePerUnit: decimal.NewFromInt(50000),
+			Currency:     "EUR",
+		})
+		s.Require().NoError(err)
+	}
+
+	fifo := models.CostBasisFIFO
+	_, err = svc.UpdateInvestmentAsset(s.Ctx, userID, assetID, &models.InvestmentAssetUpdateReq{CostBasisMethod: &fifo})
+	s.Require().Error(err)
+
+	// Back to inheriting the default, which is FIFO as well
+	inherit := models.CostBasisMethod("")
+	_, err = svc.UpdateInvestmentAsset(s.Ctx, userID, assetID, &models.InvestmentAssetUpdateReq{CostBasisMethod: &inherit})
+	s.Require().Error(err)
+
+	asset, err := svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Require().NotNil(asset.CostBasisMethod)
+	s.Assert().Equal(lifo, *asset.CostBasisMethod)
+
+	// The default only reaches assets without their own method, and this one has LIFO
+	s.Require().NoError(svc.SaveTaxSettings(s.Ctx, userID, &models.InvestmentTaxSettingsReq{CostBasisMethod: models.CostBasisAverage}))
+}
+
+// Verifies that a bond without a quote is carried at par without storing par as a price
+func (s *InvestmentServiceTestSuite) TestInsertAsset_BondParFallbackNotStoredAsPrice() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	face, rate, freq := decimal.NewFromInt(1000), decimal.NewFromInt(4), 4
+	maturity := today.AddDate(5, 0, 0)
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentBond,
+		Name:           "Test Bond",
+		Ticker:         "US912828XG55",
+		Quantity:       decimal.Zero,
+		Currency:       "EUR",
+		InstrumentTerms: models.InstrumentTerms{
+			FaceValue:       &face,
+			CouponRate:      &rate,
+			CouponFrequency: &freq,
+			MaturityDate:    &maturity,
+		},
+	})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+		AssetID:      assetID,
+		TradeType:    models.InvestmentBuy,
+		TxnDate:      today,
+		Quantity:     decimal.NewFromInt(1),
+		PricePerUnit: decimal.NewFromInt(1000),
+		Currency:     "EUR",
+	})
+	s.Require().NoError(err)
+
+	asset, err := svc.FetchInvestmentAssetByID(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Require().NotNil(asset.CurrentPrice, "bond should be carried at par")
+
+	var prices int64
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.AssetPriceHistory{}).Where("asset_id = ?", assetID).Count(&prices).Error)
+	s.Assert().Equal(int64(0), prices, "par is not a quote and must not land in the price history")
+}
+
 // Verifies that a stock with an invalid/non-existent exchange returns an error
 func (s *InvestmentServiceTestSuite) TestInsertAsset_StockWithInvalidExchange() {
 	svc := s.TC.App.InvestmentService
@@ -1811,3 +2050,548 @@ func (s *InvestmentServiceTestSuite) TestCreateInvestmentIncome_Dividend_Missing
 	s.Require().NoError(err)
 	s.Assert().Equal(int64(0), count, "no income record should be created on validation failure")
 }
+
+// Tests that a reinvested dividend buys units at the net amount without moving cash.
+func (s *InvestmentServiceTestSuite) TestCreateInvestmentIncome_ReinvestedDividendCreatesBuy() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentStock,
+		Name:           "iShares Core MSCI World",
+		Ticker:         "IWDA.AS",
+		Quantity:       decimal.Zero,
+	})
+	s.Require().NoError(err)
+
+	// 250 gross - 50 withheld = 200 net, at the mocked price of 100 -> 2 units
+	amount := decimal.NewFromInt(250)
+	taxWithheld := decimal.NewFromInt(50)
+	incomeID, err := svc.CreateInvestmentIncome(s.Ctx, userID, &models.InvestmentIncomeReq{
+		AssetID:     assetID,
+		TxnDate:     today,
+		IncomeType:  models.IncomeTypeDividend,
+		Amount:      &amount,
+		TaxWithheld: &taxWithheld,
+		Currency:    "EUR",
+		Reinvest:    true,
+	})
+	s.Require().NoError(err)
+
+	var income models.InvestmentIncome
+	err = s.TC.DB.WithContext(s.Ctx).Where("id = ?", incomeID).First(&income).Error
+	s.Require().NoError(err)
+	s.Require().NotNil(income.LinkedTransactionID)
+	s.Require().NotNil(income.ReinvestTradeID)
+
+	var trade models.InvestmentTrade
+	err = s.TC.DB.WithContext(s.Ctx).Where("id = ?", *income.ReinvestTradeID).First(&trade).Error
+	s.Require().NoError(err)
+	s.Assert().Equal(models.InvestmentBuy, trade.TradeType)
+	s.Assert().True(decimal.NewFromInt(2).Equal(trade.Quantity), "got quantity %s", trade.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(200).Equal(trade.ValueAtBuy), "got value at buy %s", trade.ValueAtBuy.String())
+
+	var asset models.InvestmentAsset
+	err = s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error
+	s.Require().NoError(err)
+	s.Assert().True(decimal.NewFromInt(2).Equal(asset.Quantity))
+	s.Assert().True(decimal.NewFromInt(100).Equal(asset.AverageBuyPrice), "got average %s", asset.AverageBuyPrice.String())
+
+	// The dividend comes in and the buy spends it, leaving cash where it was
+	var balance models.Balance
+	err = s.TC.DB.WithContext(s.Ctx).Where("account_id = ? AND as_of = ?", accID, today).First(&balance).Error
+	s.Require().NoError(err)
+	s.Assert().True(decimal.NewFromInt(200).Equal(balance.CashInflows), "got inflows %s", balance.CashInflows.String())
+	s.Assert().True(decimal.NewFromInt(200).Equal(balance.CashOutflows), "got outflows %s", balance.CashOutflows.String())
+	s.Assert().True(initialBalance.Equal(balance.EndBalance), "cash should not change after a DRIP, got %s", balance.EndBalance.String())
+}
+
+// Tests that deleting a reinvested dividend also removes its buy and the linked transaction.
+func (s *InvestmentServiceTestSuite) TestDeleteInvestmentIncome_ReinvestedDividendRemovesBuy() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      today,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentStock,
+		Name:           "iShares Core MSCI World",
+		Ticker:         "IWDA.AS",
+		Quantity:       decimal.Zero,
+	})
+	s.Require().NoError(err)
+
+	amount := decimal.NewFromInt(60)
+	qty := decimal.NewFromFloat(0.5)
+	incomeID, err := svc.CreateInvestmentIncome(s.Ctx, userID, &models.InvestmentIncomeReq{
+		AssetID:    assetID,
+		TxnDate:    today,
+		IncomeType: models.IncomeTypeDividend,
+		Amount:     &amount,
+		Quantity:   &qty,
+		Currency:   "EUR",
+		Reinvest:   true,
+	})
+	s.Require().NoError(err)
+
+	var income models.InvestmentIncome
+	err = s.TC.DB.WithContext(s.Ctx).Where("id = ?", incomeID).First(&income).Error
+	s.Require().NoError(err)
+	s.Require().NotNil(income.ReinvestTradeID)
+	tradeID := *income.ReinvestTradeID
+	linkedTxnID := *income.LinkedTransactionID
+
+	err = svc.DeleteInvestmentTrade(s.Ctx, userID, tradeID)
+	s.Require().Error(err, "the reinvested buy can only go away with its dividend")
+
+	err = svc.DeleteInvestmentIncome(s.Ctx, userID, incomeID)
+	s.Require().NoError(err)
+
+	var tradeCount int64
+	err = s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentTrade{}).Where("id = ?", tradeID).Count(&tradeCount).Error
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(0), tradeCount)
+
+	var txnCount int64
+	err = s.TC.DB.WithContext(s.Ctx).Model(&models.Transaction{}).
+		Where("id = ? AND deleted_at IS NULL", linkedTxnID).
+		Count(&txnCount).Error
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(0), txnCount)
+
+	var asset models.InvestmentAsset
+	err = s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error
+	s.Require().NoError(err)
+	s.Assert().True(asset.Quantity.IsZero(), "got quantity %s", asset.Quantity.String())
+
+	var balance models.Balance
+	err = s.TC.DB.WithContext(s.Ctx).Where("account_id = ? AND as_of = ?", accID, today).First(&balance).Error
+	s.Require().NoError(err)
+	s.Assert().True(balance.CashInflows.IsZero(), "got inflows %s", balance.CashInflows.String())
+	s.Assert().True(balance.CashOutflows.IsZero(), "got outflows %s", balance.CashOutflows.String())
+}
+
+// Verifies that a benchmark is shared between scopes but attached only once per scope.
+func (s *InvestmentServiceTestSuite) TestInsertBenchmark_RejectsDuplicateInSameScope() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	initialBalance := decimal.NewFromInt(100000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Investment Account",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      time.Now(),
+	})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertBenchmark(s.Ctx, userID, &models.BenchmarkReq{
+		Ticker:         "btc",
+		InvestmentType: models.InvestmentCrypto,
+	})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertBenchmark(s.Ctx, userID, &models.BenchmarkReq{
+		Ticker:         "BTC-USD",
+		InvestmentType: models.InvestmentCrypto,
+	})
+	s.Require().Error(err)
+	s.Assert().Contains(err.Error(), "already attached")
+
+	_, err = svc.InsertBenchmark(s.Ctx, userID, &models.BenchmarkReq{
+		AccountID:      &accID,
+		Ticker:         "BTC-USD",
+		InvestmentType: models.InvestmentCrypto,
+	})
+	s.Require().NoError(err)
+
+	records, err := svc.FetchBenchmarks(s.Ctx, userID)
+	s.Require().NoError(err)
+	s.Require().Len(records, 2)
+	s.Assert().Equal(records[0].BenchmarkID, records[1].BenchmarkID)
+	s.Assert().Equal("USD", records[0].Benchmark.Currency)
+}
+
+// Tests that an in-kind transfer carries the lots over at cost, with their purchase
+// date, without touching cash, and that deleting it puts them back.
+func (s *InvestmentServiceTestSuite) TestTransferHoldings_CarriesLotsBetweenAccounts() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	boughtOn := today.AddDate(0, 0, -30)
+	movedOn := today.AddDate(0, 0, -10)
+	initialBalance := decimal.NewFromInt(100000)
+	emptyBalance := decimal.Zero
+
+	fromID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Broker A",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      boughtOn,
+	})
+	s.Require().NoError(err)
+	toID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Broker B",
+		AccountTypeID: 5,
+		Balance:       &emptyBalance,
+		OpenedAt:      boughtOn,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      fromID,
+		InvestmentType: models.InvestmentStock,
+		Name:           "iShares Core MSCI World",
+		Ticker:         "IWDA.AS",
+		Quantity:       decimal.Zero,
+	})
+	s.Require().NoError(err)
+
+	fee := decimal.NewFromInt(2)
+	_, err = svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+		AssetID:      assetID,
+		TxnDate:      boughtOn,
+		TradeType:    models.InvestmentBuy,
+		Quantity:     decimal.NewFromInt(10),
+		PricePerUnit: decimal.NewFromInt(50),
+		Fee:          &fee,
+		Currency:     "EUR",
+	})
+	s.Require().NoError(err)
+
+	qty := decimal.NewFromInt(4)
+	transferID, err := svc.TransferHoldings(s.Ctx, userID, &models.InvestmentTransferReq{
+		AssetID:     assetID,
+		ToAccountID: toID,
+		TxnDate:     movedOn,
+		Quantity:    &qty,
+	})
+	s.Require().NoError(err)
+
+	var src, dst models.InvestmentAsset
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&src).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("account_id = ? AND ticker = ?", toID, "IWDA.AS").First(&dst).Error)
+	s.Assert().True(decimal.NewFromInt(6).Equal(src.Quantity), "got source quantity %s", src.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(4).Equal(dst.Quantity), "got target quantity %s", dst.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(200).Equal(dst.ValueAtBuy), "got target value at buy %s", dst.ValueAtBuy.String())
+
+	var carried models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("asset_id = ? AND transfer_id = ?", dst.ID, transferID).First(&carried).Error)
+	s.Require().NotNil(carried.AcquiredAt)
+	s.Assert().True(boughtOn.Equal(carried.AcquiredAt.UTC()), "got acquired %s", carried.AcquiredAt)
+	s.Assert().True(decimal.NewFromFloat(0.8).Equal(carried.Fee), "got fee %s", carried.Fee.String())
+
+	var out models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("asset_id = ? AND transfer_id = ?", assetID, transferID).First(&out).Error)
+	s.Assert().True(out.ProfitLoss.IsZero(), "a transfer realizes nothing, got %s", out.ProfitLoss.String())
+
+	var balances []models.Balance
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("account_id IN ? AND as_of = ?", []int64{fromID, toID}, movedOn).Find(&balances).Error)
+	for _, b := range balances {
+		s.Assert().True(b.CashInflows.IsZero() && b.CashOutflows.IsZero(), "transfer must not move cash on account %d", b.AccountID)
+	}
+
+	err = svc.DeleteInvestmentTrade(s.Ctx, userID, out.ID)
+	s.Require().Error(err, "transfer legs can only go away with the transfer")
+
+	s.Require().NoError(svc.DeleteTransfer(s.Ctx, userID, transferID))
+
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&src).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", dst.ID).First(&dst).Error)
+	s.Assert().True(decimal.NewFromInt(10).Equal(src.Quantity), "got source quantity %s", src.Quantity.String())
+	s.Assert().True(dst.Quantity.IsZero(), "got target quantity %s", dst.Quantity.String())
+}
+
+// A spin-off only carries over basis of units still held; units sold earlier keep theirs.
+func (s *InvestmentServiceTestSuite) TestApplyCorporateAction_SpinOffLeavesSoldLotsAlone() {
+	svc := s.TC.App.InvestmentService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	boughtOn := today.AddDate(0, 0, -30)
+	soldOn := today.AddDate(0, 0, -20)
+	spunOn := today.AddDate(0, 0, -10)
+	initialBalance := decimal.NewFromInt(10000)
+
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Broker",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      boughtOn,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentStock,
+		Name:           "Parent Corp",
+		Ticker:         "PRNT",
+		Quantity:       decimal.Zero,
+	})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+		AssetID:      assetID,
+		TxnDate:      boughtOn,
+		TradeType:    models.InvestmentBuy,
+		Quantity:     decimal.NewFromInt(10),
+		PricePerUnit: decimal.NewFromInt(10),
+		Currency:     "EUR",
+	})
+	s.Require().NoError(err)
+	sellID, err := svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+		AssetID:      assetID,
+		TxnDate:      soldOn,
+		TradeType:    models.InvestmentSell,
+		Quantity:     decimal.NewFromInt(5),
+		PricePerUnit: decimal.NewFromInt(12),
+		Currency:     "EUR",
+	})
+	s.Require().NoError(err)
+
+	var sellBefore models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", sellID).First(&sellBefore).Error)
+
+	newTicker, newName := "CHLD", "Child Corp"
+	costPercent := decimal.NewFromFloat(0.4)
+	_, err = svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:       models.CorporateActionSpinOff,
+		EffectiveDate:    spunOn,
+		NewTicker:        &newTicker,
+		NewName:          &newName,
+		CostBasisPercent: &costPercent,
+	})
+	s.Require().NoError(err)
+
+	var sellAfter models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", sellID).First(&sellAfter).Error)
+	s.Assert().True(sellBefore.ValueAtBuy.Equal(sellAfter.ValueAtBuy), "got sold basis %s", sellAfter.ValueAtBuy.String())
+	s.Assert().True(sellBefore.ProfitLoss.Equal(sellAfter.ProfitLoss), "got realized %s", sellAfter.ProfitLoss.String())
+
+	var parent, child models.InvestmentAsset
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&parent).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("account_id = ? AND ticker = ?", accID, newTicker).First(&child).Error)
+	// 5 units open with 50 of basis: 20 moves to the child, 30 stays
+	s.Assert().True(decimal.NewFromInt(30).Equal(parent.ValueAtBuy), "got parent basis %s", parent.ValueAtBuy.String())
+	s.Assert().True(decimal.NewFromInt(20).Equal(child.ValueAtBuy), "got child basis %s", child.ValueAtBuy.String())
+}
+
+// corporateActionAsset opens a broker account holding 10 units of ticker bought at 10.
+func (s *InvestmentServiceTestSuite) corporateActionAsset(ticker string) (int64, int64) {
+	svc := s.TC.App.InvestmentService
+	userID := int64(1)
+
+	boughtOn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -30)
+	initialBalance := decimal.NewFromInt(10000)
+
+	accID, err := s.TC.App.AccountService.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:          "Broker",
+		AccountTypeID: 5,
+		Balance:       &initialBalance,
+		OpenedAt:      boughtOn,
+	})
+	s.Require().NoError(err)
+
+	assetID, err := svc.InsertAsset(s.Ctx, userID, &models.InvestmentAssetReq{
+		AccountID:      accID,
+		InvestmentType: models.InvestmentStock,
+		Name:           ticker + " Corp",
+		Ticker:         ticker,
+		Quantity:       decimal.Zero,
+	})
+	s.Require().NoError(err)
+
+	_, err = svc.InsertInvestmentTrade(s.Ctx, userID, &models.InvestmentTradeReq{
+		AssetID:      assetID,
+		TxnDate:      boughtOn,
+		TradeType:    models.InvestmentBuy,
+		Quantity:     decimal.NewFromInt(10),
+		PricePerUnit: decimal.NewFromInt(10),
+		Currency:     "EUR",
+	})
+	s.Require().NoError(err)
+
+	return accID, assetID
+}
+
+func (s *InvestmentServiceTestSuite) latestCorporateActionID(assetID int64) int64 {
+	actions, err := s.TC.App.InvestmentService.FetchCorporateActions(s.Ctx, 1, assetID)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(actions)
+	return actions[0].ID
+}
+
+// A split restates the lot in post-split units without moving basis; undoing it
+// brings the original units back.
+func (s *InvestmentServiceTestSuite) TestCorporateAction_SplitAndUndo() {
+	svc := s.TC.App.InvestmentService
+	userID := int64(1)
+	_, assetID := s.corporateActionAsset("SPLT")
+
+	ratioFrom, ratioTo := decimal.NewFromInt(1), decimal.NewFromInt(2)
+	_, err := svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:    models.CorporateActionSplit,
+		EffectiveDate: time.Now().UTC().AddDate(0, 0, -5),
+		RatioFrom:     &ratioFrom,
+		RatioTo:       &ratioTo,
+	})
+	s.Require().NoError(err)
+
+	var asset models.InvestmentAsset
+	var trade models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("asset_id = ?", assetID).First(&trade).Error)
+	s.Assert().True(decimal.NewFromInt(20).Equal(asset.Quantity), "got quantity %s", asset.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(5).Equal(asset.AverageBuyPrice), "got average %s", asset.AverageBuyPrice.String())
+	s.Assert().True(decimal.NewFromInt(20).Equal(trade.Quantity), "got lot quantity %s", trade.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(5).Equal(trade.PricePerUnit), "got lot price %s", trade.PricePerUnit.String())
+	s.Assert().True(decimal.NewFromInt(100).Equal(asset.ValueAtBuy), "got basis %s", asset.ValueAtBuy.String())
+
+	s.Require().NoError(svc.RevertCorporateAction(s.Ctx, userID, assetID, s.latestCorporateActionID(assetID)))
+
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("asset_id = ?", assetID).First(&trade).Error)
+	s.Assert().True(decimal.NewFromInt(10).Equal(asset.Quantity), "got quantity %s", asset.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(10).Equal(asset.AverageBuyPrice), "got average %s", asset.AverageBuyPrice.String())
+	s.Assert().True(decimal.NewFromInt(10).Equal(trade.PricePerUnit), "got lot price %s", trade.PricePerUnit.String())
+
+	actions, err := svc.FetchCorporateActions(s.Ctx, userID, assetID)
+	s.Require().NoError(err)
+	s.Assert().Empty(actions)
+}
+
+// A merger converts the units and takes the new identity; undoing it restores both.
+func (s *InvestmentServiceTestSuite) TestCorporateAction_MergerAndUndo() {
+	svc := s.TC.App.InvestmentService
+	userID := int64(1)
+	_, assetID := s.corporateActionAsset("MRGA")
+
+	ratioFrom, ratioTo := decimal.NewFromInt(2), decimal.NewFromInt(1)
+	newTicker, newName := "MRGB", "Merged Corp"
+	_, err := svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:    models.CorporateActionMerger,
+		EffectiveDate: time.Now().UTC().AddDate(0, 0, -5),
+		RatioFrom:     &ratioFrom,
+		RatioTo:       &ratioTo,
+		NewTicker:     &newTicker,
+		NewName:       &newName,
+	})
+	s.Require().NoError(err)
+
+	var asset models.InvestmentAsset
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Assert().Equal(newTicker, asset.Ticker)
+	s.Assert().Equal(newName, asset.Name)
+	s.Assert().True(decimal.NewFromInt(5).Equal(asset.Quantity), "got quantity %s", asset.Quantity.String())
+	s.Assert().True(decimal.NewFromInt(100).Equal(asset.ValueAtBuy), "got basis %s", asset.ValueAtBuy.String())
+
+	s.Require().NoError(svc.RevertCorporateAction(s.Ctx, userID, assetID, s.latestCorporateActionID(assetID)))
+
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Assert().Equal("MRGA", asset.Ticker)
+	s.Assert().Equal("MRGA Corp", asset.Name)
+	s.Assert().True(decimal.NewFromInt(10).Equal(asset.Quantity), "got quantity %s", asset.Quantity.String())
+}
+
+// A spin-off mirrors the open lots into a new holding with its share of basis;
+// undoing it removes the holding and hands the basis back.
+func (s *InvestmentServiceTestSuite) TestCorporateAction_SpinOffAndUndo() {
+	svc := s.TC.App.InvestmentService
+	userID := int64(1)
+	accID, assetID := s.corporateActionAsset("SPIN")
+
+	newTicker, newName := "SPUN", "Spun Corp"
+	costPercent := decimal.NewFromFloat(0.25)
+	_, err := svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:       models.CorporateActionSpinOff,
+		EffectiveDate:    time.Now().UTC().AddDate(0, 0, -5),
+		NewTicker:        &newTicker,
+		NewName:          &newName,
+		CostBasisPercent: &costPercent,
+	})
+	s.Require().NoError(err)
+
+	var parent, child models.InvestmentAsset
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&parent).Error)
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("account_id = ? AND ticker = ?", accID, newTicker).First(&child).Error)
+	s.Assert().True(decimal.NewFromInt(75).Equal(parent.ValueAtBuy), "got parent basis %s", parent.ValueAtBuy.String())
+	s.Assert().True(decimal.NewFromInt(25).Equal(child.ValueAtBuy), "got child basis %s", child.ValueAtBuy.String())
+	s.Assert().True(decimal.NewFromInt(10).Equal(child.Quantity), "got child quantity %s", child.Quantity.String())
+
+	var childLots []models.InvestmentTrade
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("asset_id = ?", child.ID).Find(&childLots).Error)
+	s.Require().Len(childLots, 1)
+	s.Assert().Equal(models.InvestmentBuy, childLots[0].TradeType)
+
+	s.Require().NoError(svc.RevertCorporateAction(s.Ctx, userID, assetID, s.latestCorporateActionID(assetID)))
+
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&parent).Error)
+	s.Assert().True(decimal.NewFromInt(100).Equal(parent.ValueAtBuy), "got parent basis %s", parent.ValueAtBuy.String())
+
+	var count int64
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentAsset{}).Where("id = ?", child.ID).Count(&count).Error)
+	s.Assert().Zero(count, "the spun-off asset is removed")
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Model(&models.InvestmentTrade{}).Where("asset_id = ?", child.ID).Count(&count).Error)
+	s.Assert().Zero(count, "its mirrored lots go with it")
+}
+
+// Actions stack, so only the newest one can come off.
+func (s *InvestmentServiceTestSuite) TestRevertCorporateAction_OnlyLatest() {
+	svc := s.TC.App.InvestmentService
+	userID := int64(1)
+	_, assetID := s.corporateActionAsset("STCK")
+
+	ratioFrom, ratioTo := decimal.NewFromInt(1), decimal.NewFromInt(2)
+	_, err := svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:    models.CorporateActionSplit,
+		EffectiveDate: time.Now().UTC().AddDate(0, 0, -10),
+		RatioFrom:     &ratioFrom,
+		RatioTo:       &ratioTo,
+	})
+	s.Require().NoError(err)
+	firstID := s.latestCorporateActionID(assetID)
+
+	newTicker := "STCX"
+	_, err = svc.ApplyCorporateAction(s.Ctx, userID, assetID, &models.CorporateActionReq{
+		ActionType:    models.CorporateActionTickerChange,
+		EffectiveDate: time.Now().UTC().AddDate(0, 0, -5),
+		NewTicker:     &newTicker,
+	})
+	s.Require().NoError(err)
+
+	s.Require().Error(svc.RevertCorporateAction(s.Ctx, userID, assetID, firstID))
+
+	s.Require().NoError(svc.RevertCorporateAction(s.Ctx, userID, assetID, s.latestCorporateActionID(assetID)))
+	s.Require().NoError(svc.RevertCorporateAction(s.Ctx, userID, assetID, firstID))
+
+	var asset models.InvestmentAsset
+	s.Require().NoError(s.TC.DB.WithContext(s.Ctx).Where("id = ?", assetID).First(&asset).Error)
+	s.Assert().Equal("STCK", asset.Ticker)
+	s.Assert().True(decimal.NewFromInt(10).Equal(asset.Quantity), "got quantity %s", asset.Quantity.String())
+}
@@ -0,0 +1,615 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PriceAlertServiceInterface interface {
+	FetchRules(ctx context.Context, userID int64) ([]models.PriceAlertRule, error)
+	InsertRule(ctx context.Context, userID int64, req *models.PriceAlertRuleReq) (int64, error)
+	UpdateRule(ctx context.Context, userID, id int64, req *models.PriceAlertRuleReq) (int64, error)
+	DeleteRule(ctx context.Context, userID, id int64) error
+
+	EvaluateRules(ctx context.Context, now time.Time) ([]models.PriceAlertTrigger, error)
+}
+
+type PriceAlertService struct {
+	logger         *zap.Logger
+	repo           repositories.PriceAlertRepositoryInterface
+	investmentRepo repositories.InvestmentRepositoryInterface
+	accountRepo    repositories.AccountRepositoryInterface
+	analyticsRepo  repositories.AnalyticsRepositoryInterface
+	settingsRepo   repositories.SettingsRepositoryInterface
+	loggingRepo    repositories.LoggingRepositoryInterface
+	jobDispatcher  queue.JobDispatcher
+}
+
+func NewPriceAlertService(
+	logger *zap.Logger,
+	repo *repositories.PriceAlertRepository,
+	investmentRepo *repositories.InvestmentRepository,
+	accountRepo *repositories.AccountRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	settingsRepo *repositories.SettingsRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+) *PriceAlertService {
+	return &PriceAlertService{
+		logger:         logger,
+		repo:           repo,
+		investmentRepo: investmentRepo,
+		accountRepo:    accountRepo,
+		analyticsRepo:  analyticsRepo,
+		settingsRepo:   settingsRepo,
+		loggingRepo:    loggingRepo,
+		jobDispatcher:  jobDispatcher,
+	}
+}
+
+var _ PriceAlertServiceInterface = (*PriceAlertService)(nil)
+
+const defaultPriceAlertCooldownHours = 24
+
+func (s *PriceAlertService) FetchRules(ctx context.Context, userID int64) ([]models.PriceAlertRule, error) {
+	return s.repo.FindRules(ctx, nil, userID)
+}
+
+func validatePriceAlertReq(req *models.PriceAlertRuleReq) error {
+	if req.AssetID != nil && req.AccountID != nil {
+		return fmt.Errorf("an alert applies to one asset, one account or the whole portfolio")
+	}
+	if req.Kind == models.PriceAlertPrice && req.AssetID == nil {
+		return fmt.Errorf("price alerts can be set only on assets")
+	}
+
+	if req.Kind == models.PriceAlertPercentChange {
+		if req.PeriodDays == nil {
+			return fmt.Errorf("percent change alerts need a period of 1, 7 or 30 days")
+		}
+		if !req.Threshold.IsPositive() {
+			return fmt.Errorf("percent change threshold must be positive")
+		}
+		return nil
+	}
+
+	if req.PeriodDays != nil {
+		return fmt.Errorf("only percent change alerts take a period")
+	}
+	if req.Direction == models.PriceAlertEither {
+		return fmt.Errorf("only percent change alerts can watch both directions")
+	}
+	if req.Kind != models.PriceAlertProfitLoss && !req.Threshold.IsPositive() {
+		return fmt.Errorf("threshold must be positive")
+	}
+
+	return nil
+}
+
+// checkPriceAlertScope makes sure the asset or account the rule watches belongs to the user.
+func (s *PriceAlertService) checkPriceAlertScope(ctx context.Context, tx *gorm.DB, userID int64, req *models.PriceAlertRuleReq) error {
+	if req.AssetID == nil && req.AccountID == nil {
+		return nil
+	}
+	if req.AssetID != nil {
+		if _, err := s.investmentRepo.FindInvestmentAssetByID(ctx, tx, *req.AssetID, userID); err != nil {
+			return fmt.Errorf("asset not found: %w", err)
+		}
+		return nil
+	}
+
+	accType, err := s.accountRepo.FindAccountTypeByAccID(ctx, tx, *req.AccountID, userID)
+	if err != nil {
+		return fmt.Errorf("account not found: %w", err)
+	}
+	if req.Kind == models.PriceAlertProfitLoss && accType.Type != "investment" && accType.Type != "crypto" {
+		return fmt.Errorf("P&L alerts can be set only for investment accounts")
+	}
+
+	return nil
+}
+
+var priceAlertLogFields = []string{"scope", "scope_id", "kind", "direction", "threshold", "period_days", "cooldown_hours", "is_active"}
+
+// priceAlertFields flattens a rule for the activity log; a nil rule has no fields.
+func priceAlertFields(r *models.PriceAlertRule) map[string]string {
+	fields := map[string]string{}
+	if r == nil {
+		return fields
+	}
+	if r.AssetID != nil {
+		fields["scope"] = "asset"
+		fields["scope_id"] = strconv.FormatInt(*r.AssetID, 10)
+	} else if r.AccountID != nil {
+		fields["scope"] = "account"
+		fields["scope_id"] = strconv.FormatInt(*r.AccountID, 10)
+	} else {
+		fields["scope"] = "portfolio"
+	}
+	fields["kind"] = string(r.Kind)
+	fields["direction"] = string(r.Direction)
+	fields["threshold"] = r.Threshold.String()
+	if r.PeriodDays != nil {
+		fields["period_days"] = strconv.Itoa(*r.PeriodDays)
+	}
+	fields["cooldown_hours"] = strconv.Itoa(r.CooldownHours)
+	fields["is_active"] = strconv.FormatBool(r.IsActive)
+	return fields
+}
+
+func priceAlertChanges(old, new *models.PriceAlertRule, changes *utils.Changes) {
+	oldFields, newFields := priceAlertFields(old), priceAlertFields(new)
+	for _, key := range priceAlertLogFields {
+		utils.CompareChanges(oldFields[key], newFields[key], changes, key)
+	}
+}
+
+// priceAlertTargetChanged reports whether two rules watch a different figure or level.
+func priceAlertTargetChanged(a, b models.PriceAlertRule) bool {
+	fa, fb := priceAlertFields(&a), priceAlertFields(&b)
+	for _, key := range []string{"scope", "scope_id", "kind", "direction", "threshold", "period_days"} {
+		if fa[key] != fb[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func priceAlertRuleFromReq(req *models.PriceAlertRuleReq) models.PriceAlertRule {
+	record := models.PriceAlertRule{
+		AssetID:       req.AssetID,
+		AccountID:     req.AccountID,
+		Kind:          req.Kind,
+		Direction:     req.Direction,
+		Threshold:     req.Threshold,
+		PeriodDays:    req.PeriodDays,
+		CooldownHours: defaultPriceAlertCooldownHours,
+		IsActive:      true,
+	}
+	if req.CooldownHours != nil {
+		record.CooldownHours = *req.CooldownHours
+	}
+	if req.IsActive != nil {
+		record.IsActive = *req.IsActive
+	}
+	return record
+}
+
+func (s *PriceAlertService) InsertRule(ctx context.Context, userID int64, req *models.PriceAlertRuleReq) (int64, error) {
+	if err := validatePriceAlertReq(req); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkPriceAlertScope(ctx, nil, userID, req); err != nil {
+		return 0, err
+	}
+
+	record := priceAlertRuleFromReq(req)
+	record.UserID = userID
+
+	id, err := s.repo.InsertRule(ctx, nil, &record)
+	if err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	priceAlertChanges(nil, &record, changes)
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "price_alert",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateRule replaces the rule's settings. Changing what the rule watches re-arms it,
+// so an edited rule can fire on the next sync even while the old one was cooling down.
+func (s *PriceAlertService) UpdateRule(ctx context.Context, userID, id int64, req *models.PriceAlertRuleReq) (int64, error) {
+	if err := validatePriceAlertReq(req); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindRuleByID(ctx, tx, id, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("alert not found: %w", err)
+	}
+
+	if err := s.checkPriceAlertScope(ctx, tx, userID, req); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	record := priceAlertRuleFromReq(req)
+	record.ID = existing.ID
+	record.UserID = existing.UserID
+	record.LastTriggeredAt = existing.LastTriggeredAt
+	record.LastHit = existing.LastHit
+
+	if priceAlertTargetChanged(existing, record) {
+		record.LastTriggeredAt = nil
+		record.LastHit = false
+	}
+
+	changes := utils.InitChanges()
+	priceAlertChanges(&existing, &record, changes)
+
+	if _, err := s.repo.UpdateRule(ctx, tx, record); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "update",
+			Category:    "price_alert",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return record.ID, nil
+}
+
+func (s *PriceAlertService) DeleteRule(ctx context.Context, userID, id int64) error {
+	existing, err := s.repo.FindRuleByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("alert not found: %w", err)
+	}
+
+	if err := s.repo.DeleteRule(ctx, nil, existing.ID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(existing.ID, 10), "", changes, "id")
+	priceAlertChanges(&existing, nil, changes)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "price_alert",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// priceAlertObservation is what a rule is measured against: the current figure,
+// the figure PeriodDays ago for percent change rules, and how to describe them.
+type priceAlertObservation struct {
+	label     string
+	currency  string
+	current   decimal.Decimal
+	reference *decimal.Decimal
+}
+
+// EvaluateRules measures every active rule against the latest prices and snapshot
+// values, stamps the ones that fired so their cooldown starts now, and returns them
+// for delivery. A rule fires when its figure crosses onto the alert side, not on
+// every run it stays there: a fired rule is held until its figure goes back. A
+// crossing swallowed by the cooldown is not held, so it fires once the cooldown ends.
+// A rule that cannot be measured is skipped rather than failing the run.
+func (s *PriceAlertService) EvaluateRules(ctx context.Context, now time.Time) ([]models.PriceAlertTrigger, error) {
+	rules, err := s.repo.FindActiveRules(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggers []models.PriceAlertTrigger
+	var firedIDs, clearedIDs []int64
+	for _, rule := range rules {
+		obs, ok, err := s.observePriceAlert(ctx, rule, now)
+		if err != nil {
+			s.logger.Warn("Failed to evaluate price alert",
+				zap.Int64("rule_id", rule.ID), zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		measured, hit, fired := utils.EvaluatePriceAlert(rule, obs.current, obs.reference, now)
+		if !hit && rule.LastHit {
+			clearedIDs = append(clearedIDs, rule.ID)
+		}
+		if !fired {
+			continue
+		}
+
+		title, msg := priceAlertText(rule, obs, measured)
+		triggers = append(triggers, models.PriceAlertTrigger{
+			RuleID:  rule.ID,
+			UserID:  rule.UserID,
+			Title:   title,
+			Message: msg,
+		})
+		firedIDs = append(firedIDs, rule.ID)
+	}
+
+	if err := s.repo.SetLastHit(ctx, nil, firedIDs, true); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetLastHit(ctx, nil, clearedIDs, false); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetLastTriggeredAt(ctx, nil, firedIDs, now); err != nil {
+		return nil, err
+	}
+
+	return triggers, nil
+}
+
+func (s *PriceAlertService) observePriceAlert(ctx context.Context, rule models.PriceAlertRule, now time.Time) (priceAlertObservation, bool, error) {
+	var since *time.Time
+	if rule.Kind == models.PriceAlertPercentChange && rule.PeriodDays != nil {
+		t := now.AddDate(0, 0, -*rule.PeriodDays)
+		since = &t
+	}
+
+	if rule.AssetID != nil {
+		asset, err := s.investmentRepo.FindInvestmentAssetByID(ctx, nil, *rule.AssetID, rule.UserID)
+		if err != nil {
+			return priceAlertObservation{}, false, err
+		}
+		obs := priceAlertObservation{label: asset.Ticker, currency: asset.Currency}
+
+		switch rule.Kind {
+		case models.PriceAlertPercentChange, models.PriceAlertPrice:
+			if asset.CurrentPrice == nil {
+				return obs, false, nil
+			}
+			obs.current = *asset.CurrentPrice
+		case models.PriceAlertValue:
+			obs.current = asset.CurrentValue
+		case models.PriceAlertProfitLoss:
+			costBasis := asset.CurrentValue.Sub(asset.ProfitLoss)
+			if !costBasis.IsPositive() {
+				return obs, false, nil
+			}
+			obs.current = asset.ProfitLoss.Div(costBasis).Mul(decimal.NewFromInt(100))
+		}
+
+		if since != nil {
+			past, err := s.investmentRepo.FindAssetPriceOnOrBefore(ctx, nil, asset.ID, since)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return obs, false, nil
+				}
+				return obs, false, err
+			}
+			obs.reference = &past.Price
+		}
+
+		return obs, true, nil
+	}
+
+	if rule.AccountID == nil {
+		return s.observePortfolio(ctx, rule, since, now)
+	}
+
+	acc, err := s.accountRepo.FindAccountByID(ctx, nil, *rule.AccountID, rule.UserID, false)
+	if err != nil {
+		return priceAlertObservation{}, false, err
+	}
+	obs := priceAlertObservation{label: acc.Name, currency: acc.Currency}
+
+	if rule.Kind == models.PriceAlertProfitLoss {
+		assets, err := s.investmentRepo.FindAssetsByAccountID(ctx, nil, acc.ID, acc.UserID)
+		if err != nil {
+			return obs, false, err
+		}
+		pct, ok, err := s.profitLossPercent(ctx, assets, acc.Currency, now)
+		if err != nil || !ok {
+			return obs, false, err
+		}
+		obs.current = pct
+		return obs, true, nil
+	}
+
+	current, ok, err := s.repo.FindAccountValueOnOrBefore(ctx, nil, acc.ID, nil)
+	if err != nil || !ok {
+		return obs, false, err
+	}
+	obs.current = current
+
+	if since != nil {
+		past, ok, err := s.repo.FindAccountValueOnOrBefore(ctx, nil, acc.ID, since)
+		if err != nil || !ok {
+			return obs, false, err
+		}
+		obs.reference = &past
+	}
+
+	return obs, true, nil
+}
+
+// observePortfolio measures a rule without an asset or account against the user's
+// investment and crypto accounts taken together, in the default currency.
+func (s *PriceAlertService) observePortfolio(ctx context.Context, rule models.PriceAlertRule, since *time.Time, now time.Time) (priceAlertObservation, bool, error) {
+	settings, err := s.settingsRepo.FetchUserSettings(ctx, nil, rule.UserID)
+	if err != nil {
+		return priceAlertObservation{}, false, err
+	}
+	obs := priceAlertObservation{label: "Portfolio", currency: settings.DefaultCurrency}
+
+	if rule.Kind == models.PriceAlertProfitLoss {
+		assets, err := s.investmentRepo.FindAllInvestmentAssets(ctx, nil, rule.UserID)
+		if err != nil {
+			return obs, false, err
+		}
+		pct, ok, err := s.profitLossPercent(ctx, assets, obs.currency, now)
+		if err != nil || !ok {
+			return obs, false, err
+		}
+		obs.current = pct
+		return obs, true, nil
+	}
+
+	accounts, err := s.accountRepo.FindAllAccounts(ctx, nil, rule.UserID, false, true)
+	if err != nil {
+		return obs, false, err
+	}
+	var portfolio []models.Account
+	for _, acc := range accounts {
+		if acc.AccountType.Type == "investment" || acc.AccountType.Type == "crypto" {
+			portfolio = append(portfolio, acc)
+		}
+	}
+
+	current, ok, err := s.portfolioValue(ctx, portfolio, obs.currency, nil, now)
+	if err != nil || !ok {
+		return obs, false, err
+	}
+	obs.current = current
+
+	if since != nil {
+		past, ok, err := s.portfolioValue(ctx, portfolio, obs.currency, since, *since)
+		if err != nil || !ok {
+			return obs, false, err
+		}
+		obs.reference = &past
+	}
+
+	return obs, true, nil
+}
+
+// portfolioValue adds up the accounts' values on or before asOf, the latest with nil,
+// converted into currency at the rates of rateOn.
+func (s *PriceAlertService) portfolioValue(ctx context.Context, accounts []models.Account, currency string, asOf *time.Time, rateOn time.Time) (decimal.Decimal, bool, error) {
+	rates := map[string]decimal.Decimal{currency: decimal.NewFromInt(1)}
+	total := decimal.Zero
+	found := false
+	for _, acc := range accounts {
+		value, ok, err := s.repo.FindAccountValueOnOrBefore(ctx, nil, acc.ID, asOf)
+		if err != nil {
+			return decimal.Zero, false, err
+		}
+		if !ok {
+			continue
+		}
+		rate, err := s.alertRate(ctx, rates, acc.Currency, currency, rateOn)
+		if err != nil {
+			return decimal.Zero, false, err
+		}
+		total = total.Add(value.Mul(rate))
+		found = true
+	}
+	return total, found, nil
+}
+
+// profitLossPercent is the unrealized P&L of the holdings over their combined cost
+// basis, both taken in currency.
+func (s *PriceAlertService) profitLossPercent(ctx context.Context, assets []models.InvestmentAsset, currency string, now time.Time) (decimal.Decimal, bool, error) {
+	rates := map[string]decimal.Decimal{currency: decimal.NewFromInt(1)}
+	profit, cost := decimal.Zero, decimal.Zero
+	for _, a := range assets {
+		rate, err := s.alertRate(ctx, rates, a.Currency, currency, now)
+		if err != nil {
+			return decimal.Zero, false, err
+		}
+		profit = profit.Add(a.ProfitLoss.Mul(rate))
+		cost = cost.Add(a.CurrentValue.Sub(a.ProfitLoss).Mul(rate))
+	}
+
+	if !cost.IsPositive() {
+		return decimal.Zero, false, nil
+	}
+	return profit.Div(cost).Mul(decimal.NewFromInt(100)), true, nil
+}
+
+// alertRate looks up and caches the latest stored rate from one currency into another.
+func (s *PriceAlertService) alertRate(ctx context.Context, rates map[string]decimal.Decimal, from, to string, on time.Time) (decimal.Decimal, error) {
+	if rate, ok := rates[from]; ok {
+		return rate, nil
+	}
+	rate, found, err := s.analyticsRepo.FetchLatestExchangeRate(ctx, nil, from, to, on)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !found {
+		s.logger.Warn("no exchange rate for price alert, using 1:1",
+			zap.String("from", from), zap.String("to", to))
+		rate = decimal.NewFromInt(1)
+	}
+	rates[from] = rate
+	return rate, nil
+}
+
+func priceAlertText(rule models.PriceAlertRule, obs priceAlertObservation, measured decimal.Decimal) (string, string) {
+	side := "above"
+	if rule.Direction == models.PriceAlertBelow {
+		side = "below"
+	}
+
+	switch rule.Kind {
+	case models.PriceAlertPercentChange:
+		move := "risen"
+		short := "up"
+		if measured.IsNegative() {
+			move = "fallen"
+			short = "down"
+		}
+		pct := measured.Abs().StringFixed(1)
+		title := fmt.Sprintf("%s %s %s%% in %d days", obs.label, short, pct, *rule.PeriodDays)
+		msg := fmt.Sprintf("%s has %s by %s%% over the last %d days (from %s to %s %s).",
+			obs.label, move, pct, *rule.PeriodDays, obs.reference.StringFixed(2), obs.current.StringFixed(2), obs.currency)
+		return title, msg
+	case models.PriceAlertProfitLoss:
+		title := fmt.Sprintf("%s P&L %s %s%%", obs.label, side, rule.Threshold.StringFixed(1))
+		msg := fmt.Sprintf("%s is at %s%% unrealized P&L, %s your %s%% alert.",
+			obs.label, measured.StringFixed(1), side, rule.Threshold.StringFixed(1))
+		return title, msg
+	case models.PriceAlertValue:
+		title := fmt.Sprintf("%s value %s %s %s", obs.label, side, rule.Threshold.StringFixed(2), obs.currency)
+		msg := fmt.Sprintf("%s is worth %s %s, %s your %s %s alert.",
+			obs.label, measured.StringFixed(2), obs.currency, side, rule.Threshold.StringFixed(2), obs.currency)
+		return title, msg
+	default:
+		title := fmt.Sprintf("%s %s %s %s", obs.label, side, rule.Threshold.StringFixed(2), obs.currency)
+		msg := fmt.Sprintf("%s is trading at %s %s, %s your %s %s alert.",
+			obs.label, measured.StringFixed(2), obs.currency, side, rule.Threshold.StringFixed(2), obs.currency)
+		return title, msg
+	}
+}
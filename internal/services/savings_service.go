@@ -2,17 +2,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 	"wealth-warden/internal/models"
 	"wealth-warden/internal/queue"
 	"wealth-warden/internal/queue/queue_jobs"
 	"wealth-warden/internal/repositories"
+	"wealth-warden/internal/ws"
 	"wealth-warden/pkg/utils"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type SavingsServiceInterface interface {
@@ -23,32 +27,55 @@ type SavingsServiceInterface interface {
 	DeleteGoal(ctx context.Context, userID, id int64) error
 
 	FetchContributions(ctx context.Context, userID, goalID int64) ([]models.SavingContribution, error)
-	FetchContributionsPaginated(ctx context.Context, userID, goalID int64, p utils.PaginationParams) ([]models.SavingContribution, *utils.Paginator, error)
+	FetchContributionsPaginated(ctx context.Context, userID, goalID int64, cycle *int, p utils.PaginationParams) ([]models.SavingContribution, *utils.Paginator, error)
 	InsertContribution(ctx context.Context, userID, goalID int64, req *models.SavingContributionReq) (int64, error)
 	DeleteContribution(ctx context.Context, userID, goalID, id int64) error
 
+	FetchCycles(ctx context.Context, userID, goalID int64) ([]models.SavingGoalCycle, error)
+	SettleRecurringGoal(ctx context.Context, userID, goalID int64, req *models.SavingGoalSettleReq) (int64, error)
+	PayRecurringGoal(ctx context.Context, userID, goalID int64, req *models.SavingGoalPayReq) (int64, error)
+
+	FetchMilestones(ctx context.Context, userID, goalID int64) ([]models.SavingGoalMilestone, error)
+	SaveMilestones(ctx context.Context, userID, goalID int64, req *models.SavingGoalMilestonesReq) error
+	MarkBehindGoals(ctx context.Context) ([]models.SavingGoalWithProgress, error)
+
 	AutoFundGoal(ctx context.Context, goal models.SavingGoal, month time.Time) (funded bool, skipReason string, err error)
 	FetchActiveGoalsWithAllocation(ctx context.Context, dayOfMonth int) ([]models.SavingGoal, error)
+
+	FetchReconciliation(ctx context.Context, userID int64) ([]models.SavingsReconciliation, error)
+
+	FetchBudget(ctx context.Context, userID int64) (*models.SavingsBudget, error)
+	SaveBudget(ctx context.Context, userID int64, req *models.SavingsBudgetReq) (int64, error)
+	DeleteBudget(ctx context.Context, userID int64) error
+	PlanBudget(ctx context.Context, userID int64, month time.Time) (*models.SavingsBudgetPlan, error)
+	FetchDueBudgets(ctx context.Context, dayOfMonth int) ([]models.SavingsBudget, error)
+	FundFromBudget(ctx context.Context, budget models.SavingsBudget, month time.Time) ([]models.SavingGoalFundResult, error)
 }
 
 type SavingsService struct {
-	repo          repositories.SavingsRepositoryInterface
-	accountRepo   repositories.AccountRepositoryInterface
-	loggingRepo   repositories.LoggingRepositoryInterface
-	jobDispatcher queue.JobDispatcher
+	repo            repositories.SavingsRepositoryInterface
+	accountRepo     repositories.AccountRepositoryInterface
+	loggingRepo     repositories.LoggingRepositoryInterface
+	txnService      TransactionServiceInterface
+	jobDispatcher   queue.JobDispatcher
+	notifDispatcher queue_jobs.NotificationDispatcher
 }
 
 func NewSavingsService(
 	repo *repositories.SavingsRepository,
 	accountRepo *repositories.AccountRepository,
 	loggingRepo *repositories.LoggingRepository,
+	txnService *TransactionService,
 	jobDispatcher queue.JobDispatcher,
+	notifDispatcher queue_jobs.NotificationDispatcher,
 ) *SavingsService {
 	return &SavingsService{
-		repo:          repo,
-		accountRepo:   accountRepo,
-		loggingRepo:   loggingRepo,
-		jobDispatcher: jobDispatcher,
+		repo:            repo,
+		accountRepo:     accountRepo,
+		loggingRepo:     loggingRepo,
+		txnService:      txnService,
+		jobDispatcher:   jobDispatcher,
+		notifDispatcher: notifDispatcher,
 	}
 }
 
@@ -101,6 +128,11 @@ func (s *SavingsService) InsertGoal(ctx context.Context, userID int64, req *mode
 		return 0, fmt.Errorf("goals can be linked only to cash accounts")
 	}
 
+	if err := s.validateFundingAccount(ctx, tx, userID, req.AccountID, req.FundingAccountID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	if req.InitialAmount != nil && req.InitialAmount.IsPositive() {
 		uncategorized, err := s.repo.GetUncategorizedBalance(ctx, tx, req.AccountID, userID)
 		if err != nil {
@@ -123,6 +155,17 @@ func (s *SavingsService) InsertGoal(ctx context.Context, userID int64, req *mode
 		targetDate = &parsed
 	}
 
+	if req.Recurrence != nil && targetDate == nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("recurring goals need a due date")
+	}
+
+	completionAction, err := resolveCompletionAction(req.CompletionAction, req.Recurrence)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	record := models.SavingGoal{
 		UserID:            userID,
 		AccountID:         req.AccountID,
@@ -133,6 +176,10 @@ func (s *SavingsService) InsertGoal(ctx context.Context, userID int64, req *mode
 		Priority:          req.Priority,
 		MonthlyAllocation: req.MonthlyAllocation,
 		FundDayOfMonth:    req.FundDayOfMonth,
+		FundingAccountID:  req.FundingAccountID,
+		Recurrence:        req.Recurrence,
+		CycleNumber:       1,
+		CompletionAction:  completionAction,
 	}
 
 	if req.InitialAmount != nil && req.InitialAmount.IsPositive() {
@@ -149,11 +196,12 @@ func (s *SavingsService) InsertGoal(ctx context.Context, userID int64, req *mode
 		now := time.Now().UTC()
 		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
 		contrib := models.SavingContribution{
-			UserID: userID,
-			GoalID: id,
-			Amount: *req.InitialAmount,
-			Month:  monthStart,
-			Source: models.SavingContributionSourceManual,
+			UserID:      userID,
+			GoalID:      id,
+			Amount:      *req.InitialAmount,
+			Month:       monthStart,
+			Source:      models.SavingContributionSourceManual,
+			CycleNumber: record.CycleNumber,
 		}
 		if _, err := s.repo.InsertContribution(ctx, tx, &contrib); err != nil {
 			tx.Rollback()
@@ -204,6 +252,11 @@ func (s *SavingsService) UpdateGoal(ctx context.Context, userID, id int64, req *
 		return 0, fmt.Errorf("goal not found: %w", err)
 	}
 
+	if err := s.validateFundingAccount(ctx, tx, userID, existing.AccountID, req.FundingAccountID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	var targetDate *time.Time
 	if req.TargetDate != nil && *req.TargetDate != "" {
 		parsed, parseErr := time.Parse("2006-01-02", *req.TargetDate)
@@ -214,12 +267,26 @@ func (s *SavingsService) UpdateGoal(ctx context.Context, userID, id int64, req *
 		targetDate = &parsed
 	}
 
+	if req.Recurrence != nil && targetDate == nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("recurring goals need a due date")
+	}
+
+	completionAction, err := resolveCompletionAction(req.CompletionAction, req.Recurrence)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	changes := utils.InitChanges()
 	utils.CompareChanges(existing.Name, req.Name, changes, "name")
 	utils.CompareDecimalChange(&existing.TargetAmount, &req.TargetAmount, changes, "target_amount", 2)
 	utils.CompareDecimalChange(existing.MonthlyAllocation, req.MonthlyAllocation, changes, "monthly_allocation", 2)
 	utils.CompareDateChange(existing.TargetDate, targetDate, changes, "target_date")
 	utils.CompareChanges(string(existing.Status), string(req.Status), changes, "status")
+	utils.CompareChanges(optionalID(existing.FundingAccountID), optionalID(req.FundingAccountID), changes, "funding_account_id")
+	utils.CompareChanges(recurrenceLabel(existing.Recurrence), recurrenceLabel(req.Recurrence), changes, "recurrence")
+	utils.CompareChanges(string(existing.CompletionAction), string(completionAction), changes, "completion_action")
 
 	existing.Name = req.Name
 	existing.TargetAmount = req.TargetAmount
@@ -228,6 +295,9 @@ func (s *SavingsService) UpdateGoal(ctx context.Context, userID, id int64, req *
 	existing.Priority = req.Priority
 	existing.MonthlyAllocation = req.MonthlyAllocation
 	existing.FundDayOfMonth = req.FundDayOfMonth
+	existing.FundingAccountID = req.FundingAccountID
+	existing.Recurrence = req.Recurrence
+	existing.CompletionAction = completionAction
 
 	goalID, err := s.repo.UpdateGoal(ctx, tx, existing)
 	if err != nil {
@@ -313,19 +383,19 @@ func (s *SavingsService) FetchContributions(ctx context.Context, userID, goalID
 	return s.repo.FindContributions(ctx, nil, goalID)
 }
 
-func (s *SavingsService) FetchContributionsPaginated(ctx context.Context, userID, goalID int64, p utils.PaginationParams) ([]models.SavingContribution, *utils.Paginator, error) {
+func (s *SavingsService) FetchContributionsPaginated(ctx context.Context, userID, goalID int64, cycle *int, p utils.PaginationParams) ([]models.SavingContribution, *utils.Paginator, error) {
 	_, err := s.repo.FindGoalByID(ctx, nil, goalID, userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("goal not found: %w", err)
 	}
 
-	total, err := s.repo.CountContributions(ctx, nil, goalID)
+	total, err := s.repo.CountContributions(ctx, nil, goalID, cycle)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	offset := (p.PageNumber - 1) * p.RowsPerPage
-	records, err := s.repo.FindContributionsPaginated(ctx, nil, goalID, offset, p.RowsPerPage, p.SortField, p.SortOrder)
+	records, err := s.repo.FindContributionsPaginated(ctx, nil, goalID, cycle, offset, p.RowsPerPage, p.SortField, p.SortOrder)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -380,6 +450,23 @@ func (s *SavingsService) InsertContribution(ctx context.Context, userID, goalID
 		return 0, fmt.Errorf("cannot add contributions to a %s goal", goal.Status)
 	}
 
+	month, err := time.Parse("2006-01-02", req.Month)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("invalid month: %w", err)
+	}
+
+	// Deposits into a transfer-backed goal bring the money in before it is allocated
+	var transferID *int64
+	var transferLog *queue_jobs.ActivityLogJob
+	if goal.FundingAccountID != nil && req.Amount.IsPositive() {
+		transferID, transferLog, err = s.moveGoalFunds(ctx, tx, goal, req.Amount, &userID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
 	if req.Amount.IsNegative() {
 		if req.Amount.Abs().GreaterThan(goal.CurrentAmount) {
 			tx.Rollback()
@@ -397,19 +484,31 @@ func (s *SavingsService) InsertContribution(ctx context.Context, userID, goalID
 		}
 	}
 
-	month, err := time.Parse("2006-01-02", req.Month)
-	if err != nil {
+	before := goal.CurrentAmount
+	goal.CurrentAmount = goal.CurrentAmount.Add(req.Amount)
+	if err := s.repo.UpdateCurrentAmount(ctx, tx, goalID, goal); err != nil {
 		tx.Rollback()
-		return 0, fmt.Errorf("invalid month: %w", err)
+		return 0, err
+	}
+
+	// Withdrawals are released from the goal first, then sent back to the funding account
+	if goal.FundingAccountID != nil && req.Amount.IsNegative() {
+		transferID, transferLog, err = s.moveGoalFunds(ctx, tx, goal, req.Amount, &userID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
 	}
 
 	record := models.SavingContribution{
-		UserID: userID,
-		GoalID: goalID,
-		Amount: req.Amount,
-		Month:  month,
-		Note:   req.Note,
-		Source: models.SavingContributionSourceManual,
+		UserID:      userID,
+		GoalID:      goalID,
+		Amount:      req.Amount,
+		Month:       month,
+		Note:        req.Note,
+		Source:      models.SavingContributionSourceManual,
+		TransferID:  transferID,
+		CycleNumber: goal.CycleNumber,
 	}
 
 	id, err := s.repo.InsertContribution(ctx, tx, &record)
@@ -418,8 +517,8 @@ func (s *SavingsService) InsertContribution(ctx context.Context, userID, goalID
 		return 0, err
 	}
 
-	goal.CurrentAmount = goal.CurrentAmount.Add(req.Amount)
-	if err := s.repo.UpdateCurrentAmount(ctx, tx, goalID, goal); err != nil {
+	progress, err := s.advanceGoal(ctx, tx, &goal, before)
+	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
@@ -428,6 +527,12 @@ func (s *SavingsService) InsertContribution(ctx context.Context, userID, goalID
 		return 0, err
 	}
 
+	if transferLog != nil {
+		if err := s.jobDispatcher.Dispatch(ctx, transferLog); err != nil {
+			return 0, err
+		}
+	}
+
 	changes := utils.InitChanges()
 	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
 	utils.CompareChanges("", goal.Name, changes, "goal")
@@ -444,6 +549,8 @@ func (s *SavingsService) InsertContribution(ctx context.Context, userID, goalID
 		return 0, err
 	}
 
+	s.reportGoalProgress(ctx, goal, progress, &userID)
+
 	return id, nil
 }
 
@@ -472,6 +579,11 @@ func (s *SavingsService) DeleteContribution(ctx context.Context, userID, goalID,
 		return fmt.Errorf("contribution not found: %w", err)
 	}
 
+	if contrib.Source == models.SavingContributionSourceSettlement {
+		tx.Rollback()
+		return fmt.Errorf("settlements close a goal cycle and can't be removed")
+	}
+
 	if err := s.repo.DeleteContribution(ctx, tx, id); err != nil {
 		tx.Rollback()
 		return err
@@ -486,10 +598,36 @@ func (s *SavingsService) DeleteContribution(ctx context.Context, userID, goalID,
 		return err
 	}
 
+	var transferLog *queue_jobs.ActivityLogJob
+	if contrib.TransferID != nil {
+		transfer, fromName, toName, err := s.loadGoalTransfer(ctx, tx, userID, *contrib.TransferID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := s.txnService.DeleteTransfer(ctx, userID, *contrib.TransferID, tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to reverse goal transfer: %w", err)
+		}
+		transferLog = &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "delete",
+			Category:    "transfer",
+			Payload:     transferDeletedChanges(transfer, fromName, toName, goal.Name),
+			Causer:      &userID,
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
+	if transferLog != nil {
+		if err := s.jobDispatcher.Dispatch(ctx, transferLog); err != nil {
+			return err
+		}
+	}
+
 	changes := utils.InitChanges()
 	utils.CompareChanges("", strconv.FormatInt(contrib.ID, 10), changes, "id")
 	utils.CompareChanges(goal.Name, "", changes, "goal")
@@ -511,11 +649,545 @@ func (s *SavingsService) DeleteContribution(ctx context.Context, userID, goalID,
 	return nil
 }
 
+func (s *SavingsService) FetchCycles(ctx context.Context, userID, goalID int64) ([]models.SavingGoalCycle, error) {
+	if _, err := s.repo.FindGoalByID(ctx, nil, goalID, userID); err != nil {
+		return nil, fmt.Errorf("goal not found: %w", err)
+	}
+	return s.repo.FindCycles(ctx, nil, goalID)
+}
+
+// SettleRecurringGoal links an already booked expense that paid a recurring
+// goal's bill, drawing the goal down and moving it on to its next cycle.
+func (s *SavingsService) SettleRecurringGoal(ctx context.Context, userID, goalID int64, req *models.SavingGoalSettleReq) (int64, error) {
+	txn, err := s.txnService.FetchTransactionByID(ctx, userID, req.TransactionID, false)
+	if err != nil {
+		return 0, fmt.Errorf("transaction not found: %w", err)
+	}
+	if txn.TransactionType != "expense" || txn.IsTransfer {
+		return 0, fmt.Errorf("only an expense can settle a goal")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	goal, err := s.repo.FindGoalByID(ctx, tx, goalID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("goal not found: %w", err)
+	}
+	if err := checkSettleable(goal); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if txn.AccountID != goal.AccountID {
+		tx.Rollback()
+		return 0, fmt.Errorf("the expense must be paid from the goal's account")
+	}
+
+	if _, err := s.repo.FindCycleByTransactionID(ctx, tx, txn.ID, userID); err == nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("transaction already settles a goal")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return 0, err
+	}
+
+	cycle, err := s.closeCycle(ctx, tx, &goal, txn.ID, txn.Amount, txn.TxnDate, txn.Description)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if err := s.logSettlement(ctx, userID, goal, cycle); err != nil {
+		return 0, err
+	}
+
+	return cycle.ID, nil
+}
+
+// PayRecurringGoal books the bill of a recurring goal as an expense on the
+// goal's account. The goal's money is released first so the expense can use
+// it, then the cycle is closed the same way as SettleRecurringGoal.
+func (s *SavingsService) PayRecurringGoal(ctx context.Context, userID, goalID int64, req *models.SavingGoalPayReq) (int64, error) {
+	if !req.Amount.IsPositive() {
+		return 0, fmt.Errorf("amount must be positive")
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	goal, err := s.repo.FindGoalByID(ctx, tx, goalID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("goal not found: %w", err)
+	}
+	if err := checkSettleable(goal); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	saved := goal.CurrentAmount
+	released := goal
+	released.CurrentAmount = saved.Sub(decimal.Min(req.Amount, saved))
+	if err := s.repo.UpdateCurrentAmount(ctx, tx, goal.ID, released); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	txnDate := req.TxnDate
+	if txnDate.IsZero() {
+		txnDate = time.Now().UTC()
+	}
+	description := req.Description
+	if description == nil {
+		d := goal.Name
+		description = &d
+	}
+
+	res, err := s.txnService.InsertTransaction(ctx, userID, &models.TransactionReq{
+		AccountID:       goal.AccountID,
+		CategoryID:      req.CategoryID,
+		TransactionType: "expense",
+		Amount:          req.Amount,
+		TxnDate:         txnDate,
+		Description:     description,
+	}, tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	cycle, err := s.closeCycle(ctx, tx, &goal, res.ID, req.Amount, txnDate, description)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	if err := s.logSettlement(ctx, userID, goal, cycle); err != nil {
+		return 0, err
+	}
+
+	return cycle.ID, nil
+}
+
+func checkSettleable(goal models.SavingGoal) error {
+	if goal.Recurrence == nil || goal.TargetDate == nil {
+		return fmt.Errorf("only recurring goals can be settled")
+	}
+	if goal.Status != models.SavingGoalStatusActive && goal.Status != models.SavingGoalStatusCompleted {
+		return fmt.Errorf("cannot settle a %s goal", goal.Status)
+	}
+	return nil
+}
+
+// closeCycle draws the goal down by what was spent, records the finished
+// cycle and moves the goal to its next due date. Anything saved beyond the
+// bill carries over into the new cycle.
+func (s *SavingsService) closeCycle(ctx context.Context, tx *gorm.DB, goal *models.SavingGoal, txnID int64, spent decimal.Decimal, paidAt time.Time, note *string) (models.SavingGoalCycle, error) {
+	saved := goal.CurrentAmount
+	drawdown := decimal.Min(spent, saved)
+
+	if drawdown.IsPositive() {
+		month := time.Date(paidAt.Year(), paidAt.Month(), 1, 0, 0, 0, 0, time.UTC)
+		settlement := models.SavingContribution{
+			UserID:        goal.UserID,
+			GoalID:        goal.ID,
+			Amount:        drawdown.Neg(),
+			Month:         month,
+			Note:          note,
+			Source:        models.SavingContributionSourceSettlement,
+			CycleNumber:   goal.CycleNumber,
+			TransactionID: &txnID,
+		}
+		if _, err := s.repo.InsertContribution(ctx, tx, &settlement); err != nil {
+			return models.SavingGoalCycle{}, err
+		}
+	}
+
+	startedAt := goal.CycleStartedAt
+	if startedAt == nil {
+		startedAt = &goal.CreatedAt
+	}
+	dueDate := *goal.TargetDate
+
+	cycle := models.SavingGoalCycle{
+		UserID:        goal.UserID,
+		GoalID:        goal.ID,
+		CycleNumber:   goal.CycleNumber,
+		StartedAt:     startedAt,
+		DueDate:       dueDate,
+		Saved:         saved,
+		Spent:         spent,
+		CarriedOver:   saved.Sub(drawdown),
+		TransactionID: &txnID,
+		SettledAt:     time.Now().UTC(),
+	}
+	if _, err := s.repo.InsertCycle(ctx, tx, &cycle); err != nil {
+		return models.SavingGoalCycle{}, err
+	}
+
+	nextDue := nextDueDate(dueDate, *goal.Recurrence, paidAt)
+	goal.CurrentAmount = cycle.CarriedOver
+	goal.CycleNumber++
+	goal.CycleStartedAt = &dueDate
+	goal.TargetDate = &nextDue
+	goal.Status = models.SavingGoalStatusActive
+	if err := s.repo.AdvanceCycle(ctx, tx, *goal); err != nil {
+		return models.SavingGoalCycle{}, err
+	}
+
+	return cycle, nil
+}
+
+func (s *SavingsService) logSettlement(ctx context.Context, userID int64, goal models.SavingGoal, cycle models.SavingGoalCycle) error {
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.Itoa(cycle.CycleNumber), strconv.Itoa(goal.CycleNumber), changes, "cycle")
+	utils.CompareDecimalChange(&cycle.Saved, &goal.CurrentAmount, changes, "current_amount", 2)
+	utils.CompareDateChange(&cycle.DueDate, goal.TargetDate, changes, "target_date")
+	changes.Stamp("id", strconv.FormatInt(goal.ID, 10))
+	if cycle.TransactionID != nil {
+		changes.Stamp("transaction_id", strconv.FormatInt(*cycle.TransactionID, 10))
+	}
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "saving_goal",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *SavingsService) FetchMilestones(ctx context.Context, userID, goalID int64) ([]models.SavingGoalMilestone, error) {
+	if _, err := s.repo.FindGoalByID(ctx, nil, goalID, userID); err != nil {
+		return nil, fmt.Errorf("goal not found: %w", err)
+	}
+	return s.repo.FindMilestones(ctx, nil, goalID)
+}
+
+// SaveMilestones replaces the goal's milestones. Milestones kept from before keep
+// their reached state; new ones the goal has already passed count as reached in
+// the current cycle without notifying.
+func (s *SavingsService) SaveMilestones(ctx context.Context, userID, goalID int64, req *models.SavingGoalMilestonesReq) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	goal, err := s.repo.FindGoalByID(ctx, tx, goalID, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("goal not found: %w", err)
+	}
+
+	existing, err := s.repo.FindMilestones(ctx, tx, goalID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now().UTC()
+	records := make([]models.SavingGoalMilestone, 0, len(req.Milestones))
+	for _, m := range req.Milestones {
+		if !m.Value.IsPositive() {
+			tx.Rollback()
+			return fmt.Errorf("milestone value must be positive")
+		}
+		if m.Kind == models.SavingGoalMilestonePercent && m.Value.GreaterThan(decimal.NewFromInt(100)) {
+			tx.Rollback()
+			return fmt.Errorf("percent milestones can't go past 100%%")
+		}
+
+		record := models.SavingGoalMilestone{UserID: userID, GoalID: goalID, Kind: m.Kind, Value: m.Value}
+		for _, r := range records {
+			if r.Kind == record.Kind && r.Value.Equal(record.Value) {
+				tx.Rollback()
+				return fmt.Errorf("milestone %s is listed twice", utils.MilestoneLabel(record))
+			}
+		}
+
+		for _, old := range existing {
+			if old.Kind == record.Kind && old.Value.Equal(record.Value) {
+				record.ReachedCycle = old.ReachedCycle
+				record.ReachedAt = old.ReachedAt
+			}
+		}
+		if record.ReachedCycle == nil && goal.CurrentAmount.GreaterThanOrEqual(utils.MilestoneAmount(record, goal.TargetAmount)) {
+			cycle := goal.CycleNumber
+			record.ReachedCycle = &cycle
+			record.ReachedAt = &now
+		}
+		records = append(records, record)
+	}
+
+	if err := s.repo.ReplaceMilestones(ctx, tx, goalID, records); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(milestoneLabels(existing), milestoneLabels(records), changes, "milestones")
+	if !changes.HasChanges() {
+		return nil
+	}
+	changes.Stamp("id", strconv.FormatInt(goal.ID, 10))
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "update",
+		Category:    "saving_goal",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func milestoneLabels(milestones []models.SavingGoalMilestone) string {
+	labels := make([]string, len(milestones))
+	for i, m := range milestones {
+		labels[i] = utils.MilestoneLabel(m)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// goalProgress is what a contribution set off on its goal, reported once the
+// contribution is committed.
+type goalProgress struct {
+	milestone      *models.SavingGoalMilestone
+	completed      bool
+	prevStatus     models.SavingGoalStatus
+	prevAllocation *decimal.Decimal
+	redirectTo     *models.SavingGoal
+}
+
+// advanceGoal marks the milestones the goal passed on its way up from before and,
+// when the goal just reached its target, applies its completion action. goal must
+// already carry its new CurrentAmount.
+func (s *SavingsService) advanceGoal(ctx context.Context, tx *gorm.DB, goal *models.SavingGoal, before decimal.Decimal) (goalProgress, error) {
+	progress := goalProgress{prevStatus: goal.Status, prevAllocation: goal.MonthlyAllocation}
+	if !goal.CurrentAmount.GreaterThan(before) {
+		return progress, nil
+	}
+
+	milestones, err := s.repo.FindMilestones(ctx, tx, goal.ID)
+	if err != nil {
+		return progress, err
+	}
+	crossed := utils.CrossedMilestones(milestones, goal.TargetAmount, before, goal.CurrentAmount, goal.CycleNumber)
+	if len(crossed) > 0 {
+		ids := make([]int64, len(crossed))
+		for i, m := range crossed {
+			ids[i] = m.ID
+		}
+		if err := s.repo.MarkMilestonesReached(ctx, tx, ids, goal.CycleNumber, time.Now().UTC()); err != nil {
+			return progress, err
+		}
+		// Only the furthest one is worth telling the user about
+		progress.milestone = &crossed[len(crossed)-1]
+	}
+
+	if !goal.TargetAmount.IsPositive() || before.GreaterThanOrEqual(goal.TargetAmount) || goal.CurrentAmount.LessThan(goal.TargetAmount) {
+		return progress, nil
+	}
+	progress.completed = true
+
+	switch goal.CompletionAction {
+	case models.SavingGoalCompletionArchive:
+		goal.Status = models.SavingGoalStatusArchived
+	case models.SavingGoalCompletionRedirect:
+		goal.Status = models.SavingGoalStatusCompleted
+		if goal.MonthlyAllocation != nil && goal.MonthlyAllocation.IsPositive() {
+			next, err := s.nextGoalInLine(ctx, tx, *goal)
+			if err != nil {
+				return progress, err
+			}
+			if next != nil {
+				alloc := *goal.MonthlyAllocation
+				if next.MonthlyAllocation != nil {
+					alloc = alloc.Add(*next.MonthlyAllocation)
+				}
+				next.MonthlyAllocation = &alloc
+				if _, err := s.repo.UpdateGoal(ctx, tx, *next); err != nil {
+					return progress, err
+				}
+				goal.MonthlyAllocation = nil
+				progress.redirectTo = next
+			}
+		}
+	default:
+		return progress, nil
+	}
+
+	if _, err := s.repo.UpdateGoal(ctx, tx, *goal); err != nil {
+		return progress, err
+	}
+	return progress, nil
+}
+
+// nextGoalInLine picks the goal that takes over a completed goal's monthly
+// allocation: the next unfinished active goal in auto-funding order, wrapping
+// round to the first when the completed goal was last. Nil when there is none.
+func (s *SavingsService) nextGoalInLine(ctx context.Context, tx *gorm.DB, goal models.SavingGoal) (*models.SavingGoal, error) {
+	goals, err := s.repo.FindGoals(ctx, tx, goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]models.SavingGoal, len(goals))
+	order := []utils.GoalNeed{{GoalID: goal.ID, Priority: goal.Priority}}
+	for _, g := range goals {
+		if g.ID == goal.ID || g.Status != models.SavingGoalStatusActive || g.CurrentAmount.GreaterThanOrEqual(g.TargetAmount) {
+			continue
+		}
+		byID[g.ID] = g
+		order = append(order, utils.GoalNeed{GoalID: g.ID, Priority: g.Priority})
+	}
+	if len(byID) == 0 {
+		return nil, nil
+	}
+	utils.SortGoalNeedsByPriority(order)
+
+	pos := 0
+	for i, n := range order {
+		if n.GoalID == goal.ID {
+			pos = i
+		}
+	}
+	next := order[(pos+1)%len(order)]
+	picked := byID[next.GoalID]
+	return &picked, nil
+}
+
+// reportGoalProgress tells the user what their contribution achieved and logs a
+// completion action that changed the goal. causer is nil for automatic funding.
+func (s *SavingsService) reportGoalProgress(ctx context.Context, goal models.SavingGoal, progress goalProgress, causer *int64) {
+	payload := ws.SavingGoalPayload{GoalID: goal.ID}
+
+	if progress.completed {
+		if goal.Status != progress.prevStatus {
+			changes := utils.InitChanges()
+			utils.CompareChanges(string(progress.prevStatus), string(goal.Status), changes, "status")
+			utils.CompareDecimalChange(progress.prevAllocation, goal.MonthlyAllocation, changes, "monthly_allocation", 2)
+			if progress.redirectTo != nil {
+				utils.CompareChanges("", progress.redirectTo.Name, changes, "allocation_redirected_to")
+			}
+			changes.Stamp("id", strconv.FormatInt(goal.ID, 10))
+			_ = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+				LoggingRepo: s.loggingRepo,
+				Event:       "update",
+				Category:    "saving_goal",
+				Description: nil,
+				Payload:     changes,
+				Causer:      causer,
+			})
+		}
+
+		if s.notifDispatcher == nil {
+			return
+		}
+		title := fmt.Sprintf("%s reached its target", goal.Name)
+		msg := fmt.Sprintf("You've saved %s of %s.", goal.CurrentAmount.StringFixed(2), goal.TargetAmount.StringFixed(2))
+		switch {
+		case goal.Status == models.SavingGoalStatusArchived:
+			msg += " The goal has been archived."
+		case progress.redirectTo != nil:
+			msg += fmt.Sprintf(" Its monthly allocation now goes to %s.", progress.redirectTo.Name)
+		}
+		_ = s.notifDispatcher.DispatchWithEvent(ctx, goal.UserID, title, msg, models.NotificationTypeSuccess,
+			ws.Event{Type: ws.TypeSavingGoalCompleted, Payload: payload})
+		return
+	}
+
+	if progress.milestone == nil || s.notifDispatcher == nil {
+		return
+	}
+	payload.MilestoneID = progress.milestone.ID
+	title := fmt.Sprintf("%s passed %s", goal.Name, utils.MilestoneLabel(*progress.milestone))
+	msg := fmt.Sprintf("%s has %s of %s saved.", goal.Name, goal.CurrentAmount.StringFixed(2), goal.TargetAmount.StringFixed(2))
+	_ = s.notifDispatcher.DispatchWithEvent(ctx, goal.UserID, title, msg, models.NotificationTypeSuccess,
+		ws.Event{Type: ws.TypeSavingGoalMilestone, Payload: payload})
+}
+
+// MarkBehindGoals returns active goals that fell behind schedule since they were
+// last checked, stamping them so each slip is reported once. Goals back on track
+// are cleared, so falling behind again alerts again.
+func (s *SavingsService) MarkBehindGoals(ctx context.Context) ([]models.SavingGoalWithProgress, error) {
+	goals, err := s.repo.FindActiveGoalsWithTargetDate(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var behind []models.SavingGoalWithProgress
+	var lateIDs, recoveredIDs []int64
+	for _, g := range goals {
+		wp := computeProgress(g)
+		switch {
+		case wp.TrackStatus == "late" && g.LateAlertedAt == nil:
+			behind = append(behind, wp)
+			lateIDs = append(lateIDs, g.ID)
+		case wp.TrackStatus != "late" && g.LateAlertedAt != nil:
+			recoveredIDs = append(recoveredIDs, g.ID)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.SetLateAlertedAt(ctx, nil, lateIDs, &now); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetLateAlertedAt(ctx, nil, recoveredIDs, nil); err != nil {
+		return nil, err
+	}
+
+	return behind, nil
+}
+
 func (s *SavingsService) FetchActiveGoalsWithAllocation(ctx context.Context, dayOfMonth int) ([]models.SavingGoal, error) {
 	return s.repo.FindActiveGoalsWithAllocation(ctx, nil, dayOfMonth)
 }
 
 func (s *SavingsService) AutoFundGoal(ctx context.Context, goal models.SavingGoal, month time.Time) (bool, string, error) {
+	return s.autoFund(ctx, goal, *goal.MonthlyAllocation, month)
+}
+
+// autoFund books one automatic contribution of amount for the month, unless
+// the goal was already funded or the money isn't there.
+func (s *SavingsService) autoFund(ctx context.Context, goal models.SavingGoal, amount decimal.Decimal, month time.Time) (bool, string, error) {
 	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
 
 	tx, err := s.repo.BeginTx(ctx)
@@ -540,22 +1212,40 @@ func (s *SavingsService) AutoFundGoal(ctx context.Context, goal models.SavingGoa
 		return false, "already_funded", nil
 	}
 
-	uncategorized, err := s.repo.GetUncategorizedBalance(ctx, tx, goal.AccountID, goal.UserID)
+	// Transfer-backed goals draw the allocation from the funding account instead
+	sourceID := goal.AccountID
+	if goal.FundingAccountID != nil {
+		sourceID = *goal.FundingAccountID
+	}
+
+	uncategorized, err := s.repo.GetUncategorizedBalance(ctx, tx, sourceID, goal.UserID)
 	if err != nil {
 		tx.Rollback()
 		return false, "", fmt.Errorf("failed to compute available balance: %w", err)
 	}
-	if uncategorized.LessThan(*goal.MonthlyAllocation) {
+	if uncategorized.LessThan(amount) {
 		tx.Rollback()
 		return false, "insufficient_balance", nil
 	}
 
+	var transferID *int64
+	var transferLog *queue_jobs.ActivityLogJob
+	if goal.FundingAccountID != nil {
+		transferID, transferLog, err = s.moveGoalFunds(ctx, tx, goal, amount, nil)
+		if err != nil {
+			tx.Rollback()
+			return false, "", err
+		}
+	}
+
 	record := models.SavingContribution{
-		UserID: goal.UserID,
-		GoalID: goal.ID,
-		Amount: *goal.MonthlyAllocation,
-		Month:  monthStart,
-		Source: models.SavingContributionSourceAuto,
+		UserID:      goal.UserID,
+		GoalID:      goal.ID,
+		Amount:      amount,
+		Month:       monthStart,
+		Source:      models.SavingContributionSourceAuto,
+		TransferID:  transferID,
+		CycleNumber: goal.CycleNumber,
 	}
 	cID, err := s.repo.InsertContribution(ctx, tx, &record)
 	if err != nil {
@@ -563,20 +1253,31 @@ func (s *SavingsService) AutoFundGoal(ctx context.Context, goal models.SavingGoa
 		return false, "", err
 	}
 
-	goal.CurrentAmount = goal.CurrentAmount.Add(*goal.MonthlyAllocation)
+	before := goal.CurrentAmount
+	goal.CurrentAmount = goal.CurrentAmount.Add(amount)
 	if err := s.repo.UpdateCurrentAmount(ctx, tx, goal.ID, goal); err != nil {
 		tx.Rollback()
 		return false, "", err
 	}
 
+	progress, err := s.advanceGoal(ctx, tx, &goal, before)
+	if err != nil {
+		tx.Rollback()
+		return false, "", err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return false, "", err
 	}
 
+	if transferLog != nil {
+		_ = s.jobDispatcher.Dispatch(ctx, transferLog)
+	}
+
 	changes := utils.InitChanges()
 	utils.CompareChanges("", strconv.FormatInt(cID, 10), changes, "id")
 	utils.CompareChanges("", goal.Name, changes, "goal")
-	utils.CompareDecimalChange(nil, goal.MonthlyAllocation, changes, "amount", 2)
+	utils.CompareDecimalChange(nil, &amount, changes, "amount", 2)
 	utils.CompareChanges("", monthStart.Format("2006-01-02"), changes, "month")
 	_ = s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
 		LoggingRepo: s.loggingRepo,
@@ -585,9 +1286,415 @@ func (s *SavingsService) AutoFundGoal(ctx context.Context, goal models.SavingGoa
 		Payload:     changes,
 	})
 
+	s.reportGoalProgress(ctx, goal, progress, nil)
+
 	return true, "", nil
 }
 
+func (s *SavingsService) FetchReconciliation(ctx context.Context, userID int64) ([]models.SavingsReconciliation, error) {
+	goals, err := s.repo.FindGoals(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	backed, err := s.repo.SumTransferBackedContributions(ctx, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[int64]*models.SavingsReconciliation)
+	order := make([]int64, 0)
+	for _, g := range goals {
+		rec, ok := byAccount[g.AccountID]
+		if !ok {
+			acc, err := s.accountRepo.FindAccountByID(ctx, nil, g.AccountID, userID, true, true)
+			if err != nil {
+				return nil, fmt.Errorf("can't find account for goal %s: %w", g.Name, err)
+			}
+			rec = &models.SavingsReconciliation{
+				AccountID:   acc.ID,
+				AccountName: acc.Name,
+				Currency:    acc.Currency,
+				Balance:     acc.Balance.EndBalance,
+				Goals:       make([]models.SavingGoalReconciliation, 0),
+			}
+			byAccount[g.AccountID] = rec
+			order = append(order, g.AccountID)
+		}
+
+		transferBacked := backed[g.ID]
+		rec.Allocated = rec.Allocated.Add(g.CurrentAmount)
+		rec.Goals = append(rec.Goals, models.SavingGoalReconciliation{
+			GoalID:           g.ID,
+			Name:             g.Name,
+			CurrentAmount:    g.CurrentAmount,
+			TransferBacked:   transferBacked,
+			Unbacked:         g.CurrentAmount.Sub(transferBacked),
+			FundingAccountID: g.FundingAccountID,
+		})
+	}
+
+	result := make([]models.SavingsReconciliation, 0, len(order))
+	for _, accountID := range order {
+		rec := byAccount[accountID]
+		diff := rec.Balance.Sub(rec.Allocated)
+		if diff.IsNegative() {
+			rec.Shortfall = diff.Neg()
+		} else {
+			rec.Uncategorized = diff
+		}
+		result = append(result, *rec)
+	}
+
+	return result, nil
+}
+
+func (s *SavingsService) FetchBudget(ctx context.Context, userID int64) (*models.SavingsBudget, error) {
+	record, err := s.repo.FindBudget(ctx, nil, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *SavingsService) SaveBudget(ctx context.Context, userID int64, req *models.SavingsBudgetReq) (int64, error) {
+	switch req.Mode {
+	case models.SavingsBudgetModeFixed:
+		if req.Amount == nil || !req.Amount.IsPositive() {
+			return 0, fmt.Errorf("a fixed savings budget needs a positive amount")
+		}
+	case models.SavingsBudgetModeIncomePercent:
+		if req.IncomePercent == nil || !req.IncomePercent.IsPositive() || req.IncomePercent.GreaterThan(decimal.NewFromInt(100)) {
+			return 0, fmt.Errorf("income percent must be between 0 and 100")
+		}
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	existing, err := s.repo.FindBudget(ctx, tx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return 0, err
+	}
+
+	record := models.SavingsBudget{
+		ID:             existing.ID,
+		UserID:         userID,
+		Mode:           req.Mode,
+		Strategy:       req.Strategy,
+		FundDayOfMonth: req.FundDayOfMonth,
+		IsActive:       req.IsActive,
+	}
+	if req.Mode == models.SavingsBudgetModeFixed {
+		record.Amount = req.Amount
+	} else {
+		record.IncomePercent = req.IncomePercent
+	}
+
+	id, err := s.repo.UpsertBudget(ctx, tx, &record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	event := "update"
+	if existing.ID == 0 {
+		event = "create"
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(string(existing.Mode), string(record.Mode), changes, "mode")
+	utils.CompareDecimalChange(existing.Amount, record.Amount, changes, "amount", 2)
+	utils.CompareDecimalChange(existing.IncomePercent, record.IncomePercent, changes, "income_percent", 2)
+	utils.CompareChanges(string(existing.Strategy), string(record.Strategy), changes, "strategy")
+	utils.CompareChanges(strconv.FormatBool(existing.IsActive), strconv.FormatBool(record.IsActive), changes, "is_active")
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(id, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       event,
+			Category:    "savings_budget",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+func (s *SavingsService) DeleteBudget(ctx context.Context, userID int64) error {
+	existing, err := s.repo.FindBudget(ctx, nil, userID)
+	if err != nil {
+		return fmt.Errorf("savings budget not found: %w", err)
+	}
+
+	if err := s.repo.DeleteBudget(ctx, nil, userID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(existing.ID, 10), "", changes, "id")
+	utils.CompareChanges(string(existing.Mode), "", changes, "mode")
+	utils.CompareChanges(string(existing.Strategy), "", changes, "strategy")
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "savings_budget",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+func (s *SavingsService) PlanBudget(ctx context.Context, userID int64, month time.Time) (*models.SavingsBudgetPlan, error) {
+	budget, err := s.repo.FindBudget(ctx, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("savings budget not found: %w", err)
+	}
+
+	plan, _, err := s.buildBudgetPlan(ctx, budget, month)
+	return plan, err
+}
+
+func (s *SavingsService) FetchDueBudgets(ctx context.Context, dayOfMonth int) ([]models.SavingsBudget, error) {
+	return s.repo.FindDueBudgets(ctx, nil, dayOfMonth)
+}
+
+// FundFromBudget splits what is left of the month's budget across the user's
+// goals and books an automatic contribution for each share. It is safe to run
+// again later in the month: goals funded earlier are skipped and their
+// amounts come off the budget.
+func (s *SavingsService) FundFromBudget(ctx context.Context, budget models.SavingsBudget, month time.Time) ([]models.SavingGoalFundResult, error) {
+	plan, goals, err := s.buildBudgetPlan(ctx, budget, month)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SavingGoalFundResult, 0, len(plan.Allocations))
+	for _, a := range plan.Allocations {
+		goal := goals[a.GoalID]
+		funded, reason, err := s.autoFund(ctx, goal, a.Amount, plan.Month)
+		results = append(results, models.SavingGoalFundResult{
+			Goal:       goal,
+			Amount:     a.Amount,
+			Funded:     funded,
+			SkipReason: reason,
+			Err:        err,
+		})
+	}
+
+	return results, nil
+}
+
+// buildBudgetPlan works out the budget still to distribute this month and how
+// the budget's strategy splits it across active goals not yet funded.
+func (s *SavingsService) buildBudgetPlan(ctx context.Context, budget models.SavingsBudget, month time.Time) (*models.SavingsBudgetPlan, map[int64]models.SavingGoal, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	income, err := s.repo.SumIncomeForMonth(ctx, nil, budget.UserID, monthStart.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, nil, err
+	}
+	amount := utils.SavingsBudgetAmount(budget, income)
+
+	funded, err := s.repo.FindAutoContributionsForMonth(ctx, nil, budget.UserID, monthStart)
+	if err != nil {
+		return nil, nil, err
+	}
+	fundedGoals := make(map[int64]bool, len(funded))
+	for _, c := range funded {
+		fundedGoals[c.GoalID] = true
+		amount = amount.Sub(c.Amount)
+	}
+	if amount.IsNegative() {
+		amount = decimal.Zero
+	}
+
+	all, err := s.repo.FindGoals(ctx, nil, budget.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	goals := make(map[int64]models.SavingGoal)
+	needs := make([]utils.GoalNeed, 0, len(all))
+	for _, g := range all {
+		if g.Status != models.SavingGoalStatusActive || fundedGoals[g.ID] {
+			continue
+		}
+		wp := computeProgress(g)
+		need := utils.GoalNeed{
+			GoalID:     g.ID,
+			Priority:   g.Priority,
+			Remaining:  g.TargetAmount.Sub(g.CurrentAmount),
+			Behind:     wp.TrackStatus == "late",
+			TargetDate: g.TargetDate,
+		}
+		if wp.MonthlyNeeded != nil {
+			need.Monthly = *wp.MonthlyNeeded
+		}
+		goals[g.ID] = g
+		needs = append(needs, need)
+	}
+
+	utils.SortGoalNeedsByPriority(needs)
+	shares := utils.DistributeSavingsBudget(amount, needs, budget.Strategy)
+
+	plan := &models.SavingsBudgetPlan{
+		Month:       monthStart,
+		Budget:      amount,
+		Strategy:    budget.Strategy,
+		Unallocated: amount,
+		Allocations: make([]models.SavingsBudgetAllocation, 0, len(shares)),
+	}
+	// goals are funded in priority order, so a short account hits the least important ones
+	for _, n := range needs {
+		share, ok := shares[n.GoalID]
+		if !ok {
+			continue
+		}
+		plan.Allocations = append(plan.Allocations, models.SavingsBudgetAllocation{
+			GoalID: n.GoalID,
+			Name:   goals[n.GoalID].Name,
+			Amount: share,
+		})
+		plan.Unallocated = plan.Unallocated.Sub(share)
+	}
+
+	return plan, goals, nil
+}
+
+// moveGoalFunds books a transfer between the goal's funding account and the
+// goal's account. Positive amounts fund the goal, negative ones send money back.
+// The transfer runs inside tx, so its activity log entry is returned for the
+// caller to dispatch once tx commits.
+func (s *SavingsService) moveGoalFunds(ctx context.Context, tx *gorm.DB, goal models.SavingGoal, amount decimal.Decimal, causer *int64) (*int64, *queue_jobs.ActivityLogJob, error) {
+	sourceID, destinationID := *goal.FundingAccountID, goal.AccountID
+	if amount.IsNegative() {
+		sourceID, destinationID = destinationID, sourceID
+	}
+
+	notes := fmt.Sprintf("Savings goal: %s", goal.Name)
+	res, err := s.txnService.InsertTransfer(ctx, goal.UserID, &models.TransferReq{
+		SourceID:      sourceID,
+		DestinationID: destinationID,
+		Amount:        amount.Abs(),
+		Notes:         &notes,
+	}, tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to transfer goal funds: %w", err)
+	}
+
+	transfer, fromName, toName, err := s.loadGoalTransfer(ctx, tx, goal.UserID, res.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res.ID, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "transfer",
+		Description: &notes,
+		Payload:     transferCreatedChanges(transfer, fromName, toName),
+		Causer:      causer,
+	}, nil
+}
+
+// loadGoalTransfer fetches a goal's transfer and the names of the accounts it
+// moves money between, for the activity log.
+func (s *SavingsService) loadGoalTransfer(ctx context.Context, tx *gorm.DB, userID, transferID int64) (models.Transfer, string, string, error) {
+	transfer, err := s.repo.FindContributionTransfer(ctx, tx, transferID, userID)
+	if err != nil {
+		return models.Transfer{}, "", "", fmt.Errorf("can't find goal transfer %w", err)
+	}
+
+	fromAcc, err := s.accountRepo.FindAccountByID(ctx, tx, transfer.TransactionOutflow.AccountID, userID, true)
+	if err != nil {
+		return models.Transfer{}, "", "", fmt.Errorf("can't find source account %w", err)
+	}
+	toAcc, err := s.accountRepo.FindAccountByID(ctx, tx, transfer.TransactionInflow.AccountID, userID, true)
+	if err != nil {
+		return models.Transfer{}, "", "", fmt.Errorf("can't find destination account %w", err)
+	}
+
+	return transfer, fromAcc.Name, toAcc.Name, nil
+}
+
+func (s *SavingsService) validateFundingAccount(ctx context.Context, tx *gorm.DB, userID, goalAccountID int64, fundingAccountID *int64) error {
+	if fundingAccountID == nil {
+		return nil
+	}
+	if *fundingAccountID == goalAccountID {
+		return fmt.Errorf("funding account must differ from the goal's account")
+	}
+	if _, err := s.accountRepo.FindAccountByID(ctx, tx, *fundingAccountID, userID, false); err != nil {
+		return fmt.Errorf("funding account not found: %w", err)
+	}
+	return nil
+}
+
+// nextDueDate moves a recurring goal's due date on by at least one cycle, and
+// further if the bill was paid so late that the next date has passed too.
+func nextDueDate(due time.Time, recurrence models.SavingGoalRecurrence, paidAt time.Time) time.Time {
+	months := 12
+	if recurrence == models.SavingGoalRecurrenceQuarterly {
+		months = 3
+	}
+
+	step := 1
+	next := due.AddDate(0, months, 0)
+	for !next.After(paidAt) {
+		step++
+		next = due.AddDate(0, months*step, 0)
+	}
+	return next
+}
+
+func recurrenceLabel(r *models.SavingGoalRecurrence) string {
+	if r == nil {
+		return ""
+	}
+	return string(*r)
+}
+
+// resolveCompletionAction defaults an unset action to notify. Recurring goals
+// refill every cycle, so they can only notify when they reach their target.
+func resolveCompletionAction(action models.SavingGoalCompletionAction, recurrence *models.SavingGoalRecurrence) (models.SavingGoalCompletionAction, error) {
+	if action == "" {
+		return models.SavingGoalCompletionNotify, nil
+	}
+	if recurrence != nil && action != models.SavingGoalCompletionNotify {
+		return "", fmt.Errorf("recurring goals can only notify on completion")
+	}
+	return action, nil
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
 func computeProgress(g models.SavingGoal) models.SavingGoalWithProgress {
 	wp := models.SavingGoalWithProgress{SavingGoal: g}
 
@@ -625,9 +1732,14 @@ func computeProgress(g models.SavingGoal) models.SavingGoalWithProgress {
 		wp.MonthlyNeeded = &mn
 	}
 
-	// expected progress based on time elapsed from creation
-	createdDays := now.Sub(g.CreatedAt).Hours() / 24
-	totalSpan := g.TargetDate.Sub(g.CreatedAt).Hours() / 24
+	// expected progress based on time elapsed from creation, or from the
+	// start of the current cycle for recurring goals
+	start := g.CreatedAt
+	if g.CycleStartedAt != nil {
+		start = *g.CycleStartedAt
+	}
+	createdDays := now.Sub(start).Hours() / 24
+	totalSpan := g.TargetDate.Sub(start).Hours() / 24
 	if totalSpan <= 0 {
 		wp.TrackStatus = "no_target"
 		return wp
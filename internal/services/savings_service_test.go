@@ -1,10 +1,19 @@
 package services_test
 
 import (
+	"context"
+	"strconv"
 	"testing"
 	"time"
 	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/internal/services"
 	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"gorm.io/gorm"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/suite"
@@ -389,3 +398,435 @@ func (s *SavingsServiceTestSuite) TestInsertGoal_InitialAmountRespectsSiblingAll
 	})
 	s.Require().NoError(err)
 }
+
+// Contributions to a goal with a funding account move real money, and deleting
+// either side of the pair removes the other.
+func (s *SavingsServiceTestSuite) TestInsertContribution_TransferBackedGoal() {
+	svc := s.TC.App.SavingsService
+	accSvc := s.TC.App.AccountService
+	txnSvc := s.TC.App.TransactionService
+	userID := int64(1)
+
+	checkingBalance := decimal.NewFromInt(1000)
+	checkingID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Checking",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &checkingBalance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	savingsBalance := decimal.Zero
+	savingsID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Savings",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &savingsBalance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	goalID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:        savingsID,
+		Name:             "Holiday",
+		TargetAmount:     decimal.NewFromInt(1000),
+		FundingAccountID: &checkingID,
+	})
+	s.Require().NoError(err)
+
+	month := time.Now().UTC().Format("2006-01") + "-01"
+	contribID, err := svc.InsertContribution(s.Ctx, userID, goalID, &models.SavingContributionReq{
+		Amount: decimal.NewFromInt(300),
+		Month:  month,
+	})
+	s.Require().NoError(err)
+
+	var contrib models.SavingContribution
+	s.Require().NoError(s.TC.DB.First(&contrib, contribID).Error)
+	s.Require().NotNil(contrib.TransferID)
+
+	balanceOf := func(accountID int64) decimal.Decimal {
+		acc, err := accSvc.FetchAccountByID(s.Ctx, userID, accountID, false)
+		s.Require().NoError(err)
+		return acc.Balance.EndBalance
+	}
+	s.True(balanceOf(checkingID).Equal(decimal.NewFromInt(700)))
+	s.True(balanceOf(savingsID).Equal(decimal.NewFromInt(300)))
+
+	recs, err := svc.FetchReconciliation(s.Ctx, userID)
+	s.Require().NoError(err)
+	s.Require().Len(recs, 1)
+	s.True(recs[0].Balance.Equal(decimal.NewFromInt(300)))
+	s.True(recs[0].Allocated.Equal(decimal.NewFromInt(300)))
+	s.True(recs[0].Shortfall.IsZero())
+	s.True(recs[0].Goals[0].TransferBacked.Equal(decimal.NewFromInt(300)))
+
+	// Deleting the transfer from the ledger takes the contribution with it
+	s.Require().NoError(txnSvc.DeleteTransfer(s.Ctx, userID, *contrib.TransferID))
+
+	var count int64
+	s.Require().NoError(s.TC.DB.Model(&models.SavingContribution{}).Where("goal_id = ?", goalID).Count(&count).Error)
+	s.Equal(int64(0), count)
+
+	goal, err := svc.FetchGoalByID(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+	s.True(goal.CurrentAmount.IsZero())
+	s.True(balanceOf(checkingID).Equal(decimal.NewFromInt(1000)))
+
+	// Deleting the contribution reverses its transfer
+	contribID, err = svc.InsertContribution(s.Ctx, userID, goalID, &models.SavingContributionReq{
+		Amount: decimal.NewFromInt(200),
+		Month:  month,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(svc.DeleteContribution(s.Ctx, userID, goalID, contribID))
+
+	s.True(balanceOf(checkingID).Equal(decimal.NewFromInt(1000)))
+	s.True(balanceOf(savingsID).IsZero())
+}
+
+// transferLogRecorder notes, for every transfer log it is handed, whether the
+// transfer was already committed as the log describes it.
+type transferLogRecorder struct {
+	db      *gorm.DB
+	entries []string
+}
+
+func (r *transferLogRecorder) Dispatch(ctx context.Context, job queue.Job) error {
+	logJob, ok := job.(*queue_jobs.ActivityLogJob)
+	if !ok || logJob.Category != "transfer" {
+		return nil
+	}
+
+	raw := logJob.Payload.New["id"]
+	if logJob.Event == "delete" {
+		raw = logJob.Payload.Old["id"]
+	}
+	id, _ := strconv.ParseInt(raw, 10, 64)
+
+	var count int64
+	q := r.db.WithContext(ctx).Model(&models.Transfer{}).Where("id = ?", id)
+	if logJob.Event == "delete" {
+		q = q.Where("deleted_at IS NOT NULL")
+	} else {
+		q = q.Where("deleted_at IS NULL")
+	}
+	if err := q.Count(&count).Error; err != nil {
+		return err
+	}
+	r.entries = append(r.entries, logJob.Event+":"+strconv.FormatBool(count == 1))
+	return nil
+}
+
+// Goal transfers are logged by the savings service once its transaction has
+// committed, not by the transaction service while it is still open
+func (s *SavingsServiceTestSuite) TestContribution_LogsTransferAfterCommit() {
+	db := s.TC.DB
+	recorder := &transferLogRecorder{db: db}
+	accountRepo := repositories.NewAccountRepository(db)
+	savingsRepo := repositories.NewSavingsRepository(db)
+	loggingRepo := repositories.NewLoggingRepository(db)
+	txnSvc := services.NewTransactionService(
+		repositories.NewTransactionRepository(db),
+		accountRepo,
+		repositories.NewSettingsRepository(db),
+		loggingRepo,
+		savingsRepo,
+		repositories.NewHouseholdRepository(db),
+		recorder,
+	)
+	svc := services.NewSavingsService(savingsRepo, accountRepo, loggingRepo, txnSvc, recorder, s.TC.App.NotifDispatcher)
+
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	checkingBalance := decimal.NewFromInt(1000)
+	checkingID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Checking",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &checkingBalance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	savingsBalance := decimal.Zero
+	savingsID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Savings",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &savingsBalance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	goalID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:        savingsID,
+		Name:             "Holiday",
+		TargetAmount:     decimal.NewFromInt(1000),
+		FundingAccountID: &checkingID,
+	})
+	s.Require().NoError(err)
+
+	contribID, err := svc.InsertContribution(s.Ctx, userID, goalID, &models.SavingContributionReq{
+		Amount: decimal.NewFromInt(300),
+		Month:  time.Now().UTC().Format("2006-01") + "-01",
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(svc.DeleteContribution(s.Ctx, userID, goalID, contribID))
+
+	s.Equal([]string{"create:true", "delete:true"}, recorder.entries)
+}
+
+// A priority budget fills the higher-priority goal first, and a second run in
+// the same month only spends what is left of the budget.
+func (s *SavingsServiceTestSuite) TestFundFromBudget_PriorityWaterfall() {
+	svc := s.TC.App.SavingsService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	balance := decimal.NewFromInt(2000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Test Savings",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &balance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	urgentID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:    accID,
+		Name:         "Urgent",
+		TargetAmount: decimal.NewFromInt(150),
+		Priority:     5,
+	})
+	s.Require().NoError(err)
+
+	laterID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:    accID,
+		Name:         "Later",
+		TargetAmount: decimal.NewFromInt(1000),
+		Priority:     1,
+	})
+	s.Require().NoError(err)
+
+	amount := decimal.NewFromInt(400)
+	_, err = svc.SaveBudget(s.Ctx, userID, &models.SavingsBudgetReq{
+		Mode:     models.SavingsBudgetModeFixed,
+		Amount:   &amount,
+		Strategy: models.SavingsBudgetStrategyPriority,
+		IsActive: true,
+	})
+	s.Require().NoError(err)
+
+	budget, err := svc.FetchBudget(s.Ctx, userID)
+	s.Require().NoError(err)
+	s.Require().NotNil(budget)
+
+	month := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	results, err := svc.FundFromBudget(s.Ctx, *budget, month)
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	for _, r := range results {
+		s.Require().NoError(r.Err)
+		s.True(r.Funded)
+	}
+
+	urgent, err := svc.FetchGoalByID(s.Ctx, userID, urgentID)
+	s.Require().NoError(err)
+	s.True(urgent.CurrentAmount.Equal(decimal.NewFromInt(150)))
+
+	later, err := svc.FetchGoalByID(s.Ctx, userID, laterID)
+	s.Require().NoError(err)
+	s.True(later.CurrentAmount.Equal(decimal.NewFromInt(250)))
+
+	// The month's budget is spent, so a rerun funds nothing
+	results, err = svc.FundFromBudget(s.Ctx, *budget, month)
+	s.Require().NoError(err)
+	s.Empty(results)
+
+	// Goals of users with a budget are no longer funded from their own allocation
+	goals, err := svc.FetchActiveGoalsWithAllocation(s.Ctx, 31)
+	s.Require().NoError(err)
+	for _, g := range goals {
+		s.NotEqual(userID, g.UserID)
+	}
+}
+
+// Paying a recurring goal's bill draws it down, records the cycle and rolls
+// the due date forward, with contributions kept apart per cycle.
+func (s *SavingsServiceTestSuite) TestPayRecurringGoal_StartsNextCycle() {
+	svc := s.TC.App.SavingsService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	balance := decimal.NewFromInt(1000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Test Savings",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &balance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	due := time.Now().UTC().AddDate(0, 2, 0).Format("2006-01-02")
+	yearly := models.SavingGoalRecurrenceYearly
+	initial := decimal.NewFromInt(700)
+	goalID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:     accID,
+		Name:          "Car insurance",
+		TargetAmount:  decimal.NewFromInt(600),
+		InitialAmount: &initial,
+		TargetDate:    &due,
+		Recurrence:    &yearly,
+	})
+	s.Require().NoError(err)
+
+	before, err := svc.FetchGoalByID(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+
+	// The whole balance but 300 is allocated, yet the bill can be paid from the goal
+	_, err = svc.PayRecurringGoal(s.Ctx, userID, goalID, &models.SavingGoalPayReq{
+		Amount:  decimal.NewFromInt(640),
+		TxnDate: time.Now().UTC(),
+	})
+	s.Require().NoError(err)
+
+	after, err := svc.FetchGoalByID(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+	s.Equal(2, after.CycleNumber)
+	s.True(after.CurrentAmount.Equal(decimal.NewFromInt(60)), after.CurrentAmount.String())
+	s.Require().NotNil(after.TargetDate)
+	s.Equal(before.TargetDate.AddDate(1, 0, 0).Format("2006-01-02"), after.TargetDate.Format("2006-01-02"))
+	s.Require().NotNil(after.MonthlyNeeded)
+
+	cycles, err := svc.FetchCycles(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+	s.Require().Len(cycles, 1)
+	s.True(cycles[0].Saved.Equal(decimal.NewFromInt(700)))
+	s.True(cycles[0].Spent.Equal(decimal.NewFromInt(640)))
+	s.True(cycles[0].CarriedOver.Equal(decimal.NewFromInt(60)))
+
+	// The expense that closed the cycle can't be removed or re-priced under it
+	s.Require().NotNil(cycles[0].TransactionID)
+	txnSvc := s.TC.App.TransactionService
+	s.Error(txnSvc.DeleteTransaction(s.Ctx, userID, *cycles[0].TransactionID))
+
+	txn, err := txnSvc.FetchTransactionByID(s.Ctx, userID, *cycles[0].TransactionID, false)
+	s.Require().NoError(err)
+	_, err = txnSvc.UpdateTransaction(s.Ctx, userID, txn.ID, &models.TransactionReq{
+		AccountID:       txn.AccountID,
+		CategoryID:      txn.CategoryID,
+		TransactionType: txn.TransactionType,
+		Amount:          decimal.NewFromInt(500),
+		TxnDate:         txn.TxnDate,
+		Description:     txn.Description,
+	})
+	s.Error(err)
+
+	first := 1
+	_, paginator, err := svc.FetchContributionsPaginated(s.Ctx, userID, goalID, &first, utils.PaginationParams{PageNumber: 1, RowsPerPage: 10})
+	s.Require().NoError(err)
+	s.Equal(2, paginator.TotalRecords, "initial amount and settlement")
+
+	second := 2
+	_, paginator, err = svc.FetchContributionsPaginated(s.Ctx, userID, goalID, &second, utils.PaginationParams{PageNumber: 1, RowsPerPage: 10})
+	s.Require().NoError(err)
+	s.Equal(0, paginator.TotalRecords)
+}
+
+// Contributions mark the milestones they pass, and reaching the target hands the
+// monthly allocation to the next goal in line.
+func (s *SavingsServiceTestSuite) TestInsertContribution_MilestonesAndRedirect() {
+	svc := s.TC.App.SavingsService
+	accSvc := s.TC.App.AccountService
+	userID := int64(1)
+
+	balance := decimal.NewFromInt(2000)
+	accID, err := accSvc.InsertAccount(s.Ctx, userID, &models.AccountReq{
+		Name:           "Test Savings",
+		AccountTypeID:  2,
+		Type:           "cash",
+		Subtype:        "savings",
+		Classification: "asset",
+		Balance:        &balance,
+		OpenedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	alloc := decimal.NewFromInt(100)
+	goalID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:         accID,
+		Name:              "Laptop",
+		TargetAmount:      decimal.NewFromInt(1000),
+		Priority:          5,
+		MonthlyAllocation: &alloc,
+		CompletionAction:  models.SavingGoalCompletionRedirect,
+	})
+	s.Require().NoError(err)
+
+	nextAlloc := decimal.NewFromInt(50)
+	nextID, err := svc.InsertGoal(s.Ctx, userID, &models.SavingGoalReq{
+		AccountID:         accID,
+		Name:              "Bike",
+		TargetAmount:      decimal.NewFromInt(800),
+		Priority:          3,
+		MonthlyAllocation: &nextAlloc,
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(svc.SaveMilestones(s.Ctx, userID, goalID, &models.SavingGoalMilestonesReq{
+		Milestones: []models.SavingGoalMilestoneReq{
+			{Kind: models.SavingGoalMilestonePercent, Value: decimal.NewFromInt(25)},
+			{Kind: models.SavingGoalMilestonePercent, Value: decimal.NewFromInt(50)},
+			{Kind: models.SavingGoalMilestoneAmount, Value: decimal.NewFromInt(900)},
+		},
+	}))
+
+	_, err = svc.InsertContribution(s.Ctx, userID, goalID, &models.SavingContributionReq{
+		Amount: decimal.NewFromInt(600),
+		Month:  time.Now().UTC().Format("2006-01-02"),
+	})
+	s.Require().NoError(err)
+
+	milestones, err := svc.FetchMilestones(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+	reached := 0
+	for _, m := range milestones {
+		if m.ReachedAt != nil {
+			reached++
+		}
+	}
+	s.Equal(2, reached, "25% and 50% passed, 900 not yet")
+
+	_, err = svc.InsertContribution(s.Ctx, userID, goalID, &models.SavingContributionReq{
+		Amount: decimal.NewFromInt(400),
+		Month:  time.Now().UTC().Format("2006-01-02"),
+	})
+	s.Require().NoError(err)
+
+	goal, err := svc.FetchGoalByID(s.Ctx, userID, goalID)
+	s.Require().NoError(err)
+	s.Equal(models.SavingGoalStatusCompleted, goal.Status)
+	s.Nil(goal.MonthlyAllocation)
+
+	next, err := svc.FetchGoalByID(s.Ctx, userID, nextID)
+	s.Require().NoError(err)
+	s.Require().NotNil(next.MonthlyAllocation)
+	s.True(next.MonthlyAllocation.Equal(decimal.NewFromInt(150)), next.MonthlyAllocation.String())
+}
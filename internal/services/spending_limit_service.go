@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/queue"
+	"wealth-warden/internal/queue/queue_jobs"
+	"wealth-warden/internal/repositories"
+	"wealth-warden/internal/ws"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+type SpendingLimitServiceInterface interface {
+	FetchLimits(ctx context.Context, userID int64) ([]models.SpendingLimitStatus, error)
+	InsertLimit(ctx context.Context, userID int64, req *models.SpendingLimitReq) (int64, error)
+	UpdateLimit(ctx context.Context, userID, id int64, req *models.SpendingLimitReq) (int64, error)
+	DeleteLimit(ctx context.Context, userID, id int64) error
+
+	CheckLimits(ctx context.Context, userID int64, months []time.Time) error
+	CollectDigests(ctx context.Context) ([]models.SpendingLimitDigest, error)
+}
+
+type SpendingLimitService struct {
+	repo            repositories.SpendingLimitRepositoryInterface
+	txnRepo         repositories.TransactionRepositoryInterface
+	analyticsRepo   repositories.AnalyticsRepositoryInterface
+	loggingRepo     repositories.LoggingRepositoryInterface
+	jobDispatcher   queue.JobDispatcher
+	notifDispatcher queue_jobs.NotificationDispatcher
+}
+
+func NewSpendingLimitService(
+	repo *repositories.SpendingLimitRepository,
+	txnRepo *repositories.TransactionRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	loggingRepo *repositories.LoggingRepository,
+	jobDispatcher queue.JobDispatcher,
+	notifDispatcher queue_jobs.NotificationDispatcher,
+) *SpendingLimitService {
+	return &SpendingLimitService{
+		repo:            repo,
+		txnRepo:         txnRepo,
+		analyticsRepo:   analyticsRepo,
+		loggingRepo:     loggingRepo,
+		jobDispatcher:   jobDispatcher,
+		notifDispatcher: notifDispatcher,
+	}
+}
+
+var _ SpendingLimitServiceInterface = (*SpendingLimitService)(nil)
+
+// limitScopes resolves each limit's display name and the categories it covers.
+func (s *SpendingLimitService) limitScopes(ctx context.Context, userID int64, limits []models.SpendingLimit) (map[int64]string, map[int64][]int64, error) {
+	categories, err := s.txnRepo.FindAllCategories(ctx, nil, &userID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	categoryNames := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.DisplayName
+	}
+
+	groups, err := s.txnRepo.FindAllCategoryGroups(ctx, nil, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupsByID := make(map[int64]models.CategoryGroup, len(groups))
+	for _, g := range groups {
+		groupsByID[g.ID] = g
+	}
+
+	names := make(map[int64]string, len(limits))
+	covers := make(map[int64][]int64, len(limits))
+	for _, l := range limits {
+		if l.CategoryID != nil {
+			names[l.ID] = categoryNames[*l.CategoryID]
+			covers[l.ID] = []int64{*l.CategoryID}
+			continue
+		}
+		if g, ok := groupsByID[*l.GroupID]; ok {
+			names[l.ID] = g.Name
+			for _, c := range g.Categories {
+				covers[l.ID] = append(covers[l.ID], c.ID)
+			}
+		}
+	}
+	return names, covers, nil
+}
+
+// monthSpending sums each limit's spending in month from the monthly category totals.
+func (s *SpendingLimitService) monthSpending(ctx context.Context, userID int64, month time.Time, covers map[int64][]int64) (map[int64]decimal.Decimal, error) {
+	rows, err := s.analyticsRepo.FetchMonthlyCategoryTotals(ctx, nil, userID, nil, month.Year(), int(month.Month()))
+	if err != nil {
+		return nil, err
+	}
+	outflows := utils.CategoryOutflows(rows)
+
+	spent := make(map[int64]decimal.Decimal, len(covers))
+	for limitID, categoryIDs := range covers {
+		total := decimal.Zero
+		for _, id := range categoryIDs {
+			total = total.Add(outflows[id])
+		}
+		spent[limitID] = total
+	}
+	return spent, nil
+}
+
+func (s *SpendingLimitService) FetchLimits(ctx context.Context, userID int64) ([]models.SpendingLimitStatus, error) {
+	limits, err := s.repo.FindLimits(ctx, nil, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names, covers, err := s.limitScopes(ctx, userID, limits)
+	if err != nil {
+		return nil, err
+	}
+	spent, err := s.monthSpending(ctx, userID, utils.MonthStart(time.Now().UTC()), covers)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.SpendingLimitStatus, 0, len(limits))
+	for _, l := range limits {
+		status := models.SpendingLimitStatus{
+			SpendingLimit: l,
+			Name:          names[l.ID],
+			Spent:         spent[l.ID],
+		}
+		if l.Amount.IsPositive() {
+			status.PercentUsed = status.Spent.Div(l.Amount).Mul(decimal.NewFromInt(100)).Round(2)
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+func limitFromReq(req *models.SpendingLimitReq) (models.SpendingLimit, error) {
+	if !req.Amount.IsPositive() {
+		return models.SpendingLimit{}, fmt.Errorf("spending limit must be positive")
+	}
+
+	record := models.SpendingLimit{
+		CategoryID:    req.CategoryID,
+		GroupID:       req.GroupID,
+		Amount:        req.Amount,
+		NotifyAt80:    true,
+		NotifyAt100:   true,
+		CustomPercent: req.CustomPercent,
+		Delivery:      req.Delivery,
+		IsActive:      true,
+	}
+	if req.NotifyAt80 != nil {
+		record.NotifyAt80 = *req.NotifyAt80
+	}
+	if req.NotifyAt100 != nil {
+		record.NotifyAt100 = *req.NotifyAt100
+	}
+	if record.Delivery == "" {
+		record.Delivery = models.SpendingLimitDeliveryInstant
+	}
+	if req.IsActive != nil {
+		record.IsActive = *req.IsActive
+	}
+	return record, nil
+}
+
+var limitLogFields = []string{"scope", "scope_id", "amount", "notify_at_80", "notify_at_100", "custom_percent", "delivery", "is_active"}
+
+// limitFields flattens a limit for the activity log; a nil limit has no fields.
+func limitFields(l *models.SpendingLimit) map[string]string {
+	fields := map[string]string{}
+	if l == nil {
+		return fields
+	}
+	if l.CategoryID != nil {
+		fields["scope"] = "category"
+		fields["scope_id"] = strconv.FormatInt(*l.CategoryID, 10)
+	} else if l.GroupID != nil {
+		fields["scope"] = "group"
+		fields["scope_id"] = strconv.FormatInt(*l.GroupID, 10)
+	}
+	fields["amount"] = l.Amount.StringFixed(2)
+	fields["notify_at_80"] = strconv.FormatBool(l.NotifyAt80)
+	fields["notify_at_100"] = strconv.FormatBool(l.NotifyAt100)
+	if l.CustomPercent != nil {
+		fields["custom_percent"] = strconv.Itoa(*l.CustomPercent)
+	}
+	fields["delivery"] = string(l.Delivery)
+	fields["is_active"] = strconv.FormatBool(l.IsActive)
+	return fields
+}
+
+func limitChanges(old, new *models.SpendingLimit, changes *utils.Changes) {
+	oldFields, newFields := limitFields(old), limitFields(new)
+	for _, key := range limitLogFields {
+		utils.CompareChanges(oldFields[key], newFields[key], changes, key)
+	}
+}
+
+func (s *SpendingLimitService) InsertLimit(ctx context.Context, userID int64, req *models.SpendingLimitReq) (int64, error) {
+	record, err := limitFromReq(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkExpenseScope(ctx, s.txnRepo, nil, userID, req.CategoryID, req.GroupID, "spending limit", "limited"); err != nil {
+		return 0, err
+	}
+
+	record.UserID = userID
+	id, err := s.repo.InsertLimit(ctx, nil, &record)
+	if err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(id, 10), changes, "id")
+	limitChanges(nil, &record, changes)
+
+	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "create",
+		Category:    "spending_limit",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateLimit changes a limit's amount and alerting. What it covers is fixed.
+func (s *SpendingLimitService) UpdateLimit(ctx context.Context, userID, id int64, req *models.SpendingLimitReq) (int64, error) {
+	record, err := limitFromReq(req)
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := s.repo.FindLimitByID(ctx, nil, id, userID)
+	if err != nil {
+		return 0, fmt.Errorf("spending limit not found: %w", err)
+	}
+
+	if optionalID(req.CategoryID) != optionalID(existing.CategoryID) || optionalID(req.GroupID) != optionalID(existing.GroupID) {
+		return 0, fmt.Errorf("a spending limit's category or group can't be changed")
+	}
+
+	record.ID = existing.ID
+	record.UserID = existing.UserID
+
+	if _, err := s.repo.UpdateLimit(ctx, nil, record); err != nil {
+		return 0, err
+	}
+
+	changes := utils.InitChanges()
+	limitChanges(&existing, &record, changes)
+
+	if changes.HasChanges() {
+		changes.Stamp("id", strconv.FormatInt(existing.ID, 10))
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+			LoggingRepo: s.loggingRepo,
+			Event:       "update",
+			Category:    "spending_limit",
+			Description: nil,
+			Payload:     changes,
+			Causer:      &userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return record.ID, nil
+}
+
+func (s *SpendingLimitService) DeleteLimit(ctx context.Context, userID, id int64) error {
+	existing, err := s.repo.FindLimitByID(ctx, nil, id, userID)
+	if err != nil {
+		return fmt.Errorf("spending limit not found: %w", err)
+	}
+
+	if err := s.repo.DeleteLimit(ctx, nil, existing.ID); err != nil {
+		return err
+	}
+
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(existing.ID, 10), "", changes, "id")
+	limitChanges(&existing, nil, changes)
+
+	return s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
+		LoggingRepo: s.loggingRepo,
+		Event:       "delete",
+		Category:    "spending_limit",
+		Description: nil,
+		Payload:     changes,
+		Causer:      &userID,
+	})
+}
+
+// CheckLimits records every threshold the user's spending has reached this
+// month. Each threshold alerts once a month: instant limits notify right away
+// with the highest one just crossed, digest limits wait for CollectDigests.
+// Writes in other months can't cross anything that still matters, so only the
+// current month is checked.
+func (s *SpendingLimitService) CheckLimits(ctx context.Context, userID int64, months []time.Time) error {
+	current := utils.MonthStart(time.Now().UTC())
+	touched := false
+	for _, m := range months {
+		if utils.MonthStart(m).Equal(current) {
+			touched = true
+			break
+		}
+	}
+	if !touched {
+		return nil
+	}
+
+	limits, err := s.repo.FindLimits(ctx, nil, userID, true)
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+
+	names, covers, err := s.limitScopes(ctx, userID, limits)
+	if err != nil {
+		return err
+	}
+	spent, err := s.monthSpending(ctx, userID, current, covers)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, l := range limits {
+		instant := l.Delivery != models.SpendingLimitDeliveryDigest
+
+		var crossed []int
+		for _, p := range utils.ReachedThresholds(utils.LimitThresholds(l), spent[l.ID], l.Amount) {
+			alert := models.SpendingLimitAlert{
+				UserID:  userID,
+				LimitID: l.ID,
+				Month:   current,
+				Percent: p,
+				Spent:   spent[l.ID],
+			}
+			if instant {
+				alert.NotifiedAt = &now
+			}
+			isNew, err := s.repo.InsertAlert(ctx, nil, &alert)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				crossed = append(crossed, p)
+			}
+		}
+
+		if len(crossed) == 0 || !instant || s.notifDispatcher == nil {
+			continue
+		}
+
+		c := models.SpendingLimitCrossing{
+			LimitID: l.ID,
+			Name:    names[l.ID],
+			Percent: crossed[len(crossed)-1],
+			Spent:   spent[l.ID],
+			Amount:  l.Amount,
+		}
+		title, notifType := limitCrossingTitle(c)
+		_ = s.notifDispatcher.DispatchWithEvent(ctx, userID, title, limitCrossingLine(c), notifType,
+			ws.Event{Type: ws.TypeSpendingLimitCrossed, Payload: ws.SpendingLimitPayload{LimitIDs: []int64{l.ID}}})
+	}
+
+	return nil
+}
+
+func limitCrossingTitle(c models.SpendingLimitCrossing) (string, models.NotificationType) {
+	if c.Percent == 100 {
+		return fmt.Sprintf("%s reached its spending limit", c.Name), models.NotificationTypeError
+	}
+	if c.Percent > 100 {
+		return fmt.Sprintf("%s is at %d%% of its spending limit", c.Name, c.Percent), models.NotificationTypeError
+	}
+	return fmt.Sprintf("%s is at %d%% of its spending limit", c.Name, c.Percent), models.NotificationTypeWarning
+}
+
+func limitCrossingLine(c models.SpendingLimitCrossing) string {
+	return fmt.Sprintf("%s: %s of %s spent this month (%d%%).", c.Name, c.Spent.StringFixed(2), c.Amount.StringFixed(2), c.Percent)
+}
+
+// CollectDigests gathers crossings waiting for the daily digest, keeping the
+// highest threshold per limit and month, and stamps them as sent.
+func (s *SpendingLimitService) CollectDigests(ctx context.Context) ([]models.SpendingLimitDigest, error) {
+	pending, err := s.repo.FindPendingAlerts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[int64][]models.SpendingLimitAlert)
+	var userIDs []int64
+	for _, a := range pending {
+		if _, ok := byUser[a.UserID]; !ok {
+			userIDs = append(userIDs, a.UserID)
+		}
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+
+	now := time.Now().UTC()
+	digests := make([]models.SpendingLimitDigest, 0, len(userIDs))
+	for _, userID := range userIDs {
+		alerts := byUser[userID]
+
+		limits, err := s.repo.FindLimits(ctx, nil, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		limitsByID := make(map[int64]models.SpendingLimit, len(limits))
+		for _, l := range limits {
+			limitsByID[l.ID] = l
+		}
+		names, _, err := s.limitScopes(ctx, userID, limits)
+		if err != nil {
+			return nil, err
+		}
+
+		// alerts come ordered by limit and percent, so the last one per
+		// limit and month is the highest
+		type key struct {
+			limitID int64
+			month   string
+		}
+		highest := make(map[key]int)
+		digest := models.SpendingLimitDigest{UserID: userID}
+		ids := make([]int64, 0, len(alerts))
+		for _, a := range alerts {
+			ids = append(ids, a.ID)
+			c := models.SpendingLimitCrossing{
+				LimitID: a.LimitID,
+				Name:    names[a.LimitID],
+				Percent: a.Percent,
+				Spent:   a.Spent,
+				Amount:  limitsByID[a.LimitID].Amount,
+			}
+			k := key{a.LimitID, a.Month.Format("2006-01")}
+			if i, ok := highest[k]; ok {
+				digest.Crossings[i] = c
+				continue
+			}
+			highest[k] = len(digest.Crossings)
+			digest.Crossings = append(digest.Crossings, c)
+		}
+
+		if err := s.repo.MarkAlertsNotified(ctx, nil, ids, now); err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+	}
+
+	return digests, nil
+}
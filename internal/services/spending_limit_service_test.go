@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/internal/tests"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type SpendingLimitServiceTestSuite struct {
+	tests.ServiceIntegrationSuite
+	accountID int64
+	category  models.Category
+}
+
+func TestSpendingLimitServiceSuite(t *testing.T) {
+	suite.Run(t, new(SpendingLimitServiceTestSuite))
+}
+
+func (s *SpendingLimitServiceTestSuite) SetupTest() {
+	s.ServiceIntegrationSuite.SetupTest()
+
+	err := s.TC.DB.WithContext(s.Ctx).
+		Where("classification = ? AND parent_id IS NOT NULL AND user_id IS NULL AND deleted_at IS NULL", "expense").
+		Order("id ASC").
+		First(&s.category).Error
+	s.Require().NoError(err, "expected a default expense category")
+
+	balance := decimal.NewFromInt(10000)
+	s.accountID, err = s.TC.App.AccountService.InsertAccount(s.Ctx, 1, &models.AccountReq{
+		Name:           "Limit Account",
+		AccountTypeID:  1,
+		Type:           "asset",
+		Subtype:        "cash",
+		Classification: "current",
+		Balance:        &balance,
+		OpenedAt:       utils.MonthStart(time.Now().UTC()).AddDate(0, -1, 0),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *SpendingLimitServiceTestSuite) spend(amount int64) {
+	_, err := s.TC.App.TransactionService.InsertTransaction(s.Ctx, 1, &models.TransactionReq{
+		AccountID:       s.accountID,
+		CategoryID:      &s.category.ID,
+		TransactionType: "expense",
+		Amount:          decimal.NewFromInt(amount),
+		TxnDate:         utils.MonthStart(time.Now().UTC()),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *SpendingLimitServiceTestSuite) alerts(limitID int64) []models.SpendingLimitAlert {
+	var records []models.SpendingLimitAlert
+	s.Require().NoError(s.TC.DB.Where("limit_id = ?", limitID).Order("percent ASC").Find(&records).Error)
+	return records
+}
+
+func (s *SpendingLimitServiceTestSuite) check() {
+	s.Require().NoError(s.TC.App.SpendingLimitService.CheckLimits(s.Ctx, 1, []time.Time{time.Now().UTC()}))
+}
+
+// Each threshold alerts once a month, however often the limit is checked.
+func (s *SpendingLimitServiceTestSuite) TestCheckLimits_AlertsOncePerThreshold() {
+	svc := s.TC.App.SpendingLimitService
+	custom := 120
+
+	limitID, err := svc.InsertLimit(s.Ctx, 1, &models.SpendingLimitReq{
+		CategoryID:    &s.category.ID,
+		Amount:        decimal.NewFromInt(100),
+		CustomPercent: &custom,
+	})
+	s.Require().NoError(err)
+
+	s.spend(50)
+	s.check()
+	s.Empty(s.alerts(limitID))
+
+	s.spend(35)
+	s.check()
+	s.check()
+	alerts := s.alerts(limitID)
+	s.Require().Len(alerts, 1)
+	s.Equal(80, alerts[0].Percent)
+	s.NotNil(alerts[0].NotifiedAt, "instant alerts are sent straight away")
+
+	s.spend(40)
+	s.check()
+	alerts = s.alerts(limitID)
+	s.Require().Len(alerts, 3)
+	s.Equal([]int{80, 100, 120}, []int{alerts[0].Percent, alerts[1].Percent, alerts[2].Percent})
+
+	limits, err := svc.FetchLimits(s.Ctx, 1)
+	s.Require().NoError(err)
+	s.Require().Len(limits, 1)
+	s.True(decimal.NewFromInt(125).Equal(limits[0].Spent), "spent %s", limits[0].Spent)
+	s.True(decimal.NewFromInt(125).Equal(limits[0].PercentUsed))
+}
+
+// Digest limits hold their crossings until the daily digest picks them up.
+func (s *SpendingLimitServiceTestSuite) TestCollectDigests_ReportsHighestCrossing() {
+	svc := s.TC.App.SpendingLimitService
+
+	limitID, err := svc.InsertLimit(s.Ctx, 1, &models.SpendingLimitReq{
+		CategoryID: &s.category.ID,
+		Amount:     decimal.NewFromInt(200),
+		Delivery:   models.SpendingLimitDeliveryDigest,
+	})
+	s.Require().NoError(err)
+
+	s.spend(210)
+	s.check()
+
+	alerts := s.alerts(limitID)
+	s.Require().Len(alerts, 2)
+	s.Nil(alerts[0].NotifiedAt)
+	s.Nil(alerts[1].NotifiedAt)
+
+	digests, err := svc.CollectDigests(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().Len(digests, 1)
+	s.Equal(int64(1), digests[0].UserID)
+	s.Require().Len(digests[0].Crossings, 1)
+	s.Equal(100, digests[0].Crossings[0].Percent)
+	s.Equal(s.category.DisplayName, digests[0].Crossings[0].Name)
+
+	digests, err = svc.CollectDigests(s.Ctx)
+	s.Require().NoError(err)
+	s.Empty(digests, "crossings are only reported once")
+}
+
+func (s *SpendingLimitServiceTestSuite) TestUpdateLimit_KeepsScope() {
+	svc := s.TC.App.SpendingLimitService
+
+	limitID, err := svc.InsertLimit(s.Ctx, 1, &models.SpendingLimitReq{
+		CategoryID: &s.category.ID,
+		Amount:     decimal.NewFromInt(100),
+	})
+	s.Require().NoError(err)
+
+	groupID := int64(1)
+	_, err = svc.UpdateLimit(s.Ctx, 1, limitID, &models.SpendingLimitReq{
+		GroupID: &groupID,
+		Amount:  decimal.NewFromInt(100),
+	})
+	s.Require().Error(err)
+
+	_, err = svc.UpdateLimit(s.Ctx, 1, limitID, &models.SpendingLimitReq{
+		CategoryID: &s.category.ID,
+		Amount:     decimal.Zero,
+	})
+	s.Require().Error(err)
+}
@@ -25,13 +25,13 @@ type TransactionServiceInterface interface {
 	FetchAllCategories(ctx context.Context, userID int64, includeDeleted bool) ([]models.Category, error)
 	FetchCategoryByID(ctx context.Context, userID int64, id int64, includeDeleted bool) (*models.Category, error)
 	InsertTransaction(ctx context.Context, userID int64, req *models.TransactionReq, existingTx ...*gorm.DB) (models.InsertResult, error)
-	InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq) (models.InsertResult, error)
+	InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq, existingTx ...*gorm.DB) (models.InsertResult, error)
 	InsertCategory(ctx context.Context, userID int64, req *models.CategoryReq) (int64, error)
 	UpdateTransaction(ctx context.Context, userID int64, id int64, req *models.TransactionReq) (int64, error)
 	UpdateCategory(ctx context.Context, userID int64, id int64, req *models.CategoryReq) (int64, error)
 	DeleteTransaction(ctx context.Context, userID int64, id int64) error
 	UpdateTransfer(ctx context.Context, userID int64, id int64, req *models.UpdateTransferReq) error
-	DeleteTransfer(ctx context.Context, userID int64, id int64) error
+	DeleteTransfer(ctx context.Context, userID int64, id int64, existingTx ...*gorm.DB) error
 	DeleteCategory(ctx context.Context, userID int64, id int64) error
 	RestoreTransaction(ctx context.Context, userID int64, id int64) error
 	RestoreCategory(ctx context.Context, userID int64, id int64) error
@@ -61,6 +61,7 @@ type TransactionService struct {
 	settingsRepo  repositories.SettingsRepositoryInterface
 	loggingRepo   repositories.LoggingRepositoryInterface
 	savingsRepo   repositories.SavingsRepositoryInterface
+	householdRepo repositories.HouseholdRepositoryInterface
 	jobDispatcher queue.JobDispatcher
 }
 
@@ -70,6 +71,7 @@ func NewTransactionService(
 	settingsRepo *repositories.SettingsRepository,
 	loggingRepo *repositories.LoggingRepository,
 	savingsRepo *repositories.SavingsRepository,
+	householdRepo *repositories.HouseholdRepository,
 	jobDispatcher queue.JobDispatcher,
 ) *TransactionService {
 	return &TransactionService{
@@ -78,6 +80,7 @@ func NewTransactionService(
 		settingsRepo:  settingsRepo,
 		loggingRepo:   loggingRepo,
 		savingsRepo:   savingsRepo,
+		householdRepo: householdRepo,
 		jobDispatcher: jobDispatcher,
 	}
 }
@@ -115,6 +118,13 @@ func (s *TransactionService) updateAccountBalance(ctx context.Context, tx *gorm.
 
 func (s *TransactionService) FetchTransactionsPaginated(ctx context.Context, userID int64, p utils.PaginationParams, includeDeleted bool, accountID *int64) ([]models.Transaction, *models.TransactionBatchTotals, *utils.Paginator, error) {
 
+	// household members read a shared account's ledger as its owner
+	if accountID != nil {
+		if ownerID, err := s.householdRepo.FindSharedAccountOwner(ctx, nil, *accountID, userID, false); err == nil {
+			userID = ownerID
+		}
+	}
+
 	totalRecords, err := s.repo.CountTransactions(ctx, nil, userID, p.Filters, includeDeleted, accountID)
 	if err != nil {
 		return nil, nil, nil, err
@@ -250,6 +260,12 @@ func (s *TransactionService) InsertTransaction(ctx context.Context, userID int64
 	}
 	ownsTx := len(existingTx) == 0 || existingTx[0] == nil
 
+	// members with manage rights on a shared account book on the owner's ledger
+	actorID := userID
+	if ownerID, err := s.householdRepo.FindSharedAccountOwner(ctx, tx, req.AccountID, userID, true); err == nil {
+		userID = ownerID
+	}
+
 	account, err := s.accRepo.FindAccountByID(ctx, tx, req.AccountID, userID, false)
 	if err != nil {
 		tx.Rollback()
@@ -325,10 +341,10 @@ func (s *TransactionService) InsertTransaction(ctx context.Context, userID int64
 
 	var category models.Category
 	if req.CategoryID != nil {
-		category, err = s.repo.FindCategoryByID(ctx, tx, *req.CategoryID, &userID, false)
+		category, err = s.resolveBookingCategory(ctx, tx, *req.CategoryID, actorID, userID)
 		if err != nil {
 			tx.Rollback()
-			return models.InsertResult{}, fmt.Errorf("can't find category with given id %w", err)
+			return models.InsertResult{}, err
 		}
 	} else {
 		category, err = s.repo.FindCategoryByClassification(ctx, tx, "uncategorized", &userID)
@@ -348,12 +364,14 @@ func (s *TransactionService) InsertTransaction(ctx context.Context, userID int64
 		TxnDate:         txDay,
 		Description:     req.Description,
 		IdempotencyKey:  req.IdempotencyKey,
+		CreatedBy:       &actorID,
 	}
 
 	txnID, err := s.repo.InsertTransaction(ctx, tx, &tr)
 	if err != nil {
 		tx.Rollback()
-		if req.IdempotencyKey != nil && *req.IdempotencyKey != "" && utils.IsUniqueViolation(err) {
+		// A caller's transaction is aborted by the violation, so only our own can retry the lookup
+		if ownsTx && req.IdempotencyKey != nil && *req.IdempotencyKey != "" && utils.IsUniqueViolation(err) {
 			if existing, lookupErr := s.repo.FindTransactionByIdempotencyKey(ctx, nil, userID, *req.IdempotencyKey); lookupErr == nil {
 				return models.InsertResult{ID: existing.ID, IsDuplicate: true}, nil
 			}
@@ -410,34 +428,74 @@ func (s *TransactionService) InsertTransaction(ctx context.Context, userID int64
 		Category:    "transaction",
 		Description: nil,
 		Payload:     changes,
-		Causer:      &userID,
+		Causer:      &actorID,
 	})
 	if err != nil {
 		return models.InsertResult{}, err
 	}
 
+	// The limit check reads committed totals, so it only runs once the insert is ours to commit
+	if ownsTx && tr.TransactionType == "expense" {
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.CheckSpendingLimitsJob{
+			UserID: userID,
+			Months: []time.Time{tr.TxnDate},
+		}); err != nil {
+			return models.InsertResult{}, err
+		}
+	}
+
 	return models.InsertResult{ID: txnID}, nil
 }
 
-func (s *TransactionService) InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq) (models.InsertResult, error) {
+// resolveBookingCategory looks the picked category up among the actor's own
+// categories. When the actor books on a household member's shared account, a
+// custom category is mapped onto the owner's category of the same name, since
+// the transaction lands on the owner's ledger.
+func (s *TransactionService) resolveBookingCategory(ctx context.Context, tx *gorm.DB, categoryID, actorID, ownerID int64) (models.Category, error) {
+	category, err := s.repo.FindCategoryByID(ctx, tx, categoryID, &actorID, false)
+	if err != nil {
+		return models.Category{}, fmt.Errorf("can't find category with given id %w", err)
+	}
+	if actorID == ownerID || category.UserID == nil {
+		return category, nil
+	}
+
+	mapped, err := s.repo.FindCategoryByName(ctx, tx, category.Name, &ownerID)
+	if err != nil || mapped.Classification != category.Classification {
+		return models.Category{}, fmt.Errorf("category %q doesn't exist for the account's owner", category.DisplayName)
+	}
+	return mapped, nil
+}
+
+func (s *TransactionService) InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq, existingTx ...*gorm.DB) (models.InsertResult, error) {
 
+	var tx *gorm.DB
+	var err error
+
+	if len(existingTx) > 0 && existingTx[0] != nil {
+		tx = existingTx[0]
+	}
+	ownsTx := tx == nil
+
+	// A caller's transaction may already hold the transfer, so look there first
 	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
-		if existing, err := s.repo.FindTransferByIdempotencyKey(ctx, nil, userID, *req.IdempotencyKey); err == nil {
+		if existing, err := s.repo.FindTransferByIdempotencyKey(ctx, tx, userID, *req.IdempotencyKey); err == nil {
 			return models.InsertResult{ID: existing.ID, IsDuplicate: true}, nil
 		}
 	}
 
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		return models.InsertResult{}, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	if ownsTx {
+		tx, err = s.repo.BeginTx(ctx)
+		if err != nil {
+			return models.InsertResult{}, err
 		}
-	}()
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+	}
 
 	fromAcc, err := s.accRepo.FindAccountByID(ctx, tx, req.SourceID, userID, true)
 	if err != nil {
@@ -536,7 +594,8 @@ func (s *TransactionService) InsertTransfer(ctx context.Context, userID int64, r
 	trID, err := s.repo.InsertTransfer(ctx, tx, &transfer)
 	if err != nil {
 		tx.Rollback()
-		if req.IdempotencyKey != nil && *req.IdempotencyKey != "" && utils.IsUniqueViolation(err) {
+		// A caller's transaction is aborted by the violation, so only our own can retry the lookup
+		if ownsTx && req.IdempotencyKey != nil && *req.IdempotencyKey != "" && utils.IsUniqueViolation(err) {
 			if existing, lookupErr := s.repo.FindTransferByIdempotencyKey(ctx, nil, userID, *req.IdempotencyKey); lookupErr == nil {
 				return models.InsertResult{ID: existing.ID, IsDuplicate: true}, nil
 			}
@@ -577,24 +636,22 @@ func (s *TransactionService) InsertTransfer(ctx context.Context, userID int64, r
 		return models.InsertResult{}, err
 	}
 
+	// A caller's transaction may still roll back, so the caller logs the transfer once it commits
+	if !ownsTx {
+		return models.InsertResult{ID: trID}, nil
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return models.InsertResult{}, err
 	}
 
 	// Log transfer (one event)
-	changes := utils.InitChanges()
-	utils.CompareChanges("", strconv.FormatInt(trID, 10), changes, "id")
-	utils.CompareChanges("", fromAcc.Name, changes, "from")
-	utils.CompareChanges("", toAcc.Name, changes, "to")
-	utils.CompareChanges("", req.Amount.StringFixed(2), changes, "amount")
-	utils.CompareChanges("", transfer.Currency, changes, "currency")
-
 	if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
 		LoggingRepo: s.loggingRepo,
 		Event:       "create",
 		Category:    "transfer",
 		Description: req.Notes,
-		Payload:     changes,
+		Payload:     transferCreatedChanges(transfer, fromAcc.Name, toAcc.Name),
 		Causer:      &userID,
 	}); err != nil {
 		return models.InsertResult{}, err
@@ -684,6 +741,16 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, userID int64
 		return 0, errors.New("can't edit a manual adjustment transaction")
 	}
 
+	// An expense that settled a recurring goal's cycle is fixed by that settlement
+	sameDay := req.TxnDate.UTC().Truncate(24 * time.Hour).Equal(exTr.TxnDate.UTC().Truncate(24 * time.Hour))
+	if req.AccountID != exTr.AccountID || req.TransactionType != exTr.TransactionType ||
+		!req.Amount.Equal(exTr.Amount) || !sameDay {
+		if err := s.checkNotSettlingGoal(ctx, tx, userID, exTr.ID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
 	// Load old account & category (for logs)
 	oldAccount, err := s.accRepo.FindAccountByID(ctx, tx, exTr.AccountID, userID, false)
 	if err != nil {
@@ -904,6 +971,15 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, userID int64
 		}
 	}
 
+	if exTr.TransactionType == "expense" || tr.TransactionType == "expense" {
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.CheckSpendingLimitsJob{
+			UserID: userID,
+			Months: []time.Time{exTr.TxnDate, tr.TxnDate},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
 	return txnID, nil
 }
 
@@ -992,6 +1068,11 @@ func (s *TransactionService) DeleteTransaction(ctx context.Context, userID int64
 		return fmt.Errorf("can't find transaction with given id %w", err)
 	}
 
+	if err := s.checkNotSettlingGoal(ctx, tx, userID, tr.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	account, err := s.accRepo.FindAccountByID(ctx, tx, tr.AccountID, userID, false)
 	if err != nil {
 		tx.Rollback()
@@ -1111,6 +1192,13 @@ func (s *TransactionService) UpdateTransfer(ctx context.Context, userID int64, i
 		return fmt.Errorf("can't find transfer: %w", err)
 	}
 
+	if !req.Amount.Equal(transfer.Amount) {
+		if _, err := s.savingsRepo.FindContributionByTransferID(ctx, tx, transfer.ID, userID); err == nil {
+			tx.Rollback()
+			return fmt.Errorf("transfer backs a savings goal contribution; change the contribution instead")
+		}
+	}
+
 	inflow, err := s.repo.FindTransactionByID(ctx, tx, transfer.TransactionInflowID, userID, false)
 	if err != nil {
 		tx.Rollback()
@@ -1280,19 +1368,26 @@ func (s *TransactionService) UpdateTransfer(ctx context.Context, userID int64, i
 	return nil
 }
 
-func (s *TransactionService) DeleteTransfer(ctx context.Context, userID int64, id int64) error {
+func (s *TransactionService) DeleteTransfer(ctx context.Context, userID int64, id int64, existingTx ...*gorm.DB) error {
 
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
+	var tx *gorm.DB
+	var err error
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
+	if len(existingTx) > 0 && existingTx[0] != nil {
+		tx = existingTx[0]
+	} else {
+		tx, err = s.repo.BeginTx(ctx)
+		if err != nil {
+			return err
 		}
-	}()
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+	}
+	ownsTx := len(existingTx) == 0 || existingTx[0] == nil
 
 	// Load the transfer
 	transfer, err := s.repo.FindTransferByID(ctx, tx, id, userID)
@@ -1301,6 +1396,13 @@ func (s *TransactionService) DeleteTransfer(ctx context.Context, userID int64, i
 		return fmt.Errorf("can't find transfer with given id %w", err)
 	}
 
+	// A transfer backing a savings goal contribution takes the contribution with it
+	goalName, err := s.releaseGoalContribution(ctx, tx, userID, transfer.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Load associated transactions
 	inflow, err := s.repo.FindTransactionByID(ctx, tx, transfer.TransactionInflowID, userID, false)
 	if err != nil {
@@ -1392,19 +1494,17 @@ func (s *TransactionService) DeleteTransfer(ctx context.Context, userID int64, i
 		return err
 	}
 
+	// A caller's transaction may still roll back, so the caller logs the removal once it commits
+	if !ownsTx {
+		return nil
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
 	// Log synthetic transfer deletion
-	changes := utils.InitChanges()
-	utils.CompareChanges("", strconv.FormatInt(transfer.ID, 10), changes, "id")
-	utils.CompareChanges(fromAcc.Name, "", changes, "from")
-	utils.CompareChanges(toAcc.Name, "", changes, "to")
-	utils.CompareChanges(transfer.Amount.StringFixed(2), "", changes, "amount")
-	utils.CompareChanges(transfer.Currency, "", changes, "currency")
-	utils.CompareChanges(utils.SafeString(transfer.Notes), "", changes, "description")
-
+	changes := transferDeletedChanges(transfer, fromAcc.Name, toAcc.Name, goalName)
 	if !changes.IsEmpty() {
 		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.ActivityLogJob{
 			LoggingRepo: s.loggingRepo,
@@ -1422,6 +1522,74 @@ func (s *TransactionService) DeleteTransfer(ctx context.Context, userID int64, i
 	return nil
 }
 
+// transferCreatedChanges describes a newly booked transfer for the activity log.
+func transferCreatedChanges(transfer models.Transfer, fromName, toName string) *utils.Changes {
+	changes := utils.InitChanges()
+	utils.CompareChanges("", strconv.FormatInt(transfer.ID, 10), changes, "id")
+	utils.CompareChanges("", fromName, changes, "from")
+	utils.CompareChanges("", toName, changes, "to")
+	utils.CompareChanges("", transfer.Amount.StringFixed(2), changes, "amount")
+	utils.CompareChanges("", transfer.Currency, changes, "currency")
+	return changes
+}
+
+// transferDeletedChanges describes a removed transfer for the activity log.
+func transferDeletedChanges(transfer models.Transfer, fromName, toName, goalName string) *utils.Changes {
+	changes := utils.InitChanges()
+	utils.CompareChanges(strconv.FormatInt(transfer.ID, 10), "", changes, "id")
+	utils.CompareChanges(fromName, "", changes, "from")
+	utils.CompareChanges(toName, "", changes, "to")
+	utils.CompareChanges(goalName, "", changes, "goal")
+	utils.CompareChanges(transfer.Amount.StringFixed(2), "", changes, "amount")
+	utils.CompareChanges(transfer.Currency, "", changes, "currency")
+	utils.CompareChanges(utils.SafeString(transfer.Notes), "", changes, "description")
+	return changes
+}
+
+// releaseGoalContribution removes the savings contribution backed by the
+// given transfer, if any, and takes its amount back off the goal.
+func (s *TransactionService) releaseGoalContribution(ctx context.Context, tx *gorm.DB, userID, transferID int64) (string, error) {
+	contrib, err := s.savingsRepo.FindContributionByTransferID(ctx, tx, transferID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	goal, err := s.savingsRepo.FindGoalByID(ctx, tx, contrib.GoalID, userID)
+	if err != nil {
+		return "", fmt.Errorf("can't find savings goal for contribution %w", err)
+	}
+
+	if err := s.savingsRepo.DeleteContribution(ctx, tx, contrib.ID); err != nil {
+		return "", err
+	}
+
+	goal.CurrentAmount = goal.CurrentAmount.Sub(contrib.Amount)
+	if goal.CurrentAmount.IsNegative() {
+		goal.CurrentAmount = decimal.Zero
+	}
+	if err := s.savingsRepo.UpdateCurrentAmount(ctx, tx, goal.ID, goal); err != nil {
+		return "", err
+	}
+
+	return goal.Name, nil
+}
+
+// checkNotSettlingGoal refuses changes to an expense that settled a recurring
+// savings goal's cycle, since the closed cycle was drawn down against it.
+func (s *TransactionService) checkNotSettlingGoal(ctx context.Context, tx *gorm.DB, userID, transactionID int64) error {
+	_, err := s.savingsRepo.FindCycleByTransactionID(ctx, tx, transactionID, userID)
+	if err == nil {
+		return fmt.Errorf("transaction settles a savings goal cycle and can't be changed")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
 func (s *TransactionService) DeleteCategory(ctx context.Context, userID int64, id int64) error {
 
 	tx, err := s.repo.BeginTx(ctx)
@@ -2556,6 +2724,16 @@ func (s *TransactionService) ProcessTemplate(ctx context.Context, template *mode
 		return err
 	}
 
+	// InsertTransaction ran inside our tx and left the limit check to us
+	if currentTemplate.TemplateType != "transfer" && currentTemplate.TransactionType != nil && *currentTemplate.TransactionType == "expense" {
+		if err := s.jobDispatcher.Dispatch(ctx, &queue_jobs.CheckSpendingLimitsJob{
+			UserID: currentTemplate.UserID,
+			Months: []time.Time{txDate},
+		}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -123,7 +123,29 @@ TRUNCATE TABLE
     transfers,
     balances,
     accounts,
-    account_daily_snapshots
+    account_daily_snapshots,
+    spending_limits,
+    spending_limit_alerts,
+    households,
+    household_members,
+    account_shares,
+    access_delegations,
+    access_delegation_modules,
+    access_delegation_accounts,
+    price_alert_rules,
+    allocation_targets,
+    benchmarks,
+    user_benchmarks,
+    investment_transfers,
+    category_budgets,
+    category_budget_months,
+    envelope_budgets,
+    envelopes,
+    envelope_categories,
+    envelope_movements,
+    saving_goal_milestones,
+    saving_goal_cycles,
+    savings_budgets
 RESTART IDENTITY CASCADE;
 `
 
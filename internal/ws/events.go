@@ -3,9 +3,14 @@ package ws
 // Payloads carry identifiers only. Events are droppable, so a client must never
 // need one to reach correct state.
 const (
-	TypeReportCompleted     = "report.completed"
-	TypeReportFailed        = "report.failed"
-	TypeNotificationCreated = "notification.created"
+	TypeReportCompleted      = "report.completed"
+	TypeReportFailed         = "report.failed"
+	TypeNotificationCreated  = "notification.created"
+	TypeSavingGoalMilestone  = "saving_goal.milestone_reached"
+	TypeSavingGoalCompleted  = "saving_goal.completed"
+	TypeSavingGoalBehind     = "saving_goal.behind"
+	TypeSpendingLimitCrossed = "spending_limit.crossed"
+	TypeSpendingLimitDigest  = "spending_limit.digest"
 )
 
 type Event struct {
@@ -16,3 +21,12 @@ type Event struct {
 type ReportPayload struct {
 	ReportID int64 `json:"report_id"`
 }
+
+type SavingGoalPayload struct {
+	GoalID      int64 `json:"goal_id"`
+	MilestoneID int64 `json:"milestone_id,omitempty"`
+}
+
+type SpendingLimitPayload struct {
+	LimitIDs []int64 `json:"limit_ids"`
+}
@@ -293,16 +293,22 @@ func (_c *MockTransactionServiceInterface_DeleteTransactionTemplate_Call) RunAnd
 }
 
 // DeleteTransfer provides a mock function for the type MockTransactionServiceInterface
-func (_mock *MockTransactionServiceInterface) DeleteTransfer(ctx context.Context, userID int64, id int64) error {
-	ret := _mock.Called(ctx, userID, id)
+func (_mock *MockTransactionServiceInterface) DeleteTransfer(ctx context.Context, userID int64, id int64, existingTx ...*gorm.DB) error {
+	var tmpRet mock.Arguments
+	if len(existingTx) > 0 {
+		tmpRet = _mock.Called(ctx, userID, id, existingTx)
+	} else {
+		tmpRet = _mock.Called(ctx, userID, id)
+	}
+	ret := tmpRet
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteTransfer")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = returnFunc(ctx, userID, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, ...*gorm.DB) error); ok {
+		r0 = returnFunc(ctx, userID, id, existingTx...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -318,11 +324,13 @@ type MockTransactionServiceInterface_DeleteTransfer_Call struct {
 //   - ctx context.Context
 //   - userID int64
 //   - id int64
-func (_e *MockTransactionServiceInterface_Expecter) DeleteTransfer(ctx interface{}, userID interface{}, id interface{}) *MockTransactionServiceInterface_DeleteTransfer_Call {
-	return &MockTransactionServiceInterface_DeleteTransfer_Call{Call: _e.mock.On("DeleteTransfer", ctx, userID, id)}
+//   - existingTx ...*gorm.DB
+func (_e *MockTransactionServiceInterface_Expecter) DeleteTransfer(ctx interface{}, userID interface{}, id interface{}, existingTx ...interface{}) *MockTransactionServiceInterface_DeleteTransfer_Call {
+	return &MockTransactionServiceInterface_DeleteTransfer_Call{Call: _e.mock.On("DeleteTransfer",
+		append([]interface{}{ctx, userID, id}, existingTx...)...)}
 }
 
-func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) Run(run func(ctx context.Context, userID int64, id int64)) *MockTransactionServiceInterface_DeleteTransfer_Call {
+func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) Run(run func(ctx context.Context, userID int64, id int64, existingTx ...*gorm.DB)) *MockTransactionServiceInterface_DeleteTransfer_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -336,10 +344,17 @@ func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) Run(run func(ctx
 		if args[2] != nil {
 			arg2 = args[2].(int64)
 		}
+		var arg3 []*gorm.DB
+		var variadicArgs []*gorm.DB
+		if len(args) > 3 {
+			variadicArgs = args[3].([]*gorm.DB)
+		}
+		arg3 = variadicArgs
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3...,
 		)
 	})
 	return _c
@@ -350,7 +365,7 @@ func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) Return(err error)
 	return _c
 }
 
-func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) RunAndReturn(run func(ctx context.Context, userID int64, id int64) error) *MockTransactionServiceInterface_DeleteTransfer_Call {
+func (_c *MockTransactionServiceInterface_DeleteTransfer_Call) RunAndReturn(run func(ctx context.Context, userID int64, id int64, existingTx ...*gorm.DB) error) *MockTransactionServiceInterface_DeleteTransfer_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1669,8 +1684,14 @@ func (_c *MockTransactionServiceInterface_InsertTransactionTemplate_Call) RunAnd
 }
 
 // InsertTransfer provides a mock function for the type MockTransactionServiceInterface
-func (_mock *MockTransactionServiceInterface) InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq) (models.InsertResult, error) {
-	ret := _mock.Called(ctx, userID, req)
+func (_mock *MockTransactionServiceInterface) InsertTransfer(ctx context.Context, userID int64, req *models.TransferReq, existingTx ...*gorm.DB) (models.InsertResult, error) {
+	var tmpRet mock.Arguments
+	if len(existingTx) > 0 {
+		tmpRet = _mock.Called(ctx, userID, req, existingTx)
+	} else {
+		tmpRet = _mock.Called(ctx, userID, req)
+	}
+	ret := tmpRet
 
 	if len(ret) == 0 {
 		panic("no return value specified for InsertTransfer")
@@ -1678,16 +1699,16 @@ func (_mock *MockTransactionServiceInterface) InsertTransfer(ctx context.Context
 
 	var r0 models.InsertResult
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, *models.TransferReq) (models.InsertResult, error)); ok {
-		return returnFunc(ctx, userID, req)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, *models.TransferReq, ...*gorm.DB) (models.InsertResult, error)); ok {
+		return returnFunc(ctx, userID, req, existingTx...)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, *models.TransferReq) models.InsertResult); ok {
-		r0 = returnFunc(ctx, userID, req)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, *models.TransferReq, ...*gorm.DB) models.InsertResult); ok {
+		r0 = returnFunc(ctx, userID, req, existingTx...)
 	} else {
 		r0 = ret.Get(0).(models.InsertResult)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, *models.TransferReq) error); ok {
-		r1 = returnFunc(ctx, userID, req)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, *models.TransferReq, ...*gorm.DB) error); ok {
+		r1 = returnFunc(ctx, userID, req, existingTx...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1703,11 +1724,13 @@ type MockTransactionServiceInterface_InsertTransfer_Call struct {
 //   - ctx context.Context
 //   - userID int64
 //   - req *models.TransferReq
-func (_e *MockTransactionServiceInterface_Expecter) InsertTransfer(ctx interface{}, userID interface{}, req interface{}) *MockTransactionServiceInterface_InsertTransfer_Call {
-	return &MockTransactionServiceInterface_InsertTransfer_Call{Call: _e.mock.On("InsertTransfer", ctx, userID, req)}
+//   - existingTx ...*gorm.DB
+func (_e *MockTransactionServiceInterface_Expecter) InsertTransfer(ctx interface{}, userID interface{}, req interface{}, existingTx ...interface{}) *MockTransactionServiceInterface_InsertTransfer_Call {
+	return &MockTransactionServiceInterface_InsertTransfer_Call{Call: _e.mock.On("InsertTransfer",
+		append([]interface{}{ctx, userID, req}, existingTx...)...)}
 }
 
-func (_c *MockTransactionServiceInterface_InsertTransfer_Call) Run(run func(ctx context.Context, userID int64, req *models.TransferReq)) *MockTransactionServiceInterface_InsertTransfer_Call {
+func (_c *MockTransactionServiceInterface_InsertTransfer_Call) Run(run func(ctx context.Context, userID int64, req *models.TransferReq, existingTx ...*gorm.DB)) *MockTransactionServiceInterface_InsertTransfer_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -1721,10 +1744,17 @@ func (_c *MockTransactionServiceInterface_InsertTransfer_Call) Run(run func(ctx
 		if args[2] != nil {
 			arg2 = args[2].(*models.TransferReq)
 		}
+		var arg3 []*gorm.DB
+		var variadicArgs []*gorm.DB
+		if len(args) > 3 {
+			variadicArgs = args[3].([]*gorm.DB)
+		}
+		arg3 = variadicArgs
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3...,
 		)
 	})
 	return _c
@@ -1735,7 +1765,7 @@ func (_c *MockTransactionServiceInterface_InsertTransfer_Call) Return(insertResu
 	return _c
 }
 
-func (_c *MockTransactionServiceInterface_InsertTransfer_Call) RunAndReturn(run func(ctx context.Context, userID int64, req *models.TransferReq) (models.InsertResult, error)) *MockTransactionServiceInterface_InsertTransfer_Call {
+func (_c *MockTransactionServiceInterface_InsertTransfer_Call) RunAndReturn(run func(ctx context.Context, userID int64, req *models.TransferReq, existingTx ...*gorm.DB) (models.InsertResult, error)) *MockTransactionServiceInterface_InsertTransfer_Call {
 	_c.Call.Return(run)
 	return _c
 }
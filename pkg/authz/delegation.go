@@ -0,0 +1,226 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CtxDelegationKey = "delegation"
+	// ActingForHeader carries the owner's user id when a delegate reads someone else's data.
+	ActingForHeader = "X-Acting-For"
+)
+
+var ErrNoDelegation = errors.New("no active delegation")
+
+// readOnlyPerms are the only permissions a delegate can inherit from the owner.
+var readOnlyPerms = []string{"view_data", "view_basic_statistics", "view_advanced_statistics"}
+
+// DelegationScope is an active grant of read access from OwnerID to DelegateID.
+// Empty Modules or AccountIDs mean no restriction on that axis.
+type DelegationScope struct {
+	ID         int64
+	OwnerID    int64
+	DelegateID int64
+	Modules    map[string]struct{}
+	AccountIDs map[int64]struct{}
+}
+
+func (d *DelegationScope) AllowsModule(module string) bool {
+	if len(d.Modules) == 0 {
+		return true
+	}
+	_, ok := d.Modules[module]
+	return ok
+}
+
+// AllowsAccount reports whether the delegate may read accountID. When the scope is
+// account-restricted, requests that don't name an account are refused, since an
+// unscoped listing would expose the other accounts.
+func (d *DelegationScope) AllowsAccount(accountID *int64) bool {
+	if len(d.AccountIDs) == 0 {
+		return true
+	}
+	if accountID == nil {
+		return false
+	}
+	_, ok := d.AccountIDs[*accountID]
+	return ok
+}
+
+// reportRoutes are the year-end report routes. Tax delegates need them to produce
+// and fetch the capital gains report, analytics delegates for every other report.
+var reportRoutes = map[string][]string{
+	"/api/analytics/reports":               {"analytics", "tax"},
+	"/api/analytics/reports/:id/download":  {"analytics", "tax"},
+	"/api/analytics/reports/capital-gains": {"tax"},
+}
+
+// delegateWriteRoutes are the only non-GET requests a delegate may send. Producing
+// a report changes nothing but the owner's report list.
+var delegateWriteRoutes = map[string]struct{}{
+	http.MethodPost + " /api/analytics/reports/capital-gains": {},
+}
+
+// ModulesForRoute maps a gin route pattern (c.FullPath()) to the delegation modules
+// that cover it. Routes outside every module cannot be used by a delegate.
+func ModulesForRoute(fullPath string) []string {
+	if modules, ok := reportRoutes[fullPath]; ok {
+		return modules
+	}
+
+	segs := strings.Split(strings.Trim(fullPath, "/"), "/")
+	if len(segs) > 0 && segs[0] == "api" {
+		segs = segs[1:]
+	}
+	if len(segs) == 0 {
+		return nil
+	}
+
+	switch segs[0] {
+	case "accounts", "interest":
+		return []string{"accounts"}
+	case "transactions":
+		return []string{"transactions"}
+	case "investments":
+		if len(segs) > 1 && strings.HasPrefix(segs[1], "tax-") {
+			return []string{"tax"}
+		}
+		return []string{"investments"}
+	case "analytics":
+		return []string{"analytics"}
+	case "savings":
+		return []string{"savings"}
+	}
+	return nil
+}
+
+// DelegateMayCall reports whether a delegate may send method to the route. Delegates
+// only read, with report generation as the one exception.
+func DelegateMayCall(method, fullPath string) bool {
+	if method == http.MethodGet {
+		return true
+	}
+	_, ok := delegateWriteRoutes[method+" "+fullPath]
+	return ok
+}
+
+// AllowsRoute reports whether any of the route's modules is within the scope.
+func (d *DelegationScope) AllowsRoute(fullPath string) bool {
+	for _, module := range ModulesForRoute(fullPath) {
+		if d.AllowsModule(module) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountParamRoutes are the routes whose :id param is an account id.
+var accountParamRoutes = map[string]struct{}{
+	"/api/accounts/:id":                  {},
+	"/api/accounts/balances/:id/latest":  {},
+	"/api/interest/accounts/:id":         {},
+	"/api/interest/accounts/:id/preview": {},
+}
+
+// accountQueryRoutes are the routes whose handlers narrow their results to the
+// account_id query parameter. Anywhere else the parameter is ignored, so trusting
+// it would let an account-restricted delegate list every account.
+var accountQueryRoutes = map[string]struct{}{
+	"/api/transactions":                     {},
+	"/api/transactions/transfers":           {},
+	"/api/analytics/categories/:id/average": {},
+}
+
+// AccountForRoute extracts the account a request targets, from an account route's :id
+// param or, on routes that filter by it, the account_id query parameter.
+func AccountForRoute(fullPath string, param func(string) string, query func(string) string) *int64 {
+	var raw string
+	if _, ok := accountParamRoutes[fullPath]; ok {
+		raw = param("id")
+	} else if _, ok := accountQueryRoutes[fullPath]; ok {
+		raw = query("account_id")
+	}
+	if raw == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// ActiveDelegation loads the latest unrevoked, unexpired delegation from ownerID to delegateID.
+func (s *Service) ActiveDelegation(ctx context.Context, ownerID, delegateID int64) (*DelegationScope, error) {
+	var row struct {
+		ID int64
+	}
+	err := s.DB.WithContext(ctx).
+		Table("access_delegations AS d").
+		Select("d.id AS id").
+		Where("d.owner_id = ? AND d.delegate_id = ?", ownerID, delegateID).
+		Where("d.revoked_at IS NULL AND d.expires_at > ?", time.Now().UTC()).
+		Order("d.id DESC").
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoDelegation
+		}
+		return nil, err
+	}
+
+	var modules []string
+	if err := s.DB.WithContext(ctx).
+		Table("access_delegation_modules").
+		Where("delegation_id = ?", row.ID).
+		Pluck("module", &modules).Error; err != nil {
+		return nil, err
+	}
+
+	var accountIDs []int64
+	if err := s.DB.WithContext(ctx).
+		Table("access_delegation_accounts").
+		Where("delegation_id = ?", row.ID).
+		Pluck("account_id", &accountIDs).Error; err != nil {
+		return nil, err
+	}
+
+	scope := &DelegationScope{
+		ID:         row.ID,
+		OwnerID:    ownerID,
+		DelegateID: delegateID,
+		Modules:    make(map[string]struct{}, len(modules)),
+		AccountIDs: make(map[int64]struct{}, len(accountIDs)),
+	}
+	for _, m := range modules {
+		scope.Modules[m] = struct{}{}
+	}
+	for _, id := range accountIDs {
+		scope.AccountIDs[id] = struct{}{}
+	}
+	return scope, nil
+}
+
+// DelegatePerms narrows the owner's permissions to the read-only subset a delegate may use.
+func (s *Service) DelegatePerms(ctx context.Context, ownerID int64) (map[string]struct{}, error) {
+	perms, err := s.PermsForUser(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, isRoot := perms["root_access"]
+	out := make(map[string]struct{}, len(readOnlyPerms))
+	for _, p := range readOnlyPerms {
+		if _, ok := perms[p]; ok || isRoot {
+			out[p] = struct{}{}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package authz_test
+
+import (
+	"net/http"
+	"testing"
+	"wealth-warden/pkg/authz"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func params(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestModulesForRoute(t *testing.T) {
+	cases := map[string][]string{
+		"/api/accounts/:id":                    {"accounts"},
+		"/api/interest/accounts/:id/preview":   {"accounts"},
+		"/api/transactions":                    {"transactions"},
+		"/api/investments/trades":              {"investments"},
+		"/api/investments/tax-settings":        {"tax"},
+		"/api/analytics/networth":              {"analytics"},
+		"/api/savings/:id":                     {"savings"},
+		"/api/analytics/reports/capital-gains": {"tax"},
+		"/api/analytics/reports/:id/download":  {"analytics", "tax"},
+	}
+	for path, want := range cases {
+		assert.Equal(t, want, authz.ModulesForRoute(path), path)
+	}
+}
+
+func TestModulesForRoute_NotDelegatable(t *testing.T) {
+	for _, path := range []string{"/api/settings", "/api/users/:id", "/api/delegations", "/api/households", ""} {
+		assert.Empty(t, authz.ModulesForRoute(path), path)
+	}
+}
+
+func TestDelegateMayCall(t *testing.T) {
+	assert.True(t, authz.DelegateMayCall(http.MethodGet, "/api/transactions"))
+	assert.True(t, authz.DelegateMayCall(http.MethodPost, "/api/analytics/reports/capital-gains"))
+	assert.False(t, authz.DelegateMayCall(http.MethodPost, "/api/analytics/reports/category"))
+	assert.False(t, authz.DelegateMayCall(http.MethodPost, "/api/transactions"))
+	assert.False(t, authz.DelegateMayCall(http.MethodDelete, "/api/analytics/reports/:id"))
+}
+
+func TestAccountForRoute(t *testing.T) {
+	id := authz.AccountForRoute("/api/accounts/:id", params(map[string]string{"id": "7"}), params(nil))
+	require.NotNil(t, id)
+	assert.Equal(t, int64(7), *id)
+
+	id = authz.AccountForRoute("/api/transactions", params(nil), params(map[string]string{"account_id": "3"}))
+	require.NotNil(t, id)
+	assert.Equal(t, int64(3), *id)
+
+	// :id on an asset route is not an account
+	assert.Nil(t, authz.AccountForRoute("/api/accounts/sync/asset/:id", params(map[string]string{"id": "7"}), params(nil)))
+	assert.Nil(t, authz.AccountForRoute("/api/transactions", params(nil), params(map[string]string{"account_id": "x"})))
+
+	// routes that ignore account_id don't get to claim one
+	for _, path := range []string{"/api/accounts", "/api/accounts/all", "/api/accounts/name/:name", "/api/accounts/type/:type", "/api/accounts/subtype/:sub", "/api/accounts/defaults/all"} {
+		assert.Nil(t, authz.AccountForRoute(path, params(nil), params(map[string]string{"account_id": "3"})), path)
+	}
+}
+
+func TestDelegationScope_Unrestricted(t *testing.T) {
+	scope := authz.DelegationScope{}
+	assert.True(t, scope.AllowsModule("investments"))
+	assert.True(t, scope.AllowsAccount(nil))
+}
+
+func TestDelegationScope_Restricted(t *testing.T) {
+	scope := authz.DelegationScope{
+		Modules:    map[string]struct{}{"tax": {}},
+		AccountIDs: map[int64]struct{}{5: {}},
+	}
+	assert.True(t, scope.AllowsModule("tax"))
+	assert.False(t, scope.AllowsModule("transactions"))
+
+	allowed, other := int64(5), int64(6)
+	assert.True(t, scope.AllowsAccount(&allowed))
+	assert.False(t, scope.AllowsAccount(&other))
+	// unscoped listings are refused when accounts are restricted
+	assert.False(t, scope.AllowsAccount(nil))
+
+	assert.True(t, scope.AllowsRoute("/api/analytics/reports/capital-gains"))
+	assert.True(t, scope.AllowsRoute("/api/analytics/reports/:id/download"))
+	assert.False(t, scope.AllowsRoute("/api/analytics/networth"))
+	assert.False(t, scope.AllowsRoute("/api/settings"))
+}
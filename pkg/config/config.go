@@ -15,6 +15,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("release", false)
 	v.SetDefault("finance_api_base_url", "")
 
+	v.SetDefault("price_providers.order", []string{"yahoo", "coingecko", "stooq", "ecb"})
+	v.SetDefault("price_providers.coingecko_base_url", "")
+	v.SetDefault("price_providers.stooq_base_url", "")
+	v.SetDefault("price_providers.ecb_base_url", "")
+	v.SetDefault("price_providers.rate_limit_cooldown_sec", 900)
+
 	v.SetDefault("http_server.port", "2000")
 	v.SetDefault("http_server.request_timeout", 60)
 
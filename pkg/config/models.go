@@ -1,20 +1,31 @@
 package config
 
 type Config struct {
-	Release           bool             `mapstructure:"release"`
-	FinanceAPIBaseURL string           `mapstructure:"finance_api_base_url"`
-	WebClient         WebClientConfig  `mapstructure:"web_client"`
-	HttpServer        HttpServerConfig `mapstructure:"http_server"`
-	Host              string           `mapstructure:"host"`
-	Postgres          PostgresConfig   `mapstructure:"postgres"`
-	Redis             RedisConfig      `mapstructure:"redis"`
-	Session           SessionConfig    `mapstructure:"session"`
-	CORS              CorsConfig       `mapstructure:"cors"`
-	Seed              SeedConfig       `mapstructure:"seed"`
-	Mailer            MailerConfig     `mapstructure:"mailer"`
-	Scheduler         SchedulerConfig  `mapstructure:"scheduler"`
-	Otel              OtelConfig       `mapstructure:"otel"`
-	Queue             QueueConfig      `mapstructure:"queue"`
+	Release           bool                 `mapstructure:"release"`
+	FinanceAPIBaseURL string               `mapstructure:"finance_api_base_url"`
+	PriceProviders    PriceProvidersConfig `mapstructure:"price_providers"`
+	WebClient         WebClientConfig      `mapstructure:"web_client"`
+	HttpServer        HttpServerConfig     `mapstructure:"http_server"`
+	Host              string               `mapstructure:"host"`
+	Postgres          PostgresConfig       `mapstructure:"postgres"`
+	Redis             RedisConfig          `mapstructure:"redis"`
+	Session           SessionConfig        `mapstructure:"session"`
+	CORS              CorsConfig           `mapstructure:"cors"`
+	Seed              SeedConfig           `mapstructure:"seed"`
+	Mailer            MailerConfig         `mapstructure:"mailer"`
+	Scheduler         SchedulerConfig      `mapstructure:"scheduler"`
+	Otel              OtelConfig           `mapstructure:"otel"`
+	Queue             QueueConfig          `mapstructure:"queue"`
+}
+
+// PriceProvidersConfig lists the price sources besides Yahoo (finance_api_base_url)
+// and the order they are tried in. A provider without a base URL is disabled.
+type PriceProvidersConfig struct {
+	Order                []string `mapstructure:"order"`
+	CoinGeckoBaseURL     string   `mapstructure:"coingecko_base_url"`
+	StooqBaseURL         string   `mapstructure:"stooq_base_url"`
+	ECBBaseURL           string   `mapstructure:"ecb_base_url"`
+	RateLimitCooldownSec int      `mapstructure:"rate_limit_cooldown_sec"`
 }
 
 type WebClientConfig struct {
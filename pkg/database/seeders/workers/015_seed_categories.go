@@ -31,7 +31,7 @@ func SeedCategories(ctx context.Context, db *gorm.DB, cfg *config.Config) error
 		{
 			Name:           "Income",
 			Classification: "income",
-			Children:       []string{"Salary", "Food and transport", "Bonus", "Side hustle", "Refunds", "Other"},
+			Children:       []string{"Salary", "Food and transport", "Bonus", "Side hustle", "Refunds", "Interest", "Other"},
 		},
 		{
 			Name:           "Expense",
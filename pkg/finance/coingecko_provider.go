@@ -0,0 +1,193 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+)
+
+// coinGeckoIDs maps ticker symbols to CoinGecko coin ids. Symbols are not unique
+// on CoinGecko, so only well-known coins are resolved.
+var coinGeckoIDs = map[string]string{
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"SOL":   "solana",
+	"ADA":   "cardano",
+	"XRP":   "ripple",
+	"DOT":   "polkadot",
+	"DOGE":  "dogecoin",
+	"LTC":   "litecoin",
+	"BNB":   "binancecoin",
+	"AVAX":  "avalanche-2",
+	"LINK":  "chainlink",
+	"MATIC": "matic-network",
+	"ATOM":  "cosmos",
+	"XLM":   "stellar",
+	"USDT":  "tether",
+	"USDC":  "usd-coin",
+}
+
+// CoinGeckoProvider prices crypto through a CoinGecko-style API.
+type CoinGeckoProvider struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+var _ PriceProvider = (*CoinGeckoProvider)(nil)
+
+func NewCoinGeckoProvider(baseURL string) *CoinGeckoProvider {
+	return &CoinGeckoProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (c *CoinGeckoProvider) Name() string {
+	return ProviderCoinGecko
+}
+
+// coinFor splits a BTC-USD style ticker into the coin id and quote currency.
+func (c *CoinGeckoProvider) coinFor(ticker string, investmentType models.InvestmentType) (string, string, error) {
+	if investmentType != models.InvestmentCrypto {
+		return "", "", ErrUnsupported
+	}
+
+	base, quote, found := strings.Cut(strings.ToUpper(strings.TrimSpace(ticker)), "-")
+	if !found || quote == "" {
+		quote = "USD"
+	}
+	id, ok := coinGeckoIDs[base]
+	if !ok {
+		return "", "", fmt.Errorf("unknown coin %q: %w", base, ErrUnsupported)
+	}
+	return id, quote, nil
+}
+
+func (c *CoinGeckoProvider) get(ctx context.Context, path string, query url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("coingecko: %w", ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *CoinGeckoProvider) GetAssetPrice(ctx context.Context, ticker string, investmentType models.InvestmentType) (*PriceData, error) {
+	id, quote, err := c.coinFor(ticker, investmentType)
+	if err != nil {
+		return nil, err
+	}
+	vs := strings.ToLower(quote)
+
+	var data map[string]map[string]float64
+	err = c.get(ctx, "/api/v3/simple/price", url.Values{
+		"ids":                     {id},
+		"vs_currencies":           {vs},
+		"include_last_updated_at": {"true"},
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	price := data[id][vs]
+	if price <= 0 {
+		return nil, fmt.Errorf("no price found for '%s'", ticker)
+	}
+
+	return &PriceData{
+		Symbol:     strings.ToUpper(ticker),
+		Price:      price,
+		Currency:   quote,
+		LastUpdate: int64(data[id]["last_updated_at"]),
+	}, nil
+}
+
+func (c *CoinGeckoProvider) GetAssetPriceOnDate(ctx context.Context, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
+	id, quote, err := c.coinFor(ticker, investmentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		MarketData struct {
+			CurrentPrice map[string]float64 `json:"current_price"`
+		} `json:"market_data"`
+	}
+	err = c.get(ctx, "/api/v3/coins/"+id+"/history", url.Values{
+		"date":         {date.UTC().Format("02-01-2006")},
+		"localization": {"false"},
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	price := data.MarketData.CurrentPrice[strings.ToLower(quote)]
+	if price <= 0 {
+		return nil, fmt.Errorf("no price found for '%s' on %s", ticker, date.Format("2006-01-02"))
+	}
+
+	return &PriceData{
+		Symbol:     strings.ToUpper(ticker),
+		Price:      price,
+		Currency:   quote,
+		LastUpdate: date.UTC().Truncate(24 * time.Hour).Unix(),
+	}, nil
+}
+
+func (c *CoinGeckoProvider) GetPricesForMultipleAssets(ctx context.Context, assets []AssetRequest) (map[string]*PriceData, error) {
+	result := make(map[string]*PriceData)
+	for _, asset := range assets {
+		if asset.InvestmentType != models.InvestmentCrypto {
+			continue
+		}
+		currency := strings.ToUpper(strings.TrimSpace(asset.Currency))
+		if currency == "" {
+			currency = "USD"
+		}
+		symbol := fmt.Sprintf("%s-%s", strings.ToUpper(strings.TrimSpace(asset.Ticker)), currency)
+
+		data, err := c.GetAssetPrice(ctx, symbol, asset.InvestmentType)
+		if err != nil {
+			result[symbol] = &PriceData{Symbol: symbol, Error: err}
+			continue
+		}
+		result[symbol] = data
+	}
+	if len(result) == 0 {
+		return nil, ErrUnsupported
+	}
+	return result, nil
+}
+
+func (c *CoinGeckoProvider) GetExchangeRate(_ context.Context, _, _ string) (float64, error) {
+	return 0, ErrUnsupported
+}
+
+func (c *CoinGeckoProvider) GetExchangeRateOnDate(_ context.Context, _, _ string, _ time.Time) (float64, error) {
+	return 0, ErrUnsupported
+}
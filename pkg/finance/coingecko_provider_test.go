@@ -0,0 +1,108 @@
+package finance_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/finance"
+)
+
+func newCoinGeckoServer(t *testing.T, status int, body string) *finance.CoinGeckoProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return finance.NewCoinGeckoProvider(srv.URL)
+}
+
+func TestCoinGecko_GetAssetPrice(t *testing.T) {
+	var gotPath, gotIDs, gotVs string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotIDs = r.URL.Query().Get("ids")
+		gotVs = r.URL.Query().Get("vs_currencies")
+		_, _ = w.Write([]byte(`{"bitcoin":{"eur":45000.5,"last_updated_at":1700000000}}`))
+	}))
+	defer srv.Close()
+
+	provider := finance.NewCoinGeckoProvider(srv.URL)
+	price, err := provider.GetAssetPrice(context.Background(), "BTC-EUR", models.InvestmentCrypto)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotPath != "/api/v3/simple/price" || gotIDs != "bitcoin" || gotVs != "eur" {
+		t.Errorf("Unexpected request: path=%s ids=%s vs=%s", gotPath, gotIDs, gotVs)
+	}
+	if price.Price != 45000.5 {
+		t.Errorf("Expected price 45000.5, got %f", price.Price)
+	}
+	if price.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, got %s", price.Currency)
+	}
+	if price.LastUpdate != 1700000000 {
+		t.Errorf("Expected last update 1700000000, got %d", price.LastUpdate)
+	}
+}
+
+func TestCoinGecko_GetAssetPriceOnDate(t *testing.T) {
+	var gotPath, gotDate string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotDate = r.URL.Query().Get("date")
+		_, _ = w.Write([]byte(`{"id":"ethereum","market_data":{"current_price":{"usd":2100.25}}}`))
+	}))
+	defer srv.Close()
+
+	provider := finance.NewCoinGeckoProvider(srv.URL)
+	date := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	price, err := provider.GetAssetPriceOnDate(context.Background(), "ETH-USD", models.InvestmentCrypto, date)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotPath != "/api/v3/coins/ethereum/history" || gotDate != "09-03-2024" {
+		t.Errorf("Unexpected request: path=%s date=%s", gotPath, gotDate)
+	}
+	if price.Price != 2100.25 {
+		t.Errorf("Expected price 2100.25, got %f", price.Price)
+	}
+}
+
+func TestCoinGecko_UnsupportedRequests(t *testing.T) {
+	provider := newCoinGeckoServer(t, http.StatusOK, `{}`)
+	ctx := context.Background()
+
+	if _, err := provider.GetAssetPrice(ctx, "AAPL", models.InvestmentStock); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for a stock, got: %v", err)
+	}
+	if _, err := provider.GetAssetPrice(ctx, "UNKNOWNCOIN-USD", models.InvestmentCrypto); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for an unknown coin, got: %v", err)
+	}
+	if _, err := provider.GetExchangeRate(ctx, "EUR", "USD"); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for FX, got: %v", err)
+	}
+}
+
+func TestCoinGecko_RateLimited(t *testing.T) {
+	provider := newCoinGeckoServer(t, http.StatusTooManyRequests, ``)
+
+	_, err := provider.GetAssetPrice(context.Background(), "BTC-USD", models.InvestmentCrypto)
+	if !errors.Is(err, finance.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestCoinGecko_MissingPrice(t *testing.T) {
+	provider := newCoinGeckoServer(t, http.StatusOK, `{"bitcoin":{}}`)
+
+	if _, err := provider.GetAssetPrice(context.Background(), "BTC-USD", models.InvestmentCrypto); err == nil {
+		t.Error("Expected error for a missing price")
+	}
+}
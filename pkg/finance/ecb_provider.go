@@ -0,0 +1,194 @@
+package finance
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wealth-warden/internal/models"
+)
+
+const (
+	ecbDailyPath  = "/stats/eurofxref/eurofxref-daily.xml"
+	ecb90DaysPath = "/stats/eurofxref/eurofxref-hist-90d.xml"
+	ecbHistPath   = "/stats/eurofxref/eurofxref-hist.xml"
+
+	// ECB publishes once per working day; a fetched feed is reused until it's stale.
+	ecbCacheTTL = 6 * time.Hour
+)
+
+// ecbEnvelope is the eurofxref feed: one Cube per day holding a Cube per currency,
+// each rate being units of currency per euro.
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+type ecbDay struct {
+	date  time.Time
+	rates map[string]float64
+}
+
+type ecbFeed struct {
+	days      []ecbDay // oldest first
+	fetchedAt time.Time
+}
+
+// ECBProvider serves exchange rates from an ECB-style euro reference rate feed.
+type ECBProvider struct {
+	httpClient *http.Client
+	baseURL    string
+
+	mu    sync.Mutex
+	feeds map[string]ecbFeed
+}
+
+var _ PriceProvider = (*ECBProvider)(nil)
+
+func NewECBProvider(baseURL string) *ECBProvider {
+	return &ECBProvider{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		feeds:      make(map[string]ecbFeed),
+	}
+}
+
+func (c *ECBProvider) Name() string {
+	return ProviderECB
+}
+
+func (c *ECBProvider) feed(ctx context.Context, path string) ([]ecbDay, error) {
+	c.mu.Lock()
+	cached, ok := c.feeds[path]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < ecbCacheTTL {
+		return cached.days, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("ecb: %w", ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ecb returned status %d", resp.StatusCode)
+	}
+
+	var env ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	days := make([]ecbDay, 0, len(env.Cube.Days))
+	for _, d := range env.Cube.Days {
+		date, err := time.Parse("2006-01-02", d.Time)
+		if err != nil {
+			continue
+		}
+		rates := map[string]float64{"EUR": 1}
+		for _, r := range d.Rates {
+			if r.Rate > 0 {
+				rates[strings.ToUpper(r.Currency)] = r.Rate
+			}
+		}
+		days = append(days, ecbDay{date: date, rates: rates})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].date.Before(days[j].date) })
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no exchange rate data returned")
+	}
+
+	c.mu.Lock()
+	c.feeds[path] = ecbFeed{days: days, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return days, nil
+}
+
+// cross converts through the euro: both legs are quoted per euro.
+func (c *ECBProvider) cross(day ecbDay, fromCurrency, toCurrency string) (float64, error) {
+	from, ok := day.rates[fromCurrency]
+	if !ok {
+		return 0, fmt.Errorf("currency %s: %w", fromCurrency, ErrUnsupported)
+	}
+	to, ok := day.rates[toCurrency]
+	if !ok {
+		return 0, fmt.Errorf("currency %s: %w", toCurrency, ErrUnsupported)
+	}
+	return to / from, nil
+}
+
+func (c *ECBProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	fromCurrency, toCurrency = strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency)
+	if fromCurrency == toCurrency {
+		return 1.0, nil
+	}
+
+	days, err := c.feed(ctx, ecbDailyPath)
+	if err != nil {
+		return 0, err
+	}
+	return c.cross(days[len(days)-1], fromCurrency, toCurrency)
+}
+
+// GetExchangeRateOnDate uses the last reference rate published on or before date,
+// so weekends and TARGET holidays carry the previous fixing.
+func (c *ECBProvider) GetExchangeRateOnDate(ctx context.Context, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	fromCurrency, toCurrency = strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency)
+	if fromCurrency == toCurrency {
+		return 1.0, nil
+	}
+
+	date = date.UTC().Truncate(24 * time.Hour)
+	path := ecbHistPath
+	if time.Since(date) < 85*24*time.Hour {
+		path = ecb90DaysPath
+	}
+
+	days, err := c.feed(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+
+	i := sort.Search(len(days), func(i int) bool { return days[i].date.After(date) })
+	if i == 0 {
+		return 0, fmt.Errorf("no exchange rate published on or before %s", date.Format("2006-01-02"))
+	}
+	return c.cross(days[i-1], fromCurrency, toCurrency)
+}
+
+func (c *ECBProvider) GetAssetPrice(_ context.Context, _ string, _ models.InvestmentType) (*PriceData, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *ECBProvider) GetAssetPriceOnDate(_ context.Context, _ string, _ models.InvestmentType, _ time.Time) (*PriceData, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *ECBProvider) GetPricesForMultipleAssets(_ context.Context, _ []AssetRequest) (map[string]*PriceData, error) {
+	return nil, ErrUnsupported
+}
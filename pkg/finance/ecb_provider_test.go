@@ -0,0 +1,126 @@
+package finance_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/finance"
+)
+
+const ecbHistXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-01-05">
+			<Cube currency="USD" rate="1.0921"/>
+			<Cube currency="GBP" rate="0.8612"/>
+		</Cube>
+		<Cube time="2024-01-04">
+			<Cube currency="USD" rate="1.0953"/>
+			<Cube currency="GBP" rate="0.8630"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func newECBServer(t *testing.T, hits *int) *finance.ECBProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits != nil {
+			*hits++
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(ecbHistXML))
+	}))
+	t.Cleanup(srv.Close)
+	return finance.NewECBProvider(srv.URL)
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestECB_GetExchangeRate_FromEuro(t *testing.T) {
+	rate, err := newECBServer(t, nil).GetExchangeRate(context.Background(), "EUR", "USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1.0921) {
+		t.Errorf("Expected 1.0921, got %f", rate)
+	}
+}
+
+func TestECB_GetExchangeRate_CrossThroughEuro(t *testing.T) {
+	provider := newECBServer(t, nil)
+
+	rate, err := provider.GetExchangeRate(context.Background(), "USD", "EUR")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1/1.0921) {
+		t.Errorf("Expected %f, got %f", 1/1.0921, rate)
+	}
+
+	rate, err = provider.GetExchangeRate(context.Background(), "GBP", "USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1.0921/0.8612) {
+		t.Errorf("Expected %f, got %f", 1.0921/0.8612, rate)
+	}
+}
+
+func TestECB_GetExchangeRateOnDate_UsesLastFixing(t *testing.T) {
+	provider := newECBServer(t, nil)
+
+	// Saturday carries Friday's fixing
+	rate, err := provider.GetExchangeRateOnDate(context.Background(), "EUR", "USD", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1.0921) {
+		t.Errorf("Expected 1.0921, got %f", rate)
+	}
+
+	rate, err = provider.GetExchangeRateOnDate(context.Background(), "EUR", "USD", time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1.0953) {
+		t.Errorf("Expected 1.0953, got %f", rate)
+	}
+
+	if _, err := provider.GetExchangeRateOnDate(context.Background(), "EUR", "USD", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("Expected error before the first fixing")
+	}
+}
+
+func TestECB_FeedIsCached(t *testing.T) {
+	hits := 0
+	provider := newECBServer(t, &hits)
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.GetExchangeRate(context.Background(), "EUR", "GBP"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Expected one request, got %d", hits)
+	}
+}
+
+func TestECB_UnsupportedRequests(t *testing.T) {
+	provider := newECBServer(t, nil)
+	ctx := context.Background()
+
+	if _, err := provider.GetExchangeRate(ctx, "EUR", "XYZ"); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for an unknown currency, got: %v", err)
+	}
+	if _, err := provider.GetAssetPrice(ctx, "AAPL", models.InvestmentStock); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for a stock, got: %v", err)
+	}
+}
@@ -62,6 +62,18 @@ func NewPriceFetchClient(baseURL string) (PriceFetcher, error) {
 	}, nil
 }
 
+// newYahooProvider is the Yahoo Finance client as a registry provider.
+func newYahooProvider(baseURL string) *PriceFetchClient {
+	return &PriceFetchClient{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    baseURL,
+	}
+}
+
+func (c *PriceFetchClient) Name() string {
+	return ProviderYahoo
+}
+
 func (c *PriceFetchClient) normalizeExchange(exchange string) string {
 	if exchange == "" {
 		return ""
@@ -81,7 +93,7 @@ func (c *PriceFetchClient) GetAssetPrice(ctx context.Context, ticker string, inv
 	ticker = strings.ToUpper(strings.TrimSpace(ticker))
 
 	query := "interval=1d&range=1d"
-	if investmentType == models.InvestmentStock || investmentType == models.InvestmentETF {
+	if investmentType != models.InvestmentCrypto {
 		query = "interval=1d&range=7d"
 	}
 
@@ -105,6 +117,9 @@ func (c *PriceFetchClient) GetAssetPrice(ctx context.Context, ticker string, inv
 		}
 	}(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("yahoo finance: %w", ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("ticker '%s' not found on Yahoo Finance (status %d)", ticker, resp.StatusCode)
 	}
@@ -165,7 +180,7 @@ func (c *PriceFetchClient) GetAssetPrice(ctx context.Context, ticker string, inv
 func (c *PriceFetchClient) GetAssetPriceOnDate(ctx context.Context, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
 	ticker = strings.ToUpper(strings.TrimSpace(ticker))
 
-	if investmentType == models.InvestmentStock || investmentType == models.InvestmentETF {
+	if investmentType != models.InvestmentCrypto {
 		date = utils.AdjustToWeekday(date)
 	}
 
@@ -199,6 +214,9 @@ func (c *PriceFetchClient) GetAssetPriceOnDate(ctx context.Context, ticker strin
 		}
 	}(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("yahoo finance: %w", ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("ticker '%s' not found on Yahoo Finance (status %d)", ticker, resp.StatusCode)
 	}
@@ -313,6 +331,9 @@ func (c *PriceFetchClient) GetPricesForMultipleAssets(ctx context.Context, asset
 		}
 	}(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("yahoo finance: %w", ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("yahoo finance returned status %d", resp.StatusCode)
 	}
@@ -405,6 +426,9 @@ func (c *PriceFetchClient) fetchYahooRate(ctx context.Context, symbol string) (f
 		_ = Body.Close()
 	}(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return 0, fmt.Errorf("yahoo finance: %w", ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return 0, fmt.Errorf("failed to get exchange rate for %s (status %d)", symbol, resp.StatusCode)
 	}
@@ -483,6 +507,9 @@ func (c *PriceFetchClient) GetExchangeRateOnDate(ctx context.Context, fromCurren
 			if err != nil {
 				return 0, err
 			}
+			if resp.StatusCode == http.StatusTooManyRequests {
+				return 0, fmt.Errorf("yahoo finance: %w", ErrRateLimited)
+			}
 			continue
 		}
 
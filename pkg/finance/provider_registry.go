@@ -0,0 +1,269 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/config"
+)
+
+const (
+	ProviderYahoo     = "yahoo"
+	ProviderCoinGecko = "coingecko"
+	ProviderStooq     = "stooq"
+	ProviderECB       = "ecb"
+)
+
+// DefaultProviderOrder is the fallback order used when none is configured.
+var DefaultProviderOrder = []string{ProviderYahoo, ProviderCoinGecko, ProviderStooq, ProviderECB}
+
+var (
+	// ErrUnsupported is returned by a provider that can't serve the request at all,
+	// e.g. an FX feed asked for a stock price. The registry skips it silently.
+	ErrUnsupported = errors.New("not supported by provider")
+	// ErrRateLimited is returned when the upstream throttles us. The registry rests
+	// the provider for a cooldown instead of hammering it for every asset.
+	ErrRateLimited = errors.New("rate limited by provider")
+)
+
+// PriceProvider is one upstream source of prices and exchange rates.
+type PriceProvider interface {
+	PriceFetcher
+	Name() string
+}
+
+// ProviderRegistry is a PriceFetcher that tries its providers in order until one
+// answers.
+type ProviderRegistry struct {
+	providers map[string]PriceProvider
+	order     []string
+	cooldown  time.Duration
+
+	mu           sync.Mutex
+	limitedUntil map[string]time.Time
+}
+
+var _ PriceFetcher = (*ProviderRegistry)(nil)
+
+// NewProviderRegistry builds a registry over providers. Names in order that have
+// no provider are ignored; providers missing from order are tried last.
+func NewProviderRegistry(order []string, cooldown time.Duration, providers ...PriceProvider) (*ProviderRegistry, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one price provider is required")
+	}
+
+	r := &ProviderRegistry{
+		providers:    make(map[string]PriceProvider, len(providers)),
+		cooldown:     cooldown,
+		limitedUntil: make(map[string]time.Time),
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range order {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := r.providers[name]; ok && !seen[name] {
+			r.order = append(r.order, name)
+			seen[name] = true
+		}
+	}
+	for _, p := range providers {
+		if !seen[p.Name()] {
+			r.order = append(r.order, p.Name())
+			seen[p.Name()] = true
+		}
+	}
+
+	return r, nil
+}
+
+// NewPriceFetcher builds the registry from config. Providers without a base URL
+// are left out.
+func NewPriceFetcher(cfg *config.Config) (PriceFetcher, error) {
+	pc := cfg.PriceProviders
+
+	var providers []PriceProvider
+	if cfg.FinanceAPIBaseURL != "" {
+		providers = append(providers, newYahooProvider(cfg.FinanceAPIBaseURL))
+	}
+	if pc.CoinGeckoBaseURL != "" {
+		providers = append(providers, NewCoinGeckoProvider(pc.CoinGeckoBaseURL))
+	}
+	if pc.StooqBaseURL != "" {
+		providers = append(providers, NewStooqProvider(pc.StooqBaseURL))
+	}
+	if pc.ECBBaseURL != "" {
+		providers = append(providers, NewECBProvider(pc.ECBBaseURL))
+	}
+
+	order := pc.Order
+	if len(order) == 0 {
+		order = DefaultProviderOrder
+	}
+
+	registry, err := NewProviderRegistry(order, time.Duration(pc.RateLimitCooldownSec)*time.Second, providers...)
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Providers lists the registered provider names in fallback order.
+func (r *ProviderRegistry) Providers() []string {
+	return append([]string(nil), r.order...)
+}
+
+// WithProvider returns a fetcher that asks the named provider first and falls
+// back to the registry order after it.
+func (r *ProviderRegistry) WithProvider(name string) PriceFetcher {
+	return &routedFetcher{registry: r, preferred: strings.ToLower(strings.TrimSpace(name))}
+}
+
+// ForProvider routes f to the given provider when f is a registry and a provider
+// is set; any other fetcher is returned unchanged.
+func ForProvider(f PriceFetcher, provider *string) PriceFetcher {
+	if provider == nil || *provider == "" {
+		return f
+	}
+	if r, ok := f.(*ProviderRegistry); ok {
+		return r.WithProvider(*provider)
+	}
+	return f
+}
+
+func (r *ProviderRegistry) chain(preferred string) []PriceProvider {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := r.order
+	if _, ok := r.providers[preferred]; ok {
+		names = append([]string{preferred}, names...)
+	}
+
+	seen := make(map[string]bool)
+	var chain []PriceProvider
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if until, ok := r.limitedUntil[name]; ok && now.Before(until) {
+			continue
+		}
+		chain = append(chain, r.providers[name])
+	}
+	return chain
+}
+
+func (r *ProviderRegistry) markLimited(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limitedUntil[name] = time.Now().Add(r.cooldown)
+}
+
+// try runs call against each provider in the chain and returns the first success.
+func try[T any](r *ProviderRegistry, preferred string, call func(p PriceProvider) (T, error)) (T, error) {
+	var (
+		zero T
+		errs []error
+	)
+	for _, p := range r.chain(preferred) {
+		res, err := call(p)
+		if err == nil {
+			return res, nil
+		}
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		if errors.Is(err, ErrRateLimited) {
+			r.markLimited(p.Name())
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	if len(errs) == 0 {
+		return zero, fmt.Errorf("no price provider available: %w", ErrUnsupported)
+	}
+	return zero, errors.Join(errs...)
+}
+
+func (r *ProviderRegistry) getAssetPrice(ctx context.Context, preferred, ticker string, investmentType models.InvestmentType) (*PriceData, error) {
+	return try(r, preferred, func(p PriceProvider) (*PriceData, error) {
+		return p.GetAssetPrice(ctx, ticker, investmentType)
+	})
+}
+
+func (r *ProviderRegistry) getAssetPriceOnDate(ctx context.Context, preferred, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
+	return try(r, preferred, func(p PriceProvider) (*PriceData, error) {
+		return p.GetAssetPriceOnDate(ctx, ticker, investmentType, date)
+	})
+}
+
+func (r *ProviderRegistry) getPricesForMultipleAssets(ctx context.Context, preferred string, assets []AssetRequest) (map[string]*PriceData, error) {
+	return try(r, preferred, func(p PriceProvider) (map[string]*PriceData, error) {
+		return p.GetPricesForMultipleAssets(ctx, assets)
+	})
+}
+
+func (r *ProviderRegistry) getExchangeRate(ctx context.Context, preferred, fromCurrency, toCurrency string) (float64, error) {
+	return try(r, preferred, func(p PriceProvider) (float64, error) {
+		return p.GetExchangeRate(ctx, fromCurrency, toCurrency)
+	})
+}
+
+func (r *ProviderRegistry) getExchangeRateOnDate(ctx context.Context, preferred, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	return try(r, preferred, func(p PriceProvider) (float64, error) {
+		return p.GetExchangeRateOnDate(ctx, fromCurrency, toCurrency, date)
+	})
+}
+
+func (r *ProviderRegistry) GetAssetPrice(ctx context.Context, ticker string, investmentType models.InvestmentType) (*PriceData, error) {
+	return r.getAssetPrice(ctx, "", ticker, investmentType)
+}
+
+func (r *ProviderRegistry) GetAssetPriceOnDate(ctx context.Context, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
+	return r.getAssetPriceOnDate(ctx, "", ticker, investmentType, date)
+}
+
+func (r *ProviderRegistry) GetPricesForMultipleAssets(ctx context.Context, assets []AssetRequest) (map[string]*PriceData, error) {
+	return r.getPricesForMultipleAssets(ctx, "", assets)
+}
+
+func (r *ProviderRegistry) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	return r.getExchangeRate(ctx, "", fromCurrency, toCurrency)
+}
+
+func (r *ProviderRegistry) GetExchangeRateOnDate(ctx context.Context, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	return r.getExchangeRateOnDate(ctx, "", fromCurrency, toCurrency, date)
+}
+
+type routedFetcher struct {
+	registry  *ProviderRegistry
+	preferred string
+}
+
+func (f *routedFetcher) GetAssetPrice(ctx context.Context, ticker string, investmentType models.InvestmentType) (*PriceData, error) {
+	return f.registry.getAssetPrice(ctx, f.preferred, ticker, investmentType)
+}
+
+func (f *routedFetcher) GetAssetPriceOnDate(ctx context.Context, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
+	return f.registry.getAssetPriceOnDate(ctx, f.preferred, ticker, investmentType, date)
+}
+
+func (f *routedFetcher) GetPricesForMultipleAssets(ctx context.Context, assets []AssetRequest) (map[string]*PriceData, error) {
+	return f.registry.getPricesForMultipleAssets(ctx, f.preferred, assets)
+}
+
+func (f *routedFetcher) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	return f.registry.getExchangeRate(ctx, f.preferred, fromCurrency, toCurrency)
+}
+
+func (f *routedFetcher) GetExchangeRateOnDate(ctx context.Context, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	return f.registry.getExchangeRateOnDate(ctx, f.preferred, fromCurrency, toCurrency, date)
+}
@@ -0,0 +1,179 @@
+package finance_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/config"
+	"wealth-warden/pkg/finance"
+)
+
+// standIn serves a fixed response and counts the requests it gets.
+type standIn struct {
+	*httptest.Server
+	hits int
+}
+
+func newStandIn(t *testing.T, status int, body string) *standIn {
+	t.Helper()
+	s := &standIn{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func yahooProvider(t *testing.T, url string) finance.PriceProvider {
+	t.Helper()
+	client, err := finance.NewPriceFetchClient(url)
+	if err != nil {
+		t.Fatalf("Failed to create yahoo client: %v", err)
+	}
+	return client.(finance.PriceProvider)
+}
+
+const stooqAAPL = "Symbol,Date,Time,Close\nAAPL.US,2024-01-05,22:00:07,181.18\n"
+
+func TestRegistry_FallsBackWhenYahooRateLimits(t *testing.T) {
+	yahoo := newStandIn(t, http.StatusTooManyRequests, "")
+	stooq := newStandIn(t, http.StatusOK, stooqAAPL)
+
+	registry, err := finance.NewProviderRegistry(
+		[]string{finance.ProviderYahoo, finance.ProviderStooq},
+		time.Hour,
+		yahooProvider(t, yahoo.URL),
+		finance.NewStooqProvider(stooq.URL),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		price, err := registry.GetAssetPrice(context.Background(), "AAPL", models.InvestmentStock)
+		if err != nil {
+			t.Fatalf("Expected fallback price, got: %v", err)
+		}
+		if price.Price != 181.18 {
+			t.Errorf("Expected price 181.18, got %f", price.Price)
+		}
+	}
+
+	// Yahoo rests for the cooldown after the first 429
+	if yahoo.hits != 1 {
+		t.Errorf("Expected yahoo to be asked once, got %d", yahoo.hits)
+	}
+	if stooq.hits != 3 {
+		t.Errorf("Expected stooq to be asked 3 times, got %d", stooq.hits)
+	}
+}
+
+func TestRegistry_PinnedProviderGoesFirst(t *testing.T) {
+	yahoo := newStandIn(t, http.StatusInternalServerError, "")
+	stooq := newStandIn(t, http.StatusOK, stooqAAPL)
+
+	registry, err := finance.NewProviderRegistry(
+		finance.DefaultProviderOrder,
+		time.Hour,
+		yahooProvider(t, yahoo.URL),
+		finance.NewStooqProvider(stooq.URL),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	pinned := finance.ProviderStooq
+	if _, err := finance.ForProvider(registry, &pinned).GetAssetPrice(context.Background(), "AAPL", models.InvestmentStock); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if yahoo.hits != 0 {
+		t.Errorf("Expected yahoo to be skipped, got %d hits", yahoo.hits)
+	}
+}
+
+func TestRegistry_SkipsUnsupportedProviders(t *testing.T) {
+	ecb := newStandIn(t, http.StatusOK, ecbHistXML)
+	coingecko := newStandIn(t, http.StatusOK, `{}`)
+
+	registry, err := finance.NewProviderRegistry(
+		[]string{finance.ProviderCoinGecko, finance.ProviderECB},
+		time.Hour,
+		finance.NewCoinGeckoProvider(coingecko.URL),
+		finance.NewECBProvider(ecb.URL),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rate, err := registry.GetExchangeRate(context.Background(), "EUR", "USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !approx(rate, 1.0921) {
+		t.Errorf("Expected 1.0921, got %f", rate)
+	}
+	if coingecko.hits != 0 {
+		t.Errorf("Expected coingecko not to be called for FX, got %d hits", coingecko.hits)
+	}
+
+	if _, err := registry.GetAssetPrice(context.Background(), "AAPL", models.InvestmentStock); err == nil {
+		t.Error("Expected error when no provider serves stocks")
+	}
+}
+
+func TestRegistry_AllProvidersFail(t *testing.T) {
+	yahoo := newStandIn(t, http.StatusNotFound, "")
+	stooq := newStandIn(t, http.StatusOK, "Symbol,Date,Time,Close\nXYZ.US,N/D,N/D,N/D\n")
+
+	registry, err := finance.NewProviderRegistry(nil, time.Hour,
+		yahooProvider(t, yahoo.URL),
+		finance.NewStooqProvider(stooq.URL),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := registry.GetAssetPrice(context.Background(), "XYZ", models.InvestmentStock); err == nil {
+		t.Error("Expected error when every provider fails")
+	}
+	if yahoo.hits != 1 || stooq.hits != 1 {
+		t.Errorf("Expected each provider to be asked once, got yahoo=%d stooq=%d", yahoo.hits, stooq.hits)
+	}
+}
+
+func TestNewPriceFetcher_OnlyConfiguredProviders(t *testing.T) {
+	fetcher, err := finance.NewPriceFetcher(&config.Config{
+		FinanceAPIBaseURL: "http://yahoo.local",
+		PriceProviders: config.PriceProvidersConfig{
+			Order:        []string{"ecb", "yahoo"},
+			ECBBaseURL:   "http://ecb.local",
+			StooqBaseURL: "http://stooq.local",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	got := fetcher.(*finance.ProviderRegistry).Providers()
+	want := []string{"ecb", "yahoo", "stooq"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected providers %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected providers %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestNewPriceFetcher_NoProviders(t *testing.T) {
+	if _, err := finance.NewPriceFetcher(&config.Config{}); err == nil {
+		t.Error("Expected error without any provider configured")
+	}
+}
@@ -0,0 +1,250 @@
+package finance
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+)
+
+// stooqMarkets maps Yahoo exchange suffixes to Stooq's market suffix and the
+// currency the market quotes in.
+var stooqMarkets = map[string]struct {
+	suffix   string
+	currency string
+}{
+	"":   {"us", "USD"},
+	"DE": {"de", "EUR"},
+	"F":  {"de", "EUR"},
+	"L":  {"uk", "GBp"},
+}
+
+// StooqProvider prices stocks, ETFs, funds and commodity futures from Stooq-style
+// CSV downloads. It also quotes FX pairs.
+type StooqProvider struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+var _ PriceProvider = (*StooqProvider)(nil)
+
+func NewStooqProvider(baseURL string) *StooqProvider {
+	return &StooqProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (c *StooqProvider) Name() string {
+	return ProviderStooq
+}
+
+// symbolFor turns a Yahoo-style ticker into a Stooq symbol and its currency.
+func (c *StooqProvider) symbolFor(ticker string, investmentType models.InvestmentType) (string, string, error) {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+
+	switch investmentType {
+	case models.InvestmentStock, models.InvestmentETF, models.InvestmentMutualFund:
+		root, exchange, _ := strings.Cut(ticker, ".")
+		market, ok := stooqMarkets[exchange]
+		if !ok {
+			return "", "", fmt.Errorf("exchange %q: %w", exchange, ErrUnsupported)
+		}
+		return strings.ToLower(root + "." + market.suffix), market.currency, nil
+
+	case models.InvestmentCommodity:
+		// Futures (GC=F) and spot pairs (XAUUSD=X)
+		if root, ok := strings.CutSuffix(ticker, "=F"); ok {
+			return strings.ToLower(root + ".f"), "USD", nil
+		}
+		if pair, ok := strings.CutSuffix(ticker, "=X"); ok && len(pair) == 6 {
+			return strings.ToLower(pair), pair[3:], nil
+		}
+	}
+
+	return "", "", ErrUnsupported
+}
+
+// fetchCSV downloads a CSV and returns its rows keyed by header name.
+func (c *StooqProvider) fetchCSV(ctx context.Context, path string, query url.Values) ([]map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("stooq: %w", ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stooq returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	// Stooq answers over-quota requests with a 200 and a plain-text notice
+	if bytes.Contains(body, []byte("Exceeded the daily hits limit")) {
+		return nil, fmt.Errorf("stooq: %w", ErrRateLimited)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil || len(records) < 2 {
+		return nil, fmt.Errorf("no data returned")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, h := range header {
+			if i < len(rec) {
+				row[strings.ToLower(strings.TrimSpace(h))] = strings.TrimSpace(rec[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// latestClose reads the last close for a Stooq symbol.
+func (c *StooqProvider) latestClose(ctx context.Context, symbol string) (float64, int64, error) {
+	rows, err := c.fetchCSV(ctx, "/q/l/", url.Values{"s": {symbol}, "f": {"sd2t2c"}, "h": {""}, "e": {"csv"}})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	price, err := strconv.ParseFloat(rows[0]["close"], 64)
+	if err != nil || price <= 0 {
+		return 0, 0, fmt.Errorf("no valid price found for '%s'", symbol)
+	}
+
+	var ts int64
+	if d, err := time.Parse("2006-01-02", rows[0]["date"]); err == nil {
+		ts = d.Unix()
+	}
+	return price, ts, nil
+}
+
+// closeOnDate reads the first close on or after date, looking a week ahead so
+// weekends and holidays resolve to the next session like the Yahoo client.
+func (c *StooqProvider) closeOnDate(ctx context.Context, symbol string, date time.Time) (float64, int64, error) {
+	start := date.UTC().Truncate(24 * time.Hour)
+	rows, err := c.fetchCSV(ctx, "/q/d/l/", url.Values{
+		"s":  {symbol},
+		"d1": {start.Format("20060102")},
+		"d2": {start.AddDate(0, 0, 7).Format("20060102")},
+		"i":  {"d"},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, row := range rows {
+		price, err := strconv.ParseFloat(row["close"], 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", row["date"])
+		if err != nil {
+			continue
+		}
+		return price, d.Unix(), nil
+	}
+	return 0, 0, fmt.Errorf("no valid price found for %s on %s", symbol, date.Format("2006-01-02"))
+}
+
+func (c *StooqProvider) GetAssetPrice(ctx context.Context, ticker string, investmentType models.InvestmentType) (*PriceData, error) {
+	symbol, currency, err := c.symbolFor(ticker, investmentType)
+	if err != nil {
+		return nil, err
+	}
+
+	price, ts, err := c.latestClose(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PriceData{
+		Symbol:     strings.ToUpper(strings.TrimSpace(ticker)),
+		Price:      price,
+		Currency:   currency,
+		LastUpdate: ts,
+	}, nil
+}
+
+func (c *StooqProvider) GetAssetPriceOnDate(ctx context.Context, ticker string, investmentType models.InvestmentType, date time.Time) (*PriceData, error) {
+	symbol, currency, err := c.symbolFor(ticker, investmentType)
+	if err != nil {
+		return nil, err
+	}
+
+	price, ts, err := c.closeOnDate(ctx, symbol, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PriceData{
+		Symbol:     strings.ToUpper(strings.TrimSpace(ticker)),
+		Price:      price,
+		Currency:   currency,
+		LastUpdate: ts,
+	}, nil
+}
+
+func (c *StooqProvider) GetPricesForMultipleAssets(ctx context.Context, assets []AssetRequest) (map[string]*PriceData, error) {
+	result := make(map[string]*PriceData)
+	for _, asset := range assets {
+		ticker := strings.ToUpper(strings.TrimSpace(asset.Ticker))
+		if exchange := ExchangeMap[strings.ToUpper(strings.TrimSpace(asset.Exchange))]; exchange != "" {
+			ticker = ticker + "." + exchange
+		}
+		if _, _, err := c.symbolFor(ticker, asset.InvestmentType); err != nil {
+			continue
+		}
+
+		data, err := c.GetAssetPrice(ctx, ticker, asset.InvestmentType)
+		if err != nil {
+			result[ticker] = &PriceData{Symbol: ticker, Error: err}
+			continue
+		}
+		result[ticker] = data
+	}
+	if len(result) == 0 {
+		return nil, ErrUnsupported
+	}
+	return result, nil
+}
+
+func (c *StooqProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	fromCurrency, toCurrency = strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency)
+	if fromCurrency == toCurrency {
+		return 1.0, nil
+	}
+	rate, _, err := c.latestClose(ctx, strings.ToLower(fromCurrency+toCurrency))
+	return rate, err
+}
+
+func (c *StooqProvider) GetExchangeRateOnDate(ctx context.Context, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	fromCurrency, toCurrency = strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency)
+	if fromCurrency == toCurrency {
+		return 1.0, nil
+	}
+	rate, _, err := c.closeOnDate(ctx, strings.ToLower(fromCurrency+toCurrency), date)
+	return rate, err
+}
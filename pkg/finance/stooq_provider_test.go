@@ -0,0 +1,137 @@
+package finance_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/finance"
+)
+
+func TestStooq_GetAssetPrice(t *testing.T) {
+	var gotPath, gotSymbol string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotSymbol = r.URL.Query().Get("s")
+		_, _ = w.Write([]byte("Symbol,Date,Time,Close\r\nSAP.DE,2024-01-05,17:35:00,141.22\r\n"))
+	}))
+	defer srv.Close()
+
+	provider := finance.NewStooqProvider(srv.URL)
+	price, err := provider.GetAssetPrice(context.Background(), "SAP.DE", models.InvestmentStock)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotPath != "/q/l/" || gotSymbol != "sap.de" {
+		t.Errorf("Unexpected request: path=%s s=%s", gotPath, gotSymbol)
+	}
+	if price.Price != 141.22 {
+		t.Errorf("Expected price 141.22, got %f", price.Price)
+	}
+	if price.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, got %s", price.Currency)
+	}
+	if price.Symbol != "SAP.DE" {
+		t.Errorf("Expected symbol SAP.DE, got %s", price.Symbol)
+	}
+	if price.LastUpdate != time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("Unexpected last update %d", price.LastUpdate)
+	}
+}
+
+func TestStooq_USTickerMapsToUSMarket(t *testing.T) {
+	var gotSymbol string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSymbol = r.URL.Query().Get("s")
+		_, _ = w.Write([]byte("Symbol,Date,Time,Close\nAAPL.US,2024-01-05,22:00:07,181.18\n"))
+	}))
+	defer srv.Close()
+
+	price, err := finance.NewStooqProvider(srv.URL).GetAssetPrice(context.Background(), "AAPL", models.InvestmentStock)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if gotSymbol != "aapl.us" || price.Currency != "USD" {
+		t.Errorf("Expected aapl.us in USD, got %s in %s", gotSymbol, price.Currency)
+	}
+}
+
+func TestStooq_GetAssetPriceOnDate_SkipsToNextSession(t *testing.T) {
+	var gotFrom, gotTo string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFrom = r.URL.Query().Get("d1")
+		gotTo = r.URL.Query().Get("d2")
+		_, _ = w.Write([]byte("Date,Open,High,Low,Close,Volume\n2024-01-08,180,183,179,182.5,1000\n2024-01-09,182,184,181,183.1,1000\n"))
+	}))
+	defer srv.Close()
+
+	// Saturday: the first row is Monday's close
+	date := time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)
+	price, err := finance.NewStooqProvider(srv.URL).GetAssetPriceOnDate(context.Background(), "AAPL", models.InvestmentStock, date)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotFrom != "20240106" || gotTo != "20240113" {
+		t.Errorf("Unexpected range %s-%s", gotFrom, gotTo)
+	}
+	if price.Price != 182.5 {
+		t.Errorf("Expected price 182.5, got %f", price.Price)
+	}
+}
+
+func TestStooq_NoData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Symbol,Date,Time,Close\nXYZ.US,N/D,N/D,N/D\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := finance.NewStooqProvider(srv.URL).GetAssetPrice(context.Background(), "XYZ", models.InvestmentStock); err == nil {
+		t.Error("Expected error for a symbol without data")
+	}
+}
+
+func TestStooq_DailyLimitIsRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Exceeded the daily hits limit"))
+	}))
+	defer srv.Close()
+
+	_, err := finance.NewStooqProvider(srv.URL).GetAssetPrice(context.Background(), "AAPL", models.InvestmentStock)
+	if !errors.Is(err, finance.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestStooq_UnsupportedRequests(t *testing.T) {
+	provider := finance.NewStooqProvider("http://127.0.0.1:0")
+	ctx := context.Background()
+
+	if _, err := provider.GetAssetPrice(ctx, "BTC-USD", models.InvestmentCrypto); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for crypto, got: %v", err)
+	}
+	if _, err := provider.GetAssetPrice(ctx, "SHOP.TO", models.InvestmentStock); !errors.Is(err, finance.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for an unmapped exchange, got: %v", err)
+	}
+}
+
+func TestStooq_GetExchangeRate(t *testing.T) {
+	var gotSymbol string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSymbol = r.URL.Query().Get("s")
+		_, _ = w.Write([]byte("Symbol,Date,Time,Close\nEURUSD,2024-01-05,22:00:00,1.0921\n"))
+	}))
+	defer srv.Close()
+
+	rate, err := finance.NewStooqProvider(srv.URL).GetExchangeRate(context.Background(), "EUR", "USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if gotSymbol != "eurusd" || rate != 1.0921 {
+		t.Errorf("Expected eurusd at 1.0921, got %s at %f", gotSymbol, rate)
+	}
+}
@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// AllocationHolding is an asset with its market value and price already
+// converted into the currency the allocation is measured in.
+type AllocationHolding struct {
+	Asset models.InvestmentAsset
+	Value decimal.Decimal
+	Price *decimal.Decimal
+}
+
+// NormalizeAssetClass trims a user-entered asset class; blank means none.
+func NormalizeAssetClass(class *string) *string {
+	if class == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*class)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// AllocationAssetKey is the bucket an asset falls into for a target kind.
+// ok is false when the asset has nothing to match on, e.g. no asset class.
+func AllocationAssetKey(kind models.AllocationTargetKind, a models.InvestmentAsset) (string, bool) {
+	switch kind {
+	case models.AllocationByAsset:
+		return strconv.FormatInt(a.ID, 10), true
+	case models.AllocationByInvestmentType:
+		return string(a.InvestmentType), true
+	case models.AllocationByAssetClass:
+		if c := NormalizeAssetClass(a.AssetClass); c != nil {
+			return strings.ToLower(*c), true
+		}
+	}
+	return "", false
+}
+
+// AllocationTargetKey is the bucket a target describes. Asset classes match
+// case-insensitively.
+func AllocationTargetKey(t models.AllocationTarget) string {
+	switch t.Kind {
+	case models.AllocationByAsset:
+		if t.AssetID != nil {
+			return strconv.FormatInt(*t.AssetID, 10)
+		}
+	case models.AllocationByInvestmentType:
+		if t.InvestmentType != nil {
+			return string(*t.InvestmentType)
+		}
+	case models.AllocationByAssetClass:
+		if c := NormalizeAssetClass(t.AssetClass); c != nil {
+			return strings.ToLower(*c)
+		}
+	}
+	return ""
+}
+
+// ComputeAllocation compares holdings against a target set and proposes trades.
+//
+// Weights are measured over the holdings the targets cover; anything else is
+// listed as untargeted and left alone. Drift is in percentage points, and a
+// bucket is outside its band when |drift| exceeds its tolerance.
+//
+// With no contribution the suggestions rebalance fully, buying and selling
+// towards the targets. With a positive contribution only buys are proposed:
+// the new cash goes to underweight buckets in proportion to their shortfall
+// after adding it, so nothing has to be sold.
+func ComputeAllocation(targets []models.AllocationTarget, holdings []AllocationHolding, contribution decimal.Decimal) models.AllocationReport {
+	report := models.AllocationReport{
+		Mode:         "full",
+		Contribution: decimal.Zero,
+		Buckets:      []models.AllocationBucket{},
+		Untargeted:   []models.AllocationUntargeted{},
+	}
+	if contribution.IsPositive() {
+		report.Mode = "contribution"
+		report.Contribution = contribution
+	}
+	if len(targets) == 0 {
+		return report
+	}
+	report.Kind = targets[0].Kind
+
+	index := make(map[string]int, len(targets))
+	members := make([][]AllocationHolding, len(targets))
+	for i, t := range targets {
+		key := AllocationTargetKey(t)
+		index[key] = i
+		label := key
+		switch {
+		case t.Kind == models.AllocationByAssetClass && t.AssetClass != nil:
+			label = strings.TrimSpace(*t.AssetClass)
+		case t.Kind == models.AllocationByAsset:
+			label = "#" + key
+		}
+		report.Buckets = append(report.Buckets, models.AllocationBucket{
+			TargetID:         t.ID,
+			Key:              key,
+			Label:            label,
+			TargetPercent:    t.TargetPercent,
+			TolerancePercent: t.TolerancePercent,
+			CurrentValue:     decimal.Zero,
+			Trades:           []models.AllocationTradeSuggestion{},
+		})
+	}
+
+	total := decimal.Zero
+	for _, h := range holdings {
+		key, ok := AllocationAssetKey(report.Kind, h.Asset)
+		i, found := index[key]
+		if !ok || !found {
+			if h.Value.IsPositive() {
+				report.Untargeted = append(report.Untargeted, models.AllocationUntargeted{
+					AssetID: h.Asset.ID,
+					Ticker:  h.Asset.Ticker,
+					Value:   h.Value.Round(2),
+				})
+			}
+			continue
+		}
+		if report.Kind == models.AllocationByAsset {
+			report.Buckets[i].Label = h.Asset.Ticker
+		}
+		members[i] = append(members[i], h)
+		report.Buckets[i].CurrentValue = report.Buckets[i].CurrentValue.Add(h.Value)
+		total = total.Add(h.Value)
+	}
+	report.TotalValue = total.Round(2)
+
+	base := total.Add(report.Contribution)
+	shortfall := make([]decimal.Decimal, len(targets))
+	totalShortfall := decimal.Zero
+
+	for i := range report.Buckets {
+		b := &report.Buckets[i]
+		b.CurrentPercent = decimal.Zero
+		if total.IsPositive() {
+			b.CurrentPercent = b.CurrentValue.Div(total).Mul(hundred)
+		}
+		b.Drift = b.CurrentPercent.Sub(b.TargetPercent)
+		b.OutsideBand = total.IsPositive() && b.Drift.Abs().GreaterThan(b.TolerancePercent)
+		b.TargetValue = base.Mul(b.TargetPercent).Div(hundred)
+
+		if total.IsPositive() && b.Drift.Abs().GreaterThan(report.MaxDrift) {
+			report.MaxDrift = b.Drift.Abs()
+		}
+		if b.OutsideBand {
+			report.OutsideBand = true
+		}
+
+		if gap := b.TargetValue.Sub(b.CurrentValue); gap.IsPositive() {
+			shortfall[i] = gap
+			totalShortfall = totalShortfall.Add(gap)
+		}
+	}
+
+	for i := range report.Buckets {
+		b := &report.Buckets[i]
+		switch {
+		case report.Mode == "full":
+			b.Suggested = b.TargetValue.Sub(b.CurrentValue)
+		case totalShortfall.IsPositive():
+			b.Suggested = report.Contribution.Mul(shortfall[i]).Div(totalShortfall)
+		default:
+			b.Suggested = decimal.Zero
+		}
+		b.Trades = splitAllocationTrades(b.Suggested, members[i])
+
+		b.CurrentValue = b.CurrentValue.Round(2)
+		b.CurrentPercent = b.CurrentPercent.Round(2)
+		b.Drift = b.Drift.Round(2)
+		b.TargetValue = b.TargetValue.Round(2)
+		b.Suggested = b.Suggested.Round(2)
+	}
+	report.MaxDrift = report.MaxDrift.Round(2)
+
+	sort.SliceStable(report.Buckets, func(a, b int) bool {
+		return report.Buckets[a].TargetPercent.GreaterThan(report.Buckets[b].TargetPercent)
+	})
+
+	return report
+}
+
+// splitAllocationTrades spreads a bucket's buy or sell amount over its assets in
+// proportion to their current value, or evenly when none of them holds value.
+func splitAllocationTrades(amount decimal.Decimal, members []AllocationHolding) []models.AllocationTradeSuggestion {
+	trades := []models.AllocationTradeSuggestion{}
+	if amount.Round(2).IsZero() || len(members) == 0 {
+		return trades
+	}
+
+	tradeType := models.InvestmentBuy
+	if amount.IsNegative() {
+		tradeType = models.InvestmentSell
+	}
+
+	weightTotal := decimal.Zero
+	for _, m := range members {
+		weightTotal = weightTotal.Add(m.Value)
+	}
+	even := !weightTotal.IsPositive()
+	if even && tradeType == models.InvestmentSell {
+		return trades
+	}
+
+	for _, m := range members {
+		share := decimal.NewFromInt(1).Div(decimal.NewFromInt(int64(len(members))))
+		if !even {
+			if !m.Value.IsPositive() {
+				continue
+			}
+			share = m.Value.Div(weightTotal)
+		}
+		part := amount.Abs().Mul(share)
+
+		s := models.AllocationTradeSuggestion{
+			AssetID:   m.Asset.ID,
+			Ticker:    m.Asset.Ticker,
+			TradeType: tradeType,
+			Amount:    part.Round(2),
+			Price:     m.Price,
+		}
+		if m.Price != nil && m.Price.IsPositive() {
+			places := int32(4)
+			if m.Asset.InvestmentType == models.InvestmentCrypto {
+				places = 8
+			}
+			qty := part.Div(*m.Price).Truncate(places)
+			if tradeType == models.InvestmentSell && qty.GreaterThan(m.Asset.Quantity) {
+				qty = m.Asset.Quantity
+			}
+			s.Quantity = &qty
+		}
+		trades = append(trades, s)
+	}
+	return trades
+}
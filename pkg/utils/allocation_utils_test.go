@@ -0,0 +1,165 @@
+package utils_test
+
+import (
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeTarget(id int64, t models.InvestmentType, pct, tol float64) models.AllocationTarget {
+	return models.AllocationTarget{ID: id, Kind: models.AllocationByInvestmentType, InvestmentType: &t, TargetPercent: df(pct), TolerancePercent: df(tol)}
+}
+
+func holding(id int64, ticker string, t models.InvestmentType, value, price float64) utils.AllocationHolding {
+	p := df(price)
+	return utils.AllocationHolding{
+		Asset: models.InvestmentAsset{ID: id, Ticker: ticker, InvestmentType: t, Quantity: df(value / price)},
+		Value: df(value),
+		Price: &p,
+	}
+}
+
+func bucketByKey(t *testing.T, r models.AllocationReport, key string) models.AllocationBucket {
+	for _, b := range r.Buckets {
+		if b.Key == key {
+			return b
+		}
+	}
+	t.Fatalf("bucket %q not found", key)
+	return models.AllocationBucket{}
+}
+
+func TestComputeAllocation_DriftAndBand(t *testing.T) {
+	targets := []models.AllocationTarget{
+		typeTarget(1, models.InvestmentETF, 70, 5),
+		typeTarget(2, models.InvestmentCrypto, 30, 5),
+	}
+	holdings := []utils.AllocationHolding{
+		holding(10, "VWCE", models.InvestmentETF, 6000, 100),
+		holding(11, "BTC", models.InvestmentCrypto, 4000, 40000),
+	}
+
+	r := utils.ComputeAllocation(targets, holdings, decimal.Zero)
+
+	etf := bucketByKey(t, r, "etf")
+	assert.True(t, df(60).Equal(etf.CurrentPercent), etf.CurrentPercent.String())
+	assert.True(t, df(-10).Equal(etf.Drift), etf.Drift.String())
+	assert.True(t, etf.OutsideBand)
+	assert.True(t, r.OutsideBand)
+	assert.True(t, df(10).Equal(r.MaxDrift))
+	assert.Equal(t, "full", r.Mode)
+	assert.True(t, df(10000).Equal(r.TotalValue))
+}
+
+func TestComputeAllocation_FullRebalanceProposesBuysAndSells(t *testing.T) {
+	targets := []models.AllocationTarget{
+		typeTarget(1, models.InvestmentETF, 70, 5),
+		typeTarget(2, models.InvestmentCrypto, 30, 5),
+	}
+	holdings := []utils.AllocationHolding{
+		holding(10, "VWCE", models.InvestmentETF, 6000, 100),
+		holding(11, "BTC", models.InvestmentCrypto, 4000, 40000),
+	}
+
+	r := utils.ComputeAllocation(targets, holdings, decimal.Zero)
+
+	etf := bucketByKey(t, r, "etf")
+	require.Len(t, etf.Trades, 1)
+	assert.Equal(t, models.InvestmentBuy, etf.Trades[0].TradeType)
+	assert.True(t, df(1000).Equal(etf.Suggested))
+	assert.True(t, df(10).Equal(*etf.Trades[0].Quantity))
+
+	crypto := bucketByKey(t, r, "crypto")
+	require.Len(t, crypto.Trades, 1)
+	assert.Equal(t, models.InvestmentSell, crypto.Trades[0].TradeType)
+	assert.True(t, df(0.025).Equal(*crypto.Trades[0].Quantity), crypto.Trades[0].Quantity.String())
+}
+
+func TestComputeAllocation_ContributionOnlyNeverSells(t *testing.T) {
+	targets := []models.AllocationTarget{
+		typeTarget(1, models.InvestmentETF, 70, 5),
+		typeTarget(2, models.InvestmentStock, 20, 5),
+		typeTarget(3, models.InvestmentCrypto, 10, 5),
+	}
+	holdings := []utils.AllocationHolding{
+		holding(10, "VWCE", models.InvestmentETF, 6000, 100),
+		holding(11, "AAPL", models.InvestmentStock, 1000, 200),
+		holding(12, "BTC", models.InvestmentCrypto, 3000, 40000),
+	}
+
+	// 10000 + 2000 => targets 8400 / 2400 / 1200, shortfalls 2400 / 1400 / 0
+	r := utils.ComputeAllocation(targets, holdings, df(2000))
+
+	assert.Equal(t, "contribution", r.Mode)
+	total := decimal.Zero
+	for _, b := range r.Buckets {
+		assert.False(t, b.Suggested.IsNegative(), b.Key)
+		for _, tr := range b.Trades {
+			assert.Equal(t, models.InvestmentBuy, tr.TradeType)
+		}
+		total = total.Add(b.Suggested)
+	}
+	assert.True(t, df(2000).Equal(total), total.String())
+	assert.True(t, bucketByKey(t, r, "crypto").Suggested.IsZero())
+	assert.True(t, df(1263.16).Equal(bucketByKey(t, r, "etf").Suggested), bucketByKey(t, r, "etf").Suggested.String())
+}
+
+func TestComputeAllocation_SplitsBucketByValue(t *testing.T) {
+	targets := []models.AllocationTarget{
+		typeTarget(1, models.InvestmentETF, 50, 5),
+		typeTarget(2, models.InvestmentStock, 50, 5),
+	}
+	holdings := []utils.AllocationHolding{
+		holding(10, "VWCE", models.InvestmentETF, 3000, 100),
+		holding(11, "EIMI", models.InvestmentETF, 1000, 25),
+		holding(12, "AAPL", models.InvestmentStock, 6000, 200),
+	}
+
+	r := utils.ComputeAllocation(targets, holdings, decimal.Zero)
+
+	etf := bucketByKey(t, r, "etf")
+	require.Len(t, etf.Trades, 2)
+	assert.True(t, df(750).Equal(etf.Trades[0].Amount))
+	assert.True(t, df(250).Equal(etf.Trades[1].Amount))
+	assert.True(t, df(10).Equal(*etf.Trades[1].Quantity))
+}
+
+func TestComputeAllocation_UntargetedHoldingsAreExcluded(t *testing.T) {
+	class := "Bonds"
+	targets := []models.AllocationTarget{
+		{ID: 1, Kind: models.AllocationByAssetClass, AssetClass: &class, TargetPercent: df(100), TolerancePercent: df(5)},
+	}
+	lower := "bonds "
+	bond := holding(10, "AGGH", models.InvestmentETF, 2000, 5)
+	bond.Asset.AssetClass = &lower
+	holdings := []utils.AllocationHolding{bond, holding(11, "BTC", models.InvestmentCrypto, 500, 40000)}
+
+	r := utils.ComputeAllocation(targets, holdings, decimal.Zero)
+
+	require.Len(t, r.Untargeted, 1)
+	assert.Equal(t, "BTC", r.Untargeted[0].Ticker)
+	assert.True(t, df(2000).Equal(r.TotalValue))
+	b := bucketByKey(t, r, "bonds")
+	assert.Equal(t, "Bonds", b.Label)
+	assert.True(t, df(100).Equal(b.CurrentPercent))
+	assert.False(t, r.OutsideBand)
+}
+
+func TestComputeAllocation_EmptyBucketHasAmountWithoutTrades(t *testing.T) {
+	targets := []models.AllocationTarget{
+		typeTarget(1, models.InvestmentETF, 80, 5),
+		typeTarget(2, models.InvestmentStock, 20, 5),
+	}
+	holdings := []utils.AllocationHolding{holding(10, "VWCE", models.InvestmentETF, 1000, 100)}
+
+	r := utils.ComputeAllocation(targets, holdings, decimal.Zero)
+
+	stock := bucketByKey(t, r, "stock")
+	assert.True(t, df(200).Equal(stock.Suggested))
+	assert.Empty(t, stock.Trades)
+	assert.True(t, stock.OutsideBand)
+}
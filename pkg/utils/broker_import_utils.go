@@ -0,0 +1,913 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ParseBrokerStatement reads a broker export into trades and dividends. Rows that
+// can't be read are reported by line and skipped; an unreadable file fails as a
+// whole. The result is sorted by date with buys ahead of sells on the same day.
+func ParseBrokerStatement(broker string, r io.Reader, mapping *models.BrokerColumnMapping) ([]models.BrokerActivity, []string, error) {
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+		err        error
+	)
+
+	switch broker {
+	case models.BrokerIBKR:
+		activities, issues, err = ParseIBKRFlex(r)
+	case models.BrokerDegiro:
+		activities, issues, err = ParseDegiroCSV(r)
+	case models.BrokerTrading212:
+		activities, issues, err = ParseTrading212CSV(r)
+	case models.BrokerCSV:
+		if mapping == nil {
+			return nil, nil, errors.New("a column mapping is required for a generic broker CSV")
+		}
+		activities, issues, err = ParseMappedBrokerCSV(r, *mapping)
+	default:
+		return nil, nil, fmt.Errorf("unsupported broker: %s", broker)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		if !activities[i].Date.Equal(activities[j].Date) {
+			return activities[i].Date.Before(activities[j].Date)
+		}
+		return activities[i].Kind == models.BrokerActivityBuy && activities[j].Kind != models.BrokerActivityBuy
+	})
+
+	return activities, issues, nil
+}
+
+// parseBrokerDecimal reads an amount whose decimal separator is sep ('.' or ','),
+// the other character being a thousands separator. With sep 0 the separator is
+// inferred from the value itself, and values like "1,234" or "1.234" that read
+// either way are rejected.
+func parseBrokerDecimal(s string, sep byte) (decimal.Decimal, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if s == "" {
+		return decimal.Zero, nil
+	}
+
+	if sep == 0 {
+		var decisive bool
+		sep, decisive = decimalSeparatorOf(s)
+		if !decisive && sep != 0 {
+			return decimal.Zero, fmt.Errorf("ambiguous number %q, set the decimal separator", s)
+		}
+	}
+
+	switch sep {
+	case ',':
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	default:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return decimal.NewFromString(s)
+}
+
+// decimalSeparatorOf tells which separator a value uses for decimals. decisive is
+// false when the value has none, or when its only separator is followed by exactly
+// three digits and could as well group thousands; sep is then the separator seen.
+func decimalSeparatorOf(s string) (sep byte, decisive bool) {
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && dot > comma:
+		return '.', true
+	case comma >= 0 && dot >= 0:
+		return ',', true
+	case comma < 0 && dot < 0:
+		return 0, false
+	}
+
+	sep, last := byte('.'), dot
+	if comma >= 0 {
+		sep, last = ',', comma
+	}
+	if strings.Count(s, string(sep)) > 1 {
+		// Repeated, so it groups thousands
+		if sep == '.' {
+			return ',', true
+		}
+		return '.', true
+	}
+	whole := strings.TrimLeft(s[:last], "+-")
+	if len(s)-last-1 == 3 && whole != "" && whole != "0" && len(whole) <= 3 {
+		return sep, false
+	}
+	return sep, true
+}
+
+// decimalSeparator settles the decimal separator of a file from the values in
+// the given columns. It is 0 when no value decides it, and an error when values
+// disagree.
+func (t *csvTable) decimalSeparator(cols ...int) (byte, error) {
+	var found byte
+	for _, rec := range t.rows {
+		for _, c := range cols {
+			v := strings.ReplaceAll(csvField(rec, c), " ", "")
+			if v == "" {
+				continue
+			}
+			if _, err := decimal.NewFromString(strings.NewReplacer(",", "", ".", "").Replace(v)); err != nil {
+				continue
+			}
+			sep, decisive := decimalSeparatorOf(v)
+			if !decisive {
+				continue
+			}
+			if found != 0 && sep != found {
+				return 0, errors.New("numbers mix '.' and ',' as decimal separator")
+			}
+			found = sep
+		}
+	}
+	return found, nil
+}
+
+// brokerDateLayouts are tried in order for statements that don't pin a format.
+var brokerDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"02-01-2006",
+	"02.01.2006",
+	"01/02/2006",
+	"20060102",
+}
+
+func parseBrokerDate(s, layout string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	layouts := brokerDateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	// Timestamps with fractions or zones: the date part is enough
+	if len(s) > 10 && layout == "" {
+		return parseBrokerDate(s[:10], "2006-01-02")
+	}
+	return time.Time{}, false
+}
+
+// csvTable is a CSV file read into rows addressed by header name.
+type csvTable struct {
+	header []string
+	cols   map[string]int
+	rows   [][]string
+}
+
+func readCSVTable(r io.Reader, delimiter rune) (*csvTable, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("can't read CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	t := &csvTable{header: records[0], cols: make(map[string]int, len(records[0])), rows: records[1:]}
+	for i, h := range t.header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF")))
+		t.header[i] = key
+		if _, dup := t.cols[key]; !dup && key != "" {
+			t.cols[key] = i
+		}
+	}
+	return t, nil
+}
+
+// col finds the first column whose header starts with one of the prefixes.
+func (t *csvTable) col(prefixes ...string) int {
+	for _, p := range prefixes {
+		if i, ok := t.cols[p]; ok {
+			return i
+		}
+	}
+	for _, p := range prefixes {
+		for i, h := range t.header {
+			if strings.HasPrefix(h, p) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func csvField(rec []string, i int) string {
+	if i >= 0 && i < len(rec) {
+		return strings.TrimSpace(rec[i])
+	}
+	return ""
+}
+
+// --- Interactive Brokers ---
+
+type ibkrFlex struct {
+	Statements []struct {
+		Trades []struct {
+			AssetCategory   string `xml:"assetCategory,attr"`
+			Symbol          string `xml:"symbol,attr"`
+			Description     string `xml:"description,attr"`
+			ISIN            string `xml:"isin,attr"`
+			ListingExchange string `xml:"listingExchange,attr"`
+			Currency        string `xml:"currency,attr"`
+			TradeDate       string `xml:"tradeDate,attr"`
+			Quantity        string `xml:"quantity,attr"`
+			TradePrice      string `xml:"tradePrice,attr"`
+			Commission      string `xml:"ibCommission,attr"`
+			BuySell         string `xml:"buySell,attr"`
+			LevelOfDetail   string `xml:"levelOfDetail,attr"`
+		} `xml:"Trades>Trade"`
+		CashTransactions []struct {
+			Type          string `xml:"type,attr"`
+			AssetCategory string `xml:"assetCategory,attr"`
+			Symbol        string `xml:"symbol,attr"`
+			Description   string `xml:"description,attr"`
+			ISIN          string `xml:"isin,attr"`
+			Currency      string `xml:"currency,attr"`
+			DateTime      string `xml:"dateTime,attr"`
+			SettleDate    string `xml:"settleDate,attr"`
+			Amount        string `xml:"amount,attr"`
+		} `xml:"CashTransactions>CashTransaction"`
+	} `xml:"FlexStatements>FlexStatement"`
+}
+
+// ibkrCategories maps IBKR asset categories to investment types; anything else
+// (cash, futures, CFDs) is skipped.
+var ibkrCategories = map[string]models.InvestmentType{
+	"STK":    models.InvestmentStock,
+	"FUND":   models.InvestmentMutualFund,
+	"BOND":   models.InvestmentBond,
+	"OPT":    models.InvestmentOption,
+	"CMDTY":  models.InvestmentCommodity,
+	"CRYPTO": models.InvestmentCrypto,
+}
+
+// ibkrExchanges maps IBKR listing exchanges to Yahoo ticker suffixes. US venues
+// and unknown exchanges keep the bare symbol.
+var ibkrExchanges = map[string]string{
+	"AEB":    "AS",
+	"SBF":    "PA",
+	"IBIS":   "DE",
+	"IBIS2":  "DE",
+	"FWB":    "F",
+	"FWB2":   "F",
+	"LSE":    "L",
+	"LSEETF": "L",
+	"TSE":    "TO",
+	"ASX":    "AX",
+}
+
+func ibkrDate(s string) (time.Time, bool) {
+	// 20240115, 20240115;093000, 2024-01-15, 2024-01-15, 09:30:00
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, ";, "); i >= 0 {
+		s = s[:i]
+	}
+	return parseBrokerDate(strings.ReplaceAll(s, "-", ""), "20060102")
+}
+
+// ParseIBKRFlex reads the trades and cash dividends of an Interactive Brokers
+// Flex Query. Withholding tax is matched to the dividend paid on the same
+// symbol and day.
+func ParseIBKRFlex(r io.Reader) ([]models.BrokerActivity, []string, error) {
+	var flex ibkrFlex
+	if err := xml.NewDecoder(r).Decode(&flex); err != nil {
+		return nil, nil, fmt.Errorf("can't read Flex Query XML: %w", err)
+	}
+
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+	)
+
+	for _, st := range flex.Statements {
+		for i, t := range st.Trades {
+			if t.LevelOfDetail != "" && !strings.EqualFold(t.LevelOfDetail, "EXECUTION") {
+				continue
+			}
+			ref := fmt.Sprintf("trade %d (%s)", i+1, t.Symbol)
+
+			investmentType, ok := ibkrCategories[strings.ToUpper(t.AssetCategory)]
+			if !ok {
+				issues = append(issues, fmt.Sprintf("%s: asset category %s is not supported", ref, t.AssetCategory))
+				continue
+			}
+			date, ok := ibkrDate(t.TradeDate)
+			if !ok {
+				issues = append(issues, fmt.Sprintf("%s: invalid trade date %q", ref, t.TradeDate))
+				continue
+			}
+			qty, err := parseBrokerDecimal(t.Quantity, '.')
+			if err != nil || qty.IsZero() {
+				issues = append(issues, fmt.Sprintf("%s: invalid quantity %q", ref, t.Quantity))
+				continue
+			}
+			price, err := parseBrokerDecimal(t.TradePrice, '.')
+			if err != nil || price.IsNegative() {
+				issues = append(issues, fmt.Sprintf("%s: invalid price %q", ref, t.TradePrice))
+				continue
+			}
+			fee, err := parseBrokerDecimal(t.Commission, '.')
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s: invalid commission %q", ref, t.Commission))
+				continue
+			}
+
+			kind := models.BrokerActivityBuy
+			if strings.HasPrefix(strings.ToUpper(t.BuySell), "SELL") || (t.BuySell == "" && qty.IsNegative()) {
+				kind = models.BrokerActivitySell
+			}
+
+			ticker := strings.ToUpper(strings.TrimSpace(t.Symbol))
+			if investmentType == models.InvestmentOption {
+				ticker = strings.ReplaceAll(ticker, " ", "")
+			} else if suffix := ibkrExchanges[strings.ToUpper(t.ListingExchange)]; suffix != "" && !strings.Contains(ticker, ".") {
+				ticker = ticker + "." + suffix
+			}
+
+			activities = append(activities, models.BrokerActivity{
+				Line:           i + 1,
+				Kind:           kind,
+				Date:           date,
+				Ticker:         ticker,
+				ISIN:           strings.ToUpper(t.ISIN),
+				Name:           t.Description,
+				InvestmentType: investmentType,
+				Quantity:       qty.Abs(),
+				Price:          price,
+				Fee:            fee.Abs(),
+				Currency:       strings.ToUpper(t.Currency),
+			})
+		}
+
+		type divKey struct {
+			symbol, currency string
+			date             time.Time
+		}
+		dividends := make(map[divKey]int)
+		var withholding []int
+
+		for i, c := range st.CashTransactions {
+			typ := strings.ToLower(c.Type)
+			isDividend := strings.Contains(typ, "dividend")
+			isTax := strings.Contains(typ, "withholding")
+			if !isDividend && !isTax {
+				continue
+			}
+			ref := fmt.Sprintf("cash transaction %d (%s)", i+1, c.Symbol)
+
+			date, ok := ibkrDate(c.DateTime)
+			if !ok {
+				date, ok = ibkrDate(c.SettleDate)
+			}
+			if !ok {
+				issues = append(issues, fmt.Sprintf("%s: invalid date %q", ref, c.DateTime))
+				continue
+			}
+			amount, err := parseBrokerDecimal(c.Amount, '.')
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s: invalid amount %q", ref, c.Amount))
+				continue
+			}
+
+			if isTax {
+				withholding = append(withholding, i)
+				continue
+			}
+
+			investmentType, ok := ibkrCategories[strings.ToUpper(c.AssetCategory)]
+			if !ok {
+				investmentType = models.InvestmentStock
+			}
+			key := divKey{symbol: strings.ToUpper(c.Symbol), currency: strings.ToUpper(c.Currency), date: date}
+			if idx, ok := dividends[key]; ok {
+				activities[idx].Amount = activities[idx].Amount.Add(amount)
+				continue
+			}
+			dividends[key] = len(activities)
+			activities = append(activities, models.BrokerActivity{
+				Line:           i + 1,
+				Kind:           models.BrokerActivityDividend,
+				Date:           date,
+				Ticker:         strings.ToUpper(c.Symbol),
+				ISIN:           strings.ToUpper(c.ISIN),
+				Name:           c.Description,
+				InvestmentType: investmentType,
+				Amount:         amount,
+				Currency:       strings.ToUpper(c.Currency),
+			})
+		}
+
+		for _, i := range withholding {
+			c := st.CashTransactions[i]
+			date, _ := ibkrDate(c.DateTime)
+			if date.IsZero() {
+				date, _ = ibkrDate(c.SettleDate)
+			}
+			amount, _ := parseBrokerDecimal(c.Amount, '.')
+			key := divKey{symbol: strings.ToUpper(c.Symbol), currency: strings.ToUpper(c.Currency), date: date}
+			idx, ok := dividends[key]
+			if !ok {
+				issues = append(issues, fmt.Sprintf("cash transaction %d (%s): withholding tax without a dividend on %s", i+1, c.Symbol, date.Format("2006-01-02")))
+				continue
+			}
+			// Tax is booked negative, refunds positive
+			activities[idx].TaxWithheld = activities[idx].TaxWithheld.Sub(amount)
+		}
+	}
+
+	return activities, issues, nil
+}
+
+// --- Degiro ---
+
+// ParseDegiroCSV reads a Degiro Transactions export (trades) or Account
+// statement export (dividends and dividend tax). Degiro lists products by name
+// and ISIN only, so activities carry no ticker.
+func ParseDegiroCSV(r io.Reader) ([]models.BrokerActivity, []string, error) {
+	t, err := readCSVTable(r, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t.col("quantity", "number") >= 0 {
+		return parseDegiroTransactions(t)
+	}
+	if t.col("description") >= 0 && t.col("change") >= 0 {
+		return parseDegiroAccount(t)
+	}
+	return nil, nil, errors.New("not a Degiro transactions or account statement export")
+}
+
+func parseDegiroTransactions(t *csvTable) ([]models.BrokerActivity, []string, error) {
+	dateCol, productCol, isinCol := t.col("date"), t.col("product"), t.col("isin")
+	qtyCol, priceCol := t.col("quantity", "number"), t.col("price")
+	feeCol := t.col("transaction and/or third", "transaction costs", "transaction fee")
+	rateCol := t.col("exchange rate")
+	if dateCol < 0 || isinCol < 0 || qtyCol < 0 || priceCol < 0 {
+		return nil, nil, errors.New("missing date, ISIN, quantity or price column")
+	}
+
+	// Degiro writes numbers in the locale of the export
+	sep, err := t.decimalSeparator(qtyCol, priceCol, feeCol, rateCol, t.col("total"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+	)
+	for n, rec := range t.rows {
+		line := n + 2
+		if len(rec) == 0 || csvField(rec, dateCol) == "" {
+			continue
+		}
+
+		date, ok := parseBrokerDate(csvField(rec, dateCol), "02-01-2006")
+		if !ok {
+			issues = append(issues, fmt.Sprintf("line %d: invalid date %q", line, csvField(rec, dateCol)))
+			continue
+		}
+		qty, err := parseBrokerDecimal(csvField(rec, qtyCol), sep)
+		if err != nil || qty.IsZero() {
+			issues = append(issues, fmt.Sprintf("line %d: invalid quantity %q", line, csvField(rec, qtyCol)))
+			continue
+		}
+		price, err := parseBrokerDecimal(csvField(rec, priceCol), sep)
+		if err != nil || price.IsNegative() {
+			issues = append(issues, fmt.Sprintf("line %d: invalid price %q", line, csvField(rec, priceCol)))
+			continue
+		}
+		// The price currency sits in the unnamed column after it
+		currency := strings.ToUpper(csvField(rec, priceCol+1))
+		if len(currency) != 3 {
+			issues = append(issues, fmt.Sprintf("line %d: missing price currency", line))
+			continue
+		}
+
+		// Fees are charged in the account currency; bring them to the trade's
+		fee := decimal.Zero
+		if feeCol >= 0 {
+			fee, err = parseBrokerDecimal(csvField(rec, feeCol), sep)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("line %d: invalid fee %q", line, csvField(rec, feeCol)))
+				continue
+			}
+			fee = fee.Abs()
+			if rateCol >= 0 {
+				if rate, err := parseBrokerDecimal(csvField(rec, rateCol), sep); err == nil && rate.IsPositive() {
+					fee = fee.Mul(rate).Round(4)
+				}
+			}
+		}
+
+		kind := models.BrokerActivityBuy
+		if qty.IsNegative() {
+			kind = models.BrokerActivitySell
+		}
+
+		activities = append(activities, models.BrokerActivity{
+			Line:           line,
+			Kind:           kind,
+			Date:           date,
+			ISIN:           strings.ToUpper(csvField(rec, isinCol)),
+			Name:           csvField(rec, productCol),
+			InvestmentType: models.InvestmentStock,
+			Quantity:       qty.Abs(),
+			Price:          price,
+			Fee:            fee,
+			Currency:       currency,
+		})
+	}
+
+	return activities, issues, nil
+}
+
+func parseDegiroAccount(t *csvTable) ([]models.BrokerActivity, []string, error) {
+	dateCol, productCol, isinCol := t.col("date"), t.col("product"), t.col("isin")
+	descCol, changeCol := t.col("description"), t.col("change")
+	if dateCol < 0 || isinCol < 0 {
+		return nil, nil, errors.New("missing date or ISIN column")
+	}
+
+	type divKey struct {
+		isin, currency string
+		date           time.Time
+	}
+	dividends := make(map[divKey]int)
+
+	sep, err := t.decimalSeparator(changeCol + 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+	)
+	for n, rec := range t.rows {
+		line := n + 2
+		desc := strings.ToLower(csvField(rec, descCol))
+		isTax := strings.Contains(desc, "dividend tax") || strings.Contains(desc, "dividendbelasting")
+		isDividend := !isTax && strings.Contains(desc, "dividend")
+		if !isDividend && !isTax {
+			continue
+		}
+
+		date, ok := parseBrokerDate(csvField(rec, dateCol), "02-01-2006")
+		if !ok {
+			issues = append(issues, fmt.Sprintf("line %d: invalid date %q", line, csvField(rec, dateCol)))
+			continue
+		}
+		// "Change" holds the currency, the amount follows in an unnamed column
+		currency := strings.ToUpper(csvField(rec, changeCol))
+		amount, err := parseBrokerDecimal(csvField(rec, changeCol+1), sep)
+		if err != nil || len(currency) != 3 {
+			issues = append(issues, fmt.Sprintf("line %d: invalid amount %q", line, csvField(rec, changeCol+1)))
+			continue
+		}
+
+		key := divKey{isin: strings.ToUpper(csvField(rec, isinCol)), currency: currency, date: date}
+		idx, ok := dividends[key]
+		if !ok {
+			idx = len(activities)
+			dividends[key] = idx
+			activities = append(activities, models.BrokerActivity{
+				Line:           line,
+				Kind:           models.BrokerActivityDividend,
+				Date:           date,
+				ISIN:           key.isin,
+				Name:           csvField(rec, productCol),
+				InvestmentType: models.InvestmentStock,
+				Currency:       currency,
+			})
+		}
+		if isTax {
+			activities[idx].TaxWithheld = activities[idx].TaxWithheld.Sub(amount)
+		} else {
+			activities[idx].Amount = activities[idx].Amount.Add(amount)
+		}
+	}
+
+	// Tax booked without its dividend has nothing to attach to
+	kept := activities[:0]
+	for _, a := range activities {
+		if !a.Amount.IsPositive() {
+			issues = append(issues, fmt.Sprintf("line %d: dividend tax without a dividend for %s", a.Line, a.ISIN))
+			continue
+		}
+		kept = append(kept, a)
+	}
+
+	return kept, issues, nil
+}
+
+// --- Trading 212 ---
+
+// trading212Fees are the charge columns Trading 212 adds to a trade, each paired
+// with a "Currency (...)" column.
+var trading212Fees = []string{
+	"currency conversion fee",
+	"stamp duty reserve tax",
+	"stamp duty",
+	"transaction fee",
+	"french transaction tax",
+	"finra fee",
+	"ptm levy",
+}
+
+// ParseTrading212CSV reads a Trading 212 history export. Deposits, interest and
+// other cash movements are ignored.
+func ParseTrading212CSV(r io.Reader) ([]models.BrokerActivity, []string, error) {
+	t, err := readCSVTable(r, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actionCol, timeCol := t.col("action"), t.col("time")
+	isinCol, tickerCol, nameCol := t.col("isin"), t.col("ticker"), t.col("name")
+	qtyCol, priceCol := t.col("no. of shares"), t.col("price / share")
+	priceCurCol, rateCol := t.col("currency (price / share)"), t.col("exchange rate")
+	taxCol, taxCurCol := t.col("withholding tax"), t.col("currency (withholding tax)")
+	if actionCol < 0 || timeCol < 0 || tickerCol < 0 || qtyCol < 0 || priceCol < 0 {
+		return nil, nil, errors.New("not a Trading 212 export: missing action, time, ticker, shares or price column")
+	}
+
+	var feeCols [][2]int
+	for _, name := range trading212Fees {
+		if i, ok := t.cols[name]; ok {
+			feeCols = append(feeCols, [2]int{i, t.col("currency (" + name + ")")})
+		}
+	}
+
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+	)
+	for n, rec := range t.rows {
+		line := n + 2
+		action := strings.ToLower(csvField(rec, actionCol))
+
+		var kind models.BrokerActivityKind
+		switch {
+		case strings.HasSuffix(action, " buy"):
+			kind = models.BrokerActivityBuy
+		case strings.HasSuffix(action, " sell"):
+			kind = models.BrokerActivitySell
+		case strings.HasPrefix(action, "dividend"):
+			kind = models.BrokerActivityDividend
+		default:
+			continue
+		}
+
+		date, ok := parseBrokerDate(csvField(rec, timeCol), "")
+		if !ok {
+			issues = append(issues, fmt.Sprintf("line %d: invalid time %q", line, csvField(rec, timeCol)))
+			continue
+		}
+		qty, err := parseBrokerDecimal(csvField(rec, qtyCol), '.')
+		if err != nil || !qty.IsPositive() {
+			issues = append(issues, fmt.Sprintf("line %d: invalid number of shares %q", line, csvField(rec, qtyCol)))
+			continue
+		}
+		price, err := parseBrokerDecimal(csvField(rec, priceCol), '.')
+		if err != nil || price.IsNegative() {
+			issues = append(issues, fmt.Sprintf("line %d: invalid price %q", line, csvField(rec, priceCol)))
+			continue
+		}
+		currency := strings.ToUpper(csvField(rec, priceCurCol))
+		if len(currency) != 3 {
+			issues = append(issues, fmt.Sprintf("line %d: missing price currency", line))
+			continue
+		}
+
+		// Charges are in their own currency, usually the account's; the exchange
+		// rate column is price currency per account currency.
+		rate := decimal.NewFromInt(1)
+		if r, err := parseBrokerDecimal(csvField(rec, rateCol), '.'); err == nil && r.IsPositive() {
+			rate = r
+		}
+		toTradeCurrency := func(amount decimal.Decimal, cur string) decimal.Decimal {
+			if cur == "" || strings.EqualFold(cur, currency) {
+				return amount
+			}
+			return amount.Mul(rate).Round(4)
+		}
+
+		a := models.BrokerActivity{
+			Line:           line,
+			Kind:           kind,
+			Date:           date,
+			Ticker:         strings.ToUpper(csvField(rec, tickerCol)),
+			ISIN:           strings.ToUpper(csvField(rec, isinCol)),
+			Name:           csvField(rec, nameCol),
+			InvestmentType: models.InvestmentStock,
+			Quantity:       qty,
+			Price:          price,
+			Currency:       currency,
+		}
+
+		if kind == models.BrokerActivityDividend {
+			a.Amount = qty.Mul(price).Round(4)
+			if tax, err := parseBrokerDecimal(csvField(rec, taxCol), '.'); err == nil {
+				a.TaxWithheld = toTradeCurrency(tax.Abs(), csvField(rec, taxCurCol))
+			}
+		} else {
+			for _, fc := range feeCols {
+				fee, err := parseBrokerDecimal(csvField(rec, fc[0]), '.')
+				if err != nil {
+					continue
+				}
+				a.Fee = a.Fee.Add(toTradeCurrency(fee.Abs(), csvField(rec, fc[1])))
+			}
+		}
+
+		activities = append(activities, a)
+	}
+
+	return activities, issues, nil
+}
+
+// --- Generic CSV ---
+
+// ParseMappedBrokerCSV reads a CSV whose columns are named by mapping. The type
+// column holds buy, sell or dividend (and common synonyms).
+func ParseMappedBrokerCSV(r io.Reader, mapping models.BrokerColumnMapping) ([]models.BrokerActivity, []string, error) {
+	var delimiter rune
+	if mapping.Delimiter != "" {
+		delimiter = []rune(mapping.Delimiter)[0]
+	}
+
+	t, err := readCSVTable(r, delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lookup := func(name string, required bool) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := t.cols[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if required {
+				return -1, fmt.Errorf("column %q not found", name)
+			}
+			return -1, nil
+		}
+		return i, nil
+	}
+
+	cols := make(map[string]int)
+	for key, spec := range map[string]struct {
+		name     string
+		required bool
+	}{
+		"date":     {mapping.Date, true},
+		"type":     {mapping.Type, true},
+		"ticker":   {mapping.Ticker, true},
+		"quantity": {mapping.Quantity, true},
+		"price":    {mapping.Price, true},
+		"currency": {mapping.Currency, true},
+		"name":     {mapping.Name, false},
+		"fee":      {mapping.Fee, false},
+		"amount":   {mapping.Amount, false},
+		"tax":      {mapping.TaxWithheld, false},
+		"itype":    {mapping.InvestmentType, false},
+	} {
+		i, err := lookup(spec.name, spec.required)
+		if err != nil {
+			return nil, nil, err
+		}
+		cols[key] = i
+	}
+
+	var sep byte
+	switch mapping.DecimalSeparator {
+	case ".", ",":
+		sep = mapping.DecimalSeparator[0]
+	case "":
+		sep, err = t.decimalSeparator(cols["quantity"], cols["price"], cols["fee"], cols["amount"], cols["tax"])
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("decimal separator must be '.' or ',', got %q", mapping.DecimalSeparator)
+	}
+
+	var (
+		activities []models.BrokerActivity
+		issues     []string
+	)
+	for n, rec := range t.rows {
+		line := n + 2
+		if len(rec) == 0 || (len(rec) == 1 && strings.TrimSpace(rec[0]) == "") {
+			continue
+		}
+
+		var kind models.BrokerActivityKind
+		switch strings.ToLower(csvField(rec, cols["type"])) {
+		case "buy", "b", "purchase", "bought":
+			kind = models.BrokerActivityBuy
+		case "sell", "s", "sale", "sold":
+			kind = models.BrokerActivitySell
+		case "dividend", "div", "distribution":
+			kind = models.BrokerActivityDividend
+		default:
+			issues = append(issues, fmt.Sprintf("line %d: unknown type %q", line, csvField(rec, cols["type"])))
+			continue
+		}
+
+		date, ok := parseBrokerDate(csvField(rec, cols["date"]), mapping.DateFormat)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("line %d: invalid date %q", line, csvField(rec, cols["date"])))
+			continue
+		}
+		ticker := strings.ToUpper(csvField(rec, cols["ticker"]))
+		if ticker == "" {
+			issues = append(issues, fmt.Sprintf("line %d: ticker is empty", line))
+			continue
+		}
+		currency := strings.ToUpper(csvField(rec, cols["currency"]))
+		if len(currency) != 3 {
+			issues = append(issues, fmt.Sprintf("line %d: invalid currency %q", line, currency))
+			continue
+		}
+
+		qty, qtyErr := parseBrokerDecimal(csvField(rec, cols["quantity"]), sep)
+		price, priceErr := parseBrokerDecimal(csvField(rec, cols["price"]), sep)
+		fee, feeErr := parseBrokerDecimal(csvField(rec, cols["fee"]), sep)
+		amount, amountErr := parseBrokerDecimal(csvField(rec, cols["amount"]), sep)
+		tax, taxErr := parseBrokerDecimal(csvField(rec, cols["tax"]), sep)
+		if err := errors.Join(qtyErr, priceErr, feeErr, amountErr, taxErr); err != nil {
+			issues = append(issues, fmt.Sprintf("line %d: invalid number: %v", line, err))
+			continue
+		}
+
+		investmentType := models.InvestmentStock
+		if v := strings.ToLower(csvField(rec, cols["itype"])); v != "" {
+			investmentType = models.InvestmentType(v)
+		}
+
+		a := models.BrokerActivity{
+			Line:           line,
+			Kind:           kind,
+			Date:           date,
+			Ticker:         ticker,
+			Name:           csvField(rec, cols["name"]),
+			InvestmentType: investmentType,
+			Quantity:       qty.Abs(),
+			Price:          price.Abs(),
+			Fee:            fee.Abs(),
+			Currency:       currency,
+		}
+
+		if kind == models.BrokerActivityDividend {
+			a.Amount = amount.Abs()
+			if a.Amount.IsZero() {
+				a.Amount = a.Quantity.Mul(a.Price).Round(4)
+			}
+			a.TaxWithheld = tax.Abs()
+			if !a.Amount.IsPositive() {
+				issues = append(issues, fmt.Sprintf("line %d: dividend amount is missing", line))
+				continue
+			}
+		} else if !a.Quantity.IsPositive() {
+			issues = append(issues, fmt.Sprintf("line %d: quantity must be positive", line))
+			continue
+		}
+
+		activities = append(activities, a)
+	}
+
+	return activities, issues, nil
+}
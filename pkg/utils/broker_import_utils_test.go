@@ -0,0 +1,188 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ibkrFlexXML = `<FlexQueryResponse queryName="trades" type="AF">
+<FlexStatements count="1">
+<FlexStatement accountId="U1234567" fromDate="20240101" toDate="20241231">
+<Trades>
+<Trade assetCategory="STK" symbol="VWRL" description="VANGUARD FTSE ALL-WORLD" isin="IE00B3RBWM25" listingExchange="AEB" currency="EUR" tradeDate="20240115" quantity="10" tradePrice="105.2" ibCommission="-1.25" buySell="BUY" levelOfDetail="EXECUTION"/>
+<Trade assetCategory="STK" symbol="VWRL" currency="EUR" tradeDate="20240115" quantity="10" tradePrice="105.2" buySell="BUY" levelOfDetail="ORDER"/>
+<Trade assetCategory="STK" symbol="AAPL" description="APPLE INC" isin="US0378331005" listingExchange="NASDAQ" currency="USD" tradeDate="20240320" quantity="-5" tradePrice="178.5" ibCommission="-1" buySell="SELL" levelOfDetail="EXECUTION"/>
+<Trade assetCategory="CASH" symbol="EUR.USD" currency="USD" tradeDate="20240320" quantity="1000" tradePrice="1.09" buySell="BUY" levelOfDetail="EXECUTION"/>
+</Trades>
+<CashTransactions>
+<CashTransaction type="Dividends" assetCategory="STK" symbol="AAPL" isin="US0378331005" currency="USD" dateTime="20240215;202000" amount="2.40" description="AAPL CASH DIVIDEND USD 0.24 PER SHARE"/>
+<CashTransaction type="Withholding Tax" assetCategory="STK" symbol="AAPL" currency="USD" dateTime="20240215;202000" amount="-0.36" description="AAPL US TAX"/>
+<CashTransaction type="Deposits/Withdrawals" currency="EUR" dateTime="20240101" amount="5000"/>
+</CashTransactions>
+</FlexStatement>
+</FlexStatements>
+</FlexQueryResponse>`
+
+func TestParseIBKRFlex_TradesAndDividends(t *testing.T) {
+	activities, issues, err := utils.ParseBrokerStatement(models.BrokerIBKR, strings.NewReader(ibkrFlexXML), nil)
+	require.NoError(t, err)
+	require.Len(t, activities, 3)
+	require.Len(t, issues, 1, "the FX conversion is reported, not imported")
+	assert.Contains(t, issues[0], "CASH")
+
+	buy := activities[0]
+	assert.Equal(t, models.BrokerActivityBuy, buy.Kind)
+	assert.Equal(t, "VWRL.AS", buy.Ticker)
+	assert.Equal(t, date(2024, 1, 15), buy.Date)
+	assert.True(t, df(10).Equal(buy.Quantity))
+	assert.True(t, df(1.25).Equal(buy.Fee))
+
+	div := activities[1]
+	assert.Equal(t, models.BrokerActivityDividend, div.Kind)
+	assert.Equal(t, "AAPL", div.Ticker)
+	assert.True(t, df(2.4).Equal(div.Amount))
+	assert.True(t, df(0.36).Equal(div.TaxWithheld))
+
+	sell := activities[2]
+	assert.Equal(t, models.BrokerActivitySell, sell.Kind)
+	assert.True(t, df(5).Equal(sell.Quantity))
+	assert.Equal(t, "USD", sell.Currency)
+}
+
+func TestParseDegiroCSV_Transactions(t *testing.T) {
+	csv := "Date,Time,Product,ISIN,Reference exchange,Venue,Quantity,Price,,Local value,,Value,,Exchange rate,Transaction and/or third party fees,,Total,,Order ID\n" +
+		"15-01-2024,09:04,VANGUARD FTSE AW,IE00B3RBWM25,EAM,XAMS,10,\"105,20\",EUR,\"-1052,00\",EUR,\"-1052,00\",EUR,,\"-2,00\",EUR,\"-1054,00\",EUR,abc\n" +
+		"20-03-2024,15:31,APPLE INC,US0378331005,NDQ,XNAS,-5,\"178,50\",USD,\"892,50\",USD,\"818,81\",EUR,\"1,09\",\"-0,50\",EUR,\"818,31\",EUR,def\n"
+
+	activities, issues, err := utils.ParseBrokerStatement(models.BrokerDegiro, strings.NewReader(csv), nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	require.Len(t, activities, 2)
+
+	assert.Equal(t, models.BrokerActivityBuy, activities[0].Kind)
+	assert.Equal(t, "IE00B3RBWM25", activities[0].ISIN)
+	assert.Empty(t, activities[0].Ticker)
+	assert.True(t, df(105.2).Equal(activities[0].Price))
+	assert.True(t, df(2).Equal(activities[0].Fee))
+
+	// EUR fee restated in the USD trade currency
+	assert.Equal(t, models.BrokerActivitySell, activities[1].Kind)
+	assert.Equal(t, "USD", activities[1].Currency)
+	assert.True(t, df(0.545).Equal(activities[1].Fee), activities[1].Fee.String())
+}
+
+func TestParseDegiroCSV_AccountDividends(t *testing.T) {
+	csv := "Date,Time,Value date,Product,ISIN,Description,FX,Change,,Balance,,Order Id\n" +
+		"15-02-2024,07:31,15-02-2024,APPLE INC,US0378331005,Dividend,,USD,\"2,40\",USD,\"2,40\",\n" +
+		"15-02-2024,07:31,15-02-2024,APPLE INC,US0378331005,Dividend Tax,,USD,\"-0,36\",USD,\"2,04\",\n" +
+		"16-02-2024,10:00,16-02-2024,,,Deposit,,EUR,\"500,00\",EUR,\"500,00\",\n"
+
+	activities, issues, err := utils.ParseBrokerStatement(models.BrokerDegiro, strings.NewReader(csv), nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	require.Len(t, activities, 1)
+
+	assert.Equal(t, models.BrokerActivityDividend, activities[0].Kind)
+	assert.True(t, df(2.4).Equal(activities[0].Amount))
+	assert.True(t, df(0.36).Equal(activities[0].TaxWithheld))
+}
+
+func TestParseTrading212CSV(t *testing.T) {
+	csv := "Action,Time,ISIN,Ticker,Name,No. of shares,Price / share,Currency (Price / share),Exchange rate,Result,Currency (Result),Total,Currency (Total),Withholding tax,Currency (Withholding tax),Currency conversion fee,Currency (Currency conversion fee)\n" +
+		"Deposit,2024-01-02 10:00:00,,,,,,,,,,1000.00,EUR,,,,\n" +
+		"Market buy,2024-01-03 14:30:05,US0378331005,AAPL,Apple,2,185.00,USD,1.10,,,337.13,EUR,,,0.50,EUR\n" +
+		"Dividend (Ordinary),2024-02-15 08:00:00,US0378331005,AAPL,Apple,2,0.24,USD,1.08,,,0.38,EUR,0.07,USD,,\n"
+
+	activities, issues, err := utils.ParseBrokerStatement(models.BrokerTrading212, strings.NewReader(csv), nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	require.Len(t, activities, 2)
+
+	buy := activities[0]
+	assert.Equal(t, models.BrokerActivityBuy, buy.Kind)
+	assert.Equal(t, "AAPL", buy.Ticker)
+	assert.True(t, df(0.55).Equal(buy.Fee), buy.Fee.String())
+
+	div := activities[1]
+	assert.Equal(t, models.BrokerActivityDividend, div.Kind)
+	assert.True(t, df(0.48).Equal(div.Amount))
+	assert.True(t, df(0.07).Equal(div.TaxWithheld))
+}
+
+func TestParseMappedBrokerCSV(t *testing.T) {
+	mapping := &models.BrokerColumnMapping{
+		Date: "Trade Date", Type: "Side", Ticker: "Symbol", Quantity: "Qty", Price: "Price",
+		Currency: "Ccy", Fee: "Commission", TaxWithheld: "Tax", DateFormat: "02/01/2006", Delimiter: ";",
+	}
+	csv := "Trade Date;Side;Symbol;Qty;Price;Ccy;Commission;Tax\n" +
+		"05/03/2024;Sell;msft;1;410,5;USD;1;\n" +
+		"05/03/2024;Buy;msft;3;400;USD;1;\n" +
+		"10/03/2024;Dividend;MSFT;3;0,75;USD;;0,34\n" +
+		"11/03/2024;Split;MSFT;;;USD;;\n"
+
+	activities, issues, err := utils.ParseBrokerStatement(models.BrokerCSV, strings.NewReader(csv), mapping)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0], "line 5")
+	require.Len(t, activities, 3)
+
+	assert.Equal(t, models.BrokerActivityBuy, activities[0].Kind, "buys sort ahead of same-day sells")
+	assert.Equal(t, "MSFT", activities[0].Ticker)
+	assert.Equal(t, date(2024, 3, 5), activities[0].Date)
+	assert.Equal(t, models.BrokerActivitySell, activities[1].Kind)
+	assert.True(t, df(410.5).Equal(activities[1].Price))
+	assert.True(t, df(2.25).Equal(activities[2].Amount))
+	assert.True(t, df(0.34).Equal(activities[2].TaxWithheld))
+}
+
+func TestParseMappedBrokerCSV_DecimalSeparatorPerFile(t *testing.T) {
+	mapping := models.BrokerColumnMapping{
+		Date: "date", Type: "type", Ticker: "ticker", Quantity: "qty", Price: "price", Currency: "ccy", Delimiter: ";",
+	}
+
+	// "2,50" settles the file on a decimal comma, so "1,234" is one and a bit units
+	activities, issues, err := utils.ParseMappedBrokerCSV(strings.NewReader(
+		"date;type;ticker;qty;price;ccy\n2024-03-05;buy;ACME;1,234;2,50;EUR\n"), mapping)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	require.Len(t, activities, 1)
+	assert.True(t, df(1.234).Equal(activities[0].Quantity), activities[0].Quantity.String())
+
+	// "2.50" settles it on a decimal point, so "1,234" groups thousands
+	activities, issues, err = utils.ParseMappedBrokerCSV(strings.NewReader(
+		"date;type;ticker;qty;price;ccy\n2024-03-05;buy;ACME;1,234;2.50;EUR\n"), mapping)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	assert.True(t, df(1234).Equal(activities[0].Quantity), activities[0].Quantity.String())
+
+	// Nothing settles it: the row is rejected rather than guessed
+	_, issues, err = utils.ParseMappedBrokerCSV(strings.NewReader(
+		"date;type;ticker;qty;price;ccy\n2024-03-05;buy;ACME;1,234;100;EUR\n"), mapping)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0], "ambiguous")
+
+	// ...unless the mapping names the separator
+	mapping.DecimalSeparator = ","
+	activities, issues, err = utils.ParseMappedBrokerCSV(strings.NewReader(
+		"date;type;ticker;qty;price;ccy\n2024-03-05;buy;ACME;1,234;100;EUR\n"), mapping)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	assert.True(t, df(1.234).Equal(activities[0].Quantity), activities[0].Quantity.String())
+
+	// A file that uses both is refused as a whole
+	mapping.DecimalSeparator = ""
+	_, _, err = utils.ParseMappedBrokerCSV(strings.NewReader(
+		"date;type;ticker;qty;price;ccy\n2024-03-05;buy;ACME;2,5;100.25;EUR\n"), mapping)
+	assert.Error(t, err)
+}
+
+func TestParseBrokerStatement_MappedCSVNeedsMapping(t *testing.T) {
+	_, _, err := utils.ParseBrokerStatement(models.BrokerCSV, strings.NewReader("a,b\n"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// MonthStart returns midnight UTC on the first day of t's month.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// BudgetMonth is one month of a budget: the amount planned for it and what was spent.
+type BudgetMonth struct {
+	Planned decimal.Decimal
+	Spent   decimal.Decimal
+}
+
+// BudgetCarryover walks months oldest first and returns what rolls into the
+// month after the last one. Each month's leftover includes what was carried
+// into it, so rollover accumulates for as long as the rule lets it.
+func BudgetCarryover(rollover models.CategoryBudgetRollover, months []BudgetMonth) decimal.Decimal {
+	carry := decimal.Zero
+	for _, m := range months {
+		left := m.Planned.Add(carry).Sub(m.Spent)
+		switch rollover {
+		case models.CategoryBudgetRolloverUnspent:
+			carry = decimal.Max(left, decimal.Zero)
+		case models.CategoryBudgetRolloverOverspent:
+			carry = decimal.Min(left, decimal.Zero)
+		case models.CategoryBudgetRolloverBoth:
+			carry = left
+		default:
+			carry = decimal.Zero
+		}
+	}
+	return carry
+}
+
+// CategoryOutflows turns monthly category totals into spending per category as
+// a positive amount. Uncategorized rows are left out.
+func CategoryOutflows(rows []models.YearlyCategoryRow) map[int64]decimal.Decimal {
+	out := make(map[int64]decimal.Decimal, len(rows))
+	for _, r := range rows {
+		if r.CategoryID == 0 {
+			continue
+		}
+		outflow, err := decimal.NewFromString(r.OutflowText)
+		if err != nil || outflow.IsZero() {
+			continue
+		}
+		out[r.CategoryID] = out[r.CategoryID].Add(outflow.Abs())
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package utils_test
+
+import (
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetCarryover(t *testing.T) {
+	// 100 planned each month: 40 left in the first, 30 overspent in the second
+	months := []utils.BudgetMonth{
+		{Planned: df(100), Spent: df(60)},
+		{Planned: df(100), Spent: df(130)},
+	}
+
+	assert.True(t, utils.BudgetCarryover(models.CategoryBudgetRolloverNone, months).IsZero())
+	// unspent: 40 carried, 140 - 130 leaves 10
+	assert.True(t, df(10).Equal(utils.BudgetCarryover(models.CategoryBudgetRolloverUnspent, months)))
+	// overspent: the 40 is dropped, then 30 over
+	assert.True(t, df(-30).Equal(utils.BudgetCarryover(models.CategoryBudgetRolloverOverspent, months)))
+	assert.True(t, df(10).Equal(utils.BudgetCarryover(models.CategoryBudgetRolloverBoth, months)))
+
+	// A deficit only carried by overspent/both keeps growing
+	deficit := []utils.BudgetMonth{
+		{Planned: df(100), Spent: df(150)},
+		{Planned: df(100), Spent: df(120)},
+	}
+	assert.True(t, utils.BudgetCarryover(models.CategoryBudgetRolloverUnspent, deficit).IsZero())
+	assert.True(t, df(-70).Equal(utils.BudgetCarryover(models.CategoryBudgetRolloverBoth, deficit)))
+}
+
+func TestCategoryOutflows(t *testing.T) {
+	rows := []models.YearlyCategoryRow{
+		{CategoryID: 3, OutflowText: "-120.50", InflowText: "0"},
+		{CategoryID: 4, OutflowText: "0", InflowText: "2000"},
+		{CategoryID: 0, OutflowText: "-15"},
+	}
+
+	out := utils.CategoryOutflows(rows)
+	assert.Len(t, out, 1)
+	assert.True(t, df(120.5).Equal(out[3]))
+}
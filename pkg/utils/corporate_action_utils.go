@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// splitRatioTolerance is how far a price move may sit from a whole ratio and still
+// be read as a split rather than a market move.
+var splitRatioTolerance = decimal.NewFromFloat(0.03)
+
+// LikelySplitRatio reports whether a move from oldPrice to newPrice looks like an
+// unrecorded split or reverse split, returning the ratio as from:to units
+// (a 10:1 split is 1, 10). Only whole ratios of at least 2 are recognised.
+func LikelySplitRatio(oldPrice, newPrice decimal.Decimal) (int64, int64, bool) {
+	if !oldPrice.IsPositive() || !newPrice.IsPositive() {
+		return 0, 0, false
+	}
+
+	if oldPrice.GreaterThan(newPrice) {
+		if n, ok := wholeRatio(oldPrice.Div(newPrice)); ok {
+			return 1, n, true
+		}
+		return 0, 0, false
+	}
+
+	if n, ok := wholeRatio(newPrice.Div(oldPrice)); ok {
+		return n, 1, true
+	}
+	return 0, 0, false
+}
+
+func wholeRatio(r decimal.Decimal) (int64, bool) {
+	n := r.Round(0)
+	if n.LessThan(decimal.NewFromInt(2)) || n.GreaterThan(decimal.NewFromInt(1000)) {
+		return 0, false
+	}
+	if r.Sub(n).Abs().Div(n).GreaterThan(splitRatioTolerance) {
+		return 0, false
+	}
+	return n.IntPart(), true
+}
+
+// SpinOffLots derives the lots of a spun-off holding from the parent's open lots.
+// Each lot keeps its purchase date so holding periods carry over; quantity is
+// scaled by factor and costPercent of the parent's cost basis and fees moves across.
+func SpinOffLots(lots []FifoLot, factor, costPercent decimal.Decimal) []FifoLot {
+	out := make([]FifoLot, 0, len(lots))
+	for _, lot := range lots {
+		qty := lot.Quantity.Mul(factor)
+		if !qty.IsPositive() {
+			continue
+		}
+		out = append(out, FifoLot{
+			TxnDate:    lot.TxnDate,
+			Quantity:   qty,
+			ValueAtBuy: lot.ValueAtBuy.Mul(costPercent),
+			Fee:        lot.Fee.Mul(costPercent),
+		})
+	}
+	return out
+}
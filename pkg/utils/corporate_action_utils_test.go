@@ -0,0 +1,56 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// --- LikelySplitRatio ---
+
+func TestLikelySplitRatio_TenForOneSplit(t *testing.T) {
+	from, to, ok := utils.LikelySplitRatio(df(1200), df(120.4))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), from)
+	assert.Equal(t, int64(10), to)
+}
+
+func TestLikelySplitRatio_ReverseSplit(t *testing.T) {
+	from, to, ok := utils.LikelySplitRatio(df(2), df(40))
+	assert.True(t, ok)
+	assert.Equal(t, int64(20), from)
+	assert.Equal(t, int64(1), to)
+}
+
+func TestLikelySplitRatio_MarketMoveIsNotASplit(t *testing.T) {
+	_, _, ok := utils.LikelySplitRatio(df(100), df(88))
+	assert.False(t, ok)
+
+	_, _, ok = utils.LikelySplitRatio(df(100), df(40))
+	assert.False(t, ok)
+}
+
+func TestLikelySplitRatio_InvalidPrices(t *testing.T) {
+	_, _, ok := utils.LikelySplitRatio(df(0), df(10))
+	assert.False(t, ok)
+}
+
+// --- SpinOffLots ---
+
+func TestSpinOffLots_KeepsDatesAndSplitsCost(t *testing.T) {
+	bought := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	lots := []utils.FifoLot{
+		{TxnDate: bought, Quantity: df(10), ValueAtBuy: df(1000), Fee: df(10)},
+		{TxnDate: bought.AddDate(1, 0, 0), Quantity: df(0), ValueAtBuy: df(0), Fee: df(0)},
+	}
+
+	out := utils.SpinOffLots(lots, df(0.5), df(0.2))
+
+	assert.Len(t, out, 1)
+	assert.Equal(t, bought, out[0].TxnDate)
+	assert.True(t, df(5).Equal(out[0].Quantity))
+	assert.True(t, df(200).Equal(out[0].ValueAtBuy))
+	assert.True(t, df(2).Equal(out[0].Fee))
+}
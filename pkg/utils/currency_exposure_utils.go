@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// CurrencyPosition is what is held in one currency over a range, one entry per
+// day: cash balances, holdings at market value, the money put into holdings that
+// day (negative when taken out), all in the currency itself, and the rate into
+// the reporting currency.
+type CurrencyPosition struct {
+	Currency string
+	Cash     []decimal.Decimal
+	Holdings []decimal.Decimal
+	Flows    []decimal.Decimal
+	Rates    []decimal.Decimal
+}
+
+func dayValue(values []decimal.Decimal, i int) decimal.Decimal {
+	if i < 0 || i >= len(values) {
+		return decimal.Zero
+	}
+	return values[i]
+}
+
+// Value is the total held on day i, in the currency itself.
+func (p CurrencyPosition) Value(i int) decimal.Decimal {
+	return dayValue(p.Cash, i).Add(dayValue(p.Holdings, i))
+}
+
+// Converted is the total held on day i in the reporting currency.
+func (p CurrencyPosition) Converted(i int) decimal.Decimal {
+	return p.Value(i).Mul(dayValue(p.Rates, i))
+}
+
+// AttributeFX splits the change in p's converted value over the range. The FX
+// gain is what the amount held at each day's open earned from that day's rate
+// move. The price gain is what holdings earned in their own currency, net of the
+// money put in, valued at the day's rate; cash earns none.
+func AttributeFX(p CurrencyPosition) (decimal.Decimal, decimal.Decimal) {
+	fxGain, priceGain := decimal.Zero, decimal.Zero
+	for i := 1; i < len(p.Rates); i++ {
+		fxGain = fxGain.Add(p.Value(i - 1).Mul(p.Rates[i].Sub(p.Rates[i-1])))
+
+		earned := dayValue(p.Holdings, i).Sub(dayValue(p.Holdings, i-1)).Sub(dayValue(p.Flows, i))
+		priceGain = priceGain.Add(earned.Mul(p.Rates[i]))
+	}
+	return fxGain, priceGain
+}
+
+// FillDaily spreads dated points over days starting at from, carrying each value
+// forward until the next one. Days before the first point are zero.
+func FillDaily(points []models.ChartPoint, from time.Time, days int) []decimal.Decimal {
+	from = from.UTC().Truncate(24 * time.Hour)
+	out := make([]decimal.Decimal, days)
+	set := make([]bool, days)
+	carry, seen := decimal.Zero, false
+	for _, p := range points {
+		i := dayIndex(from, p.Date)
+		switch {
+		case i < 0:
+			carry, seen = p.Value, true
+		case i < days:
+			out[i], set[i] = p.Value, true
+		}
+	}
+	for i := range out {
+		if set[i] {
+			carry, seen = out[i], true
+			continue
+		}
+		if seen {
+			out[i] = carry
+		}
+	}
+	return out
+}
+
+// DailyRates is FillDaily for exchange rates: days before the first known rate
+// take that rate instead of zero. It returns nil when there are no rates at all.
+func DailyRates(rates []models.ChartPoint, from time.Time, days int) []decimal.Decimal {
+	if len(rates) == 0 {
+		return nil
+	}
+	out := FillDaily(rates, from, days)
+	first := -1
+	for i, r := range out {
+		if !r.IsZero() {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	for i := 0; i < first; i++ {
+		out[i] = out[first]
+	}
+	return out
+}
+
+// ChartSampleDays picks which of days starting at from are charted, at the same
+// granularity as the net worth chart: every day up to 90 days, the last day of
+// each week up to 370 and of each month beyond. The final day is always included.
+func ChartSampleDays(from time.Time, days int) []int {
+	if days <= 0 {
+		return nil
+	}
+	from = from.UTC().Truncate(24 * time.Hour)
+
+	var out []int
+	for i := 0; i < days-1; i++ {
+		d := from.AddDate(0, 0, i)
+		switch {
+		case days <= 90:
+		case days <= 370:
+			if d.Weekday() != time.Sunday {
+				continue
+			}
+		default:
+			if d.AddDate(0, 0, 1).Day() != 1 {
+				continue
+			}
+		}
+		out = append(out, i)
+	}
+	return append(out, days-1)
+}
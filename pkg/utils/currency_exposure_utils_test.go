@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func decs(values ...float64) []decimal.Decimal {
+	out := make([]decimal.Decimal, len(values))
+	for i, v := range values {
+		out[i] = df(v)
+	}
+	return out
+}
+
+func TestAttributeFX_SeparatesRateFromPriceMoves(t *testing.T) {
+	// 100 USD of stock at 0.90 EUR; the stock gains 10% on day 1 and the dollar
+	// weakens to 0.80 on day 2.
+	p := utils.CurrencyPosition{
+		Currency: "USD",
+		Holdings: decs(100, 110, 110),
+		Rates:    decs(0.9, 0.9, 0.8),
+	}
+
+	fx, price := utils.AttributeFX(p)
+	assert.True(t, df(-11).Equal(fx), fx.String())
+	assert.True(t, df(9).Equal(price), price.String())
+
+	// Together they explain the whole change in converted value: 88 - 90
+	total := p.Converted(2).Sub(p.Converted(0))
+	assert.True(t, total.Equal(fx.Add(price)), total.String())
+}
+
+func TestAttributeFX_ContributionsAreNotGains(t *testing.T) {
+	// 50 USD cash goes into the stock on day 1; only the rate move on day 2 counts,
+	// and it applies to everything held over that day.
+	p := utils.CurrencyPosition{
+		Currency: "USD",
+		Cash:     decs(50, 0, 0),
+		Holdings: decs(100, 150, 150),
+		Flows:    decs(0, 50, 0),
+		Rates:    decs(1, 1, 1.1),
+	}
+
+	fx, price := utils.AttributeFX(p)
+	assert.True(t, df(15).Equal(fx), fx.String())
+	assert.True(t, price.IsZero(), price.String())
+}
+
+func TestFillDaily_CarriesForward(t *testing.T) {
+	points := []models.ChartPoint{
+		{Date: perfDay(-3), Value: df(5)},
+		{Date: perfDay(2), Value: df(7)},
+	}
+	got := utils.FillDaily(points, perfStart, 4)
+	assert.Equal(t, []string{"5", "5", "7", "7"}, []string{got[0].String(), got[1].String(), got[2].String(), got[3].String()})
+
+	late := utils.FillDaily(points[1:], perfStart, 4)
+	assert.True(t, late[0].IsZero(), "nothing before the first point")
+}
+
+func TestDailyRates_BackfillsFirstRate(t *testing.T) {
+	rates := utils.DailyRates([]models.ChartPoint{{Date: perfDay(1), Value: df(1.2)}}, perfStart, 3)
+	assert.True(t, df(1.2).Equal(rates[0]))
+	assert.True(t, df(1.2).Equal(rates[2]))
+
+	assert.Nil(t, utils.DailyRates(nil, perfStart, 3))
+}
+
+func TestChartSampleDays(t *testing.T) {
+	assert.Len(t, utils.ChartSampleDays(perfStart, 30), 30)
+
+	// 2026-01-01 is a Thursday: Sundays fall on days 3, 10, ... plus the last day
+	weekly := utils.ChartSampleDays(perfStart, 120)
+	assert.Equal(t, 3, weekly[0])
+	assert.Equal(t, 119, weekly[len(weekly)-1])
+
+	monthly := utils.ChartSampleDays(perfStart, 400)
+	assert.Equal(t, 30, monthly[0], "January 31st")
+	assert.Equal(t, 399, monthly[len(monthly)-1])
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"sort"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// EnvelopeOpening is what a month starts with.
+type EnvelopeOpening struct {
+	ToBeAssigned decimal.Decimal
+	// Overspent is the previous month's uncovered overspending, already
+	// taken out of ToBeAssigned.
+	Overspent decimal.Decimal
+	Balances  map[int64]decimal.Decimal
+}
+
+// EnvelopeActivity is what happened to the money during a month.
+type EnvelopeActivity struct {
+	Income      decimal.Decimal
+	Expected    decimal.Decimal
+	Unenveloped decimal.Decimal
+	Spent       map[int64]decimal.Decimal
+	Movements   []models.EnvelopeMovement
+}
+
+type EnvelopeBalance struct {
+	Carryover decimal.Decimal
+	Assigned  decimal.Decimal
+	Spent     decimal.Decimal
+	Available decimal.Decimal
+}
+
+// EnvelopeActivityFromTotals splits monthly category totals into income,
+// spending per envelope and spending no envelope covers. Refunds in an
+// envelope's categories go back to that envelope instead of counting as income.
+func EnvelopeActivityFromTotals(rows []models.YearlyCategoryRow, envelopeOf map[int64]int64) EnvelopeActivity {
+	act := EnvelopeActivity{Spent: make(map[int64]decimal.Decimal)}
+	for _, r := range rows {
+		inflow, err := decimal.NewFromString(r.InflowText)
+		if err != nil {
+			inflow = decimal.Zero
+		}
+		outflow, err := decimal.NewFromString(r.OutflowText)
+		if err != nil {
+			outflow = decimal.Zero
+		}
+
+		if envelopeID, ok := envelopeOf[r.CategoryID]; ok && r.CategoryID != 0 {
+			act.Spent[envelopeID] = act.Spent[envelopeID].Add(outflow.Abs()).Sub(inflow)
+			continue
+		}
+		act.Income = act.Income.Add(inflow)
+		act.Unenveloped = act.Unenveloped.Add(outflow.Abs())
+	}
+	return act
+}
+
+// StepEnvelopeMonth applies a month's activity to its opening balances. It
+// returns each envelope's balances, the net amount assigned out of the pool
+// and what is left to be assigned.
+func StepEnvelopeMonth(open EnvelopeOpening, act EnvelopeActivity) (map[int64]EnvelopeBalance, decimal.Decimal, decimal.Decimal) {
+	lines := make(map[int64]EnvelopeBalance)
+	for id, carry := range open.Balances {
+		lines[id] = EnvelopeBalance{Carryover: carry}
+	}
+
+	assigned := decimal.Zero
+	for _, m := range act.Movements {
+		if m.FromEnvelopeID == nil {
+			assigned = assigned.Add(m.Amount)
+		} else {
+			l := lines[*m.FromEnvelopeID]
+			l.Assigned = l.Assigned.Sub(m.Amount)
+			lines[*m.FromEnvelopeID] = l
+		}
+		if m.ToEnvelopeID == nil {
+			assigned = assigned.Sub(m.Amount)
+		} else {
+			l := lines[*m.ToEnvelopeID]
+			l.Assigned = l.Assigned.Add(m.Amount)
+			lines[*m.ToEnvelopeID] = l
+		}
+	}
+
+	for id, spent := range act.Spent {
+		l := lines[id]
+		l.Spent = spent
+		lines[id] = l
+	}
+
+	for id, l := range lines {
+		l.Available = l.Carryover.Add(l.Assigned).Sub(l.Spent)
+		lines[id] = l
+	}
+
+	toBeAssigned := open.ToBeAssigned.
+		Add(act.Income).
+		Add(act.Expected).
+		Sub(act.Unenveloped).
+		Sub(assigned)
+
+	return lines, assigned, toBeAssigned
+}
+
+// NextEnvelopeOpening closes a month. Envelopes keep what they have left,
+// while overspending nobody covered comes out of next month's pool and the
+// envelope starts over at zero.
+func NextEnvelopeOpening(lines map[int64]EnvelopeBalance, toBeAssigned decimal.Decimal) EnvelopeOpening {
+	next := EnvelopeOpening{Balances: make(map[int64]decimal.Decimal, len(lines))}
+	for id, l := range lines {
+		if l.Available.IsNegative() {
+			next.Overspent = next.Overspent.Add(l.Available.Neg())
+			continue
+		}
+		if l.Available.IsPositive() {
+			next.Balances[id] = l.Available
+		}
+	}
+	next.ToBeAssigned = toBeAssigned.Sub(next.Overspent)
+	return next
+}
+
+// ExpectedTemplateIncome lists the upcoming runs of active income templates
+// that fall in [from, to), soonest first. Runs that already happened are
+// transactions by now, so only the template's next run onwards is counted.
+func ExpectedTemplateIncome(templates []models.TransactionTemplate, from, to time.Time, loc *time.Location) []models.ExpectedIncome {
+	var out []models.ExpectedIncome
+	for _, t := range templates {
+		if !t.IsActive || t.TemplateType != "transaction" || t.TransactionType == nil || *t.TransactionType != "income" {
+			continue
+		}
+
+		runs := t.RunCount
+		for run := t.NextRunAt; run.Before(to); run = CalculateNextRun(run, t.Frequency, t.DayOfMonth, loc) {
+			if t.EndDate != nil && run.After(*t.EndDate) {
+				break
+			}
+			if t.MaxRuns != nil && runs >= *t.MaxRuns {
+				break
+			}
+			if !run.Before(from) {
+				out = append(out, models.ExpectedIncome{
+					TemplateID: t.ID,
+					Name:       t.Name,
+					Date:       run,
+					Amount:     t.Amount,
+				})
+			}
+			runs++
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out
+}
@@ -0,0 +1,91 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeActivityFromTotals(t *testing.T) {
+	rows := []models.YearlyCategoryRow{
+		{CategoryID: 1, InflowText: "3000", OutflowText: "0"},   // salary
+		{CategoryID: 2, InflowText: "20", OutflowText: "-200"},  // groceries with a refund
+		{CategoryID: 3, InflowText: "0", OutflowText: "-50"},    // no envelope
+		{CategoryID: 0, InflowText: "0", OutflowText: "-10.50"}, // uncategorized
+	}
+
+	act := utils.EnvelopeActivityFromTotals(rows, map[int64]int64{2: 7})
+	assert.True(t, df(3000).Equal(act.Income))
+	assert.True(t, df(180).Equal(act.Spent[7]))
+	assert.True(t, df(60.5).Equal(act.Unenveloped))
+}
+
+func TestStepEnvelopeMonth(t *testing.T) {
+	food, rent := int64(1), int64(2)
+
+	open := utils.EnvelopeOpening{
+		ToBeAssigned: df(100),
+		Balances:     map[int64]decimal.Decimal{food: df(30)},
+	}
+	act := utils.EnvelopeActivity{
+		Income:   df(2000),
+		Expected: df(500),
+		Spent:    map[int64]decimal.Decimal{food: df(400), rent: df(1000)},
+		Movements: []models.EnvelopeMovement{
+			{ToEnvelopeID: &food, Amount: df(300), Kind: models.EnvelopeMovementAssign},
+			{ToEnvelopeID: &rent, Amount: df(1200), Kind: models.EnvelopeMovementAssign},
+			{FromEnvelopeID: &rent, ToEnvelopeID: &food, Amount: df(50), Kind: models.EnvelopeMovementCover},
+		},
+	}
+
+	lines, assigned, tba := utils.StepEnvelopeMonth(open, act)
+	assert.True(t, df(1500).Equal(assigned))
+	// 100 + 2000 + 500 - 1500
+	assert.True(t, df(1100).Equal(tba))
+	// 30 + 350 - 400
+	assert.True(t, df(-20).Equal(lines[food].Available))
+	assert.True(t, df(150).Equal(lines[rent].Available))
+
+	next := utils.NextEnvelopeOpening(lines, tba)
+	assert.True(t, df(20).Equal(next.Overspent))
+	assert.True(t, df(1080).Equal(next.ToBeAssigned))
+	assert.True(t, df(150).Equal(next.Balances[rent]))
+	_, carried := next.Balances[food]
+	assert.False(t, carried, "an overspent envelope starts over at zero")
+}
+
+func TestExpectedTemplateIncome(t *testing.T) {
+	income, expense := "income", "expense"
+	maxRuns := 3
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	templates := []models.TransactionTemplate{
+		{ID: 1, Name: "Salary", IsActive: true, TemplateType: "transaction", TransactionType: &income,
+			Amount: df(2500), Frequency: "monthly", DayOfMonth: 25,
+			NextRunAt: time.Date(2026, time.March, 25, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "Side gig", IsActive: true, TemplateType: "transaction", TransactionType: &income,
+			Amount: df(100), Frequency: "weekly", RunCount: 1, MaxRuns: &maxRuns,
+			NextRunAt: time.Date(2026, time.February, 25, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Name: "Rent", IsActive: true, TemplateType: "transaction", TransactionType: &expense,
+			Amount: df(900), Frequency: "monthly", DayOfMonth: 1,
+			NextRunAt: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, Name: "Paused", IsActive: false, TemplateType: "transaction", TransactionType: &income,
+			Amount: df(50), Frequency: "monthly", DayOfMonth: 10,
+			NextRunAt: time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	got := utils.ExpectedTemplateIncome(templates, from, to, time.UTC)
+
+	// The side gig ran once, its run on Feb 25 is outside the month and the
+	// one on Mar 4 is its last
+	assert.Len(t, got, 2)
+	assert.Equal(t, int64(2), got[0].TemplateID)
+	assert.Equal(t, 4, got[0].Date.Day())
+	assert.Equal(t, int64(1), got[1].TemplateID)
+	assert.True(t, df(2500).Equal(got[1].Amount))
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"regexp"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	troyOunceGrams          = decimal.RequireFromString("31.1034768")
+	defaultOptionMultiplier = decimal.NewFromInt(100)
+
+	// OCC option symbol: root, expiry YYMMDD, C/P, strike x1000 over 8 digits.
+	optionSymbolRegex = regexp.MustCompile(`^([A-Z]{1,6})(\d{6})([CP])(\d{8})$`)
+)
+
+// QuoteIsUnitPrice reports whether a quote for t is already the price of one held unit.
+func QuoteIsUnitPrice(t models.InvestmentType) bool {
+	switch t {
+	case models.InvestmentBond, models.InvestmentCommodity, models.InvestmentOption:
+		return false
+	}
+	return true
+}
+
+// UnitPrice converts a quote into the price of one held unit on asOf: a bond's
+// dirty price, a commodity's price per gram, kilogram or ounce held, and an
+// option contract's premium. Matured bonds are worth their face value. Manually
+// priced assets are entered per unit held and are returned as they are.
+func UnitPrice(asset models.InvestmentAsset, quote decimal.Decimal, asOf time.Time) decimal.Decimal {
+	if asset.ManualPricing {
+		return quote
+	}
+	switch asset.InvestmentType {
+	case models.InvestmentBond:
+		if asset.FaceValue == nil {
+			return quote
+		}
+		if asset.MaturityDate != nil && !asOf.Before(*asset.MaturityDate) {
+			return *asset.FaceValue
+		}
+		return asset.FaceValue.Mul(quote).Div(hundred).Add(AccruedInterest(asset, asOf)).Round(4)
+	case models.InvestmentCommodity:
+		return quote.Mul(gramsPerUnit(asset)).Div(troyOunceGrams).Round(4)
+	case models.InvestmentOption:
+		return quote.Mul(OptionMultiplier(asset)).Round(4)
+	}
+	return quote
+}
+
+func gramsPerUnit(asset models.InvestmentAsset) decimal.Decimal {
+	if asset.Unit == nil {
+		return decimal.NewFromInt(1)
+	}
+	switch *asset.Unit {
+	case models.CommodityKilogram:
+		return decimal.NewFromInt(1000)
+	case models.CommodityTroyOunce:
+		return troyOunceGrams
+	}
+	return decimal.NewFromInt(1)
+}
+
+// CouponAmount is what one bond pays per coupon.
+func CouponAmount(asset models.InvestmentAsset) decimal.Decimal {
+	if asset.FaceValue == nil || asset.CouponRate == nil || asset.CouponFrequency == nil || *asset.CouponFrequency <= 0 {
+		return decimal.Zero
+	}
+	return asset.FaceValue.Mul(*asset.CouponRate).Div(hundred).Div(decimal.NewFromInt(int64(*asset.CouponFrequency)))
+}
+
+// CouponDates returns the bond's coupon dates in (from, to], oldest first. The
+// schedule is counted back from maturity in equal steps.
+func CouponDates(asset models.InvestmentAsset, from, to time.Time) []time.Time {
+	if CouponAmount(asset).IsZero() || asset.MaturityDate == nil {
+		return nil
+	}
+	step := 12 / *asset.CouponFrequency
+	maturity := asset.MaturityDate.UTC().Truncate(24 * time.Hour)
+
+	var dates []time.Time
+	for k := 0; ; k++ {
+		d := addMonthsClamped(maturity, -k*step)
+		if !d.After(from) {
+			break
+		}
+		if !d.After(to) {
+			dates = append(dates, d)
+		}
+	}
+
+	for i, j := 0, len(dates)-1; i < j; i, j = i+1, j-1 {
+		dates[i], dates[j] = dates[j], dates[i]
+	}
+	return dates
+}
+
+// AccruedInterest is the interest one bond has earned since its last coupon,
+// pro rata by days within the coupon period.
+func AccruedInterest(asset models.InvestmentAsset, asOf time.Time) decimal.Decimal {
+	coupon := CouponAmount(asset)
+	if coupon.IsZero() || asset.MaturityDate == nil {
+		return decimal.Zero
+	}
+	asOf = asOf.UTC().Truncate(24 * time.Hour)
+	maturity := asset.MaturityDate.UTC().Truncate(24 * time.Hour)
+	if !asOf.Before(maturity) {
+		return decimal.Zero
+	}
+
+	step := 12 / *asset.CouponFrequency
+	next := maturity
+	prev := addMonthsClamped(maturity, -step)
+	for k := 2; prev.After(asOf); k++ {
+		next = prev
+		prev = addMonthsClamped(maturity, -k*step)
+	}
+
+	period := next.Sub(prev).Hours() / 24
+	elapsed := asOf.Sub(prev).Hours() / 24
+	if period <= 0 {
+		return decimal.Zero
+	}
+	return coupon.Mul(decimal.NewFromFloat(elapsed)).Div(decimal.NewFromFloat(period)).Round(4)
+}
+
+// addMonthsClamped moves t by n months, keeping the day of month where it
+// exists and using the month's last day otherwise.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, n, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// OptionMultiplier is the number of underlying shares one contract covers.
+func OptionMultiplier(asset models.InvestmentAsset) decimal.Decimal {
+	if asset.Multiplier == nil || !asset.Multiplier.IsPositive() {
+		return defaultOptionMultiplier
+	}
+	return *asset.Multiplier
+}
+
+// OptionExpired reports whether the option can no longer trade on asOf.
+func OptionExpired(asset models.InvestmentAsset, asOf time.Time) bool {
+	return asset.ExpiryDate != nil && asOf.UTC().Truncate(24*time.Hour).After(asset.ExpiryDate.UTC())
+}
+
+// OptionIntrinsicValue is what one contract is worth when exercised with the
+// underlying at underlyingPrice.
+func OptionIntrinsicValue(asset models.InvestmentAsset, underlyingPrice decimal.Decimal) decimal.Decimal {
+	if asset.StrikePrice == nil || asset.OptionType == nil {
+		return decimal.Zero
+	}
+	diff := underlyingPrice.Sub(*asset.StrikePrice)
+	if *asset.OptionType == models.OptionPut {
+		diff = diff.Neg()
+	}
+	if !diff.IsPositive() {
+		return decimal.Zero
+	}
+	return diff.Mul(OptionMultiplier(asset)).Round(4)
+}
+
+// ParseOptionSymbol reads an OCC option symbol such as AAPL250117C00150000 into
+// its underlying ticker, expiry, type and strike.
+func ParseOptionSymbol(symbol string) (string, time.Time, models.OptionType, decimal.Decimal, bool) {
+	m := optionSymbolRegex.FindStringSubmatch(symbol)
+	if m == nil {
+		return "", time.Time{}, "", decimal.Zero, false
+	}
+	expiry, err := time.Parse("060102", m[2])
+	if err != nil {
+		return "", time.Time{}, "", decimal.Zero, false
+	}
+	optType := models.OptionCall
+	if m[3] == "P" {
+		optType = models.OptionPut
+	}
+	strike := decimal.RequireFromString(m[4]).Div(decimal.NewFromInt(1000))
+	return m[1], expiry, optType, strike, true
+}
@@ -0,0 +1,119 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(y int, m time.Month, dd int) time.Time {
+	return time.Date(y, m, dd, 0, 0, 0, 0, time.UTC)
+}
+
+func semiAnnualBond() models.InvestmentAsset {
+	face, rate, freq := df(1000), df(5), 2
+	maturity := date(2030, 6, 15)
+	return models.InvestmentAsset{
+		InvestmentType: models.InvestmentBond,
+		InstrumentTerms: models.InstrumentTerms{
+			FaceValue:       &face,
+			CouponRate:      &rate,
+			CouponFrequency: &freq,
+			MaturityDate:    &maturity,
+		},
+	}
+}
+
+// --- Bonds ---
+
+func TestCouponAmount_SemiAnnual(t *testing.T) {
+	assert.True(t, df(25).Equal(utils.CouponAmount(semiAnnualBond())))
+}
+
+func TestCouponDates_CountBackFromMaturity(t *testing.T) {
+	dates := utils.CouponDates(semiAnnualBond(), date(2029, 1, 1), date(2030, 12, 31))
+	assert.Equal(t, []time.Time{date(2029, 6, 15), date(2029, 12, 15), date(2030, 6, 15)}, dates)
+}
+
+func TestCouponDates_ZeroCouponHasNone(t *testing.T) {
+	bond := semiAnnualBond()
+	zero := 0
+	bond.CouponFrequency = &zero
+	assert.Empty(t, utils.CouponDates(bond, date(2029, 1, 1), date(2030, 12, 31)))
+}
+
+func TestAccruedInterest_ProRataWithinPeriod(t *testing.T) {
+	// 90 of 182 days since the December coupon
+	accrued := utils.AccruedInterest(semiAnnualBond(), date(2030, 3, 15))
+	assert.True(t, df(12.3626).Equal(accrued), accrued.String())
+
+	assert.True(t, utils.AccruedInterest(semiAnnualBond(), date(2029, 12, 15)).IsZero())
+}
+
+func TestUnitPrice_BondIsDirtyPrice(t *testing.T) {
+	price := utils.UnitPrice(semiAnnualBond(), df(98), date(2030, 3, 15))
+	assert.True(t, df(992.3626).Equal(price), price.String())
+}
+
+func TestUnitPrice_MaturedBondAtFace(t *testing.T) {
+	price := utils.UnitPrice(semiAnnualBond(), df(97), date(2030, 7, 1))
+	assert.True(t, df(1000).Equal(price), price.String())
+}
+
+// --- Commodities ---
+
+func TestUnitPrice_CommodityPerGram(t *testing.T) {
+	gold := models.InvestmentAsset{InvestmentType: models.InvestmentCommodity}
+	price := utils.UnitPrice(gold, df(2000), date(2025, 1, 1))
+	assert.True(t, df(64.3015).Equal(price), price.String())
+
+	oz := models.CommodityTroyOunce
+	gold.Unit = &oz
+	assert.True(t, df(2000).Equal(utils.UnitPrice(gold, df(2000), date(2025, 1, 1))))
+}
+
+// --- Options ---
+
+func TestUnitPrice_OptionPerContract(t *testing.T) {
+	opt := models.InvestmentAsset{InvestmentType: models.InvestmentOption}
+	assert.True(t, df(250).Equal(utils.UnitPrice(opt, df(2.5), date(2025, 1, 1))))
+
+	mini := decimal.NewFromInt(10)
+	opt.Multiplier = &mini
+	assert.True(t, df(25).Equal(utils.UnitPrice(opt, df(2.5), date(2025, 1, 1))))
+}
+
+func TestOptionIntrinsicValue(t *testing.T) {
+	strike, put, call := df(150), models.OptionPut, models.OptionCall
+	opt := models.InvestmentAsset{
+		InvestmentType:  models.InvestmentOption,
+		InstrumentTerms: models.InstrumentTerms{StrikePrice: &strike, OptionType: &put},
+	}
+	assert.True(t, df(1000).Equal(utils.OptionIntrinsicValue(opt, df(140))))
+
+	opt.OptionType = &call
+	assert.True(t, utils.OptionIntrinsicValue(opt, df(140)).IsZero())
+}
+
+func TestOptionExpired_AfterExpiryDay(t *testing.T) {
+	expiry := date(2025, 1, 17)
+	opt := models.InvestmentAsset{InstrumentTerms: models.InstrumentTerms{ExpiryDate: &expiry}}
+	assert.False(t, utils.OptionExpired(opt, date(2025, 1, 17)))
+	assert.True(t, utils.OptionExpired(opt, date(2025, 1, 18)))
+}
+
+func TestParseOptionSymbol(t *testing.T) {
+	underlying, expiry, optType, strike, ok := utils.ParseOptionSymbol("AAPL250117C00150000")
+	assert.True(t, ok)
+	assert.Equal(t, "AAPL", underlying)
+	assert.Equal(t, date(2025, 1, 17), expiry)
+	assert.Equal(t, models.OptionCall, optType)
+	assert.True(t, df(150).Equal(strike))
+
+	_, _, _, _, ok = utils.ParseOptionSymbol("AAPL")
+	assert.False(t, ok)
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	daysInYear = decimal.NewFromInt(365)
+	hundred    = decimal.NewFromInt(100)
+)
+
+// InterestRateForBalance returns the annual rate (in percent) that applies to balance.
+// Tiers work on the whole balance: the tier with the highest MinBalance that the
+// balance reaches wins, and the base rate applies below the lowest tier.
+func InterestRateForBalance(baseRate decimal.Decimal, tiers []models.AccountInterestTier, balance decimal.Decimal) decimal.Decimal {
+	rate := baseRate
+	var best *models.AccountInterestTier
+	for i := range tiers {
+		if balance.LessThan(tiers[i].MinBalance) {
+			continue
+		}
+		if best == nil || tiers[i].MinBalance.GreaterThan(best.MinBalance) {
+			best = &tiers[i]
+		}
+	}
+	if best != nil {
+		rate = best.AnnualRate
+	}
+	return rate
+}
+
+// DailyInterest returns one day of simple interest on balance (actual/365).
+// Overdrawn balances never accrue.
+func DailyInterest(balance, annualRatePct decimal.Decimal) decimal.Decimal {
+	if !balance.IsPositive() || !annualRatePct.IsPositive() {
+		return decimal.Zero
+	}
+	return balance.Mul(annualRatePct).Div(hundred).Div(daysInYear)
+}
+
+// AccrueInterest accrues daily interest for every day in [from, to] and returns the
+// new accrued total. Balances must be sorted by AsOf ASC; days without a row carry
+// the previous end balance forward, starting from opening. With daily compounding
+// the pending accrual earns interest too; other frequencies compound at payout.
+func AccrueInterest(
+	cfg models.AccountInterestConfig,
+	balances []models.Balance,
+	opening decimal.Decimal,
+	from, to time.Time,
+) decimal.Decimal {
+	accrued := cfg.AccruedAmount
+	balance := opening
+	idx := 0
+
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.AddDate(0, 0, 1) {
+		for idx < len(balances) && !balances[idx].AsOf.UTC().Truncate(24*time.Hour).After(day) {
+			balance = balances[idx].EndBalance
+			idx++
+		}
+
+		base := balance
+		if cfg.Compounding == models.InterestCompoundingDaily {
+			base = base.Add(accrued)
+		}
+		rate := InterestRateForBalance(cfg.AnnualRate, cfg.Tiers, balance)
+		accrued = accrued.Add(DailyInterest(base, rate))
+	}
+
+	return accrued
+}
+
+// compoundingPeriods returns how many times per year interest is paid out.
+// Daily compounding still pays out once a month.
+func compoundingPeriods(c models.InterestCompounding) int {
+	switch c {
+	case models.InterestCompoundingDaily:
+		return 365
+	case models.InterestCompoundingQuarterly:
+		return 4
+	case models.InterestCompoundingYearly:
+		return 1
+	default:
+		return 12
+	}
+}
+
+// ProjectYearlyInterest estimates the interest earned over a year if balance
+// stays where it is, compounding at the configured frequency.
+func ProjectYearlyInterest(balance, annualRatePct decimal.Decimal, compounding models.InterestCompounding) decimal.Decimal {
+	if !balance.IsPositive() || !annualRatePct.IsPositive() {
+		return decimal.Zero
+	}
+	n := compoundingPeriods(compounding)
+	periodRate := annualRatePct.Div(hundred).Div(decimal.NewFromInt(int64(n)))
+	factor := decimal.NewFromInt(1).Add(periodRate).Pow(decimal.NewFromInt(int64(n)))
+	return balance.Mul(factor.Sub(decimal.NewFromInt(1))).Round(2)
+}
+
+// IsInterestPayoutMonth reports whether interest is paid out in month.
+// Quarterly payouts land at the end of each calendar quarter, yearly in December.
+func IsInterestPayoutMonth(month time.Month, compounding models.InterestCompounding) bool {
+	switch compounding {
+	case models.InterestCompoundingQuarterly:
+		return month%3 == 0
+	case models.InterestCompoundingYearly:
+		return month == time.December
+	default:
+		return true
+	}
+}
+
+// NextInterestPayoutDate returns the first payout date strictly after `after`.
+// A payout day past the end of a short month falls on its last day.
+func NextInterestPayoutDate(after time.Time, compounding models.InterestCompounding, payoutDay int) time.Time {
+	after = after.UTC().Truncate(24 * time.Hour)
+	monthStart := time.Date(after.Year(), after.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= 12; i++ {
+		m := monthStart.AddDate(0, i, 0)
+		if !IsInterestPayoutMonth(m.Month(), compounding) {
+			continue
+		}
+		day := payoutDay
+		if last := m.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		candidate := time.Date(m.Year(), m.Month(), day, 0, 0, 0, 0, time.UTC)
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+
+	// unreachable for valid input; fall back to a year out
+	return after.AddDate(1, 0, 0)
+}
@@ -0,0 +1,112 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func tier(min, rate float64) models.AccountInterestTier {
+	return models.AccountInterestTier{MinBalance: df(min), AnnualRate: df(rate)}
+}
+
+// --- InterestRateForBalance ---
+
+func TestInterestRateForBalance_NoTiers(t *testing.T) {
+	assert.True(t, df(2).Equal(utils.InterestRateForBalance(df(2), nil, df(5000))))
+}
+
+func TestInterestRateForBalance_BelowLowestTier(t *testing.T) {
+	tiers := []models.AccountInterestTier{tier(10000, 3)}
+	assert.True(t, df(1).Equal(utils.InterestRateForBalance(df(1), tiers, df(9999))))
+}
+
+func TestInterestRateForBalance_PicksHighestReachedTier(t *testing.T) {
+	tiers := []models.AccountInterestTier{tier(50000, 4), tier(0, 1), tier(10000, 3)}
+	assert.True(t, df(3).Equal(utils.InterestRateForBalance(df(0.5), tiers, df(10000))))
+	assert.True(t, df(4).Equal(utils.InterestRateForBalance(df(0.5), tiers, df(75000))))
+	assert.True(t, df(1).Equal(utils.InterestRateForBalance(df(0.5), tiers, df(20))))
+}
+
+// --- DailyInterest ---
+
+func TestDailyInterest(t *testing.T) {
+	assert.True(t, df(10).Equal(utils.DailyInterest(df(36500), df(10))))
+}
+
+func TestDailyInterest_NegativeBalanceAccruesNothing(t *testing.T) {
+	assert.True(t, utils.DailyInterest(df(-500), df(10)).IsZero())
+}
+
+// --- AccrueInterest ---
+
+func TestAccrueInterest_CarriesBalanceForward(t *testing.T) {
+	cfg := models.AccountInterestConfig{AnnualRate: df(10), Compounding: models.InterestCompoundingMonthly}
+	balances := []models.Balance{
+		{AsOf: day(2026, 1, 3), EndBalance: df(73000)},
+	}
+
+	// Jan 1-2 at 36500 (10/day), Jan 3-4 at 73000 (20/day)
+	got := utils.AccrueInterest(cfg, balances, df(36500), day(2026, 1, 1), day(2026, 1, 4))
+	assert.True(t, df(60).Equal(got), got.String())
+}
+
+func TestAccrueInterest_AddsToExistingAccrual(t *testing.T) {
+	cfg := models.AccountInterestConfig{AnnualRate: df(10), Compounding: models.InterestCompoundingMonthly, AccruedAmount: df(5)}
+	got := utils.AccrueInterest(cfg, nil, df(36500), day(2026, 1, 1), day(2026, 1, 1))
+	assert.True(t, df(15).Equal(got), got.String())
+}
+
+func TestAccrueInterest_DailyCompoundingEarnsOnAccrued(t *testing.T) {
+	cfg := models.AccountInterestConfig{AnnualRate: df(10), Compounding: models.InterestCompoundingDaily}
+	got := utils.AccrueInterest(cfg, nil, df(36500), day(2026, 1, 1), day(2026, 1, 2))
+	assert.True(t, got.GreaterThan(df(20)), got.String())
+}
+
+func TestAccrueInterest_EmptyRange(t *testing.T) {
+	cfg := models.AccountInterestConfig{AnnualRate: df(10), AccruedAmount: df(3)}
+	got := utils.AccrueInterest(cfg, nil, df(36500), day(2026, 1, 2), day(2026, 1, 1))
+	assert.True(t, df(3).Equal(got))
+}
+
+// --- ProjectYearlyInterest ---
+
+func TestProjectYearlyInterest_Yearly(t *testing.T) {
+	got := utils.ProjectYearlyInterest(df(10000), df(5), models.InterestCompoundingYearly)
+	assert.True(t, df(500).Equal(got), got.String())
+}
+
+func TestProjectYearlyInterest_MonthlyBeatsYearly(t *testing.T) {
+	got := utils.ProjectYearlyInterest(df(10000), df(5), models.InterestCompoundingMonthly)
+	assert.True(t, df(511.62).Equal(got), got.String())
+}
+
+func TestProjectYearlyInterest_ZeroBalance(t *testing.T) {
+	assert.True(t, utils.ProjectYearlyInterest(decimal.Zero, df(5), models.InterestCompoundingMonthly).IsZero())
+}
+
+// --- NextInterestPayoutDate ---
+
+func TestNextInterestPayoutDate_Monthly(t *testing.T) {
+	assert.Equal(t, day(2026, 3, 15), utils.NextInterestPayoutDate(day(2026, 3, 1), models.InterestCompoundingMonthly, 15))
+	assert.Equal(t, day(2026, 4, 15), utils.NextInterestPayoutDate(day(2026, 3, 15), models.InterestCompoundingMonthly, 15))
+}
+
+func TestNextInterestPayoutDate_ClampsToMonthEnd(t *testing.T) {
+	assert.Equal(t, day(2026, 2, 28), utils.NextInterestPayoutDate(day(2026, 1, 31), models.InterestCompoundingMonthly, 31))
+}
+
+func TestNextInterestPayoutDate_Quarterly(t *testing.T) {
+	assert.Equal(t, day(2026, 6, 1), utils.NextInterestPayoutDate(day(2026, 3, 1), models.InterestCompoundingQuarterly, 1))
+}
+
+func TestNextInterestPayoutDate_Yearly(t *testing.T) {
+	assert.Equal(t, day(2026, 12, 31), utils.NextInterestPayoutDate(day(2026, 1, 10), models.InterestCompoundingYearly, 31))
+	assert.Equal(t, day(2027, 12, 31), utils.NextInterestPayoutDate(day(2026, 12, 31), models.InterestCompoundingYearly, 31))
+}
@@ -0,0 +1,364 @@
+package utils
+
+import (
+	"math"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// CashFlow is money moved into (positive) or out of (negative) an investment.
+type CashFlow struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+// PositionSeries is the end-of-day market value of a holding over a range, with
+// the cash flows that moved in or out of it. Values[0] is the opening value on
+// the first day; Flows only hold flows dated after it.
+type PositionSeries struct {
+	Values []models.ChartPoint
+	Flows  []CashFlow
+}
+
+func dayIndex(from, d time.Time) int {
+	return int(d.UTC().Truncate(24*time.Hour).Sub(from) / (24 * time.Hour))
+}
+
+// AssetPositionSeries replays an asset's trades and income day by day over
+// [from, to]. Prices come from the price history, with trade prices filling in
+// days the history doesn't cover, and are carried forward over gaps.
+//
+// Buys flow in at their gross cost (including cash fees for non-crypto), sells
+// flow out at their proceeds and dividends flow out as cash paid to the owner.
+// Transfers in kind flow at market value.
+// Staking rewards only add units, so they count as return rather than a flow.
+func AssetPositionSeries(asset models.InvestmentAsset, trades []models.InvestmentTrade, income []models.InvestmentIncome, prices []models.AssetPriceHistory, from, to time.Time) PositionSeries {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	days := dayIndex(from, to) + 1
+	if days <= 0 {
+		return PositionSeries{}
+	}
+
+	qtyDelta := make([]decimal.Decimal, days)
+	dayPrice := make([]*decimal.Decimal, days)
+	openingQty := decimal.Zero
+	var openingPrice *decimal.Decimal
+	var openingPriceDate time.Time
+	var flows []CashFlow
+
+	setPrice := func(d time.Time, p decimal.Decimal) {
+		i := dayIndex(from, d)
+		switch {
+		case i < 0:
+			if openingPrice == nil || !d.Before(openingPriceDate) {
+				openingPrice, openingPriceDate = &p, d
+			}
+		case i < days:
+			dayPrice[i] = &p
+		}
+	}
+
+	addQty := func(d time.Time, q decimal.Decimal) {
+		i := dayIndex(from, d)
+		switch {
+		case i <= 0:
+			openingQty = openingQty.Add(q)
+		case i < days:
+			qtyDelta[i] = qtyDelta[i].Add(q)
+		}
+	}
+
+	addFlow := func(d time.Time, amount decimal.Decimal) {
+		if i := dayIndex(from, d); i > 0 && i < days {
+			flows = append(flows, CashFlow{Date: d, Amount: amount})
+		}
+	}
+
+	// Units moved in kind flow in or out at their market value on the day, not at
+	// the cost basis the trades carry, so transfers between accounts net out.
+	transferQty := make([]decimal.Decimal, days)
+
+	for _, t := range trades {
+		if t.TxnDate.After(to) {
+			continue
+		}
+		if t.TransferID != nil {
+			q := t.Quantity
+			if t.TradeType != models.InvestmentBuy {
+				q = q.Neg()
+			}
+			addQty(t.TxnDate, q)
+			if i := dayIndex(from, t.TxnDate); i > 0 && i < days {
+				transferQty[i] = transferQty[i].Add(q)
+			}
+			continue
+		}
+		setPrice(t.TxnDate, t.PricePerUnit)
+		if t.TradeType == models.InvestmentBuy {
+			addQty(t.TxnDate, t.Quantity)
+			cost := t.Quantity.Mul(t.PricePerUnit)
+			if asset.InvestmentType != models.InvestmentCrypto {
+				cost = cost.Add(t.Fee)
+			}
+			addFlow(t.TxnDate, cost)
+		} else {
+			addQty(t.TxnDate, t.Quantity.Neg())
+			addFlow(t.TxnDate, t.RealizedValue.Neg())
+		}
+	}
+
+	// History wins over trade prices on days that have both.
+	for _, p := range prices {
+		if !p.AsOf.After(to) {
+			setPrice(p.AsOf, p.Price)
+		}
+	}
+
+	for _, inc := range income {
+		if inc.TxnDate.After(to) {
+			continue
+		}
+		switch inc.IncomeType {
+		case models.IncomeTypeStaking:
+			if inc.Quantity != nil {
+				addQty(inc.TxnDate, *inc.Quantity)
+			}
+		default:
+			addFlow(inc.TxnDate, inc.Amount.Neg())
+		}
+	}
+
+	values := make([]models.ChartPoint, days)
+	qty := openingQty
+	price := openingPrice
+	for i := 0; i < days; i++ {
+		qty = qty.Add(qtyDelta[i])
+		if dayPrice[i] != nil {
+			price = dayPrice[i]
+		}
+		value := decimal.Zero
+		if price != nil && qty.IsPositive() {
+			value = qty.Mul(*price)
+		}
+		values[i] = models.ChartPoint{Date: from.AddDate(0, 0, i), Value: value}
+		if !transferQty[i].IsZero() && price != nil {
+			flows = append(flows, CashFlow{Date: values[i].Date, Amount: transferQty[i].Mul(*price)})
+		}
+	}
+
+	return PositionSeries{Values: values, Flows: flows}
+}
+
+// Scale converts a series by a fixed rate, e.g. into another currency.
+func (s PositionSeries) Scale(rate decimal.Decimal) PositionSeries {
+	out := PositionSeries{
+		Values: make([]models.ChartPoint, len(s.Values)),
+		Flows:  make([]CashFlow, len(s.Flows)),
+	}
+	for i, v := range s.Values {
+		out.Values[i] = models.ChartPoint{Date: v.Date, Value: v.Value.Mul(rate)}
+	}
+	for i, f := range s.Flows {
+		out.Flows[i] = CashFlow{Date: f.Date, Amount: f.Amount.Mul(rate)}
+	}
+	return out
+}
+
+// MergePositionSeries adds up series that cover the same days, so holdings can
+// be measured as one account or portfolio.
+func MergePositionSeries(series []PositionSeries) PositionSeries {
+	var out PositionSeries
+	for _, s := range series {
+		if out.Values == nil {
+			out.Values = make([]models.ChartPoint, len(s.Values))
+			copy(out.Values, s.Values)
+		} else {
+			for i := range out.Values {
+				if i < len(s.Values) {
+					out.Values[i].Value = out.Values[i].Value.Add(s.Values[i].Value)
+				}
+			}
+		}
+		out.Flows = append(out.Flows, s.Flows...)
+	}
+	return out
+}
+
+// TimeWeightedReturn chains daily sub-period returns, treating each day's flows
+// as arriving at its close, so the size and timing of contributions don't move
+// the result. Days that start from an empty position are skipped. The result is
+// a fraction (0.05 = 5%).
+func TimeWeightedReturn(s PositionSeries) decimal.Decimal {
+	if len(s.Values) < 2 {
+		return decimal.Zero
+	}
+	from := s.Values[0].Date
+	flowByDay := make(map[int]decimal.Decimal)
+	for _, f := range s.Flows {
+		i := dayIndex(from, f.Date)
+		flowByDay[i] = flowByDay[i].Add(f.Amount)
+	}
+
+	growth := decimal.NewFromInt(1)
+	for i := 1; i < len(s.Values); i++ {
+		prev := s.Values[i-1].Value
+		if !prev.IsPositive() {
+			continue
+		}
+		growth = growth.Mul(s.Values[i].Value.Sub(flowByDay[i]).Div(prev))
+	}
+	return growth.Sub(decimal.NewFromInt(1))
+}
+
+// AnnualizeReturn turns a return over days into a yearly rate. Periods shorter
+// than a year are returned as-is, since stretching them exaggerates noise.
+func AnnualizeReturn(r decimal.Decimal, days int) decimal.Decimal {
+	if days <= 365 {
+		return r
+	}
+	base := 1 + r.InexactFloat64()
+	if base <= 0 {
+		return decimal.NewFromInt(-1)
+	}
+	return decimal.NewFromFloat(math.Pow(base, 365/float64(days)) - 1)
+}
+
+// MoneyWeightedReturn is the XIRR of a position: the opening value counts as
+// money put in on the first day and the closing value as money taken out on the
+// last. ok is false when no rate solves the flows (e.g. nothing was invested).
+func MoneyWeightedReturn(s PositionSeries) (decimal.Decimal, bool) {
+	if len(s.Values) < 2 {
+		return decimal.Zero, false
+	}
+	first, last := s.Values[0], s.Values[len(s.Values)-1]
+
+	flows := []CashFlow{{Date: first.Date, Amount: first.Value.Neg()}}
+	for _, f := range s.Flows {
+		flows = append(flows, CashFlow{Date: f.Date, Amount: f.Amount.Neg()})
+	}
+	flows = append(flows, CashFlow{Date: last.Date, Amount: last.Value})
+
+	return XIRR(flows)
+}
+
+// XIRR finds the annual rate at which the flows' net present value is zero,
+// using Newton's method with a bisection fallback. Flows are signed from the
+// investor's side: money put in is negative, money received is positive.
+func XIRR(flows []CashFlow) (decimal.Decimal, bool) {
+	if len(flows) < 2 {
+		return decimal.Zero, false
+	}
+
+	start := flows[0].Date
+	for _, f := range flows {
+		if f.Date.Before(start) {
+			start = f.Date
+		}
+	}
+
+	years := make([]float64, len(flows))
+	amounts := make([]float64, len(flows))
+	hasIn, hasOut := false, false
+	for i, f := range flows {
+		years[i] = f.Date.Sub(start).Hours() / 24 / 365
+		amounts[i] = f.Amount.InexactFloat64()
+		if amounts[i] < 0 {
+			hasIn = true
+		}
+		if amounts[i] > 0 {
+			hasOut = true
+		}
+	}
+	if !hasIn || !hasOut {
+		return decimal.Zero, false
+	}
+
+	npv := func(rate float64) float64 {
+		var sum float64
+		for i, a := range amounts {
+			sum += a / math.Pow(1+rate, years[i])
+		}
+		return sum
+	}
+	dnpv := func(rate float64) float64 {
+		var sum float64
+		for i, a := range amounts {
+			sum -= years[i] * a / math.Pow(1+rate, years[i]+1)
+		}
+		return sum
+	}
+
+	const tolerance = 1e-9
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		v, d := npv(rate), dnpv(rate)
+		if d == 0 {
+			break
+		}
+		next := rate - v/d
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < tolerance {
+			return decimal.NewFromFloat(next), true
+		}
+		rate = next
+	}
+
+	lo, hi := -0.9999, 100.0
+	flo, fhi := npv(lo), npv(hi)
+	if flo*fhi > 0 {
+		return decimal.Zero, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		fm := npv(mid)
+		if math.Abs(fm) < tolerance || hi-lo < tolerance {
+			return decimal.NewFromFloat(mid), true
+		}
+		if flo*fm < 0 {
+			hi = mid
+		} else {
+			lo, flo = mid, fm
+		}
+	}
+	return decimal.NewFromFloat((lo + hi) / 2), true
+}
+
+// NormalizeBenchmark rescales benchmark prices onto a chart so both start at the
+// chart's first value (or 100 when the chart starts empty). Prices must be in
+// ascending date order; each point takes the latest price on or before its date
+// and points before the first price are left out.
+func NormalizeBenchmark(points []models.ChartPoint, prices []models.ChartPoint) []models.ChartPoint {
+	out := []models.ChartPoint{}
+	if len(points) == 0 || len(prices) == 0 {
+		return out
+	}
+
+	base := decimal.NewFromInt(100)
+	if points[0].Value.IsPositive() {
+		base = points[0].Value
+	}
+
+	var basePrice decimal.Decimal
+	j := -1
+	for _, p := range points {
+		for j+1 < len(prices) && !prices[j+1].Date.After(p.Date) {
+			j++
+		}
+		if j < 0 || !prices[j].Value.IsPositive() {
+			continue
+		}
+		if basePrice.IsZero() {
+			basePrice = prices[j].Value
+		}
+		out = append(out, models.ChartPoint{
+			Date:  p.Date,
+			Value: base.Mul(prices[j].Value).Div(basePrice).Round(4),
+		})
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var perfStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func perfDay(n int) time.Time { return perfStart.AddDate(0, 0, n) }
+
+func series(values []float64, flows map[int]float64) utils.PositionSeries {
+	var s utils.PositionSeries
+	for i, v := range values {
+		s.Values = append(s.Values, models.ChartPoint{Date: perfDay(i), Value: df(v)})
+	}
+	for i, f := range flows {
+		s.Flows = append(s.Flows, utils.CashFlow{Date: perfDay(i), Amount: df(f)})
+	}
+	return s
+}
+
+func TestTimeWeightedReturn_IgnoresContributions(t *testing.T) {
+	// +10% on day 1, then 1000 is added, then +10% again: 21% regardless of size.
+	s := series([]float64{100, 110, 1110, 1221}, map[int]float64{2: 1000})
+	assert.True(t, df(0.21).Equal(utils.TimeWeightedReturn(s).Round(6)))
+}
+
+func TestTimeWeightedReturn_SkipsEmptyStart(t *testing.T) {
+	s := series([]float64{0, 100, 120}, map[int]float64{1: 100})
+	assert.True(t, df(0.2).Equal(utils.TimeWeightedReturn(s).Round(6)))
+}
+
+func TestXIRR_OneYearDoubling(t *testing.T) {
+	flows := []utils.CashFlow{
+		{Date: perfStart, Amount: df(-100)},
+		{Date: perfStart.AddDate(0, 0, 365), Amount: df(200)},
+	}
+	rate, ok := utils.XIRR(flows)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, rate.InexactFloat64(), 1e-6)
+}
+
+func TestXIRR_NoSolutionWithoutBothSigns(t *testing.T) {
+	_, ok := utils.XIRR([]utils.CashFlow{{Date: perfStart, Amount: df(-100)}, {Date: perfDay(10), Amount: df(-50)}})
+	assert.False(t, ok)
+}
+
+func TestMoneyWeightedReturn_OpeningAndClosingValues(t *testing.T) {
+	s := utils.PositionSeries{Values: []models.ChartPoint{
+		{Date: perfStart, Value: df(100)},
+		{Date: perfDay(365), Value: df(200)},
+	}}
+	mwr, ok := utils.MoneyWeightedReturn(s)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, mwr.InexactFloat64(), 1e-6)
+
+	// Adding 100 halfway through earns it only half a year, so the same closing
+	// value means a lower money-weighted return.
+	s.Values[1].Value = df(300)
+	s.Flows = []utils.CashFlow{{Date: perfDay(182), Amount: df(100)}}
+	withFlow, ok := utils.MoneyWeightedReturn(s)
+	assert.True(t, ok)
+	assert.Greater(t, withFlow.InexactFloat64(), 0.5)
+	assert.Less(t, withFlow.InexactFloat64(), 1.0)
+}
+
+func TestAssetPositionSeries_ReplaysTradesAndIncome(t *testing.T) {
+	asset := models.InvestmentAsset{ID: 1, InvestmentType: models.InvestmentStock}
+	trades := []models.InvestmentTrade{
+		{TradeType: models.InvestmentBuy, TxnDate: perfDay(-5), Quantity: df(10), PricePerUnit: df(9), Fee: df(1)},
+		{TradeType: models.InvestmentBuy, TxnDate: perfDay(2), Quantity: df(5), PricePerUnit: df(12), Fee: df(1)},
+		{TradeType: models.InvestmentSell, TxnDate: perfDay(3), Quantity: df(3), PricePerUnit: df(12), RealizedValue: df(35)},
+	}
+	income := []models.InvestmentIncome{
+		{TxnDate: perfDay(1), IncomeType: models.IncomeTypeDividend, Amount: df(4)},
+	}
+	prices := []models.AssetPriceHistory{
+		{AsOf: perfDay(-1), Price: df(10)},
+		{AsOf: perfDay(1), Price: df(11)},
+	}
+
+	s := utils.AssetPositionSeries(asset, trades, income, prices, perfStart, perfDay(3))
+
+	want := []float64{100, 110, 180, 144}
+	for i, w := range want {
+		assert.True(t, df(w).Equal(s.Values[i].Value), "day %d: got %s, want %v", i, s.Values[i].Value, w)
+	}
+	got := map[int]string{}
+	for _, f := range s.Flows {
+		got[int(f.Date.Sub(perfStart).Hours()/24)] = f.Amount.String()
+	}
+	assert.Equal(t, map[int]string{1: "-4", 2: "61", 3: "-35"}, got)
+}
+
+func TestAssetPositionSeries_StakingAddsUnitsWithoutFlow(t *testing.T) {
+	asset := models.InvestmentAsset{ID: 1, InvestmentType: models.InvestmentCrypto}
+	qty := df(1)
+	trades := []models.InvestmentTrade{{TradeType: models.InvestmentBuy, TxnDate: perfDay(-1), Quantity: df(10), PricePerUnit: df(5)}}
+	income := []models.InvestmentIncome{{TxnDate: perfDay(1), IncomeType: models.IncomeTypeStaking, Quantity: &qty, Amount: df(5)}}
+
+	s := utils.AssetPositionSeries(asset, trades, income, nil, perfStart, perfDay(1))
+
+	assert.Empty(t, s.Flows)
+	assert.True(t, df(55).Equal(s.Values[1].Value))
+	assert.True(t, df(0.1).Equal(utils.TimeWeightedReturn(s)))
+}
+
+func TestAssetPositionSeries_TransferFlowsAtMarketValue(t *testing.T) {
+	asset := models.InvestmentAsset{ID: 1, InvestmentType: models.InvestmentStock}
+	transferID := int64(1)
+	trades := []models.InvestmentTrade{
+		{TradeType: models.InvestmentBuy, TxnDate: perfDay(-1), Quantity: df(10), PricePerUnit: df(5)},
+		{TradeType: models.InvestmentSell, TxnDate: perfDay(1), Quantity: df(4), PricePerUnit: df(5), RealizedValue: df(20), TransferID: &transferID},
+	}
+	prices := []models.AssetPriceHistory{{AsOf: perfDay(-1), Price: df(8)}}
+
+	s := utils.AssetPositionSeries(asset, trades, nil, prices, perfStart, perfDay(1))
+
+	assert.True(t, df(48).Equal(s.Values[1].Value))
+	require.Len(t, s.Flows, 1)
+	// leaves at today's price, not the cost basis, so it is not mistaken for a loss
+	assert.True(t, df(-32).Equal(s.Flows[0].Amount), s.Flows[0].Amount.String())
+	assert.True(t, utils.TimeWeightedReturn(s).IsZero())
+}
+
+// --- NormalizeBenchmark ---
+
+func TestNormalizeBenchmark_ScalesToFirstChartValue(t *testing.T) {
+	points := series([]float64{1000, 1100, 1200}, nil).Values
+	prices := []models.ChartPoint{
+		{Date: perfDay(0), Value: df(50)},
+		{Date: perfDay(2), Value: df(60)},
+	}
+
+	got := utils.NormalizeBenchmark(points, prices)
+	require.Len(t, got, 3)
+	assert.True(t, df(1000).Equal(got[0].Value))
+	// day 1 has no price, the day 0 price carries forward
+	assert.True(t, df(1000).Equal(got[1].Value))
+	assert.True(t, df(1200).Equal(got[2].Value), got[2].Value.String())
+}
+
+func TestNormalizeBenchmark_SkipsPointsBeforeFirstPrice(t *testing.T) {
+	points := series([]float64{0, 0, 500}, nil).Values
+	prices := []models.ChartPoint{
+		{Date: perfDay(1), Value: df(20)},
+		{Date: perfDay(2), Value: df(25)},
+	}
+
+	got := utils.NormalizeBenchmark(points, prices)
+	require.Len(t, got, 2)
+	assert.True(t, got[0].Date.Equal(perfDay(1)))
+	assert.True(t, df(100).Equal(got[0].Value))
+	assert.True(t, df(125).Equal(got[1].Value))
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceAlertCoolingDown reports whether the rule fired too recently to fire again at now.
+func PriceAlertCoolingDown(rule models.PriceAlertRule, now time.Time) bool {
+	if rule.LastTriggeredAt == nil {
+		return false
+	}
+	return now.Before(rule.LastTriggeredAt.Add(time.Duration(rule.CooldownHours) * time.Hour))
+}
+
+// EvaluatePriceAlert checks a rule against the observed figure. Percent change
+// rules compare current with reference, the level PeriodDays ago, and report the
+// move in percent; every other kind compares current with the threshold directly.
+// It returns the measured figure, whether that figure sits on the alert side, and
+// whether the rule fires: only when it has not fired since the figure was last on
+// the other side (rule.LastHit) and is not still cooling down.
+func EvaluatePriceAlert(rule models.PriceAlertRule, current decimal.Decimal, reference *decimal.Decimal, now time.Time) (decimal.Decimal, bool, bool) {
+	measured := current
+	if rule.Kind == models.PriceAlertPercentChange {
+		if reference == nil || !reference.IsPositive() {
+			return decimal.Zero, false, false
+		}
+		measured = current.Sub(*reference).Div(*reference).Mul(decimal.NewFromInt(100))
+	}
+
+	var hit bool
+	switch {
+	case rule.Kind == models.PriceAlertPercentChange && rule.Direction == models.PriceAlertEither:
+		hit = measured.Abs().GreaterThanOrEqual(rule.Threshold)
+	case rule.Kind == models.PriceAlertPercentChange && rule.Direction == models.PriceAlertBelow:
+		hit = measured.LessThanOrEqual(rule.Threshold.Neg())
+	case rule.Direction == models.PriceAlertAbove:
+		hit = measured.GreaterThanOrEqual(rule.Threshold)
+	case rule.Direction == models.PriceAlertBelow:
+		hit = measured.LessThanOrEqual(rule.Threshold)
+	}
+
+	if !hit || rule.LastHit || PriceAlertCoolingDown(rule, now) {
+		return measured, hit, false
+	}
+	return measured, hit, true
+}
@@ -0,0 +1,99 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var alertNow = time.Date(2026, 6, 8, 18, 0, 0, 0, time.UTC)
+
+func percentRule(direction models.PriceAlertDirection, threshold float64) models.PriceAlertRule {
+	days := 7
+	return models.PriceAlertRule{
+		Kind:          models.PriceAlertPercentChange,
+		Direction:     direction,
+		Threshold:     df(threshold),
+		PeriodDays:    &days,
+		CooldownHours: 24,
+	}
+}
+
+func TestEvaluatePriceAlert_PercentChange(t *testing.T) {
+	ref := df(100)
+
+	measured, _, fired := utils.EvaluatePriceAlert(percentRule(models.PriceAlertAbove, 10), df(112), &ref, alertNow)
+	assert.True(t, fired)
+	assert.True(t, df(12).Equal(measured), measured.String())
+
+	_, _, fired = utils.EvaluatePriceAlert(percentRule(models.PriceAlertAbove, 10), df(85), &ref, alertNow)
+	assert.False(t, fired, "a drop does not satisfy an upward rule")
+
+	measured, _, fired = utils.EvaluatePriceAlert(percentRule(models.PriceAlertBelow, 10), df(85), &ref, alertNow)
+	assert.True(t, fired)
+	assert.True(t, df(-15).Equal(measured), measured.String())
+
+	_, _, fired = utils.EvaluatePriceAlert(percentRule(models.PriceAlertEither, 10), df(91), &ref, alertNow)
+	assert.False(t, fired)
+	_, _, fired = utils.EvaluatePriceAlert(percentRule(models.PriceAlertEither, 10), df(90), &ref, alertNow)
+	assert.True(t, fired)
+
+	_, _, fired = utils.EvaluatePriceAlert(percentRule(models.PriceAlertEither, 10), df(150), nil, alertNow)
+	assert.False(t, fired, "no history to compare against")
+}
+
+func TestEvaluatePriceAlert_Levels(t *testing.T) {
+	above := models.PriceAlertRule{Kind: models.PriceAlertPrice, Direction: models.PriceAlertAbove, Threshold: df(250), CooldownHours: 24}
+	_, _, fired := utils.EvaluatePriceAlert(above, df(249.99), nil, alertNow)
+	assert.False(t, fired)
+	_, _, fired = utils.EvaluatePriceAlert(above, df(250), nil, alertNow)
+	assert.True(t, fired)
+
+	// P&L thresholds may sit below zero
+	loss := models.PriceAlertRule{Kind: models.PriceAlertProfitLoss, Direction: models.PriceAlertBelow, Threshold: df(-20), CooldownHours: 24}
+	_, _, fired = utils.EvaluatePriceAlert(loss, df(-12), nil, alertNow)
+	assert.False(t, fired)
+	_, _, fired = utils.EvaluatePriceAlert(loss, df(-25), nil, alertNow)
+	assert.True(t, fired)
+}
+
+func TestEvaluatePriceAlert_Cooldown(t *testing.T) {
+	rule := models.PriceAlertRule{Kind: models.PriceAlertValue, Direction: models.PriceAlertAbove, Threshold: df(10000), CooldownHours: 24}
+
+	recent := alertNow.Add(-23 * time.Hour)
+	rule.LastTriggeredAt = &recent
+	_, _, fired := utils.EvaluatePriceAlert(rule, df(12000), nil, alertNow)
+	assert.False(t, fired, "still cooling down")
+
+	earlier := alertNow.Add(-24 * time.Hour)
+	rule.LastTriggeredAt = &earlier
+	_, _, fired = utils.EvaluatePriceAlert(rule, df(12000), nil, alertNow)
+	assert.True(t, fired)
+}
+
+func TestEvaluatePriceAlert_FiresOnlyOnCrossing(t *testing.T) {
+	rule := models.PriceAlertRule{Kind: models.PriceAlertPrice, Direction: models.PriceAlertAbove, Threshold: df(250), CooldownHours: 24}
+
+	_, hit, fired := utils.EvaluatePriceAlert(rule, df(260), nil, alertNow)
+	assert.True(t, hit)
+	assert.True(t, fired, "first run on the alert side is a crossing")
+
+	earlier := alertNow.Add(-48 * time.Hour)
+	rule.LastTriggeredAt = &earlier
+	rule.LastHit = true
+	_, hit, fired = utils.EvaluatePriceAlert(rule, df(270), nil, alertNow)
+	assert.True(t, hit)
+	assert.False(t, fired, "staying above after the cooldown is not a new crossing")
+
+	_, hit, fired = utils.EvaluatePriceAlert(rule, df(240), nil, alertNow)
+	assert.False(t, hit)
+	assert.False(t, fired)
+
+	rule.LastHit = false
+	_, hit, fired = utils.EvaluatePriceAlert(rule, df(255), nil, alertNow)
+	assert.True(t, hit)
+	assert.True(t, fired, "crossing back above fires again")
+}
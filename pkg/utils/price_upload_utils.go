@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// priceDateLayouts are the date formats accepted in a price upload.
+var priceDateLayouts = []string{"2006-01-02", "02.01.2006", "01/02/2006"}
+
+// ParsePriceCSV reads a price upload with a header naming the ticker, date, price
+// and (optionally) currency columns in any order. Rows that can't be read are
+// reported by line and skipped; a missing column fails the whole file.
+func ParsePriceCSV(r io.Reader) ([]models.PriceUploadRow, []string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't read header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF")))] = i
+	}
+	for _, required := range []string{"ticker", "date", "price"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+	currencyCol, hasCurrency := cols["currency"]
+
+	var (
+		rows   []models.PriceUploadRow
+		issues []string
+	)
+	for line := 2; ; line++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		field := func(i int) string {
+			if i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		ticker := strings.ToUpper(field(cols["ticker"]))
+		if ticker == "" {
+			issues = append(issues, fmt.Sprintf("line %d: ticker is empty", line))
+			continue
+		}
+
+		asOf, ok := parsePriceDate(field(cols["date"]))
+		if !ok {
+			issues = append(issues, fmt.Sprintf("line %d: invalid date %q", line, field(cols["date"])))
+			continue
+		}
+
+		price, err := decimal.NewFromString(strings.ReplaceAll(field(cols["price"]), ",", "."))
+		if err != nil || !price.IsPositive() {
+			issues = append(issues, fmt.Sprintf("line %d: invalid price %q", line, field(cols["price"])))
+			continue
+		}
+
+		currency := ""
+		if hasCurrency {
+			currency = strings.ToUpper(field(currencyCol))
+			if currency != "" && len(currency) != 3 {
+				issues = append(issues, fmt.Sprintf("line %d: invalid currency %q", line, currency))
+				continue
+			}
+		}
+
+		rows = append(rows, models.PriceUploadRow{Line: line, Ticker: ticker, AsOf: asOf, Price: price, Currency: currency})
+	}
+
+	return rows, issues, nil
+}
+
+// DedupePriceRows keeps one row per ticker and date, the last one in the file, in
+// the position of the first. Each row it replaces is reported by line.
+func DedupePriceRows(rows []models.PriceUploadRow) ([]models.PriceUploadRow, []string) {
+	type key struct {
+		ticker string
+		asOf   string
+	}
+	var (
+		kept       []models.PriceUploadRow
+		duplicates []string
+	)
+	index := make(map[key]int, len(rows))
+	for _, row := range rows {
+		k := key{row.Ticker, row.AsOf.Format("2006-01-02")}
+		if i, ok := index[k]; ok {
+			duplicates = append(duplicates, fmt.Sprintf("line %d: %s on %s replaces line %d", row.Line, row.Ticker, k.asOf, kept[i].Line))
+			kept[i] = row
+			continue
+		}
+		index[k] = len(kept)
+		kept = append(kept, row)
+	}
+	return kept, duplicates
+}
+
+func parsePriceDate(s string) (time.Time, bool) {
+	for _, layout := range priceDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
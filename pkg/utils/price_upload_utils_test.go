@@ -0,0 +1,77 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriceCSV_ColumnsInAnyOrder(t *testing.T) {
+	csv := "Date,Price,Ticker,Currency\n2024-03-31,12.50,acme,eur\n31.12.2023,11,ACME,\n"
+
+	rows, issues, err := utils.ParsePriceCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "ACME", rows[0].Ticker)
+	assert.Equal(t, date(2024, 3, 31), rows[0].AsOf)
+	assert.True(t, df(12.5).Equal(rows[0].Price))
+	assert.Equal(t, "EUR", rows[0].Currency)
+
+	assert.Equal(t, date(2023, 12, 31), rows[1].AsOf)
+	assert.Equal(t, "", rows[1].Currency)
+}
+
+func TestParsePriceCSV_CurrencyColumnOptional(t *testing.T) {
+	rows, issues, err := utils.ParsePriceCSV(strings.NewReader("ticker,date,price\nPENSION,2024-01-31,\"1,05\"\n"))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	require.Len(t, rows, 1)
+	assert.True(t, df(1.05).Equal(rows[0].Price))
+}
+
+func TestParsePriceCSV_BadRowsReportedByLine(t *testing.T) {
+	csv := "ticker,date,price,currency\n" +
+		"ACME,2024-01-31,10,EUR\n" +
+		",2024-01-31,10,EUR\n" +
+		"ACME,yesterday,10,EUR\n" +
+		"ACME,2024-02-29,-1,EUR\n" +
+		"ACME,2024-02-29,10,EURO\n"
+
+	rows, issues, err := utils.ParsePriceCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	require.Len(t, issues, 4)
+	assert.Contains(t, issues[0], "line 3")
+	assert.Contains(t, issues[3], "line 6")
+}
+
+func TestParsePriceCSV_MissingColumnFails(t *testing.T) {
+	_, _, err := utils.ParsePriceCSV(strings.NewReader("ticker,price\nACME,10\n"))
+	assert.Error(t, err)
+}
+
+func TestDedupePriceRows_LastRowWins(t *testing.T) {
+	csv := "ticker,date,price\n" +
+		"ACME,2024-01-31,10\n" +
+		"OTHER,2024-01-31,5\n" +
+		"acme,31.01.2024,11\n" +
+		"ACME,2024-02-29,12\n"
+
+	rows, issues, err := utils.ParsePriceCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	kept, duplicates := utils.DedupePriceRows(rows)
+	require.Len(t, kept, 3)
+	assert.Equal(t, 4, kept[0].Line)
+	assert.True(t, df(11).Equal(kept[0].Price))
+	assert.Equal(t, "OTHER", kept[1].Ticker)
+	require.Len(t, duplicates, 1)
+	assert.Contains(t, duplicates[0], "line 4")
+	assert.Contains(t, duplicates[0], "line 2")
+}
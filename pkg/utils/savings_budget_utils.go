@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"sort"
+	"time"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var cent = decimal.New(1, -2)
+
+// GoalNeed is a goal's claim on a monthly savings budget.
+type GoalNeed struct {
+	GoalID     int64
+	Priority   int
+	Remaining  decimal.Decimal // target minus what is already saved
+	Monthly    decimal.Decimal // amount needed this month to stay on schedule, zero if unknown
+	Behind     bool
+	TargetDate *time.Time
+}
+
+// SavingsBudgetAmount resolves the budget for a month: the fixed amount, or a
+// percentage of last month's take-home income.
+func SavingsBudgetAmount(b models.SavingsBudget, lastMonthIncome decimal.Decimal) decimal.Decimal {
+	var amount decimal.Decimal
+	switch b.Mode {
+	case models.SavingsBudgetModeFixed:
+		if b.Amount != nil {
+			amount = *b.Amount
+		}
+	case models.SavingsBudgetModeIncomePercent:
+		if b.IncomePercent != nil && lastMonthIncome.IsPositive() {
+			amount = lastMonthIncome.Mul(*b.IncomePercent).Div(decimal.NewFromInt(100))
+		}
+	}
+	if amount.IsNegative() {
+		return decimal.Zero
+	}
+	return amount.RoundFloor(2)
+}
+
+// DistributeSavingsBudget splits budget across goals by strategy. No goal gets
+// more than its remaining amount, so part of the budget may stay unallocated.
+// Goals that get nothing are left out of the result.
+func DistributeSavingsBudget(budget decimal.Decimal, needs []GoalNeed, strategy models.SavingsBudgetStrategy) map[int64]decimal.Decimal {
+	alloc := make(map[int64]decimal.Decimal)
+	budget = budget.RoundFloor(2)
+	if !budget.IsPositive() || len(needs) == 0 {
+		return alloc
+	}
+
+	ordered := make([]GoalNeed, 0, len(needs))
+	for _, n := range needs {
+		if n.Remaining.IsPositive() {
+			ordered = append(ordered, n)
+		}
+	}
+	SortGoalNeedsByPriority(ordered)
+
+	switch strategy {
+	case models.SavingsBudgetStrategyProportional:
+		distributeProportionally(budget, ordered, alloc)
+	case models.SavingsBudgetStrategyDeadline:
+		// Goals falling behind go first, then whichever deadline is closest
+		sort.SliceStable(ordered, func(i, j int) bool {
+			if ordered[i].Behind != ordered[j].Behind {
+				return ordered[i].Behind
+			}
+			di, dj := ordered[i].TargetDate, ordered[j].TargetDate
+			if di == nil || dj == nil {
+				return di != nil && dj == nil
+			}
+			return di.Before(*dj)
+		})
+
+		left := budget
+		for _, n := range ordered {
+			need := n.Monthly
+			if !need.IsPositive() {
+				if !n.Behind {
+					continue
+				}
+				// overdue goals have no monthly schedule left; the whole rest is due
+				need = n.Remaining
+			}
+			give := decimal.Min(need.RoundCeil(2), n.Remaining, left)
+			if give.IsPositive() {
+				alloc[n.GoalID] = give
+				left = left.Sub(give)
+			}
+		}
+
+		// Whatever is left after schedules are met is shared by what each goal still lacks
+		if left.IsPositive() {
+			rest := make([]GoalNeed, 0, len(ordered))
+			for _, n := range ordered {
+				n.Remaining = n.Remaining.Sub(alloc[n.GoalID])
+				rest = append(rest, n)
+			}
+			SortGoalNeedsByPriority(rest)
+			distributeProportionally(left, rest, alloc)
+		}
+	default:
+		left := budget
+		for _, n := range ordered {
+			if !left.IsPositive() {
+				break
+			}
+			give := decimal.Min(n.Remaining, left)
+			alloc[n.GoalID] = give
+			left = left.Sub(give)
+		}
+	}
+
+	return alloc
+}
+
+// SortGoalNeedsByPriority orders goals the way auto-funding does: higher priority
+// first, unprioritised (0) goals last.
+func SortGoalNeedsByPriority(needs []GoalNeed) {
+	sort.SliceStable(needs, func(i, j int) bool {
+		pi, pj := needs[i].Priority, needs[j].Priority
+		if pi == 0 || pj == 0 {
+			return pi != 0 && pj == 0
+		}
+		if pi != pj {
+			return pi > pj
+		}
+		return needs[i].GoalID < needs[j].GoalID
+	})
+}
+
+// distributeProportionally adds to alloc each goal's share of budget weighted
+// by its remaining amount. Cents lost to rounding go to the largest remainders.
+func distributeProportionally(budget decimal.Decimal, needs []GoalNeed, alloc map[int64]decimal.Decimal) {
+	total := decimal.Zero
+	for _, n := range needs {
+		if n.Remaining.IsPositive() {
+			total = total.Add(n.Remaining)
+		}
+	}
+	if !total.IsPositive() {
+		return
+	}
+
+	shares := make([]decimal.Decimal, len(needs))
+	fractions := make([]decimal.Decimal, len(needs))
+	left := budget
+	for i, n := range needs {
+		if !n.Remaining.IsPositive() {
+			continue
+		}
+		share := n.Remaining
+		if total.GreaterThan(budget) {
+			exact := budget.Mul(n.Remaining).Div(total)
+			share = exact.RoundFloor(2)
+			fractions[i] = exact.Sub(share)
+		}
+		shares[i] = share
+		left = left.Sub(share)
+	}
+
+	order := make([]int, len(needs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]].GreaterThan(fractions[order[b]])
+	})
+
+	for left.GreaterThanOrEqual(cent) {
+		gave := false
+		for _, i := range order {
+			if left.LessThan(cent) {
+				break
+			}
+			if shares[i].Add(cent).GreaterThan(needs[i].Remaining) {
+				continue
+			}
+			shares[i] = shares[i].Add(cent)
+			left = left.Sub(cent)
+			gave = true
+		}
+		if !gave {
+			break
+		}
+	}
+
+	for i, n := range needs {
+		if shares[i].IsPositive() {
+			alloc[n.GoalID] = alloc[n.GoalID].Add(shares[i])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func sumAlloc(alloc map[int64]decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, v := range alloc {
+		total = total.Add(v)
+	}
+	return total
+}
+
+func TestSavingsBudgetAmount(t *testing.T) {
+	amount := df(250)
+	fixed := models.SavingsBudget{Mode: models.SavingsBudgetModeFixed, Amount: &amount}
+	assert.True(t, df(250).Equal(utils.SavingsBudgetAmount(fixed, df(4000))))
+
+	pct := df(15)
+	income := models.SavingsBudget{Mode: models.SavingsBudgetModeIncomePercent, IncomePercent: &pct}
+	assert.True(t, df(487.5).Equal(utils.SavingsBudgetAmount(income, df(3250))))
+	assert.True(t, utils.SavingsBudgetAmount(income, decimal.Zero).IsZero(), "no income last month, nothing to save")
+}
+
+func TestDistributeSavingsBudget_PriorityWaterfall(t *testing.T) {
+	needs := []utils.GoalNeed{
+		{GoalID: 1, Priority: 0, Remaining: df(500)},
+		{GoalID: 2, Priority: 5, Remaining: df(120)},
+		{GoalID: 3, Priority: 1, Remaining: df(300)},
+	}
+
+	alloc := utils.DistributeSavingsBudget(df(400), needs, models.SavingsBudgetStrategyPriority)
+	assert.True(t, df(120).Equal(alloc[2]))
+	assert.True(t, df(280).Equal(alloc[3]))
+	_, ok := alloc[1]
+	assert.False(t, ok, "unprioritised goals come last")
+
+	// A budget larger than all goals leaves the excess unallocated
+	alloc = utils.DistributeSavingsBudget(df(2000), needs, models.SavingsBudgetStrategyPriority)
+	assert.True(t, df(920).Equal(sumAlloc(alloc)))
+}
+
+func TestDistributeSavingsBudget_Proportional(t *testing.T) {
+	needs := []utils.GoalNeed{
+		{GoalID: 1, Priority: 1, Remaining: df(100)},
+		{GoalID: 2, Priority: 2, Remaining: df(200)},
+		{GoalID: 3, Priority: 3, Remaining: df(300)},
+		{GoalID: 4, Priority: 4, Remaining: decimal.Zero},
+	}
+
+	alloc := utils.DistributeSavingsBudget(df(100), needs, models.SavingsBudgetStrategyProportional)
+	assert.True(t, df(100).Equal(sumAlloc(alloc)), sumAlloc(alloc).String())
+	assert.True(t, df(16.67).Equal(alloc[1]), alloc[1].String())
+	assert.True(t, df(33.33).Equal(alloc[2]), alloc[2].String())
+	assert.True(t, df(50).Equal(alloc[3]), alloc[3].String())
+	_, ok := alloc[4]
+	assert.False(t, ok, "completed goals get nothing")
+}
+
+func TestDistributeSavingsBudget_DeadlineFavoursLateGoals(t *testing.T) {
+	soon := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	needs := []utils.GoalNeed{
+		{GoalID: 1, Priority: 9, Remaining: df(1200), Monthly: df(100), TargetDate: &later},
+		{GoalID: 2, Priority: 1, Remaining: df(600), Monthly: df(150), TargetDate: &soon, Behind: true},
+		{GoalID: 3, Priority: 1, Remaining: df(1000)},
+	}
+
+	// Only enough for part of the schedules: the late goal is served first
+	alloc := utils.DistributeSavingsBudget(df(200), needs, models.SavingsBudgetStrategyDeadline)
+	assert.True(t, df(150).Equal(alloc[2]))
+	assert.True(t, df(50).Equal(alloc[1]))
+	_, ok := alloc[3]
+	assert.False(t, ok)
+
+	// Once schedules are met the rest follows remaining amounts
+	alloc = utils.DistributeSavingsBudget(df(500), needs, models.SavingsBudgetStrategyDeadline)
+	assert.True(t, df(500).Equal(sumAlloc(alloc)))
+	assert.True(t, alloc[2].GreaterThan(df(150)))
+	assert.True(t, alloc[1].GreaterThan(df(100)))
+	assert.True(t, alloc[3].IsPositive())
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"sort"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// MilestoneAmount is the saved amount at which a milestone is reached.
+func MilestoneAmount(m models.SavingGoalMilestone, target decimal.Decimal) decimal.Decimal {
+	if m.Kind == models.SavingGoalMilestonePercent {
+		return target.Mul(m.Value).Div(decimal.NewFromInt(100))
+	}
+	return m.Value
+}
+
+// CrossedMilestones returns the milestones a goal passed when its saved amount
+// went from before to after, lowest first. Milestones already reached in the
+// given cycle are skipped, and a decrease crosses nothing.
+func CrossedMilestones(milestones []models.SavingGoalMilestone, target, before, after decimal.Decimal, cycle int) []models.SavingGoalMilestone {
+	if !after.GreaterThan(before) {
+		return nil
+	}
+
+	var crossed []models.SavingGoalMilestone
+	for _, m := range milestones {
+		if m.ReachedCycle != nil && *m.ReachedCycle == cycle {
+			continue
+		}
+		at := MilestoneAmount(m, target)
+		if at.GreaterThan(before) && at.LessThanOrEqual(after) {
+			crossed = append(crossed, m)
+		}
+	}
+
+	sort.SliceStable(crossed, func(i, j int) bool {
+		return MilestoneAmount(crossed[i], target).LessThan(MilestoneAmount(crossed[j], target))
+	})
+	return crossed
+}
+
+// MilestoneLabel describes a milestone the way a user set it, "50%" or "1000.00".
+func MilestoneLabel(m models.SavingGoalMilestone) string {
+	if m.Kind == models.SavingGoalMilestonePercent {
+		return m.Value.String() + "%"
+	}
+	return m.Value.StringFixed(2)
+}
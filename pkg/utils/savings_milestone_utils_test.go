@@ -0,0 +1,45 @@
+package utils_test
+
+import (
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossedMilestones(t *testing.T) {
+	reachedCycle := 1
+	milestones := []models.SavingGoalMilestone{
+		{ID: 1, Kind: models.SavingGoalMilestonePercent, Value: df(75)},
+		{ID: 2, Kind: models.SavingGoalMilestonePercent, Value: df(25)},
+		{ID: 3, Kind: models.SavingGoalMilestoneAmount, Value: df(300)},
+		{ID: 4, Kind: models.SavingGoalMilestonePercent, Value: df(50), ReachedCycle: &reachedCycle},
+		{ID: 5, Kind: models.SavingGoalMilestonePercent, Value: df(100)},
+	}
+
+	// 100 -> 800 of 1000 passes 25% (250), 300 and 75% (750); 50% already fired this cycle
+	crossed := utils.CrossedMilestones(milestones, df(1000), df(100), df(800), 1)
+	ids := make([]int64, len(crossed))
+	for i, m := range crossed {
+		ids[i] = m.ID
+	}
+	assert.Equal(t, []int64{2, 3, 1}, ids)
+
+	// Landing exactly on a milestone reaches it
+	crossed = utils.CrossedMilestones(milestones, df(1000), df(800), df(1000), 1)
+	assert.Len(t, crossed, 1)
+	assert.Equal(t, int64(5), crossed[0].ID)
+
+	// A new cycle re-arms milestones reached in the previous one
+	crossed = utils.CrossedMilestones(milestones, df(1000), df(400), df(600), 2)
+	assert.Len(t, crossed, 1)
+	assert.Equal(t, int64(4), crossed[0].ID)
+
+	assert.Empty(t, utils.CrossedMilestones(milestones, df(1000), df(800), df(100), 1), "withdrawals cross nothing")
+}
+
+func TestMilestoneLabel(t *testing.T) {
+	assert.Equal(t, "25%", utils.MilestoneLabel(models.SavingGoalMilestone{Kind: models.SavingGoalMilestonePercent, Value: df(25)}))
+	assert.Equal(t, "1500.00", utils.MilestoneLabel(models.SavingGoalMilestone{Kind: models.SavingGoalMilestoneAmount, Value: df(1500)}))
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"sort"
+	"wealth-warden/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// LimitThresholds returns the percentages a limit alerts at, lowest first.
+func LimitThresholds(l models.SpendingLimit) []int {
+	seen := map[int]bool{}
+	var out []int
+	add := func(p int) {
+		if p > 0 && !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	if l.NotifyAt80 {
+		add(80)
+	}
+	if l.NotifyAt100 {
+		add(100)
+	}
+	if l.CustomPercent != nil {
+		add(*l.CustomPercent)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// ReachedThresholds returns the thresholds that spent has reached against amount.
+func ReachedThresholds(thresholds []int, spent, amount decimal.Decimal) []int {
+	if !amount.IsPositive() {
+		return nil
+	}
+	var out []int
+	for _, p := range thresholds {
+		// spent / amount >= p / 100, without rounding the ratio
+		if spent.Mul(decimal.NewFromInt(100)).GreaterThanOrEqual(amount.Mul(decimal.NewFromInt(int64(p)))) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package utils_test
+
+import (
+	"testing"
+	"wealth-warden/internal/models"
+	"wealth-warden/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitThresholds(t *testing.T) {
+	custom := 50
+	assert.Equal(t, []int{50, 80, 100}, utils.LimitThresholds(models.SpendingLimit{NotifyAt80: true, NotifyAt100: true, CustomPercent: &custom}))
+
+	dup := 100
+	assert.Equal(t, []int{100}, utils.LimitThresholds(models.SpendingLimit{NotifyAt100: true, CustomPercent: &dup}))
+	assert.Empty(t, utils.LimitThresholds(models.SpendingLimit{}))
+}
+
+func TestReachedThresholds(t *testing.T) {
+	thresholds := []int{80, 100, 120}
+
+	assert.Empty(t, utils.ReachedThresholds(thresholds, df(319.99), df(400)))
+	assert.Equal(t, []int{80}, utils.ReachedThresholds(thresholds, df(320), df(400)))
+	assert.Equal(t, []int{80, 100}, utils.ReachedThresholds(thresholds, df(400), df(400)))
+	assert.Equal(t, []int{80, 100, 120}, utils.ReachedThresholds(thresholds, df(1000), df(400)))
+	assert.Empty(t, utils.ReachedThresholds(thresholds, df(10), df(0)))
+}
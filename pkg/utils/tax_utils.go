@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"sort"
 	"time"
 	"wealth-warden/internal/models"
 
@@ -20,66 +21,226 @@ func ApplyBracket(brackets []models.InvestmentTaxBracket, daysHeld int) *models.
 }
 
 type FifoLot struct {
+	TradeID    int64
 	TxnDate    time.Time
 	Quantity   decimal.Decimal
 	ValueAtBuy decimal.Decimal
 	Fee        decimal.Decimal
+
+	// pending is basis moved off the lot by corporate actions not yet effective at
+	// the point the lots were built to. ValueAtBuy and Fee already exclude it, but
+	// sells before the action still consume it.
+	pending []lotAdjustment
+}
+
+type lotAdjustment struct {
+	on    time.Time
+	value decimal.Decimal
+	fee   decimal.Decimal
+}
+
+func (l FifoLot) pendingBasis() (decimal.Decimal, decimal.Decimal) {
+	value, fee := decimal.Zero, decimal.Zero
+	for _, a := range l.pending {
+		value = value.Add(a.value)
+		fee = fee.Add(a.fee)
+	}
+	return value, fee
+}
+
+// settleLots drops the adjustments that are in effect on the given date.
+func settleLots(lots []FifoLot, on time.Time) {
+	for i := range lots {
+		if len(lots[i].pending) == 0 {
+			continue
+		}
+		var still []lotAdjustment
+		for _, a := range lots[i].pending {
+			if a.on.After(on) {
+				still = append(still, a)
+			}
+		}
+		lots[i].pending = still
+	}
+}
+
+// ResolveCostBasisMethod returns the asset's own cost-basis method, falling back to
+// the user's tax setting and then FIFO.
+func ResolveCostBasisMethod(asset models.InvestmentAsset, settings models.InvestmentTaxSettings) models.CostBasisMethod {
+	if asset.CostBasisMethod != nil && *asset.CostBasisMethod != "" {
+		return *asset.CostBasisMethod
+	}
+	if settings.CostBasisMethod != "" {
+		return settings.CostBasisMethod
+	}
+	return models.CostBasisFIFO
 }
 
 // BuildFifoLots processes trades in order (txn_date ASC, id ASC), consuming sells
 // against buy lots, and returns the remaining open lots. If stopAtID > 0 the loop
 // halts before that trade ID — use this when computing hold-period for a sell.
 func BuildFifoLots(trades []models.InvestmentTrade, stopAtID int64) []FifoLot {
+	return BuildLots(trades, stopAtID, models.CostBasisFIFO)
+}
+
+// BuildLots is BuildFifoLots for any cost-basis method. Sells that carry explicit
+// lot picks (trade.Lots) consume those lots first, whatever the method, except under
+// weighted average where individual lots can't be singled out.
+func BuildLots(trades []models.InvestmentTrade, stopAtID int64, method models.CostBasisMethod) []FifoLot {
 	var lots []FifoLot
 	for _, t := range trades {
 		if stopAtID > 0 && t.ID == stopAtID {
 			break
 		}
 		if t.TradeType == models.InvestmentBuy {
-			lots = append(lots, FifoLot{
-				TxnDate:    t.TxnDate,
-				Quantity:   t.Quantity,
-				ValueAtBuy: t.ValueAtBuy,
-				Fee:        t.Fee,
-			})
+			lots = addBuyLot(lots, t)
+		} else {
+			ConsumeLots(lots, t.TxnDate, t.Quantity, method, t.Lots)
+		}
+	}
+	return lots
+}
+
+// addBuyLot opens a lot for a buy, dated by when it was originally acquired. Lots
+// carried in by a transfer queue up by that date, ahead of any newer buys already
+// in the account; every other buy arrives in trade order and is simply appended.
+func addBuyLot(lots []FifoLot, t models.InvestmentTrade) []FifoLot {
+	lot := FifoLot{
+		TradeID:    t.ID,
+		TxnDate:    t.AcquiredOn(),
+		Quantity:   t.Quantity,
+		ValueAtBuy: t.ValueAtBuy,
+		Fee:        t.Fee,
+	}
+	for _, a := range t.BasisAdjustments {
+		lot.pending = append(lot.pending, lotAdjustment{on: a.EffectiveDate, value: a.ValueMoved, fee: a.FeeMoved})
+	}
+	if t.AcquiredAt == nil {
+		return append(lots, lot)
+	}
+
+	i := len(lots)
+	for i > 0 && lots[i-1].TxnDate.After(lot.TxnDate) {
+		i--
+	}
+	lots = append(lots, FifoLot{})
+	copy(lots[i+1:], lots[i:])
+	lots[i] = lot
+	return lots
+}
+
+// ConsumeLots removes quantity from the open lots according to method and returns
+// the consumed portions, each carrying its share of cost basis and fees. on is the
+// date of the sell, which decides whether basis moved off by a corporate action still
+// belongs to the lot. lots is modified in place. Quantity beyond what the lots hold
+// is ignored.
+func ConsumeLots(lots []FifoLot, on time.Time, quantity decimal.Decimal, method models.CostBasisMethod, picks []models.InvestmentTradeLot) []FifoLot {
+	var consumed []FifoLot
+	remaining := quantity
+
+	settleLots(lots, on)
+
+	take := func(i int, amount decimal.Decimal) {
+		if !amount.IsPositive() || !lots[i].Quantity.IsPositive() {
+			return
+		}
+		if amount.GreaterThan(lots[i].Quantity) {
+			amount = lots[i].Quantity
+		}
+		pendingValue, pendingFee := lots[i].pendingBasis()
+		value := lots[i].ValueAtBuy.Add(pendingValue)
+		fee := lots[i].Fee.Add(pendingFee)
+
+		part := FifoLot{TradeID: lots[i].TradeID, TxnDate: lots[i].TxnDate, Quantity: amount}
+		if amount.Equal(lots[i].Quantity) {
+			part.ValueAtBuy = value
+			part.Fee = fee
+			lots[i].Quantity = decimal.Zero
+			lots[i].ValueAtBuy = decimal.Zero
+			lots[i].Fee = decimal.Zero
+			lots[i].pending = nil
 		} else {
-			toConsume := t.Quantity
+			proportion := amount.Div(lots[i].Quantity)
+			part.ValueAtBuy = value.Mul(proportion)
+			part.Fee = fee.Mul(proportion)
+			lots[i].ValueAtBuy = value.Sub(part.ValueAtBuy).Sub(pendingValue)
+			lots[i].Fee = fee.Sub(part.Fee).Sub(pendingFee)
+			lots[i].Quantity = lots[i].Quantity.Sub(amount)
+		}
+		consumed = append(consumed, part)
+		remaining = remaining.Sub(amount)
+	}
+
+	if method == models.CostBasisAverage {
+		open := decimal.Zero
+		for _, lot := range lots {
+			open = open.Add(lot.Quantity)
+		}
+		if !open.IsPositive() {
+			return consumed
+		}
+		if remaining.GreaterThanOrEqual(open) {
 			for i := range lots {
-				if toConsume.IsZero() {
-					break
-				}
-				if lots[i].Quantity.LessThanOrEqual(toConsume) {
-					toConsume = toConsume.Sub(lots[i].Quantity)
-					lots[i].Quantity = decimal.Zero
-					lots[i].ValueAtBuy = decimal.Zero
-					lots[i].Fee = decimal.Zero
-				} else {
-					proportion := toConsume.Div(lots[i].Quantity)
-					lots[i].ValueAtBuy = lots[i].ValueAtBuy.Sub(lots[i].ValueAtBuy.Mul(proportion))
-					lots[i].Fee = lots[i].Fee.Sub(lots[i].Fee.Mul(proportion))
-					lots[i].Quantity = lots[i].Quantity.Sub(toConsume)
-					toConsume = decimal.Zero
-				}
+				take(i, lots[i].Quantity)
 			}
+			return consumed
 		}
+		share := remaining.Div(open)
+		for i := range lots {
+			take(i, lots[i].Quantity.Mul(share))
+		}
+		return consumed
 	}
-	return lots
+
+	for _, pick := range picks {
+		for i := range lots {
+			if lots[i].TradeID == pick.BuyTradeID {
+				take(i, decimal.Min(pick.Quantity, remaining))
+				break
+			}
+		}
+	}
+
+	if method == models.CostBasisLIFO {
+		for i := len(lots) - 1; i >= 0 && remaining.IsPositive(); i-- {
+			take(i, remaining)
+		}
+		return consumed
+	}
+
+	// FIFO, and the fallback for specific-lot sells without (enough) picks
+	for i := 0; i < len(lots) && remaining.IsPositive(); i++ {
+		take(i, remaining)
+	}
+	return consumed
 }
 
 // ComputeBuyTradeTaxInfo returns tax info for an open (unrealized) buy trade.
-func ComputeBuyTradeTaxInfo(trade models.InvestmentTrade, brackets []models.InvestmentTaxBracket, today time.Time) models.TradeTaxInfo {
-	daysHeld := int(today.UTC().Sub(trade.TxnDate.UTC()) / (24 * time.Hour))
+// When allAssetTrades is given, only the part of the lot still open under method
+// counts towards the profit; with nil the whole trade is treated as open.
+func ComputeBuyTradeTaxInfo(trade models.InvestmentTrade, allAssetTrades []models.InvestmentTrade, method models.CostBasisMethod, brackets []models.InvestmentTaxBracket, today time.Time) models.TradeTaxInfo {
+	daysHeld := int(today.UTC().Sub(trade.AcquiredOn().UTC()) / (24 * time.Hour))
 	info := models.TradeTaxInfo{DaysHeld: daysHeld}
 
+	profit := trade.ProfitLoss
+	if len(allAssetTrades) > 0 && trade.Quantity.IsPositive() {
+		for _, lot := range BuildLots(allAssetTrades, 0, method) {
+			if lot.TradeID == trade.ID {
+				profit = profit.Mul(lot.Quantity).Div(trade.Quantity)
+				break
+			}
+		}
+	}
+
 	bracket := ApplyBracket(brackets, daysHeld)
 	if bracket != nil {
 		p := bracket.TaxablePercent
 		info.TaxablePercent = &p
 		taxDue := decimal.Zero
-		if trade.ProfitLoss.IsPositive() {
-			taxDue = trade.ProfitLoss.Mul(bracket.TaxablePercent).Div(decimal.NewFromInt(100))
+		if profit.IsPositive() {
+			taxDue = profit.Mul(bracket.TaxablePercent).Div(decimal.NewFromInt(100))
 		}
-		info.TaxableProfit = trade.ProfitLoss.Sub(taxDue)
+		info.TaxableProfit = profit.Sub(taxDue)
 	}
 
 	for _, b := range brackets {
@@ -108,22 +269,16 @@ func ComputeBuyTradeTaxInfo(trade models.InvestmentTrade, brackets []models.Inve
 
 // ComputeSellTradeTaxInfo returns tax info for a realized sell trade.
 // allAssetTrades must include all trades for the asset sorted (txn_date ASC, id ASC).
-func ComputeSellTradeTaxInfo(sell models.InvestmentTrade, allAssetTrades []models.InvestmentTrade, brackets []models.InvestmentTaxBracket) models.TradeTaxInfo {
-	lots := BuildFifoLots(allAssetTrades, sell.ID)
+func ComputeSellTradeTaxInfo(sell models.InvestmentTrade, allAssetTrades []models.InvestmentTrade, method models.CostBasisMethod, brackets []models.InvestmentTaxBracket) models.TradeTaxInfo {
+	lots := BuildLots(allAssetTrades, sell.ID, method)
 
-	remaining := sell.Quantity
 	weightedDays := decimal.Zero
 	consumed := decimal.Zero
 
-	for _, lot := range lots {
-		if lot.Quantity.IsZero() || remaining.IsZero() {
-			continue
-		}
-		c := decimal.Min(lot.Quantity, remaining)
+	for _, lot := range ConsumeLots(lots, sell.TxnDate, sell.Quantity, method, sell.Lots) {
 		days := int64(sell.TxnDate.UTC().Sub(lot.TxnDate.UTC()) / (24 * time.Hour))
-		weightedDays = weightedDays.Add(c.Mul(decimal.NewFromInt(days)))
-		consumed = consumed.Add(c)
-		remaining = remaining.Sub(c)
+		weightedDays = weightedDays.Add(lot.Quantity.Mul(decimal.NewFromInt(days)))
+		consumed = consumed.Add(lot.Quantity)
 	}
 
 	var daysHeld int
@@ -147,14 +302,15 @@ func ComputeSellTradeTaxInfo(sell models.InvestmentTrade, allAssetTrades []model
 	return info
 }
 
-// ComputeAssetTaxSummary computes after-tax PnL for an asset across all open lots.
+// ComputeAssetTaxSummary computes after-tax PnL for an asset across all open lots,
+// using the asset's cost-basis method to decide which lots are still open.
 // allAssetTrades must be sorted (txn_date ASC, id ASC).
 func ComputeAssetTaxSummary(asset models.InvestmentAsset, allAssetTrades []models.InvestmentTrade, brackets []models.InvestmentTaxBracket, settings models.InvestmentTaxSettings, today time.Time) models.AssetTaxSummary {
 	if len(brackets) == 0 || asset.CurrentPrice == nil || asset.CurrentPrice.IsZero() {
 		return models.AssetTaxSummary{AfterTaxPnL: asset.ProfitLoss}
 	}
 
-	lots := BuildFifoLots(allAssetTrades, 0)
+	lots := BuildLots(allAssetTrades, 0, ResolveCostBasisMethod(asset, settings))
 
 	type openLot struct {
 		txnDate   time.Time
@@ -218,3 +374,284 @@ func ComputeAssetTaxSummary(asset models.InvestmentAsset, allAssetTrades []model
 		AfterTaxPnL:     asset.ProfitLoss.Sub(totalTax),
 	}
 }
+
+// RealizedGainsForYear lists every sell dated in year with the lots it consumed,
+// its holding period and the bracket that applies to it. trades must hold all of
+// the user's trades with Asset and Lots loaded, sorted (txn_date ASC, id ASC), so
+// earlier years still feed the lot matching. brackets may mix investment types.
+//
+// Rows stay in their trade currency. rate converts each of them into a single
+// currency on its sell date before the totals are taken, so gains and losses in
+// different currencies offset each other at their value on the day; nil treats
+// every row as already being in that currency.
+//
+// With loss offsetting enabled the year's losses reduce every gain by the same
+// share; otherwise losses are reported but never lower the taxable amount.
+func RealizedGainsForYear(trades []models.InvestmentTrade, brackets []models.InvestmentTaxBracket, settings models.InvestmentTaxSettings, year int, rate RateFunc) models.CapitalGainsSummary {
+	summary := models.CapitalGainsSummary{Year: year, LossOffsetting: settings.LossOffsettingEnabled}
+
+	byAsset := make(map[int64][]models.InvestmentTrade)
+	var assetOrder []int64
+	for _, t := range trades {
+		if _, ok := byAsset[t.AssetID]; !ok {
+			assetOrder = append(assetOrder, t.AssetID)
+		}
+		byAsset[t.AssetID] = append(byAsset[t.AssetID], t)
+	}
+
+	for _, assetID := range assetOrder {
+		assetTrades := byAsset[assetID]
+		asset := assetTrades[0].Asset
+		method := ResolveCostBasisMethod(asset, settings)
+
+		var typeBrackets []models.InvestmentTaxBracket
+		for _, b := range brackets {
+			if b.InvestmentType == asset.InvestmentType {
+				typeBrackets = append(typeBrackets, b)
+			}
+		}
+
+		var lots []FifoLot
+		for _, t := range assetTrades {
+			if t.TradeType == models.InvestmentBuy {
+				lots = addBuyLot(lots, t)
+				continue
+			}
+
+			consumed := ConsumeLots(lots, t.TxnDate, t.Quantity, method, t.Lots)
+			// Lots moved out in kind are still held, just elsewhere
+			if t.TxnDate.Year() != year || t.TransferID != nil {
+				continue
+			}
+			summary.Rows = append(summary.Rows, realizedGainRow(t, asset, consumed, typeBrackets))
+		}
+	}
+
+	sort.SliceStable(summary.Rows, func(i, j int) bool {
+		return summary.Rows[i].SellDate.Before(summary.Rows[j].SellDate)
+	})
+
+	for i := range summary.Rows {
+		r := &summary.Rows[i]
+		r.Rate = decimal.NewFromInt(1)
+		if rate != nil {
+			r.Rate = rate(r.Currency, r.SellDate)
+		}
+
+		summary.TotalProceeds = summary.TotalProceeds.Add(r.Proceeds.Mul(r.Rate))
+		if r.Gain.IsPositive() {
+			summary.TotalGains = summary.TotalGains.Add(r.Gain.Mul(r.Rate))
+		} else {
+			summary.TotalLosses = summary.TotalLosses.Add(r.Gain.Neg().Mul(r.Rate))
+		}
+	}
+	summary.NetGain = summary.TotalGains.Sub(summary.TotalLosses)
+
+	share := decimal.NewFromInt(1)
+	if summary.LossOffsetting && summary.TotalGains.IsPositive() {
+		share = decimal.Max(summary.NetGain, decimal.Zero).Div(summary.TotalGains)
+	}
+
+	hundred := decimal.NewFromInt(100)
+	for i := range summary.Rows {
+		r := &summary.Rows[i]
+		if !r.Gain.IsPositive() {
+			continue
+		}
+		r.TaxableGain = r.Gain.Mul(share).Round(4)
+		if r.Bracket != nil {
+			r.TaxDue = r.TaxableGain.Mul(r.Bracket.TaxablePercent).Div(hundred).Round(4)
+		}
+		summary.TaxableGain = summary.TaxableGain.Add(r.TaxableGain.Mul(r.Rate))
+		summary.TaxDue = summary.TaxDue.Add(r.TaxDue.Mul(r.Rate))
+	}
+
+	return summary
+}
+
+// RateFunc returns the rate that converts an amount in currency on the given date
+// into the currency being reported in.
+type RateFunc func(currency string, on time.Time) decimal.Decimal
+
+// realizedGainRow prices a single sell from the lots it consumed. Fees of
+// non-crypto trades are cash and count against the gain; crypto fees are paid in
+// coin units and are already reflected in the quantities.
+func realizedGainRow(sell models.InvestmentTrade, asset models.InvestmentAsset, consumed []FifoLot, brackets []models.InvestmentTaxBracket) models.RealizedGainRow {
+	row := models.RealizedGainRow{
+		TradeID:        sell.ID,
+		Ticker:         asset.Ticker,
+		AssetName:      asset.Name,
+		InvestmentType: asset.InvestmentType,
+		Currency:       sell.Currency,
+		SellDate:       sell.TxnDate,
+		Quantity:       sell.Quantity,
+		Proceeds:       sell.RealizedValue,
+	}
+
+	cryptoFees := asset.InvestmentType == models.InvestmentCrypto
+	if !cryptoFees {
+		row.Proceeds = row.Proceeds.Add(sell.Fee)
+		row.Fees = sell.Fee
+	}
+
+	weightedDays := decimal.Zero
+	consumedQty := decimal.Zero
+	seen := make(map[time.Time]bool)
+	for _, lot := range consumed {
+		row.CostBasis = row.CostBasis.Add(lot.ValueAtBuy)
+		if !cryptoFees {
+			row.Fees = row.Fees.Add(lot.Fee)
+		}
+		if !seen[lot.TxnDate] {
+			seen[lot.TxnDate] = true
+			row.AcquisitionDates = append(row.AcquisitionDates, lot.TxnDate)
+		}
+		days := int64(sell.TxnDate.UTC().Sub(lot.TxnDate.UTC()) / (24 * time.Hour))
+		weightedDays = weightedDays.Add(lot.Quantity.Mul(decimal.NewFromInt(days)))
+		consumedQty = consumedQty.Add(lot.Quantity)
+	}
+	sort.Slice(row.AcquisitionDates, func(i, j int) bool { return row.AcquisitionDates[i].Before(row.AcquisitionDates[j]) })
+
+	if consumedQty.IsPositive() {
+		row.DaysHeld = int(weightedDays.Div(consumedQty).IntPart())
+	}
+	row.Gain = row.Proceeds.Sub(row.CostBasis).Sub(row.Fees)
+	row.Bracket = ApplyBracket(brackets, row.DaysHeld)
+
+	return row
+}
+
+// TaxHints ranks the open positions for tax planning on today. Assets at a loss
+// are harvest candidates when loss offsetting is enabled, ordered by the size of
+// the loss; each is assigned the share of this year's net realized gain it would
+// cancel, largest first. Lots in profit that reach a lower bracket within window
+// days are hold candidates, ordered by the tax that waiting saves.
+//
+// trades must hold all of the user's trades with Asset and Lots loaded, sorted
+// (txn_date ASC, id ASC). brackets may mix investment types.
+func TaxHints(trades []models.InvestmentTrade, brackets []models.InvestmentTaxBracket, settings models.InvestmentTaxSettings, today time.Time, window int) models.TaxOptimizationHints {
+	today = today.UTC().Truncate(24 * time.Hour)
+	realized := RealizedGainsForYear(trades, brackets, settings, today.Year(), nil)
+
+	hints := models.TaxOptimizationHints{
+		Year:            today.Year(),
+		LossOffsetting:  settings.LossOffsettingEnabled,
+		RealizedNetGain: realized.NetGain,
+		RealizedTaxDue:  realized.TaxDue,
+		Harvest:         []models.TaxHarvestCandidate{},
+		Hold:            []models.TaxHoldCandidate{},
+	}
+
+	byAsset := make(map[int64][]models.InvestmentTrade)
+	var assetOrder []int64
+	for _, t := range trades {
+		if _, ok := byAsset[t.AssetID]; !ok {
+			assetOrder = append(assetOrder, t.AssetID)
+		}
+		byAsset[t.AssetID] = append(byAsset[t.AssetID], t)
+	}
+
+	hundred := decimal.NewFromInt(100)
+	for _, assetID := range assetOrder {
+		assetTrades := byAsset[assetID]
+		asset := assetTrades[0].Asset
+		if asset.CurrentPrice == nil || asset.CurrentPrice.IsZero() {
+			continue
+		}
+
+		var typeBrackets []models.InvestmentTaxBracket
+		for _, b := range brackets {
+			if b.InvestmentType == asset.InvestmentType {
+				typeBrackets = append(typeBrackets, b)
+			}
+		}
+
+		harvest := models.TaxHarvestCandidate{
+			AssetID:        asset.ID,
+			Ticker:         asset.Ticker,
+			Name:           asset.Name,
+			InvestmentType: asset.InvestmentType,
+			Currency:       asset.Currency,
+		}
+
+		for _, lot := range BuildLots(assetTrades, 0, ResolveCostBasisMethod(asset, settings)) {
+			if !lot.Quantity.IsPositive() {
+				continue
+			}
+			costBasis := lot.ValueAtBuy
+			if asset.InvestmentType != models.InvestmentCrypto {
+				costBasis = costBasis.Add(lot.Fee)
+			}
+			value := lot.Quantity.Mul(*asset.CurrentPrice)
+			pnl := value.Sub(costBasis)
+
+			if pnl.IsNegative() {
+				harvest.Quantity = harvest.Quantity.Add(lot.Quantity)
+				harvest.CostBasis = harvest.CostBasis.Add(costBasis)
+				harvest.MarketValue = harvest.MarketValue.Add(value)
+				harvest.UnrealizedLoss = harvest.UnrealizedLoss.Sub(pnl)
+				continue
+			}
+			if !pnl.IsPositive() || len(typeBrackets) == 0 {
+				continue
+			}
+
+			info := ComputeBuyTradeTaxInfo(models.InvestmentTrade{TxnDate: lot.TxnDate}, nil, "", typeBrackets, today)
+			if info.TaxablePercent == nil || info.DaysUntilNextBracket == nil || *info.DaysUntilNextBracket > window {
+				continue
+			}
+			next := ApplyBracket(typeBrackets, info.DaysHeld+*info.DaysUntilNextBracket)
+			if next == nil || !next.TaxablePercent.LessThan(*info.TaxablePercent) {
+				continue
+			}
+
+			hints.Hold = append(hints.Hold, models.TaxHoldCandidate{
+				TradeID:               lot.TradeID,
+				AssetID:               asset.ID,
+				Ticker:                asset.Ticker,
+				Name:                  asset.Name,
+				InvestmentType:        asset.InvestmentType,
+				Currency:              asset.Currency,
+				BuyDate:               lot.TxnDate,
+				Quantity:              lot.Quantity,
+				MarketValue:           value.Round(4),
+				UnrealizedGain:        pnl.Round(4),
+				DaysHeld:              info.DaysHeld,
+				DaysUntilNextBracket:  *info.DaysUntilNextBracket,
+				DaysUntilTaxFree:      info.DaysUntilTaxFree,
+				CurrentTaxablePercent: *info.TaxablePercent,
+				NextTaxablePercent:    next.TaxablePercent,
+				TaxSavedByWaiting:     pnl.Mul(info.TaxablePercent.Sub(next.TaxablePercent)).Div(hundred).Round(4),
+			})
+		}
+
+		if settings.LossOffsettingEnabled && harvest.UnrealizedLoss.IsPositive() {
+			harvest.MarketValue = harvest.MarketValue.Round(4)
+			harvest.UnrealizedLoss = harvest.UnrealizedLoss.Round(4)
+			hints.Harvest = append(hints.Harvest, harvest)
+		}
+	}
+
+	sort.SliceStable(hints.Harvest, func(i, j int) bool {
+		return hints.Harvest[i].UnrealizedLoss.GreaterThan(hints.Harvest[j].UnrealizedLoss)
+	})
+
+	// Losses only lower the tax while there is net gain left for them to cancel
+	remaining := decimal.Max(realized.NetGain, decimal.Zero)
+	taxRate := decimal.Zero
+	if realized.TaxableGain.IsPositive() {
+		taxRate = realized.TaxDue.Div(realized.TaxableGain)
+	}
+	for i := range hints.Harvest {
+		h := &hints.Harvest[i]
+		h.GainOffset = decimal.Min(h.UnrealizedLoss, remaining)
+		h.EstimatedTaxSaved = h.GainOffset.Mul(taxRate).Round(4)
+		remaining = remaining.Sub(h.GainOffset)
+	}
+
+	sort.SliceStable(hints.Hold, func(i, j int) bool {
+		return hints.Hold[i].TaxSavedByWaiting.GreaterThan(hints.Hold[j].TaxSavedByWaiting)
+	})
+
+	return hints
+}
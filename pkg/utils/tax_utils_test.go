@@ -136,6 +136,89 @@ func TestBuildFifoLots_StopAtID(t *testing.T) {
 	assert.True(t, df(10).Equal(lots[0].Quantity), "sell should not be applied when stopAtID=2")
 }
 
+// --- BuildLots / ConsumeLots ---
+
+func TestBuildLots_LifoConsumesNewestFirst(t *testing.T) {
+	trades := []models.InvestmentTrade{
+		buyTrade(1, 200, 5, 50, 0, 0),
+		buyTrade(2, 100, 5, 75, 0, 0),
+		sellTrade(3, 50, 7),
+	}
+	lots := utils.BuildLots(trades, 0, models.CostBasisLIFO)
+	assert.True(t, df(3).Equal(lots[0].Quantity), "lot1 should have 3 remaining")
+	assert.True(t, df(30).Equal(lots[0].ValueAtBuy))
+	assert.True(t, lots[1].Quantity.IsZero(), "lot2 should be fully consumed")
+}
+
+func TestBuildLots_AverageReducesAllLotsEvenly(t *testing.T) {
+	trades := []models.InvestmentTrade{
+		buyTrade(1, 200, 5, 50, 0, 0),
+		buyTrade(2, 100, 5, 100, 0, 0),
+		sellTrade(3, 50, 5),
+	}
+	lots := utils.BuildLots(trades, 0, models.CostBasisAverage)
+	assert.True(t, df(2.5).Equal(lots[0].Quantity))
+	assert.True(t, df(25).Equal(lots[0].ValueAtBuy))
+	assert.True(t, df(2.5).Equal(lots[1].Quantity))
+	assert.True(t, df(50).Equal(lots[1].ValueAtBuy))
+}
+
+func TestBuildLots_SpecificLotPicks(t *testing.T) {
+	sell := sellTrade(3, 50, 4)
+	sell.Lots = []models.InvestmentTradeLot{{BuyTradeID: 2, Quantity: df(4)}}
+	trades := []models.InvestmentTrade{
+		buyTrade(1, 200, 5, 50, 0, 0),
+		buyTrade(2, 100, 5, 100, 0, 0),
+		sell,
+	}
+	lots := utils.BuildLots(trades, 0, models.CostBasisSpecificLot)
+	assert.True(t, df(5).Equal(lots[0].Quantity), "unpicked lot stays open")
+	assert.True(t, df(1).Equal(lots[1].Quantity))
+}
+
+func TestConsumeLots_SpecificLotFallsBackToFifo(t *testing.T) {
+	lots := []utils.FifoLot{
+		{TradeID: 1, Quantity: df(5), ValueAtBuy: df(50)},
+		{TradeID: 2, Quantity: df(5), ValueAtBuy: df(100)},
+	}
+	picks := []models.InvestmentTradeLot{{BuyTradeID: 2, Quantity: df(2)}}
+	consumed := utils.ConsumeLots(lots, taxToday, df(4), models.CostBasisSpecificLot, picks)
+	assert.Len(t, consumed, 2)
+	assert.Equal(t, int64(2), consumed[0].TradeID)
+	assert.True(t, df(40).Equal(consumed[0].ValueAtBuy))
+	assert.Equal(t, int64(1), consumed[1].TradeID)
+	assert.True(t, df(2).Equal(consumed[1].Quantity))
+}
+
+// A spin-off moved 40% of the open basis off the buy: sells before it keep the
+// original basis, sells after it see the reduced one.
+func TestBuildLots_BasisAdjustment(t *testing.T) {
+	buy := buyTrade(1, 100, 10, 80, 0, 0)
+	buy.BasisAdjustments = []models.InvestmentBasisAdjustment{{TradeID: 1, EffectiveDate: daysAgo(50), ValueMoved: df(20)}}
+	early := sellTrade(2, 60, 5)
+	late := sellTrade(3, 10, 1)
+	trades := []models.InvestmentTrade{buy, early, late}
+
+	consumed := utils.ConsumeLots(utils.BuildLots(trades, early.ID, models.CostBasisFIFO), early.TxnDate, early.Quantity, models.CostBasisFIFO, nil)
+	assert.Len(t, consumed, 1)
+	assert.True(t, df(50).Equal(consumed[0].ValueAtBuy), "sold before the spin-off: %s", consumed[0].ValueAtBuy)
+
+	consumed = utils.ConsumeLots(utils.BuildLots(trades, late.ID, models.CostBasisFIFO), late.TxnDate, late.Quantity, models.CostBasisFIFO, nil)
+	assert.Len(t, consumed, 1)
+	assert.True(t, df(6).Equal(consumed[0].ValueAtBuy), "sold after the spin-off: %s", consumed[0].ValueAtBuy)
+
+	lots := utils.BuildLots(trades, 0, models.CostBasisFIFO)
+	assert.True(t, df(4).Equal(lots[0].Quantity))
+	assert.True(t, df(24).Equal(lots[0].ValueAtBuy))
+}
+
+func TestResolveCostBasisMethod(t *testing.T) {
+	lifo := models.CostBasisLIFO
+	assert.Equal(t, models.CostBasisFIFO, utils.ResolveCostBasisMethod(models.InvestmentAsset{}, models.InvestmentTaxSettings{}))
+	assert.Equal(t, models.CostBasisAverage, utils.ResolveCostBasisMethod(models.InvestmentAsset{}, models.InvestmentTaxSettings{CostBasisMethod: models.CostBasisAverage}))
+	assert.Equal(t, models.CostBasisLIFO, utils.ResolveCostBasisMethod(models.InvestmentAsset{CostBasisMethod: &lifo}, models.InvestmentTaxSettings{CostBasisMethod: models.CostBasisAverage}))
+}
+
 // --- ComputeBuyTradeTaxInfo ---
 
 var sloETFBrackets = []models.InvestmentTaxBracket{
@@ -145,7 +228,7 @@ var sloETFBrackets = []models.InvestmentTaxBracket{
 
 func TestComputeBuyTradeTaxInfo_NoBrackets(t *testing.T) {
 	trade := buyTrade(1, 500, 10, 100, 0, 50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, nil, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, nil, taxToday)
 	assert.Equal(t, 500, info.DaysHeld)
 	assert.Nil(t, info.TaxablePercent)
 	assert.True(t, info.TaxableProfit.IsZero())
@@ -153,7 +236,7 @@ func TestComputeBuyTradeTaxInfo_NoBrackets(t *testing.T) {
 
 func TestComputeBuyTradeTaxInfo_PositivePnL(t *testing.T) {
 	trade := buyTrade(1, 500, 10, 100, 0, 80)
-	info := utils.ComputeBuyTradeTaxInfo(trade, sloETFBrackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, sloETFBrackets, taxToday)
 
 	assert.Equal(t, 500, info.DaysHeld)
 	assert.NotNil(t, info.TaxablePercent)
@@ -164,21 +247,21 @@ func TestComputeBuyTradeTaxInfo_PositivePnL(t *testing.T) {
 
 func TestComputeBuyTradeTaxInfo_NegativePnLNotTaxed(t *testing.T) {
 	trade := buyTrade(1, 500, 10, 200, 0, -50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, sloETFBrackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, sloETFBrackets, taxToday)
 	// no tax on losses → after-tax profit equals raw pnl
 	assert.True(t, df(-50).Equal(info.TaxableProfit))
 }
 
 func TestComputeBuyTradeTaxInfo_DaysUntilNextBracket(t *testing.T) {
 	trade := buyTrade(1, 500, 10, 100, 0, 50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, sloETFBrackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, sloETFBrackets, taxToday)
 	assert.NotNil(t, info.DaysUntilNextBracket)
 	assert.Equal(t, 1826-500, *info.DaysUntilNextBracket)
 }
 
 func TestComputeBuyTradeTaxInfo_AlreadyAtTaxFreeBracket(t *testing.T) {
 	trade := buyTrade(1, 2000, 10, 100, 0, 50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, sloETFBrackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, sloETFBrackets, taxToday)
 
 	assert.NotNil(t, info.TaxablePercent)
 	assert.True(t, info.TaxablePercent.IsZero())
@@ -191,7 +274,7 @@ func TestComputeBuyTradeTaxInfo_AlreadyAtTaxFreeBracket(t *testing.T) {
 
 func TestComputeBuyTradeTaxInfo_DaysUntilTaxFree(t *testing.T) {
 	trade := buyTrade(1, 500, 10, 100, 0, 50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, sloETFBrackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, sloETFBrackets, taxToday)
 	assert.NotNil(t, info.DaysUntilTaxFree)
 	assert.Equal(t, 1826-500, *info.DaysUntilTaxFree)
 }
@@ -199,10 +282,18 @@ func TestComputeBuyTradeTaxInfo_DaysUntilTaxFree(t *testing.T) {
 func TestComputeBuyTradeTaxInfo_NoTaxFreeBracket(t *testing.T) {
 	brackets := []models.InvestmentTaxBracket{bracket(0, 27.5)}
 	trade := buyTrade(1, 100, 10, 100, 0, 50)
-	info := utils.ComputeBuyTradeTaxInfo(trade, brackets, taxToday)
+	info := utils.ComputeBuyTradeTaxInfo(trade, nil, models.CostBasisFIFO, brackets, taxToday)
 	assert.Nil(t, info.DaysUntilTaxFree)
 }
 
+func TestComputeBuyTradeTaxInfo_OnlyOpenPartIsTaxed(t *testing.T) {
+	trade := buyTrade(1, 400, 10, 1000, 0, 500)
+	all := []models.InvestmentTrade{trade, sellTrade(2, 100, 6)}
+	info := utils.ComputeBuyTradeTaxInfo(trade, all, models.CostBasisFIFO, []models.InvestmentTaxBracket{bracket(0, 50)}, taxToday)
+	// 4 of 10 units are still open, so 200 of the 500 profit remains; half of it is taxed
+	assert.True(t, df(100).Equal(info.TaxableProfit), "got %s", info.TaxableProfit)
+}
+
 // --- ComputeSellTradeTaxInfo ---
 
 func TestComputeSellTradeTaxInfo_SingleLot(t *testing.T) {
@@ -218,7 +309,7 @@ func TestComputeSellTradeTaxInfo_SingleLot(t *testing.T) {
 		ProfitLoss: df(50),
 	}
 
-	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, sloETFBrackets)
+	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, models.CostBasisFIFO, sloETFBrackets)
 
 	assert.Equal(t, 200, info.DaysHeld)
 	assert.NotNil(t, info.TaxablePercent)
@@ -243,7 +334,7 @@ func TestComputeSellTradeTaxInfo_WeightedAvgDaysHeld(t *testing.T) {
 		ProfitLoss: df(0),
 	}
 
-	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, sloETFBrackets)
+	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, models.CostBasisFIFO, sloETFBrackets)
 
 	expected := (5*100 + 2*300) / 7
 	assert.Equal(t, expected, info.DaysHeld)
@@ -258,7 +349,7 @@ func TestComputeSellTradeTaxInfo_NoBrackets(t *testing.T) {
 		Quantity:  df(10),
 	}
 
-	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, nil)
+	info := utils.ComputeSellTradeTaxInfo(sell, allTrades, models.CostBasisFIFO, nil)
 	assert.Nil(t, info.TaxablePercent)
 	assert.True(t, info.TaxableProfit.IsZero())
 }
@@ -361,3 +452,242 @@ func TestComputeAssetTaxSummary_LossOffsetting_ReducesTax(t *testing.T) {
 
 	assert.True(t, without.EstimatedTaxDue.GreaterThan(with.EstimatedTaxDue))
 }
+
+// --- RealizedGainsForYear ---
+
+var gainsAsset = models.InvestmentAsset{ID: 5, Ticker: "VWCE", Name: "FTSE All-World", InvestmentType: models.InvestmentETF}
+
+func realizedSell(id int64, daysHeld int, qty, proceeds, fee float64) models.InvestmentTrade {
+	t := sellTrade(id, daysHeld, qty)
+	t.RealizedValue = df(proceeds)
+	t.Fee = df(fee)
+	return t
+}
+
+func forAsset(asset models.InvestmentAsset, trades ...models.InvestmentTrade) []models.InvestmentTrade {
+	for i := range trades {
+		trades[i].AssetID = asset.ID
+		trades[i].Asset = asset
+	}
+	return trades
+}
+
+func TestRealizedGainsForYear_SellRow(t *testing.T) {
+	// 10 units bought 400 days ago for 100 (+2 fee), sold 5 of them 10 days ago
+	// for 80 net of a 1 fee: proceeds 81, basis 50, fees 1+1, gain 29.
+	trades := forAsset(gainsAsset,
+		buyTrade(1, 400, 10, 100, 2, 0),
+		realizedSell(2, 10, 5, 80, 1),
+	)
+	brackets := []models.InvestmentTaxBracket{bracket(0, 25), bracket(365, 20)}
+	brackets[0].InvestmentType = models.InvestmentETF
+	brackets[1].InvestmentType = models.InvestmentETF
+
+	s := utils.RealizedGainsForYear(trades, brackets, models.InvestmentTaxSettings{}, taxToday.Year(), nil)
+
+	assert.Len(t, s.Rows, 1)
+	row := s.Rows[0]
+	assert.Equal(t, []time.Time{daysAgo(400)}, row.AcquisitionDates)
+	assert.Equal(t, 390, row.DaysHeld)
+	assert.True(t, df(81).Equal(row.Proceeds))
+	assert.True(t, df(50).Equal(row.CostBasis))
+	assert.True(t, df(2).Equal(row.Fees))
+	assert.True(t, df(29).Equal(row.Gain))
+	if assert.NotNil(t, row.Bracket) {
+		assert.Equal(t, 365, row.Bracket.MinDaysHeld)
+	}
+	assert.True(t, df(29).Equal(row.TaxableGain))
+	assert.True(t, df(5.8).Equal(row.TaxDue))
+}
+
+func TestRealizedGainsForYear_OnlySellsInYear(t *testing.T) {
+	// The earlier sell still consumes the first lot, so this year's sell is
+	// matched against the second one.
+	trades := forAsset(gainsAsset,
+		buyTrade(1, 900, 10, 100, 0, 0),
+		buyTrade(2, 600, 10, 200, 0, 0),
+		realizedSell(3, 500, 10, 150, 0),
+		realizedSell(4, 5, 10, 300, 0),
+	)
+
+	s := utils.RealizedGainsForYear(trades, nil, models.InvestmentTaxSettings{}, taxToday.Year(), nil)
+
+	assert.Len(t, s.Rows, 1)
+	assert.Equal(t, int64(4), s.Rows[0].TradeID)
+	assert.True(t, df(200).Equal(s.Rows[0].CostBasis))
+	assert.True(t, df(100).Equal(s.Rows[0].Gain))
+}
+
+func TestRealizedGainsForYear_LossOffsetting(t *testing.T) {
+	other := models.InvestmentAsset{ID: 6, Ticker: "XYZ", InvestmentType: models.InvestmentETF}
+	trades := append(
+		forAsset(gainsAsset, buyTrade(1, 90, 10, 100, 0, 0), realizedSell(2, 20, 10, 300, 0)),
+		forAsset(other, buyTrade(3, 80, 10, 200, 0, 0), realizedSell(4, 10, 10, 150, 0))...,
+	)
+	b := bracket(0, 25)
+	b.InvestmentType = models.InvestmentETF
+	brackets := []models.InvestmentTaxBracket{b}
+
+	without := utils.RealizedGainsForYear(trades, brackets, models.InvestmentTaxSettings{}, taxToday.Year(), nil)
+	assert.True(t, df(200).Equal(without.TotalGains))
+	assert.True(t, df(50).Equal(without.TotalLosses))
+	assert.True(t, df(200).Equal(without.TaxableGain))
+	assert.True(t, df(50).Equal(without.TaxDue))
+
+	with := utils.RealizedGainsForYear(trades, brackets, models.InvestmentTaxSettings{LossOffsettingEnabled: true}, taxToday.Year(), nil)
+	assert.True(t, df(150).Equal(with.NetGain))
+	assert.True(t, df(150).Equal(with.TaxableGain))
+	assert.True(t, df(37.5).Equal(with.TaxDue))
+	assert.True(t, with.Rows[1].TaxableGain.IsZero())
+}
+
+// A loss in another currency offsets gains at its value on the sell date.
+func TestRealizedGainsForYear_ConvertsBeforeOffsetting(t *testing.T) {
+	other := models.InvestmentAsset{ID: 6, Ticker: "XYZ", InvestmentType: models.InvestmentETF}
+	gain := realizedSell(2, 20, 10, 300, 0)
+	gain.Currency = "EUR"
+	loss := realizedSell(4, 10, 10, 150, 0)
+	loss.Currency = "USD"
+	trades := append(
+		forAsset(gainsAsset, buyTrade(1, 90, 10, 100, 0, 0), gain),
+		forAsset(other, buyTrade(3, 80, 10, 200, 0, 0), loss)...,
+	)
+	b := bracket(0, 25)
+	b.InvestmentType = models.InvestmentETF
+
+	toEUR := func(currency string, _ time.Time) decimal.Decimal {
+		if currency == "USD" {
+			return df(0.8)
+		}
+		return df(1)
+	}
+	s := utils.RealizedGainsForYear(trades, []models.InvestmentTaxBracket{b}, models.InvestmentTaxSettings{LossOffsettingEnabled: true}, taxToday.Year(), toEUR)
+
+	assert.True(t, df(420).Equal(s.TotalProceeds), s.TotalProceeds.String())
+	assert.True(t, df(40).Equal(s.TotalLosses), s.TotalLosses.String())
+	assert.True(t, df(160).Equal(s.NetGain), s.NetGain.String())
+	assert.True(t, df(160).Equal(s.TaxableGain), s.TaxableGain.String())
+	assert.True(t, df(40).Equal(s.TaxDue), s.TaxDue.String())
+	assert.True(t, df(-50).Equal(s.Rows[1].Gain), "rows stay in their own currency")
+	assert.True(t, df(0.8).Equal(s.Rows[1].Rate))
+}
+
+func TestRealizedGainsForYear_CryptoFeesInCoins(t *testing.T) {
+	coin := models.InvestmentAsset{ID: 7, Ticker: "BTC", InvestmentType: models.InvestmentCrypto}
+	trades := forAsset(coin, buyTrade(1, 30, 1, 100, 0.01, 0), realizedSell(2, 1, 1, 150, 0.01))
+
+	s := utils.RealizedGainsForYear(trades, nil, models.InvestmentTaxSettings{}, taxToday.Year(), nil)
+
+	assert.True(t, df(150).Equal(s.Rows[0].Proceeds))
+	assert.True(t, s.Rows[0].Fees.IsZero())
+	assert.True(t, df(50).Equal(s.Rows[0].Gain))
+	assert.Nil(t, s.Rows[0].Bracket)
+	assert.True(t, s.Rows[0].TaxDue.IsZero())
+}
+
+// --- In-kind transfers ---
+
+// transferredBuy is a lot carried into the account daysAgo(arrived), originally
+// bought daysAgo(acquired).
+func transferredBuy(id int64, arrived, acquired int, qty, valueAtBuy float64) models.InvestmentTrade {
+	t := buyTrade(id, arrived, qty, valueAtBuy, 0, 0)
+	transferID := int64(1)
+	at := daysAgo(acquired)
+	t.TransferID = &transferID
+	t.AcquiredAt = &at
+	return t
+}
+
+func TestBuildLots_TransferredLotKeepsPurchaseDate(t *testing.T) {
+	// The carried-in lot arrived after the local buy but was bought long before it,
+	// so FIFO sells it first.
+	trades := []models.InvestmentTrade{
+		buyTrade(1, 100, 5, 50, 0, 0),
+		transferredBuy(2, 50, 400, 5, 20),
+		sellTrade(3, 10, 5),
+	}
+	lots := utils.BuildLots(trades, 0, models.CostBasisFIFO)
+	assert.Equal(t, int64(2), lots[0].TradeID)
+	assert.Equal(t, daysAgo(400), lots[0].TxnDate)
+	assert.True(t, lots[0].Quantity.IsZero(), "carried-in lot should be consumed first")
+	assert.True(t, df(5).Equal(lots[1].Quantity))
+
+	info := utils.ComputeBuyTradeTaxInfo(transferredBuy(2, 50, 400, 5, 20), nil, models.CostBasisFIFO, nil, taxToday)
+	assert.Equal(t, 400, info.DaysHeld)
+}
+
+func TestRealizedGainsForYear_SkipsTransfers(t *testing.T) {
+	out := realizedSell(2, 10, 10, 100, 0)
+	transferID := int64(1)
+	out.TransferID = &transferID
+	trades := forAsset(gainsAsset, buyTrade(1, 400, 10, 100, 0, 0), out)
+
+	s := utils.RealizedGainsForYear(trades, nil, models.InvestmentTaxSettings{}, taxToday.Year(), nil)
+
+	assert.Empty(t, s.Rows)
+	assert.True(t, s.TotalGains.IsZero())
+}
+
+// --- TaxHints ---
+
+func pricedAsset(id int64, ticker string, price float64) models.InvestmentAsset {
+	p := df(price)
+	return models.InvestmentAsset{ID: id, Ticker: ticker, InvestmentType: models.InvestmentETF, CurrentPrice: &p}
+}
+
+func TestTaxHints_HarvestRankedByLossAndOffsetsGain(t *testing.T) {
+	// 200 of gain realized this year at 25%; two positions sit at a loss
+	trades := forAsset(gainsAsset, buyTrade(1, 200, 10, 100, 0, 0), realizedSell(2, 20, 10, 300, 0))
+	trades = append(trades, forAsset(pricedAsset(8, "AAA", 5), buyTrade(3, 100, 10, 150, 0, 0))...)
+	trades = append(trades, forAsset(pricedAsset(9, "BBB", 9), buyTrade(4, 90, 10, 250, 0, 0))...)
+	b := bracket(0, 25)
+	b.InvestmentType = models.InvestmentETF
+	brackets := []models.InvestmentTaxBracket{b}
+
+	hints := utils.TaxHints(trades, brackets, models.InvestmentTaxSettings{LossOffsettingEnabled: true}, taxToday, 30)
+
+	assert.True(t, df(200).Equal(hints.RealizedNetGain))
+	if assert.Len(t, hints.Harvest, 2) {
+		first, second := hints.Harvest[0], hints.Harvest[1]
+		assert.Equal(t, "BBB", first.Ticker)
+		assert.True(t, df(160).Equal(first.UnrealizedLoss))
+		assert.True(t, df(160).Equal(first.GainOffset))
+		assert.True(t, df(40).Equal(first.EstimatedTaxSaved))
+
+		assert.Equal(t, "AAA", second.Ticker)
+		assert.True(t, df(100).Equal(second.UnrealizedLoss))
+		assert.True(t, df(40).Equal(second.GainOffset), "only the gain left after the first candidate")
+		assert.True(t, df(10).Equal(second.EstimatedTaxSaved))
+	}
+
+	without := utils.TaxHints(trades, brackets, models.InvestmentTaxSettings{}, taxToday, 30)
+	assert.Empty(t, without.Harvest)
+}
+
+func TestTaxHints_HoldLotsNearLowerBracket(t *testing.T) {
+	asset := pricedAsset(8, "AAA", 20)
+	trades := forAsset(asset,
+		buyTrade(1, 400, 10, 100, 0, 0), // already tax-free
+		buyTrade(2, 360, 10, 100, 0, 0), // tax-free in 5 days
+		buyTrade(3, 100, 10, 100, 0, 0), // too far out
+		buyTrade(4, 362, 10, 300, 0, 0), // at a loss
+	)
+	brackets := []models.InvestmentTaxBracket{bracket(0, 25), bracket(365, 0)}
+	for i := range brackets {
+		brackets[i].InvestmentType = models.InvestmentETF
+	}
+
+	hints := utils.TaxHints(trades, brackets, models.InvestmentTaxSettings{}, taxToday, 30)
+
+	if assert.Len(t, hints.Hold, 1) {
+		h := hints.Hold[0]
+		assert.Equal(t, int64(2), h.TradeID)
+		assert.Equal(t, 5, h.DaysUntilNextBracket)
+		if assert.NotNil(t, h.DaysUntilTaxFree) {
+			assert.Equal(t, 5, *h.DaysUntilTaxFree)
+		}
+		assert.True(t, df(200).Equal(h.MarketValue))
+		assert.True(t, df(100).Equal(h.UnrealizedGain))
+		assert.True(t, df(25).Equal(h.TaxSavedByWaiting))
+	}
+}
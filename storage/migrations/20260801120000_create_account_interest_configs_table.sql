@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE interest_compounding AS ENUM ('daily', 'monthly', 'quarterly', 'yearly');
+
+CREATE TABLE account_interest_configs (
+    id                  BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id             BIGINT NOT NULL,
+    account_id          BIGINT NOT NULL,
+    annual_rate         NUMERIC(7,4) NOT NULL CHECK (annual_rate >= 0),
+    compounding         interest_compounding NOT NULL DEFAULT 'monthly',
+    payout_day_of_month SMALLINT NOT NULL DEFAULT 1 CHECK (payout_day_of_month >= 1 AND payout_day_of_month <= 31),
+    is_active           BOOLEAN NOT NULL DEFAULT TRUE,
+    accrued_amount      NUMERIC(19,4) NOT NULL DEFAULT 0,
+    accrued_through     DATE NULL,
+    last_payout_at      DATE NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_aic_user    FOREIGN KEY (user_id)    REFERENCES users(id),
+    CONSTRAINT fk_aic_account FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT uq_aic_account UNIQUE (account_id)
+);
+
+CREATE INDEX idx_aic_user ON account_interest_configs (user_id);
+
+CREATE TRIGGER set_account_interest_configs_updated_at
+    BEFORE UPDATE ON account_interest_configs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE account_interest_tiers (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    config_id   BIGINT NOT NULL,
+    min_balance NUMERIC(19,4) NOT NULL CHECK (min_balance >= 0),
+    annual_rate NUMERIC(7,4) NOT NULL CHECK (annual_rate >= 0),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ait_config FOREIGN KEY (config_id) REFERENCES account_interest_configs(id) ON DELETE CASCADE,
+    CONSTRAINT uq_ait_config_min UNIQUE (config_id, min_balance)
+);
+
+CREATE TRIGGER set_account_interest_tiers_updated_at
+    BEFORE UPDATE ON account_interest_tiers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing installs already have the default income tree; fresh ones get it from the seeder
+INSERT INTO categories (user_id, name, display_name, classification, parent_id, is_default)
+SELECT NULL, 'interest', 'Interest', 'income', p.id, TRUE
+FROM categories p
+WHERE p.user_id IS NULL AND p.parent_id IS NULL AND p.classification = 'income'
+ORDER BY p.id
+LIMIT 1
+ON CONFLICT (name, classification) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_account_interest_tiers_updated_at ON account_interest_tiers;
+DROP TABLE IF EXISTS account_interest_tiers;
+DROP TRIGGER IF EXISTS set_account_interest_configs_updated_at ON account_interest_configs;
+DROP TABLE IF EXISTS account_interest_configs;
+DROP TYPE IF EXISTS interest_compounding;
+-- +goose StatementEnd
@@ -0,0 +1,145 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE household_role AS ENUM ('owner', 'member');
+CREATE TYPE account_share_permission AS ENUM ('view', 'manage');
+
+CREATE TABLE households (
+    id         BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name       VARCHAR(150) NOT NULL,
+    owner_id   BIGINT NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_households_owner FOREIGN KEY (owner_id) REFERENCES users(id)
+);
+
+CREATE TRIGGER set_households_updated_at
+    BEFORE UPDATE ON households
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- a user belongs to at most one household
+CREATE TABLE household_members (
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    household_id BIGINT NOT NULL,
+    user_id      BIGINT NOT NULL,
+    role         household_role NOT NULL DEFAULT 'member',
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_hm_household FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE,
+    CONSTRAINT fk_hm_user      FOREIGN KEY (user_id)      REFERENCES users(id),
+    CONSTRAINT uq_hm_user UNIQUE (user_id)
+);
+
+CREATE INDEX idx_hm_household ON household_members (household_id);
+
+CREATE TRIGGER set_household_members_updated_at
+    BEFORE UPDATE ON household_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE account_shares (
+    id                BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id        BIGINT NOT NULL,
+    household_id      BIGINT NOT NULL,
+    user_id           BIGINT NOT NULL,
+    permission        account_share_permission NOT NULL DEFAULT 'view',
+    ownership_percent NUMERIC(5,2) NULL CHECK (ownership_percent IS NULL OR (ownership_percent >= 0 AND ownership_percent <= 100)),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_as_account   FOREIGN KEY (account_id)   REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_as_household FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE,
+    CONSTRAINT fk_as_user      FOREIGN KEY (user_id)      REFERENCES users(id),
+    CONSTRAINT uq_as_account_user UNIQUE (account_id, user_id)
+);
+
+CREATE INDEX idx_as_user ON account_shares (user_id);
+
+CREATE TRIGGER set_account_shares_updated_at
+    BEFORE UPDATE ON account_shares
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- household invitations reuse the invitation flow
+ALTER TABLE invitations
+    ADD COLUMN household_id BIGINT NULL,
+    ADD CONSTRAINT fk_invitations_household FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE;
+
+-- the owner's portion of a shared account; NULL counts the full balance
+ALTER TABLE accounts
+    ADD COLUMN ownership_percent NUMERIC(5,2) NULL
+        CHECK (ownership_percent IS NULL OR (ownership_percent >= 0 AND ownership_percent <= 100));
+
+-- who entered the transaction; differs from user_id when a household member books on a shared account
+ALTER TABLE transactions
+    ADD COLUMN created_by BIGINT NULL,
+    ADD CONSTRAINT fk_transactions_created_by FOREIGN KEY (created_by) REFERENCES users(id);
+
+UPDATE transactions SET created_by = user_id WHERE created_by IS NULL;
+
+-- shared accounts count towards every member's net worth, scaled by ownership
+CREATE OR REPLACE VIEW v_user_account_daily_snapshots AS
+SELECT
+    s.user_id,
+    s.account_id,
+    s.as_of,
+    s.currency,
+    ((s.end_balance + s.market_value) * COALESCE(a.ownership_percent, 100) / 100)::NUMERIC(19,4) AS end_balance
+FROM account_daily_snapshots s
+         JOIN accounts a ON a.id = s.account_id
+WHERE
+    a.include_in_net_worth = TRUE
+  AND (a.opened_at IS NULL OR s.as_of::date >= a.opened_at::date)
+  AND (a.closed_at IS NULL OR s.as_of::date <  a.closed_at::date)
+UNION ALL
+SELECT
+    sh.user_id,
+    s.account_id,
+    s.as_of,
+    s.currency,
+    ((s.end_balance + s.market_value) * COALESCE(sh.ownership_percent, 100) / 100)::NUMERIC(19,4) AS end_balance
+FROM account_daily_snapshots s
+         JOIN accounts a        ON a.id = s.account_id
+         JOIN account_shares sh ON sh.account_id = s.account_id
+WHERE
+    a.include_in_net_worth = TRUE
+  AND (a.opened_at IS NULL OR s.as_of::date >= a.opened_at::date)
+  AND (a.closed_at IS NULL OR s.as_of::date <  a.closed_at::date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE VIEW v_user_account_daily_snapshots AS
+SELECT
+    s.user_id,
+    s.account_id,
+    s.as_of,
+    s.currency,
+    (s.end_balance + s.market_value)::NUMERIC(19,4) AS end_balance
+FROM account_daily_snapshots s
+         JOIN accounts a ON a.id = s.account_id
+WHERE
+    a.include_in_net_worth = TRUE
+  AND (a.opened_at IS NULL OR s.as_of::date >= a.opened_at::date)
+  AND (a.closed_at IS NULL OR s.as_of::date <  a.closed_at::date);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_created_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS created_by;
+ALTER TABLE accounts DROP COLUMN IF EXISTS ownership_percent;
+ALTER TABLE invitations DROP CONSTRAINT IF EXISTS fk_invitations_household;
+ALTER TABLE invitations DROP COLUMN IF EXISTS household_id;
+
+DROP TRIGGER IF EXISTS set_account_shares_updated_at ON account_shares;
+DROP TABLE IF EXISTS account_shares;
+DROP TRIGGER IF EXISTS set_household_members_updated_at ON household_members;
+DROP TABLE IF EXISTS household_members;
+DROP TRIGGER IF EXISTS set_households_updated_at ON households;
+DROP TABLE IF EXISTS households;
+DROP TYPE IF EXISTS account_share_permission;
+DROP TYPE IF EXISTS household_role;
+-- +goose StatementEnd
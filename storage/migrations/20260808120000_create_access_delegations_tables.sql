@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE delegation_module AS ENUM ('accounts', 'transactions', 'investments', 'tax', 'analytics', 'savings');
+
+-- time-limited read-only access an owner grants to another user (e.g. a tax advisor)
+CREATE TABLE access_delegations (
+    id               BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    owner_id         BIGINT NOT NULL,
+    delegate_id      BIGINT NULL,
+    email            VARCHAR(255) NOT NULL,
+    label            VARCHAR(150) NULL,
+    expires_at       TIMESTAMPTZ NOT NULL,
+    revoked_at       TIMESTAMPTZ NULL,
+    last_accessed_at TIMESTAMPTZ NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ad_owner    FOREIGN KEY (owner_id)    REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ad_delegate FOREIGN KEY (delegate_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_ad_not_self CHECK (delegate_id IS NULL OR delegate_id <> owner_id)
+);
+
+CREATE INDEX idx_ad_owner    ON access_delegations (owner_id);
+CREATE INDEX idx_ad_delegate ON access_delegations (delegate_id, owner_id) WHERE revoked_at IS NULL;
+
+CREATE TRIGGER set_access_delegations_updated_at
+    BEFORE UPDATE ON access_delegations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- no rows means every module is readable
+CREATE TABLE access_delegation_modules (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    delegation_id BIGINT NOT NULL,
+    module        delegation_module NOT NULL,
+
+    CONSTRAINT fk_adm_delegation FOREIGN KEY (delegation_id) REFERENCES access_delegations(id) ON DELETE CASCADE,
+    CONSTRAINT uq_adm_module UNIQUE (delegation_id, module)
+);
+
+-- no rows means every account is readable
+CREATE TABLE access_delegation_accounts (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    delegation_id BIGINT NOT NULL,
+    account_id    BIGINT NOT NULL,
+
+    CONSTRAINT fk_ada_delegation FOREIGN KEY (delegation_id) REFERENCES access_delegations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ada_account    FOREIGN KEY (account_id)    REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT uq_ada_account UNIQUE (delegation_id, account_id)
+);
+
+-- external delegates register through the invitation flow
+ALTER TABLE invitations
+    ADD COLUMN delegation_id BIGINT NULL,
+    ADD CONSTRAINT fk_invitations_delegation FOREIGN KEY (delegation_id) REFERENCES access_delegations(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE invitations DROP CONSTRAINT IF EXISTS fk_invitations_delegation;
+ALTER TABLE invitations DROP COLUMN IF EXISTS delegation_id;
+
+DROP TABLE IF EXISTS access_delegation_accounts;
+DROP TABLE IF EXISTS access_delegation_modules;
+DROP TRIGGER IF EXISTS set_access_delegations_updated_at ON access_delegations;
+DROP TABLE IF EXISTS access_delegations;
+DROP TYPE IF EXISTS delegation_module;
+-- +goose StatementEnd
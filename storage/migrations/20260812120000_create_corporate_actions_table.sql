@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE corporate_action_type AS ENUM ('split', 'reverse_split', 'ticker_change', 'merger', 'spin_off');
+
+CREATE TABLE corporate_actions (
+    id                 BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id            BIGINT NOT NULL,
+    asset_id           BIGINT NOT NULL,
+    action_type        corporate_action_type NOT NULL,
+    effective_date     DATE NOT NULL,
+
+    -- ratio_from old units become ratio_to new units (10:1 split => from 1, to 10)
+    ratio_from         NUMERIC(19,8) NOT NULL DEFAULT 1 CHECK (ratio_from > 0),
+    ratio_to           NUMERIC(19,8) NOT NULL DEFAULT 1 CHECK (ratio_to > 0),
+
+    old_ticker         VARCHAR(20),
+    new_ticker         VARCHAR(20),
+    new_name           VARCHAR(255),
+
+    -- spin-off: the asset created for the spun-off shares and the share of cost basis it takes over
+    spun_off_asset_id  BIGINT,
+    cost_basis_percent NUMERIC(7,4) CHECK (cost_basis_percent >= 0 AND cost_basis_percent <= 1),
+
+    notes              VARCHAR(255),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ca_user      FOREIGN KEY (user_id)           REFERENCES users(id),
+    CONSTRAINT fk_ca_asset     FOREIGN KEY (asset_id)          REFERENCES investment_assets(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ca_spun_off  FOREIGN KEY (spun_off_asset_id) REFERENCES investment_assets(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ca_asset ON corporate_actions (asset_id, effective_date);
+CREATE INDEX idx_ca_user ON corporate_actions (user_id);
+
+CREATE TRIGGER set_corporate_actions_updated_at
+    BEFORE UPDATE ON corporate_actions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_corporate_actions_updated_at ON corporate_actions;
+DROP TABLE IF EXISTS corporate_actions;
+DROP TYPE IF EXISTS corporate_action_type;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE cost_basis_method AS ENUM ('fifo', 'lifo', 'average', 'specific_lot');
+
+ALTER TABLE investment_tax_settings
+    ADD COLUMN cost_basis_method cost_basis_method NOT NULL DEFAULT 'fifo';
+
+-- NULL inherits the user's method from investment_tax_settings
+ALTER TABLE investment_assets
+    ADD COLUMN cost_basis_method cost_basis_method NULL;
+
+CREATE TABLE investment_trade_lots (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    sell_trade_id BIGINT NOT NULL,
+    buy_trade_id  BIGINT NOT NULL,
+    quantity      NUMERIC(19,8) NOT NULL CHECK (quantity > 0),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_itl_sell FOREIGN KEY (sell_trade_id) REFERENCES investment_trades(id) ON DELETE CASCADE,
+    CONSTRAINT fk_itl_buy  FOREIGN KEY (buy_trade_id)  REFERENCES investment_trades(id) ON DELETE CASCADE,
+    CONSTRAINT uq_itl_sell_buy UNIQUE (sell_trade_id, buy_trade_id)
+);
+
+CREATE INDEX idx_itl_buy ON investment_trade_lots (buy_trade_id);
+
+CREATE TRIGGER set_investment_trade_lots_updated_at
+    BEFORE UPDATE ON investment_trade_lots
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_investment_trade_lots_updated_at ON investment_trade_lots;
+DROP TABLE IF EXISTS investment_trade_lots;
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS cost_basis_method;
+ALTER TABLE investment_tax_settings DROP COLUMN IF EXISTS cost_basis_method;
+DROP TYPE IF EXISTS cost_basis_method;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE investment_assets ADD COLUMN asset_class VARCHAR(50);
+
+CREATE TYPE allocation_target_kind AS ENUM ('asset', 'investment_type', 'asset_class');
+
+CREATE TABLE allocation_targets (
+    id                BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id           BIGINT NOT NULL,
+    -- NULL targets the whole portfolio
+    account_id        BIGINT,
+    kind              allocation_target_kind NOT NULL,
+    asset_id          BIGINT,
+    investment_type   investment_type,
+    asset_class       VARCHAR(50),
+    target_percent    NUMERIC(7,4) NOT NULL CHECK (target_percent > 0 AND target_percent <= 100),
+    tolerance_percent NUMERIC(7,4) NOT NULL DEFAULT 5 CHECK (tolerance_percent >= 0),
+    out_of_band_since DATE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_at_user    FOREIGN KEY (user_id)    REFERENCES users(id),
+    CONSTRAINT fk_at_account FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_at_asset   FOREIGN KEY (asset_id)   REFERENCES investment_assets(id) ON DELETE CASCADE,
+    CONSTRAINT chk_at_kind CHECK (
+        (kind = 'asset' AND asset_id IS NOT NULL) OR
+        (kind = 'investment_type' AND investment_type IS NOT NULL) OR
+        (kind = 'asset_class' AND asset_class IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_at_user_account ON allocation_targets (user_id, account_id);
+
+CREATE TRIGGER set_allocation_targets_updated_at
+    BEFORE UPDATE ON allocation_targets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_allocation_targets_updated_at ON allocation_targets;
+DROP TABLE IF EXISTS allocation_targets;
+DROP TYPE IF EXISTS allocation_target_kind;
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS asset_class;
+-- +goose StatementEnd
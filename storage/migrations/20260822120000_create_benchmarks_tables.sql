@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE benchmarks (
+    id              BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    ticker          VARCHAR(20) NOT NULL,
+    investment_type investment_type NOT NULL,
+    currency        CHAR(3) NOT NULL DEFAULT 'USD',
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_benchmarks_ticker UNIQUE (ticker, investment_type)
+);
+
+CREATE TRIGGER set_benchmarks_updated_at
+    BEFORE UPDATE ON benchmarks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE user_benchmarks (
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    -- NULL attaches the benchmark to the whole portfolio
+    account_id   BIGINT,
+    benchmark_id BIGINT NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_ub_user      FOREIGN KEY (user_id)      REFERENCES users(id),
+    CONSTRAINT fk_ub_account   FOREIGN KEY (account_id)   REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ub_benchmark FOREIGN KEY (benchmark_id) REFERENCES benchmarks(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_ub_scope ON user_benchmarks (user_id, COALESCE(account_id, 0), benchmark_id);
+
+CREATE TRIGGER set_user_benchmarks_updated_at
+    BEFORE UPDATE ON user_benchmarks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Benchmark prices share the asset price history; a row belongs to either an asset or a benchmark.
+ALTER TABLE asset_price_history DROP CONSTRAINT asset_price_history_pkey;
+ALTER TABLE asset_price_history ALTER COLUMN asset_id DROP NOT NULL;
+ALTER TABLE asset_price_history ADD COLUMN benchmark_id BIGINT;
+ALTER TABLE asset_price_history
+    ADD CONSTRAINT fk_aph_benchmark FOREIGN KEY (benchmark_id) REFERENCES benchmarks(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chk_aph_owner CHECK ((asset_id IS NULL) <> (benchmark_id IS NULL)),
+    ADD CONSTRAINT uq_aph_asset_asof UNIQUE (asset_id, as_of),
+    ADD CONSTRAINT uq_aph_benchmark_asof UNIQUE (benchmark_id, as_of);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM asset_price_history WHERE benchmark_id IS NOT NULL;
+ALTER TABLE asset_price_history
+    DROP CONSTRAINT IF EXISTS uq_aph_benchmark_asof,
+    DROP CONSTRAINT IF EXISTS uq_aph_asset_asof,
+    DROP CONSTRAINT IF EXISTS chk_aph_owner,
+    DROP CONSTRAINT IF EXISTS fk_aph_benchmark;
+ALTER TABLE asset_price_history DROP COLUMN IF EXISTS benchmark_id;
+ALTER TABLE asset_price_history ALTER COLUMN asset_id SET NOT NULL;
+ALTER TABLE asset_price_history ADD PRIMARY KEY (asset_id, as_of);
+
+DROP TRIGGER IF EXISTS set_user_benchmarks_updated_at ON user_benchmarks;
+DROP TABLE IF EXISTS user_benchmarks;
+DROP TRIGGER IF EXISTS set_benchmarks_updated_at ON benchmarks;
+DROP TABLE IF EXISTS benchmarks;
+-- +goose StatementEnd
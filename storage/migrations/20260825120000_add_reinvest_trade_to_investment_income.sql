@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set when a dividend was reinvested; points at the buy it funded
+ALTER TABLE investment_income
+    ADD COLUMN reinvest_trade_id BIGINT,
+    ADD CONSTRAINT fk_income_reinvest_trade FOREIGN KEY (reinvest_trade_id)
+        REFERENCES investment_trades(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_inv_income_reinvest_trade ON investment_income(reinvest_trade_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_inv_income_reinvest_trade;
+ALTER TABLE investment_income
+    DROP CONSTRAINT IF EXISTS fk_income_reinvest_trade,
+    DROP COLUMN IF EXISTS reinvest_trade_id;
+-- +goose StatementEnd
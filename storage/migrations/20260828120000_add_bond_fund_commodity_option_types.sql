@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE investment_type ADD VALUE IF NOT EXISTS 'bond';
+ALTER TYPE investment_type ADD VALUE IF NOT EXISTS 'mutual_fund';
+ALTER TYPE investment_type ADD VALUE IF NOT EXISTS 'commodity';
+ALTER TYPE investment_type ADD VALUE IF NOT EXISTS 'option';
+
+ALTER TYPE income_type ADD VALUE IF NOT EXISTS 'coupon';
+
+-- Contract terms; only set for the types they apply to
+ALTER TABLE investment_assets
+    ADD COLUMN face_value       NUMERIC(19,4),
+    ADD COLUMN coupon_rate      NUMERIC(7,4),
+    ADD COLUMN coupon_frequency INT,
+    ADD COLUMN maturity_date    DATE,
+    ADD COLUMN unit             VARCHAR(10),
+    ADD COLUMN option_type      VARCHAR(4),
+    ADD COLUMN strike_price     NUMERIC(19,4),
+    ADD COLUMN expiry_date      DATE,
+    ADD COLUMN multiplier       NUMERIC(19,4),
+    ADD CONSTRAINT chk_ia_coupon_frequency CHECK (coupon_frequency IN (0, 1, 2, 4, 12)),
+    ADD CONSTRAINT chk_ia_unit CHECK (unit IN ('g', 'kg', 'oz')),
+    ADD CONSTRAINT chk_ia_option_type CHECK (option_type IN ('call', 'put'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Enum values can't be dropped; only the term columns are removed.
+ALTER TABLE investment_assets
+    DROP CONSTRAINT IF EXISTS chk_ia_option_type,
+    DROP CONSTRAINT IF EXISTS chk_ia_unit,
+    DROP CONSTRAINT IF EXISTS chk_ia_coupon_frequency,
+    DROP COLUMN IF EXISTS multiplier,
+    DROP COLUMN IF EXISTS expiry_date,
+    DROP COLUMN IF EXISTS strike_price,
+    DROP COLUMN IF EXISTS option_type,
+    DROP COLUMN IF EXISTS unit,
+    DROP COLUMN IF EXISTS maturity_date,
+    DROP COLUMN IF EXISTS coupon_frequency,
+    DROP COLUMN IF EXISTS coupon_rate,
+    DROP COLUMN IF EXISTS face_value;
+-- +goose StatementEnd
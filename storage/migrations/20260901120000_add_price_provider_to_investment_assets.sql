@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE investment_assets ADD COLUMN price_provider VARCHAR(20);
+
+ALTER TABLE investment_assets
+    ADD CONSTRAINT chk_ia_price_provider CHECK (price_provider IN ('yahoo', 'coingecko', 'stooq', 'ecb'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE investment_assets DROP CONSTRAINT IF EXISTS chk_ia_price_provider;
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS price_provider;
+-- +goose StatementEnd
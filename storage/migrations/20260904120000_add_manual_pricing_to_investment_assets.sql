@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Manually priced assets are valued from prices the user enters and are left out of the price sync.
+ALTER TABLE investment_assets ADD COLUMN manual_pricing BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS manual_pricing;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set on dividends read from a broker statement so the import can be rolled back
+ALTER TABLE investment_income
+    ADD COLUMN import_id BIGINT;
+
+CREATE INDEX idx_inv_income_import ON investment_income(import_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_inv_income_import;
+ALTER TABLE investment_income
+    DROP COLUMN IF EXISTS import_id;
+-- +goose StatementEnd
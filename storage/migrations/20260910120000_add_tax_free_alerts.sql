@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Lots worth at least this much are warned about a week before they become tax-free.
+ALTER TABLE investment_tax_settings ADD COLUMN tax_free_alert_min_value DECIMAL(19,4) NOT NULL DEFAULT 1000;
+-- Set once the warning for a buy lot went out, so it is only sent once.
+ALTER TABLE investment_trades ADD COLUMN tax_free_alerted_at DATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE investment_trades DROP COLUMN IF EXISTS tax_free_alerted_at;
+ALTER TABLE investment_tax_settings DROP COLUMN IF EXISTS tax_free_alert_min_value;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE price_alert_kind AS ENUM ('percent_change', 'price', 'value', 'profit_loss');
+CREATE TYPE price_alert_direction AS ENUM ('above', 'below', 'either');
+
+CREATE TABLE price_alert_rules (
+    id                BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id           BIGINT NOT NULL,
+    -- exactly one of asset_id / account_id scopes the rule
+    asset_id          BIGINT,
+    account_id        BIGINT,
+    kind              price_alert_kind NOT NULL,
+    direction         price_alert_direction NOT NULL,
+    threshold         NUMERIC(19,4) NOT NULL,
+    period_days       INT,
+    cooldown_hours    INT NOT NULL DEFAULT 24 CHECK (cooldown_hours > 0),
+    is_active         BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_par_user    FOREIGN KEY (user_id)    REFERENCES users(id),
+    CONSTRAINT fk_par_asset   FOREIGN KEY (asset_id)   REFERENCES investment_assets(id) ON DELETE CASCADE,
+    CONSTRAINT fk_par_account FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    CONSTRAINT chk_par_scope CHECK ((asset_id IS NULL) <> (account_id IS NULL)),
+    CONSTRAINT chk_par_period CHECK (
+        (kind = 'percent_change' AND period_days IN (1, 7, 30)) OR
+        (kind <> 'percent_change' AND period_days IS NULL)
+    )
+);
+
+CREATE INDEX idx_par_user ON price_alert_rules (user_id);
+
+CREATE TRIGGER set_price_alert_rules_updated_at
+    BEFORE UPDATE ON price_alert_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_price_alert_rules_updated_at ON price_alert_rules;
+DROP TABLE IF EXISTS price_alert_rules;
+DROP TYPE IF EXISTS price_alert_direction;
+DROP TYPE IF EXISTS price_alert_kind;
+-- +goose StatementEnd
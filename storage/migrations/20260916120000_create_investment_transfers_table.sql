@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE investment_transfers (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id       BIGINT NOT NULL,
+    from_asset_id BIGINT NOT NULL,
+    to_asset_id   BIGINT NOT NULL,
+    txn_date      DATE NOT NULL,
+    quantity      NUMERIC(19,8) NOT NULL CHECK (quantity > 0),
+    cost_basis    NUMERIC(19,4) NOT NULL DEFAULT 0,
+    description   VARCHAR(255),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_it_user       FOREIGN KEY (user_id)       REFERENCES users(id),
+    CONSTRAINT fk_it_from_asset FOREIGN KEY (from_asset_id) REFERENCES investment_assets(id),
+    CONSTRAINT fk_it_to_asset   FOREIGN KEY (to_asset_id)   REFERENCES investment_assets(id),
+    CONSTRAINT chk_it_assets CHECK (from_asset_id <> to_asset_id)
+);
+
+CREATE INDEX idx_it_user ON investment_transfers (user_id);
+
+CREATE TRIGGER set_investment_transfers_updated_at
+    BEFORE UPDATE ON investment_transfers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Both legs of a transfer point back at it; buys carried over keep their original purchase date.
+ALTER TABLE investment_trades ADD COLUMN transfer_id BIGINT REFERENCES investment_transfers(id) ON DELETE CASCADE;
+ALTER TABLE investment_trades ADD COLUMN acquired_at DATE;
+CREATE INDEX idx_inv_trades_transfer ON investment_trades (transfer_id) WHERE transfer_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_inv_trades_transfer;
+ALTER TABLE investment_trades DROP COLUMN IF EXISTS acquired_at;
+ALTER TABLE investment_trades DROP COLUMN IF EXISTS transfer_id;
+DROP TRIGGER IF EXISTS set_investment_transfers_updated_at ON investment_transfers;
+DROP TABLE IF EXISTS investment_transfers;
+-- +goose StatementEnd
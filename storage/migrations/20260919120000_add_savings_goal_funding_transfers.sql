@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- When set, contributions to the goal move money from this account into the goal's account.
+ALTER TABLE saving_goals ADD COLUMN funding_account_id BIGINT;
+ALTER TABLE saving_goals
+    ADD CONSTRAINT fk_sg_funding_account FOREIGN KEY (funding_account_id) REFERENCES accounts(id) ON DELETE SET NULL;
+
+ALTER TABLE saving_contributions ADD COLUMN transfer_id BIGINT;
+ALTER TABLE saving_contributions
+    ADD CONSTRAINT fk_sc_transfer FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saving_contributions_transfer ON saving_contributions(transfer_id) WHERE transfer_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_saving_contributions_transfer;
+ALTER TABLE saving_contributions DROP CONSTRAINT IF EXISTS fk_sc_transfer;
+ALTER TABLE saving_contributions DROP COLUMN IF EXISTS transfer_id;
+ALTER TABLE saving_goals DROP CONSTRAINT IF EXISTS fk_sg_funding_account;
+ALTER TABLE saving_goals DROP COLUMN IF EXISTS funding_account_id;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE savings_budget_mode AS ENUM ('fixed', 'income_percent');
+CREATE TYPE savings_budget_strategy AS ENUM ('priority', 'proportional', 'deadline');
+
+CREATE TABLE savings_budgets (
+    id                BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id           BIGINT NOT NULL,
+    mode              savings_budget_mode NOT NULL,
+    amount            NUMERIC(19,4) NULL CHECK (amount IS NULL OR amount > 0),
+    income_percent    NUMERIC(7,4) NULL CHECK (income_percent IS NULL OR (income_percent > 0 AND income_percent <= 100)),
+    strategy          savings_budget_strategy NOT NULL DEFAULT 'priority',
+    fund_day_of_month SMALLINT NULL CHECK (fund_day_of_month IS NULL OR (fund_day_of_month >= 1 AND fund_day_of_month <= 31)),
+    is_active         BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sb_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT uq_sb_user UNIQUE (user_id),
+    CONSTRAINT chk_sb_mode CHECK (
+        (mode = 'fixed' AND amount IS NOT NULL) OR
+        (mode = 'income_percent' AND income_percent IS NOT NULL)
+    )
+);
+
+CREATE TRIGGER set_savings_budgets_updated_at
+    BEFORE UPDATE ON savings_budgets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_savings_budgets_updated_at ON savings_budgets;
+DROP TABLE IF EXISTS savings_budgets;
+DROP TYPE IF EXISTS savings_budget_strategy;
+DROP TYPE IF EXISTS savings_budget_mode;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE saving_goal_recurrence AS ENUM ('yearly', 'quarterly');
+ALTER TYPE saving_contribution_source ADD VALUE IF NOT EXISTS 'settlement';
+
+-- Recurring goals refill every cycle; target_date is the due date of the current one.
+ALTER TABLE saving_goals
+    ADD COLUMN recurrence       saving_goal_recurrence NULL,
+    ADD COLUMN cycle_number     INT NOT NULL DEFAULT 1,
+    ADD COLUMN cycle_started_at DATE NULL,
+    ADD CONSTRAINT chk_sg_recurrence_due CHECK (recurrence IS NULL OR target_date IS NOT NULL);
+
+ALTER TABLE saving_contributions
+    ADD COLUMN cycle_number   INT NOT NULL DEFAULT 1,
+    ADD COLUMN transaction_id BIGINT NULL,
+    ADD CONSTRAINT fk_sc_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saving_contributions_transaction ON saving_contributions(transaction_id) WHERE transaction_id IS NOT NULL;
+
+CREATE TABLE saving_goal_cycles (
+    id             BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id        BIGINT NOT NULL,
+    goal_id        BIGINT NOT NULL,
+    cycle_number   INT NOT NULL,
+    started_at     DATE NULL,
+    due_date       DATE NOT NULL,
+    saved          NUMERIC(19,4) NOT NULL DEFAULT 0,
+    spent          NUMERIC(19,4) NOT NULL DEFAULT 0,
+    carried_over   NUMERIC(19,4) NOT NULL DEFAULT 0,
+    transaction_id BIGINT NULL,
+    settled_at     TIMESTAMPTZ NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sgc_user        FOREIGN KEY (user_id)        REFERENCES users(id),
+    CONSTRAINT fk_sgc_goal        FOREIGN KEY (goal_id)        REFERENCES saving_goals(id) ON DELETE CASCADE,
+    CONSTRAINT fk_sgc_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL,
+    CONSTRAINT uq_sgc_goal_cycle UNIQUE (goal_id, cycle_number)
+);
+
+CREATE TRIGGER set_saving_goal_cycles_updated_at
+    BEFORE UPDATE ON saving_goal_cycles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Enum values can't be dropped; 'settlement' stays on saving_contribution_source.
+DROP TRIGGER IF EXISTS set_saving_goal_cycles_updated_at ON saving_goal_cycles;
+DROP TABLE IF EXISTS saving_goal_cycles;
+DROP INDEX IF EXISTS idx_saving_contributions_transaction;
+ALTER TABLE saving_contributions
+    DROP CONSTRAINT IF EXISTS fk_sc_transaction,
+    DROP COLUMN IF EXISTS transaction_id,
+    DROP COLUMN IF EXISTS cycle_number;
+ALTER TABLE saving_goals
+    DROP CONSTRAINT IF EXISTS chk_sg_recurrence_due,
+    DROP COLUMN IF EXISTS cycle_started_at,
+    DROP COLUMN IF EXISTS cycle_number,
+    DROP COLUMN IF EXISTS recurrence;
+DROP TYPE IF EXISTS saving_goal_recurrence;
+-- +goose StatementEnd
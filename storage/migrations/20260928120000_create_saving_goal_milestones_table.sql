@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE saving_goal_milestone_kind AS ENUM ('percent', 'amount');
+CREATE TYPE saving_goal_completion_action AS ENUM ('notify', 'archive', 'redirect');
+
+-- late_alerted_at is set when the goal was last reported as falling behind and
+-- cleared once it is back on track, so the next slip alerts again.
+ALTER TABLE saving_goals
+    ADD COLUMN completion_action saving_goal_completion_action NOT NULL DEFAULT 'notify',
+    ADD COLUMN late_alerted_at   TIMESTAMPTZ NULL;
+
+CREATE TABLE saving_goal_milestones (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id       BIGINT NOT NULL,
+    goal_id       BIGINT NOT NULL,
+    kind          saving_goal_milestone_kind NOT NULL,
+    value         NUMERIC(19,4) NOT NULL CHECK (value > 0),
+    -- a milestone fires once per goal cycle; recurring goals re-arm it on the next one
+    reached_cycle INT NULL,
+    reached_at    TIMESTAMPTZ NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sgm_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT fk_sgm_goal FOREIGN KEY (goal_id) REFERENCES saving_goals(id) ON DELETE CASCADE,
+    CONSTRAINT uq_sgm_goal_kind_value UNIQUE (goal_id, kind, value),
+    CONSTRAINT chk_sgm_percent CHECK (kind <> 'percent' OR value <= 100)
+);
+
+CREATE TRIGGER set_saving_goal_milestones_updated_at
+    BEFORE UPDATE ON saving_goal_milestones
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_saving_goal_milestones_updated_at ON saving_goal_milestones;
+DROP TABLE IF EXISTS saving_goal_milestones;
+ALTER TABLE saving_goals
+    DROP COLUMN IF EXISTS late_alerted_at,
+    DROP COLUMN IF EXISTS completion_action;
+DROP TYPE IF EXISTS saving_goal_completion_action;
+DROP TYPE IF EXISTS saving_goal_milestone_kind;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE category_budget_rollover AS ENUM ('none', 'unspent', 'overspent', 'both');
+
+CREATE TABLE category_budgets (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    -- exactly one of category_id / group_id is budgeted
+    category_id BIGINT NULL,
+    group_id    BIGINT NULL,
+    amount      NUMERIC(19,4) NOT NULL CHECK (amount >= 0),
+    rollover    category_budget_rollover NOT NULL DEFAULT 'none',
+    -- rollover is carried forward from this month on
+    start_month DATE NOT NULL,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_cb_user     FOREIGN KEY (user_id)     REFERENCES users(id),
+    CONSTRAINT fk_cb_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    CONSTRAINT fk_cb_group    FOREIGN KEY (group_id)    REFERENCES category_groups(id) ON DELETE CASCADE,
+    CONSTRAINT chk_cb_scope CHECK ((category_id IS NULL) <> (group_id IS NULL)),
+    CONSTRAINT chk_cb_start_month CHECK (EXTRACT(DAY FROM start_month) = 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cb_user_category ON category_budgets (user_id, category_id) WHERE category_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cb_user_group ON category_budgets (user_id, group_id) WHERE group_id IS NOT NULL;
+
+CREATE TRIGGER set_category_budgets_updated_at
+    BEFORE UPDATE ON category_budgets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Months whose amount differs from the budget's default
+CREATE TABLE category_budget_months (
+    id        BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    budget_id BIGINT NOT NULL,
+    month     DATE NOT NULL,
+    amount    NUMERIC(19,4) NOT NULL CHECK (amount >= 0),
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_cbm_budget FOREIGN KEY (budget_id) REFERENCES category_budgets(id) ON DELETE CASCADE,
+    CONSTRAINT uq_cbm_budget_month UNIQUE (budget_id, month),
+    CONSTRAINT chk_cbm_month CHECK (EXTRACT(DAY FROM month) = 1)
+);
+
+CREATE TRIGGER set_category_budget_months_updated_at
+    BEFORE UPDATE ON category_budget_months
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS set_category_budget_months_updated_at ON category_budget_months;
+DROP TABLE IF EXISTS category_budget_months;
+DROP TRIGGER IF EXISTS set_category_budgets_updated_at ON category_budgets;
+DROP TABLE IF EXISTS category_budgets;
+DROP TYPE IF EXISTS category_budget_rollover;
+-- +goose StatementEnd
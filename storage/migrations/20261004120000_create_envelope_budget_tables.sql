@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per user that has switched envelope budgeting on
+CREATE TABLE envelope_budgets (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    -- balances are carried forward from this month on
+    start_month DATE NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_eb_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT uq_eb_user UNIQUE (user_id),
+    CONSTRAINT chk_eb_start_month CHECK (EXTRACT(DAY FROM start_month) = 1)
+);
+
+CREATE TRIGGER set_envelope_budgets_updated_at
+    BEFORE UPDATE ON envelope_budgets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE envelopes (
+    id        BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id   BIGINT NOT NULL,
+    name      VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_env_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT uq_env_user_name UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER set_envelopes_updated_at
+    BEFORE UPDATE ON envelopes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Spending in a category is drawn from the one envelope it belongs to
+CREATE TABLE envelope_categories (
+    envelope_id BIGINT NOT NULL,
+    category_id BIGINT NOT NULL,
+    user_id     BIGINT NOT NULL,
+
+    PRIMARY KEY (envelope_id, category_id),
+    CONSTRAINT fk_envc_envelope FOREIGN KEY (envelope_id) REFERENCES envelopes(id) ON DELETE CASCADE,
+    CONSTRAINT fk_envc_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    CONSTRAINT fk_envc_user     FOREIGN KEY (user_id)     REFERENCES users(id),
+    CONSTRAINT uq_envc_user_category UNIQUE (user_id, category_id)
+);
+
+CREATE TYPE envelope_movement_kind AS ENUM ('assign', 'move', 'cover');
+
+-- Money moving between the to-be-assigned pool (NULL side) and envelopes
+CREATE TABLE envelope_movements (
+    id               BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id          BIGINT NOT NULL,
+    month            DATE NOT NULL,
+    from_envelope_id BIGINT NULL,
+    to_envelope_id   BIGINT NULL,
+    amount           NUMERIC(19,4) NOT NULL CHECK (amount > 0),
+    kind             envelope_movement_kind NOT NULL,
+    note             VARCHAR(255) NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_envm_user FOREIGN KEY (user_id)          REFERENCES users(id),
+    CONSTRAINT fk_envm_from FOREIGN KEY (from_envelope_id) REFERENCES envelopes(id) ON DELETE CASCADE,
+    CONSTRAINT fk_envm_to   FOREIGN KEY (to_envelope_id)   REFERENCES envelopes(id) ON DELETE CASCADE,
+    CONSTRAINT chk_envm_sides CHECK (from_envelope_id IS NOT NULL OR to_envelope_id IS NOT NULL),
+    CONSTRAINT chk_envm_distinct CHECK (from_envelope_id IS DISTINCT FROM to_envelope_id),
+    CONSTRAINT chk_envm_month CHECK (EXTRACT(DAY FROM month) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_envm_user_month ON envelope_movements (user_id, month);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS envelope_movements;
+DROP TYPE IF EXISTS envelope_movement_kind;
+DROP TABLE IF EXISTS envelope_categories;
+DROP TRIGGER IF EXISTS set_envelopes_updated_at ON envelopes;
+DROP TABLE IF EXISTS envelopes;
+DROP TRIGGER IF EXISTS set_envelope_budgets_updated_at ON envelope_budgets;
+DROP TABLE IF EXISTS envelope_budgets;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE spending_limit_delivery AS ENUM ('instant', 'digest');
+
+CREATE TABLE spending_limits (
+    id             BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id        BIGINT NOT NULL,
+    -- exactly one of category_id / group_id is limited
+    category_id    BIGINT NULL,
+    group_id       BIGINT NULL,
+    amount         NUMERIC(19,4) NOT NULL CHECK (amount > 0),
+    notify_at_80   BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_at_100  BOOLEAN NOT NULL DEFAULT TRUE,
+    custom_percent SMALLINT NULL CHECK (custom_percent BETWEEN 1 AND 500),
+    delivery       spending_limit_delivery NOT NULL DEFAULT 'instant',
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sl_user     FOREIGN KEY (user_id)     REFERENCES users(id),
+    CONSTRAINT fk_sl_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    CONSTRAINT fk_sl_group    FOREIGN KEY (group_id)    REFERENCES category_groups(id) ON DELETE CASCADE,
+    CONSTRAINT chk_sl_scope CHECK ((category_id IS NULL) <> (group_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sl_user_category ON spending_limits (user_id, category_id) WHERE category_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sl_user_group ON spending_limits (user_id, group_id) WHERE group_id IS NOT NULL;
+
+CREATE TRIGGER set_spending_limits_updated_at
+    BEFORE UPDATE ON spending_limits
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Each threshold is crossed at most once per month; digest alerts wait with notified_at unset
+CREATE TABLE spending_limit_alerts (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    limit_id    BIGINT NOT NULL,
+    month       DATE NOT NULL,
+    percent     SMALLINT NOT NULL,
+    spent       NUMERIC(19,4) NOT NULL,
+    notified_at TIMESTAMPTZ NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sla_user  FOREIGN KEY (user_id)  REFERENCES users(id),
+    CONSTRAINT fk_sla_limit FOREIGN KEY (limit_id) REFERENCES spending_limits(id) ON DELETE CASCADE,
+    CONSTRAINT uq_sla_limit_month_percent UNIQUE (limit_id, month, percent),
+    CONSTRAINT chk_sla_month CHECK (EXTRACT(DAY FROM month) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sla_pending ON spending_limit_alerts (user_id) WHERE notified_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS spending_limit_alerts;
+DROP TRIGGER IF EXISTS set_spending_limits_updated_at ON spending_limits;
+DROP TABLE IF EXISTS spending_limits;
+DROP TYPE IF EXISTS spending_limit_delivery;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cost basis a corporate action moved off a buy's open units, e.g. to a spun-off holding.
+-- The buy row already carries the reduced basis; units sold before effective_date are
+-- matched against the basis as it was.
+CREATE TABLE investment_basis_adjustments (
+    id                  BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    trade_id            BIGINT NOT NULL,
+    corporate_action_id BIGINT NOT NULL,
+    effective_date      DATE NOT NULL,
+    value_moved         NUMERIC(19,4) NOT NULL DEFAULT 0,
+    fee_moved           NUMERIC(19,4) NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_iba_trade  FOREIGN KEY (trade_id)            REFERENCES investment_trades(id) ON DELETE CASCADE,
+    CONSTRAINT fk_iba_action FOREIGN KEY (corporate_action_id) REFERENCES corporate_actions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_iba_trade ON investment_basis_adjustments (trade_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS investment_basis_adjustments;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Consecutive price syncs held back because the move looked like an unrecorded split.
+ALTER TABLE investment_assets ADD COLUMN split_holds SMALLINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS split_holds;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Last coupon date the scheduler has processed, so a deleted coupon isn't booked again.
+ALTER TABLE investment_assets ADD COLUMN last_coupon_date DATE;
+
+UPDATE investment_assets a
+SET last_coupon_date = (
+    SELECT MAX(i.txn_date)
+    FROM investment_income i
+    WHERE i.asset_id = a.id
+      AND i.income_type = 'coupon'
+)
+WHERE a.investment_type = 'bond';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE investment_assets DROP COLUMN IF EXISTS last_coupon_date;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Whether the rule fired and its figure has stayed on the alert side since, so a rule
+-- fires when the level is crossed rather than for as long as it stays there.
+ALTER TABLE price_alert_rules ADD COLUMN last_hit BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE price_alert_rules
+SET last_hit = TRUE
+WHERE last_triggered_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE price_alert_rules DROP COLUMN IF EXISTS last_hit;
+-- +goose StatementEnd
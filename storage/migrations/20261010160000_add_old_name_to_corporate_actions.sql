@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The asset's name before a ticker change or merger, so the action can be undone.
+ALTER TABLE corporate_actions ADD COLUMN old_name VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE corporate_actions DROP COLUMN IF EXISTS old_name;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A rule without an asset or account watches the whole portfolio.
+ALTER TABLE price_alert_rules DROP CONSTRAINT chk_par_scope;
+ALTER TABLE price_alert_rules ADD CONSTRAINT chk_par_scope CHECK (asset_id IS NULL OR account_id IS NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM price_alert_rules WHERE asset_id IS NULL AND account_id IS NULL;
+ALTER TABLE price_alert_rules DROP CONSTRAINT chk_par_scope;
+ALTER TABLE price_alert_rules ADD CONSTRAINT chk_par_scope CHECK ((asset_id IS NULL) <> (account_id IS NULL));
+-- +goose StatementEnd